@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// The components of GetCalendar.
+const (
+	CalendarVTodo  = "vtodo"
+	CalendarVEvent = "vevent"
+)
+
+// CreateCalendarToken a new secret token of the calendar feed, the feed of the previous one stops working.
+// The token is returned only here.
+func (c *Client) CreateCalendarToken(ctx context.Context) (CalendarToken, error) {
+	var res CalendarToken
+
+	if _, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/calendar/token",
+	}, &res); err != nil {
+		return CalendarToken{}, err
+	}
+
+	return res, nil
+}
+
+// DeleteCalendarToken disables the calendar feed.
+func (c *Client) DeleteCalendarToken(ctx context.Context) error {
+	_, err := c.do(ctx, request{
+		method: http.MethodDelete,
+		path:   "/calendar/token",
+	}, nil)
+
+	return err
+}
+
+// GetCalendar copies the iCalendar feed of the token to w, component - CalendarVTodo(empty) or CalendarVEvent.
+// The credentials of the client are not used.
+func (c *Client) GetCalendar(ctx context.Context, token, component string, w io.Writer) error {
+	u := c.BaseURL + apiV1 + "/calendar/" + url.PathEscape(token) + ".ics"
+	if component != "" {
+		u += "?" + url.Values{"component": {component}}.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return &networkError{err: err}
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp.Body)
+
+		return newError(resp.StatusCode, b)
+	}
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return &networkError{err: err}
+	}
+
+	return nil
+}
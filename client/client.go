@@ -0,0 +1,274 @@
+// Package client of the task API /api/v1. It has its own types of the requests and responses, it does not
+// depend on the server.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const apiV1 = "/api/v1"
+
+const (
+	defaultTimeout    = 30 * time.Second
+	defaultMaxRetries = 2
+	defaultRetryDelay = 200 * time.Millisecond
+)
+
+const (
+	mimeJSON       = "application/json"
+	mimeMergePatch = "application/merge-patch+json"
+	mimeJSONPatch  = "application/json-patch+json"
+)
+
+const (
+	headerETag           = "ETag"
+	headerIfMatch        = "If-Match"
+	headerIfNoneMatch    = "If-None-Match"
+	headerIdempotencyKey = "Idempotency-Key"
+)
+
+// Client safe for concurrent use. The fields are not changed after the first request.
+type Client struct {
+	// BaseURL without /api, http://127.0.0.1:45222.
+	BaseURL string
+	// Username, Password the user of the tasks or the manager for CreateUser and DeleteUser.
+	Username string
+	Password string
+	// Manager true - the webhook methods are of the webhooks of the manager.
+	Manager    bool
+	HTTPClient *http.Client
+	// MaxRetries after a network error, 429 or 5xx. POST requests are retried with the same Idempotency-Key,
+	// PATCH requests are not retried.
+	MaxRetries int
+	// RetryDelay doubles after each retry.
+	RetryDelay time.Duration
+}
+
+// TaskPatch nil - the field is not changed. Due and Recurrence are removed with the "remove" of PatchTaskJSON.
+type TaskPatch struct {
+	Title     *string    `json:"title,omitempty"`
+	Completed *bool      `json:"completed,omitempty"`
+	Due       *time.Time `json:"due,omitempty"`
+	// Recurrence RRULE without the prefix, FREQ=WEEKLY;BYDAY=MO.
+	Recurrence *string `json:"recurrence,omitempty"`
+}
+
+// JSONPatchOperation RFC 6902, the paths are /title, /completed, /due and /recurrence.
+type JSONPatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	From  string `json:"from,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	contentType string
+	// ifMatch empty - the task is changed whatever its version.
+	ifMatch     string
+	ifNoneMatch string
+	body        any
+	// resultOnError the error responses also have the result body.
+	resultOnError bool
+}
+
+type response struct {
+	statusCode int
+	etag       string
+}
+
+func New(baseURL, username, password string) *Client {
+	return &Client{
+		BaseURL:  strings.TrimRight(baseURL, "/"),
+		Username: username,
+		Password: password,
+		HTTPClient: &http.Client{
+			Timeout: defaultTimeout,
+		},
+		MaxRetries: defaultMaxRetries,
+		RetryDelay: defaultRetryDelay,
+	}
+}
+
+// do sends the request with retries, the response body is decoded into result if it is not nil.
+func (c *Client) do(ctx context.Context, r request, result any) (response, error) {
+	u := c.BaseURL + apiV1 + r.path
+	if len(r.query) > 0 {
+		u += "?" + r.query.Encode()
+	}
+
+	var body []byte
+
+	if r.body != nil {
+		b, err := json.Marshal(r.body)
+		if err != nil {
+			return response{}, fmt.Errorf("marshal body: %w", err)
+		}
+
+		body = b
+	}
+
+	header := make(http.Header)
+
+	header.Set("Accept", mimeJSON)
+
+	if r.body != nil {
+		contentType := r.contentType
+		if contentType == "" {
+			contentType = mimeJSON
+		}
+
+		header.Set("Content-Type", contentType)
+	}
+
+	if r.ifMatch != "" {
+		header.Set(headerIfMatch, r.ifMatch)
+	}
+
+	if r.ifNoneMatch != "" {
+		header.Set(headerIfNoneMatch, r.ifNoneMatch)
+	}
+
+	retries := c.MaxRetries
+
+	switch r.method {
+	case http.MethodPost:
+		key, err := newIdempotencyKey()
+		if err != nil {
+			return response{}, err
+		}
+
+		header.Set(headerIdempotencyKey, key)
+
+	case http.MethodPatch:
+		retries = 0
+	}
+
+	delay := c.RetryDelay
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, r, u, header, body, result)
+		if err == nil || attempt >= retries || !retryable(ctx, err) {
+			return resp, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return response{}, fmt.Errorf("retry: %w", ctx.Err())
+
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+	}
+}
+
+func (c *Client) send(
+	ctx context.Context, r request, u string, header http.Header, body []byte, result any,
+) (response, error) {
+	var reqBody io.Reader
+
+	if body != nil {
+		reqBody = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, r.method, u, reqBody)
+	if err != nil {
+		return response{}, fmt.Errorf("new request: %w", err)
+	}
+
+	req.Header = header.Clone()
+	req.SetBasicAuth(c.Username, c.Password)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return response{}, &networkError{err: err}
+	}
+
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return response{}, &networkError{err: err}
+	}
+
+	res := response{
+		statusCode: resp.StatusCode,
+		etag:       resp.Header.Get(headerETag),
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		if r.resultOnError && result != nil {
+			_ = json.Unmarshal(b, result)
+		}
+
+		return res, newError(resp.StatusCode, b)
+	}
+
+	if result == nil || resp.StatusCode == http.StatusNotModified || resp.StatusCode == http.StatusNoContent {
+		return res, nil
+	}
+
+	if err := json.Unmarshal(b, result); err != nil {
+		return res, fmt.Errorf("unmarshal body: %w", err)
+	}
+
+	return res, nil
+}
+
+func newError(statusCode int, body []byte) *Error {
+	var errorBody ErrorBody
+
+	// The body is empty for 401 and 403.
+	_ = json.Unmarshal(body, &errorBody)
+
+	return &Error{
+		StatusCode: statusCode,
+		Type:       errorBody.Type,
+		Comment:    errorBody.Comment,
+	}
+}
+
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var netErr *networkError
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	return apiErr.StatusCode == http.StatusTooManyRequests ||
+		apiErr.StatusCode >= http.StatusInternalServerError ||
+		apiErr.Type == typeIdempotencyKeyInProgress
+}
+
+// newIdempotencyKey the same for all attempts of the request.
+func newIdempotencyKey() (string, error) {
+	b := make([]byte, 16)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("idempotency key: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,634 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taskmanager/internal/app"
+	"taskmanager/internal/event"
+	"taskmanager/internal/handler"
+	"taskmanager/internal/model"
+	"taskmanager/internal/transport/httpsrv"
+	"taskmanager/internal/worker"
+)
+
+const (
+	testManageUsername = "admin"
+	testManagePassword = "admin"
+)
+
+func newTestServer(t *testing.T, wrap func(http.Handler) http.Handler) *httptest.Server {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	hub := event.NewHub()
+
+	var router http.Handler = httpsrv.Conf{
+		ManageUsername:         testManageUsername,
+		ManagePassword:         testManagePassword,
+		IdempotencyKeyTTLHours: 1,
+		EventSubscriber:        hub,
+		// The test hosts are not resolved.
+		WebhookAllowPrivateNetworks: true,
+	}.NewRouter(context.Background(), event.Storage{Backend: model.NewMemory(), Hub: hub},
+		app.Metrics{MetricsRoute: "/metrics"})
+
+	if wrap != nil {
+		router = wrap(router)
+	}
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+
+	server := newTestServer(t, nil)
+
+	manager := New(server.URL, testManageUsername, testManagePassword)
+
+	userID, err := manager.CreateUser(ctx, "sdkuser", "sdkpassword")
+	require.NoError(t, err)
+
+	_, err = manager.CreateUser(ctx, "sdkuser", "sdkpassword")
+	assert.ErrorIs(t, err, ErrUsernameAlreadyExists)
+
+	_, err = New(server.URL, testManageUsername, "wrong").CreateUser(ctx, "another", "password")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	c := New(server.URL, "sdkuser", "sdkpassword")
+
+	_, err = New(server.URL, "sdkuser", "wrong").CreateTask(ctx, "task")
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	me, err := c.Me(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Me{UserID: userID, Username: "sdkuser"}, me)
+
+	_, err = New(server.URL, "sdkuser", "wrong").Me(ctx)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	taskID, err := c.CreateTask(ctx, "first")
+	require.NoError(t, err)
+
+	task, err := c.GetTask(ctx, taskID)
+	require.NoError(t, err)
+	assert.Equal(t, "first", task.Title)
+	require.NotEmpty(t, task.ETag)
+
+	_, modified, err := c.GetTaskIfNoneMatch(ctx, taskID, task.ETag)
+	require.NoError(t, err)
+	assert.False(t, modified)
+
+	_, err = c.GetTask(ctx, taskID+100)
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+
+	t.Run("changes", func(t *testing.T) {
+		require.NoError(t, c.UpdateTask(ctx, taskID, "second", false, task.ETag))
+
+		err := c.UpdateTask(ctx, taskID, "third", false, task.ETag)
+		assert.ErrorIs(t, err, ErrTaskVersionMismatch)
+
+		var apiErr *Error
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusPreconditionFailed, apiErr.StatusCode)
+
+		title := "third"
+		require.NoError(t, c.PatchTask(ctx, taskID, TaskPatch{Title: &title}, ""))
+
+		err = c.PatchTaskJSON(ctx, taskID, []JSONPatchOperation{
+			{Op: "test", Path: "/title", Value: "wrong"},
+			{Op: "replace", Path: "/completed", Value: true},
+		}, "")
+		assert.ErrorIs(t, err, ErrPatchTestFailed)
+
+		require.NoError(t, c.PatchTaskJSON(ctx, taskID, []JSONPatchOperation{
+			{Op: "test", Path: "/title", Value: "third"},
+			{Op: "replace", Path: "/completed", Value: true},
+		}, ""))
+
+		task, err := c.GetTask(ctx, taskID)
+		require.NoError(t, err)
+		assert.Equal(t, "third", task.Title)
+		assert.True(t, task.Status)
+	})
+
+	t.Run("history", func(t *testing.T) {
+		changes, err := c.GetTaskHistory(ctx, taskID)
+		require.NoError(t, err)
+		assert.NotEmpty(t, changes)
+
+		task, err := c.GetTaskRevision(ctx, taskID, 0)
+		require.NoError(t, err)
+		assert.Equal(t, "first", task.Title)
+
+		_, err = c.GetTaskRevision(ctx, taskID, 100)
+		assert.ErrorIs(t, err, ErrRevisionNotFound)
+
+		require.NoError(t, c.RevertTask(ctx, taskID, 0))
+
+		task, err = c.GetTask(ctx, taskID)
+		require.NoError(t, err)
+		assert.Equal(t, "first", task.Title)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		injectionTaskID, err := c.CreateTaskWithInjection(ctx, "injection")
+		require.NoError(t, err)
+
+		tasks, etag, modified, err := c.GetTasksIfNoneMatch(ctx, "")
+		require.NoError(t, err)
+		assert.True(t, modified)
+		require.Len(t, tasks, 2)
+		assert.Equal(t, injectionTaskID, tasks[1].ID)
+
+		_, _, modified, err = c.GetTasksIfNoneMatch(ctx, etag)
+		require.NoError(t, err)
+		assert.False(t, modified)
+
+		require.NoError(t, c.DeleteTask(ctx, injectionTaskID, true, ""))
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		title := "batch"
+
+		results, err := c.BatchTasks(ctx, []BatchOperation{
+			{Op: BatchOpCreate, Title: &title},
+			{Op: BatchOpDelete, TaskID: taskID + 100},
+		}, false)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		require.NoError(t, BatchError(results[0]))
+		assert.ErrorIs(t, BatchError(results[1]), ErrTaskNotFound)
+
+		batchTaskID := results[0].TaskID
+
+		results, err = c.BatchTasks(ctx, []BatchOperation{
+			{Op: BatchOpCreate, Title: &title},
+			{Op: BatchOpDelete, TaskID: taskID + 100},
+		}, true)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.ErrorIs(t, BatchError(results[0]), ErrOperationRolledBack)
+		assert.ErrorIs(t, BatchError(results[1]), ErrTaskNotFound)
+
+		require.NoError(t, c.DeleteTask(ctx, batchTaskID, true, ""))
+	})
+
+	t.Run("trash", func(t *testing.T) {
+		require.NoError(t, c.DeleteTask(ctx, taskID, false, ""))
+
+		_, err := c.GetTask(ctx, taskID)
+		assert.ErrorIs(t, err, ErrTaskNotFound)
+
+		trash, err := c.GetTrash(ctx)
+		require.NoError(t, err)
+		require.Len(t, trash, 1)
+		assert.Equal(t, taskID, trash[0].ID)
+
+		require.NoError(t, c.RestoreTask(ctx, taskID))
+
+		deleted, err := c.DeleteTasks(ctx, false)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		restored, err := c.RestoreTasks(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), restored)
+
+		var export strings.Builder
+
+		require.NoError(t, c.ExportTasks(ctx, FormatCSV, &export))
+		assert.Contains(t, export.String(),
+			"id,status,title,created,updated,completed,deleted,due,recurrence\n"+strconv.Itoa(taskID)+",")
+
+		var apiErr *Error
+
+		require.ErrorAs(t, c.ExportTasks(ctx, "xml", &export), &apiErr)
+		assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+
+		imported, err := c.ImportTasks(ctx, FormatCSV, strings.NewReader(export.String()), nil, true)
+		require.NoError(t, err)
+		assert.True(t, imported.DryRun)
+		assert.Equal(t, 1, imported.Duplicates)
+
+		imported, err = c.ImportTasks(ctx, FormatJSON, strings.NewReader(`[{"Name": "imported"}]`),
+			map[string]string{"title": "Name"}, false)
+		require.NoError(t, err)
+		require.Len(t, imported.Rows, 1)
+		assert.Equal(t, ImportStatusCreated, imported.Rows[0].Status)
+		require.NoError(t, c.DeleteTask(ctx, imported.Rows[0].TaskID, true, ""))
+
+		_, err = c.ImportTasks(ctx, FormatJSON, strings.NewReader(`{}`), nil, false)
+		assert.ErrorAs(t, err, &apiErr)
+
+		deleted, err = c.DeleteTasks(ctx, true)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+
+		trash, err = c.GetTrash(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, trash)
+	})
+
+	t.Run("webhooks", func(t *testing.T) {
+		webhookID, secret, err := c.CreateWebhook(ctx, "https://example.com/hooks", []string{"task.created"}, "")
+		require.NoError(t, err)
+		assert.NotEmpty(t, secret)
+
+		webhooks, err := c.GetWebhooks(ctx)
+		require.NoError(t, err)
+		require.Len(t, webhooks, 1)
+		assert.Equal(t, webhookID, webhooks[0].ID)
+		assert.True(t, webhooks[0].Active)
+
+		deliveries, err := c.GetWebhookDeliveries(ctx, webhookID)
+		require.NoError(t, err)
+		assert.Empty(t, deliveries)
+
+		manageWebhooks := New(server.URL, testManageUsername, testManagePassword)
+		manageWebhooks.Manager = true
+
+		_, err = manageWebhooks.GetWebhookDeliveries(ctx, webhookID)
+		assert.ErrorIs(t, err, ErrWebhookNotFound)
+
+		require.NoError(t, c.EnableWebhook(ctx, webhookID))
+		require.NoError(t, c.DeleteWebhook(ctx, webhookID))
+		assert.ErrorIs(t, c.DeleteWebhook(ctx, webhookID), ErrWebhookNotFound)
+
+		payload := []byte(`{"event":"task.created"}`)
+		signature := SignWebhookPayload(secret, time.Now(), payload)
+
+		require.NoError(t, VerifyWebhookSignature(secret, signature, payload, time.Minute))
+		assert.ErrorIs(t, VerifyWebhookSignature("wrong", signature, payload, time.Minute), ErrWebhookSignature)
+		assert.ErrorIs(t, VerifyWebhookSignature(secret,
+			SignWebhookPayload(secret, time.Now().Add(-time.Hour), payload), payload, time.Minute),
+			ErrWebhookSignature)
+	})
+
+	t.Run("calendar", func(t *testing.T) {
+		due := time.Date(2023, 5, 2, 9, 30, 0, 0, time.UTC)
+		recurrence := "FREQ=DAILY;COUNT=3"
+
+		taskID, err := c.CreateTask(ctx, "daily")
+		require.NoError(t, err)
+
+		defer func() {
+			require.NoError(t, c.DeleteTask(ctx, taskID, true, ""))
+		}()
+
+		require.NoError(t, c.PatchTask(ctx, taskID, TaskPatch{Due: &due, Recurrence: &recurrence}, ""))
+
+		token, err := c.CreateCalendarToken(ctx)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+
+		require.NoError(t, c.GetCalendar(ctx, token.Token, CalendarVEvent, &buf))
+		assert.Contains(t, buf.String(), "DTSTART:20230502T093000Z\r\n")
+		assert.Contains(t, buf.String(), "RRULE:FREQ=DAILY;COUNT=3\r\n")
+
+		require.NoError(t, c.PatchTaskJSON(ctx, taskID, []JSONPatchOperation{
+			{Op: "remove", Path: "/due"},
+			{Op: "remove", Path: "/recurrence"},
+		}, ""))
+
+		require.NoError(t, c.DeleteCalendarToken(ctx))
+		assert.ErrorIs(t, c.DeleteCalendarToken(ctx), ErrCalendarTokenNotFound)
+
+		var apiErr *Error
+		require.ErrorAs(t, c.GetCalendar(ctx, token.Token, "", &buf), &apiErr)
+		assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	})
+
+	t.Run("notifications", func(t *testing.T) {
+		notifications, err := c.GetNotifications(ctx, true, 0, 10)
+		require.NoError(t, err)
+		assert.Empty(t, notifications.Notifications)
+		assert.Equal(t, 0, notifications.Unread)
+
+		assert.ErrorIs(t, c.ReadNotification(ctx, 1000), ErrNotificationNotFound)
+
+		read, err := c.ReadNotifications(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), read)
+
+		preferences, err := c.SetNotificationPreferences(ctx, []NotificationPreference{
+			{Type: NotificationWebhookDisabled, Enabled: false},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []NotificationPreference{
+			{Type: NotificationTaskReminder, Enabled: true},
+			{Type: NotificationWebhookDisabled, Enabled: false},
+			{Type: NotificationProjectShared, Enabled: true},
+			{Type: NotificationTaskAssigned, Enabled: true},
+			{Type: NotificationTaskCommented, Enabled: true},
+		}, preferences)
+
+		preferences, err = c.GetNotificationPreferences(ctx)
+		require.NoError(t, err)
+		require.Len(t, preferences, 5)
+		assert.False(t, preferences[1].Enabled)
+
+		_, err = c.SetNotificationPreferences(ctx, []NotificationPreference{{Type: "task.comment"}})
+		assert.ErrorIs(t, err, ErrParametersRequired)
+	})
+
+	t.Run("reminders", func(t *testing.T) {
+		taskID, err := c.CreateTask(ctx, "call the bank")
+		require.NoError(t, err)
+
+		defer func() {
+			require.NoError(t, c.DeleteTask(ctx, taskID, true, ""))
+		}()
+
+		beforeMinutes := 30
+
+		reminderID, err := c.CreateReminder(ctx, taskID, NewReminder{
+			BeforeMinutes: &beforeMinutes,
+			Channel:       ReminderInApp,
+		})
+		require.NoError(t, err)
+
+		reminders, err := c.GetReminders(ctx, taskID)
+		require.NoError(t, err)
+		require.Len(t, reminders, 1)
+		assert.Equal(t, reminderID, reminders[0].ID)
+		assert.Equal(t, ReminderInApp, reminders[0].Channel)
+
+		_, err = c.CreateReminder(ctx, taskID, NewReminder{Channel: ReminderEmail})
+		assert.ErrorIs(t, err, ErrParameterRequired)
+
+		require.NoError(t, c.DeleteReminder(ctx, taskID, reminderID))
+		assert.ErrorIs(t, c.DeleteReminder(ctx, taskID, reminderID), ErrReminderNotFound)
+	})
+
+	t.Run("projects", func(t *testing.T) {
+		memberID, err := manager.CreateUser(ctx, "sdkmember", "sdkpassword")
+		require.NoError(t, err)
+
+		member := New(server.URL, "sdkmember", "sdkpassword")
+
+		projectID, err := c.CreateProject(ctx, "home")
+		require.NoError(t, err)
+
+		_, err = member.GetProjectTasks(ctx, projectID)
+		assert.ErrorIs(t, err, ErrProjectNotFound)
+
+		addedID, err := c.AddProjectMember(ctx, projectID, "sdkmember")
+		require.NoError(t, err)
+		assert.Equal(t, memberID, addedID)
+
+		_, err = c.AddProjectMember(ctx, projectID, "nobody")
+		assert.ErrorIs(t, err, ErrUserNotFound)
+
+		projects, err := member.GetProjects(ctx)
+		require.NoError(t, err)
+		require.Len(t, projects, 1)
+		assert.Equal(t, Project{ID: projectID, Name: "home", OwnerID: userID, Created: projects[0].Created}, projects[0])
+
+		members, err := member.GetProjectMembers(ctx, projectID)
+		require.NoError(t, err)
+		require.Len(t, members, 2)
+		assert.Equal(t, "sdkuser", members[0].Username)
+		assert.Equal(t, "sdkmember", members[1].Username)
+
+		taskID, err := c.CreateTask(ctx, "paint the fence")
+		require.NoError(t, err)
+
+		defer func() {
+			require.NoError(t, c.DeleteTask(ctx, taskID, true, ""))
+		}()
+
+		require.NoError(t, c.SetTaskProject(ctx, taskID, projectID))
+
+		tasks, err := member.GetProjectTasks(ctx, projectID)
+		require.NoError(t, err)
+		require.Len(t, tasks, 1)
+		assert.Equal(t, taskID, tasks[0].ID)
+		assert.Equal(t, projectID, tasks[0].ProjectID)
+
+		require.NoError(t, member.RemoveProjectMember(ctx, projectID, memberID))
+
+		_, err = member.GetProjectTasks(ctx, projectID)
+		assert.ErrorIs(t, err, ErrProjectNotFound)
+
+		require.NoError(t, c.DeleteProject(ctx, projectID))
+		assert.ErrorIs(t, c.DeleteProject(ctx, projectID), ErrProjectNotFound)
+
+		task, err := c.GetTask(ctx, taskID)
+		require.NoError(t, err)
+		assert.Zero(t, task.ProjectID)
+	})
+
+	t.Run("events", func(t *testing.T) {
+		err := c.StreamTaskEvents(ctx, "x", func(event Event) error { return nil })
+
+		var apiErr *Error
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+
+		// The events of the subtests before are in the log.
+		errStop := errors.New("stop")
+
+		var events []Event
+
+		err = c.StreamTaskEvents(ctx, "0", func(event Event) error {
+			events = append(events, event)
+			if len(events) == 2 {
+				return errStop
+			}
+
+			return nil
+		})
+		require.ErrorIs(t, err, errStop)
+		assert.Equal(t, "task.created", events[0].Type)
+		assert.Equal(t, taskID, events[0].TaskID)
+		assert.Less(t, events[0].Seq, events[1].Seq)
+	})
+
+	t.Run("websocket", func(t *testing.T) {
+		ws, err := c.DialWebSocket(ctx, "0")
+		require.NoError(t, err)
+
+		defer ws.Close()
+
+		receive := func(messageType string) WebSocketMessage {
+			t.Helper()
+
+			for {
+				msg, err := ws.Receive()
+				require.NoError(t, err)
+
+				if msg.Type == messageType {
+					return msg
+				}
+			}
+		}
+
+		assert.NotEmpty(t, receive(WebSocketConnected).ConnectionID)
+
+		require.NoError(t, ws.Send(WebSocketRequest{ID: "1", Type: WebSocketSubscribe, Topic: WebSocketTopicTasks}))
+
+		// The events of the subtests before are in the log.
+		msg := receive(WebSocketEvent)
+		require.NotNil(t, msg.Event)
+		assert.Equal(t, "task.created", msg.Event.Type)
+
+		require.NoError(t, ws.Send(WebSocketRequest{
+			ID: "2", Type: WebSocketPresenceView, BatchOperation: BatchOperation{TaskID: 1 << 30},
+		}))
+
+		msg = receive(WebSocketError)
+		assert.Equal(t, "2", msg.ID)
+		assert.ErrorIs(t, MessageError(msg), ErrTaskNotFound)
+	})
+
+	require.NoError(t, manager.DeleteUser(ctx, userID))
+	assert.ErrorIs(t, manager.DeleteUser(ctx, userID), ErrUserNotFound)
+}
+
+func TestClientRetries(t *testing.T) {
+	ctx := context.Background()
+
+	var (
+		mu       sync.Mutex
+		failures int
+		requests []*http.Request
+	)
+
+	// The first failures requests get 503.
+	server := newTestServer(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			requests = append(requests, r)
+			fail := failures > 0
+			failures--
+			mu.Unlock()
+
+			if fail {
+				w.WriteHeader(http.StatusServiceUnavailable)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	manager := New(server.URL, testManageUsername, testManagePassword)
+	manager.RetryDelay = time.Millisecond
+
+	reset := func(n int) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		failures = n
+		requests = nil
+	}
+
+	reset(2)
+
+	_, err := manager.CreateUser(ctx, "retryuser", "password")
+	require.NoError(t, err)
+	require.Len(t, requests, 3)
+	assert.NotEmpty(t, requests[0].Header.Get(headerIdempotencyKey))
+	assert.Equal(t, requests[0].Header.Get(headerIdempotencyKey), requests[2].Header.Get(headerIdempotencyKey))
+
+	c := New(server.URL, "retryuser", "password")
+	c.RetryDelay = time.Millisecond
+
+	reset(3)
+
+	_, err = c.GetTasks(ctx)
+
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	assert.Len(t, requests, 3)
+
+	reset(0)
+
+	taskID, err := c.CreateTask(ctx, "task")
+	require.NoError(t, err)
+
+	reset(1)
+
+	title := "changed"
+	err = c.PatchTask(ctx, taskID, TaskPatch{Title: &title}, "")
+	require.ErrorAs(t, err, &apiErr)
+	assert.Len(t, requests, 1)
+
+	reset(1)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	_, err = c.GetTasks(canceled)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Empty(t, requests)
+}
+
+// TestServerConstants the client has its own copies of the constants of the server, they must not drift.
+func TestServerConstants(t *testing.T) {
+	assert.Equal(t,
+		[]string{model.TaskOperationCreate, model.TaskOperationUpdate, model.TaskOperationDelete},
+		[]string{BatchOpCreate, BatchOpUpdate, BatchOpDelete})
+	assert.Equal(t,
+		[]string{model.ReminderChannelWebhook, model.ReminderChannelEmail, model.ReminderChannelInApp},
+		[]string{ReminderWebhook, ReminderEmail, ReminderInApp})
+	assert.Equal(t, model.NotificationTypes, []string{
+		NotificationTaskReminder, NotificationWebhookDisabled, NotificationProjectShared, NotificationTaskAssigned,
+		NotificationTaskCommented,
+	})
+	assert.Equal(t,
+		[]string{
+			handler.ImportStatusCreated, handler.ImportStatusValid, handler.ImportStatusDuplicate,
+			handler.ImportStatusInvalid, handler.ImportStatusFailed,
+		},
+		[]string{ImportStatusCreated, ImportStatusValid, ImportStatusDuplicate, ImportStatusInvalid, ImportStatusFailed})
+	assert.Equal(t,
+		[]string{
+			handler.WebSocketSubscribe, handler.WebSocketUnsubscribe, handler.WebSocketTaskCreate,
+			handler.WebSocketTaskUpdate, handler.WebSocketTaskDelete, handler.WebSocketPresenceView,
+			handler.WebSocketPresenceLeave, handler.WebSocketConnected, handler.WebSocketAck, handler.WebSocketError,
+			handler.WebSocketEvent, handler.WebSocketPresence, handler.WebSocketHeartbeat,
+			handler.WebSocketTopicTasks, handler.WebSocketTopicTaskPrefix, handler.WebSocketTopicProjectPrefix,
+		},
+		[]string{
+			WebSocketSubscribe, WebSocketUnsubscribe, WebSocketTaskCreate,
+			WebSocketTaskUpdate, WebSocketTaskDelete, WebSocketPresenceView,
+			WebSocketPresenceLeave, WebSocketConnected, WebSocketAck, WebSocketError,
+			WebSocketEvent, WebSocketPresence, WebSocketHeartbeat,
+			WebSocketTopicTasks, WebSocketTopicTaskPrefix, WebSocketTopicProjectPrefix,
+		})
+	assert.Equal(t,
+		[]string{worker.HeaderWebhookEvent, worker.HeaderWebhookDelivery, worker.HeaderWebhookSignature},
+		[]string{HeaderWebhookEvent, HeaderWebhookDelivery, HeaderWebhookSignature})
+
+	timestamp := time.Unix(1682942400, 0)
+	assert.Equal(t,
+		worker.SignWebhookPayload("secret", timestamp, []byte(`{"event":"task.created"}`)),
+		SignWebhookPayload("secret", timestamp, []byte(`{"event":"task.created"}`)))
+}
@@ -0,0 +1,176 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// The errors of the API by HTTPError.Type: errors.Is(err, client.ErrTaskNotFound).
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden the user is not found by the credentials on creating a task.
+	ErrForbidden = errors.New("forbidden")
+
+	ErrCalendarTokenNotFound = errors.New("calendar token not found")
+	ErrCommentNotFound       = errors.New("comment not found")
+	ErrImportInvalid         = errors.New("import invalid")
+	ErrNotificationNotFound  = errors.New("notification not found")
+	ErrParameterTooLong      = errors.New("parameter too long")
+	ErrParameterRequired     = errors.New("parameter required")
+	ErrParametersRequired    = errors.New("parameters required")
+	ErrPasswordRequired      = errors.New("password required")
+	ErrPatchInvalid          = errors.New("patch invalid")
+	ErrProjectNotFound       = errors.New("project not found")
+	ErrReminderNotFound      = errors.New("reminder not found")
+	ErrRevisionNotFound      = errors.New("revision not found")
+	ErrTaskAlreadyExists     = errors.New("task already exists")
+	ErrTaskNotFound          = errors.New("task not found")
+	ErrTaskParentInvalid     = errors.New("task parent invalid")
+	ErrUsernameAlreadyExists = errors.New("username already exists")
+	ErrUsernameRequired      = errors.New("username required")
+	ErrUserNotFound          = errors.New("user not found")
+	ErrWebhookNotFound       = errors.New("webhook not found")
+
+	ErrIdempotencyKeyInProgress = errors.New("idempotency key in progress")
+	ErrPatchTestFailed          = errors.New("patch test failed")
+
+	ErrTaskVersionMismatch = errors.New("task version mismatch")
+
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused")
+
+	ErrOperationRolledBack = errors.New("operation rolled back")
+
+	ErrInternal = errors.New("internal error")
+)
+
+// The types of the errors of the API, HTTPError.Type of the server.
+const (
+	typeCalendarTokenNotFound = "CALENDAR_TOKEN_NOT_FOUND"
+	typeCommentNotFound       = "COMMENT_NOT_FOUND"
+	typeImportInvalid         = "IMPORT_INVALID"
+	typeNotificationNotFound  = "NOTIFICATION_NOT_FOUND"
+	typeParameterTooLong      = "PARAMETER_TOO_LONG"
+	typeParameterRequired     = "PARAMETER_REQUIRED"
+	typeParametersRequired    = "PARAMETERS_REQUIRED"
+	typePasswordRequired      = "PASSWORD_REQUIRED"
+	typePatchInvalid          = "PATCH_INVALID"
+	typeProjectNotFound       = "PROJECT_NOT_FOUND"
+	typeReminderNotFound      = "REMINDER_NOT_FOUND"
+	typeRevisionNotFound      = "REVISION_NOT_FOUND"
+	typeTaskAlreadyExists     = "TASK_ALREADY_EXISTS"
+	typeTaskNotFound          = "TASK_NOT_FOUND"
+	typeTaskParentInvalid     = "TASK_PARENT_INVALID"
+	typeUsernameAlreadyExists = "USERNAME_ALREADY_EXISTS"
+	typeUsernameRequired      = "USERNAME_REQUIRED"
+	typeUserNotFound          = "USER_NOT_FOUND"
+	typeWebhookNotFound       = "WEBHOOK_NOT_FOUND"
+
+	typeIdempotencyKeyInProgress = "IDEMPOTENCY_KEY_IN_PROGRESS"
+	typePatchTestFailed          = "PATCH_TEST_FAILED"
+
+	typeTaskVersionMismatch = "TASK_VERSION_MISMATCH"
+
+	typeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+
+	typeIdempotencyKeyReused = "IDEMPOTENCY_KEY_REUSED"
+
+	typeOperationRolledBack = "OPERATION_ROLLED_BACK"
+
+	typeInternalError = "INTERNAL"
+)
+
+var errorsByType = map[string]error{
+	typeCalendarTokenNotFound: ErrCalendarTokenNotFound,
+	typeCommentNotFound:       ErrCommentNotFound,
+	typeImportInvalid:         ErrImportInvalid,
+	typeNotificationNotFound:  ErrNotificationNotFound,
+	typeParameterTooLong:      ErrParameterTooLong,
+	typeParameterRequired:     ErrParameterRequired,
+	typeParametersRequired:    ErrParametersRequired,
+	typePasswordRequired:      ErrPasswordRequired,
+	typePatchInvalid:          ErrPatchInvalid,
+	typeProjectNotFound:       ErrProjectNotFound,
+	typeReminderNotFound:      ErrReminderNotFound,
+	typeRevisionNotFound:      ErrRevisionNotFound,
+	typeTaskAlreadyExists:     ErrTaskAlreadyExists,
+	typeTaskNotFound:          ErrTaskNotFound,
+	typeTaskParentInvalid:     ErrTaskParentInvalid,
+	typeUsernameAlreadyExists: ErrUsernameAlreadyExists,
+	typeUsernameRequired:      ErrUsernameRequired,
+	typeUserNotFound:          ErrUserNotFound,
+	typeWebhookNotFound:       ErrWebhookNotFound,
+
+	typeIdempotencyKeyInProgress: ErrIdempotencyKeyInProgress,
+	typePatchTestFailed:          ErrPatchTestFailed,
+
+	typeTaskVersionMismatch: ErrTaskVersionMismatch,
+
+	typeUnsupportedMediaType: ErrUnsupportedMediaType,
+
+	typeIdempotencyKeyReused: ErrIdempotencyKeyReused,
+
+	typeOperationRolledBack: ErrOperationRolledBack,
+
+	typeInternalError: ErrInternal,
+}
+
+// Error the error response of the API, Type and Comment are empty if the response has no body(401, 403).
+type Error struct {
+	StatusCode int
+	Type       string
+	Comment    string
+}
+
+// networkError the request can be retried.
+type networkError struct {
+	err error
+}
+
+func (e *Error) Error() string {
+	if e.Type == "" {
+		return fmt.Sprintf("status %d", e.StatusCode)
+	}
+
+	return fmt.Sprintf("status %d: %s: %s", e.StatusCode, e.Type, e.Comment)
+}
+
+// Unwrap the error of the Type, nil for an unknown one.
+func (e *Error) Unwrap() error {
+	if err, ok := errorsByType[e.Type]; ok {
+		return err
+	}
+
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+
+	case http.StatusForbidden:
+		return ErrForbidden
+	}
+
+	return nil
+}
+
+// BatchError the error of the operation of BatchTasks, nil if it succeeded.
+func BatchError(res BatchOperationResult) error {
+	if res.Error == nil {
+		return nil
+	}
+
+	return &Error{
+		StatusCode: res.Status,
+		Type:       res.Error.Type,
+		Comment:    res.Error.Comment,
+	}
+}
+
+func (e *networkError) Error() string {
+	return fmt.Sprintf("network: %v", e.err)
+}
+
+func (e *networkError) Unwrap() error {
+	return e.err
+}
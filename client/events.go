@@ -0,0 +1,120 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	mimeEventStream   = "text/event-stream"
+	headerLastEventID = "Last-Event-ID"
+)
+
+// maxLengthEventLine the line of the data of an event with a long title.
+const maxLengthEventLine = 1 << 20
+
+// StreamTaskEvents calls fn for each event of the tasks of the user until the context is canceled, the stream ends
+// or fn returns an error. lastEventID empty - only the new events, otherwise - the events after it, see Event.Seq.
+// The stream is not reconnected, the caller resumes it with the Seq of the last event.
+func (c *Client) StreamTaskEvents(ctx context.Context, lastEventID string, fn func(event Event) error) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+apiV1+"/tasks/events", nil)
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+
+	req.Header.Set("Accept", mimeEventStream)
+	req.SetBasicAuth(c.Username, c.Password)
+
+	if lastEventID != "" {
+		req.Header.Set(headerLastEventID, lastEventID)
+	}
+
+	// The timeout of the client would end the stream.
+	httpClient := *c.HTTPClient
+	httpClient.Timeout = 0
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return &networkError{err: err}
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp.Body)
+
+		return newError(resp.StatusCode, b)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(nil, maxLengthEventLine)
+
+	var id, data string
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// The end of the message, the comments(heartbeats) and retry have no data.
+		if line == "" {
+			if data == "" {
+				continue
+			}
+
+			event, err := parseEvent(id, data)
+			if err != nil {
+				return err
+			}
+
+			id, data = "", ""
+
+			if err := fn(event); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "id":
+			id = value
+		case "data":
+			data = value
+		}
+	}
+
+	if ctx.Err() != nil {
+		return fmt.Errorf("stream: %w", ctx.Err())
+	}
+
+	if err := scanner.Err(); err != nil {
+		return &networkError{err: err}
+	}
+
+	return nil
+}
+
+func parseEvent(id, data string) (Event, error) {
+	var event Event
+
+	if err := json.Unmarshal([]byte(data), &event); err != nil {
+		return Event{}, fmt.Errorf("unmarshal event: %w", err)
+	}
+
+	seq, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return Event{}, fmt.Errorf("event id %q: %w", id, err)
+	}
+
+	event.Seq = seq
+
+	return event, nil
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// The formats of ExportTasks and ImportTasks.
+const (
+	FormatJSON     = "json"
+	FormatCSV      = "csv"
+	FormatMarkdown = "md"
+	FormatTodoTxt  = "todotxt"
+)
+
+// ExportTasks copies all tasks of the user including the trash in the format to w as they are received.
+func (c *Client) ExportTasks(ctx context.Context, format string, w io.Writer) error {
+	u := c.BaseURL + apiV1 + "/tasks/export?" + url.Values{"format": {format}}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return fmt.Errorf("new request: %w", err)
+	}
+
+	req.SetBasicAuth(c.Username, c.Password)
+
+	// The timeout of the client would cut a large export.
+	httpClient := *c.HTTPClient
+	httpClient.Timeout = 0
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return &networkError{err: err}
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp.Body)
+
+		return newError(resp.StatusCode, b)
+	}
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return &networkError{err: err}
+	}
+
+	return nil
+}
+
+// ImportTasks creates the tasks of the file in the format, the duplicates of the titles are not created.
+// columns - the names of the columns title, status, deleted in the file; dryRun - only the validation,
+// nothing is created.
+func (c *Client) ImportTasks(
+	ctx context.Context, format string, r io.Reader, columns map[string]string, dryRun bool,
+) (ImportResult, error) {
+	query := url.Values{
+		"format": {format},
+		"dryRun": {strconv.FormatBool(dryRun)},
+	}
+
+	for column, name := range columns {
+		query.Set("columns["+column+"]", name)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL+apiV1+"/tasks/import?"+query.Encode(), r)
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("new request: %w", err)
+	}
+
+	req.SetBasicAuth(c.Username, c.Password)
+
+	// The body is read once, the import is not retried.
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return ImportResult{}, &networkError{err: err}
+	}
+
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return ImportResult{}, &networkError{err: err}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return ImportResult{}, newError(resp.StatusCode, b)
+	}
+
+	var res ImportResult
+	if err := json.Unmarshal(b, &res); err != nil {
+		return ImportResult{}, fmt.Errorf("unmarshal body: %w", err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const notificationsPath = "/notifications/"
+
+// GetNotifications the newest first with the unread counts. unread - only the unread ones, before - the
+// notificationId of the last notification of the previous page, 0 - the first page; limit 0 - 50.
+func (c *Client) GetNotifications(ctx context.Context, unread bool, before, limit int) (Notifications, error) {
+	query := url.Values{}
+
+	if unread {
+		query.Set("unread", "true")
+	}
+
+	if before > 0 {
+		query.Set("before", strconv.Itoa(before))
+	}
+
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	var res Notifications
+
+	if _, err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   notificationsPath,
+		query:  query,
+	}, &res); err != nil {
+		return Notifications{}, err
+	}
+
+	return res, nil
+}
+
+func (c *Client) ReadNotification(ctx context.Context, notificationID int) error {
+	_, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   notificationsPath + strconv.Itoa(notificationID) + "/read",
+	}, nil)
+
+	return err
+}
+
+// ReadNotifications marks all notifications read, returns the quantity of the ones marked.
+func (c *Client) ReadNotifications(ctx context.Context) (int64, error) {
+	var res readNotificationsResult
+
+	if _, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   notificationsPath + "read",
+	}, &res); err != nil {
+		return 0, err
+	}
+
+	return res.Read, nil
+}
+
+func (c *Client) GetNotificationPreferences(ctx context.Context) ([]NotificationPreference, error) {
+	var preferences []NotificationPreference
+
+	if _, err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   notificationsPath + "preferences",
+	}, &preferences); err != nil {
+		return nil, err
+	}
+
+	return preferences, nil
+}
+
+// SetNotificationPreferences the types missing in preferences are not changed. Returns the preferences of all types.
+func (c *Client) SetNotificationPreferences(
+	ctx context.Context, preferences []NotificationPreference,
+) ([]NotificationPreference, error) {
+	body := updateNotificationPreferencesBody{
+		Preferences: make([]notificationPreferenceBody, 0, len(preferences)),
+	}
+
+	for _, p := range preferences {
+		enabled := p.Enabled
+
+		body.Preferences = append(body.Preferences, notificationPreferenceBody{
+			Type:    p.Type,
+			Enabled: &enabled,
+		})
+	}
+
+	var res []NotificationPreference
+
+	if _, err := c.do(ctx, request{
+		method: http.MethodPut,
+		path:   notificationsPath + "preferences",
+		body:   body,
+	}, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// CreateProject owned by the user, the owner is its first member.
+func (c *Client) CreateProject(ctx context.Context, name string) (int, error) {
+	var res createProjectResult
+
+	if _, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/projects/",
+		body:   createProjectBody{Name: name},
+	}, &res); err != nil {
+		return 0, err
+	}
+
+	return res.ProjectID, nil
+}
+
+// GetProjects the user is a member of.
+func (c *Client) GetProjects(ctx context.Context) ([]Project, error) {
+	var projects []Project
+
+	if _, err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/projects/",
+	}, &projects); err != nil {
+		return nil, err
+	}
+
+	return projects, nil
+}
+
+// DeleteProject by the owner, its tasks leave the project.
+func (c *Client) DeleteProject(ctx context.Context, projectID int) error {
+	_, err := c.do(ctx, request{
+		method: http.MethodDelete,
+		path:   projectPath(projectID),
+	}, nil)
+
+	return err
+}
+
+// GetProjectTasks of all members of the project.
+func (c *Client) GetProjectTasks(ctx context.Context, projectID int) ([]Task, error) {
+	var tasks []Task
+
+	if _, err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   projectPath(projectID) + "/tasks",
+	}, &tasks); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+func (c *Client) GetProjectMembers(ctx context.Context, projectID int) ([]ProjectMember, error) {
+	var members []ProjectMember
+
+	if _, err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   projectPath(projectID) + "/members",
+	}, &members); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// AddProjectMember by the owner, returns the id of the member.
+func (c *Client) AddProjectMember(ctx context.Context, projectID int, username string) (int, error) {
+	var res addProjectMemberResult
+
+	if _, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   projectPath(projectID) + "/members",
+		body:   addProjectMemberBody{Username: username},
+	}, &res); err != nil {
+		return 0, err
+	}
+
+	return res.UserID, nil
+}
+
+// RemoveProjectMember by the owner or the member itself, its tasks leave the project.
+func (c *Client) RemoveProjectMember(ctx context.Context, projectID, userID int) error {
+	_, err := c.do(ctx, request{
+		method: http.MethodDelete,
+		path:   projectPath(projectID) + "/members/" + strconv.Itoa(userID),
+	}, nil)
+
+	return err
+}
+
+// SetTaskProject moves the task to the project, 0 - the task leaves its project.
+func (c *Client) SetTaskProject(ctx context.Context, taskID, projectID int) error {
+	_, err := c.do(ctx, request{
+		method: http.MethodPut,
+		path:   taskPath(taskID) + "/project",
+		body:   setTaskProjectBody{ProjectID: projectID},
+	}, nil)
+
+	return err
+}
+
+func projectPath(projectID int) string {
+	return "/projects/" + strconv.Itoa(projectID)
+}
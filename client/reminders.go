@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// CreateReminder of the task, see NewReminder.
+func (c *Client) CreateReminder(ctx context.Context, taskID int, reminder NewReminder) (int, error) {
+	var res createReminderResult
+
+	if _, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   taskPath(taskID) + "/reminders",
+		body:   reminder,
+	}, &res); err != nil {
+		return 0, err
+	}
+
+	return res.ReminderID, nil
+}
+
+func (c *Client) GetReminders(ctx context.Context, taskID int) ([]Reminder, error) {
+	var reminders []Reminder
+
+	if _, err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   taskPath(taskID) + "/reminders",
+	}, &reminders); err != nil {
+		return nil, err
+	}
+
+	return reminders, nil
+}
+
+func (c *Client) DeleteReminder(ctx context.Context, taskID, reminderID int) error {
+	_, err := c.do(ctx, request{
+		method: http.MethodDelete,
+		path:   taskPath(taskID) + "/reminders/" + strconv.Itoa(reminderID),
+	}, nil)
+
+	return err
+}
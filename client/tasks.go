@@ -0,0 +1,256 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+func (c *Client) CreateTask(ctx context.Context, title string) (int, error) {
+	return c.createTask(ctx, "/task/", title)
+}
+
+// CreateTaskWithInjection the example of the SQL injection, see README.
+func (c *Client) CreateTaskWithInjection(ctx context.Context, title string) (int, error) {
+	return c.createTask(ctx, "/task/create-task-injection", title)
+}
+
+func (c *Client) createTask(ctx context.Context, path, title string) (int, error) {
+	var res CreateTaskResult
+
+	if _, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   path,
+		body:   createTaskBody{Title: title},
+	}, &res); err != nil {
+		return 0, err
+	}
+
+	return res.TaskID, nil
+}
+
+func (c *Client) GetTask(ctx context.Context, taskID int) (Task, error) {
+	task, _, err := c.GetTaskIfNoneMatch(ctx, taskID, "")
+
+	return task, err
+}
+
+// GetTaskIfNoneMatch modified false - the task has the etag, it is not returned.
+func (c *Client) GetTaskIfNoneMatch(ctx context.Context, taskID int, etag string) (Task, bool, error) {
+	var task Task
+
+	res, err := c.do(ctx, request{
+		method:      http.MethodGet,
+		path:        taskPath(taskID),
+		ifNoneMatch: etag,
+	}, &task)
+	if err != nil {
+		return Task{}, false, err
+	}
+
+	return task, res.statusCode != http.StatusNotModified, nil
+}
+
+// UpdateTask replaces the task, its due date and recurrence are removed, PatchTask keeps them.
+// etag - the version the change is based on, empty - any.
+func (c *Client) UpdateTask(ctx context.Context, taskID int, title string, completed bool, etag string) error {
+	_, err := c.do(ctx, request{
+		method:  http.MethodPut,
+		path:    taskPath(taskID),
+		ifMatch: etag,
+		body: updateTaskBody{
+			Title:     title,
+			Completed: &completed,
+		},
+	}, nil)
+
+	return err
+}
+
+// PatchTask merge patch, etag - the version the change is based on, empty - any.
+func (c *Client) PatchTask(ctx context.Context, taskID int, patch TaskPatch, etag string) error {
+	_, err := c.do(ctx, request{
+		method:      http.MethodPatch,
+		path:        taskPath(taskID),
+		contentType: mimeMergePatch,
+		ifMatch:     etag,
+		body:        patch,
+	}, nil)
+
+	return err
+}
+
+// PatchTaskJSON JSON patch, etag - the version the change is based on, empty - any.
+func (c *Client) PatchTaskJSON(ctx context.Context, taskID int, operations []JSONPatchOperation, etag string) error {
+	_, err := c.do(ctx, request{
+		method:      http.MethodPatch,
+		path:        taskPath(taskID),
+		contentType: mimeJSONPatch,
+		ifMatch:     etag,
+		body:        operations,
+	}, nil)
+
+	return err
+}
+
+// DeleteTask moves the task to the trash, permanent - deletes it without the trash.
+func (c *Client) DeleteTask(ctx context.Context, taskID int, permanent bool, etag string) error {
+	_, err := c.do(ctx, request{
+		method:  http.MethodDelete,
+		path:    taskPath(taskID),
+		query:   permanentQuery(permanent),
+		ifMatch: etag,
+	}, nil)
+
+	return err
+}
+
+// RestoreTask from the trash.
+func (c *Client) RestoreTask(ctx context.Context, taskID int) error {
+	_, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   taskPath(taskID) + "/restore",
+	}, nil)
+
+	return err
+}
+
+func (c *Client) GetTaskHistory(ctx context.Context, taskID int) ([]TaskChange, error) {
+	var changes []TaskChange
+
+	if _, err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   taskPath(taskID) + "/history",
+	}, &changes); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// GetTaskRevision the task as it was after the revision, 0 - as created.
+func (c *Client) GetTaskRevision(ctx context.Context, taskID, revision int) (Task, error) {
+	var task Task
+
+	if _, err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   revisionPath(taskID, revision),
+	}, &task); err != nil {
+		return Task{}, err
+	}
+
+	return task, nil
+}
+
+// RevertTask to the revision, the revert is a new revision.
+func (c *Client) RevertTask(ctx context.Context, taskID, revision int) error {
+	_, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   revisionPath(taskID, revision) + "/revert",
+	}, nil)
+
+	return err
+}
+
+func (c *Client) GetTasks(ctx context.Context) ([]Task, error) {
+	tasks, _, _, err := c.GetTasksIfNoneMatch(ctx, "")
+
+	return tasks, err
+}
+
+// GetTasksIfNoneMatch modified false - the list has the etag, it is not returned. The ETag of the list is returned.
+func (c *Client) GetTasksIfNoneMatch(ctx context.Context, etag string) ([]Task, string, bool, error) {
+	var tasks []Task
+
+	res, err := c.do(ctx, request{
+		method:      http.MethodGet,
+		path:        "/tasks/",
+		ifNoneMatch: etag,
+	}, &tasks)
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	return tasks, res.etag, res.statusCode != http.StatusNotModified, nil
+}
+
+// DeleteTasks moves all tasks to the trash, permanent - deletes them and empties the trash.
+func (c *Client) DeleteTasks(ctx context.Context, permanent bool) (int64, error) {
+	var res quantityResult
+
+	if _, err := c.do(ctx, request{
+		method: http.MethodDelete,
+		path:   "/tasks/",
+		query:  permanentQuery(permanent),
+	}, &res); err != nil {
+		return 0, err
+	}
+
+	return res.Quantity, nil
+}
+
+// BatchTasks the errors of the operations are in the results, see BatchError.
+// An atomic batch is rolled back if any operation has an error.
+func (c *Client) BatchTasks(
+	ctx context.Context, operations []BatchOperation, atomic bool,
+) ([]BatchOperationResult, error) {
+	var res batchTasksResult
+
+	if _, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/tasks/batch",
+		body: batchTasksBody{
+			Atomic:     atomic,
+			Operations: operations,
+		},
+		resultOnError: true,
+	}, &res); err != nil && len(res.Results) == 0 {
+		return nil, err
+	}
+
+	return res.Results, nil
+}
+
+func (c *Client) GetTrash(ctx context.Context) ([]DeletedTask, error) {
+	var tasks []DeletedTask
+
+	if _, err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/tasks/trash",
+	}, &tasks); err != nil {
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// RestoreTasks all tasks from the trash.
+func (c *Client) RestoreTasks(ctx context.Context) (int64, error) {
+	var res quantityResult
+
+	if _, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/tasks/trash/restore",
+	}, &res); err != nil {
+		return 0, err
+	}
+
+	return res.Quantity, nil
+}
+
+func taskPath(taskID int) string {
+	return "/task/" + strconv.Itoa(taskID)
+}
+
+func revisionPath(taskID, revision int) string {
+	return taskPath(taskID) + "/history/" + strconv.Itoa(revision)
+}
+
+func permanentQuery(permanent bool) url.Values {
+	if !permanent {
+		return nil
+	}
+
+	return url.Values{"permanent": {"true"}}
+}
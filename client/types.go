@@ -0,0 +1,315 @@
+package client
+
+import "time"
+
+// The operations of BatchTasks.
+const (
+	BatchOpCreate = "create"
+	BatchOpUpdate = "update"
+	BatchOpDelete = "delete"
+)
+
+// The channels of the reminders.
+const (
+	ReminderWebhook = "webhook"
+	ReminderEmail   = "email"
+	ReminderInApp   = "inapp"
+)
+
+// The types of the notifications.
+const (
+	NotificationTaskReminder    = "task.reminder"
+	NotificationWebhookDisabled = "webhook.disabled"
+	NotificationProjectShared   = "project.shared"
+	NotificationTaskAssigned    = "task.assigned"
+	NotificationTaskCommented   = "task.commented"
+)
+
+// The statuses of the rows of ImportTasks.
+const (
+	ImportStatusCreated = "created"
+	// ImportStatusValid dry run: the task would be created.
+	ImportStatusValid     = "valid"
+	ImportStatusDuplicate = "duplicate"
+	ImportStatusInvalid   = "invalid"
+	// ImportStatusFailed the server failed to create the task.
+	ImportStatusFailed = "failed"
+)
+
+// ErrorBody the error of the operation of BatchTasks, of the row of ImportTasks and of the WebSocket message.
+type ErrorBody struct {
+	Type    string `json:"type,omitempty"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// Me the user of the credentials.
+type Me struct {
+	UserID   int    `json:"userId"`
+	Username string `json:"username"`
+}
+
+type Task struct {
+	ID        int       `json:"id,omitempty"`
+	Status    bool      `json:"status"`
+	Title     string    `json:"title"`
+	Created   time.Time `json:"created"`
+	Updated   time.Time `json:"updated"`
+	Completed time.Time `json:"completed"`
+	// Due nil - the task has no due date.
+	Due *time.Time `json:"due,omitempty"`
+	// Recurrence RRULE without the prefix, FREQ=WEEKLY;BYDAY=MO, the task repeats from Due.
+	Recurrence string `json:"recurrence,omitempty"`
+	// ProjectID 0 - the task is not in a project.
+	ProjectID int `json:"projectId,omitempty"`
+	// ETag the version of the task for the changes, set by GetTask and GetTasks.
+	ETag string `json:"etag,omitempty"`
+}
+
+// CreateTaskResult the body of the response of the creation of the task.
+type CreateTaskResult struct {
+	TaskID int `json:"taskId"`
+}
+
+type DeletedTask struct {
+	Task
+	Deleted time.Time `json:"deleted"`
+}
+
+type TaskChange struct {
+	Revision int       `json:"revision"`
+	Field    string    `json:"field"`
+	Old      string    `json:"old"`
+	New      string    `json:"new"`
+	Actor    string    `json:"actor"`
+	Changed  time.Time `json:"changed"`
+}
+
+// BatchOperation op - BatchOpCreate(Title), BatchOpUpdate(TaskID, Title, Completed, IfMatch) or
+// BatchOpDelete(TaskID, Permanent, IfMatch).
+type BatchOperation struct {
+	Op        string  `json:"op"`
+	TaskID    int     `json:"taskId,omitempty"`
+	Title     *string `json:"title,omitempty"`
+	Completed *bool   `json:"completed,omitempty"`
+	IfMatch   string  `json:"ifMatch,omitempty"`
+	Permanent bool    `json:"permanent,omitempty"`
+}
+
+type BatchOperationResult struct {
+	Status int        `json:"status"`
+	TaskID int        `json:"taskId,omitempty"`
+	Error  *ErrorBody `json:"error,omitempty"`
+}
+
+type ImportResult struct {
+	DryRun     bool `json:"dryRun"`
+	Created    int  `json:"created"`
+	Duplicates int  `json:"duplicates"`
+	Failed     int  `json:"failed"`
+	// Rows all rows of the file in order.
+	Rows []ImportRow `json:"rows"`
+}
+
+type ImportRow struct {
+	Row    int        `json:"row"`
+	Title  string     `json:"title,omitempty"`
+	Status string     `json:"status"`
+	TaskID int        `json:"taskId,omitempty"`
+	Error  *ErrorBody `json:"error,omitempty"`
+}
+
+type Webhook struct {
+	ID     int      `json:"webhookId"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// Active false - disabled after too many failed attempts in a row.
+	Active bool `json:"active"`
+	// Failures the failed attempts in a row.
+	Failures int       `json:"failures"`
+	Created  time.Time `json:"created"`
+}
+
+type WebhookDelivery struct {
+	ID        int    `json:"deliveryId"`
+	WebhookID int    `json:"webhookId"`
+	EventID   string `json:"eventId"`
+	Event     string `json:"event"`
+	Status    string `json:"status"`
+	Attempts  int    `json:"attempts"`
+	// ResponseStatus of the last attempt, 0 - no response.
+	ResponseStatus int       `json:"responseStatus,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	NextAttempt    time.Time `json:"nextAttempt"`
+	Created        time.Time `json:"created"`
+}
+
+// Event the payload of the webhook deliveries and of the events of StreamTaskEvents.
+type Event struct {
+	ID      string    `json:"id"`
+	Type    string    `json:"event"`
+	Created time.Time `json:"created"`
+	// UserID the owner of the task or the deleted user.
+	UserID int `json:"userId"`
+	TaskID int `json:"taskId,omitempty"`
+	// ProjectID the project of the task, the event is also in the logs of its members.
+	ProjectID int `json:"projectId,omitempty"`
+	// Task the task after the change, nil for task.deleted and user.deleted.
+	Task *Task `json:"task,omitempty"`
+	// Reminder the sent reminder of task.reminder.
+	Reminder *Reminder `json:"reminder,omitempty"`
+	// Seq the position in the event log, the id of the event of the stream.
+	Seq int64 `json:"-"`
+}
+
+// NewReminder RemindAt or BeforeMinutes, Channel - ReminderWebhook, ReminderEmail with Email or ReminderInApp.
+type NewReminder struct {
+	RemindAt *time.Time `json:"remindAt,omitempty"`
+	// BeforeMinutes before the due date of the task, it follows the changes of the due date.
+	BeforeMinutes *int   `json:"beforeMinutes,omitempty"`
+	Channel       string `json:"channel"`
+	Email         string `json:"email,omitempty"`
+}
+
+type Reminder struct {
+	ID     int `json:"reminderId"`
+	TaskID int `json:"taskId"`
+	// RemindAt the time of the reminder, nil - BeforeMinutes before the due date of the task.
+	RemindAt      *time.Time `json:"remindAt,omitempty"`
+	BeforeMinutes *int       `json:"beforeMinutes,omitempty"`
+	Channel       string     `json:"channel"`
+	Email         string     `json:"email,omitempty"`
+	Status        string     `json:"status"`
+	// FireAt nil - the task has no due date, the reminder waits for it.
+	FireAt   *time.Time `json:"fireAt,omitempty"`
+	Attempts int        `json:"attempts"`
+	// Error of the last failed attempt.
+	Error   string     `json:"error,omitempty"`
+	Sent    *time.Time `json:"sent,omitempty"`
+	Created time.Time  `json:"created"`
+}
+
+// CalendarToken the token is returned only by CreateCalendarToken.
+type CalendarToken struct {
+	Token string `json:"token"`
+	// Path of the feed on the server.
+	Path string `json:"path"`
+}
+
+type Notification struct {
+	ID    int    `json:"notificationId"`
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	// TaskID 0 - not of a task or the task is deleted.
+	TaskID int `json:"taskId,omitempty"`
+	// Read nil - unread.
+	Read    *time.Time `json:"read"`
+	Created time.Time  `json:"created"`
+}
+
+// Project the members see the tasks of each other in the project, the owner manages the members.
+type Project struct {
+	ID      int       `json:"projectId"`
+	Name    string    `json:"name"`
+	OwnerID int       `json:"ownerId"`
+	Created time.Time `json:"created"`
+}
+
+type ProjectMember struct {
+	UserID   int       `json:"userId"`
+	Username string    `json:"username"`
+	Joined   time.Time `json:"joined"`
+}
+
+type NotificationPreference struct {
+	Type    string `json:"type"`
+	Enabled bool   `json:"enabled"`
+}
+
+type Notifications struct {
+	Notifications []Notification `json:"notifications"`
+	// Unread the unread notifications of the user, not only of the page.
+	Unread       int            `json:"unread"`
+	UnreadByType map[string]int `json:"unreadByType"`
+}
+
+// The bodies and the results of the requests.
+type (
+	createUserBody struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+
+	createUserResult struct {
+		UserID int `json:"userId"`
+	}
+
+	createTaskBody struct {
+		Title string `json:"title"`
+	}
+
+	updateTaskBody struct {
+		Title     string `json:"title"`
+		Completed *bool  `json:"completed"`
+	}
+
+	quantityResult struct {
+		Quantity int64 `json:"quantity"`
+	}
+
+	batchTasksBody struct {
+		Atomic     bool             `json:"atomic"`
+		Operations []BatchOperation `json:"operations"`
+	}
+
+	batchTasksResult struct {
+		Results []BatchOperationResult `json:"results"`
+	}
+
+	createWebhookBody struct {
+		URL    string   `json:"url"`
+		Events []string `json:"events"`
+		Secret string   `json:"secret,omitempty"`
+	}
+
+	createWebhookResult struct {
+		WebhookID int    `json:"webhookId"`
+		Secret    string `json:"secret"`
+	}
+
+	createReminderResult struct {
+		ReminderID int `json:"reminderId"`
+	}
+
+	createProjectBody struct {
+		Name string `json:"name"`
+	}
+
+	createProjectResult struct {
+		ProjectID int `json:"projectId"`
+	}
+
+	addProjectMemberBody struct {
+		Username string `json:"username"`
+	}
+
+	addProjectMemberResult struct {
+		UserID int `json:"userId"`
+	}
+
+	setTaskProjectBody struct {
+		ProjectID int `json:"projectId"`
+	}
+
+	readNotificationsResult struct {
+		Read int64 `json:"read"`
+	}
+
+	notificationPreferenceBody struct {
+		Type    string `json:"type"`
+		Enabled *bool  `json:"enabled"`
+	}
+
+	updateNotificationPreferencesBody struct {
+		Preferences []notificationPreferenceBody `json:"preferences"`
+	}
+)
@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+)
+
+// CreateUser the client credentials are of the manager.
+func (c *Client) CreateUser(ctx context.Context, username, password string) (int, error) {
+	var res createUserResult
+
+	if _, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/manage/user",
+		body: createUserBody{
+			Username: username,
+			Password: password,
+		},
+	}, &res); err != nil {
+		return 0, err
+	}
+
+	return res.UserID, nil
+}
+
+// Me the user of the client credentials, ErrForbidden for unknown ones.
+func (c *Client) Me(ctx context.Context) (Me, error) {
+	var res Me
+
+	if _, err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   "/me",
+	}, &res); err != nil {
+		return Me{}, err
+	}
+
+	return res, nil
+}
+
+// DeleteUser the client credentials are of the manager.
+func (c *Client) DeleteUser(ctx context.Context, userID int) error {
+	_, err := c.do(ctx, request{
+		method: http.MethodDelete,
+		path:   "/manage/user/" + strconv.Itoa(userID),
+	}, nil)
+
+	return err
+}
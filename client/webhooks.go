@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The headers of the webhook deliveries.
+const (
+	HeaderWebhookEvent     = "X-Webhook-Event"
+	HeaderWebhookDelivery  = "X-Webhook-Delivery"
+	HeaderWebhookSignature = "X-Webhook-Signature"
+)
+
+var ErrWebhookSignature = errors.New("invalid webhook signature")
+
+// CreateWebhook secret empty - generated, the secret is returned only here.
+// Manager true - the webhook of the manager, it gets the events of all users.
+func (c *Client) CreateWebhook(ctx context.Context, url string, events []string, secret string) (int, string, error) {
+	var res createWebhookResult
+
+	if _, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   c.webhooksPath() + "/",
+		body: createWebhookBody{
+			URL:    url,
+			Events: events,
+			Secret: secret,
+		},
+	}, &res); err != nil {
+		return 0, "", err
+	}
+
+	return res.WebhookID, res.Secret, nil
+}
+
+func (c *Client) GetWebhooks(ctx context.Context) ([]Webhook, error) {
+	var webhooks []Webhook
+
+	if _, err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   c.webhooksPath() + "/",
+	}, &webhooks); err != nil {
+		return nil, err
+	}
+
+	return webhooks, nil
+}
+
+func (c *Client) DeleteWebhook(ctx context.Context, webhookID int) error {
+	_, err := c.do(ctx, request{
+		method: http.MethodDelete,
+		path:   c.webhookPath(webhookID),
+	}, nil)
+
+	return err
+}
+
+// EnableWebhook after it is disabled by the failed deliveries.
+func (c *Client) EnableWebhook(ctx context.Context, webhookID int) error {
+	_, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   c.webhookPath(webhookID) + "/enable",
+	}, nil)
+
+	return err
+}
+
+// GetWebhookDeliveries the delivery log, the newest first.
+func (c *Client) GetWebhookDeliveries(ctx context.Context, webhookID int) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+
+	if _, err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   c.webhookPath(webhookID) + "/deliveries",
+	}, &deliveries); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// VerifyWebhookSignature of the delivery received by the webhook, signature - X-Webhook-Signature.
+// tolerance the maximum age of the signature, 0 - any.
+func VerifyWebhookSignature(secret, signature string, payload []byte, tolerance time.Duration) error {
+	t, _, ok := strings.Cut(strings.TrimPrefix(signature, "t="), ",")
+	if !ok {
+		return fmt.Errorf("%w: format", ErrWebhookSignature)
+	}
+
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: timestamp: %v", ErrWebhookSignature, err)
+	}
+
+	timestamp := time.Unix(unix, 0)
+
+	if tolerance > 0 && time.Since(timestamp).Abs() > tolerance {
+		return fmt.Errorf("%w: timestamp out of tolerance", ErrWebhookSignature)
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(SignWebhookPayload(secret, timestamp, payload))) {
+		return ErrWebhookSignature
+	}
+
+	return nil
+}
+
+// SignWebhookPayload the signature of the payload of the delivery: t=timestamp,v1=HMAC-SHA256(secret, t.payload).
+func SignWebhookPayload(secret string, timestamp time.Time, payload []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t + "."))
+	mac.Write(payload)
+
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (c *Client) webhooksPath() string {
+	if c.Manager {
+		return "/manage/webhooks"
+	}
+
+	return "/webhooks"
+}
+
+func (c *Client) webhookPath(webhookID int) string {
+	return c.webhooksPath() + "/" + strconv.Itoa(webhookID)
+}
@@ -0,0 +1,187 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// The messages of the client.
+const (
+	WebSocketSubscribe     = "subscribe"
+	WebSocketUnsubscribe   = "unsubscribe"
+	WebSocketTaskCreate    = "task.create"
+	WebSocketTaskUpdate    = "task.update"
+	WebSocketTaskDelete    = "task.delete"
+	WebSocketPresenceView  = "presence.view"
+	WebSocketPresenceLeave = "presence.leave"
+)
+
+// The messages of the server.
+const (
+	WebSocketConnected = "connected"
+	WebSocketAck       = "ack"
+	WebSocketError     = "error"
+	WebSocketEvent     = "event"
+	WebSocketPresence  = "presence"
+	WebSocketHeartbeat = "heartbeat"
+)
+
+// The topics of the subscriptions: all tasks of the user, one task, "task:24", or the tasks of the project of the
+// member, "project:5".
+const (
+	WebSocketTopicTasks         = "tasks"
+	WebSocketTopicTaskPrefix    = "task:"
+	WebSocketTopicProjectPrefix = "project:"
+)
+
+// WebSocketRequest the message of the client, id is returned in the ack or the error.
+type WebSocketRequest struct {
+	ID   string `json:"id,omitempty"`
+	Type string `json:"type"`
+	// Topic of subscribe and unsubscribe.
+	Topic string `json:"topic,omitempty"`
+	// BatchOperation the fields of the mutations and presence, op is set by the type.
+	BatchOperation
+}
+
+// WebSocketMessage the message of the server.
+type WebSocketMessage struct {
+	Type string `json:"type"`
+	// ID of the request of the ack and the error.
+	ID     string `json:"id,omitempty"`
+	Status int    `json:"status,omitempty"`
+	TaskID int    `json:"taskId,omitempty"`
+	Topic  string `json:"topic,omitempty"`
+	// ConnectionID of connected, the viewers are identified by it.
+	ConnectionID string `json:"connectionId,omitempty"`
+	// LastEventID the position in the event log of connected and event, lastEventId of the reconnection.
+	LastEventID int64  `json:"lastEventId,omitempty"`
+	Event       *Event `json:"event,omitempty"`
+	// Viewers of the task of presence and of the acks of presence and of the subscriptions to the task.
+	Viewers []WebSocketViewer `json:"viewers,omitempty"`
+	Error   *ErrorBody        `json:"error,omitempty"`
+}
+
+type WebSocketViewer struct {
+	ConnectionID string    `json:"connectionId"`
+	Username     string    `json:"username"`
+	Since        time.Time `json:"since"`
+}
+
+// WebSocket the connection of DialWebSocket, Send and Receive can be called from different goroutines.
+type WebSocket struct {
+	conn *websocket.Conn
+}
+
+// DialWebSocket connects to /api/v1/ws. lastEventID empty - the events from the connection on,
+// otherwise - the events after it, see WebSocketMessage.LastEventID. The connection is not reconnected.
+// The handshake is refused without the status, the credentials are checked by the other methods.
+func (c *Client) DialWebSocket(ctx context.Context, lastEventID string) (*WebSocket, error) {
+	u, err := url.Parse(c.BaseURL + apiV1 + "/ws")
+	if err != nil {
+		return nil, fmt.Errorf("parse url: %w", err)
+	}
+
+	origin := u.Scheme + "://" + u.Host
+
+	port := u.Port()
+	if port == "" {
+		port = "80"
+
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+
+	address := net.JoinHostPort(u.Hostname(), port)
+
+	var conn net.Conn
+
+	if u.Scheme == "https" {
+		u.Scheme = "wss"
+		conn, err = (&tls.Dialer{}).DialContext(ctx, "tcp", address)
+	} else {
+		u.Scheme = "ws"
+		conn, err = (&net.Dialer{}).DialContext(ctx, "tcp", address)
+	}
+
+	if err != nil {
+		return nil, &networkError{err: err}
+	}
+
+	if lastEventID != "" {
+		u.RawQuery = url.Values{"lastEventId": {lastEventID}}.Encode()
+	}
+
+	config, err := websocket.NewConfig(u.String(), origin)
+	if err != nil {
+		conn.Close()
+
+		return nil, fmt.Errorf("config: %w", err)
+	}
+
+	config.Header.Set("Authorization",
+		"Basic "+base64.StdEncoding.EncodeToString([]byte(c.Username+":"+c.Password)))
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	ws, err := websocket.NewClient(config, conn)
+	if err != nil {
+		conn.Close()
+
+		return nil, fmt.Errorf("handshake: %w", err)
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+
+	return &WebSocket{conn: ws}, nil
+}
+
+func (ws *WebSocket) Send(req WebSocketRequest) error {
+	if err := websocket.JSON.Send(ws.conn, req); err != nil {
+		return fmt.Errorf("send: %w", err)
+	}
+
+	return nil
+}
+
+// Receive the next message, blocks until it comes or the connection is closed.
+func (ws *WebSocket) Receive() (WebSocketMessage, error) {
+	var msg WebSocketMessage
+
+	if err := websocket.JSON.Receive(ws.conn, &msg); err != nil {
+		return WebSocketMessage{}, fmt.Errorf("receive: %w", err)
+	}
+
+	return msg, nil
+}
+
+func (ws *WebSocket) Close() error {
+	if err := ws.conn.Close(); err != nil {
+		return fmt.Errorf("close: %w", err)
+	}
+
+	return nil
+}
+
+// MessageError the error of the request of the message of the type error, nil for the other types.
+func MessageError(msg WebSocketMessage) error {
+	if msg.Type != WebSocketError || msg.Error == nil {
+		return nil
+	}
+
+	return &Error{
+		StatusCode: msg.Status,
+		Type:       msg.Error.Type,
+		Comment:    msg.Error.Comment,
+	}
+}
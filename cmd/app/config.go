@@ -0,0 +1,22 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+const configValidate = "validate"
+
+var errConfigUsage = errors.New("usage: apitm config validate")
+
+// runConfig the config is loaded and validated before the command runs.
+func runConfig(configFileName string, args []string, stdout io.Writer) error {
+	if len(args) != 1 || args[0] != configValidate {
+		return errConfigUsage
+	}
+
+	fmt.Fprintf(stdout, "config is valid: %s\n", configFileName)
+
+	return nil
+}
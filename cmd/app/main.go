@@ -2,38 +2,55 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"os"
-	"os/signal"
-	"syscall"
 
-	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 
 	_ "github.com/lib/pq"
 
-	"taskmanager/internal/app"
 	"taskmanager/internal/config"
-	"taskmanager/internal/db"
 	"taskmanager/internal/loggers"
-	"taskmanager/internal/model"
-	"taskmanager/internal/transport/httpsrv"
 
 	_ "taskmanager/docs"
 )
 
 const (
-	loggerFileName = "logs/app.log"
-	configFileName = "configs/conf.toml"
+	loggerFileName        = "logs/app.log"
+	defaultConfigFileName = "configs/conf.toml"
 )
 
-const prometheusRoute = "/metrics"
-
 const (
 	loggerTimeFormat = "06-01-02 15:04:05"
 )
 
-const httpServerMaxHeaderBytes = 1 << 16
+const (
+	commandServe   = "serve"
+	commandMigrate = "migrate"
+	commandUser    = "user"
+	commandConfig  = "config"
+	commandVersion = "version"
+)
+
+const usage = `Usage: apitm [--config file] [command]
+
+Commands:
+  serve                                          run the HTTP server (default)
+  migrate up|down [steps]|status                 apply or roll back the migrations
+  user create [--password password] username     without --password it is read from stdin
+  user delete username
+  user list
+  user reset-password [--password password] username
+  config validate
+  version
+
+Flags:
+`
+
+var errUnknownCommand = errors.New("unknown command")
 
 // @title API Task Manager
 // @version 1.0
@@ -48,71 +65,75 @@ const httpServerMaxHeaderBytes = 1 << 16
 
 // @securityDefinitions.basic BasicAuth
 func main() {
-	conf, err := config.GetFromFile(configFileName)
-	if err != nil {
-		log.Fatalf("get config: %v", err)
-	}
+	configFileName := flag.String("config", defaultConfigFileName, "the config file")
 
-	loggerConf := loggers.Conf{
-		FileName:    conf.Logger.FileName,
-		TimeFormat:  loggerTimeFormat,
-		MaxSizeMb:   conf.Logger.MaxSizeMb,
-		MaxBackups:  conf.Logger.MaxBackups,
-		MaxAgeDays:  conf.Logger.MaxAgeDays,
-		MultiWriter: true, // stdout + file
-		Level:       logrus.InfoLevel,
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
 	}
 
-	logger := loggerConf.CreateLoggerWithRotate(loggerFileName)
+	flag.Parse()
+
+	if err := run(context.Background(), *configFileName, flag.Args(), os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "apitm: %v\n", err)
 
-	postgresConf := db.Conf{
-		DockerEnvConn: "DB_CONN",
-		ConnAddress:   conf.Postgres.ConnAddress,
-		MaxOpenConns:  conf.Postgres.MaxOpenConns,
-		MaxIdleConns:  conf.Postgres.MaxIdleConns,
+		if errors.Is(err, errUnknownCommand) {
+			flag.Usage()
+		}
+
+		os.Exit(1)
 	}
+}
 
-	postgresPool, err := postgresConf.CreatePool(logger)
-	if err != nil {
-		logger.Fatalf("create postgres pool: %v", err)
+// run the command, the server without arguments.
+func run(ctx context.Context, configFileName string, args []string, stdin io.Reader, stdout io.Writer) error {
+	command := commandServe
+
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
 	}
 
-	postgres := model.Postgres{
-		Pool:         postgresPool,
-		QueryTimeout: conf.Postgres.QueryTimeout,
-		Logger:       logger,
+	if command == commandVersion {
+		printVersion(stdout)
+
+		return nil
 	}
 
-	serverConf := httpsrv.Conf{
-		Port:               conf.Server.Port,
-		ManageUsername:     conf.Server.ManageUsername,
-		ManagePassword:     conf.Server.ManagePassword,
-		Mode:               gin.ReleaseMode,
-		MaxHeaderBytes:     httpServerMaxHeaderBytes,
-		ReadTimeoutSecond:  conf.Server.ReadTimeoutSeconds,
-		WriteTimeoutSecond: conf.Server.WriteTimeoutSeconds,
-		MaxShutdownTime:    conf.Server.MaxShutdownTime,
-		CORS: httpsrv.CORS{
-			AllowHeaders: conf.Server.CORSAllowHeaders,
-			AllowMethods: conf.Server.CORSAllowMethods,
-			AllowOrigins: conf.Server.CORSAllowOrigins,
-		},
+	conf, err := config.GetFromFile(configFileName)
+	if err != nil {
+		return fmt.Errorf("get config: %s: %w", configFileName, err)
 	}
 
-	metrics := app.CreatePrometheusMetrics(prometheusRoute)
+	switch command {
+	case commandServe:
+		runServe(conf, createLogger(conf, true))
 
-	ctx, cancel := context.WithCancel(context.Background())
+		return nil
 
-	go func() {
-		sigChan := make(chan os.Signal, 1)
-		signal.Notify(sigChan, syscall.SIGHUP, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGTERM)
+	case commandMigrate:
+		return runMigrate(ctx, conf, createLogger(conf, false), args, stdout)
 
-		<-sigChan
+	case commandUser:
+		return runUser(ctx, conf, createLogger(conf, false), args, stdin, stdout)
+
+	case commandConfig:
+		return runConfig(configFileName, args, stdout)
+	}
 
-		cancel()
-	}()
+	return fmt.Errorf("%w: %s", errUnknownCommand, command)
+}
 
-	if err := serverConf.RunHTTPServer(ctx, postgres, metrics, logger); err != nil {
-		logger.Fatalf("run http server: %v", err)
+// createLogger multiWriter - stdout and the file, otherwise the file only, not to mix with the command output.
+func createLogger(conf *config.Conf, multiWriter bool) *logrus.Logger {
+	loggerConf := loggers.Conf{
+		FileName:    conf.Logger.FileName,
+		TimeFormat:  loggerTimeFormat,
+		MaxSizeMb:   conf.Logger.MaxSizeMb,
+		MaxBackups:  conf.Logger.MaxBackups,
+		MaxAgeDays:  conf.Logger.MaxAgeDays,
+		MultiWriter: multiWriter,
+		Level:       logrus.InfoLevel,
 	}
+
+	return loggerConf.CreateLoggerWithRotate(loggerFileName)
 }
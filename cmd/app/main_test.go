@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+
+	// The log file is created in the working directory.
+	dir := t.TempDir()
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	conf, err := os.ReadFile(filepath.Join(wd, "../..", defaultConfigFileName))
+	require.NoError(t, err)
+
+	require.NoError(t, os.Chdir(dir))
+
+	defer func() {
+		require.NoError(t, os.Chdir(wd))
+	}()
+
+	conf = bytes.Replace(conf, []byte(`Type = "postgres"`), []byte(`Type = "sqlite"`), 1)
+	configFileName := filepath.Join(dir, "conf.toml")
+	require.NoError(t, os.WriteFile(configFileName, conf, 0o600))
+
+	cases := []struct {
+		name     string
+		args     []string
+		stdin    string
+		expected string
+		err      error
+	}{
+		{
+			name:     "version",
+			args:     []string{"version"},
+			expected: "apitm dev",
+		},
+		{
+			name:     "config_validate",
+			args:     []string{"config", "validate"},
+			expected: "config is valid",
+		},
+		{
+			name: "config_usage",
+			args: []string{"config"},
+			err:  errConfigUsage,
+		},
+		{
+			name: "unknown_command",
+			args: []string{"unknown"},
+			err:  errUnknownCommand,
+		},
+		{
+			name: "user_usage",
+			args: []string{"user", "create"},
+			err:  errUserUsage,
+		},
+		{
+			name:     "user_create_stdin",
+			args:     []string{"user", "create", "alice"},
+			stdin:    "password1\n",
+			expected: "user created: alice, id 1",
+		},
+		{
+			name:     "user_create_flag",
+			args:     []string{"user", "create", "--password", "password2", "bob"},
+			expected: "user created: bob, id 2",
+		},
+		{
+			name: "user_create_no_password",
+			args: []string{"user", "create", "carol"},
+			err:  errPasswordRequired,
+		},
+		{
+			name:     "user_list",
+			args:     []string{"user", "list"},
+			expected: "ID  USERNAME\n1   alice\n2   bob",
+		},
+		{
+			name:     "user_reset_password",
+			args:     []string{"user", "reset-password", "--password", "password3", "alice"},
+			expected: "password reset: alice",
+		},
+		{
+			name:     "user_delete",
+			args:     []string{"user", "delete", "bob"},
+			expected: "user deleted: bob, id 2",
+		},
+		{
+			name:     "migrate_status",
+			args:     []string{"migrate", "status"},
+			expected: "0001_init\t",
+		},
+		{
+			name: "migrate_usage",
+			args: []string{"migrate", "down", "0"},
+			err:  errMigrateUsage,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout bytes.Buffer
+
+			err := run(ctx, configFileName, tt.args, strings.NewReader(tt.stdin), &stdout)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Contains(t, stdout.String(), tt.expected)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"taskmanager/internal/config"
+	"taskmanager/internal/migrate"
+)
+
+const (
+	migrateUp     = "up"
+	migrateDown   = "down"
+	migrateStatus = "status"
+)
+
+var errMigrateUsage = errors.New("usage: apitm migrate up|down [steps]|status")
+
+func createMigrator(conf *config.Conf, logger *logrus.Logger) (migrate.Migrator, error) {
+	switch conf.Storage.Type {
+	case storageTypeMemory:
+		return migrate.Migrator{}, errMemoryStorage
+
+	case storageTypeSQLite:
+		sqlite, err := createSQLite(conf, logger)
+		if err != nil {
+			return migrate.Migrator{}, fmt.Errorf("create sqlite: %w", err)
+		}
+
+		return sqlite.Migrator()
+	}
+
+	postgres, err := createPostgres(conf, logger)
+	if err != nil {
+		return migrate.Migrator{}, fmt.Errorf("create postgres: %w", err)
+	}
+
+	return postgres.Migrator()
+}
+
+// runMigrate apitm migrate up|down [steps]|status, down rolls back one migration by default.
+func runMigrate(ctx context.Context, conf *config.Conf, logger *logrus.Logger, args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errMigrateUsage
+	}
+
+	migrator, err := createMigrator(conf, logger)
+	if err != nil {
+		return fmt.Errorf("create migrator: %w", err)
+	}
+
+	defer func() {
+		if err := migrator.Pool.Close(); err != nil {
+			logger.Errorf("migrate: pool close: %v", err)
+		}
+	}()
+
+	switch args[0] {
+	case migrateUp:
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return fmt.Errorf("up: %w", err)
+		}
+
+		fmt.Fprintf(stdout, "applied: %d\n", applied)
+
+	case migrateDown:
+		steps := 1
+
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return errMigrateUsage
+			}
+		}
+
+		rolledBack, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return fmt.Errorf("down: %w", err)
+		}
+
+		fmt.Fprintf(stdout, "rolled back: %d\n", rolledBack)
+
+	case migrateStatus:
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return fmt.Errorf("status: %w", err)
+		}
+
+		for _, status := range statuses {
+			applied := "pending"
+			if !status.Applied.IsZero() {
+				applied = status.Applied.Format(time.RFC3339)
+			}
+
+			fmt.Fprintf(stdout, "%04d_%s\t%s\n", status.Version, status.Name, applied)
+		}
+
+	default:
+		return errMigrateUsage
+	}
+
+	return nil
+}
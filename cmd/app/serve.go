@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"taskmanager/internal/app"
+	"taskmanager/internal/config"
+	"taskmanager/internal/event"
+	"taskmanager/internal/model"
+	"taskmanager/internal/security"
+	"taskmanager/internal/transport/grpcsrv"
+	"taskmanager/internal/transport/httpsrv"
+	"taskmanager/internal/worker"
+)
+
+const prometheusRoute = "/metrics"
+
+const httpServerMaxHeaderBytes = 1 << 16
+
+func runServe(conf *config.Conf, logger *logrus.Logger) {
+	storage, err := createStorage(context.Background(), conf, logger)
+	if err != nil {
+		logger.Fatalf("create storage: %v", err)
+	}
+
+	// Wakes the event streams of the users after their events are saved.
+	hub := event.NewHub()
+
+	serverConf := httpsrv.Conf{
+		Port:               conf.Server.Port,
+		ManageUsername:     conf.Server.ManageUsername,
+		ManagePassword:     conf.Server.ManagePassword,
+		Mode:               gin.ReleaseMode,
+		MaxHeaderBytes:     httpServerMaxHeaderBytes,
+		ReadTimeoutSecond:  conf.Server.ReadTimeoutSeconds,
+		WriteTimeoutSecond: conf.Server.WriteTimeoutSeconds,
+		MaxShutdownTime:    conf.Server.MaxShutdownTime,
+
+		IdempotencyKeyTTLHours: conf.Idempotency.KeyTTLHours,
+
+		EventsHeartbeatSeconds: conf.Events.HeartbeatSeconds,
+		EventSubscriber:        hub,
+
+		WebSocketSendQueueSize:       conf.WebSocket.SendQueueSize,
+		WebSocketMaxMessageBytes:     conf.WebSocket.MaxMessageBytes,
+		WebSocketWriteTimeoutSeconds: conf.WebSocket.WriteTimeoutSeconds,
+
+		GraphQLMaxDepth:      conf.GraphQL.MaxDepth,
+		GraphQLMaxComplexity: conf.GraphQL.MaxComplexity,
+
+		WebhookAllowPrivateNetworks: conf.Webhook.AllowPrivateNetworks,
+
+		CORS: httpsrv.CORS{
+			AllowHeaders: conf.Server.CORSAllowHeaders,
+			AllowMethods: conf.Server.CORSAllowMethods,
+			AllowOrigins: conf.Server.CORSAllowOrigins,
+		},
+	}
+
+	grpcServerConf := grpcsrv.Conf{
+		Port:            conf.GRPC.Port,
+		ManageUsername:  conf.Server.ManageUsername,
+		ManagePassword:  conf.Server.ManagePassword,
+		MaxShutdownTime: conf.Server.MaxShutdownTime,
+	}
+
+	trashConf := worker.TrashConf{
+		RetentionDays:        conf.Trash.RetentionDays,
+		PurgeIntervalMinutes: conf.Trash.PurgeIntervalMinutes,
+	}
+
+	idempotencyConf := worker.IdempotencyConf{
+		PurgeIntervalMinutes: conf.Idempotency.PurgeIntervalMinutes,
+	}
+
+	webhookConf := worker.WebhookConf{
+		IntervalSeconds:      conf.Webhook.IntervalSeconds,
+		TimeoutSeconds:       conf.Webhook.TimeoutSeconds,
+		MaxAttempts:          conf.Webhook.MaxAttempts,
+		RetryDelaySeconds:    conf.Webhook.RetryDelaySeconds,
+		DisableAfterFailures: conf.Webhook.DisableAfterFailures,
+		RetentionDays:        conf.Webhook.RetentionDays,
+		PurgeIntervalMinutes: conf.Webhook.PurgeIntervalMinutes,
+	}
+
+	reminderConf := worker.ReminderConf{
+		IntervalSeconds:   conf.Reminder.IntervalSeconds,
+		TimeoutSeconds:    conf.Reminder.TimeoutSeconds,
+		MaxAttempts:       conf.Reminder.MaxAttempts,
+		RetryDelaySeconds: conf.Reminder.RetryDelaySeconds,
+	}
+
+	notificationConf := worker.NotificationConf{
+		RetentionDays:        conf.Notification.RetentionDays,
+		MaxPerUser:           conf.Notification.MaxPerUser,
+		PurgeIntervalMinutes: conf.Notification.PurgeIntervalMinutes,
+	}
+
+	eventsConf := worker.EventsConf{
+		RetentionDays:        conf.Events.RetentionDays,
+		PurgeIntervalMinutes: conf.Events.PurgeIntervalMinutes,
+	}
+
+	metrics := app.CreatePrometheusMetrics(prometheusRoute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		sigChan := make(chan os.Signal, 1)
+		signal.Notify(sigChan, syscall.SIGHUP, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGTERM)
+
+		<-sigChan
+
+		cancel()
+	}()
+
+	go trashConf.RunTrashPurge(ctx, storage, logger)
+	go idempotencyConf.RunIdempotencyKeysPurge(ctx, storage, logger)
+	go webhookConf.RunWebhookDeliveries(ctx, storage, webhookClient(conf), logger)
+	go webhookConf.RunWebhookDeliveriesPurge(ctx, storage, logger)
+	go eventsConf.RunEventsPurge(ctx, storage, logger)
+	go reminderConf.RunReminders(ctx, storage, reminderNotifiers(conf, storage, hub), logger)
+	go notificationConf.RunNotificationsPurge(ctx, storage, logger)
+
+	// The events saved by the other instances.
+	if conf.Storage.Type == storageTypePostgres {
+		go func() {
+			if err := hub.ListenPostgres(ctx, postgresDBConf(conf).Address(), logger); err != nil {
+				logger.Errorf("events listener: %v", err)
+			}
+		}()
+	}
+
+	// The changes of the tasks and the users are published to the event log and the webhooks.
+	events := event.Storage{Backend: storage, Hub: hub}
+
+	grpcStopped := make(chan struct{})
+
+	go func() {
+		defer close(grpcStopped)
+
+		if err := grpcServerConf.RunGRPCServer(ctx, events, metrics, logger); err != nil {
+			logger.Fatalf("run grpc server: %v", err)
+		}
+	}()
+
+	if err := serverConf.RunHTTPServer(ctx, events, metrics, logger); err != nil {
+		logger.Fatalf("run http server: %v", err)
+	}
+
+	<-grpcStopped
+}
+
+// webhookClient connects only to the public addresses unless the private networks are allowed.
+func webhookClient(conf *config.Conf) *http.Client {
+	if conf.Webhook.AllowPrivateNetworks {
+		return &http.Client{}
+	}
+
+	return security.NewPublicHTTPClient()
+}
+
+// reminderNotifiers the notifiers of the channels, the email reminders fail without [smtp].
+func reminderNotifiers(conf *config.Conf, storage storage, hub *event.Hub) map[string]worker.Notifier {
+	notifiers := map[string]worker.Notifier{
+		model.ReminderChannelWebhook: worker.WebhookNotifier{Postgres: storage},
+		model.ReminderChannelInApp:   worker.InAppNotifier{Postgres: storage, Hub: hub},
+	}
+
+	if conf.SMTP.Address != "" {
+		notifiers[model.ReminderChannelEmail] = worker.SMTPNotifier{
+			Address:  conf.SMTP.Address,
+			From:     conf.SMTP.From,
+			Username: conf.SMTP.Username,
+			Password: conf.SMTP.Password,
+		}
+	}
+
+	return notifiers
+}
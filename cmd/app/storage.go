@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+
+	"taskmanager/internal/config"
+	"taskmanager/internal/db"
+	"taskmanager/internal/handler"
+	"taskmanager/internal/model"
+	"taskmanager/internal/worker"
+)
+
+const (
+	storageTypePostgres = "postgres"
+	storageTypeSQLite   = "sqlite"
+	storageTypeMemory   = "memory"
+)
+
+var errMemoryStorage = errors.New("memory storage is lost on exit, the command requires a database")
+
+type storage interface {
+	handler.Storage
+	worker.TrashPurger
+	worker.IdempotencyKeysPurger
+	worker.WebhookDeliverer
+	worker.WebhookDeliveriesPurger
+	worker.EventsPurger
+	worker.ReminderSender
+	worker.NotificationCreator
+	worker.NotificationsPurger
+	EnqueueWebhookEvent(ctx context.Context, event model.Event) (int64, error)
+	SaveEvent(ctx context.Context, userID int, event model.Event) (int64, error)
+	GetProjectMemberIDs(ctx context.Context, projectID int) ([]int, error)
+	GetTaskProjects(ctx context.Context, userID int) (map[int]int, error)
+	GetTaskUserIDs(ctx context.Context, taskID int) ([]int, error)
+	Atomic(ctx context.Context, fn func(ctx context.Context) error) error
+	userAdminStorage
+}
+
+func createStorage(ctx context.Context, conf *config.Conf, logger *logrus.Logger) (storage, error) {
+	switch conf.Storage.Type {
+	case storageTypeMemory:
+		logger.Warn("memory storage: the data will be lost on restart")
+
+		return model.NewMemory(), nil
+
+	case storageTypeSQLite:
+		sqlite, err := createSQLite(conf, logger)
+		if err != nil {
+			return nil, fmt.Errorf("create sqlite: %w", err)
+		}
+
+		if conf.Storage.AutoMigrate {
+			if err := sqlite.Migrate(ctx); err != nil {
+				return nil, fmt.Errorf("sqlite migrate: %w", err)
+			}
+		}
+
+		return sqlite, nil
+	}
+
+	postgres, err := createPostgres(conf, logger)
+	if err != nil {
+		return nil, fmt.Errorf("create postgres: %w", err)
+	}
+
+	if conf.Storage.AutoMigrate {
+		if err := postgres.Migrate(ctx); err != nil {
+			return nil, fmt.Errorf("postgres migrate: %w", err)
+		}
+	}
+
+	return postgres, nil
+}
+
+func createSQLite(conf *config.Conf, logger *logrus.Logger) (model.SQLite, error) {
+	pool, err := db.CreateSQLitePool(conf.SQLite.Path)
+	if err != nil {
+		return model.SQLite{}, fmt.Errorf("create sqlite pool: %w", err)
+	}
+
+	return model.SQLite{
+		Pool:         pool,
+		Logger:       logger,
+		QueryTimeout: conf.SQLite.QueryTimeout,
+	}, nil
+}
+
+func createPostgres(conf *config.Conf, logger *logrus.Logger) (model.Postgres, error) {
+	postgresPool, err := postgresDBConf(conf).CreatePool(logger)
+	if err != nil {
+		return model.Postgres{}, fmt.Errorf("create postgres pool: %w", err)
+	}
+
+	txIsolation, err := model.ParseIsolationLevel(conf.Postgres.TxIsolationLevel)
+	if err != nil {
+		return model.Postgres{}, fmt.Errorf("parse tx isolation level: %w", err)
+	}
+
+	return model.Postgres{
+		Pool:         postgresPool,
+		QueryTimeout: conf.Postgres.QueryTimeout,
+		Logger:       logger,
+		TxOptions: model.TxOptions{
+			Isolation:  txIsolation,
+			MaxRetries: conf.Postgres.TxMaxRetries,
+		},
+	}, nil
+}
+
+func postgresDBConf(conf *config.Conf) db.Conf {
+	return db.Conf{
+		DockerEnvConn: "DB_CONN",
+		ConnAddress:   conf.Postgres.ConnAddress,
+		MaxOpenConns:  conf.Postgres.MaxOpenConns,
+		MaxIdleConns:  conf.Postgres.MaxIdleConns,
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/sirupsen/logrus"
+
+	"taskmanager/internal/config"
+	"taskmanager/internal/handler"
+	"taskmanager/internal/model"
+	"taskmanager/internal/security"
+)
+
+const (
+	userCreate        = "create"
+	userDelete        = "delete"
+	userList          = "list"
+	userResetPassword = "reset-password"
+)
+
+var (
+	errUserUsage = errors.New(
+		"usage: apitm user create [--password password] username|delete username|list|" +
+			"reset-password [--password password] username",
+	)
+	errPasswordRequired = errors.New("password required")
+)
+
+// userAdminStorage the user management of the command line, the manage API has UserStorage only.
+type userAdminStorage interface {
+	handler.UserStorage
+	GetUsers(ctx context.Context) ([]model.User, error)
+	GetUserID(ctx context.Context, username string) (int, error)
+	UpdateUserPassword(ctx context.Context, username, password string) error
+}
+
+func runUser(
+	ctx context.Context, conf *config.Conf, logger *logrus.Logger, args []string, stdin io.Reader, stdout io.Writer,
+) error {
+	if len(args) == 0 {
+		return errUserUsage
+	}
+
+	if conf.Storage.Type == storageTypeMemory {
+		return errMemoryStorage
+	}
+
+	command, args := args[0], args[1:]
+
+	flags := flag.NewFlagSet("user "+command, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	password := flags.String("password", "", "read from stdin if empty")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w: %s", errUserUsage, err.Error())
+	}
+
+	args = flags.Args()
+
+	storage, err := createStorage(ctx, conf, logger)
+	if err != nil {
+		return fmt.Errorf("create storage: %w", err)
+	}
+
+	switch {
+	case command == userList && len(args) == 0:
+		return listUsers(ctx, storage, stdout)
+
+	case command == userCreate && len(args) == 1:
+		if *password == "" {
+			if *password, err = readPassword(stdin); err != nil {
+				return err
+			}
+		}
+
+		if err := handler.ValidateUsernamePassword(args[0], *password); err != nil {
+			return err
+		}
+
+		userID, err := storage.CreateNewUser(ctx, args[0], security.SaltPassword(*password))
+		if err != nil {
+			return fmt.Errorf("create user: %w", err)
+		}
+
+		fmt.Fprintf(stdout, "user created: %s, id %d\n", args[0], userID)
+
+		return nil
+
+	case command == userDelete && len(args) == 1:
+		userID, err := storage.GetUserID(ctx, args[0])
+		if err != nil {
+			return fmt.Errorf("get user id: %w", err)
+		}
+
+		if err := storage.DeleteUser(ctx, userID); err != nil {
+			return fmt.Errorf("delete user: %w", err)
+		}
+
+		fmt.Fprintf(stdout, "user deleted: %s, id %d\n", args[0], userID)
+
+		return nil
+
+	case command == userResetPassword && len(args) == 1:
+		if *password == "" {
+			if *password, err = readPassword(stdin); err != nil {
+				return err
+			}
+		}
+
+		if err := handler.ValidatePassword(*password); err != nil {
+			return err
+		}
+
+		if err := storage.UpdateUserPassword(ctx, args[0], security.SaltPassword(*password)); err != nil {
+			return fmt.Errorf("update user password: %w", err)
+		}
+
+		fmt.Fprintf(stdout, "password reset: %s\n", args[0])
+
+		return nil
+	}
+
+	return errUserUsage
+}
+
+func listUsers(ctx context.Context, storage userAdminStorage, stdout io.Writer) error {
+	users, err := storage.GetUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("get users: %w", err)
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "ID\tUSERNAME")
+
+	for _, user := range users {
+		fmt.Fprintf(w, "%d\t%s\n", user.ID, user.Username)
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("flush: %w", err)
+	}
+
+	return nil
+}
+
+// readPassword the first line of stdin, so that the password is not kept in the shell history.
+func readPassword(stdin io.Reader) (string, error) {
+	line, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("read password: %w", err)
+	}
+
+	password := strings.TrimRight(line, "\r\n")
+	if password == "" {
+		return "", errPasswordRequired
+	}
+
+	return password, nil
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime/debug"
+)
+
+// version set on build: go build -ldflags "-X main.version=v1.0.0".
+var version = "dev"
+
+func printVersion(stdout io.Writer) {
+	fmt.Fprintf(stdout, "apitm %s", version)
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		fmt.Fprintf(stdout, " %s", info.GoVersion)
+
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				fmt.Fprintf(stdout, " %s", setting.Value)
+			}
+		}
+	}
+
+	fmt.Fprintln(stdout)
+}
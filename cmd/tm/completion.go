@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	shellBash = "bash"
+	shellZsh  = "zsh"
+	shellFish = "fish"
+)
+
+// completionScripts %s - the commands separated by spaces.
+var completionScripts = map[string]string{
+	shellBash: `# tm completion bash > /etc/bash_completion.d/tm
+_tm() {
+    local cur word
+    cur="${COMP_WORDS[COMP_CWORD]}"
+
+    for word in "${COMP_WORDS[@]:1:COMP_CWORD-1}"; do
+        case "$word" in
+            -*) ;;
+            *) return 0 ;;
+        esac
+    done
+
+    if [[ "$cur" == -* ]]; then
+        COMPREPLY=($(compgen -W "--config --profile --output" -- "$cur"))
+    else
+        COMPREPLY=($(compgen -W "%s" -- "$cur"))
+    fi
+}
+
+complete -F _tm tm
+`,
+	shellZsh: `#compdef tm
+# tm completion zsh > "${fpath[1]}/_tm"
+_arguments \
+    '--config[the file of the profiles]:file:_files' \
+    '--profile[the profile]:profile:' \
+    '--output[table or json]:output:(table json)' \
+    '1:command:(%s)' \
+    '*::arg:_default'
+`,
+	shellFish: `# tm completion fish > ~/.config/fish/completions/tm.fish
+complete -c tm -l config -r -d 'the file of the profiles'
+complete -c tm -l profile -x -d 'the profile'
+complete -c tm -l output -x -a 'table json'
+complete -c tm -f -n __fish_use_subcommand -a '%s'
+`,
+}
+
+func (c cli) completion(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%w: completion bash|zsh|fish", errUsage)
+	}
+
+	script, ok := completionScripts[args[0]]
+	if !ok {
+		return fmt.Errorf("%w: shell %s", errUsage, args[0])
+	}
+
+	fmt.Fprintf(c.stdout, script, strings.Join(commands, " "))
+
+	return nil
+}
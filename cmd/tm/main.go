@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+const (
+	commandAdd        = "add"
+	commandLs         = "ls"
+	commandDone       = "done"
+	commandEdit       = "edit"
+	commandRm         = "rm"
+	commandLogin      = "login"
+	commandProfiles   = "profiles"
+	commandUse        = "use"
+	commandCompletion = "completion"
+)
+
+// commands for the usage and the shell completion.
+var commands = []string{
+	commandAdd, commandLs, commandDone, commandEdit, commandRm,
+	commandLogin, commandProfiles, commandUse, commandCompletion,
+}
+
+const usage = `Usage: tm [flags] command [args]
+
+Commands:
+  add title                              create a task
+  ls [--done|--todo] [--search text]     list the tasks
+  done [--undo] id...                    complete the tasks
+  edit id title                          change the title
+  rm [--permanent] id...                 move the tasks to the trash
+  login [--url url] [--username username] [--password password]
+                                         save the credentials of the profile, without --password it is read from stdin
+  profiles                               list the profiles, * - the current one
+  use profile                            make the profile current
+  completion bash|zsh|fish               print the shell completion script
+
+Flags:
+`
+
+var (
+	errUnknownCommand = errors.New("unknown command")
+	errUsage          = errors.New("invalid arguments")
+)
+
+// cli the flags shared by the commands.
+type cli struct {
+	configFileName string
+	profile        string
+	output         string
+
+	stdin  io.Reader
+	stdout io.Writer
+}
+
+func main() {
+	flags := flag.NewFlagSet("tm", flag.ExitOnError)
+
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+
+	c := cli{
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+	}
+
+	flags.StringVar(&c.configFileName, "config", defaultConfigFileName(), "the file of the profiles")
+	flags.StringVar(&c.profile, "profile", "", "the profile, the current one if empty")
+	flags.StringVar(&c.output, "output", outputTable, "table or json")
+
+	// ExitOnError.
+	_ = flags.Parse(os.Args[1:])
+
+	if err := c.run(context.Background(), flags.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "tm: %v\n", err)
+
+		if errors.Is(err, errUnknownCommand) || errors.Is(err, errUsage) {
+			flags.Usage()
+		}
+
+		os.Exit(1)
+	}
+}
+
+func (c cli) run(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: command required", errUsage)
+	}
+
+	if c.output != outputTable && c.output != outputJSON {
+		return fmt.Errorf("%w: output %s", errUsage, c.output)
+	}
+
+	command, args := args[0], args[1:]
+
+	switch command {
+	case commandLogin:
+		return c.login(ctx, args)
+
+	case commandProfiles:
+		return c.profiles()
+
+	case commandUse:
+		return c.use(args)
+
+	case commandCompletion:
+		return c.completion(args)
+	}
+
+	api, err := c.client()
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case commandAdd:
+		return c.add(ctx, api, args)
+
+	case commandLs:
+		return c.ls(ctx, api, args)
+
+	case commandDone:
+		return c.done(ctx, api, args)
+
+	case commandEdit:
+		return c.edit(ctx, api, args)
+
+	case commandRm:
+		return c.rm(ctx, api, args)
+	}
+
+	return fmt.Errorf("%w: %s", errUnknownCommand, command)
+}
+
+// defaultConfigFileName ~/.config/tm/config.json on Linux.
+func defaultConfigFileName() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+
+	return filepath.Join(dir, "tm", "config.json")
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taskmanager/client"
+	"taskmanager/internal/app"
+	"taskmanager/internal/model"
+	"taskmanager/internal/security"
+	"taskmanager/internal/transport/httpsrv"
+)
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+
+	storage := model.NewMemory()
+
+	_, err := storage.CreateNewUser(ctx, "tmuser", security.SaltPassword("tmpassword"))
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+
+	server := httptest.NewServer(httpsrv.Conf{
+		ManageUsername: "admin",
+		ManagePassword: "admin",
+	}.NewRouter(ctx, storage, app.Metrics{MetricsRoute: "/metrics"}))
+	defer server.Close()
+
+	configFileName := filepath.Join(t.TempDir(), "tm", "config.json")
+
+	run := func(t *testing.T, stdin string, args ...string) (string, error) {
+		t.Helper()
+
+		var stdout bytes.Buffer
+
+		c := cli{
+			configFileName: configFileName,
+			output:         outputTable,
+			stdin:          strings.NewReader(stdin),
+			stdout:         &stdout,
+		}
+
+		if len(args) > 1 && args[0] == "--output" {
+			c.output, args = args[1], args[2:]
+		}
+
+		err := c.run(ctx, args)
+
+		return stdout.String(), err
+	}
+
+	_, err = run(t, "", "ls")
+	assert.ErrorIs(t, err, errNoProfile)
+
+	_, err = run(t, "tmpassword\n", "login", "--url", server.URL+"/unknown", "--username", "tmuser")
+	require.Error(t, err)
+
+	_, err = run(t, "wrong\n", "login", "--url", server.URL, "--username", "tmuser")
+	require.ErrorIs(t, err, client.ErrForbidden)
+
+	_, err = run(t, "", "ls")
+	assert.ErrorIs(t, err, errNoProfile)
+
+	out, err := run(t, "tmpassword\n", "login", "--url", server.URL, "--username", "tmuser")
+	require.NoError(t, err)
+	assert.Equal(t, "logged in: tmuser, profile default\n", out)
+
+	out, err = run(t, "", "profiles")
+	require.NoError(t, err)
+	assert.Contains(t, out, "*  default  "+server.URL+"  tmuser")
+
+	out, err = run(t, "", "add", "buy", "milk")
+	require.NoError(t, err)
+	assert.Equal(t, "task created: 1\n", out)
+
+	out, err = run(t, "", "--output", "json", "add", "write report")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"taskId": 2}`, out)
+
+	out, err = run(t, "", "done", "1")
+	require.NoError(t, err)
+	assert.Equal(t, "task completed: 1\n", out)
+
+	out, err = run(t, "", "edit", "2", "write", "the", "report")
+	require.NoError(t, err)
+	assert.Equal(t, "task changed: 2\n", out)
+
+	out, err = run(t, "", "ls", "--todo")
+	require.NoError(t, err)
+	assert.Contains(t, out, "write the report")
+	assert.NotContains(t, out, "buy milk")
+
+	out, err = run(t, "", "--output", "json", "ls", "--done")
+	require.NoError(t, err)
+
+	var tasks []model.Task
+	require.NoError(t, json.Unmarshal([]byte(out), &tasks))
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "buy milk", tasks[0].Title)
+	assert.True(t, tasks[0].Status)
+
+	out, err = run(t, "", "ls", "--search", "MILK")
+	require.NoError(t, err)
+	assert.Contains(t, out, "buy milk")
+	assert.NotContains(t, out, "report")
+
+	out, err = run(t, "", "rm", "1", "2")
+	require.NoError(t, err)
+	assert.Equal(t, "task moved to trash: 1\ntask moved to trash: 2\n", out)
+
+	_, err = run(t, "", "rm", "1")
+	require.Error(t, err)
+
+	out, err = run(t, "", "rm", "--permanent", "1")
+	require.NoError(t, err)
+	assert.Equal(t, "task deleted: 1\n", out)
+
+	out, err = run(t, "", "--output", "json", "ls")
+	require.NoError(t, err)
+	assert.JSONEq(t, `[]`, out)
+
+	_, err = run(t, "", "use", "unknown")
+	assert.ErrorIs(t, err, errNoProfile)
+
+	_, err = run(t, "", "done", "x")
+	assert.ErrorIs(t, err, errUsage)
+
+	_, err = run(t, "", "unknown")
+	assert.ErrorIs(t, err, errUnknownCommand)
+
+	out, err = run(t, "", "completion", "bash")
+	require.NoError(t, err)
+	assert.Contains(t, out, "add ls done edit rm")
+}
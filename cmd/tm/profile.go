@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"taskmanager/client"
+)
+
+const (
+	defaultProfile = "default"
+	defaultURL     = "http://127.0.0.1:45222"
+)
+
+var (
+	errNoProfile        = errors.New("no profile, run: tm login")
+	errPasswordRequired = errors.New("password required")
+)
+
+// profiles the file is readable by the owner only, the passwords are stored as is.
+type profiles struct {
+	Current  string             `json:"current"`
+	Profiles map[string]profile `json:"profiles"`
+}
+
+type profile struct {
+	URL      string `json:"url"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+func loadProfiles(fileName string) (profiles, error) {
+	p := profiles{
+		Profiles: make(map[string]profile),
+	}
+
+	b, err := os.ReadFile(fileName)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return p, nil
+		}
+
+		return profiles{}, fmt.Errorf("read file: %w", err)
+	}
+
+	if err := json.Unmarshal(b, &p); err != nil {
+		return profiles{}, fmt.Errorf("unmarshal %s: %w", fileName, err)
+	}
+
+	if p.Profiles == nil {
+		p.Profiles = make(map[string]profile)
+	}
+
+	return p, nil
+}
+
+func (p profiles) save(fileName string) error {
+	if err := os.MkdirAll(filepath.Dir(fileName), 0o700); err != nil {
+		return fmt.Errorf("mkdir: %w", err)
+	}
+
+	b, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+
+	if err := os.WriteFile(fileName, b, 0o600); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+
+	return nil
+}
+
+// client of the profile from the flag or the current one.
+func (c cli) client() (*client.Client, error) {
+	p, err := loadProfiles(c.configFileName)
+	if err != nil {
+		return nil, fmt.Errorf("load profiles: %w", err)
+	}
+
+	name := c.profile
+	if name == "" {
+		name = p.Current
+	}
+
+	current, ok := p.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errNoProfile, name)
+	}
+
+	return client.New(current.URL, current.Username, current.Password), nil
+}
+
+// login saves the credentials checked by the server, the profile becomes current.
+func (c cli) login(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet(commandLogin, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	url := flags.String("url", defaultURL, "")
+	username := flags.String("username", "", "")
+	password := flags.String("password", "", "")
+
+	if err := flags.Parse(args); err != nil || flags.NArg() != 0 || *username == "" {
+		return fmt.Errorf("%w: login [--url url] --username username [--password password]", errUsage)
+	}
+
+	if *password == "" {
+		line, err := bufio.NewReader(c.stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("read password: %w", err)
+		}
+
+		*password = strings.TrimRight(line, "\r\n")
+		if *password == "" {
+			return errPasswordRequired
+		}
+	}
+
+	if _, err := client.New(*url, *username, *password).Me(ctx); err != nil {
+		return fmt.Errorf("check credentials: %w", err)
+	}
+
+	p, err := loadProfiles(c.configFileName)
+	if err != nil {
+		return fmt.Errorf("load profiles: %w", err)
+	}
+
+	name := c.profile
+	if name == "" {
+		name = defaultProfile
+	}
+
+	p.Profiles[name] = profile{
+		URL:      *url,
+		Username: *username,
+		Password: *password,
+	}
+	p.Current = name
+
+	if err := p.save(c.configFileName); err != nil {
+		return fmt.Errorf("save profiles: %w", err)
+	}
+
+	if c.output == outputTable {
+		fmt.Fprintf(c.stdout, "logged in: %s, profile %s\n", *username, name)
+	}
+
+	return nil
+}
+
+func (c cli) profiles() error {
+	p, err := loadProfiles(c.configFileName)
+	if err != nil {
+		return fmt.Errorf("load profiles: %w", err)
+	}
+
+	names := make([]string, 0, len(p.Profiles))
+	for name := range p.Profiles {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	if c.output == outputJSON {
+		type profileResult struct {
+			Name     string `json:"name"`
+			URL      string `json:"url"`
+			Username string `json:"username"`
+			Current  bool   `json:"current"`
+		}
+
+		res := make([]profileResult, 0, len(names))
+
+		for _, name := range names {
+			res = append(res, profileResult{
+				Name:     name,
+				URL:      p.Profiles[name].URL,
+				Username: p.Profiles[name].Username,
+				Current:  name == p.Current,
+			})
+		}
+
+		return c.printJSON(res)
+	}
+
+	w := newTableWriter(c.stdout)
+
+	fmt.Fprintln(w, "\tPROFILE\tURL\tUSERNAME")
+
+	for _, name := range names {
+		current := ""
+		if name == p.Current {
+			current = "*"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", current, name, p.Profiles[name].URL, p.Profiles[name].Username)
+	}
+
+	return w.Flush()
+}
+
+func (c cli) use(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%w: use profile", errUsage)
+	}
+
+	p, err := loadProfiles(c.configFileName)
+	if err != nil {
+		return fmt.Errorf("load profiles: %w", err)
+	}
+
+	if _, ok := p.Profiles[args[0]]; !ok {
+		return fmt.Errorf("%w: %s", errNoProfile, args[0])
+	}
+
+	p.Current = args[0]
+
+	if err := p.save(c.configFileName); err != nil {
+		return fmt.Errorf("save profiles: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"taskmanager/client"
+)
+
+const tableTimeFormat = "2006-01-02 15:04"
+
+func (c cli) add(ctx context.Context, api *client.Client, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: add title", errUsage)
+	}
+
+	taskID, err := api.CreateTask(ctx, strings.Join(args, " "))
+	if err != nil {
+		return fmt.Errorf("create task: %w", err)
+	}
+
+	if c.output == outputJSON {
+		return c.printJSON(client.CreateTaskResult{TaskID: taskID})
+	}
+
+	fmt.Fprintf(c.stdout, "task created: %d\n", taskID)
+
+	return nil
+}
+
+// ls the API returns all tasks, the filters are applied here.
+func (c cli) ls(ctx context.Context, api *client.Client, args []string) error {
+	flags := flag.NewFlagSet(commandLs, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	done := flags.Bool("done", false, "")
+	todo := flags.Bool("todo", false, "")
+	search := flags.String("search", "", "")
+
+	if err := flags.Parse(args); err != nil || flags.NArg() != 0 || (*done && *todo) {
+		return fmt.Errorf("%w: ls [--done|--todo] [--search text]", errUsage)
+	}
+
+	tasks, err := api.GetTasks(ctx)
+	if err != nil {
+		return fmt.Errorf("get tasks: %w", err)
+	}
+
+	filtered := make([]client.Task, 0, len(tasks))
+
+	for _, task := range tasks {
+		if (*done && !task.Status) || (*todo && task.Status) {
+			continue
+		}
+
+		if *search != "" && !strings.Contains(strings.ToLower(task.Title), strings.ToLower(*search)) {
+			continue
+		}
+
+		filtered = append(filtered, task)
+	}
+
+	if c.output == outputJSON {
+		return c.printJSON(filtered)
+	}
+
+	w := newTableWriter(c.stdout)
+
+	fmt.Fprintln(w, "ID\tDONE\tTITLE\tUPDATED")
+
+	for _, task := range filtered {
+		status := ""
+		if task.Status {
+			status = "x"
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", task.ID, status, task.Title, task.Updated.Local().Format(tableTimeFormat))
+	}
+
+	return w.Flush()
+}
+
+func (c cli) done(ctx context.Context, api *client.Client, args []string) error {
+	flags := flag.NewFlagSet(commandDone, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	undo := flags.Bool("undo", false, "")
+
+	if err := flags.Parse(args); err != nil || flags.NArg() == 0 {
+		return fmt.Errorf("%w: done [--undo] id...", errUsage)
+	}
+
+	taskIDs, err := parseTaskIDs(flags.Args())
+	if err != nil {
+		return err
+	}
+
+	completed := !*undo
+
+	for _, taskID := range taskIDs {
+		if err := api.PatchTask(ctx, taskID, client.TaskPatch{Completed: &completed}, ""); err != nil {
+			return fmt.Errorf("patch task %d: %w", taskID, err)
+		}
+
+		if c.output == outputTable {
+			if completed {
+				fmt.Fprintf(c.stdout, "task completed: %d\n", taskID)
+			} else {
+				fmt.Fprintf(c.stdout, "task reopened: %d\n", taskID)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c cli) edit(ctx context.Context, api *client.Client, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("%w: edit id title", errUsage)
+	}
+
+	taskIDs, err := parseTaskIDs(args[:1])
+	if err != nil {
+		return err
+	}
+
+	title := strings.Join(args[1:], " ")
+
+	if err := api.PatchTask(ctx, taskIDs[0], client.TaskPatch{Title: &title}, ""); err != nil {
+		return fmt.Errorf("patch task %d: %w", taskIDs[0], err)
+	}
+
+	if c.output == outputTable {
+		fmt.Fprintf(c.stdout, "task changed: %d\n", taskIDs[0])
+	}
+
+	return nil
+}
+
+func (c cli) rm(ctx context.Context, api *client.Client, args []string) error {
+	flags := flag.NewFlagSet(commandRm, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+
+	permanent := flags.Bool("permanent", false, "")
+
+	if err := flags.Parse(args); err != nil || flags.NArg() == 0 {
+		return fmt.Errorf("%w: rm [--permanent] id...", errUsage)
+	}
+
+	taskIDs, err := parseTaskIDs(flags.Args())
+	if err != nil {
+		return err
+	}
+
+	for _, taskID := range taskIDs {
+		if err := api.DeleteTask(ctx, taskID, *permanent, ""); err != nil {
+			return fmt.Errorf("delete task %d: %w", taskID, err)
+		}
+
+		if c.output != outputTable {
+			continue
+		}
+
+		if *permanent {
+			fmt.Fprintf(c.stdout, "task deleted: %d\n", taskID)
+		} else {
+			fmt.Fprintf(c.stdout, "task moved to trash: %d\n", taskID)
+		}
+	}
+
+	return nil
+}
+
+func parseTaskIDs(args []string) ([]int, error) {
+	taskIDs := make([]int, 0, len(args))
+
+	for _, arg := range args {
+		taskID, err := strconv.Atoi(arg)
+		if err != nil || taskID < 1 {
+			return nil, fmt.Errorf("%w: task id %s", errUsage, arg)
+		}
+
+		taskIDs = append(taskIDs, taskID)
+	}
+
+	return taskIDs, nil
+}
+
+func (c cli) printJSON(v any) error {
+	enc := json.NewEncoder(c.stdout)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+
+	return nil
+}
+
+func newTableWriter(w io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+}
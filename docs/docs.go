@@ -1,17 +1,10 @@
-// Package docs GENERATED BY THE COMMAND ABOVE; DO NOT EDIT
-// This file was generated by swaggo/swag
-package docs
+// Code generated by swaggo/swag. DO NOT EDIT.
 
-import (
-	"bytes"
-	"encoding/json"
-	"strings"
-	"text/template"
+package docs
 
-	"github.com/swaggo/swag"
-)
+import "github.com/swaggo/swag"
 
-var doc = `{
+const docTemplate = `{
     "schemes": {{ marshal .Schemes }},
     "swagger": "2.0",
     "info": {
@@ -27,6 +20,175 @@ var doc = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/graphql": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "graphql"
+                ],
+                "summary": "GraphQL of the tasks",
+                "parameters": [
+                    {
+                        "description": "query, operationName, variables; GET - the query parameters, only queries",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.GraphQLRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "data, errors; extensions.code of the errors - the error type",
+                        "schema": {
+                            "$ref": "#/definitions/handler.GraphQLResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/calendar/token": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "create calendar token",
+                "responses": {
+                    "201": {
+                        "description": "token, path of the feed",
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateCalendarTokenResult"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "delete calendar token",
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/calendar/{file}": {
+            "get": {
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "get calendar",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "\u003ctoken\u003e.ics",
+                        "name": "file",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "vtodo(default), vevent",
+                        "name": "component",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "text/calendar",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
         "/v1/manage/user": {
             "post": {
                 "consumes": [
@@ -46,15 +208,21 @@ var doc = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.createUserBody"
+                            "$ref": "#/definitions/handler.CreateUserBody"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "retries with the same key get the stored response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "201": {
                         "description": "userId",
                         "schema": {
-                            "$ref": "#/definitions/handler.createUserResult"
+                            "$ref": "#/definitions/handler.CreateUserResult"
                         }
                     },
                     "400": {
@@ -119,7 +287,42 @@ var doc = `{
                 }
             }
         },
-        "/v1/task": {
+        "/v1/manage/webhooks": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "get webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Webhook"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            },
             "post": {
                 "consumes": [
                     "application/json"
@@ -128,25 +331,25 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "task"
+                    "webhooks"
                 ],
-                "summary": "create new task",
+                "summary": "create webhook",
                 "parameters": [
                     {
-                        "description": "title - max 200",
+                        "description": "url - http or https, a public host; events - task.created, task.updated, task.completed, task.deleted, task.restored, task.reminder, user.deleted(manage); secret - generated if empty",
                         "name": "data",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/handler.createTaskBody"
+                            "$ref": "#/definitions/handler.CreateWebhookBody"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "taskId",
+                        "description": "webhookId, secret",
                         "schema": {
-                            "$ref": "#/definitions/handler.createTaskResult"
+                            "$ref": "#/definitions/handler.CreateWebhookResult"
                         }
                     },
                     "400": {
@@ -158,6 +361,9 @@ var doc = `{
                     "401": {
                         "description": "Unauthorized"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
                     "500": {
                         "description": "error type, comment",
                         "schema": {
@@ -167,8 +373,8 @@ var doc = `{
                 }
             }
         },
-        "/v1/task/{taskId}": {
-            "get": {
+        "/v1/manage/webhooks/{webhookId}": {
+            "delete": {
                 "consumes": [
                     "application/json"
                 ],
@@ -176,25 +382,22 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "task"
+                    "webhooks"
                 ],
-                "summary": "get task",
+                "summary": "delete webhook",
                 "parameters": [
                     {
                         "minimum": 1,
                         "type": "integer",
-                        "description": "taskId",
-                        "name": "taskId",
+                        "description": "webhookId",
+                        "name": "webhookId",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/model.Task"
-                        }
+                    "204": {
+                        "description": "No Content"
                     },
                     "400": {
                         "description": "error type, comment",
@@ -205,6 +408,9 @@ var doc = `{
                     "401": {
                         "description": "Unauthorized"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
                     "500": {
                         "description": "error type, comment",
                         "schema": {
@@ -212,8 +418,10 @@ var doc = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/v1/manage/webhooks/{webhookId}/deliveries": {
+            "get": {
                 "consumes": [
                     "application/json"
                 ],
@@ -221,31 +429,28 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "task"
+                    "webhooks"
                 ],
-                "summary": "update task",
+                "summary": "get webhook deliveries",
                 "parameters": [
                     {
                         "minimum": 1,
                         "type": "integer",
-                        "description": "taskId",
-                        "name": "taskId",
+                        "description": "webhookId",
+                        "name": "webhookId",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "any of the fields",
-                        "name": "data",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handler.updateTaskBody"
-                        }
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "the newest first",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.WebhookDelivery"
+                            }
+                        }
                     },
                     "400": {
                         "description": "error type, comment",
@@ -256,6 +461,9 @@ var doc = `{
                     "401": {
                         "description": "Unauthorized"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
                     "500": {
                         "description": "error type, comment",
                         "schema": {
@@ -263,8 +471,10 @@ var doc = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/v1/manage/webhooks/{webhookId}/enable": {
+            "post": {
                 "consumes": [
                     "application/json"
                 ],
@@ -272,15 +482,15 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "task"
+                    "webhooks"
                 ],
-                "summary": "delete task",
+                "summary": "enable webhook",
                 "parameters": [
                     {
                         "minimum": 1,
                         "type": "integer",
-                        "description": "taskId",
-                        "name": "taskId",
+                        "description": "webhookId",
+                        "name": "webhookId",
                         "in": "path",
                         "required": true
                     }
@@ -298,6 +508,9 @@ var doc = `{
                     "401": {
                         "description": "Unauthorized"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
                     "500": {
                         "description": "error type, comment",
                         "schema": {
@@ -307,7 +520,7 @@ var doc = `{
                 }
             }
         },
-        "/v1/tasks": {
+        "/v1/me": {
             "get": {
                 "consumes": [
                     "application/json"
@@ -316,22 +529,22 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
+                    "user"
                 ],
-                "summary": "get tasks",
+                "summary": "get the user of the credentials",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/model.Task"
-                            }
+                            "$ref": "#/definitions/handler.GetMeResult"
                         }
                     },
                     "401": {
                         "description": "Unauthorized"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
                     "500": {
                         "description": "error type, comment",
                         "schema": {
@@ -339,8 +552,10 @@ var doc = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/v1/notifications": {
+            "get": {
                 "consumes": [
                     "application/json"
                 ],
@@ -348,19 +563,51 @@ var doc = `{
                     "application/json"
                 ],
                 "tags": [
-                    "tasks"
+                    "notifications"
+                ],
+                "summary": "get notifications",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "only the unread ones",
+                        "name": "unread",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "notificationId, the notifications before it",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "50 by default",
+                        "name": "limit",
+                        "in": "query"
+                    }
                 ],
-                "summary": "delete tasks",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/handler.deleteTasksResult"
+                            "$ref": "#/definitions/handler.NotificationsResult"
+                        }
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
                         }
                     },
                     "401": {
                         "description": "Unauthorized"
                     },
+                    "403": {
+                        "description": "Forbidden"
+                    },
                     "500": {
                         "description": "error type, comment",
                         "schema": {
@@ -369,83 +616,2868 @@ var doc = `{
                     }
                 }
             }
-        }
+        },
+        "/v1/notifications/preferences": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "get notification preferences",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.NotificationPreference"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "update notification preferences",
+                "parameters": [
+                    {
+                        "description": "type - task.reminder, webhook.disabled, project.shared, task.assigned, task.commented",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateNotificationPreferencesBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "all types",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.NotificationPreference"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/notifications/read": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "mark all notifications read",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ReadNotificationsResult"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/notifications/{notificationId}/read": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "mark notification read",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "notificationId",
+                        "name": "notificationId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/projects": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "projects"
+                ],
+                "summary": "get projects",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Project"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "projects"
+                ],
+                "summary": "create project",
+                "parameters": [
+                    {
+                        "description": "name",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateProjectBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "projectId",
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateProjectResult"
+                        }
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/projects/{projectId}": {
+            "delete": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "projects"
+                ],
+                "summary": "delete project",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "projectId",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/projects/{projectId}/members": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "projects"
+                ],
+                "summary": "get project members",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "projectId",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.ProjectMember"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "projects"
+                ],
+                "summary": "add project member",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "projectId",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "username",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.AddProjectMemberBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "userId",
+                        "schema": {
+                            "$ref": "#/definitions/handler.AddProjectMemberResult"
+                        }
+                    },
+                    "201": {
+                        "description": "userId",
+                        "schema": {
+                            "$ref": "#/definitions/handler.AddProjectMemberResult"
+                        }
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/projects/{projectId}/members/{userId}": {
+            "delete": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "projects"
+                ],
+                "summary": "remove project member",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "projectId",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "userId",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/projects/{projectId}/tasks": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "projects"
+                ],
+                "summary": "get project tasks",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "projectId",
+                        "name": "projectId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Task"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/task": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "create new task",
+                "parameters": [
+                    {
+                        "description": "title - max 200",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateTaskBody"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "retries with the same key get the stored response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "taskId",
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateTaskResult"
+                        }
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/task/{taskId}": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "get task",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "taskId",
+                        "name": "taskId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the cached task",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.TaskResponse"
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "replace task",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "taskId",
+                        "name": "taskId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "all fields; title - max 200; due, recurrence - absent or null removes them",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.UpdateTaskBody"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the task",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "412": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "delete task(move to the trash)",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "taskId",
+                        "name": "taskId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "delete without moving to the trash",
+                        "name": "permanent",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the task",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "412": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "consumes": [
+                    "application/json",
+                    "application/merge-patch+json",
+                    "application/json-patch+json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "patch task",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "taskId",
+                        "name": "taskId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "merge patch - the fields; json patch - ops on /title, /completed, /due, /recurrence",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the task",
+                        "name": "If-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "409": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "412": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "415": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/task/{taskId}/history": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "get task change history, also of the task in the trash or deleted permanently",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "taskId",
+                        "name": "taskId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.TaskChange"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/task/{taskId}/history/{revision}": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "get task as it was after the revision(0 - as created)",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "taskId",
+                        "name": "taskId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "revision",
+                        "name": "revision",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.Task"
+                        }
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/task/{taskId}/history/{revision}/revert": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "revert task to the revision(0 - as created)",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "taskId",
+                        "name": "taskId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "revision",
+                        "name": "revision",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "retries with the same key get the stored response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/task/{taskId}/project": {
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "projects"
+                ],
+                "summary": "set task project",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "taskId",
+                        "name": "taskId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "projectId, 0 - out of the project",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.SetTaskProjectBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/task/{taskId}/reminders": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reminders"
+                ],
+                "summary": "get task reminders",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "taskId",
+                        "name": "taskId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Reminder"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reminders"
+                ],
+                "summary": "create task reminder",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "taskId",
+                        "name": "taskId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "remindAt or beforeMinutes; channel - webhook, email, inapp",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateReminderBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "reminderId",
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateReminderResult"
+                        }
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/task/{taskId}/reminders/{reminderId}": {
+            "delete": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "reminders"
+                ],
+                "summary": "delete task reminder",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "taskId",
+                        "name": "taskId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "reminderId",
+                        "name": "reminderId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/task/{taskId}/restore": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "restore deleted task",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "taskId",
+                        "name": "taskId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "retries with the same key get the stored response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/tasks": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "get tasks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ETag of the cached list",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/handler.TaskResponse"
+                            }
+                        }
+                    },
+                    "304": {
+                        "description": "Not Modified"
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "delete tasks(move to the trash)",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "delete without moving to the trash, the trash is also emptied",
+                        "name": "permanent",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.DeleteTasksResult"
+                        }
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/tasks/batch": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "create, update and delete tasks in one transaction",
+                "parameters": [
+                    {
+                        "description": "op - create(title), update(taskId, title, completed, ifMatch), delete(taskId, permanent, ifMatch); max 100",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.BatchTasksBody"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "retries with the same key get the stored response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "status of each operation",
+                        "schema": {
+                            "$ref": "#/definitions/handler.BatchTasksResult"
+                        }
+                    },
+                    "400": {
+                        "description": "atomic: status of each operation",
+                        "schema": {
+                            "$ref": "#/definitions/handler.BatchTasksResult"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/tasks/events": {
+            "get": {
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "stream task events",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "the id of the last received event",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Last-Event-ID for the clients that cannot set it",
+                        "name": "lastEventId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "the stream of the events",
+                        "schema": {
+                            "$ref": "#/definitions/model.Event"
+                        }
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/tasks/export": {
+            "get": {
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "text/markdown",
+                    "text/plain"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "export tasks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "json(default), csv, md, todotxt",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.ExportedTask"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/tasks/import": {
+            "post": {
+                "consumes": [
+                    "application/json",
+                    "text/csv",
+                    "text/markdown",
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "import tasks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "json(default), csv, md, todotxt",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only validate, nothing is created",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "the column of the title, the same for status and deleted",
+                        "name": "columns[title]",
+                        "in": "query"
+                    },
+                    {
+                        "description": "the file, max 10000 tasks, 10 MiB; title - max 200",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "the status of each row",
+                        "schema": {
+                            "$ref": "#/definitions/handler.ImportTasksResult"
+                        }
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/tasks/trash": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "get deleted tasks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.DeletedTask"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/tasks/trash/restore": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "restore all deleted tasks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "retries with the same key get the stored response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/handler.RestoreTasksResult"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "get webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.Webhook"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "create webhook",
+                "parameters": [
+                    {
+                        "description": "url - http or https, a public host; events - task.created, task.updated, task.completed, task.deleted, task.restored, task.reminder, user.deleted(manage); secret - generated if empty",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateWebhookBody"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "webhookId, secret",
+                        "schema": {
+                            "$ref": "#/definitions/handler.CreateWebhookResult"
+                        }
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks/{webhookId}": {
+            "delete": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "delete webhook",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "webhookId",
+                        "name": "webhookId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks/{webhookId}/deliveries": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "get webhook deliveries",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "webhookId",
+                        "name": "webhookId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "the newest first",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/webhooks/{webhookId}/enable": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "webhooks"
+                ],
+                "summary": "enable webhook",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "webhookId",
+                        "name": "webhookId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/ws": {
+            "get": {
+                "tags": [
+                    "tasks"
+                ],
+                "summary": "WebSocket of the tasks: subscriptions, mutations, presence",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "the id of the last received event",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Last-Event-ID for the clients that cannot set it",
+                        "name": "lastEventId",
+                        "in": "query"
+                    },
+                    {
+                        "description": "the messages of the client; type - subscribe(topic: tasks, task:\u003ctaskId\u003e, project:\u003cprojectId\u003e), unsubscribe(topic), task.create(title), task.update(taskId, title, completed, ifMatch), task.delete(taskId, permanent, ifMatch), presence.view(taskId), presence.leave(taskId)",
+                        "name": "data",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/handler.WebSocketRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "the messages of the server; type - connected, ack, error, event, presence, heartbeat",
+                        "schema": {
+                            "$ref": "#/definitions/handler.WebSocketMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized"
+                    },
+                    "403": {
+                        "description": "Forbidden"
+                    },
+                    "500": {
+                        "description": "error type, comment",
+                        "schema": {
+                            "$ref": "#/definitions/handler.HTTPError"
+                        }
+                    }
+                }
+            }
+        }
     },
     "definitions": {
+        "handler.AddProjectMemberBody": {
+            "type": "object",
+            "required": [
+                "username"
+            ],
+            "properties": {
+                "username": {
+                    "type": "string",
+                    "example": "colleague"
+                }
+            }
+        },
+        "handler.AddProjectMemberResult": {
+            "type": "object",
+            "properties": {
+                "userId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.BatchTaskOperation": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "ifMatch": {
+                    "type": "string",
+                    "example": "\"3\""
+                },
+                "op": {
+                    "type": "string",
+                    "example": "update"
+                },
+                "permanent": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "taskId": {
+                    "type": "integer",
+                    "example": 24
+                },
+                "title": {
+                    "type": "string",
+                    "example": "some title"
+                }
+            }
+        },
+        "handler.BatchTaskOperationResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/handler.HTTPError"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 201
+                },
+                "taskId": {
+                    "type": "integer",
+                    "example": 24
+                }
+            }
+        },
+        "handler.BatchTasksBody": {
+            "type": "object",
+            "required": [
+                "operations"
+            ],
+            "properties": {
+                "atomic": {
+                    "description": "Atomic all or nothing, otherwise each operation is applied separately.",
+                    "type": "boolean",
+                    "example": true
+                },
+                "operations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.BatchTaskOperation"
+                    }
+                }
+            }
+        },
+        "handler.BatchTasksResult": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.BatchTaskOperationResult"
+                    }
+                }
+            }
+        },
+        "handler.CreateCalendarTokenResult": {
+            "type": "object",
+            "properties": {
+                "path": {
+                    "description": "Path of the feed on this server.",
+                    "type": "string",
+                    "example": "/api/v1/calendar/9f86d081884c7d659a2feaa0c55ad015.ics"
+                },
+                "token": {
+                    "description": "Token the secret of the feed, it is returned only here.",
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015"
+                }
+            }
+        },
+        "handler.CreateProjectBody": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "maxLength": 200,
+                    "example": "board"
+                }
+            }
+        },
+        "handler.CreateProjectResult": {
+            "type": "object",
+            "properties": {
+                "projectId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.CreateReminderBody": {
+            "type": "object",
+            "required": [
+                "channel"
+            ],
+            "properties": {
+                "beforeMinutes": {
+                    "description": "BeforeMinutes before the due date of the task, it follows the changes of the due date.",
+                    "type": "integer",
+                    "maximum": 525600,
+                    "minimum": 0,
+                    "example": 30
+                },
+                "channel": {
+                    "type": "string",
+                    "enum": [
+                        "webhook",
+                        "email",
+                        "inapp"
+                    ],
+                    "example": "inapp"
+                },
+                "email": {
+                    "description": "Email the recipient of the email channel.",
+                    "type": "string",
+                    "maxLength": 254,
+                    "example": ""
+                },
+                "remindAt": {
+                    "description": "RemindAt the time of the reminder, or BeforeMinutes.",
+                    "type": "string",
+                    "example": "2023-05-02T09:00:00Z"
+                }
+            }
+        },
+        "handler.CreateReminderResult": {
+            "type": "object",
+            "properties": {
+                "reminderId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.CreateTaskBody": {
+            "type": "object",
+            "required": [
+                "title"
+            ],
+            "properties": {
+                "title": {
+                    "type": "string",
+                    "example": "some title"
+                }
+            }
+        },
+        "handler.CreateTaskResult": {
+            "type": "object",
+            "properties": {
+                "taskId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.CreateUserBody": {
+            "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string",
+                    "example": "newuser"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "newuser"
+                }
+            }
+        },
+        "handler.CreateUserResult": {
+            "type": "object",
+            "properties": {
+                "userId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.CreateWebhookBody": {
+            "type": "object",
+            "required": [
+                "events",
+                "url"
+            ],
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "task.created",
+                        "task.completed"
+                    ]
+                },
+                "secret": {
+                    "description": "Secret empty - generated.",
+                    "type": "string",
+                    "maxLength": 128,
+                    "minLength": 16,
+                    "example": ""
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/hooks/tasks"
+                }
+            }
+        },
+        "handler.CreateWebhookResult": {
+            "type": "object",
+            "properties": {
+                "secret": {
+                    "description": "Secret the key of the signature of the deliveries, it is returned only here.",
+                    "type": "string"
+                },
+                "webhookId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.DeleteTasksResult": {
+            "type": "object",
+            "properties": {
+                "quantity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.GetMeResult": {
+            "type": "object",
+            "properties": {
+                "userId": {
+                    "type": "integer",
+                    "example": 24
+                },
+                "username": {
+                    "type": "string",
+                    "example": "newuser"
+                }
+            }
+        },
+        "handler.GraphQLError": {
+            "type": "object",
+            "properties": {
+                "extensions": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "locations": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.GraphQLLocation"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "array",
+                    "items": {}
+                }
+            }
+        },
+        "handler.GraphQLLocation": {
+            "type": "object",
+            "properties": {
+                "column": {
+                    "type": "integer"
+                },
+                "line": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.GraphQLRequest": {
+            "type": "object",
+            "required": [
+                "query"
+            ],
+            "properties": {
+                "operationName": {
+                    "type": "string"
+                },
+                "query": {
+                    "type": "string",
+                    "example": "{ tasks { edges { node { id title } } } }"
+                },
+                "variables": {
+                    "type": "object",
+                    "additionalProperties": {}
+                }
+            }
+        },
+        "handler.GraphQLResponse": {
+            "type": "object",
+            "properties": {
+                "data": {},
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.GraphQLError"
+                    }
+                }
+            }
+        },
         "handler.HTTPError": {
             "type": "object",
             "properties": {
-                "comment": {
+                "comment": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.ImportTaskRow": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "$ref": "#/definitions/handler.HTTPError"
+                },
+                "row": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "status": {
+                    "type": "string",
+                    "example": "created"
+                },
+                "taskId": {
+                    "type": "integer",
+                    "example": 24
+                },
+                "title": {
+                    "type": "string",
+                    "example": "some title"
+                }
+            }
+        },
+        "handler.ImportTasksResult": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "dryRun": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "duplicates": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "rows": {
+                    "description": "Rows all rows of the file in order.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.ImportTaskRow"
+                    }
+                }
+            }
+        },
+        "handler.NotificationPreferenceBody": {
+            "type": "object",
+            "required": [
+                "enabled",
+                "type"
+            ],
+            "properties": {
+                "enabled": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "task.reminder",
+                        "webhook.disabled",
+                        "project.shared",
+                        "task.assigned",
+                        "task.commented"
+                    ],
+                    "example": "task.reminder"
+                }
+            }
+        },
+        "handler.NotificationsResult": {
+            "type": "object",
+            "properties": {
+                "notifications": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Notification"
+                    }
+                },
+                "unread": {
+                    "description": "Unread the unread notifications of the user, not only of the page.",
+                    "type": "integer",
+                    "example": 3
+                },
+                "unreadByType": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "handler.ReadNotificationsResult": {
+            "type": "object",
+            "properties": {
+                "read": {
+                    "description": "Read the notifications marked read, the ones read before are not counted.",
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "handler.RestoreTasksResult": {
+            "type": "object",
+            "properties": {
+                "quantity": {
+                    "type": "integer"
+                }
+            }
+        },
+        "handler.SetTaskProjectBody": {
+            "type": "object",
+            "required": [
+                "projectId"
+            ],
+            "properties": {
+                "projectId": {
+                    "description": "ProjectID 0 - the task leaves its project.",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 5
+                }
+            }
+        },
+        "handler.TaskResponse": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "type": "string"
+                },
+                "created": {
+                    "type": "string"
+                },
+                "due": {
+                    "description": "Due nil - the task has no due date.",
+                    "type": "string"
+                },
+                "etag": {
+                    "type": "string",
+                    "example": "\"3\""
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "parentId": {
+                    "description": "ParentID 0 - the task is not a subtask.",
+                    "type": "integer"
+                },
+                "projectId": {
+                    "description": "ProjectID 0 - the task is not in a project.",
+                    "type": "integer"
+                },
+                "recurrence": {
+                    "description": "Recurrence the RRULE of RFC 5545 without the prefix, for example FREQ=WEEKLY;BYDAY=MO, the task repeats from Due.",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "boolean"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated": {
+                    "type": "string"
+                }
+            }
+        },
+        "handler.UpdateNotificationPreferencesBody": {
+            "type": "object",
+            "required": [
+                "preferences"
+            ],
+            "properties": {
+                "preferences": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/handler.NotificationPreferenceBody"
+                    }
+                }
+            }
+        },
+        "handler.UpdateTaskBody": {
+            "type": "object",
+            "required": [
+                "completed",
+                "title"
+            ],
+            "properties": {
+                "completed": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "due": {
+                    "type": "string",
+                    "example": "2023-05-02T09:30:00Z"
+                },
+                "recurrence": {
+                    "type": "string",
+                    "example": "FREQ=WEEKLY;BYDAY=TU"
+                },
+                "title": {
+                    "type": "string",
+                    "example": "some new title"
+                }
+            }
+        },
+        "handler.WebSocketMessage": {
+            "type": "object",
+            "properties": {
+                "connectionId": {
+                    "description": "ConnectionID of connected, the viewers are identified by it.",
+                    "type": "string",
+                    "example": "9f86d081884c7d65"
+                },
+                "error": {
+                    "$ref": "#/definitions/handler.HTTPError"
+                },
+                "event": {
+                    "$ref": "#/definitions/model.Event"
+                },
+                "id": {
+                    "description": "ID of the request of the ack and the error.",
+                    "type": "string",
+                    "example": "1"
+                },
+                "lastEventId": {
+                    "description": "LastEventID the position in the event log of connected and event, lastEventId of the reconnection.",
+                    "type": "integer",
+                    "example": 42
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "taskId": {
+                    "type": "integer",
+                    "example": 24
+                },
+                "topic": {
+                    "type": "string",
+                    "example": "tasks"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "ack"
+                },
+                "viewers": {
+                    "description": "Viewers of the task of presence and of the acks of presence and of the subscriptions to the task,\nwithout them nobody views the task.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/handler.WebSocketViewer"
+                    }
+                }
+            }
+        },
+        "handler.WebSocketRequest": {
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "id": {
+                    "type": "string",
+                    "example": "1"
+                },
+                "ifMatch": {
+                    "type": "string",
+                    "example": "\"3\""
+                },
+                "op": {
+                    "type": "string",
+                    "example": "update"
+                },
+                "permanent": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "taskId": {
+                    "type": "integer",
+                    "example": 24
+                },
+                "title": {
+                    "type": "string",
+                    "example": "some title"
+                },
+                "topic": {
+                    "description": "Topic of subscribe and unsubscribe.",
+                    "type": "string",
+                    "example": "tasks"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "task.update"
+                }
+            }
+        },
+        "handler.WebSocketViewer": {
+            "type": "object",
+            "properties": {
+                "connectionId": {
+                    "type": "string",
+                    "example": "9f86d081884c7d65"
+                },
+                "since": {
+                    "type": "string",
+                    "example": "2023-04-20T12:00:00Z"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "user"
+                }
+            }
+        },
+        "model.DeletedTask": {
+            "type": "object",
+            "properties": {
+                "completed": {
                     "type": "string"
                 },
-                "type": {
+                "created": {
+                    "type": "string"
+                },
+                "deleted": {
+                    "type": "string"
+                },
+                "due": {
+                    "description": "Due nil - the task has no due date.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "parentId": {
+                    "description": "ParentID 0 - the task is not a subtask.",
+                    "type": "integer"
+                },
+                "projectId": {
+                    "description": "ProjectID 0 - the task is not in a project.",
+                    "type": "integer"
+                },
+                "recurrence": {
+                    "description": "Recurrence the RRULE of RFC 5545 without the prefix, for example FREQ=WEEKLY;BYDAY=MO, the task repeats from Due.",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "boolean"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated": {
                     "type": "string"
                 }
             }
         },
-        "handler.createTaskBody": {
+        "model.Event": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "projectId": {
+                    "description": "ProjectID the project of the task, the event is also in the logs of its members.",
+                    "type": "integer"
+                },
+                "reminder": {
+                    "description": "Reminder the sent reminder of task.reminder.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Reminder"
+                        }
+                    ]
+                },
+                "task": {
+                    "description": "Task the task after the change, nil for task.deleted and user.deleted.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Task"
+                        }
+                    ]
+                },
+                "taskId": {
+                    "type": "integer"
+                },
+                "userId": {
+                    "description": "UserID the owner of the task or the deleted user.",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ExportedTask": {
             "type": "object",
-            "required": [
-                "title"
-            ],
             "properties": {
+                "completed": {
+                    "type": "string"
+                },
+                "created": {
+                    "type": "string"
+                },
+                "deleted": {
+                    "type": "string"
+                },
+                "due": {
+                    "description": "Due nil - the task has no due date.",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "parentId": {
+                    "description": "ParentID 0 - the task is not a subtask.",
+                    "type": "integer"
+                },
+                "projectId": {
+                    "description": "ProjectID 0 - the task is not in a project.",
+                    "type": "integer"
+                },
+                "recurrence": {
+                    "description": "Recurrence the RRULE of RFC 5545 without the prefix, for example FREQ=WEEKLY;BYDAY=MO, the task repeats from Due.",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "boolean"
+                },
                 "title": {
-                    "type": "string",
-                    "example": "some title"
+                    "type": "string"
+                },
+                "updated": {
+                    "type": "string"
                 }
             }
         },
-        "handler.createTaskResult": {
+        "model.Notification": {
             "type": "object",
             "properties": {
+                "created": {
+                    "type": "string"
+                },
+                "notificationId": {
+                    "type": "integer"
+                },
+                "read": {
+                    "description": "Read nil - unread.",
+                    "type": "string"
+                },
                 "taskId": {
+                    "description": "TaskID 0 - not of a task or the task is deleted.",
                     "type": "integer"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
                 }
             }
         },
-        "handler.createUserBody": {
+        "model.NotificationPreference": {
             "type": "object",
-            "required": [
-                "password",
-                "username"
-            ],
             "properties": {
-                "password": {
-                    "type": "string",
-                    "example": "newuser"
+                "enabled": {
+                    "type": "boolean"
                 },
-                "username": {
-                    "type": "string",
-                    "example": "newuser"
+                "type": {
+                    "type": "string"
                 }
             }
         },
-        "handler.createUserResult": {
+        "model.Project": {
             "type": "object",
             "properties": {
-                "userId": {
+                "created": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "ownerId": {
+                    "type": "integer"
+                },
+                "projectId": {
                     "type": "integer"
                 }
             }
         },
-        "handler.deleteTasksResult": {
+        "model.ProjectMember": {
             "type": "object",
             "properties": {
-                "quantity": {
+                "joined": {
+                    "type": "string"
+                },
+                "userId": {
                     "type": "integer"
+                },
+                "username": {
+                    "type": "string"
                 }
             }
         },
-        "handler.updateTaskBody": {
+        "model.Reminder": {
             "type": "object",
             "properties": {
-                "completed": {
-                    "type": "boolean",
-                    "example": true
+                "attempts": {
+                    "type": "integer"
                 },
-                "title": {
-                    "type": "string",
-                    "example": "some new title"
+                "beforeMinutes": {
+                    "type": "integer"
+                },
+                "channel": {
+                    "type": "string"
+                },
+                "created": {
+                    "type": "string"
+                },
+                "email": {
+                    "description": "Email the recipient of the email channel.",
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Error of the last failed attempt.",
+                    "type": "string"
+                },
+                "fireAt": {
+                    "description": "FireAt nil - the task has no due date, the reminder waits for it.",
+                    "type": "string"
+                },
+                "remindAt": {
+                    "description": "RemindAt the time of the reminder, nil - BeforeMinutes before the due date of the task.",
+                    "type": "string"
+                },
+                "reminderId": {
+                    "type": "integer"
+                },
+                "sent": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "taskId": {
+                    "type": "integer"
                 }
             }
         },
@@ -458,9 +3490,25 @@ var doc = `{
                 "created": {
                     "type": "string"
                 },
+                "due": {
+                    "description": "Due nil - the task has no due date.",
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
+                "parentId": {
+                    "description": "ParentID 0 - the task is not a subtask.",
+                    "type": "integer"
+                },
+                "projectId": {
+                    "description": "ProjectID 0 - the task is not in a project.",
+                    "type": "integer"
+                },
+                "recurrence": {
+                    "description": "Recurrence the RRULE of RFC 5545 without the prefix, for example FREQ=WEEKLY;BYDAY=MO, the task repeats from Due.",
+                    "type": "string"
+                },
                 "status": {
                     "type": "boolean"
                 },
@@ -471,6 +3519,93 @@ var doc = `{
                     "type": "string"
                 }
             }
+        },
+        "model.TaskChange": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string"
+                },
+                "changed": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                },
+                "new": {
+                    "type": "string"
+                },
+                "old": {
+                    "type": "string"
+                },
+                "revision": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.Webhook": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "Active false - disabled after too many failed attempts in a row.",
+                    "type": "boolean"
+                },
+                "created": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "failures": {
+                    "description": "Failures the failed attempts in a row.",
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "webhookId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created": {
+                    "type": "string"
+                },
+                "deliveryId": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "string"
+                },
+                "nextAttempt": {
+                    "type": "string"
+                },
+                "responseStatus": {
+                    "description": "ResponseStatus of the last attempt, 0 - no response.",
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "webhookId": {
+                    "type": "integer"
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -480,56 +3615,18 @@ var doc = `{
     }
 }`
 
-type swaggerInfo struct {
-	Version     string
-	Host        string
-	BasePath    string
-	Schemes     []string
-	Title       string
-	Description string
-}
-
 // SwaggerInfo holds exported Swagger Info so clients can modify it
-var SwaggerInfo = swaggerInfo{
-	Version:     "1.0",
-	Host:        "127.0.0.1:45222",
-	BasePath:    "/api",
-	Schemes:     []string{"http"},
-	Title:       "API Task Manager",
-	Description: "",
-}
-
-type s struct{}
-
-func (s *s) ReadDoc() string {
-	sInfo := SwaggerInfo
-	sInfo.Description = strings.Replace(sInfo.Description, "\n", "\\n", -1)
-
-	t, err := template.New("swagger_info").Funcs(template.FuncMap{
-		"marshal": func(v interface{}) string {
-			a, _ := json.Marshal(v)
-			return string(a)
-		},
-		"escape": func(v interface{}) string {
-			// escape tabs
-			str := strings.Replace(v.(string), "\t", "\\t", -1)
-			// replace " with \", and if that results in \\", replace that with \\\"
-			str = strings.Replace(str, "\"", "\\\"", -1)
-			return strings.Replace(str, "\\\\\"", "\\\\\\\"", -1)
-		},
-	}).Parse(doc)
-	if err != nil {
-		return doc
-	}
-
-	var tpl bytes.Buffer
-	if err := t.Execute(&tpl, sInfo); err != nil {
-		return doc
-	}
-
-	return tpl.String()
+var SwaggerInfo = &swag.Spec{
+	Version:          "1.0",
+	Host:             "127.0.0.1:45222",
+	BasePath:         "/api",
+	Schemes:          []string{"http"},
+	Title:            "API Task Manager",
+	Description:      "",
+	InfoInstanceName: "swagger",
+	SwaggerTemplate:  docTemplate,
 }
 
 func init() {
-	swag.Register(swag.Name, &s{})
+	swag.Register(SwaggerInfo.InstanceName(), SwaggerInfo)
 }
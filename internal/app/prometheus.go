@@ -8,8 +8,9 @@ import (
 )
 
 type Metrics struct {
-	MetricsRoute  string
-	RequestsTotal *prometheus.CounterVec
+	MetricsRoute      string
+	RequestsTotal     *prometheus.CounterVec
+	GRPCRequestsTotal *prometheus.CounterVec
 }
 
 func CreatePrometheusMetrics(metricsRoute string) Metrics {
@@ -24,10 +25,20 @@ func CreatePrometheusMetrics(metricsRoute string) Metrics {
 			},
 			[]string{"code", "method", "path"},
 		),
+		GRPCRequestsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "api_tm",
+				Subsystem: "request",
+				Name:      "grpc_requests_total",
+				Help:      "Total number of gRPC requests, their methods and codes",
+			},
+			[]string{"code", "method"},
+		),
 	}
 
 	prometheus.MustRegister(
 		metrics.RequestsTotal,
+		metrics.GRPCRequestsTotal,
 	)
 
 	metrics.RequestsTotal.WithLabelValues(strconv.Itoa(http.StatusOK), http.MethodGet, "/api/v1/tasks").Add(0)
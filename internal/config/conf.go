@@ -11,9 +11,21 @@ import (
 var errInvalidField = errors.New("invalid field")
 
 type Conf struct {
-	Server   server   `toml:"server"`
-	Postgres postgres `toml:"postgres"`
-	Logger   logger   `toml:"logger"`
+	Server       server       `toml:"server"`
+	Storage      storage      `toml:"storage"`
+	Postgres     postgres     `toml:"postgres"`
+	SQLite       sqlite       `toml:"sqlite"`
+	Logger       logger       `toml:"logger"`
+	Trash        trash        `toml:"trash"`
+	Idempotency  idempotency  `toml:"idempotency"`
+	Webhook      webhook      `toml:"webhook"`
+	Reminder     reminder     `toml:"reminder"`
+	SMTP         smtp         `toml:"smtp"`
+	Notification notification `toml:"notification"`
+	Events       events       `toml:"events"`
+	WebSocket    webSocket    `toml:"websocket"`
+	GRPC         grpc         `toml:"grpc"`
+	GraphQL      graphQL      `toml:"graphql"`
 }
 
 type server struct {
@@ -28,11 +40,26 @@ type server struct {
 	CORSAllowOrigins    []string `toml:"CORSAllowOrigins" validate:"min=1"`
 }
 
+type storage struct {
+	// Type memory - without a database, the data is lost on restart.
+	Type string `toml:"Type" validate:"oneof=postgres sqlite memory"`
+	// AutoMigrate false - the migrations are applied with the migrate command only.
+	AutoMigrate bool `toml:"AutoMigrate"`
+}
+
 type postgres struct {
 	ConnAddress  string `toml:"ConnAddress" validate:"min=10"`
 	MaxOpenConns int    `toml:"MaxOpenConns" validate:"gte=1,lte=100"`
 	MaxIdleConns int    `toml:"MaxIdleConns" validate:"gte=1,lte=100"`
 	QueryTimeout int    `toml:"QueryTimeout" validate:"gte=2,lte=60"`
+	// TxIsolationLevel empty - the database default.
+	TxIsolationLevel string `toml:"TxIsolationLevel" validate:"omitempty,oneof='read committed' 'repeatable read' serializable"`
+	TxMaxRetries     int    `toml:"TxMaxRetries" validate:"gte=0,lte=10"`
+}
+
+type sqlite struct {
+	Path         string `toml:"Path" validate:"min=1"`
+	QueryTimeout int    `toml:"QueryTimeout" validate:"gte=2,lte=60"`
 }
 
 type logger struct {
@@ -42,6 +69,70 @@ type logger struct {
 	MaxAgeDays int    `toml:"MaxAgeDays" validate:"gte=1,lte=720"`
 }
 
+type trash struct {
+	RetentionDays        int `toml:"RetentionDays" validate:"gte=1,lte=3650"`
+	PurgeIntervalMinutes int `toml:"PurgeIntervalMinutes" validate:"gte=1,lte=1440"`
+}
+
+type idempotency struct {
+	KeyTTLHours          int `toml:"KeyTTLHours" validate:"gte=1,lte=720"`
+	PurgeIntervalMinutes int `toml:"PurgeIntervalMinutes" validate:"gte=1,lte=1440"`
+}
+
+type webhook struct {
+	IntervalSeconds      int `toml:"IntervalSeconds" validate:"gte=1,lte=3600"`
+	TimeoutSeconds       int `toml:"TimeoutSeconds" validate:"gte=1,lte=60"`
+	MaxAttempts          int `toml:"MaxAttempts" validate:"gte=1,lte=20"`
+	RetryDelaySeconds    int `toml:"RetryDelaySeconds" validate:"gte=1,lte=3600"`
+	DisableAfterFailures int `toml:"DisableAfterFailures" validate:"gte=0,lte=1000"`
+	RetentionDays        int `toml:"RetentionDays" validate:"gte=1,lte=3650"`
+	PurgeIntervalMinutes int `toml:"PurgeIntervalMinutes" validate:"gte=1,lte=1440"`
+	// AllowPrivateNetworks the webhooks may target the loopback, private and link-local addresses.
+	AllowPrivateNetworks bool `toml:"AllowPrivateNetworks"`
+}
+
+type reminder struct {
+	IntervalSeconds   int `toml:"IntervalSeconds" validate:"gte=1,lte=3600"`
+	TimeoutSeconds    int `toml:"TimeoutSeconds" validate:"gte=1,lte=60"`
+	MaxAttempts       int `toml:"MaxAttempts" validate:"gte=1,lte=20"`
+	RetryDelaySeconds int `toml:"RetryDelaySeconds" validate:"gte=1,lte=3600"`
+}
+
+type smtp struct {
+	// Address empty - the email reminders are not sent.
+	Address  string `toml:"Address" validate:"omitempty,hostname_port"`
+	From     string `toml:"From" validate:"required_with=Address,omitempty,email"`
+	Username string `toml:"Username"`
+	Password string `toml:"Password"`
+}
+
+type notification struct {
+	RetentionDays        int `toml:"RetentionDays" validate:"gte=1,lte=3650"`
+	MaxPerUser           int `toml:"MaxPerUser" validate:"gte=0,lte=100000"`
+	PurgeIntervalMinutes int `toml:"PurgeIntervalMinutes" validate:"gte=1,lte=1440"`
+}
+
+type events struct {
+	HeartbeatSeconds     int `toml:"HeartbeatSeconds" validate:"gte=1,lte=300"`
+	RetentionDays        int `toml:"RetentionDays" validate:"gte=1,lte=3650"`
+	PurgeIntervalMinutes int `toml:"PurgeIntervalMinutes" validate:"gte=1,lte=1440"`
+}
+
+type webSocket struct {
+	SendQueueSize       int `toml:"SendQueueSize" validate:"gte=1,lte=10000"`
+	MaxMessageBytes     int `toml:"MaxMessageBytes" validate:"gte=1024,lte=10485760"`
+	WriteTimeoutSeconds int `toml:"WriteTimeoutSeconds" validate:"gte=1,lte=300"`
+}
+
+type grpc struct {
+	Port string `toml:"Port" validate:"numeric"`
+}
+
+type graphQL struct {
+	MaxDepth      int `toml:"MaxDepth" validate:"gte=1,lte=100"`
+	MaxComplexity int `toml:"MaxComplexity" validate:"gte=1,lte=100000"`
+}
+
 func GetFromFile(fileName string) (*Conf, error) {
 	var conf *Conf
 	if _, err := toml.DecodeFile(fileName, &conf); err != nil {
@@ -4,11 +4,15 @@ import (
 	"errors"
 
 	"github.com/lib/pq"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
 )
 
 const (
 	postgresUniqueConstraintError = "23505"
 	postgresNullValueError        = "23502"
+	postgresSerializationFailure  = "40001"
+	postgresDeadlockDetected      = "40P01"
 )
 
 func IsUniqueConstraintError(err error) bool {
@@ -19,7 +23,7 @@ func IsUniqueConstraintError(err error) bool {
 		}
 	}
 
-	return false
+	return isSQLiteError(err, sqlite3.SQLITE_CONSTRAINT_UNIQUE)
 }
 
 func IsNullValueError(err error) bool {
@@ -30,5 +34,28 @@ func IsNullValueError(err error) bool {
 		}
 	}
 
+	return isSQLiteError(err, sqlite3.SQLITE_CONSTRAINT_NOTNULL)
+}
+
+// IsSerializationError the transaction can be retried.
+func IsSerializationError(err error) bool {
+	var pqError *pq.Error
+	if errors.As(err, &pqError) {
+		if pqError.Code == postgresSerializationFailure || pqError.Code == postgresDeadlockDetected {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isSQLiteError(err error, code int) bool {
+	var sqliteError *sqlite.Error
+	if errors.As(err, &sqliteError) {
+		if sqliteError.Code() == code {
+			return true
+		}
+	}
+
 	return false
 }
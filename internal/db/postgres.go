@@ -31,16 +31,22 @@ type Conf struct {
 	MaxIdleConns  int
 }
 
-func (conf Conf) CreatePool(logger *logrus.Logger) (*sql.DB, error) {
-	envDockerConnAddress, ok := os.LookupEnv(dockerEnvConnAddress)
-	if ok {
-		conf.ConnAddress = envDockerConnAddress
+// Address of the connections of the pool, the environment variable of docker overrides ConnAddress.
+func (conf Conf) Address() string {
+	if address, ok := os.LookupEnv(dockerEnvConnAddress); ok {
+		return address
+	}
 
+	return conf.ConnAddress
+}
+
+func (conf Conf) CreatePool(logger *logrus.Logger) (*sql.DB, error) {
+	if _, ok := os.LookupEnv(dockerEnvConnAddress); ok {
 		time.Sleep(time.Second * delayBetweenAttempts)
 	}
 
 	for i := 0; i <= maxConnectionAttempts; i++ {
-		pool, err := createPool(conf.ConnAddress)
+		pool, err := createPool(conf.Address())
 		if err != nil {
 			if errors.Is(err, errTempError) {
 				logger.Warnf("connection attempt %d: %v", i+1, err)
@@ -0,0 +1,38 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	_ "modernc.org/sqlite"
+)
+
+// CreateSQLitePool the database file and its directory are created if they do not exist.
+// SQLite has one writer, so the pool has one connection: requests wait for it instead of failing with SQLITE_BUSY.
+func CreateSQLitePool(path string) (*sql.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("mkdir: %w", err)
+	}
+
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Set("_time_format", "sqlite")
+
+	pool, err := sql.Open("sqlite", "file:"+path+"?"+params.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("sql open: %w", err)
+	}
+
+	pool.SetMaxOpenConns(1)
+
+	if err := pool.Ping(); err != nil {
+		return nil, fmt.Errorf("ping: fail: %w", err)
+	}
+
+	return pool, nil
+}
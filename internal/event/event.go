@@ -0,0 +1,542 @@
+package event
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"taskmanager/internal/handler"
+	"taskmanager/internal/model"
+)
+
+const eventIDBytes = 16
+
+type Backend interface {
+	handler.Storage
+	SaveEvent(ctx context.Context, userID int, event model.Event) (int64, error)
+	EnqueueWebhookEvent(ctx context.Context, event model.Event) (int64, error)
+	GetProjectMemberIDs(ctx context.Context, projectID int) ([]int, error)
+	GetTaskProjects(ctx context.Context, userID int) (map[int]int, error)
+	GetTaskUserIDs(ctx context.Context, taskID int) ([]int, error)
+	CreateNotification(ctx context.Context, notification model.Notification) (int, error)
+	// Atomic runs fn in a transaction, the methods called with the context of fn join it.
+	Atomic(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Storage publishes the events of the changes of the backend to the event log and the webhooks in the transactions
+// of the changes: a change and its events are committed together, a failed publication fails the change.
+// The events of the tasks of a project are also in the logs of its members.
+// The notifications of the shared projects, the assigned tasks and the comments are created in the transactions too.
+// The event streams are woken after the commit.
+type Storage struct {
+	Backend
+	// Hub nil - the event streams are not woken, they read the log on the heartbeats.
+	Hub *Hub
+}
+
+func (s Storage) DeleteUser(ctx context.Context, userID int) error {
+	return s.change(ctx, func(ctx context.Context) ([]model.Event, error) {
+		if err := s.Backend.DeleteUser(ctx, userID); err != nil {
+			return nil, err
+		}
+
+		return []model.Event{{
+			Type:   model.EventUserDeleted,
+			UserID: userID,
+		}}, nil
+	})
+}
+
+func (s Storage) CreateTask(ctx context.Context, username, password, title string) (int, error) {
+	return s.createTask(ctx, username, password, title, s.Backend.CreateTask)
+}
+
+func (s Storage) CreateTaskWithInjection(ctx context.Context, username, password, title string) (int, error) {
+	return s.createTask(ctx, username, password, title, s.Backend.CreateTaskWithInjection)
+}
+
+func (s Storage) UpdateTask(
+	ctx context.Context, username, password string, taskID, version int, update model.TaskUpdate,
+) error {
+	return s.change(ctx, func(ctx context.Context) ([]model.Event, error) {
+		completedBefore := s.completed(ctx, username, password, taskID, update)
+
+		if err := s.Backend.UpdateTask(ctx, username, password, taskID, version, update); err != nil {
+			return nil, err
+		}
+
+		return s.taskEvents(ctx, username, password, s.updateEvent(completedBefore, update), taskID)
+	})
+}
+
+func (s Storage) RevertTask(ctx context.Context, username, password string, taskID, revision int) error {
+	return s.change(ctx, func(ctx context.Context) ([]model.Event, error) {
+		if err := s.Backend.RevertTask(ctx, username, password, taskID, revision); err != nil {
+			return nil, err
+		}
+
+		return s.taskEvents(ctx, username, password, model.EventTaskUpdated, taskID)
+	})
+}
+
+func (s Storage) DeleteTask(ctx context.Context, username, password string, taskID, version int) error {
+	return s.change(ctx, func(ctx context.Context) ([]model.Event, error) {
+		projects, err := s.taskProjects(ctx, username, password)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.Backend.DeleteTask(ctx, username, password, taskID, version); err != nil {
+			return nil, err
+		}
+
+		return s.taskDeletedEvents(ctx, username, password, []int{taskID}, projects)
+	})
+}
+
+func (s Storage) DeleteTaskPermanently(ctx context.Context, username, password string, taskID, version int) error {
+	return s.change(ctx, func(ctx context.Context) ([]model.Event, error) {
+		projects, err := s.taskProjects(ctx, username, password)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.Backend.DeleteTaskPermanently(ctx, username, password, taskID, version); err != nil {
+			return nil, err
+		}
+
+		return s.taskDeletedEvents(ctx, username, password, []int{taskID}, projects)
+	})
+}
+
+func (s Storage) DeleteTasks(ctx context.Context, username, password string) ([]int, error) {
+	return s.deleteTasks(ctx, username, password, s.Backend.DeleteTasks)
+}
+
+// DeleteTasksPermanently the tasks in the trash get task.deleted again, as with DeleteTaskPermanently.
+func (s Storage) DeleteTasksPermanently(ctx context.Context, username, password string) ([]int, error) {
+	return s.deleteTasks(ctx, username, password, s.Backend.DeleteTasksPermanently)
+}
+
+func (s Storage) RestoreTask(ctx context.Context, username, password string, taskID int) error {
+	return s.change(ctx, func(ctx context.Context) ([]model.Event, error) {
+		if err := s.Backend.RestoreTask(ctx, username, password, taskID); err != nil {
+			return nil, err
+		}
+
+		return s.taskEvents(ctx, username, password, model.EventTaskRestored, taskID)
+	})
+}
+
+func (s Storage) RestoreTasks(ctx context.Context, username, password string) ([]int, error) {
+	var taskIDs []int
+
+	if err := s.change(ctx, func(ctx context.Context) ([]model.Event, error) {
+		var err error
+
+		if taskIDs, err = s.Backend.RestoreTasks(ctx, username, password); err != nil {
+			return nil, err
+		}
+
+		return s.taskEvents(ctx, username, password, model.EventTaskRestored, taskIDs...)
+	}); err != nil {
+		return nil, err
+	}
+
+	return taskIDs, nil
+}
+
+func (s Storage) BatchTasks(
+	ctx context.Context, username, password string, operations []model.TaskOperation, atomic bool,
+) ([]model.TaskOperationResult, error) {
+	var results []model.TaskOperationResult
+
+	if err := s.change(ctx, func(ctx context.Context) ([]model.Event, error) {
+		completedBefore := make([]bool, len(operations))
+		for i, op := range operations {
+			if op.Op == model.TaskOperationUpdate {
+				completedBefore[i] = s.completed(ctx, username, password, op.TaskID, op.Update)
+			}
+		}
+
+		projects, err := s.taskProjects(ctx, username, password)
+		if err != nil {
+			return nil, err
+		}
+
+		if results, err = s.Backend.BatchTasks(ctx, username, password, operations, atomic); err != nil {
+			return nil, err
+		}
+
+		var events []model.Event
+
+		for i, result := range results {
+			if result.Err != nil || i >= len(operations) {
+				continue
+			}
+
+			var opEvents []model.Event
+
+			switch op := operations[i]; op.Op {
+			case model.TaskOperationCreate:
+				opEvents, err = s.taskEvents(ctx, username, password, model.EventTaskCreated, result.TaskID)
+
+			case model.TaskOperationUpdate:
+				opEvents, err = s.taskEvents(ctx, username, password, s.updateEvent(completedBefore[i], op.Update), op.TaskID)
+
+			case model.TaskOperationDelete:
+				opEvents, err = s.taskDeletedEvents(ctx, username, password, []int{op.TaskID}, projects)
+			}
+
+			// The task is deleted by a later operation, its task.deleted is published.
+			if errors.Is(err, model.ErrTaskNotFound) {
+				continue
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			events = append(events, opEvents...)
+		}
+
+		return events, nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// SetTaskProject the members of the project the task left get its task.updated too, the members of the project
+// it is moved to get task.assigned.
+func (s Storage) SetTaskProject(ctx context.Context, username, password string, taskID, projectID int) error {
+	var userIDs []int
+
+	if err := s.Backend.Atomic(ctx, func(ctx context.Context) error {
+		projects, err := s.taskProjects(ctx, username, password)
+		if err != nil {
+			return err
+		}
+
+		if err := s.Backend.SetTaskProject(ctx, username, password, taskID, projectID); err != nil {
+			return err
+		}
+
+		events, err := s.taskEvents(ctx, username, password, model.EventTaskUpdated, taskID)
+		if err != nil {
+			return err
+		}
+
+		if projectID != 0 && projectID != projects[taskID] {
+			if err := s.notifyTaskAssigned(ctx, events[0]); err != nil {
+				return err
+			}
+		}
+
+		userIDs, err = s.save(ctx, events, projects[taskID])
+
+		return err
+	}); err != nil {
+		return err
+	}
+
+	s.notify(userIDs)
+
+	return nil
+}
+
+func (s Storage) SetTaskParent(ctx context.Context, username, password string, taskID, parentID int) error {
+	return s.change(ctx, func(ctx context.Context) ([]model.Event, error) {
+		if err := s.Backend.SetTaskParent(ctx, username, password, taskID, parentID); err != nil {
+			return nil, err
+		}
+
+		return s.taskEvents(ctx, username, password, model.EventTaskUpdated, taskID)
+	})
+}
+
+func (s Storage) createTask(
+	ctx context.Context, username, password, title string,
+	createTask func(ctx context.Context, username, password, title string) (int, error),
+) (int, error) {
+	var taskID int
+
+	if err := s.change(ctx, func(ctx context.Context) ([]model.Event, error) {
+		var err error
+
+		if taskID, err = createTask(ctx, username, password, title); err != nil {
+			return nil, err
+		}
+
+		return s.taskEvents(ctx, username, password, model.EventTaskCreated, taskID)
+	}); err != nil {
+		return 0, err
+	}
+
+	return taskID, nil
+}
+
+// deleteTasks the events are of the ids returned by the deletion, in its transaction.
+func (s Storage) deleteTasks(
+	ctx context.Context, username, password string,
+	deleteTasks func(ctx context.Context, username, password string) ([]int, error),
+) ([]int, error) {
+	var taskIDs []int
+
+	if err := s.change(ctx, func(ctx context.Context) ([]model.Event, error) {
+		projects, err := s.taskProjects(ctx, username, password)
+		if err != nil {
+			return nil, err
+		}
+
+		if taskIDs, err = deleteTasks(ctx, username, password); err != nil {
+			return nil, err
+		}
+
+		return s.taskDeletedEvents(ctx, username, password, taskIDs, projects)
+	}); err != nil {
+		return nil, err
+	}
+
+	return taskIDs, nil
+}
+
+// change runs fn and saves the events returned by it in one transaction, the streams are woken after the commit.
+// After a serialization failure fn is run again.
+func (s Storage) change(ctx context.Context, fn func(ctx context.Context) ([]model.Event, error)) error {
+	var userIDs []int
+
+	if err := s.Backend.Atomic(ctx, func(ctx context.Context) error {
+		events, err := fn(ctx)
+		if err != nil {
+			return err
+		}
+
+		userIDs, err = s.save(ctx, events, 0)
+
+		return err
+	}); err != nil {
+		return err
+	}
+
+	s.notify(userIDs)
+
+	return nil
+}
+
+// completed the task is completed before the update, false if the update does not complete it.
+func (s Storage) completed(ctx context.Context, username, password string, taskID int, update model.TaskUpdate) bool {
+	if update.Status == nil || !*update.Status {
+		return false
+	}
+
+	task, err := s.Backend.GetTask(ctx, username, password, taskID)
+	if err != nil {
+		return false
+	}
+
+	return task.Status
+}
+
+func (s Storage) updateEvent(completedBefore bool, update model.TaskUpdate) string {
+	if update.Status != nil && *update.Status && !completedBefore {
+		return model.EventTaskCompleted
+	}
+
+	return model.EventTaskUpdated
+}
+
+// taskEvents the events of the tasks with the tasks after the change.
+func (s Storage) taskEvents(
+	ctx context.Context, username, password, eventType string, taskIDs ...int,
+) ([]model.Event, error) {
+	userID, err := s.Backend.GetUserIDByCredentials(ctx, username, password)
+	if err != nil {
+		return nil, fmt.Errorf("publish %s: get user: %w", eventType, err)
+	}
+
+	events := make([]model.Event, 0, len(taskIDs))
+
+	for _, taskID := range taskIDs {
+		task, err := s.Backend.GetTask(ctx, username, password, taskID)
+		if err != nil {
+			return nil, fmt.Errorf("publish %s: get task %d: %w", eventType, taskID, err)
+		}
+
+		events = append(events, model.Event{
+			Type:      eventType,
+			UserID:    userID,
+			TaskID:    taskID,
+			ProjectID: task.ProjectID,
+			Task:      &task,
+		})
+	}
+
+	return events, nil
+}
+
+// taskDeletedEvents projects - the projects of the tasks before the deletion.
+func (s Storage) taskDeletedEvents(
+	ctx context.Context, username, password string, taskIDs []int, projects map[int]int,
+) ([]model.Event, error) {
+	userID, err := s.Backend.GetUserIDByCredentials(ctx, username, password)
+	if err != nil {
+		return nil, fmt.Errorf("publish %s: get user: %w", model.EventTaskDeleted, err)
+	}
+
+	events := make([]model.Event, 0, len(taskIDs))
+
+	for _, taskID := range taskIDs {
+		events = append(events, model.Event{
+			Type:      model.EventTaskDeleted,
+			UserID:    userID,
+			TaskID:    taskID,
+			ProjectID: projects[taskID],
+		})
+	}
+
+	return events, nil
+}
+
+// logEvent the event in the log of the user.
+type logEvent struct {
+	userID int
+	event  model.Event
+}
+
+// save writes the events to the webhook deliveries and to the logs of the owners and of the members of the projects
+// of the tasks, leftProjectID - the project the task left, its members get the events too.
+// The logs are written in the order of the users, so that the concurrent changes do not deadlock on their locks.
+// Returns the users of the written logs.
+func (s Storage) save(ctx context.Context, events []model.Event, leftProjectID int) ([]int, error) {
+	var logEvents []logEvent
+
+	for i := range events {
+		event := &events[i]
+
+		id, err := newEventID()
+		if err != nil {
+			return nil, fmt.Errorf("publish %s: %w", event.Type, err)
+		}
+
+		event.ID = id
+		event.Created = time.Now().UTC()
+
+		if _, err := s.Backend.EnqueueWebhookEvent(ctx, *event); err != nil {
+			return nil, fmt.Errorf("publish %s: %w", event.Type, err)
+		}
+
+		// The log is of the events visible to the user, the deleted user has no stream.
+		if event.Type == model.EventUserDeleted {
+			continue
+		}
+
+		userIDs, err := s.logUserIDs(ctx, *event, leftProjectID)
+		if err != nil {
+			return nil, fmt.Errorf("publish %s: %w", event.Type, err)
+		}
+
+		for _, userID := range userIDs {
+			logEvents = append(logEvents, logEvent{userID: userID, event: *event})
+		}
+	}
+
+	sort.SliceStable(logEvents, func(i, j int) bool {
+		return logEvents[i].userID < logEvents[j].userID
+	})
+
+	var userIDs []int
+
+	for _, e := range logEvents {
+		if _, err := s.Backend.SaveEvent(ctx, e.userID, e.event); err != nil {
+			return nil, fmt.Errorf("publish %s: save event: %w", e.event.Type, err)
+		}
+
+		if len(userIDs) == 0 || userIDs[len(userIDs)-1] != e.userID {
+			userIDs = append(userIDs, e.userID)
+		}
+	}
+
+	return userIDs, nil
+}
+
+// logUserIDs the owner of the task and the members of its projects.
+func (s Storage) logUserIDs(ctx context.Context, event model.Event, leftProjectID int) ([]int, error) {
+	userIDs := []int{event.UserID}
+
+	for _, projectID := range []int{event.ProjectID, leftProjectID} {
+		if projectID == 0 {
+			continue
+		}
+
+		memberIDs, err := s.Backend.GetProjectMemberIDs(ctx, projectID)
+		if err != nil {
+			return nil, fmt.Errorf("get project members: %w", err)
+		}
+
+		for _, memberID := range memberIDs {
+			if !containsInt(userIDs, memberID) {
+				userIDs = append(userIDs, memberID)
+			}
+		}
+	}
+
+	return userIDs, nil
+}
+
+// taskProjects the projects of the tasks of the user before the deletion, the deleted tasks have none.
+// The credentials are checked by the change.
+func (s Storage) taskProjects(ctx context.Context, username, password string) (map[int]int, error) {
+	userID, err := s.Backend.GetUserIDByCredentials(ctx, username, password)
+	if errors.Is(err, model.ErrUserNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+
+	projects, err := s.Backend.GetTaskProjects(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get task projects: %w", err)
+	}
+
+	return projects, nil
+}
+
+// notify wakes the streams of the users of the committed events.
+// The streams of the other instances are woken by the NOTIFY of Postgres.
+func (s Storage) notify(userIDs []int) {
+	if s.Hub == nil {
+		return
+	}
+
+	for _, userID := range userIDs {
+		s.Hub.Notify(userID)
+	}
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newEventID() (string, error) {
+	b := make([]byte, eventIDBytes)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("event id: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,672 @@
+package event
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taskmanager/internal/app"
+	"taskmanager/internal/db"
+	"taskmanager/internal/handler"
+	"taskmanager/internal/model"
+	"taskmanager/internal/transport/httpsrv"
+	"taskmanager/internal/worker"
+)
+
+const (
+	testManageUsername = "admin"
+	testManagePassword = "admin"
+)
+
+type receivedEvent struct {
+	event     model.Event
+	signature string
+	body      []byte
+}
+
+type testAPI struct {
+	t      *testing.T
+	server *httptest.Server
+}
+
+func (a testAPI) do(method, path, username, password string, body any, res any) int {
+	a.t.Helper()
+
+	var reqBody io.Reader
+
+	if body != nil {
+		b, err := json.Marshal(body)
+		require.NoError(a.t, err)
+
+		reqBody = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, a.server.URL+path, reqBody)
+	require.NoError(a.t, err)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(username, password)
+
+	resp, err := a.server.Client().Do(req)
+	require.NoError(a.t, err)
+
+	defer resp.Body.Close()
+
+	if res != nil {
+		require.NoError(a.t, json.NewDecoder(resp.Body).Decode(res))
+	}
+
+	return resp.StatusCode
+}
+
+func TestWebhooks(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	gin.SetMode(gin.TestMode)
+
+	storage := model.NewMemory()
+
+	router := httpsrv.Conf{
+		ManageUsername:         testManageUsername,
+		ManagePassword:         testManagePassword,
+		IdempotencyKeyTTLHours: 1,
+		// The receiver listens on the loopback.
+		WebhookAllowPrivateNetworks: true,
+	}.NewRouter(context.Background(), Storage{Backend: storage}, app.Metrics{MetricsRoute: "/metrics"})
+
+	api := testAPI{t: t, server: httptest.NewServer(router)}
+	t.Cleanup(api.server.Close)
+
+	received := make(chan receivedEvent, 10)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		var event model.Event
+		if err := json.Unmarshal(body, &event); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+
+			return
+		}
+
+		received <- receivedEvent{
+			event:     event,
+			signature: r.Header.Get(worker.HeaderWebhookSignature),
+			body:      body,
+		}
+	}))
+	t.Cleanup(receiver.Close)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(failing.Close)
+
+	var user handler.CreateUserResult
+
+	require.Equal(t, http.StatusCreated, api.do(http.MethodPost, "/api/v1/manage/user",
+		testManageUsername, testManagePassword, handler.CreateUserBody{Username: "hookuser", Password: "hookuser"}, &user))
+
+	var userWebhook, managerWebhook, failingWebhook handler.CreateWebhookResult
+
+	require.Equal(t, http.StatusCreated, api.do(http.MethodPost, "/api/v1/webhooks/", "hookuser", "hookuser",
+		handler.CreateWebhookBody{
+			URL:    receiver.URL,
+			Events: []string{model.EventTaskCreated, model.EventTaskCompleted, model.EventTaskDeleted},
+		}, &userWebhook))
+	require.Len(t, userWebhook.Secret, 64)
+
+	require.Equal(t, http.StatusCreated, api.do(http.MethodPost, "/api/v1/manage/webhooks/",
+		testManageUsername, testManagePassword,
+		handler.CreateWebhookBody{
+			URL:    receiver.URL,
+			Events: []string{model.EventUserDeleted},
+			Secret: "manager-secret-key",
+		}, &managerWebhook))
+
+	require.Equal(t, http.StatusCreated, api.do(http.MethodPost, "/api/v1/webhooks/", "hookuser", "hookuser",
+		handler.CreateWebhookBody{
+			URL:    failing.URL,
+			Events: []string{model.EventTaskCreated},
+		}, &failingWebhook))
+
+	var httpErr handler.HTTPError
+
+	assert.Equal(t, http.StatusBadRequest, api.do(http.MethodPost, "/api/v1/webhooks/", "hookuser", "hookuser",
+		handler.CreateWebhookBody{
+			URL:    receiver.URL,
+			Events: []string{model.EventUserDeleted},
+		}, &httpErr))
+	assert.Equal(t, "PARAMETER_REQUIRED", httpErr.Type)
+
+	assert.Equal(t, http.StatusForbidden, api.do(http.MethodGet, "/api/v1/webhooks/", "hookuser", "wrong", nil, nil))
+
+	var task handler.CreateTaskResult
+
+	require.Equal(t, http.StatusCreated, api.do(http.MethodPost, "/api/v1/task/", "hookuser", "hookuser",
+		handler.CreateTaskBody{Title: "hooked"}, &task))
+
+	completed := true
+
+	require.Equal(t, http.StatusNoContent, api.do(http.MethodPut, "/api/v1/task/"+strconv.Itoa(task.TaskID),
+		"hookuser", "hookuser", handler.UpdateTaskBody{Title: "hooked", Completed: &completed}, nil))
+
+	// task.updated: the webhook is not subscribed.
+	require.Equal(t, http.StatusNoContent, api.do(http.MethodPut, "/api/v1/task/"+strconv.Itoa(task.TaskID),
+		"hookuser", "hookuser", handler.UpdateTaskBody{Title: "renamed", Completed: &completed}, nil))
+
+	require.Equal(t, http.StatusNoContent, api.do(http.MethodDelete, "/api/v1/task/"+strconv.Itoa(task.TaskID),
+		"hookuser", "hookuser", nil, nil))
+
+	conf := worker.WebhookConf{
+		IntervalSeconds:      1,
+		TimeoutSeconds:       5,
+		MaxAttempts:          3,
+		RetryDelaySeconds:    60,
+		DisableAfterFailures: 1,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go conf.RunWebhookDeliveries(ctx, storage, &http.Client{}, logger)
+
+	events := map[string]receivedEvent{}
+
+	for len(events) < 3 {
+		select {
+		case e := <-received:
+			events[e.event.Type] = e
+
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "webhook deliveries timeout", "received: %v", events)
+		}
+	}
+
+	created := events[model.EventTaskCreated]
+	require.NotNil(t, created.event.Task)
+	assert.Equal(t, "hooked", created.event.Task.Title)
+	assert.Equal(t, user.UserID, created.event.UserID)
+	assert.Equal(t, task.TaskID, created.event.TaskID)
+
+	assert.Equal(t, worker.SignWebhookPayload(userWebhook.Secret, signatureTime(t, created.signature), created.body),
+		created.signature)
+
+	require.NotNil(t, events[model.EventTaskCompleted].event.Task)
+	assert.True(t, events[model.EventTaskCompleted].event.Task.Status)
+	assert.Nil(t, events[model.EventTaskDeleted].event.Task)
+
+	t.Run("deliveries", func(t *testing.T) {
+		var deliveries []model.WebhookDelivery
+
+		require.Eventually(t, func() bool {
+			deliveries = nil
+
+			api.do(http.MethodGet, "/api/v1/webhooks/"+strconv.Itoa(userWebhook.WebhookID)+"/deliveries",
+				"hookuser", "hookuser", nil, &deliveries)
+
+			return len(deliveries) == 3 && deliveries[0].Status == model.WebhookDeliverySucceeded
+		}, 5*time.Second, 50*time.Millisecond)
+
+		assert.Equal(t, model.EventTaskDeleted, deliveries[0].Event)
+		assert.Equal(t, 1, deliveries[0].Attempts)
+		assert.Equal(t, http.StatusOK, deliveries[0].ResponseStatus)
+
+		assert.Equal(t, http.StatusBadRequest, api.do(http.MethodGet,
+			"/api/v1/webhooks/"+strconv.Itoa(managerWebhook.WebhookID)+"/deliveries",
+			"hookuser", "hookuser", nil, &httpErr))
+		assert.Equal(t, "WEBHOOK_NOT_FOUND", httpErr.Type)
+	})
+
+	t.Run("disable", func(t *testing.T) {
+		var webhooks []model.Webhook
+
+		require.Eventually(t, func() bool {
+			webhooks = nil
+
+			api.do(http.MethodGet, "/api/v1/webhooks/", "hookuser", "hookuser", nil, &webhooks)
+
+			return len(webhooks) == 2 && !webhooks[1].Active
+		}, 5*time.Second, 50*time.Millisecond)
+
+		assert.Equal(t, failingWebhook.WebhookID, webhooks[1].ID)
+		assert.Equal(t, 1, webhooks[1].Failures)
+
+		var deliveries []model.WebhookDelivery
+
+		api.do(http.MethodGet, "/api/v1/webhooks/"+strconv.Itoa(failingWebhook.WebhookID)+"/deliveries",
+			"hookuser", "hookuser", nil, &deliveries)
+		require.Len(t, deliveries, 1)
+		assert.Equal(t, model.WebhookDeliveryFailed, deliveries[0].Status)
+		assert.Equal(t, http.StatusServiceUnavailable, deliveries[0].ResponseStatus)
+
+		require.Equal(t, http.StatusNoContent, api.do(http.MethodPost,
+			"/api/v1/webhooks/"+strconv.Itoa(failingWebhook.WebhookID)+"/enable", "hookuser", "hookuser", nil, nil))
+
+		require.Equal(t, http.StatusNoContent, api.do(http.MethodDelete,
+			"/api/v1/webhooks/"+strconv.Itoa(failingWebhook.WebhookID), "hookuser", "hookuser", nil, nil))
+	})
+
+	t.Run("user deleted", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, api.do(http.MethodDelete,
+			"/api/v1/manage/user/"+strconv.Itoa(user.UserID), testManageUsername, testManagePassword, nil, nil))
+
+		select {
+		case e := <-received:
+			assert.Equal(t, model.EventUserDeleted, e.event.Type)
+			assert.Equal(t, user.UserID, e.event.UserID)
+			assert.Equal(t, worker.SignWebhookPayload("manager-secret-key", signatureTime(t, e.signature), e.body),
+				e.signature)
+
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "user.deleted delivery timeout")
+		}
+	})
+}
+
+func signatureTime(t *testing.T, signature string) time.Time {
+	t.Helper()
+
+	require.Regexp(t, `^t=\d+,v1=[0-9a-f]{64}$`, signature)
+
+	unix, err := strconv.ParseInt(signature[len("t="):bytes.IndexByte([]byte(signature), ',')], 10, 64)
+	require.NoError(t, err)
+
+	return time.Unix(unix, 0)
+}
+
+type sseMessage struct {
+	id      string
+	event   string
+	data    string
+	comment string
+}
+
+// readSSEMessage the next message of the stream, the retry field is skipped.
+func readSSEMessage(t *testing.T, r *bufio.Reader) sseMessage {
+	t.Helper()
+
+	var m sseMessage
+
+	for {
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+
+		line = strings.TrimSuffix(line, "\n")
+
+		if line == "" {
+			if m != (sseMessage{}) {
+				return m
+			}
+
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "id":
+			m.id = value
+		case "event":
+			m.event = value
+		case "data":
+			m.data = value
+		case "":
+			m.comment = value
+		}
+	}
+}
+
+// readSSEEvent the next message of the stream is the event of the type.
+func readSSEEvent(t *testing.T, r *bufio.Reader, eventType string) model.Event {
+	t.Helper()
+
+	m := readSSEMessage(t, r)
+	require.Equal(t, eventType, m.event)
+
+	var event model.Event
+
+	require.NoError(t, json.Unmarshal([]byte(m.data), &event))
+
+	return event
+}
+
+func TestTaskEvents(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	gin.SetMode(gin.TestMode)
+
+	hub := NewHub()
+
+	router := httpsrv.Conf{
+		ManageUsername:         testManageUsername,
+		ManagePassword:         testManagePassword,
+		EventsHeartbeatSeconds: 1,
+		EventSubscriber:        hub,
+	}.NewRouter(context.Background(), Storage{Backend: model.NewMemory(), Hub: hub},
+		app.Metrics{MetricsRoute: "/metrics"})
+
+	api := testAPI{t: t, server: httptest.NewServer(router)}
+	t.Cleanup(api.server.Close)
+
+	for _, username := range []string{"streamuser", "otheruser"} {
+		require.Equal(t, http.StatusCreated, api.do(http.MethodPost, "/api/v1/manage/user",
+			testManageUsername, testManagePassword, handler.CreateUserBody{Username: username, Password: username}, nil))
+	}
+
+	stream := func(t *testing.T, lastEventID string) *bufio.Reader {
+		t.Helper()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, api.server.URL+"/api/v1/tasks/events", nil)
+		require.NoError(t, err)
+
+		req.SetBasicAuth("streamuser", "streamuser")
+
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+
+		resp, err := api.server.Client().Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+		return bufio.NewReader(resp.Body)
+	}
+
+	var before, task handler.CreateTaskResult
+
+	// Before the stream, it is not sent without Last-Event-ID.
+	require.Equal(t, http.StatusCreated, api.do(http.MethodPost, "/api/v1/task/", "streamuser", "streamuser",
+		handler.CreateTaskBody{Title: "before"}, &before))
+
+	r := stream(t, "")
+
+	require.Equal(t, http.StatusCreated, api.do(http.MethodPost, "/api/v1/task/", "otheruser", "otheruser",
+		handler.CreateTaskBody{Title: "other"}, nil))
+
+	require.Equal(t, http.StatusCreated, api.do(http.MethodPost, "/api/v1/task/", "streamuser", "streamuser",
+		handler.CreateTaskBody{Title: "streamed"}, &task))
+
+	created := readSSEMessage(t, r)
+	assert.Equal(t, model.EventTaskCreated, created.event)
+	require.NotEmpty(t, created.id)
+
+	var event model.Event
+
+	require.NoError(t, json.Unmarshal([]byte(created.data), &event))
+	require.NotNil(t, event.Task)
+	assert.Equal(t, "streamed", event.Task.Title)
+	assert.Equal(t, task.TaskID, event.TaskID)
+
+	require.Equal(t, http.StatusNoContent, api.do(http.MethodDelete, "/api/v1/task/"+strconv.Itoa(task.TaskID),
+		"streamuser", "streamuser", nil, nil))
+
+	deleted := readSSEMessage(t, r)
+	assert.Equal(t, model.EventTaskDeleted, deleted.event)
+
+	require.Equal(t, http.StatusNoContent, api.do(http.MethodPost,
+		"/api/v1/task/"+strconv.Itoa(task.TaskID)+"/restore", "streamuser", "streamuser", nil, nil))
+
+	restored := readSSEMessage(t, r)
+	assert.Equal(t, model.EventTaskRestored, restored.event)
+
+	event = model.Event{}
+
+	require.NoError(t, json.Unmarshal([]byte(restored.data), &event))
+	require.NotNil(t, event.Task)
+	assert.Equal(t, "streamed", event.Task.Title)
+
+	// The events of the tasks deleted by the request, the task of the other user is kept.
+	require.Equal(t, http.StatusOK, api.do(http.MethodDelete, "/api/v1/tasks/", "streamuser", "streamuser", nil, nil))
+
+	first := readSSEEvent(t, r, model.EventTaskDeleted)
+	second := readSSEEvent(t, r, model.EventTaskDeleted)
+	assert.ElementsMatch(t, []int{before.TaskID, task.TaskID}, []int{first.TaskID, second.TaskID})
+
+	// No events - the heartbeat.
+	assert.Equal(t, "heartbeat", readSSEMessage(t, r).comment)
+
+	t.Run("resume", func(t *testing.T) {
+		r := stream(t, created.id)
+
+		m := readSSEMessage(t, r)
+		assert.Equal(t, deleted.id, m.id)
+		assert.Equal(t, model.EventTaskDeleted, m.event)
+	})
+
+	t.Run("invalid last event id", func(t *testing.T) {
+		var httpErr handler.HTTPError
+
+		assert.Equal(t, http.StatusBadRequest, api.do(http.MethodGet, "/api/v1/tasks/events?lastEventId=x",
+			"streamuser", "streamuser", nil, &httpErr))
+		assert.Equal(t, "PARAMETER_REQUIRED", httpErr.Type)
+
+		assert.Equal(t, http.StatusForbidden, api.do(http.MethodGet, "/api/v1/tasks/events",
+			"streamuser", "wrong", nil, nil))
+	})
+}
+
+var errSaveEvent = errors.New("save event")
+
+// failingEventsBackend the events are not saved to the log.
+type failingEventsBackend struct {
+	model.SQLite
+}
+
+func (b failingEventsBackend) SaveEvent(context.Context, int, model.Event) (int64, error) {
+	return 0, errSaveEvent
+}
+
+func TestFailedPublicationRollsBackChange(t *testing.T) {
+	ctx := context.Background()
+
+	pool, err := db.CreateSQLitePool(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, pool.Close())
+	})
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	backend := model.SQLite{Pool: pool, Logger: logger, QueryTimeout: 5}
+	require.NoError(t, backend.Migrate(ctx))
+
+	userID, err := backend.CreateNewUser(ctx, "user", "password")
+	require.NoError(t, err)
+
+	taskID, err := backend.CreateTask(ctx, "user", "password", "kept")
+	require.NoError(t, err)
+
+	webhookID, err := backend.CreateWebhook(ctx, userID, model.Webhook{
+		URL:    "https://example.com/hooks",
+		Events: []string{model.EventTaskCreated, model.EventTaskDeleted},
+		Secret: "secret",
+	})
+	require.NoError(t, err)
+
+	s := Storage{Backend: failingEventsBackend{SQLite: backend}}
+
+	_, err = s.CreateTask(ctx, "user", "password", "rolled back")
+	assert.ErrorIs(t, err, errSaveEvent)
+
+	_, err = s.DeleteTasks(ctx, "user", "password")
+	assert.ErrorIs(t, err, errSaveEvent)
+
+	tasks, err := backend.GetTasks(ctx, "user", "password")
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, taskID, tasks[0].ID)
+
+	// The deliveries enqueued before the failure are rolled back with the change.
+	deliveries, err := backend.GetWebhookDeliveries(ctx, userID, webhookID, 10)
+	require.NoError(t, err)
+	assert.Empty(t, deliveries)
+
+	_, err = Storage{Backend: backend}.CreateTask(ctx, "user", "password", "published")
+	require.NoError(t, err)
+
+	deliveries, err = backend.GetWebhookDeliveries(ctx, userID, webhookID, 10)
+	require.NoError(t, err)
+	assert.Len(t, deliveries, 1)
+}
+
+func TestBatchEventsOfDeletedTask(t *testing.T) {
+	ctx := context.Background()
+
+	backend := model.NewMemory()
+
+	userID, err := backend.CreateNewUser(ctx, "user", "password")
+	require.NoError(t, err)
+
+	taskID, err := backend.CreateTask(ctx, "user", "password", "task")
+	require.NoError(t, err)
+
+	title := "renamed"
+
+	results, err := Storage{Backend: backend}.BatchTasks(ctx, "user", "password", []model.TaskOperation{
+		{Op: model.TaskOperationUpdate, TaskID: taskID, Update: model.TaskUpdate{Title: &title}},
+		{Op: model.TaskOperationDelete, TaskID: taskID},
+	}, true)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.NoError(t, results[0].Err)
+	assert.NoError(t, results[1].Err)
+
+	events, err := backend.GetEvents(ctx, userID, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, model.EventTaskDeleted, events[0].Type)
+	assert.Equal(t, taskID, events[0].TaskID)
+}
+
+func TestNotificationTriggers(t *testing.T) {
+	ctx := context.Background()
+
+	pool, err := db.CreateSQLitePool(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, pool.Close())
+	})
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	backend := model.SQLite{Pool: pool, Logger: logger, QueryTimeout: 5}
+	require.NoError(t, backend.Migrate(ctx))
+
+	s := Storage{Backend: backend}
+
+	ownerID, err := backend.CreateNewUser(ctx, "owner", "password")
+	require.NoError(t, err)
+
+	memberID, err := backend.CreateNewUser(ctx, "member", "password")
+	require.NoError(t, err)
+
+	projectID, err := backend.CreateProject(ctx, ownerID, "board")
+	require.NoError(t, err)
+
+	taskID, err := backend.CreateTask(ctx, "owner", "password", "buy milk")
+	require.NoError(t, err)
+
+	notifications := func(t *testing.T, userID int) []model.Notification {
+		t.Helper()
+
+		notifications, err := backend.GetNotifications(ctx, userID, model.NotificationQuery{Limit: 10})
+		require.NoError(t, err)
+
+		return notifications
+	}
+
+	t.Run("share", func(t *testing.T) {
+		id, added, err := s.AddProjectMember(ctx, ownerID, projectID, "member")
+		require.NoError(t, err)
+		assert.True(t, added)
+		assert.Equal(t, memberID, id)
+
+		received := notifications(t, memberID)
+		require.Len(t, received, 1)
+		assert.Equal(t, model.NotificationProjectShared, received[0].Type)
+		assert.Equal(t, `owner shared the project "board"`, received[0].Title)
+
+		// The member already in the project is not notified again.
+		_, added, err = s.AddProjectMember(ctx, ownerID, projectID, "member")
+		require.NoError(t, err)
+		assert.False(t, added)
+		assert.Len(t, notifications(t, memberID), 1)
+		assert.Empty(t, notifications(t, ownerID))
+	})
+
+	t.Run("assign", func(t *testing.T) {
+		require.NoError(t, s.SetTaskProject(ctx, "owner", "password", taskID, projectID))
+
+		received := notifications(t, memberID)
+		require.Len(t, received, 2)
+		assert.Equal(t, model.NotificationTaskAssigned, received[0].Type)
+		assert.Equal(t, `"buy milk" assigned to the project "board"`, received[0].Title)
+		assert.Equal(t, taskID, received[0].TaskID)
+		assert.Empty(t, notifications(t, ownerID), "the owner moved the task")
+
+		// The task already in the project is not assigned again.
+		require.NoError(t, s.SetTaskProject(ctx, "owner", "password", taskID, projectID))
+		assert.Len(t, notifications(t, memberID), 2)
+	})
+
+	t.Run("comment", func(t *testing.T) {
+		_, err := s.CreateTaskComment(ctx, memberID, taskID, "which one?")
+		require.NoError(t, err)
+
+		received := notifications(t, ownerID)
+		require.Len(t, received, 1)
+		assert.Equal(t, model.NotificationTaskCommented, received[0].Type)
+		assert.Equal(t, "member commented: which one?", received[0].Title)
+		assert.Equal(t, taskID, received[0].TaskID)
+		assert.Len(t, notifications(t, memberID), 2, "the author is not notified")
+
+		// The disabled type is not created.
+		require.NoError(t, backend.SetNotificationPreferences(ctx, memberID, []model.NotificationPreference{
+			{Type: model.NotificationTaskCommented, Enabled: false},
+		}))
+
+		_, err = s.CreateTaskComment(ctx, ownerID, taskID, strings.Repeat("a", maxNotificationExcerpt+1))
+		require.NoError(t, err)
+		assert.Len(t, notifications(t, memberID), 2)
+		assert.Len(t, notifications(t, ownerID), 1)
+
+		_, err = s.CreateTaskComment(ctx, memberID, taskID+1000, "lost")
+		assert.ErrorIs(t, err, model.ErrTaskNotFound)
+		assert.Len(t, notifications(t, ownerID), 1)
+	})
+}
+
+func TestNotificationExcerpt(t *testing.T) {
+	assert.Equal(t, "short", notificationExcerpt("short"))
+	assert.Equal(t, strings.Repeat("é", maxNotificationExcerpt)+"…",
+		notificationExcerpt(strings.Repeat("é", maxNotificationExcerpt+1)))
+}
@@ -0,0 +1,145 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+
+	"taskmanager/internal/model"
+)
+
+const (
+	listenerMinReconnectInterval = time.Second
+	listenerMaxReconnectInterval = time.Minute
+	// listenerPingInterval the broken connection is detected by the ping if no notifications come.
+	listenerPingInterval = 90 * time.Second
+)
+
+// Hub wakes the event streams of the users on this instance after their events are saved to the log.
+// The streams read the events from the log, the notifications carry no data and are coalesced.
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[int]map[chan struct{}]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		subscribers: make(map[int]map[chan struct{}]struct{}),
+	}
+}
+
+// Subscribe the channel receives after new events of the user, the returned func unsubscribes.
+func (h *Hub) Subscribe(userID int) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = make(map[chan struct{}]struct{})
+	}
+
+	h.subscribers[userID][ch] = struct{}{}
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+
+		delete(h.subscribers[userID], ch)
+
+		if len(h.subscribers[userID]) == 0 {
+			delete(h.subscribers, userID)
+		}
+	}
+}
+
+func (h *Hub) Notify(userID int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subscribers[userID] {
+		wake(ch)
+	}
+}
+
+// NotifyAll after the notifications could be lost.
+func (h *Hub) NotifyAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, subscribers := range h.subscribers {
+		for ch := range subscribers {
+			wake(ch)
+		}
+	}
+}
+
+// ListenPostgres notifies the subscribers of the events saved by all instances, NOTIFY model.EventsChannel.
+// Blocks until the context is canceled.
+func (h *Hub) ListenPostgres(ctx context.Context, connAddress string, logger *logrus.Logger) error {
+	listener := pq.NewListener(connAddress, listenerMinReconnectInterval, listenerMaxReconnectInterval,
+		func(event pq.ListenerEventType, err error) {
+			if err != nil {
+				logger.Errorf("events listener: %v", err)
+			}
+		})
+
+	defer func() {
+		if err := listener.Close(); err != nil {
+			logger.Errorf("events listener: close: %v", err)
+		}
+	}()
+
+	if err := listener.Listen(model.EventsChannel); err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+
+	ticker := time.NewTicker(listenerPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("stop events listener: ok")
+
+			return nil
+
+		case n := <-listener.Notify:
+			// nil - the connection is reestablished, the notifications could be lost meanwhile.
+			if n == nil {
+				h.NotifyAll()
+
+				continue
+			}
+
+			userID, err := strconv.Atoi(n.Extra)
+			if err != nil {
+				logger.Errorf("events listener: notification %q: %v", n.Extra, err)
+
+				continue
+			}
+
+			h.Notify(userID)
+
+		case <-ticker.C:
+			go func() {
+				if err := listener.Ping(); err != nil {
+					logger.Warnf("events listener: ping: %v", err)
+				}
+			}()
+		}
+	}
+}
+
+// wake the subscriber which has a notification pending is not woken twice.
+func wake(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
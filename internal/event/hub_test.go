@@ -0,0 +1,38 @@
+package event
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHub(t *testing.T) {
+	hub := NewHub()
+
+	first, unsubscribeFirst := hub.Subscribe(1)
+	second, unsubscribeSecond := hub.Subscribe(2)
+
+	defer unsubscribeSecond()
+
+	// The notifications are coalesced.
+	hub.Notify(1)
+	hub.Notify(1)
+
+	assert.Len(t, first, 1)
+	assert.Len(t, second, 0)
+
+	<-first
+
+	hub.NotifyAll()
+
+	assert.Len(t, first, 1)
+	assert.Len(t, second, 1)
+
+	<-first
+
+	unsubscribeFirst()
+	hub.Notify(1)
+
+	assert.Len(t, first, 0)
+	assert.NotContains(t, hub.subscribers, 1)
+}
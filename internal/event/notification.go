@@ -0,0 +1,166 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"unicode/utf8"
+
+	"taskmanager/internal/model"
+)
+
+// maxNotificationExcerpt the characters of the comment in the title of its notification.
+const maxNotificationExcerpt = 100
+
+// AddProjectMember the added member gets project.shared.
+func (s Storage) AddProjectMember(ctx context.Context, userID, projectID int, username string) (int, bool, error) {
+	var (
+		memberID int
+		added    bool
+	)
+
+	if err := s.Backend.Atomic(ctx, func(ctx context.Context) error {
+		var err error
+
+		if memberID, added, err = s.Backend.AddProjectMember(ctx, userID, projectID, username); err != nil || !added {
+			return err
+		}
+
+		members, err := s.Backend.GetProjectMembers(ctx, userID, projectID)
+		if err != nil {
+			return fmt.Errorf("notify %s: get project members: %w", model.NotificationProjectShared, err)
+		}
+
+		owner := ""
+
+		for _, member := range members {
+			if member.UserID == userID {
+				owner = member.Username
+			}
+		}
+
+		name, err := s.projectName(ctx, userID, projectID)
+		if err != nil {
+			return fmt.Errorf("notify %s: %w", model.NotificationProjectShared, err)
+		}
+
+		return s.createNotifications(ctx, model.Notification{
+			Type:  model.NotificationProjectShared,
+			Title: fmt.Sprintf("%s shared the project %q", owner, name),
+		}, memberID)
+	}); err != nil {
+		return 0, false, err
+	}
+
+	return memberID, added, nil
+}
+
+// CreateTaskComment the owner of the task and the members of its project, except the author, get task.commented.
+func (s Storage) CreateTaskComment(ctx context.Context, userID, taskID int, text string) (int, error) {
+	var commentID int
+
+	if err := s.Backend.Atomic(ctx, func(ctx context.Context) error {
+		var err error
+
+		if commentID, err = s.Backend.CreateTaskComment(ctx, userID, taskID, text); err != nil {
+			return err
+		}
+
+		comments, err := s.Backend.GetTasksComments(ctx, userID, []int{taskID})
+		if err != nil {
+			return fmt.Errorf("notify %s: get comments: %w", model.NotificationTaskCommented, err)
+		}
+
+		author := ""
+
+		for _, comment := range comments[taskID] {
+			if comment.ID == commentID {
+				author = comment.Username
+			}
+		}
+
+		userIDs, err := s.Backend.GetTaskUserIDs(ctx, taskID)
+		if err != nil {
+			return fmt.Errorf("notify %s: get task users: %w", model.NotificationTaskCommented, err)
+		}
+
+		return s.createNotifications(ctx, model.Notification{
+			Type:   model.NotificationTaskCommented,
+			Title:  fmt.Sprintf("%s commented: %s", author, notificationExcerpt(text)),
+			TaskID: taskID,
+		}, exceptInt(userIDs, userID)...)
+	}); err != nil {
+		return 0, err
+	}
+
+	return commentID, nil
+}
+
+// notifyTaskAssigned the members of the project of the task.updated event, except the owner of the task,
+// get task.assigned.
+func (s Storage) notifyTaskAssigned(ctx context.Context, event model.Event) error {
+	memberIDs, err := s.Backend.GetProjectMemberIDs(ctx, event.ProjectID)
+	if err != nil {
+		return fmt.Errorf("notify %s: get project members: %w", model.NotificationTaskAssigned, err)
+	}
+
+	name, err := s.projectName(ctx, event.UserID, event.ProjectID)
+	if err != nil {
+		return fmt.Errorf("notify %s: %w", model.NotificationTaskAssigned, err)
+	}
+
+	return s.createNotifications(ctx, model.Notification{
+		Type:   model.NotificationTaskAssigned,
+		Title:  fmt.Sprintf("%q assigned to the project %q", event.Task.Title, name),
+		TaskID: event.TaskID,
+	}, exceptInt(memberIDs, event.UserID)...)
+}
+
+// projectName of the project of the member.
+func (s Storage) projectName(ctx context.Context, userID, projectID int) (string, error) {
+	projects, err := s.Backend.GetProjects(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("get projects: %w", err)
+	}
+
+	for _, project := range projects {
+		if project.ID == projectID {
+			return project.Name, nil
+		}
+	}
+
+	return "", fmt.Errorf("projectId %d: %w", projectID, model.ErrProjectNotFound)
+}
+
+// createNotifications the notification in the inboxes of the users, unless they disabled its type.
+func (s Storage) createNotifications(ctx context.Context, notification model.Notification, userIDs ...int) error {
+	for _, userID := range userIDs {
+		notification.UserID = userID
+
+		if _, err := s.Backend.CreateNotification(ctx, notification); err != nil {
+			return fmt.Errorf("notify %s: create notification: %w", notification.Type, err)
+		}
+	}
+
+	return nil
+}
+
+// notificationExcerpt the beginning of the text, cut to maxNotificationExcerpt characters.
+func notificationExcerpt(text string) string {
+	if utf8.RuneCountInString(text) <= maxNotificationExcerpt {
+		return text
+	}
+
+	return string([]rune(text)[:maxNotificationExcerpt]) + "…"
+}
+
+func exceptInt(values []int, value int) []int {
+	var rest []int
+
+	for _, v := range values {
+		if v != value {
+			rest = append(rest, v)
+		}
+	}
+
+	return rest
+}
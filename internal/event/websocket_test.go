@@ -0,0 +1,310 @@
+package event
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+
+	"taskmanager/internal/app"
+	"taskmanager/internal/handler"
+	"taskmanager/internal/model"
+	"taskmanager/internal/transport/httpsrv"
+)
+
+const testOrigin = "http://localhost"
+
+func dialWebSocket(t *testing.T, server *httptest.Server, path, username, password, origin string) (*websocket.Conn, error) {
+	t.Helper()
+
+	config, err := websocket.NewConfig("ws"+strings.TrimPrefix(server.URL, "http")+path, origin)
+	require.NoError(t, err)
+
+	config.Header.Set("Authorization",
+		"Basic "+base64.StdEncoding.EncodeToString([]byte(username+":"+password)))
+
+	conn, err := websocket.DialConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	t.Cleanup(func() { conn.Close() })
+
+	return conn, nil
+}
+
+// receiveWebSocketMessage the next message of the type, the others are skipped.
+func receiveWebSocketMessage(t *testing.T, conn *websocket.Conn, messageType string) handler.WebSocketMessage {
+	t.Helper()
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	for {
+		var msg handler.WebSocketMessage
+
+		require.NoError(t, websocket.JSON.Receive(conn, &msg))
+
+		if msg.Type == messageType {
+			return msg
+		}
+	}
+}
+
+func sendWebSocketMessage(t *testing.T, conn *websocket.Conn, req handler.WebSocketRequest) {
+	t.Helper()
+
+	require.NoError(t, websocket.JSON.Send(conn, req))
+}
+
+func TestWebSocket(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	gin.SetMode(gin.TestMode)
+
+	hub := NewHub()
+
+	router := httpsrv.Conf{
+		ManageUsername:         testManageUsername,
+		ManagePassword:         testManagePassword,
+		EventsHeartbeatSeconds: 1,
+		EventSubscriber:        hub,
+		CORS: httpsrv.CORS{
+			AllowOrigins: []string{testOrigin},
+		},
+	}.NewRouter(context.Background(), Storage{Backend: model.NewMemory(), Hub: hub},
+		app.Metrics{MetricsRoute: "/metrics"})
+
+	api := testAPI{t: t, server: httptest.NewServer(router)}
+	t.Cleanup(api.server.Close)
+
+	require.Equal(t, http.StatusCreated, api.do(http.MethodPost, "/api/v1/manage/user",
+		testManageUsername, testManagePassword, handler.CreateUserBody{Username: "wsuser", Password: "wsuser"}, nil))
+
+	dial := func(t *testing.T) (*websocket.Conn, handler.WebSocketMessage) {
+		t.Helper()
+
+		conn, err := dialWebSocket(t, api.server, "/api/v1/ws", "wsuser", "wsuser", testOrigin)
+		require.NoError(t, err)
+
+		connected := receiveWebSocketMessage(t, conn, handler.WebSocketConnected)
+		require.NotEmpty(t, connected.ConnectionID)
+
+		return conn, connected
+	}
+
+	first, firstConnected := dial(t)
+
+	sendWebSocketMessage(t, first, handler.WebSocketRequest{
+		ID: "1", Type: handler.WebSocketSubscribe, Topic: handler.WebSocketTopicTasks,
+	})
+
+	ack := receiveWebSocketMessage(t, first, handler.WebSocketAck)
+	assert.Equal(t, "1", ack.ID)
+	assert.Equal(t, http.StatusOK, ack.Status)
+
+	title := "live"
+
+	sendWebSocketMessage(t, first, handler.WebSocketRequest{
+		ID: "2", Type: handler.WebSocketTaskCreate, BatchTaskOperation: handler.BatchTaskOperation{Title: &title},
+	})
+
+	created := receiveWebSocketMessage(t, first, handler.WebSocketEvent)
+	require.NotNil(t, created.Event)
+	assert.Equal(t, model.EventTaskCreated, created.Event.Type)
+	assert.Equal(t, handler.WebSocketTopicTasks, created.Topic)
+	assert.Greater(t, created.LastEventID, firstConnected.LastEventID)
+
+	taskID := created.Event.TaskID
+	taskTopic := handler.WebSocketTopicTaskPrefix + strconv.Itoa(taskID)
+
+	t.Run("mutation errors", func(t *testing.T) {
+		completed := true
+
+		sendWebSocketMessage(t, first, handler.WebSocketRequest{
+			ID: "3", Type: handler.WebSocketTaskUpdate, BatchTaskOperation: handler.BatchTaskOperation{
+				TaskID: taskID, Completed: &completed, IfMatch: `"9"`,
+			},
+		})
+
+		msg := receiveWebSocketMessage(t, first, handler.WebSocketError)
+		assert.Equal(t, "3", msg.ID)
+		assert.Equal(t, http.StatusPreconditionFailed, msg.Status)
+		require.NotNil(t, msg.Error)
+		assert.Equal(t, "TASK_VERSION_MISMATCH", msg.Error.Type)
+
+		sendWebSocketMessage(t, first, handler.WebSocketRequest{ID: "4", Type: "project.subscribe"})
+
+		msg = receiveWebSocketMessage(t, first, handler.WebSocketError)
+		assert.Equal(t, "4", msg.ID)
+		assert.Equal(t, http.StatusBadRequest, msg.Status)
+
+		require.NoError(t, websocket.Message.Send(first, "{"))
+
+		msg = receiveWebSocketMessage(t, first, handler.WebSocketError)
+		assert.Equal(t, http.StatusBadRequest, msg.Status)
+	})
+
+	t.Run("presence", func(t *testing.T) {
+		second, secondConnected := dial(t)
+
+		sendWebSocketMessage(t, second, handler.WebSocketRequest{ID: "1", Type: handler.WebSocketSubscribe, Topic: taskTopic})
+
+		ack := receiveWebSocketMessage(t, second, handler.WebSocketAck)
+		assert.Equal(t, taskID, ack.TaskID)
+		assert.Empty(t, ack.Viewers)
+
+		sendWebSocketMessage(t, first, handler.WebSocketRequest{
+			ID: "5", Type: handler.WebSocketPresenceView, BatchTaskOperation: handler.BatchTaskOperation{TaskID: taskID},
+		})
+
+		ack = receiveWebSocketMessage(t, first, handler.WebSocketAck)
+		assert.Equal(t, "5", ack.ID)
+		require.Len(t, ack.Viewers, 1)
+		assert.Equal(t, firstConnected.ConnectionID, ack.Viewers[0].ConnectionID)
+		assert.Equal(t, "wsuser", ack.Viewers[0].Username)
+
+		presence := receiveWebSocketMessage(t, second, handler.WebSocketPresence)
+		assert.Equal(t, taskID, presence.TaskID)
+		require.Len(t, presence.Viewers, 1)
+		assert.Equal(t, firstConnected.ConnectionID, presence.Viewers[0].ConnectionID)
+		assert.NotEqual(t, secondConnected.ConnectionID, presence.Viewers[0].ConnectionID)
+
+		// The mutation of one connection is the event of the other.
+		completed := true
+
+		sendWebSocketMessage(t, first, handler.WebSocketRequest{
+			ID: "6", Type: handler.WebSocketTaskUpdate, BatchTaskOperation: handler.BatchTaskOperation{
+				TaskID: taskID, Completed: &completed,
+			},
+		})
+
+		ack = receiveWebSocketMessage(t, first, handler.WebSocketAck)
+		assert.Equal(t, "6", ack.ID)
+		assert.Equal(t, http.StatusOK, ack.Status)
+
+		event := receiveWebSocketMessage(t, second, handler.WebSocketEvent)
+		require.NotNil(t, event.Event)
+		assert.Equal(t, model.EventTaskCompleted, event.Event.Type)
+		assert.Equal(t, taskTopic, event.Topic)
+
+		// The viewer leaves with the connection.
+		require.NoError(t, first.Close())
+
+		presence = receiveWebSocketMessage(t, second, handler.WebSocketPresence)
+		assert.Equal(t, taskID, presence.TaskID)
+		assert.Empty(t, presence.Viewers)
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		conn, err := dialWebSocket(t, api.server, "/api/v1/ws?lastEventId="+strconv.FormatInt(firstConnected.LastEventID, 10),
+			"wsuser", "wsuser", testOrigin)
+		require.NoError(t, err)
+
+		sendWebSocketMessage(t, conn, handler.WebSocketRequest{ID: "1", Type: handler.WebSocketSubscribe, Topic: taskTopic})
+
+		// The log is read from lastEventId after the subscription.
+		msg := receiveWebSocketMessage(t, conn, handler.WebSocketEvent)
+		require.NotNil(t, msg.Event)
+		assert.Equal(t, created.LastEventID, msg.LastEventID)
+		assert.Equal(t, model.EventTaskCreated, msg.Event.Type)
+	})
+
+	t.Run("project", func(t *testing.T) {
+		require.Equal(t, http.StatusCreated, api.do(http.MethodPost, "/api/v1/manage/user",
+			testManageUsername, testManagePassword, handler.CreateUserBody{Username: "wsmember", Password: "wsmember"}, nil))
+
+		var project handler.CreateProjectResult
+
+		require.Equal(t, http.StatusCreated, api.do(http.MethodPost, "/api/v1/projects/", "wsuser", "wsuser",
+			handler.CreateProjectBody{Name: "board"}, &project))
+
+		projectTopic := handler.WebSocketTopicProjectPrefix + strconv.Itoa(project.ProjectID)
+
+		member, err := dialWebSocket(t, api.server, "/api/v1/ws", "wsmember", "wsmember", testOrigin)
+		require.NoError(t, err)
+
+		// Only the members subscribe.
+		sendWebSocketMessage(t, member, handler.WebSocketRequest{
+			ID: "1", Type: handler.WebSocketSubscribe, Topic: projectTopic,
+		})
+
+		msg := receiveWebSocketMessage(t, member, handler.WebSocketError)
+		assert.Equal(t, "1", msg.ID)
+		assert.Equal(t, http.StatusBadRequest, msg.Status)
+		require.NotNil(t, msg.Error)
+		assert.Equal(t, "PROJECT_NOT_FOUND", msg.Error.Type)
+
+		require.Equal(t, http.StatusCreated, api.do(http.MethodPost,
+			"/api/v1/projects/"+strconv.Itoa(project.ProjectID)+"/members", "wsuser", "wsuser",
+			handler.AddProjectMemberBody{Username: "wsmember"}, nil))
+
+		sendWebSocketMessage(t, member, handler.WebSocketRequest{
+			ID: "2", Type: handler.WebSocketSubscribe, Topic: handler.WebSocketTopicTasks,
+		})
+		assert.Equal(t, "2", receiveWebSocketMessage(t, member, handler.WebSocketAck).ID)
+
+		sendWebSocketMessage(t, member, handler.WebSocketRequest{
+			ID: "3", Type: handler.WebSocketSubscribe, Topic: projectTopic,
+		})
+
+		ack := receiveWebSocketMessage(t, member, handler.WebSocketAck)
+		assert.Equal(t, "3", ack.ID)
+		assert.Equal(t, http.StatusOK, ack.Status)
+
+		// The task of the owner moved to the project is the event of the project of the member.
+		projectID := project.ProjectID
+
+		require.Equal(t, http.StatusNoContent, api.do(http.MethodPut, "/api/v1/task/"+strconv.Itoa(taskID)+"/project",
+			"wsuser", "wsuser", handler.SetTaskProjectBody{ProjectID: &projectID}, nil))
+
+		event := receiveWebSocketMessage(t, member, handler.WebSocketEvent)
+		require.NotNil(t, event.Event)
+		assert.Equal(t, projectTopic, event.Topic)
+		assert.Equal(t, model.EventTaskUpdated, event.Event.Type)
+		assert.Equal(t, taskID, event.Event.TaskID)
+		assert.Equal(t, project.ProjectID, event.Event.ProjectID)
+		require.NotNil(t, event.Event.Task)
+		assert.Equal(t, project.ProjectID, event.Event.Task.ProjectID)
+
+		// The members get the deletion of the task of the project.
+		require.Equal(t, http.StatusNoContent, api.do(http.MethodDelete, "/api/v1/task/"+strconv.Itoa(taskID),
+			"wsuser", "wsuser", nil, nil))
+
+		event = receiveWebSocketMessage(t, member, handler.WebSocketEvent)
+		require.NotNil(t, event.Event)
+		assert.Equal(t, projectTopic, event.Topic)
+		assert.Equal(t, model.EventTaskDeleted, event.Event.Type)
+		assert.Equal(t, taskID, event.Event.TaskID)
+
+		require.Equal(t, http.StatusNoContent, api.do(http.MethodPost, "/api/v1/task/"+strconv.Itoa(taskID)+"/restore",
+			"wsuser", "wsuser", nil, nil))
+
+		event = receiveWebSocketMessage(t, member, handler.WebSocketEvent)
+		require.NotNil(t, event.Event)
+		assert.Equal(t, model.EventTaskRestored, event.Event.Type)
+	})
+
+	t.Run("handshake", func(t *testing.T) {
+		_, err := dialWebSocket(t, api.server, "/api/v1/ws", "wsuser", "wrong", testOrigin)
+		assert.Error(t, err)
+
+		_, err = dialWebSocket(t, api.server, "/api/v1/ws", "wsuser", "wsuser", "http://example.com")
+		assert.Error(t, err)
+
+		// Only in /api/v1.
+		_, err = dialWebSocket(t, api.server, "/api/v2/ws", "wsuser", "wsuser", testOrigin)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,285 @@
+package handler
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"taskmanager/internal/model"
+)
+
+const (
+	mimeCalendar = "text/calendar"
+
+	calendarExtension   = ".ics"
+	calendarTokenBytes  = 32
+	calendarProductID   = "-//taskmanager//tasks//EN"
+	calendarUIDDomain   = "taskmanager"
+	calendarTime        = "20060102T150405Z"
+	calendarDate        = "20060102"
+	calendarLineOctets  = 75
+	maxLengthRecurrence = 200
+)
+
+// The components of the tasks in the feed.
+const (
+	calendarComponentVTodo  = "vtodo"
+	calendarComponentVEvent = "vevent"
+)
+
+var errRecurrenceInvalid = errors.New("invalid recurrence")
+
+var calendarWeekdays = map[string]bool{
+	"MO": true, "TU": true, "WE": true, "TH": true, "FR": true, "SA": true, "SU": true,
+}
+
+// newCalendarToken the token of the URL of the feed and its hash, only the hash is stored.
+func newCalendarToken() (string, string, error) {
+	b := make([]byte, calendarTokenBytes)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("calendar token: %w", err)
+	}
+
+	token := hex.EncodeToString(b)
+
+	return token, calendarTokenHash(token), nil
+}
+
+func calendarTokenHash(token string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(token)))
+}
+
+// checkRecurrence the RRULE of RFC 5545 without the prefix: FREQ is required, the supported parts are
+// INTERVAL, COUNT, UNTIL, BYDAY, BYMONTHDAY, BYMONTH. Empty - no recurrence.
+func checkRecurrence(rule string) error {
+	if rule == "" {
+		return nil
+	}
+
+	if len(rule) > maxLengthRecurrence {
+		return fmt.Errorf("%w: max %d", errRecurrenceInvalid, maxLengthRecurrence)
+	}
+
+	parts := make(map[string]string)
+
+	for _, part := range strings.Split(rule, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return fmt.Errorf("%w: %q: NAME=VALUE required", errRecurrenceInvalid, part)
+		}
+
+		if _, ok := parts[name]; ok {
+			return fmt.Errorf("%w: %s: repeated", errRecurrenceInvalid, name)
+		}
+
+		if err := checkRecurrencePart(name, value); err != nil {
+			return fmt.Errorf("%w: %s: %s", errRecurrenceInvalid, name, err.Error())
+		}
+
+		parts[name] = value
+	}
+
+	if _, ok := parts["FREQ"]; !ok {
+		return fmt.Errorf("%w: FREQ required", errRecurrenceInvalid)
+	}
+
+	if _, ok := parts["COUNT"]; ok {
+		if _, ok := parts["UNTIL"]; ok {
+			return fmt.Errorf("%w: COUNT or UNTIL", errRecurrenceInvalid)
+		}
+	}
+
+	return nil
+}
+
+func checkRecurrencePart(name, value string) error {
+	switch name {
+	case "FREQ":
+		switch value {
+		case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+			return nil
+		}
+
+		return errors.New("DAILY, WEEKLY, MONTHLY or YEARLY")
+
+	case "INTERVAL", "COUNT":
+		return checkRecurrenceNumbers(value, 1, 1000, false)
+
+	case "UNTIL":
+		if _, err := time.Parse(calendarTime, value); err == nil {
+			return nil
+		}
+
+		if _, err := time.Parse(calendarDate, value); err == nil {
+			return nil
+		}
+
+		return errors.New("YYYYMMDD or YYYYMMDDTHHMMSSZ")
+
+	case "BYDAY":
+		for _, day := range strings.Split(value, ",") {
+			if len(day) < 2 || !calendarWeekdays[day[len(day)-2:]] {
+				return errors.New("MO, TU, WE, TH, FR, SA or SU with an optional week number")
+			}
+
+			if week := day[:len(day)-2]; week != "" {
+				if err := checkRecurrenceNumbers(week, 1, 53, true); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+
+	case "BYMONTHDAY":
+		return checkRecurrenceNumbers(value, 1, 31, true)
+
+	case "BYMONTH":
+		return checkRecurrenceNumbers(value, 1, 12, false)
+	}
+
+	return errors.New("FREQ, INTERVAL, COUNT, UNTIL, BYDAY, BYMONTHDAY or BYMONTH")
+}
+
+// checkRecurrenceNumbers the comma separated numbers from min to max, negative - from the end.
+func checkRecurrenceNumbers(value string, minValue, maxValue int, negative bool) error {
+	for _, s := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(s)
+		if negative && n < 0 {
+			n = -n
+		}
+
+		if err != nil || n < minValue || n > maxValue {
+			return fmt.Errorf("%d-%d", minValue, maxValue)
+		}
+	}
+
+	return nil
+}
+
+// calendarWriter writes the lines of RFC 5545: CRLF, the lines longer than 75 octets are folded.
+type calendarWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (cw *calendarWriter) line(name, value string) {
+	if cw.err != nil {
+		return
+	}
+
+	line := name + ":" + value
+
+	var b strings.Builder
+
+	for width := 0; line != ""; {
+		r, size := utf8.DecodeRuneInString(line)
+
+		// The continuation line starts with a space, it is counted.
+		if width+size > calendarLineOctets {
+			b.WriteString("\r\n ")
+
+			width = 1
+		}
+
+		b.WriteRune(r)
+
+		width += size
+		line = line[size:]
+	}
+
+	b.WriteString("\r\n")
+
+	_, cw.err = io.WriteString(cw.w, b.String())
+}
+
+// writeCalendar the tasks as the components of one calendar. now - DTSTAMP.
+func writeCalendar(w io.Writer, tasks []model.Task, component string, now time.Time) error {
+	cw := calendarWriter{w: w}
+
+	cw.line("BEGIN", "VCALENDAR")
+	cw.line("VERSION", "2.0")
+	cw.line("PRODID", calendarProductID)
+	cw.line("CALSCALE", "GREGORIAN")
+	cw.line("X-WR-CALNAME", "Tasks")
+
+	for _, task := range tasks {
+		if task.Due == nil {
+			continue
+		}
+
+		writeCalendarTask(&cw, task, component, now)
+	}
+
+	cw.line("END", "VCALENDAR")
+
+	return cw.err
+}
+
+// writeCalendarTask VTODO with the status and DUE, DTSTART - the start of the recurrence.
+// VEVENT at the due time, the completed task is marked [x] as in todo.txt.
+func writeCalendarTask(cw *calendarWriter, task model.Task, component string, now time.Time) {
+	name := strings.ToUpper(component)
+
+	cw.line("BEGIN", name)
+	cw.line("UID", fmt.Sprintf("task-%d@%s", task.ID, calendarUIDDomain))
+	cw.line("DTSTAMP", calendarTimeValue(now))
+	cw.line("CREATED", calendarTimeValue(task.Created))
+	cw.line("LAST-MODIFIED", calendarTimeValue(task.Updated))
+
+	switch component {
+	case calendarComponentVEvent:
+		summary := task.Title
+		if task.Status {
+			summary = "[x] " + summary
+		}
+
+		cw.line("SUMMARY", calendarText(summary))
+		cw.line("DTSTART", calendarTimeValue(*task.Due))
+		cw.line("TRANSP", "TRANSPARENT")
+
+	default:
+		cw.line("SUMMARY", calendarText(task.Title))
+
+		if task.Recurrence != "" {
+			cw.line("DTSTART", calendarTimeValue(*task.Due))
+		}
+
+		cw.line("DUE", calendarTimeValue(*task.Due))
+
+		if task.Status {
+			cw.line("STATUS", "COMPLETED")
+			cw.line("PERCENT-COMPLETE", "100")
+
+			if !task.Completed.IsZero() {
+				cw.line("COMPLETED", calendarTimeValue(task.Completed))
+			}
+		} else {
+			cw.line("STATUS", "NEEDS-ACTION")
+		}
+	}
+
+	if task.Recurrence != "" {
+		cw.line("RRULE", task.Recurrence)
+	}
+
+	cw.line("END", name)
+}
+
+func calendarTimeValue(t time.Time) string {
+	return t.UTC().Format(calendarTime)
+}
+
+var calendarTextReplacer = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`)
+
+func calendarText(s string) string {
+	return calendarTextReplacer.Replace(s)
+}
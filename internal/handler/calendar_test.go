@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taskmanager/internal/model"
+)
+
+func TestCheckRecurrence(t *testing.T) {
+	cases := []struct {
+		rule  string
+		valid bool
+	}{
+		{rule: "", valid: true},
+		{rule: "FREQ=DAILY", valid: true},
+		{rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE,FR", valid: true},
+		{rule: "FREQ=MONTHLY;BYDAY=-1FR;COUNT=12", valid: true},
+		{rule: "FREQ=MONTHLY;BYMONTHDAY=1,-1;UNTIL=20231231", valid: true},
+		{rule: "FREQ=YEARLY;BYMONTH=5;UNTIL=20301231T235959Z", valid: true},
+		{rule: "INTERVAL=2"},
+		{rule: "FREQ=HOURLY"},
+		{rule: "FREQ=DAILY;FREQ=WEEKLY"},
+		{rule: "FREQ=DAILY;COUNT=0"},
+		{rule: "FREQ=DAILY;COUNT=2;UNTIL=20231231"},
+		{rule: "FREQ=WEEKLY;BYDAY=MONDAY"},
+		{rule: "FREQ=MONTHLY;BYMONTHDAY=32"},
+		{rule: "FREQ=YEARLY;BYMONTH=13"},
+		{rule: "FREQ=DAILY;UNTIL=2023-12-31"},
+		{rule: "RRULE:FREQ=DAILY"},
+		{rule: "FREQ=DAILY;BYSETPOS=1"},
+		{rule: "FREQ=DAILY;"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.rule, func(t *testing.T) {
+			err := checkRecurrence(tt.rule)
+			if tt.valid {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			assert.ErrorIs(t, err, errRecurrenceInvalid)
+		})
+	}
+}
+
+func TestWriteCalendar(t *testing.T) {
+	created := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	due := time.Date(2023, 5, 2, 12, 30, 0, 0, time.FixedZone("MSK", 3*60*60))
+	now := time.Date(2023, 5, 3, 8, 0, 0, 0, time.UTC)
+
+	tasks := []model.Task{
+		{ID: 1, Title: "without due", Created: created, Updated: created},
+		{ID: 24, Title: "buy milk, bread; eggs", Created: created, Updated: created, Due: &due},
+		{
+			ID: 25, Status: true, Title: "weekly", Created: created, Updated: now, Completed: now, Due: &due,
+			Recurrence: "FREQ=WEEKLY;BYDAY=TU",
+		},
+	}
+
+	t.Run("vtodo", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		require.NoError(t, writeCalendar(&buf, tasks, calendarComponentVTodo, now))
+
+		expected := strings.Join([]string{
+			"BEGIN:VCALENDAR",
+			"VERSION:2.0",
+			"PRODID:-//taskmanager//tasks//EN",
+			"CALSCALE:GREGORIAN",
+			"X-WR-CALNAME:Tasks",
+			"BEGIN:VTODO",
+			"UID:task-24@taskmanager",
+			"DTSTAMP:20230503T080000Z",
+			"CREATED:20230501T100000Z",
+			"LAST-MODIFIED:20230501T100000Z",
+			`SUMMARY:buy milk\, bread\; eggs`,
+			"DUE:20230502T093000Z",
+			"STATUS:NEEDS-ACTION",
+			"END:VTODO",
+			"BEGIN:VTODO",
+			"UID:task-25@taskmanager",
+			"DTSTAMP:20230503T080000Z",
+			"CREATED:20230501T100000Z",
+			"LAST-MODIFIED:20230503T080000Z",
+			"SUMMARY:weekly",
+			"DTSTART:20230502T093000Z",
+			"DUE:20230502T093000Z",
+			"STATUS:COMPLETED",
+			"PERCENT-COMPLETE:100",
+			"COMPLETED:20230503T080000Z",
+			"RRULE:FREQ=WEEKLY;BYDAY=TU",
+			"END:VTODO",
+			"END:VCALENDAR",
+		}, "\r\n") + "\r\n"
+
+		assert.Equal(t, expected, buf.String())
+	})
+
+	t.Run("vevent", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		require.NoError(t, writeCalendar(&buf, tasks[2:], calendarComponentVEvent, now))
+
+		assert.Contains(t, buf.String(), "BEGIN:VEVENT\r\n")
+		assert.Contains(t, buf.String(), "SUMMARY:[x] weekly\r\nDTSTART:20230502T093000Z\r\nTRANSP:TRANSPARENT\r\n")
+		assert.Contains(t, buf.String(), "RRULE:FREQ=WEEKLY;BYDAY=TU\r\nEND:VEVENT\r\n")
+		assert.NotContains(t, buf.String(), "STATUS")
+	})
+
+	t.Run("folding", func(t *testing.T) {
+		title := strings.Repeat("задача ", 30) + "\nnext line"
+
+		var buf bytes.Buffer
+
+		require.NoError(t, writeCalendar(&buf, []model.Task{{ID: 1, Title: title, Due: &due}}, calendarComponentVTodo, now))
+
+		var summary strings.Builder
+
+		for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+			assert.LessOrEqual(t, len(line), calendarLineOctets)
+
+			switch {
+			case strings.HasPrefix(line, "SUMMARY:"):
+				summary.WriteString(strings.TrimPrefix(line, "SUMMARY:"))
+
+			case strings.HasPrefix(line, " "):
+				summary.WriteString(strings.TrimPrefix(line, " "))
+			}
+		}
+
+		assert.Equal(t, strings.Repeat("задача ", 30)+`\nnext line`, summary.String())
+	})
+}
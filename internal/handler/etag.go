@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/security"
+)
+
+const (
+	headerETag        = "ETag"
+	headerIfMatch     = "If-Match"
+	headerIfNoneMatch = "If-None-Match"
+)
+
+const weakETagPrefix = "W/"
+
+func taskETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// tasksETag one ETag for the whole list, changes if any task is added, changed or deleted.
+func tasksETag(tasks []model.Task) string {
+	hash := sha256.New()
+
+	for _, task := range tasks {
+		fmt.Fprintf(hash, "%d:%d;", task.ID, task.Version)
+	}
+
+	return strconv.Quote(fmt.Sprintf("%x", hash.Sum(nil)[:16]))
+}
+
+// ifMatch the task versions of the If-Match header.
+type ifMatch struct {
+	// anyVersion the header is empty or "*".
+	anyVersion bool
+	versions   []int
+}
+
+// parseIfMatch evaluates the If-Match list in the strong comparison of RFC 9110 section 13.1.1: the weak ETags and
+// the ones not of a task never match, they are skipped. ok is false if nothing in the header can match.
+func parseIfMatch(header string) (ifMatch, bool) {
+	header = strings.TrimSpace(header)
+
+	if header == "" || header == "*" {
+		return ifMatch{anyVersion: true}, true
+	}
+
+	var match ifMatch
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+
+		if strings.HasPrefix(tag, weakETagPrefix) {
+			continue
+		}
+
+		value, err := strconv.Unquote(tag)
+		if err != nil {
+			continue
+		}
+
+		version, err := strconv.Atoi(value)
+		if err != nil || version < 1 {
+			continue
+		}
+
+		match.versions = append(match.versions, version)
+	}
+
+	return match, len(match.versions) > 0
+}
+
+// version the expected task version for the storage, 0 - any. If the list has several versions the current one is
+// read, it is expected if it is in the list, otherwise model.ErrVersionMismatch.
+func (m ifMatch) version(current func() (int, error)) (int, error) {
+	switch {
+	case m.anyVersion:
+		return 0, nil
+
+	case len(m.versions) == 0:
+		return 0, model.ErrVersionMismatch
+
+	case len(m.versions) == 1:
+		return m.versions[0], nil
+	}
+
+	version, err := current()
+	if err != nil {
+		return 0, err
+	}
+
+	for _, v := range m.versions {
+		if v == version {
+			return version, nil
+		}
+	}
+
+	return 0, fmt.Errorf("version %d: %w", version, model.ErrVersionMismatch)
+}
+
+// ifMatchTaskVersion the version of the task for ifMatch.version.
+func ifMatchTaskVersion(
+	ctx context.Context, storage TaskStorage, username, password string, taskID int,
+) func() (int, error) {
+	return func() (int, error) {
+		task, err := storage.GetTask(ctx, username, security.SaltPassword(password), taskID)
+		if err != nil {
+			return 0, fmt.Errorf("get task: %w", err)
+		}
+
+		return task.Version, nil
+	}
+}
+
+// matchIfNoneMatch reports whether the If-None-Match header matches the ETag, weak comparison.
+func matchIfNoneMatch(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+
+		if tag == "*" || strings.TrimPrefix(tag, weakETagPrefix) == etag {
+			return true
+		}
+	}
+
+	return false
+}
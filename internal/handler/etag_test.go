@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"taskmanager/internal/model"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	cases := []struct {
+		name          string
+		header        string
+		expectedMatch ifMatch
+		expectedOk    bool
+	}{
+		{name: "empty", header: "", expectedMatch: ifMatch{anyVersion: true}, expectedOk: true},
+		{name: "any", header: "*", expectedMatch: ifMatch{anyVersion: true}, expectedOk: true},
+		{name: "version", header: `"3"`, expectedMatch: ifMatch{versions: []int{3}}, expectedOk: true},
+		{name: "list", header: `"3", "4"`, expectedMatch: ifMatch{versions: []int{3, 4}}, expectedOk: true},
+		{name: "list with weak", header: `W/"3", "4"`, expectedMatch: ifMatch{versions: []int{4}}, expectedOk: true},
+		{name: "list with other", header: `"abc","5"`, expectedMatch: ifMatch{versions: []int{5}}, expectedOk: true},
+		{name: "not quoted", header: "3", expectedOk: false},
+		{name: "weak", header: `W/"3"`, expectedOk: false},
+		{name: "not number", header: `"abc"`, expectedOk: false},
+		{name: "weak list", header: `W/"3", W/"4"`, expectedOk: false},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			match, ok := parseIfMatch(tt.header)
+			assert.Equal(t, tt.expectedOk, ok)
+			assert.Equal(t, tt.expectedMatch, match)
+		})
+	}
+}
+
+func TestIfMatchVersion(t *testing.T) {
+	errGetTask := errors.New("get task")
+
+	cases := []struct {
+		name            string
+		match           ifMatch
+		current         int
+		currentErr      error
+		expectedVersion int
+		expectedErr     error
+	}{
+		{name: "any", match: ifMatch{anyVersion: true}, current: 7, expectedVersion: 0},
+		{name: "none", match: ifMatch{}, current: 7, expectedErr: model.ErrVersionMismatch},
+		{name: "one", match: ifMatch{versions: []int{3}}, current: 7, expectedVersion: 3},
+		{name: "list current", match: ifMatch{versions: []int{3, 7}}, current: 7, expectedVersion: 7},
+		{name: "list other", match: ifMatch{versions: []int{3, 4}}, current: 7, expectedErr: model.ErrVersionMismatch},
+		{name: "list error", match: ifMatch{versions: []int{3, 4}}, currentErr: errGetTask, expectedErr: errGetTask},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			version, err := tt.match.version(func() (int, error) {
+				return tt.current, tt.currentErr
+			})
+			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.Equal(t, tt.expectedVersion, version)
+		})
+	}
+}
+
+func TestMatchIfNoneMatch(t *testing.T) {
+	cases := []struct {
+		name     string
+		header   string
+		etag     string
+		expected bool
+	}{
+		{name: "empty", header: "", etag: `"3"`, expected: false},
+		{name: "any", header: "*", etag: `"3"`, expected: true},
+		{name: "same", header: `"3"`, etag: `"3"`, expected: true},
+		{name: "weak", header: `W/"3"`, etag: `"3"`, expected: true},
+		{name: "other", header: `"2"`, etag: `"3"`, expected: false},
+		{name: "list", header: `"1", "3"`, etag: `"3"`, expected: true},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, matchIfNoneMatch(tt.header, tt.etag))
+		})
+	}
+}
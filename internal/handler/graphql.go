@@ -0,0 +1,236 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+
+	"taskmanager/internal/security"
+)
+
+type GraphQLStorage interface {
+	TaskStorage
+	ProjectStorage
+	TaskRelationStorage
+}
+
+type GraphQLConf struct {
+	// MaxDepth of the nested fields of the query.
+	MaxDepth int
+	// MaxComplexity of the query: a field costs 1, the fields of the nodes of a page - times first.
+	MaxComplexity int
+}
+
+// GraphQLRequest the variables of GET are JSON.
+type GraphQLRequest struct {
+	Query         string         `json:"query" form:"query" binding:"required" example:"{ tasks { edges { node { id title } } } }"`
+	OperationName string         `json:"operationName" form:"operationName"`
+	Variables     map[string]any `json:"variables" form:"-"`
+}
+
+// GraphQLResponse data is absent if the query was not executed.
+type GraphQLResponse struct {
+	Data   any            `json:"data,omitempty"`
+	Errors []GraphQLError `json:"errors,omitempty"`
+}
+
+// GraphQLError extensions.code is the type of the error of /api/v1.
+type GraphQLError struct {
+	Message    string            `json:"message"`
+	Locations  []GraphQLLocation `json:"locations,omitempty"`
+	Path       []any             `json:"path,omitempty"`
+	Extensions map[string]any    `json:"extensions,omitempty"`
+}
+
+type GraphQLLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// GraphQL the queries and the mutations of the tasks of the user and of their projects, the schema is
+// in newGraphQLSchema. The histories, the tags, the comments and the subtasks of the tasks of the response are
+// loaded in one query each. The queries deeper than MaxDepth or more complex than MaxComplexity are not executed.
+//
+// @Summary GraphQL of the tasks
+// @Tags graphql
+// @Accept json
+// @Produce json
+// @Param data body GraphQLRequest true "query, operationName, variables; GET - the query parameters, only queries"
+// @Success 200 {object} GraphQLResponse "data, errors; extensions.code of the errors - the error type"
+// @Failure 400 {object} HTTPError "error type, comment"
+// @Failure 401 {object} nil
+// @Failure 403 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /graphql [post]
+func GraphQL(ctx context.Context, storage GraphQLStorage, conf GraphQLConf) gin.HandlerFunc {
+	schema, err := newGraphQLSchema(storage)
+	if err != nil {
+		panic(fmt.Sprintf("graphql schema: %v", err))
+	}
+
+	return func(c *gin.Context) {
+		userID, ok := authorizedUserID(ctx, c, storage)
+		if !ok {
+			return
+		}
+
+		username, password, _ := c.Request.BasicAuth()
+
+		var r GraphQLRequest
+		if !bindGraphQLRequest(c, &r) {
+			return
+		}
+
+		gqlReq := newGraphQLRequest(storage, userID, username, security.SaltPassword(password))
+
+		res := executeGraphQL(context.WithValue(ctx, graphQLRequestKey{}, gqlReq), &schema, conf, r,
+			c.Request.Method == http.MethodGet)
+
+		for _, err := range res.Errors {
+			var gqlErr *graphQLError
+
+			if located, ok := err.OriginalError().(*gqlerrors.Error); ok &&
+				errors.As(located.OriginalError, &gqlErr) && gqlErr.err != nil {
+				_ = c.Error(gqlErr.err)
+			}
+		}
+
+		c.JSON(http.StatusOK, res.response())
+	}
+}
+
+// bindGraphQLRequest false - the error is written.
+func bindGraphQLRequest(c *gin.Context, r *GraphQLRequest) bool {
+	var err error
+
+	if c.Request.Method == http.MethodGet {
+		err = c.ShouldBindQuery(r)
+
+		if variables := c.Query("variables"); err == nil && variables != "" {
+			err = json.Unmarshal([]byte(variables), &r.Variables)
+		}
+	} else {
+		err = c.ShouldBindJSON(r)
+	}
+
+	if err != nil {
+		writeError(c, http.StatusBadRequest, HTTPError{
+			Type:    typeParameterRequired,
+			Comment: "query",
+			Error:   err.Error(),
+			fields:  fieldErrors(err),
+		})
+
+		return false
+	}
+
+	return true
+}
+
+// graphQLResult executed - the data is written even if it is null.
+type graphQLResult struct {
+	Data     any
+	Errors   []gqlerrors.FormattedError
+	executed bool
+}
+
+func (r graphQLResult) response() GraphQLResponse {
+	res := GraphQLResponse{Data: r.Data}
+
+	if r.executed && r.Data == nil {
+		res.Data = json.RawMessage("null")
+	}
+
+	for _, err := range r.Errors {
+		gqlErr := GraphQLError{
+			Message:    err.Message,
+			Path:       err.Path,
+			Extensions: err.Extensions,
+		}
+
+		for _, l := range err.Locations {
+			gqlErr.Locations = append(gqlErr.Locations, GraphQLLocation{Line: l.Line, Column: l.Column})
+		}
+
+		res.Errors = append(res.Errors, gqlErr)
+	}
+
+	return res
+}
+
+// executeGraphQL the document is parsed and validated, the operation is checked against the limits and executed.
+// readOnly - the mutations are not executed, like for GET.
+func executeGraphQL(
+	ctx context.Context, schema *graphql.Schema, conf GraphQLConf, r GraphQLRequest, readOnly bool,
+) graphQLResult {
+	src := source.NewSource(&source.Source{Body: []byte(r.Query), Name: "GraphQL request"})
+
+	doc, err := parser.Parse(parser.ParseParams{Source: src})
+	if err != nil {
+		return graphQLResult{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	if validation := graphql.ValidateDocument(schema, doc, nil); !validation.IsValid {
+		return graphQLResult{Errors: validation.Errors}
+	}
+
+	limits := graphQLLimits{
+		schema:    schema,
+		conf:      conf,
+		source:    src,
+		fragments: make(map[string]*ast.FragmentDefinition),
+		variables: r.Variables,
+	}
+
+	var operations []*ast.OperationDefinition
+
+	for _, definition := range doc.Definitions {
+		switch d := definition.(type) {
+		case *ast.OperationDefinition:
+			if r.OperationName == "" || d.Name != nil && d.Name.Value == r.OperationName {
+				operations = append(operations, d)
+			}
+
+		case *ast.FragmentDefinition:
+			limits.fragments[d.Name.Value] = d
+		}
+	}
+
+	switch {
+	case len(operations) == 0:
+		return graphQLResult{Errors: gqlerrors.FormatErrors(fmt.Errorf("unknown operation %q", r.OperationName))}
+
+	case len(operations) > 1:
+		return graphQLResult{Errors: gqlerrors.FormatErrors(
+			errors.New("operationName is required for the document with several operations"),
+		)}
+
+	case readOnly && operations[0].Operation != ast.OperationTypeQuery:
+		return graphQLResult{Errors: gqlerrors.FormatErrors(gqlerrors.NewError(
+			"mutations are not allowed with GET", []ast.Node{operations[0]}, "", src, nil, nil,
+		))}
+	}
+
+	if err := limits.check(operations[0]); err != nil {
+		return graphQLResult{Errors: gqlerrors.FormatErrors(err)}
+	}
+
+	res := graphql.Execute(graphql.ExecuteParams{
+		Schema:        *schema,
+		AST:           doc,
+		OperationName: r.OperationName,
+		Args:          r.Variables,
+		Context:       ctx,
+	})
+
+	return graphQLResult{Data: res.Data, Errors: res.Errors, executed: true}
+}
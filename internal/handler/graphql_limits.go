@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// graphQLIntrospectionMaxDepth the introspection queries of the tools, like GraphiQL, are deeper than the queries
+// of the tasks, they are allowed even if MaxDepth is lower.
+const graphQLIntrospectionMaxDepth = 15
+
+// graphQLLimits the depth and the complexity of the operation are checked before it is executed,
+// the document must be valid.
+type graphQLLimits struct {
+	schema    *graphql.Schema
+	conf      GraphQLConf
+	source    *source.Source
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]any
+}
+
+func (l graphQLLimits) check(op *ast.OperationDefinition) error {
+	root := l.schema.QueryType()
+	if op.Operation == ast.OperationTypeMutation {
+		root = l.schema.MutationType()
+	}
+
+	complexity, err := l.selectionSet(root, op.SelectionSet, 1, l.conf.MaxDepth)
+	if err != nil {
+		return err
+	}
+
+	if l.conf.MaxComplexity > 0 && complexity > l.conf.MaxComplexity {
+		return l.error(
+			fmt.Sprintf("query complexity %d exceeds the max %d", complexity, l.conf.MaxComplexity),
+			"COMPLEXITY_LIMIT_EXCEEDED",
+			op,
+		)
+	}
+
+	return nil
+}
+
+// selectionSet the fields of the fragments are at the depth of the selection set.
+func (l graphQLLimits) selectionSet(object *graphql.Object, set *ast.SelectionSet, depth, maxDepth int) (int, error) {
+	if set == nil {
+		return 0, nil
+	}
+
+	complexity := 0
+
+	for _, selection := range set.Selections {
+		var (
+			c   int
+			err error
+		)
+
+		switch s := selection.(type) {
+		case *ast.Field:
+			c, err = l.field(object, s, depth, maxDepth)
+
+		case *ast.InlineFragment:
+			c, err = l.selectionSet(l.fragmentObject(object, s.TypeCondition), s.SelectionSet, depth, maxDepth)
+
+		case *ast.FragmentSpread:
+			if f, ok := l.fragments[s.Name.Value]; ok {
+				c, err = l.selectionSet(l.fragmentObject(object, f.TypeCondition), f.SelectionSet, depth, maxDepth)
+			}
+		}
+
+		if err != nil {
+			return 0, err
+		}
+
+		complexity += c
+	}
+
+	return complexity, nil
+}
+
+// field costs 1 and its fields, the fields of the page of the connection are counted first times.
+func (l graphQLLimits) field(object *graphql.Object, f *ast.Field, depth, maxDepth int) (int, error) {
+	var definition *graphql.FieldDefinition
+
+	switch f.Name.Value {
+	case "__typename":
+		return 0, nil
+
+	case graphql.SchemaMetaFieldDef.Name:
+		definition = graphql.SchemaMetaFieldDef
+
+	case graphql.TypeMetaFieldDef.Name:
+		definition = graphql.TypeMetaFieldDef
+
+	default:
+		definition = object.Fields()[f.Name.Value]
+	}
+
+	introspection := definition == graphql.SchemaMetaFieldDef || definition == graphql.TypeMetaFieldDef
+	if introspection && maxDepth > 0 && maxDepth < graphQLIntrospectionMaxDepth {
+		maxDepth = graphQLIntrospectionMaxDepth
+	}
+
+	if maxDepth > 0 && depth > maxDepth {
+		return 0, l.error(fmt.Sprintf("query depth exceeds the max %d", maxDepth), "DEPTH_LIMIT_EXCEEDED", f)
+	}
+
+	if definition == nil {
+		return 0, nil
+	}
+
+	childComplexity := 0
+
+	if child, ok := graphql.GetNamed(definition.Type).(*graphql.Object); ok {
+		var err error
+
+		childComplexity, err = l.selectionSet(child, f.SelectionSet, depth+1, maxDepth)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if first, ok := l.first(definition, f); ok {
+		return 1 + first*childComplexity, nil
+	}
+
+	return 1 + childComplexity, nil
+}
+
+// first the size of the page, the invalid one is counted as the max, it is not executed anyway.
+// false - the field is not a connection.
+func (l graphQLLimits) first(definition *graphql.FieldDefinition, f *ast.Field) (int, bool) {
+	var (
+		value   any
+		defined bool
+	)
+
+	for _, arg := range definition.Args {
+		if arg.Name() == "first" {
+			value, defined = arg.DefaultValue, true
+		}
+	}
+
+	if !defined {
+		return 0, false
+	}
+
+	for _, arg := range f.Arguments {
+		if arg.Name.Value != "first" {
+			continue
+		}
+
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			value, _ = strconv.Atoi(v.Value)
+
+		case *ast.Variable:
+			if variable, ok := l.variables[v.Name.Value]; ok {
+				value = variable
+			}
+		}
+	}
+
+	first := 0
+
+	switch v := value.(type) {
+	case int:
+		first = v
+
+	case float64:
+		first = int(v)
+
+	case json.Number:
+		n, _ := v.Int64()
+		first = int(n)
+	}
+
+	if first < 1 || first > maxGraphQLPageSize {
+		first = maxGraphQLPageSize
+	}
+
+	return first, true
+}
+
+func (l graphQLLimits) fragmentObject(object *graphql.Object, typeCondition *ast.Named) *graphql.Object {
+	if typeCondition == nil {
+		return object
+	}
+
+	if t, ok := l.schema.Type(typeCondition.Name.Value).(*graphql.Object); ok {
+		return t
+	}
+
+	return object
+}
+
+func (l graphQLLimits) error(message, code string, node ast.Node) error {
+	return gqlerrors.NewError(message, []ast.Node{node}, "", l.source, nil, &graphQLError{
+		errorType: code,
+		comment:   message,
+	})
+}
@@ -0,0 +1,1239 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/graphql-go/graphql"
+
+	"taskmanager/internal/model"
+)
+
+const (
+	defaultGraphQLPageSize = 20
+	maxGraphQLPageSize     = 100
+)
+
+const (
+	maxLengthTag         = 50
+	maxLengthComment     = 2000
+	maxLengthProjectName = 200
+)
+
+const graphQLCursorPrefix = "task:"
+
+type graphQLRequestKey struct{}
+
+// graphQLRequest the state of the request for the resolvers, the password is salted.
+type graphQLRequest struct {
+	userID   int
+	username string
+	password string
+	history  *batchLoader[[]model.TaskChange]
+	tags     *batchLoader[[]string]
+	comments *batchLoader[[]model.TaskComment]
+	subtasks *batchLoader[[]model.Task]
+	// projects of the user by id, nil - not loaded yet.
+	projects map[int]model.Project
+}
+
+func newGraphQLRequest(storage GraphQLStorage, userID int, username, password string) *graphQLRequest {
+	r := &graphQLRequest{
+		userID:   userID,
+		username: username,
+		password: password,
+	}
+
+	r.history = newBatchLoader(func(ctx context.Context, taskIDs []int) (map[int][]model.TaskChange, error) {
+		return storage.GetTasksHistory(ctx, username, password, taskIDs)
+	})
+
+	r.tags = newBatchLoader(func(ctx context.Context, taskIDs []int) (map[int][]string, error) {
+		return storage.GetTasksTags(ctx, userID, taskIDs)
+	})
+
+	r.comments = newBatchLoader(func(ctx context.Context, taskIDs []int) (map[int][]model.TaskComment, error) {
+		return storage.GetTasksComments(ctx, userID, taskIDs)
+	})
+
+	r.subtasks = newBatchLoader(func(ctx context.Context, taskIDs []int) (map[int][]model.Task, error) {
+		subtasks, err := storage.GetSubtasks(ctx, userID, taskIDs)
+
+		// The subtasks of all the tasks are loaded at once, so are their relations.
+		for _, tasks := range subtasks {
+			for _, task := range tasks {
+				r.prime(task.ID)
+			}
+		}
+
+		return subtasks, err
+	})
+
+	return r
+}
+
+func requestOfGraphQL(ctx context.Context) *graphQLRequest {
+	r, _ := ctx.Value(graphQLRequestKey{}).(*graphQLRequest)
+
+	return r
+}
+
+// prime the relations of the tasks of the response.
+func (r *graphQLRequest) prime(taskIDs ...int) {
+	r.history.prime(taskIDs...)
+	r.tags.prime(taskIDs...)
+	r.comments.prime(taskIDs...)
+	r.subtasks.prime(taskIDs...)
+}
+
+// batchLoader loads the values of the tasks of the response in one query instead of one query per task:
+// the lists prime the ids of their tasks, the first value that is resolved loads all primed values.
+// The fields are resolved one by one, it is not safe for concurrent use.
+type batchLoader[V any] struct {
+	load    func(ctx context.Context, ids []int) (map[int]V, error)
+	pending map[int]struct{}
+	loaded  map[int]V
+}
+
+func newBatchLoader[V any](load func(ctx context.Context, ids []int) (map[int]V, error)) *batchLoader[V] {
+	return &batchLoader[V]{
+		load:    load,
+		pending: make(map[int]struct{}),
+		loaded:  make(map[int]V),
+	}
+}
+
+func (l *batchLoader[V]) prime(ids ...int) {
+	for _, id := range ids {
+		if _, ok := l.loaded[id]; !ok {
+			l.pending[id] = struct{}{}
+		}
+	}
+}
+
+func (l *batchLoader[V]) get(ctx context.Context, id int) (V, error) {
+	if value, ok := l.loaded[id]; ok {
+		return value, nil
+	}
+
+	l.prime(id)
+
+	// The load can prime more ids, they are loaded the next time.
+	ids := make([]int, 0, len(l.pending))
+	for pendingID := range l.pending {
+		ids = append(ids, pendingID)
+	}
+
+	l.pending = make(map[int]struct{})
+
+	values, err := l.load(ctx, ids)
+	if err != nil {
+		var empty V
+
+		return empty, err
+	}
+
+	for _, pendingID := range ids {
+		l.loaded[pendingID] = values[pendingID]
+	}
+
+	return l.loaded[id], nil
+}
+
+// graphQLError the code is the type of the error of /api/v1, err - the cause of the internal error to log.
+type graphQLError struct {
+	errorType string
+	comment   string
+	err       error
+}
+
+func newGraphQLError(errorType, comment string) *graphQLError {
+	return &graphQLError{errorType: errorType, comment: comment}
+}
+
+func (e *graphQLError) Error() string {
+	return e.errorType + ": " + e.comment
+}
+
+// Extensions of the formatted error.
+func (e *graphQLError) Extensions() map[string]any {
+	return map[string]any{"code": e.errorType}
+}
+
+// graphQLStorageError the errors of the storage like the handlers write them, the comment of the not found one
+// is the id, the cause of the internal error is logged.
+func graphQLStorageError(err error, id int, comment string) *graphQLError {
+	switch {
+	case errors.Is(err, model.ErrUserNotFound):
+		return newGraphQLError(typeUserNotFound, "credentials")
+
+	case errors.Is(err, model.ErrTaskAlreadyExists):
+		return newGraphQLError(typeTaskAlreadyExists, "duplicate task")
+
+	case errors.Is(err, model.ErrTaskNotFound):
+		return newGraphQLError(typeTaskNotFound, strconv.Itoa(id))
+
+	case errors.Is(err, model.ErrRevisionNotFound):
+		return newGraphQLError(typeRevisionNotFound, strconv.Itoa(id))
+
+	case errors.Is(err, model.ErrVersionMismatch):
+		return newGraphQLError(typeTaskVersionMismatch, strconv.Itoa(id))
+
+	case errors.Is(err, model.ErrTaskParentInvalid):
+		return newGraphQLError(typeTaskParentInvalid, strconv.Itoa(id))
+
+	case errors.Is(err, model.ErrProjectNotFound):
+		return newGraphQLError(typeProjectNotFound, strconv.Itoa(id))
+
+	case errors.Is(err, model.ErrCommentNotFound):
+		return newGraphQLError(typeCommentNotFound, strconv.Itoa(id))
+	}
+
+	gqlErr := newGraphQLError(typeInternalError, comment)
+	gqlErr.err = fmt.Errorf("%s: %w", comment, err)
+
+	return gqlErr
+}
+
+// graphQLLengthError the value is required and at most maxLength characters.
+func graphQLLengthError(name, value string, maxLength int) error {
+	if value == "" {
+		return newGraphQLError(typeParameterRequired, name)
+	}
+
+	if utf8.RuneCountInString(value) > maxLength {
+		return newGraphQLError(typeParameterTooLong, fmt.Sprintf("%s: max %d", name, maxLength))
+	}
+
+	return nil
+}
+
+// newGraphQLSchema
+//
+//	type Query {
+//	  task(id: Int!): Task
+//	  tasks(first: Int = 20, after: String, completed: Boolean): TaskConnection!
+//	  trash(first: Int = 20, after: String): DeletedTaskConnection!
+//	  projects: [Project!]!
+//	  project(id: Int!): Project
+//	}
+//
+//	type Mutation {
+//	  createTask(title: String!): Task!
+//	  updateTask(id: Int!, title: String, completed: Boolean, due: String, recurrence: String, version: Int = 0): Task!
+//	  deleteTask(id: Int!, permanent: Boolean = false, version: Int = 0): Boolean!
+//	  restoreTask(id: Int!): Task!
+//	  revertTask(id: Int!, revision: Int!): Task!
+//	  setTaskTags(id: Int!, tags: [String!]!): Task!
+//	  setTaskParent(id: Int!, parentId: Int): Task!
+//	  setTaskProject(id: Int!, projectId: Int): Task!
+//	  addComment(taskId: Int!, text: String!): Comment!
+//	  deleteComment(id: Int!): Boolean!
+//	  createProject(name: String!): Project!
+//	  deleteProject(id: Int!): Boolean!
+//	  addProjectMember(projectId: Int!, username: String!): ProjectMember!
+//	  removeProjectMember(projectId: Int!, userId: Int!): Boolean!
+//	}
+//
+//	type Task {
+//	  id: Int!, title: String!, completed: Boolean!, created: String!, updated: String!, completedAt: String
+//	  due: String, recurrence: String, version: Int!, history: [TaskChange!]!, tags: [String!]!
+//	  comments: [Comment!]!, subtasks: [Task!]!, parentId: Int, project: Project
+//	}
+//
+//	type TaskChange { revision: Int!, field: String!, old: String, new: String, actor: String!, changed: String! }
+//	type Comment { id: Int!, text: String!, authorId: Int!, author: String!, created: String! }
+//	type Project {
+//	  id: Int!, name: String!, ownerId: Int!, created: String!, members: [ProjectMember!]!, tasks: [Task!]!
+//	}
+//	type ProjectMember { userId: Int!, username: String!, joined: String! }
+//	type DeletedTask { task: Task!, deleted: String! }
+//	type TaskConnection { edges: [TaskEdge!]!, pageInfo: PageInfo!, totalCount: Int! }
+//	type TaskEdge { cursor: String!, node: Task! }
+//	type DeletedTaskConnection { edges: [DeletedTaskEdge!]!, pageInfo: PageInfo!, totalCount: Int! }
+//	type DeletedTaskEdge { cursor: String!, node: DeletedTask! }
+//	type PageInfo { hasNextPage: Boolean!, endCursor: String }
+//
+// The version 0 - any version, like without If-Match; parentId, projectId null or 0 - none. The times are RFC 3339.
+// The empty due and recurrence of updateTask are removed, recurrence is the RRULE like in PATCH /api/v1/task.
+// The tasks of the projects of the other members are seen in project.tasks, only the owner changes the task.
+func newGraphQLSchema(storage GraphQLStorage) (graphql.Schema, error) {
+	r := graphQLResolver{storage: storage}
+
+	taskChange := graphql.NewObject(graphql.ObjectConfig{Name: "TaskChange", Fields: graphql.Fields{
+		"revision": {Type: nonNull(graphql.Int), Resolve: resolveSource(func(c model.TaskChange) any {
+			return c.Revision
+		})},
+		"field": {Type: nonNull(graphql.String), Resolve: resolveSource(func(c model.TaskChange) any {
+			return c.Field
+		})},
+		"old": {Type: graphql.String, Resolve: resolveSource(func(c model.TaskChange) any {
+			return graphQLNullString(c.Old)
+		})},
+		"new": {Type: graphql.String, Resolve: resolveSource(func(c model.TaskChange) any {
+			return graphQLNullString(c.New)
+		})},
+		"actor": {Type: nonNull(graphql.String), Resolve: resolveSource(func(c model.TaskChange) any {
+			return c.Actor
+		})},
+		"changed": {Type: nonNull(graphql.String), Resolve: resolveSource(func(c model.TaskChange) any {
+			return graphQLTime(c.Changed)
+		})},
+	}})
+
+	comment := graphql.NewObject(graphql.ObjectConfig{Name: "Comment", Fields: graphql.Fields{
+		"id": {Type: nonNull(graphql.Int), Resolve: resolveSource(func(c model.TaskComment) any {
+			return c.ID
+		})},
+		"text": {Type: nonNull(graphql.String), Resolve: resolveSource(func(c model.TaskComment) any {
+			return c.Text
+		})},
+		"authorId": {Type: nonNull(graphql.Int), Resolve: resolveSource(func(c model.TaskComment) any {
+			return c.UserID
+		})},
+		"author": {Type: nonNull(graphql.String), Resolve: resolveSource(func(c model.TaskComment) any {
+			return c.Username
+		})},
+		"created": {Type: nonNull(graphql.String), Resolve: resolveSource(func(c model.TaskComment) any {
+			return graphQLTime(c.Created)
+		})},
+	}})
+
+	projectMember := graphql.NewObject(graphql.ObjectConfig{Name: "ProjectMember", Fields: graphql.Fields{
+		"userId": {Type: nonNull(graphql.Int), Resolve: resolveSource(func(m model.ProjectMember) any {
+			return m.UserID
+		})},
+		"username": {Type: nonNull(graphql.String), Resolve: resolveSource(func(m model.ProjectMember) any {
+			return m.Username
+		})},
+		"joined": {Type: nonNull(graphql.String), Resolve: resolveSource(func(m model.ProjectMember) any {
+			return graphQLTime(m.Joined)
+		})},
+	}})
+
+	// The task and the project refer to each other, their fields are defined when the schema is built.
+	var task, project *graphql.Object
+
+	task = graphql.NewObject(graphql.ObjectConfig{Name: "Task", Fields: graphql.FieldsThunk(func() graphql.Fields {
+		return graphql.Fields{
+			"id": {Type: nonNull(graphql.Int), Resolve: resolveSource(func(t model.Task) any {
+				return t.ID
+			})},
+			"title": {Type: nonNull(graphql.String), Resolve: resolveSource(func(t model.Task) any {
+				return t.Title
+			})},
+			"completed": {Type: nonNull(graphql.Boolean), Resolve: resolveSource(func(t model.Task) any {
+				return t.Status
+			})},
+			"created": {Type: nonNull(graphql.String), Resolve: resolveSource(func(t model.Task) any {
+				return graphQLTime(t.Created)
+			})},
+			"updated": {Type: nonNull(graphql.String), Resolve: resolveSource(func(t model.Task) any {
+				return graphQLTime(t.Updated)
+			})},
+			"completedAt": {Type: graphql.String, Resolve: resolveSource(func(t model.Task) any {
+				if t.Completed.IsZero() {
+					return nil
+				}
+
+				return graphQLTime(t.Completed)
+			})},
+			"due": {Type: graphql.String, Resolve: resolveSource(func(t model.Task) any {
+				if t.Due == nil {
+					return nil
+				}
+
+				return graphQLTime(*t.Due)
+			})},
+			"recurrence": {Type: graphql.String, Resolve: resolveSource(func(t model.Task) any {
+				return graphQLNullString(t.Recurrence)
+			})},
+			"version": {Type: nonNull(graphql.Int), Resolve: resolveSource(func(t model.Task) any {
+				return t.Version
+			})},
+			"history":  {Type: nonNull(graphql.NewList(nonNull(taskChange))), Resolve: r.taskHistory},
+			"tags":     {Type: nonNull(graphql.NewList(nonNull(graphql.String))), Resolve: r.taskTags},
+			"comments": {Type: nonNull(graphql.NewList(nonNull(comment))), Resolve: r.taskComments},
+			"subtasks": {Type: nonNull(graphql.NewList(nonNull(task))), Resolve: r.taskSubtasks},
+			"parentId": {Type: graphql.Int, Resolve: resolveSource(func(t model.Task) any {
+				if t.ParentID == 0 {
+					return nil
+				}
+
+				return t.ParentID
+			})},
+			"project": {Type: project, Resolve: r.taskProject},
+		}
+	})})
+
+	project = graphql.NewObject(graphql.ObjectConfig{Name: "Project", Fields: graphql.FieldsThunk(func() graphql.Fields {
+		return graphql.Fields{
+			"id": {Type: nonNull(graphql.Int), Resolve: resolveSource(func(p model.Project) any {
+				return p.ID
+			})},
+			"name": {Type: nonNull(graphql.String), Resolve: resolveSource(func(p model.Project) any {
+				return p.Name
+			})},
+			"ownerId": {Type: nonNull(graphql.Int), Resolve: resolveSource(func(p model.Project) any {
+				return p.OwnerID
+			})},
+			"created": {Type: nonNull(graphql.String), Resolve: resolveSource(func(p model.Project) any {
+				return graphQLTime(p.Created)
+			})},
+			"members": {Type: nonNull(graphql.NewList(nonNull(projectMember))), Resolve: r.projectMembers},
+			"tasks":   {Type: nonNull(graphql.NewList(nonNull(task))), Resolve: r.projectTasks},
+		}
+	})})
+
+	deletedTask := graphql.NewObject(graphql.ObjectConfig{Name: "DeletedTask", Fields: graphql.Fields{
+		"task": {Type: nonNull(task), Resolve: resolveSource(func(t model.DeletedTask) any {
+			return t.Task
+		})},
+		"deleted": {Type: nonNull(graphql.String), Resolve: resolveSource(func(t model.DeletedTask) any {
+			return graphQLTime(t.Deleted)
+		})},
+	}})
+
+	pageInfo := graphql.NewObject(graphql.ObjectConfig{Name: "PageInfo", Fields: graphql.Fields{
+		"hasNextPage": {Type: nonNull(graphql.Boolean)},
+		"endCursor":   {Type: graphql.String},
+	}})
+
+	page := graphql.FieldConfigArgument{
+		"first": {Type: graphql.Int, DefaultValue: defaultGraphQLPageSize},
+		"after": {Type: graphql.String},
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{Name: "Query", Fields: graphql.Fields{
+		"task": {
+			Type:    task,
+			Args:    graphql.FieldConfigArgument{"id": {Type: nonNull(graphql.Int)}},
+			Resolve: r.task,
+		},
+		"tasks": {
+			Type: nonNull(graphQLConnection("Task", task, pageInfo, r.tasksTotalCount)),
+			Args: graphql.FieldConfigArgument{
+				"first":     page["first"],
+				"after":     page["after"],
+				"completed": {Type: graphql.Boolean},
+			},
+			Resolve: r.tasks,
+		},
+		"trash": {
+			Type:    nonNull(graphQLConnection("DeletedTask", deletedTask, pageInfo, nil)),
+			Args:    page,
+			Resolve: r.trash,
+		},
+		"projects": {
+			Type:    nonNull(graphql.NewList(nonNull(project))),
+			Resolve: r.projects,
+		},
+		"project": {
+			Type:    project,
+			Args:    graphql.FieldConfigArgument{"id": {Type: nonNull(graphql.Int)}},
+			Resolve: r.project,
+		},
+	}})
+
+	taskID := &graphql.ArgumentConfig{Type: nonNull(graphql.Int)}
+	version := &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0}
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{Name: "Mutation", Fields: graphql.Fields{
+		"createTask": {
+			Type:    nonNull(task),
+			Args:    graphql.FieldConfigArgument{"title": {Type: nonNull(graphql.String)}},
+			Resolve: r.createTask,
+		},
+		"updateTask": {
+			Type: nonNull(task),
+			Args: graphql.FieldConfigArgument{
+				"id":         taskID,
+				"title":      {Type: graphql.String},
+				"completed":  {Type: graphql.Boolean},
+				"due":        {Type: graphql.String},
+				"recurrence": {Type: graphql.String},
+				"version":    version,
+			},
+			Resolve: r.updateTask,
+		},
+		"deleteTask": {
+			Type: nonNull(graphql.Boolean),
+			Args: graphql.FieldConfigArgument{
+				"id":        taskID,
+				"permanent": {Type: graphql.Boolean, DefaultValue: false},
+				"version":   version,
+			},
+			Resolve: r.deleteTask,
+		},
+		"restoreTask": {
+			Type:    nonNull(task),
+			Args:    graphql.FieldConfigArgument{"id": taskID},
+			Resolve: r.restoreTask,
+		},
+		"revertTask": {
+			Type:    nonNull(task),
+			Args:    graphql.FieldConfigArgument{"id": taskID, "revision": {Type: nonNull(graphql.Int)}},
+			Resolve: r.revertTask,
+		},
+		"setTaskTags": {
+			Type: nonNull(task),
+			Args: graphql.FieldConfigArgument{
+				"id":   taskID,
+				"tags": {Type: nonNull(graphql.NewList(nonNull(graphql.String)))},
+			},
+			Resolve: r.setTaskTags,
+		},
+		"setTaskParent": {
+			Type:    nonNull(task),
+			Args:    graphql.FieldConfigArgument{"id": taskID, "parentId": {Type: graphql.Int}},
+			Resolve: r.setTaskParent,
+		},
+		"setTaskProject": {
+			Type:    nonNull(task),
+			Args:    graphql.FieldConfigArgument{"id": taskID, "projectId": {Type: graphql.Int}},
+			Resolve: r.setTaskProject,
+		},
+		"addComment": {
+			Type: nonNull(comment),
+			Args: graphql.FieldConfigArgument{
+				"taskId": {Type: nonNull(graphql.Int)},
+				"text":   {Type: nonNull(graphql.String)},
+			},
+			Resolve: r.addComment,
+		},
+		"deleteComment": {
+			Type:    nonNull(graphql.Boolean),
+			Args:    graphql.FieldConfigArgument{"id": {Type: nonNull(graphql.Int)}},
+			Resolve: r.deleteComment,
+		},
+		"createProject": {
+			Type:    nonNull(project),
+			Args:    graphql.FieldConfigArgument{"name": {Type: nonNull(graphql.String)}},
+			Resolve: r.createProject,
+		},
+		"deleteProject": {
+			Type:    nonNull(graphql.Boolean),
+			Args:    graphql.FieldConfigArgument{"id": {Type: nonNull(graphql.Int)}},
+			Resolve: r.deleteProject,
+		},
+		"addProjectMember": {
+			Type: nonNull(projectMember),
+			Args: graphql.FieldConfigArgument{
+				"projectId": {Type: nonNull(graphql.Int)},
+				"username":  {Type: nonNull(graphql.String)},
+			},
+			Resolve: r.addProjectMember,
+		},
+		"removeProjectMember": {
+			Type: nonNull(graphql.Boolean),
+			Args: graphql.FieldConfigArgument{
+				"projectId": {Type: nonNull(graphql.Int)},
+				"userId":    {Type: nonNull(graphql.Int)},
+			},
+			Resolve: r.removeProjectMember,
+		},
+	}})
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query:    query,
+		Mutation: mutation,
+	})
+	if err != nil {
+		return graphql.Schema{}, fmt.Errorf("new schema: %w", err)
+	}
+
+	return schema, nil
+}
+
+func nonNull(t graphql.Type) *graphql.NonNull {
+	return graphql.NewNonNull(t)
+}
+
+// resolveSource the value of the field of the source of the type T.
+func resolveSource[T any](value func(source T) any) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (any, error) {
+		source, _ := p.Source.(T)
+
+		return value(source), nil
+	}
+}
+
+// graphQLNullString the empty value of the history is null.
+func graphQLNullString(s string) any {
+	if s == "" {
+		return nil
+	}
+
+	return s
+}
+
+func graphQLTime(t time.Time) string {
+	return t.Format(time.RFC3339Nano)
+}
+
+// graphQLConnection the page of the nodes: edges, pageInfo and totalCount - of the whole list.
+// totalCount nil - the value of the connection.
+func graphQLConnection(
+	name string, node *graphql.Object, pageInfo *graphql.Object, totalCount graphql.FieldResolveFn,
+) *graphql.Object {
+	edge := graphql.NewObject(graphql.ObjectConfig{Name: name + "Edge", Fields: graphql.Fields{
+		"cursor": {Type: nonNull(graphql.String)},
+		"node":   {Type: nonNull(node)},
+	}})
+
+	return graphql.NewObject(graphql.ObjectConfig{Name: name + "Connection", Fields: graphql.Fields{
+		"edges":      {Type: nonNull(graphql.NewList(nonNull(edge)))},
+		"pageInfo":   {Type: nonNull(pageInfo)},
+		"totalCount": {Type: nonNull(graphql.Int), Resolve: totalCount},
+	}})
+}
+
+// graphQLPage the nodes after the node of the cursor.
+type graphQLPage struct {
+	first int
+	// afterID 0 - from the first node.
+	afterID int
+}
+
+func graphQLPageFromArgs(args map[string]any) (graphQLPage, error) {
+	first, _ := args["first"].(int)
+	if first < 1 || first > maxGraphQLPageSize {
+		return graphQLPage{}, newGraphQLError(
+			typeParameterRequired,
+			fmt.Sprintf("first: min 1, max %d", maxGraphQLPageSize),
+		)
+	}
+
+	page := graphQLPage{first: first}
+
+	after, ok := args["after"].(string)
+	if !ok {
+		return page, nil
+	}
+
+	id, err := graphQLCursorID(after)
+	if err != nil {
+		return graphQLPage{}, newGraphQLError(typeParameterRequired, "after: invalid cursor")
+	}
+
+	page.afterID = id
+
+	return page, nil
+}
+
+func graphQLCursor(taskID int) string {
+	return base64.StdEncoding.EncodeToString([]byte(graphQLCursorPrefix + strconv.Itoa(taskID)))
+}
+
+func graphQLCursorID(cursor string) (int, error) {
+	b, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("decode: %w", err)
+	}
+
+	id, err := strconv.Atoi(strings.TrimPrefix(string(b), graphQLCursorPrefix))
+	if err != nil || !strings.HasPrefix(string(b), graphQLCursorPrefix) || id < 1 {
+		return 0, fmt.Errorf("%w: %q", errInvalidTask, b)
+	}
+
+	return id, nil
+}
+
+// graphQLConnectionPage the connection of the nodes from start, the nodes are in the order of the list.
+func graphQLConnectionPage[T any](nodes []T, start, first int, nodeID func(T) int) map[string]any {
+	end := start + first
+	if end > len(nodes) {
+		end = len(nodes)
+	}
+
+	connection := graphQLConnectionEdges(nodes[start:end], end < len(nodes), nodeID)
+	connection["totalCount"] = len(nodes)
+
+	return connection
+}
+
+// graphQLConnectionEdges the connection of the nodes of the page without totalCount.
+func graphQLConnectionEdges[T any](nodes []T, hasNextPage bool, nodeID func(T) int) map[string]any {
+	edges := make([]map[string]any, 0, len(nodes))
+
+	for _, node := range nodes {
+		edges = append(edges, map[string]any{
+			"cursor": graphQLCursor(nodeID(node)),
+			"node":   node,
+		})
+	}
+
+	var endCursor any
+	if len(edges) > 0 {
+		endCursor = edges[len(edges)-1]["cursor"]
+	}
+
+	return map[string]any{
+		"edges": edges,
+		"pageInfo": map[string]any{
+			"hasNextPage": hasNextPage,
+			"endCursor":   endCursor,
+		},
+	}
+}
+
+type graphQLResolver struct {
+	storage GraphQLStorage
+}
+
+func (r graphQLResolver) task(p graphql.ResolveParams) (any, error) {
+	req := requestOfGraphQL(p.Context)
+	taskID, _ := p.Args["id"].(int)
+
+	task, err := r.storage.GetTask(p.Context, req.username, req.password, taskID)
+	if err != nil {
+		if errors.Is(err, model.ErrTaskNotFound) {
+			return nil, nil
+		}
+
+		return nil, graphQLStorageError(err, taskID, "get task")
+	}
+
+	// GetTask does not return the id.
+	task.ID = taskID
+
+	req.prime(task.ID)
+
+	return task, nil
+}
+
+// tasks sorted by id, the cursor is of the task after which the page starts, the task can be deleted meanwhile.
+// Only the page is read, with one more task for hasNextPage.
+func (r graphQLResolver) tasks(p graphql.ResolveParams) (any, error) {
+	req := requestOfGraphQL(p.Context)
+
+	page, err := graphQLPageFromArgs(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	query := model.TaskQuery{AfterID: page.afterID, Limit: page.first + 1}
+
+	if completed, ok := p.Args["completed"].(bool); ok {
+		query.Completed = &completed
+	}
+
+	tasks, err := r.storage.GetTasksPage(p.Context, req.username, req.password, query)
+	if err != nil {
+		return nil, graphQLStorageError(err, 0, "get tasks")
+	}
+
+	hasNextPage := len(tasks) > page.first
+	if hasNextPage {
+		tasks = tasks[:page.first]
+	}
+
+	for _, task := range tasks {
+		req.prime(task.ID)
+	}
+
+	connection := graphQLConnectionEdges(tasks, hasNextPage, func(t model.Task) int {
+		return t.ID
+	})
+
+	// For totalCount.
+	connection["completed"] = query.Completed
+
+	return connection, nil
+}
+
+// tasksTotalCount the tasks are counted only if totalCount is selected.
+func (r graphQLResolver) tasksTotalCount(p graphql.ResolveParams) (any, error) {
+	req := requestOfGraphQL(p.Context)
+
+	connection, _ := p.Source.(map[string]any)
+	completed, _ := connection["completed"].(*bool)
+
+	quantity, err := r.storage.CountTasks(p.Context, req.username, req.password, completed)
+	if err != nil {
+		return nil, graphQLStorageError(err, 0, "count tasks")
+	}
+
+	return quantity, nil
+}
+
+// trash sorted by the deletion, the newest first, the task of the cursor must be in the trash.
+func (r graphQLResolver) trash(p graphql.ResolveParams) (any, error) {
+	req := requestOfGraphQL(p.Context)
+
+	page, err := graphQLPageFromArgs(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := r.storage.GetTrash(p.Context, req.username, req.password)
+	if err != nil {
+		return nil, graphQLStorageError(err, 0, "get trash")
+	}
+
+	start := 0
+
+	if page.afterID != 0 {
+		start = -1
+
+		for i, task := range tasks {
+			if task.ID == page.afterID {
+				start = i + 1
+
+				break
+			}
+		}
+
+		if start < 0 {
+			return nil, newGraphQLError(typeTaskNotFound, "after: "+strconv.Itoa(page.afterID))
+		}
+	}
+
+	connection := graphQLConnectionPage(tasks, start, page.first, func(t model.DeletedTask) int {
+		return t.ID
+	})
+
+	for _, edge := range connection["edges"].([]map[string]any) {
+		req.prime(edge["node"].(model.DeletedTask).ID)
+	}
+
+	return connection, nil
+}
+
+func (r graphQLResolver) taskHistory(p graphql.ResolveParams) (any, error) {
+	t, _ := p.Source.(model.Task)
+
+	history, err := requestOfGraphQL(p.Context).history.get(p.Context, t.ID)
+	if err != nil {
+		return nil, graphQLStorageError(err, t.ID, "get tasks history")
+	}
+
+	return history, nil
+}
+
+func (r graphQLResolver) taskTags(p graphql.ResolveParams) (any, error) {
+	t, _ := p.Source.(model.Task)
+
+	tags, err := requestOfGraphQL(p.Context).tags.get(p.Context, t.ID)
+	if err != nil {
+		return nil, graphQLStorageError(err, t.ID, "get tasks tags")
+	}
+
+	return tags, nil
+}
+
+func (r graphQLResolver) taskComments(p graphql.ResolveParams) (any, error) {
+	t, _ := p.Source.(model.Task)
+
+	comments, err := requestOfGraphQL(p.Context).comments.get(p.Context, t.ID)
+	if err != nil {
+		return nil, graphQLStorageError(err, t.ID, "get tasks comments")
+	}
+
+	return comments, nil
+}
+
+func (r graphQLResolver) taskSubtasks(p graphql.ResolveParams) (any, error) {
+	t, _ := p.Source.(model.Task)
+
+	subtasks, err := requestOfGraphQL(p.Context).subtasks.get(p.Context, t.ID)
+	if err != nil {
+		return nil, graphQLStorageError(err, t.ID, "get subtasks")
+	}
+
+	return subtasks, nil
+}
+
+// taskProject the projects of the user are read once for all the tasks of the response.
+func (r graphQLResolver) taskProject(p graphql.ResolveParams) (any, error) {
+	t, _ := p.Source.(model.Task)
+
+	if t.ProjectID == 0 {
+		return nil, nil
+	}
+
+	return r.projectOfUser(p.Context, t.ProjectID)
+}
+
+// projectOfUser nil - the user is not a member of the project.
+func (r graphQLResolver) projectOfUser(ctx context.Context, projectID int) (any, error) {
+	req := requestOfGraphQL(ctx)
+
+	if req.projects == nil {
+		projects, err := r.storage.GetProjects(ctx, req.userID)
+		if err != nil {
+			return nil, graphQLStorageError(err, projectID, "get projects")
+		}
+
+		req.projects = make(map[int]model.Project, len(projects))
+
+		for _, project := range projects {
+			req.projects[project.ID] = project
+		}
+	}
+
+	project, ok := req.projects[projectID]
+	if !ok {
+		return nil, nil
+	}
+
+	return project, nil
+}
+
+func (r graphQLResolver) projects(p graphql.ResolveParams) (any, error) {
+	req := requestOfGraphQL(p.Context)
+
+	projects, err := r.storage.GetProjects(p.Context, req.userID)
+	if err != nil {
+		return nil, graphQLStorageError(err, 0, "get projects")
+	}
+
+	req.projects = make(map[int]model.Project, len(projects))
+
+	for _, project := range projects {
+		req.projects[project.ID] = project
+	}
+
+	return projects, nil
+}
+
+func (r graphQLResolver) project(p graphql.ResolveParams) (any, error) {
+	projectID, _ := p.Args["id"].(int)
+
+	return r.projectOfUser(p.Context, projectID)
+}
+
+// projectMembers one query per project, the user is a member of a few projects.
+func (r graphQLResolver) projectMembers(p graphql.ResolveParams) (any, error) {
+	project, _ := p.Source.(model.Project)
+
+	members, err := r.storage.GetProjectMembers(p.Context, requestOfGraphQL(p.Context).userID, project.ID)
+	if err != nil {
+		return nil, graphQLStorageError(err, project.ID, "get project members")
+	}
+
+	return members, nil
+}
+
+func (r graphQLResolver) projectTasks(p graphql.ResolveParams) (any, error) {
+	req := requestOfGraphQL(p.Context)
+	project, _ := p.Source.(model.Project)
+
+	tasks, err := r.storage.GetProjectTasks(p.Context, req.userID, project.ID)
+	if err != nil {
+		return nil, graphQLStorageError(err, project.ID, "get project tasks")
+	}
+
+	for _, task := range tasks {
+		req.prime(task.ID)
+	}
+
+	return tasks, nil
+}
+
+func (r graphQLResolver) createTask(p graphql.ResolveParams) (any, error) {
+	req := requestOfGraphQL(p.Context)
+	title, _ := p.Args["title"].(string)
+
+	if err := graphQLLengthError("title", title, maxLengthTaskTitle); err != nil {
+		return nil, err
+	}
+
+	taskID, err := r.storage.CreateTask(p.Context, req.username, req.password, title)
+	if err != nil {
+		return nil, graphQLStorageError(err, 0, "create task")
+	}
+
+	return r.getTask(p.Context, taskID)
+}
+
+func (r graphQLResolver) updateTask(p graphql.ResolveParams) (any, error) {
+	req := requestOfGraphQL(p.Context)
+	taskID, _ := p.Args["id"].(int)
+	version, _ := p.Args["version"].(int)
+
+	var update model.TaskUpdate
+
+	if title, ok := p.Args["title"].(string); ok {
+		if err := graphQLLengthError("title", title, maxLengthTaskTitle); err != nil {
+			return nil, err
+		}
+
+		update.Title = &title
+	}
+
+	if completed, ok := p.Args["completed"].(bool); ok {
+		update.Status = &completed
+	}
+
+	if due, ok := p.Args["due"].(string); ok {
+		var t time.Time
+
+		if due != "" {
+			var err error
+
+			if t, err = time.Parse(time.RFC3339, due); err != nil {
+				return nil, newGraphQLError(typeParameterRequired, "due: RFC 3339")
+			}
+
+			t = t.UTC()
+		}
+
+		update.Due = &t
+	}
+
+	if recurrence, ok := p.Args["recurrence"].(string); ok {
+		if err := checkRecurrence(recurrence); err != nil {
+			return nil, newGraphQLError(typeParameterRequired, "recurrence: "+err.Error())
+		}
+
+		update.Recurrence = &recurrence
+	}
+
+	if update.Title == nil && update.Status == nil && update.Due == nil && update.Recurrence == nil {
+		return nil, newGraphQLError(typeParametersRequired, "title, completed, due or recurrence")
+	}
+
+	if err := r.storage.UpdateTask(p.Context, req.username, req.password, taskID, version, update); err != nil {
+		return nil, graphQLStorageError(err, taskID, "update task")
+	}
+
+	return r.getTask(p.Context, taskID)
+}
+
+func (r graphQLResolver) deleteTask(p graphql.ResolveParams) (any, error) {
+	req := requestOfGraphQL(p.Context)
+	taskID, _ := p.Args["id"].(int)
+	version, _ := p.Args["version"].(int)
+
+	deleteTask := r.storage.DeleteTask
+	if permanent, _ := p.Args["permanent"].(bool); permanent {
+		deleteTask = r.storage.DeleteTaskPermanently
+	}
+
+	if err := deleteTask(p.Context, req.username, req.password, taskID, version); err != nil {
+		return nil, graphQLStorageError(err, taskID, "delete task")
+	}
+
+	return true, nil
+}
+
+func (r graphQLResolver) restoreTask(p graphql.ResolveParams) (any, error) {
+	req := requestOfGraphQL(p.Context)
+	taskID, _ := p.Args["id"].(int)
+
+	if err := r.storage.RestoreTask(p.Context, req.username, req.password, taskID); err != nil {
+		return nil, graphQLStorageError(err, taskID, "restore task")
+	}
+
+	return r.getTask(p.Context, taskID)
+}
+
+func (r graphQLResolver) revertTask(p graphql.ResolveParams) (any, error) {
+	req := requestOfGraphQL(p.Context)
+	taskID, _ := p.Args["id"].(int)
+	revision, _ := p.Args["revision"].(int)
+
+	if err := r.storage.RevertTask(p.Context, req.username, req.password, taskID, revision); err != nil {
+		return nil, graphQLStorageError(err, taskID, "revert task")
+	}
+
+	return r.getTask(p.Context, taskID)
+}
+
+// setTaskTags replaces the tags, the duplicates are ignored.
+func (r graphQLResolver) setTaskTags(p graphql.ResolveParams) (any, error) {
+	req := requestOfGraphQL(p.Context)
+	taskID, _ := p.Args["id"].(int)
+	values, _ := p.Args["tags"].([]any)
+
+	tags := make([]string, 0, len(values))
+
+	for _, value := range values {
+		tag, _ := value.(string)
+
+		if err := graphQLLengthError("tags", tag, maxLengthTag); err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, tag)
+	}
+
+	if err := r.storage.SetTaskTags(p.Context, req.userID, taskID, tags); err != nil {
+		return nil, graphQLStorageError(err, taskID, "set task tags")
+	}
+
+	return r.getTask(p.Context, taskID)
+}
+
+func (r graphQLResolver) setTaskParent(p graphql.ResolveParams) (any, error) {
+	req := requestOfGraphQL(p.Context)
+	taskID, _ := p.Args["id"].(int)
+	parentID, _ := p.Args["parentId"].(int)
+
+	if err := r.storage.SetTaskParent(p.Context, req.username, req.password, taskID, parentID); err != nil {
+		return nil, graphQLStorageError(err, taskID, "set task parent")
+	}
+
+	return r.getTask(p.Context, taskID)
+}
+
+func (r graphQLResolver) setTaskProject(p graphql.ResolveParams) (any, error) {
+	req := requestOfGraphQL(p.Context)
+	taskID, _ := p.Args["id"].(int)
+	projectID, _ := p.Args["projectId"].(int)
+
+	if err := r.storage.SetTaskProject(p.Context, req.username, req.password, taskID, projectID); err != nil {
+		if errors.Is(err, model.ErrProjectNotFound) {
+			return nil, newGraphQLError(typeProjectNotFound, strconv.Itoa(projectID))
+		}
+
+		return nil, graphQLStorageError(err, taskID, "set task project")
+	}
+
+	return r.getTask(p.Context, taskID)
+}
+
+func (r graphQLResolver) addComment(p graphql.ResolveParams) (any, error) {
+	req := requestOfGraphQL(p.Context)
+	taskID, _ := p.Args["taskId"].(int)
+	text, _ := p.Args["text"].(string)
+
+	if err := graphQLLengthError("text", text, maxLengthComment); err != nil {
+		return nil, err
+	}
+
+	commentID, err := r.storage.CreateTaskComment(p.Context, req.userID, taskID, text)
+	if err != nil {
+		return nil, graphQLStorageError(err, taskID, "create task comment")
+	}
+
+	comments, err := r.storage.GetTasksComments(p.Context, req.userID, []int{taskID})
+	if err != nil {
+		return nil, graphQLStorageError(err, taskID, "get tasks comments")
+	}
+
+	for _, comment := range comments[taskID] {
+		if comment.ID == commentID {
+			return comment, nil
+		}
+	}
+
+	return nil, graphQLStorageError(model.ErrCommentNotFound, commentID, "get tasks comments")
+}
+
+// deleteComment only its author deletes the comment.
+func (r graphQLResolver) deleteComment(p graphql.ResolveParams) (any, error) {
+	commentID, _ := p.Args["id"].(int)
+
+	if err := r.storage.DeleteTaskComment(p.Context, requestOfGraphQL(p.Context).userID, commentID); err != nil {
+		return nil, graphQLStorageError(err, commentID, "delete task comment")
+	}
+
+	return true, nil
+}
+
+func (r graphQLResolver) createProject(p graphql.ResolveParams) (any, error) {
+	req := requestOfGraphQL(p.Context)
+	name, _ := p.Args["name"].(string)
+
+	if err := graphQLLengthError("name", name, maxLengthProjectName); err != nil {
+		return nil, err
+	}
+
+	projectID, err := r.storage.CreateProject(p.Context, req.userID, name)
+	if err != nil {
+		return nil, graphQLStorageError(err, 0, "create project")
+	}
+
+	req.projects = nil
+
+	project, err := r.projectOfUser(p.Context, projectID)
+	if err != nil || project == nil {
+		return nil, graphQLStorageError(model.ErrProjectNotFound, projectID, "get projects")
+	}
+
+	return project, nil
+}
+
+// deleteProject only the owner deletes the project, its tasks leave it.
+func (r graphQLResolver) deleteProject(p graphql.ResolveParams) (any, error) {
+	req := requestOfGraphQL(p.Context)
+	projectID, _ := p.Args["id"].(int)
+
+	if err := r.storage.DeleteProject(p.Context, req.userID, projectID); err != nil {
+		return nil, graphQLStorageError(err, projectID, "delete project")
+	}
+
+	req.projects = nil
+
+	return true, nil
+}
+
+// addProjectMember by the owner, the member is returned even if they are already a member.
+func (r graphQLResolver) addProjectMember(p graphql.ResolveParams) (any, error) {
+	req := requestOfGraphQL(p.Context)
+	projectID, _ := p.Args["projectId"].(int)
+	username, _ := p.Args["username"].(string)
+
+	memberID, _, err := r.storage.AddProjectMember(p.Context, req.userID, projectID, username)
+	if err != nil {
+		if errors.Is(err, model.ErrUserNotFound) {
+			return nil, newGraphQLError(typeUserNotFound, username)
+		}
+
+		return nil, graphQLStorageError(err, projectID, "add project member")
+	}
+
+	members, err := r.storage.GetProjectMembers(p.Context, req.userID, projectID)
+	if err != nil {
+		return nil, graphQLStorageError(err, projectID, "get project members")
+	}
+
+	for _, member := range members {
+		if member.UserID == memberID {
+			return member, nil
+		}
+	}
+
+	return nil, newGraphQLError(typeUserNotFound, username)
+}
+
+// removeProjectMember by the owner or by the member themselves.
+func (r graphQLResolver) removeProjectMember(p graphql.ResolveParams) (any, error) {
+	req := requestOfGraphQL(p.Context)
+	projectID, _ := p.Args["projectId"].(int)
+	memberID, _ := p.Args["userId"].(int)
+
+	if err := r.storage.RemoveProjectMember(p.Context, req.userID, projectID, memberID); err != nil {
+		if errors.Is(err, model.ErrUserNotFound) {
+			return nil, newGraphQLError(typeUserNotFound, strconv.Itoa(memberID))
+		}
+
+		return nil, graphQLStorageError(err, projectID, "remove project member")
+	}
+
+	req.projects = nil
+
+	return true, nil
+}
+
+// getTask the task after the mutation.
+func (r graphQLResolver) getTask(ctx context.Context, taskID int) (model.Task, error) {
+	req := requestOfGraphQL(ctx)
+
+	task, err := r.storage.GetTask(ctx, req.username, req.password, taskID)
+	if err != nil {
+		return model.Task{}, graphQLStorageError(err, taskID, "get task")
+	}
+
+	task.ID = taskID
+
+	return task, nil
+}
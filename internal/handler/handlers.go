@@ -2,30 +2,74 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"taskmanager/internal/model"
+	"taskmanager/internal/security"
 )
 
+// HTTPError the error response of /api/v1, in /api/v2 it is written as Problem.
 type HTTPError struct {
 	Error   string `json:"-"`
 	Type    string `json:"type,omitempty"`
 	Comment string `json:"comment,omitempty"`
+
+	fields []FieldError
+	// swappedInV1 Type and Comment are swapped in /api/v1, it is kept for compatibility.
+	swappedInV1 bool
 }
 
 // 401.
 const (
+	typeCalendarTokenNotFound = "CALENDAR_TOKEN_NOT_FOUND"
+	typeCommentNotFound       = "COMMENT_NOT_FOUND"
+	typeImportInvalid         = "IMPORT_INVALID"
+	typeNotificationNotFound  = "NOTIFICATION_NOT_FOUND"
 	typeParameterTooLong      = "PARAMETER_TOO_LONG"
 	typeParameterRequired     = "PARAMETER_REQUIRED"
 	typeParametersRequired    = "PARAMETERS_REQUIRED"
 	typePasswordRequired      = "PASSWORD_REQUIRED"
+	typePatchInvalid          = "PATCH_INVALID"
+	typeProjectNotFound       = "PROJECT_NOT_FOUND"
+	typeReminderNotFound      = "REMINDER_NOT_FOUND"
+	typeRevisionNotFound      = "REVISION_NOT_FOUND"
 	typeTaskAlreadyExists     = "TASK_ALREADY_EXISTS"
 	typeTaskNotFound          = "TASK_NOT_FOUND"
+	typeTaskParentInvalid     = "TASK_PARENT_INVALID"
 	typeUsernameAlreadyExists = "USERNAME_ALREADY_EXISTS"
 	typeUsernameRequired      = "USERNAME_REQUIRED"
 	typeUserNotFound          = "USER_NOT_FOUND"
+	typeWebhookNotFound       = "WEBHOOK_NOT_FOUND"
+)
+
+// 409.
+const (
+	typeIdempotencyKeyInProgress = "IDEMPOTENCY_KEY_IN_PROGRESS"
+	typePatchTestFailed          = "PATCH_TEST_FAILED"
+)
+
+// 412.
+const (
+	typeTaskVersionMismatch = "TASK_VERSION_MISMATCH"
+)
+
+// 415.
+const (
+	typeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+)
+
+// 422.
+const (
+	typeIdempotencyKeyReused = "IDEMPOTENCY_KEY_REUSED"
+)
+
+// 424.
+const (
+	typeOperationRolledBack = "OPERATION_ROLLED_BACK"
 )
 
 // 500.
@@ -33,23 +77,179 @@ const (
 	typeInternalError = "INTERNAL"
 )
 
-type PostgresDB interface {
+// Storage is implemented by model.Postgres, model.SQLite and model.Memory.
+type Storage interface {
+	UserStorage
+	TaskStorage
+	IdempotencyStorage
+	WebhookStorage
+	EventStorage
+	CalendarStorage
+	ReminderStorage
+	NotificationStorage
+	ProjectStorage
+	TaskRelationStorage
+}
+
+type UserStorage interface {
 	CreateNewUser(ctx context.Context, username string, password string) (int, error)
 	DeleteUser(ctx context.Context, userID int) error
+}
 
+type TaskStorage interface {
 	CreateTask(ctx context.Context, username, password, title string) (int, error)
 	GetTask(ctx context.Context, username, password string, taskID int) (model.Task, error)
-	UpdateTask(ctx context.Context, username, password string, taskID int, setValues []string) error
-	DeleteTask(ctx context.Context, username, password string, taskID int) error
+	UpdateTask(ctx context.Context, username, password string, taskID, version int, update model.TaskUpdate) error
+	DeleteTask(ctx context.Context, username, password string, taskID, version int) error
+	DeleteTaskPermanently(ctx context.Context, username, password string, taskID, version int) error
+	RestoreTask(ctx context.Context, username, password string, taskID int) error
+
+	GetTaskHistory(ctx context.Context, username, password string, taskID int) ([]model.TaskChange, error)
+	GetTasksHistory(ctx context.Context, username, password string, taskIDs []int) (map[int][]model.TaskChange, error)
+	GetTaskRevision(ctx context.Context, username, password string, taskID, revision int) (model.Task, error)
+	RevertTask(ctx context.Context, username, password string, taskID, revision int) error
 
 	GetTasks(ctx context.Context, username, password string) ([]model.Task, error)
-	DeleteTasks(ctx context.Context, username, password string) (int64, error)
+	GetTasksPage(ctx context.Context, username, password string, query model.TaskQuery) ([]model.Task, error)
+	CountTasks(ctx context.Context, username, password string, completed *bool) (int, error)
+	DeleteTasks(ctx context.Context, username, password string) ([]int, error)
+	DeleteTasksPermanently(ctx context.Context, username, password string) ([]int, error)
+	ExportTasks(ctx context.Context, username, password string, fn func(model.ExportedTask) error) error
+
+	GetTrash(ctx context.Context, username, password string) ([]model.DeletedTask, error)
+	RestoreTasks(ctx context.Context, username, password string) ([]int, error)
+
+	BatchTasks(
+		ctx context.Context, username, password string, operations []model.TaskOperation, atomic bool,
+	) ([]model.TaskOperationResult, error)
 
 	// CreateTaskWithInjection - SQL injection.
 	CreateTaskWithInjection(ctx context.Context, username, password, title string) (int, error)
 }
 
+type IdempotencyStorage interface {
+	CredentialsStorage
+	ReserveIdempotencyKey(
+		ctx context.Context, scope, key, fingerprint string, expires time.Time,
+	) (model.IdempotentResponse, bool, error)
+	SaveIdempotentResponse(ctx context.Context, scope, key string, response model.IdempotentResponse) error
+	DeleteIdempotencyKey(ctx context.Context, scope, key string) error
+}
+
+// CredentialsStorage the routes outside of the task and manage groups get the user by the credentials.
+type CredentialsStorage interface {
+	GetUserIDByCredentials(ctx context.Context, username, password string) (int, error)
+}
+
+// WebhookStorage userID 0 - the webhooks of the manager.
+type WebhookStorage interface {
+	CredentialsStorage
+	CreateWebhook(ctx context.Context, userID int, webhook model.Webhook) (int, error)
+	GetWebhooks(ctx context.Context, userID int) ([]model.Webhook, error)
+	DeleteWebhook(ctx context.Context, userID, webhookID int) error
+	EnableWebhook(ctx context.Context, userID, webhookID int) error
+	GetWebhookDeliveries(ctx context.Context, userID, webhookID, limit int) ([]model.WebhookDelivery, error)
+}
+
+type EventStorage interface {
+	CredentialsStorage
+	GetEvents(ctx context.Context, userID int, afterSeq int64, limit int) ([]model.Event, error)
+	GetLastEventSeq(ctx context.Context, userID int) (int64, error)
+}
+
+// CalendarStorage the tokens are stored as hashes.
+type CalendarStorage interface {
+	CredentialsStorage
+	SetCalendarToken(ctx context.Context, userID int, tokenHash string) error
+	DeleteCalendarToken(ctx context.Context, userID int) error
+	GetCalendarTasks(ctx context.Context, tokenHash string) ([]model.Task, error)
+}
+
+type ReminderStorage interface {
+	CredentialsStorage
+	CreateReminder(ctx context.Context, userID, taskID int, reminder model.Reminder) (int, error)
+	GetReminders(ctx context.Context, userID, taskID int) ([]model.Reminder, error)
+	DeleteReminder(ctx context.Context, userID, taskID, reminderID int) error
+}
+
+type NotificationStorage interface {
+	CredentialsStorage
+	GetNotifications(ctx context.Context, userID int, query model.NotificationQuery) ([]model.Notification, error)
+	GetUnreadNotifications(ctx context.Context, userID int) (map[string]int, error)
+	ReadNotification(ctx context.Context, userID, notificationID int) error
+	ReadNotifications(ctx context.Context, userID int) (int64, error)
+	GetNotificationPreferences(ctx context.Context, userID int) ([]model.NotificationPreference, error)
+	SetNotificationPreferences(ctx context.Context, userID int, preferences []model.NotificationPreference) error
+}
+
+// ProjectStorage the members of the project see the tasks of each other in it.
+type ProjectStorage interface {
+	CredentialsStorage
+	CreateProject(ctx context.Context, userID int, name string) (int, error)
+	GetProjects(ctx context.Context, userID int) ([]model.Project, error)
+	DeleteProject(ctx context.Context, userID, projectID int) error
+	GetProjectMembers(ctx context.Context, userID, projectID int) ([]model.ProjectMember, error)
+	AddProjectMember(ctx context.Context, userID, projectID int, username string) (int, bool, error)
+	RemoveProjectMember(ctx context.Context, userID, projectID, memberID int) error
+	IsProjectMember(ctx context.Context, userID, projectID int) (bool, error)
+	GetProjectTasks(ctx context.Context, userID, projectID int) ([]model.Task, error)
+	SetTaskProject(ctx context.Context, username, password string, taskID, projectID int) error
+}
+
+// TaskRelationStorage the tags, the comments and the subtasks of the tasks, the batches are by the task ids.
+type TaskRelationStorage interface {
+	SetTaskTags(ctx context.Context, userID, taskID int, tags []string) error
+	GetTasksTags(ctx context.Context, userID int, taskIDs []int) (map[int][]string, error)
+	CreateTaskComment(ctx context.Context, userID, taskID int, text string) (int, error)
+	GetTasksComments(ctx context.Context, userID int, taskIDs []int) (map[int][]model.TaskComment, error)
+	DeleteTaskComment(ctx context.Context, userID, commentID int) error
+	SetTaskParent(ctx context.Context, username, password string, taskID, parentID int) error
+	GetSubtasks(ctx context.Context, userID int, parentIDs []int) (map[int][]model.Task, error)
+}
+
+// EventSubscriber wakes the event streams after new events of the user are saved, implemented by event.Hub.
+type EventSubscriber interface {
+	Subscribe(userID int) (<-chan struct{}, func())
+}
+
+func (e HTTPError) v1() HTTPError {
+	if e.swappedInV1 {
+		e.Type, e.Comment = e.Comment, e.Type
+	}
+
+	return e
+}
+
+// authorizedUserID the user of the basic auth credentials, false - the error is written.
+func authorizedUserID(ctx context.Context, c *gin.Context, storage CredentialsStorage) (int, bool) {
+	username, password, ok := c.Request.BasicAuth()
+	if !ok {
+		abortWithStatusUnauthorized(c)
+
+		return 0, false
+	}
+
+	userID, err := storage.GetUserIDByCredentials(ctx, username, security.SaltPassword(password))
+	if err != nil {
+		if errors.Is(err, model.ErrUserNotFound) {
+			abortWithStatus(c, http.StatusForbidden)
+
+			return 0, false
+		}
+
+		writeError(c, http.StatusInternalServerError, HTTPError{
+			Type:    typeInternalError,
+			Comment: "get user",
+			Error:   err.Error(),
+		})
+
+		return 0, false
+	}
+
+	return userID, true
+}
+
 func abortWithStatusUnauthorized(c *gin.Context) {
 	c.Writer.Header().Set("WWW-Authenticate", "Basic realm=Restricted")
-	c.AbortWithStatus(http.StatusUnauthorized)
+	abortWithStatus(c, http.StatusUnauthorized)
 }
@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"taskmanager/client"
+)
+
+// TestClientErrorTypes the client has its own copies of the types of the errors, they must not drift.
+func TestClientErrorTypes(t *testing.T) {
+	for _, typ := range []string{
+		typeCalendarTokenNotFound, typeCommentNotFound, typeImportInvalid, typeNotificationNotFound,
+		typeParameterTooLong, typeParameterRequired, typeParametersRequired, typePasswordRequired,
+		typePatchInvalid, typeProjectNotFound, typeReminderNotFound, typeRevisionNotFound, typeTaskAlreadyExists,
+		typeTaskNotFound, typeTaskParentInvalid, typeUsernameAlreadyExists, typeUsernameRequired, typeUserNotFound,
+		typeWebhookNotFound, typeIdempotencyKeyInProgress, typePatchTestFailed, typeTaskVersionMismatch,
+		typeUnsupportedMediaType, typeIdempotencyKeyReused, typeOperationRolledBack, typeInternalError,
+	} {
+		assert.Error(t, (&client.Error{Type: typ}).Unwrap(), typ)
+	}
+}
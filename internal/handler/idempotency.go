@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/security"
+)
+
+const (
+	headerIdempotencyKey     = "Idempotency-Key"
+	headerIdempotentReplayed = "Idempotent-Replayed"
+)
+
+const maxLengthIdempotencyKey = 255
+
+type idempotencyRecorder struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w idempotencyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+
+	return w.ResponseWriter.Write(b)
+}
+
+// Idempotency for POST requests with the Idempotency-Key header the first response is stored for ttl,
+// retries with the same key and body get the stored response, with another body - 422.
+// Keys are scoped by the request credentials, of a user or of the manager. The requests with other credentials are
+// passed to the handlers as is. Server errors are not stored, the request can be retried.
+func Idempotency(
+	ctx context.Context, storage IdempotencyStorage, ttl time.Duration, manageUsername, managePassword string,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(headerIdempotencyKey)
+		if c.Request.Method != http.MethodPost || key == "" {
+			c.Next()
+
+			return
+		}
+
+		if len(key) > maxLengthIdempotencyKey {
+			abortWithError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParameterTooLong,
+				Comment: fmt.Sprintf("%s: max %d", headerIdempotencyKey, maxLengthIdempotencyKey),
+			})
+
+			return
+		}
+
+		authenticated, err := idempotencyAuthenticated(ctx, c, storage, manageUsername, managePassword)
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, HTTPError{
+				Type:    typeInternalError,
+				Comment: "get user",
+				Error:   err.Error(),
+			})
+
+			return
+		}
+
+		// The handler rejects the credentials, its response is not stored.
+		if !authenticated {
+			c.Next()
+
+			return
+		}
+
+		// The import accepts the largest bodies.
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				abortWithError(c, http.StatusRequestEntityTooLarge, HTTPError{
+					Type:    typeParameterTooLong,
+					Comment: fmt.Sprintf("body: max %d bytes", maxImportBytes),
+				})
+
+				return
+			}
+
+			abortWithError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParametersRequired,
+				Comment: "body",
+				Error:   err.Error(),
+			})
+
+			return
+		}
+
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		scope := idempotencyScope(c.GetHeader("Authorization"))
+		fingerprint := idempotencyFingerprint(c.Request.Method, c.Request.URL.RequestURI(), body)
+
+		stored, reserved, err := storage.ReserveIdempotencyKey(ctx, scope, key, fingerprint, time.Now().Add(ttl))
+		if err != nil {
+			abortWithError(c, http.StatusInternalServerError, HTTPError{
+				Type:    typeInternalError,
+				Comment: "reserve idempotency key",
+				Error:   err.Error(),
+			})
+
+			return
+		}
+
+		if !reserved {
+			idempotencyReplay(c, stored, fingerprint)
+
+			return
+		}
+
+		recorder := &idempotencyRecorder{
+			ResponseWriter: c.Writer,
+			body:           bytes.NewBufferString(""),
+		}
+
+		c.Writer = recorder
+
+		saved := false
+
+		// The key is released if the handler panics or fails.
+		defer func() {
+			if saved {
+				return
+			}
+
+			if err := storage.DeleteIdempotencyKey(ctx, scope, key); err != nil {
+				_ = c.Error(fmt.Errorf("delete idempotency key: %w", err))
+			}
+		}()
+
+		c.Next()
+
+		// 401: the credentials were changed during the request.
+		if status := c.Writer.Status(); status == http.StatusUnauthorized || status >= http.StatusInternalServerError {
+			return
+		}
+
+		if err := storage.SaveIdempotentResponse(ctx, scope, key, model.IdempotentResponse{
+			Status:      c.Writer.Status(),
+			ContentType: c.Writer.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		}); err != nil {
+			_ = c.Error(fmt.Errorf("save idempotent response: %w", err))
+
+			return
+		}
+
+		saved = true
+	}
+}
+
+func idempotencyAuthenticated(
+	ctx context.Context, c *gin.Context, storage CredentialsStorage, manageUsername, managePassword string,
+) (bool, error) {
+	username, password, ok := c.Request.BasicAuth()
+	if !ok {
+		return false, nil
+	}
+
+	if secureCompare(username, manageUsername) && secureCompare(password, managePassword) {
+		return true, nil
+	}
+
+	if _, err := storage.GetUserIDByCredentials(ctx, username, security.SaltPassword(password)); err != nil {
+		if errors.Is(err, model.ErrUserNotFound) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+func idempotencyReplay(c *gin.Context, stored model.IdempotentResponse, fingerprint string) {
+	if stored.Fingerprint != fingerprint {
+		abortWithError(c, http.StatusUnprocessableEntity, HTTPError{
+			Type:    typeIdempotencyKeyReused,
+			Comment: "the key was used with another request",
+		})
+
+		return
+	}
+
+	if stored.Status == 0 {
+		abortWithError(c, http.StatusConflict, HTTPError{
+			Type:    typeIdempotencyKeyInProgress,
+			Comment: "the request with the key is still being processed",
+		})
+
+		return
+	}
+
+	c.Header(headerIdempotentReplayed, "true")
+
+	if len(stored.Body) == 0 {
+		c.AbortWithStatus(stored.Status)
+
+		return
+	}
+
+	c.Data(stored.Status, stored.ContentType, stored.Body)
+	c.Abort()
+}
+
+func idempotencyScope(authorization string) string {
+	hash := sha256.Sum256([]byte(authorization))
+
+	return hex.EncodeToString(hash[:])
+}
+
+func idempotencyFingerprint(method, uri string, body []byte) string {
+	hash := sha256.New()
+
+	hash.Write([]byte(method + " " + uri + "\n"))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/security"
+)
+
+type idempotencyStorageTest struct {
+	IdempotencyStorage
+
+	mu        sync.Mutex
+	responses map[string]model.IdempotentResponse
+}
+
+func (p *idempotencyStorageTest) ReserveIdempotencyKey(
+	ctx context.Context, scope, key, fingerprint string, expires time.Time,
+) (model.IdempotentResponse, bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if response, ok := p.responses[scope+key]; ok {
+		return response, false, nil
+	}
+
+	p.responses[scope+key] = model.IdempotentResponse{Fingerprint: fingerprint}
+
+	return model.IdempotentResponse{}, true, nil
+}
+
+func (p *idempotencyStorageTest) SaveIdempotentResponse(
+	ctx context.Context, scope, key string, response model.IdempotentResponse,
+) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	response.Fingerprint = p.responses[scope+key].Fingerprint
+	p.responses[scope+key] = response
+
+	return nil
+}
+
+func (p *idempotencyStorageTest) GetUserIDByCredentials(ctx context.Context, username, password string) (int, error) {
+	if username != "user" || password != security.SaltPassword("password") {
+		return 0, model.ErrUserNotFound
+	}
+
+	return 1, nil
+}
+
+func (p *idempotencyStorageTest) DeleteIdempotencyKey(ctx context.Context, scope, key string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.responses, scope+key)
+
+	return nil
+}
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	storage := &idempotencyStorageTest{
+		responses: make(map[string]model.IdempotentResponse),
+	}
+
+	calls := 0
+
+	router := gin.New()
+	router.Use(Idempotency(context.Background(), storage, time.Hour, "admin", "admin"))
+	router.POST("/task", func(c *gin.Context) {
+		calls++
+
+		if c.Query("unauthorized") != "" {
+			abortWithStatusUnauthorized(c)
+
+			return
+		}
+
+		if c.Query("fail") != "" {
+			c.Status(http.StatusInternalServerError)
+
+			return
+		}
+
+		c.JSON(http.StatusCreated, CreateTaskResult{TaskID: calls})
+	})
+
+	cases := []struct {
+		name             string
+		key              string
+		username         string
+		password         string
+		route            string
+		body             string
+		expectedCode     int
+		expectedBody     string
+		expectedCalls    int
+		expectedReplayed bool
+	}{
+		{
+			name:          "first request",
+			key:           "key1",
+			route:         "/task",
+			body:          `{"title": "task1"}`,
+			expectedCode:  http.StatusCreated,
+			expectedBody:  `{"taskId":1}`,
+			expectedCalls: 1,
+		},
+		{
+			name:             "retry",
+			key:              "key1",
+			route:            "/task",
+			body:             `{"title": "task1"}`,
+			expectedCode:     http.StatusCreated,
+			expectedBody:     `{"taskId":1}`,
+			expectedCalls:    1,
+			expectedReplayed: true,
+		},
+		{
+			name:          "same key with another body",
+			key:           "key1",
+			route:         "/task",
+			body:          `{"title": "task2"}`,
+			expectedCode:  http.StatusUnprocessableEntity,
+			expectedBody:  typeIdempotencyKeyReused,
+			expectedCalls: 1,
+		},
+		{
+			name:          "without key",
+			route:         "/task",
+			body:          `{"title": "task1"}`,
+			expectedCode:  http.StatusCreated,
+			expectedBody:  `{"taskId":2}`,
+			expectedCalls: 2,
+		},
+		{
+			name:          "server error is not stored",
+			key:           "key2",
+			route:         "/task?fail=1",
+			expectedCode:  http.StatusInternalServerError,
+			expectedCalls: 3,
+		},
+		{
+			name:          "retry after server error",
+			key:           "key2",
+			route:         "/task?fail=1",
+			expectedCode:  http.StatusInternalServerError,
+			expectedCalls: 4,
+		},
+		{
+			name:          "unauthorized is not stored",
+			key:           "key3",
+			route:         "/task?unauthorized=1",
+			expectedCode:  http.StatusUnauthorized,
+			expectedCalls: 5,
+		},
+		{
+			name:          "retry after unauthorized",
+			key:           "key3",
+			route:         "/task?unauthorized=1",
+			expectedCode:  http.StatusUnauthorized,
+			expectedCalls: 6,
+		},
+		{
+			name:          "unknown credentials are not stored",
+			key:           "key5",
+			password:      "wrong",
+			route:         "/task",
+			expectedCode:  http.StatusCreated,
+			expectedBody:  `{"taskId":7}`,
+			expectedCalls: 7,
+		},
+		{
+			name:          "retry with unknown credentials",
+			key:           "key5",
+			password:      "wrong",
+			route:         "/task",
+			expectedCode:  http.StatusCreated,
+			expectedBody:  `{"taskId":8}`,
+			expectedCalls: 8,
+		},
+		{
+			name:          "manager",
+			key:           "key6",
+			username:      "admin",
+			password:      "admin",
+			route:         "/task",
+			expectedCode:  http.StatusCreated,
+			expectedBody:  `{"taskId":9}`,
+			expectedCalls: 9,
+		},
+		{
+			name:             "manager retry",
+			key:              "key6",
+			username:         "admin",
+			password:         "admin",
+			route:            "/task",
+			expectedCode:     http.StatusCreated,
+			expectedBody:     `{"taskId":9}`,
+			expectedCalls:    9,
+			expectedReplayed: true,
+		},
+		{
+			name:          "too long key",
+			key:           strings.Repeat("k", maxLengthIdempotencyKey+1),
+			route:         "/task",
+			expectedCode:  http.StatusBadRequest,
+			expectedBody:  typeParameterTooLong,
+			expectedCalls: 9,
+		},
+		{
+			name:          "too large body",
+			key:           "key4",
+			route:         "/task",
+			body:          strings.Repeat(" ", maxImportBytes+1),
+			expectedCode:  http.StatusRequestEntityTooLarge,
+			expectedBody:  typeParameterTooLong,
+			expectedCalls: 9,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodPost, tt.route, strings.NewReader(tt.body))
+			require.NoError(t, err)
+
+			username, password := "user", "password"
+			if tt.username != "" {
+				username = tt.username
+			}
+
+			if tt.password != "" {
+				password = tt.password
+			}
+
+			req.SetBasicAuth(username, password)
+
+			if tt.key != "" {
+				req.Header.Set(headerIdempotencyKey, tt.key)
+			}
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			assert.Equal(t, tt.expectedCalls, calls)
+			assert.Equal(t, tt.expectedReplayed, w.Header().Get(headerIdempotentReplayed) == "true")
+		})
+	}
+}
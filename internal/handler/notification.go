@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"taskmanager/internal/model"
+)
+
+const defaultNotificationsLimit = 50
+
+type notificationURI struct {
+	NotificationID int `uri:"notificationId" binding:"required" example:"7"`
+}
+
+func notificationWriteError(c *gin.Context, notificationID int, comment string, err error) {
+	if errors.Is(err, model.ErrNotificationNotFound) {
+		writeError(c, http.StatusBadRequest, HTTPError{
+			Type:    typeNotificationNotFound,
+			Comment: strconv.Itoa(notificationID),
+			Error:   err.Error(),
+		})
+
+		return
+	}
+
+	writeError(c, http.StatusInternalServerError, HTTPError{
+		Type:    typeInternalError,
+		Comment: comment,
+		Error:   err.Error(),
+	})
+}
@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+const mimeProblemJSON = "application/problem+json"
+
+const contextKeyProblemDetails = "problemDetails"
+
+// problemTypeBase the type of a problem is problemTypeBase + the Type of HTTPError in lower kebab case,
+// urn:taskmanager:problem:task-not-found.
+const problemTypeBase = "urn:taskmanager:problem:"
+
+// problemTypeBlank the problem has no more semantics than the status(RFC 7807).
+const problemTypeBlank = "about:blank"
+
+var problemTitles = map[string]string{
+	typeCalendarTokenNotFound: "Calendar token not found",
+	typeImportInvalid:         "Invalid import",
+	typeNotificationNotFound:  "Notification not found",
+	typeParameterTooLong:      "Parameter too long",
+	typeParameterRequired:     "Parameter required",
+	typeParametersRequired:    "Parameters required",
+	typePasswordRequired:      "Invalid password",
+	typePatchInvalid:          "Invalid patch",
+	typeReminderNotFound:      "Reminder not found",
+	typeRevisionNotFound:      "Revision not found",
+	typeTaskAlreadyExists:     "Task already exists",
+	typeTaskNotFound:          "Task not found",
+	typeUsernameAlreadyExists: "Username already exists",
+	typeUsernameRequired:      "Invalid username",
+	typeUserNotFound:          "User not found",
+	typeWebhookNotFound:       "Webhook not found",
+
+	typeIdempotencyKeyInProgress: "Idempotency key in progress",
+	typePatchTestFailed:          "Patch test failed",
+
+	typeTaskVersionMismatch: "Task version mismatch",
+
+	typeUnsupportedMediaType: "Unsupported media type",
+
+	typeIdempotencyKeyReused: "Idempotency key reused",
+
+	typeOperationRolledBack: "Operation rolled back",
+
+	typeInternalError: "Internal error",
+}
+
+var registerFieldNamesOnce sync.Once
+
+// Problem the error response of /api/v2, application/problem+json(RFC 7807).
+type Problem struct {
+	Type     string `json:"type" example:"urn:taskmanager:problem:task-not-found"`
+	Title    string `json:"title" example:"Task not found"`
+	Status   int    `json:"status" example:"400"`
+	Detail   string `json:"detail,omitempty" example:"24"`
+	Instance string `json:"instance,omitempty" example:"/api/v2/task/24"`
+	// Errors the invalid fields of the request.
+	Errors []FieldError `json:"errors,omitempty"`
+	// Results the status of each operation of a failed atomic batch.
+	Results []BatchTaskOperationResult `json:"results,omitempty"`
+}
+
+type FieldError struct {
+	// Field the name in the body, path or query.
+	Field   string `json:"field" example:"title"`
+	Message string `json:"message" example:"required"`
+}
+
+// ProblemDetails the errors of the next handlers are written as application/problem+json.
+func ProblemDetails() gin.HandlerFunc {
+	registerFieldNamesOnce.Do(registerFieldNames)
+
+	return func(c *gin.Context) {
+		c.Set(contextKeyProblemDetails, true)
+		c.Next()
+	}
+}
+
+// BasicAuth gin.BasicAuth with the problem of 401.
+func BasicAuth(username, password string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		u, p, ok := c.Request.BasicAuth()
+		if !ok || !secureCompare(u, username) || !secureCompare(p, password) {
+			abortWithStatusUnauthorized(c)
+
+			return
+		}
+
+		c.Set(gin.AuthUserKey, u)
+		c.Next()
+	}
+}
+
+// writeError HTTPError for /api/v1, Problem for /api/v2.
+func writeError(c *gin.Context, status int, httpError HTTPError) {
+	if !c.GetBool(contextKeyProblemDetails) {
+		c.JSON(status, httpError.v1())
+
+		return
+	}
+
+	writeProblem(c, newProblem(c, status, httpError))
+}
+
+func abortWithError(c *gin.Context, status int, httpError HTTPError) {
+	writeError(c, status, httpError)
+	c.Abort()
+}
+
+// abortWithStatus the response has no body in /api/v1.
+func abortWithStatus(c *gin.Context, status int) {
+	if !c.GetBool(contextKeyProblemDetails) {
+		c.AbortWithStatus(status)
+
+		return
+	}
+
+	writeProblem(c, Problem{
+		Type:     problemTypeBlank,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Instance: c.Request.URL.Path,
+	})
+	c.Abort()
+}
+
+func newProblem(c *gin.Context, status int, httpError HTTPError) Problem {
+	return Problem{
+		Type:     problemTypeBase + strings.ReplaceAll(strings.ToLower(httpError.Type), "_", "-"),
+		Title:    problemTitle(status, httpError.Type),
+		Status:   status,
+		Detail:   httpError.Comment,
+		Instance: c.Request.URL.Path,
+		Errors:   httpError.fields,
+	}
+}
+
+func writeProblem(c *gin.Context, problem Problem) {
+	// gin keeps the content type if it is set.
+	c.Header("Content-Type", mimeProblemJSON)
+	c.JSON(problem.Status, problem)
+}
+
+func problemTitle(status int, httpErrorType string) string {
+	if title, ok := problemTitles[httpErrorType]; ok {
+		return title
+	}
+
+	return http.StatusText(status)
+}
+
+// fieldErrors of binding the request, nil if err is not about fields.
+func fieldErrors(err error) []FieldError {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		fields := make([]FieldError, 0, len(validationErrors))
+
+		for _, fieldErr := range validationErrors {
+			message := fieldErr.Tag()
+			if fieldErr.Param() != "" {
+				message += "=" + fieldErr.Param()
+			}
+
+			fields = append(fields, FieldError{
+				Field:   fieldErr.Field(),
+				Message: message,
+			})
+		}
+
+		return fields
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []FieldError{{
+			Field:   typeErr.Field,
+			Message: typeErr.Value + " instead of " + typeErr.Type.String(),
+		}}
+	}
+
+	return nil
+}
+
+// registerFieldNames the fields of the validation errors are named as in the request, not as in the struct.
+func registerFieldNames() {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "uri", "form"} {
+			name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+			if name == "-" {
+				return ""
+			}
+
+			if name != "" {
+				return name
+			}
+		}
+
+		return field.Name
+	})
+}
+
+func secureCompare(given, actual string) bool {
+	return subtle.ConstantTimeCompare([]byte(given), []byte(actual)) == 1
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"taskmanager/internal/model"
+)
+
+type projectURI struct {
+	ProjectID int `uri:"projectId" binding:"required" example:"5"`
+}
+
+type projectMemberURI struct {
+	ProjectID int `uri:"projectId" binding:"required" example:"5"`
+	UserID    int `uri:"userId" binding:"required" example:"2"`
+}
+
+func projectWriteError(c *gin.Context, projectID int, comment string, err error) {
+	switch {
+	case errors.Is(err, model.ErrProjectNotFound):
+		writeError(c, http.StatusBadRequest, HTTPError{
+			Type:    typeProjectNotFound,
+			Comment: strconv.Itoa(projectID),
+			Error:   err.Error(),
+		})
+
+	case errors.Is(err, model.ErrUserNotFound):
+		writeError(c, http.StatusBadRequest, HTTPError{
+			Type:    typeUserNotFound,
+			Comment: comment,
+			Error:   err.Error(),
+		})
+
+	case errors.Is(err, model.ErrTaskNotFound):
+		writeError(c, http.StatusBadRequest, HTTPError{
+			Type:    typeTaskNotFound,
+			Comment: comment,
+			Error:   err.Error(),
+		})
+
+	default:
+		writeError(c, http.StatusInternalServerError, HTTPError{
+			Type:    typeInternalError,
+			Comment: comment,
+			Error:   err.Error(),
+		})
+	}
+}
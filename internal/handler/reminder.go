@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"taskmanager/internal/model"
+)
+
+type remindersURI struct {
+	TaskID int `uri:"taskId" binding:"required" example:"24"`
+}
+
+type reminderURI struct {
+	TaskID     int `uri:"taskId" binding:"required" example:"24"`
+	ReminderID int `uri:"reminderId" binding:"required" example:"3"`
+}
+
+// checkReminder one of remindAt and beforeMinutes, the email only of the email channel.
+func checkReminder(b CreateReminderBody) *HTTPError {
+	if (b.RemindAt == nil) == (b.BeforeMinutes == nil) {
+		return &HTTPError{
+			Type:    typeParameterRequired,
+			Comment: "remindAt or beforeMinutes",
+			fields: []FieldError{
+				{Field: "remindAt", Message: "required_without=beforeMinutes"},
+				{Field: "beforeMinutes", Message: "required_without=remindAt"},
+			},
+		}
+	}
+
+	if (b.Channel == model.ReminderChannelEmail) != (b.Email != "") {
+		return &HTTPError{
+			Type:    typeParameterRequired,
+			Comment: "email: required for the email channel only",
+			fields:  []FieldError{{Field: "email", Message: "required_if=channel email"}},
+		}
+	}
+
+	return nil
+}
+
+func reminderWriteError(c *gin.Context, taskID, reminderID int, comment string, err error) {
+	switch {
+	case errors.Is(err, model.ErrTaskNotFound):
+		writeError(c, http.StatusBadRequest, HTTPError{
+			Type:    typeTaskNotFound,
+			Comment: strconv.Itoa(taskID),
+			Error:   err.Error(),
+		})
+
+	case errors.Is(err, model.ErrReminderNotFound):
+		writeError(c, http.StatusBadRequest, HTTPError{
+			Type:    typeReminderNotFound,
+			Comment: strconv.Itoa(reminderID),
+			Error:   err.Error(),
+		})
+
+	default:
+		writeError(c, http.StatusInternalServerError, HTTPError{
+			Type:    typeInternalError,
+			Comment: comment,
+			Error:   err.Error(),
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"taskmanager/internal/model"
+)
+
+// The formats of the export, the import accepts the same.
+const (
+	taskFormatJSON     = "json"
+	taskFormatCSV      = "csv"
+	taskFormatMarkdown = "md"
+	taskFormatTodoTxt  = "todotxt"
+)
+
+// taskExportColumns the columns of csv and md, the keys of json are the same.
+var taskExportColumns = []string{
+	"id", "status", "title", "created", "updated", "completed", "deleted", "due", "recurrence",
+}
+
+// todoTxtDate the dates of todo.txt.
+const todoTxtDate = "2006-01-02"
+
+type taskFormat struct {
+	contentType string
+	newEncoder  func(w io.Writer) taskEncoder
+}
+
+var taskFormats = map[string]taskFormat{
+	taskFormatJSON: {
+		contentType: "application/json; charset=utf-8",
+		newEncoder:  func(w io.Writer) taskEncoder { return &jsonTaskEncoder{w: w} },
+	},
+	taskFormatCSV: {
+		contentType: "text/csv; charset=utf-8",
+		newEncoder:  func(w io.Writer) taskEncoder { return &csvTaskEncoder{w: csv.NewWriter(w)} },
+	},
+	taskFormatMarkdown: {
+		contentType: "text/markdown; charset=utf-8",
+		newEncoder:  func(w io.Writer) taskEncoder { return markdownTaskEncoder{w: w} },
+	},
+	taskFormatTodoTxt: {
+		contentType: "text/plain; charset=utf-8",
+		newEncoder:  func(w io.Writer) taskEncoder { return todoTxtTaskEncoder{w: w} },
+	},
+}
+
+// taskEncoder writes the tasks one by one, nothing is buffered except csv.Writer.
+type taskEncoder interface {
+	begin() error
+	encode(task model.ExportedTask) error
+	end() error
+}
+
+// exportedTask the json of the export: the times are UTC, completed, deleted and due are null if there are none.
+type exportedTask struct {
+	ID         int        `json:"id"`
+	Status     bool       `json:"status"`
+	Title      string     `json:"title"`
+	Created    time.Time  `json:"created"`
+	Updated    time.Time  `json:"updated"`
+	Completed  *time.Time `json:"completed"`
+	Deleted    *time.Time `json:"deleted"`
+	Due        *time.Time `json:"due"`
+	Recurrence string     `json:"recurrence"`
+}
+
+func newExportedTask(task model.ExportedTask) exportedTask {
+	optional := func(t time.Time) *time.Time {
+		if t.IsZero() {
+			return nil
+		}
+
+		t = t.UTC()
+
+		return &t
+	}
+
+	return exportedTask{
+		ID:         task.ID,
+		Status:     task.Status,
+		Title:      task.Title,
+		Created:    task.Created.UTC(),
+		Updated:    task.Updated.UTC(),
+		Completed:  optional(task.Completed),
+		Deleted:    optional(task.Deleted),
+		Due:        optional(exportedDue(task)),
+		Recurrence: task.Recurrence,
+	}
+}
+
+// exportedDue zero - the task has no due date.
+func exportedDue(task model.ExportedTask) time.Time {
+	if task.Due == nil {
+		return time.Time{}
+	}
+
+	return *task.Due
+}
+
+// exportRow the values of taskExportColumns, the times are RFC 3339 in UTC, empty if there are none.
+func exportRow(task model.ExportedTask) []string {
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+
+		return t.UTC().Format(time.RFC3339)
+	}
+
+	return []string{
+		strconv.Itoa(task.ID),
+		strconv.FormatBool(task.Status),
+		task.Title,
+		formatTime(task.Created),
+		formatTime(task.Updated),
+		formatTime(task.Completed),
+		formatTime(task.Deleted),
+		formatTime(exportedDue(task)),
+		task.Recurrence,
+	}
+}
+
+// jsonTaskEncoder an array, an element per line.
+type jsonTaskEncoder struct {
+	w     io.Writer
+	count int
+}
+
+func (e *jsonTaskEncoder) begin() error {
+	_, err := io.WriteString(e.w, "[")
+
+	return err
+}
+
+func (e *jsonTaskEncoder) encode(task model.ExportedTask) error {
+	b, err := json.Marshal(newExportedTask(task))
+	if err != nil {
+		return fmt.Errorf("marshal task: %w", err)
+	}
+
+	separator := "\n"
+	if e.count > 0 {
+		separator = ",\n"
+	}
+
+	e.count++
+
+	_, err = io.WriteString(e.w, separator+string(b))
+
+	return err
+}
+
+func (e *jsonTaskEncoder) end() error {
+	_, err := io.WriteString(e.w, "\n]\n")
+
+	return err
+}
+
+type csvTaskEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvTaskEncoder) begin() error {
+	return e.w.Write(taskExportColumns)
+}
+
+func (e *csvTaskEncoder) encode(task model.ExportedTask) error {
+	return e.w.Write(exportRow(task))
+}
+
+func (e *csvTaskEncoder) end() error {
+	e.w.Flush()
+
+	return e.w.Error()
+}
+
+// markdownTaskEncoder a table, | and the line breaks of the titles are escaped.
+type markdownTaskEncoder struct {
+	w io.Writer
+}
+
+var markdownCellReplacer = strings.NewReplacer(`\`, `\\`, "|", `\|`, "\r\n", " ", "\n", " ", "\r", " ")
+
+func (e markdownTaskEncoder) begin() error {
+	separators := make([]string, len(taskExportColumns))
+	for i := range separators {
+		separators[i] = "---"
+	}
+
+	_, err := io.WriteString(e.w, markdownRow(taskExportColumns)+markdownRow(separators))
+
+	return err
+}
+
+func (e markdownTaskEncoder) encode(task model.ExportedTask) error {
+	_, err := io.WriteString(e.w, markdownRow(exportRow(task)))
+
+	return err
+}
+
+func (e markdownTaskEncoder) end() error {
+	return nil
+}
+
+func markdownRow(cells []string) string {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		escaped[i] = markdownCellReplacer.Replace(cell)
+	}
+
+	return "| " + strings.Join(escaped, " | ") + " |\n"
+}
+
+// todoTxtTaskEncoder a task per line:
+//
+//	x 2023-05-02 2023-05-01 buy milk id:24 deleted:2023-05-03
+//
+// The completion and the creation dates, the title, the id and the deletion date as the key:value tags.
+type todoTxtTaskEncoder struct {
+	w io.Writer
+}
+
+var todoTxtTitleReplacer = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ")
+
+func (e todoTxtTaskEncoder) begin() error {
+	return nil
+}
+
+func (e todoTxtTaskEncoder) encode(task model.ExportedTask) error {
+	var line strings.Builder
+
+	if task.Status {
+		completed := task.Completed
+		if completed.IsZero() {
+			completed = task.Updated
+		}
+
+		line.WriteString("x " + completed.UTC().Format(todoTxtDate) + " ")
+	}
+
+	line.WriteString(task.Created.UTC().Format(todoTxtDate) + " ")
+	line.WriteString(todoTxtTitleReplacer.Replace(task.Title))
+	line.WriteString(" id:" + strconv.Itoa(task.ID))
+
+	if !task.Deleted.IsZero() {
+		line.WriteString(" deleted:" + task.Deleted.UTC().Format(todoTxtDate))
+	}
+
+	line.WriteString("\n")
+
+	_, err := io.WriteString(e.w, line.String())
+
+	return err
+}
+
+func (e todoTxtTaskEncoder) end() error {
+	return nil
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taskmanager/internal/model"
+)
+
+func TestTaskEncoders(t *testing.T) {
+	created := time.Date(2023, 5, 1, 10, 0, 0, 0, time.FixedZone("", 3*60*60))
+	due := created.Add(72 * time.Hour)
+
+	tasks := []model.ExportedTask{
+		{
+			Task: model.Task{
+				ID: 24, Title: "buy milk", Created: created, Updated: created, Due: &due, Recurrence: "FREQ=WEEKLY",
+			},
+		},
+		{
+			Task: model.Task{
+				ID:        25,
+				Status:    true,
+				Title:     "a | b\nc, \"d\"",
+				Created:   created,
+				Updated:   created.Add(time.Hour),
+				Completed: created.Add(24 * time.Hour),
+			},
+			Deleted: created.Add(48 * time.Hour),
+		},
+	}
+
+	cases := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: taskFormatJSON,
+			expected: `[
+{"id":24,"status":false,"title":"buy milk","created":"2023-05-01T07:00:00Z","updated":"2023-05-01T07:00:00Z",` +
+				`"completed":null,"deleted":null,"due":"2023-05-04T07:00:00Z","recurrence":"FREQ=WEEKLY"},
+{"id":25,"status":true,"title":"a | b\nc, \"d\"","created":"2023-05-01T07:00:00Z","updated":"2023-05-01T08:00:00Z",` +
+				`"completed":"2023-05-02T07:00:00Z","deleted":"2023-05-03T07:00:00Z","due":null,"recurrence":""}
+]
+`,
+		},
+		{
+			format: taskFormatCSV,
+			expected: `id,status,title,created,updated,completed,deleted,due,recurrence
+24,false,buy milk,2023-05-01T07:00:00Z,2023-05-01T07:00:00Z,,,2023-05-04T07:00:00Z,FREQ=WEEKLY
+25,true,"a | b
+c, ""d""",2023-05-01T07:00:00Z,2023-05-01T08:00:00Z,2023-05-02T07:00:00Z,2023-05-03T07:00:00Z,,
+`,
+		},
+		{
+			format: taskFormatMarkdown,
+			expected: `| id | status | title | created | updated | completed | deleted | due | recurrence |
+| --- | --- | --- | --- | --- | --- | --- | --- | --- |
+| 24 | false | buy milk | 2023-05-01T07:00:00Z | 2023-05-01T07:00:00Z |  |  | 2023-05-04T07:00:00Z | FREQ=WEEKLY |
+| 25 | true | a \| b c, "d" | 2023-05-01T07:00:00Z | 2023-05-01T08:00:00Z | 2023-05-02T07:00:00Z | 2023-05-03T07:00:00Z |  |  |
+`,
+		},
+		{
+			format: taskFormatTodoTxt,
+			expected: `2023-05-01 buy milk id:24
+x 2023-05-02 2023-05-01 a | b c, "d" id:25 deleted:2023-05-03
+`,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			encoder := taskFormats[tt.format].newEncoder(&buf)
+
+			require.NoError(t, encoder.begin())
+
+			for _, task := range tasks {
+				require.NoError(t, encoder.encode(task))
+			}
+
+			require.NoError(t, encoder.end())
+
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestTaskEncodersEmpty(t *testing.T) {
+	var buf bytes.Buffer
+
+	encoder := taskFormats[taskFormatJSON].newEncoder(&buf)
+
+	require.NoError(t, encoder.begin())
+	require.NoError(t, encoder.end())
+
+	assert.JSONEq(t, `[]`, buf.String())
+}
@@ -0,0 +1,451 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"taskmanager/internal/model"
+)
+
+// The columns of the import, the others are ignored. The names can be mapped to the columns of another tool.
+const (
+	importColumnTitle      = "title"
+	importColumnStatus     = "status"
+	importColumnDeleted    = "deleted"
+	importColumnDue        = "due"
+	importColumnRecurrence = "recurrence"
+)
+
+var importColumns = []string{
+	importColumnTitle, importColumnStatus, importColumnDeleted, importColumnDue, importColumnRecurrence,
+}
+
+// maxLengthImportLine the line of md and todo.txt.
+const maxLengthImportLine = 1 << 20
+
+var errImportInvalid = errors.New("invalid import")
+
+// importedTask a row of the import, err - the row is invalid.
+type importedTask struct {
+	// row the number of the task in the file from 1, without the header and the empty lines.
+	row     int
+	title   string
+	status  bool
+	deleted bool
+	// due zero - the task has no due date.
+	due        time.Time
+	recurrence string
+	err        *HTTPError
+}
+
+// decodeTasks calls fn for each task of the file. columns - the names of importColumns in the file,
+// the absent ones are the same. The error of fn stops the decoding and is returned.
+func decodeTasks(format string, r io.Reader, columns map[string]string, fn func(importedTask) error) error {
+	switch format {
+	case taskFormatJSON:
+		return decodeJSONTasks(r, columns, fn)
+
+	case taskFormatCSV:
+		return decodeCSVTasks(r, columns, fn)
+
+	case taskFormatMarkdown:
+		return decodeMarkdownTasks(r, columns, fn)
+
+	case taskFormatTodoTxt:
+		return decodeTodoTxtTasks(r, fn)
+	}
+
+	return fmt.Errorf("%w: format %s", errImportInvalid, format)
+}
+
+// update the fields of the created task, false - there are none.
+func (t importedTask) update() (model.TaskUpdate, bool) {
+	var update model.TaskUpdate
+
+	if t.status {
+		update.Status = &t.status
+	}
+
+	if !t.due.IsZero() {
+		update.Due = &t.due
+	}
+
+	if t.recurrence != "" {
+		update.Recurrence = &t.recurrence
+	}
+
+	return update, update.Status != nil || update.Due != nil || update.Recurrence != nil
+}
+
+func importColumnName(columns map[string]string, column string) string {
+	if name, ok := columns[column]; ok {
+		return name
+	}
+
+	return column
+}
+
+// newImportedTask the values by importColumns, the absent ones are empty. The title is trimmed, an empty status is
+// false, any deleted value - the task goes to the trash, due is RFC 3339, recurrence - like in PATCH.
+// The first invalid value is the error of the task.
+func newImportedTask(row int, values map[string]string) importedTask {
+	task := importedTask{
+		row:        row,
+		title:      strings.TrimSpace(values[importColumnTitle]),
+		deleted:    strings.TrimSpace(values[importColumnDeleted]) != "",
+		recurrence: strings.TrimSpace(values[importColumnRecurrence]),
+	}
+
+	switch {
+	case task.title == "":
+		task.err = &HTTPError{Type: typeParameterRequired, Comment: "title"}
+
+	case utf8.RuneCountInString(task.title) > maxLengthTaskTitle:
+		task.err = &HTTPError{Type: typeParameterTooLong, Comment: fmt.Sprintf("title: max %d", maxLengthTaskTitle)}
+	}
+
+	if status := strings.TrimSpace(values[importColumnStatus]); status != "" {
+		completed, err := strconv.ParseBool(status)
+		if err != nil && task.err == nil {
+			task.err = &HTTPError{Type: typeParameterRequired, Comment: "status: true or false"}
+		}
+
+		task.status = completed
+	}
+
+	if due := strings.TrimSpace(values[importColumnDue]); due != "" {
+		t, err := time.Parse(time.RFC3339, due)
+		if err != nil && task.err == nil {
+			task.err = &HTTPError{Type: typeParameterRequired, Comment: "due: RFC 3339"}
+		}
+
+		task.due = t.UTC()
+	}
+
+	if err := checkRecurrence(task.recurrence); err != nil && task.err == nil {
+		task.err = &HTTPError{Type: typeParameterRequired, Comment: "recurrence", Error: err.Error()}
+	}
+
+	return task
+}
+
+// importTable the columns of csv and md by the header.
+type importTable struct {
+	indexes map[string]int
+}
+
+func newImportTable(header []string, columns map[string]string) (importTable, error) {
+	t := importTable{
+		indexes: make(map[string]int),
+	}
+
+	for _, column := range importColumns {
+		name := importColumnName(columns, column)
+
+		for i, cell := range header {
+			if strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(cell, "\ufeff")), name) {
+				t.indexes[column] = i
+
+				break
+			}
+		}
+	}
+
+	if _, ok := t.indexes[importColumnTitle]; !ok {
+		return importTable{}, fmt.Errorf("%w: column %s", errImportInvalid, importColumnName(columns, importColumnTitle))
+	}
+
+	return t, nil
+}
+
+func (t importTable) task(row int, record []string) importedTask {
+	values := make(map[string]string, len(t.indexes))
+
+	for column, i := range t.indexes {
+		if i < len(record) {
+			values[column] = record[i]
+		}
+	}
+
+	return newImportedTask(row, values)
+}
+
+func decodeCSVTasks(r io.Reader, columns map[string]string, fn func(importedTask) error) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%w: csv: header required", errImportInvalid)
+		}
+
+		return fmt.Errorf("%w: csv: %w", errImportInvalid, err)
+	}
+
+	table, err := newImportTable(header, columns)
+	if err != nil {
+		return err
+	}
+
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("%w: csv: %w", errImportInvalid, err)
+		}
+
+		if err := fn(table.task(row, record)); err != nil {
+			return err
+		}
+	}
+}
+
+// decodeMarkdownTasks the table of the export: the header, the separator, | and \ of the cells are escaped.
+func decodeMarkdownTasks(r io.Reader, columns map[string]string, fn func(importedTask) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLengthImportLine)
+
+	var (
+		table     importTable
+		line, row int
+	)
+
+	for scanner.Scan() {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		line++
+
+		cells := markdownCells(text)
+
+		switch line {
+		case 1:
+			t, err := newImportTable(cells, columns)
+			if err != nil {
+				return err
+			}
+
+			table = t
+
+		case 2:
+			for _, cell := range cells {
+				if strings.Trim(cell, "-: ") != "" {
+					return fmt.Errorf("%w: md: the separator of the header required", errImportInvalid)
+				}
+			}
+
+		default:
+			row++
+
+			if err := fn(table.task(row, cells)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%w: md: %w", errImportInvalid, err)
+	}
+
+	if line == 0 {
+		return fmt.Errorf("%w: md: header required", errImportInvalid)
+	}
+
+	return nil
+}
+
+// markdownCells the cells of the row of a table, the outer | are optional.
+func markdownCells(line string) []string {
+	line = strings.TrimPrefix(line, "|")
+
+	var (
+		cells []string
+		cell  strings.Builder
+	)
+
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && (line[i+1] == '|' || line[i+1] == '\\'):
+			i++
+			cell.WriteByte(line[i])
+
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+
+	if last := strings.TrimSpace(cell.String()); last != "" {
+		cells = append(cells, last)
+	}
+
+	return cells
+}
+
+// decodeJSONTasks an array of objects, the keys are the columns.
+// The title is a string, the status - a boolean or a string, deleted - any value except null and "",
+// due and recurrence - strings or null.
+func decodeJSONTasks(r io.Reader, columns map[string]string, fn func(importedTask) error) error {
+	decoder := json.NewDecoder(r)
+
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return fmt.Errorf("%w: json: array required", errImportInvalid)
+	}
+
+	for row := 1; decoder.More(); row++ {
+		var object map[string]json.RawMessage
+		if err := decoder.Decode(&object); err != nil {
+			return fmt.Errorf("%w: json: %w", errImportInvalid, err)
+		}
+
+		value := func(column string) json.RawMessage {
+			name := importColumnName(columns, column)
+
+			for key, v := range object {
+				if strings.EqualFold(key, name) {
+					return v
+				}
+			}
+
+			return nil
+		}
+
+		values := make(map[string]string)
+
+		// Not a string - the title is empty.
+		var title string
+		_ = json.Unmarshal(value(importColumnTitle), &title)
+		values[importColumnTitle] = title
+
+		if raw := value(importColumnStatus); raw != nil && string(raw) != "null" {
+			var (
+				completed bool
+				status    string
+			)
+
+			if json.Unmarshal(raw, &completed) == nil {
+				status = strconv.FormatBool(completed)
+			} else if json.Unmarshal(raw, &status) != nil {
+				status = string(raw)
+			}
+
+			values[importColumnStatus] = status
+		}
+
+		if raw := value(importColumnDeleted); raw != nil && string(raw) != "null" && string(raw) != `""` {
+			values[importColumnDeleted] = string(raw)
+		}
+
+		// Not a string - the value is invalid.
+		for _, column := range []string{importColumnDue, importColumnRecurrence} {
+			if raw := value(column); raw != nil && string(raw) != "null" {
+				var s string
+				if json.Unmarshal(raw, &s) != nil {
+					s = string(raw)
+				}
+
+				values[column] = s
+			}
+		}
+
+		if err := fn(newImportedTask(row, values)); err != nil {
+			return err
+		}
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return fmt.Errorf("%w: json: %w", errImportInvalid, err)
+	}
+
+	return nil
+}
+
+// decodeTodoTxtTasks "x 2023-05-02 2023-05-01 buy milk id:24 deleted:2023-05-03": the completion mark, the priority
+// and the dates are not in the title, the tags id and deleted of the export too, the other tags are kept.
+func decodeTodoTxtTasks(r io.Reader, fn func(importedTask) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLengthImportLine)
+
+	row := 0
+
+	for scanner.Scan() {
+		words := strings.Fields(scanner.Text())
+		if len(words) == 0 {
+			continue
+		}
+
+		row++
+
+		status := ""
+
+		if words[0] == "x" {
+			status = "true"
+			words = words[1:]
+		} else if isTodoTxtPriority(words[0]) {
+			words = words[1:]
+		}
+
+		// The completion and the creation dates.
+		for i := 0; i < 2 && len(words) > 0 && isTodoTxtDate(words[0]); i++ {
+			words = words[1:]
+		}
+
+		var (
+			title   []string
+			deleted string
+		)
+
+		for _, word := range words {
+			switch {
+			case strings.HasPrefix(word, "id:"):
+
+			case strings.HasPrefix(word, "deleted:"):
+				deleted = strings.TrimPrefix(word, "deleted:")
+
+			default:
+				title = append(title, word)
+			}
+		}
+
+		if err := fn(newImportedTask(row, map[string]string{
+			importColumnTitle:   strings.Join(title, " "),
+			importColumnStatus:  status,
+			importColumnDeleted: deleted,
+		})); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%w: todotxt: %w", errImportInvalid, err)
+	}
+
+	return nil
+}
+
+func isTodoTxtPriority(word string) bool {
+	return len(word) == 3 && word[0] == '(' && word[1] >= 'A' && word[1] <= 'Z' && word[2] == ')'
+}
+
+func isTodoTxtDate(word string) bool {
+	_, err := time.Parse(todoTxtDate, word)
+
+	return err == nil
+}
@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taskmanager/internal/model"
+)
+
+func decodeAllTasks(t *testing.T, format, data string, columns map[string]string) ([]importedTask, error) {
+	t.Helper()
+
+	var tasks []importedTask
+
+	err := decodeTasks(format, strings.NewReader(data), columns, func(task importedTask) error {
+		tasks = append(tasks, task)
+
+		return nil
+	})
+
+	return tasks, err
+}
+
+func TestDecodeTasksOfExport(t *testing.T) {
+	created := time.Date(2023, 5, 1, 10, 0, 0, 0, time.UTC)
+	due := created.Add(72 * time.Hour)
+
+	exported := []model.ExportedTask{
+		{
+			Task: model.Task{
+				ID: 24, Title: "buy milk", Created: created, Updated: created, Due: &due, Recurrence: "FREQ=WEEKLY",
+			},
+		},
+		{
+			Task: model.Task{
+				ID: 25, Status: true, Title: `a | b \ c, "d"`, Created: created, Updated: created, Completed: created,
+			},
+			Deleted: created,
+		},
+	}
+
+	for format := range taskFormats {
+		expected := []importedTask{
+			{row: 1, title: "buy milk", due: due, recurrence: "FREQ=WEEKLY"},
+			{row: 2, title: `a | b \ c, "d"`, status: true, deleted: true},
+		}
+
+		// todo.txt has no due date and recurrence.
+		if format == taskFormatTodoTxt {
+			expected[0].due, expected[0].recurrence = time.Time{}, ""
+		}
+
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			encoder := taskFormats[format].newEncoder(&buf)
+
+			require.NoError(t, encoder.begin())
+
+			for _, task := range exported {
+				require.NoError(t, encoder.encode(task))
+			}
+
+			require.NoError(t, encoder.end())
+
+			tasks, err := decodeAllTasks(t, format, buf.String(), nil)
+			require.NoError(t, err)
+			assert.Equal(t, expected, tasks)
+		})
+	}
+}
+
+func TestDecodeTasks(t *testing.T) {
+	cases := []struct {
+		name     string
+		format   string
+		data     string
+		columns  map[string]string
+		expected []importedTask
+		err      error
+	}{
+		{
+			name:    "csv columns",
+			format:  taskFormatCSV,
+			data:    "\ufeffName,Done,Notes\n task1 ,yes,\ntask2\n\n,false,empty\n",
+			columns: map[string]string{"title": "name", "status": "Done"},
+			expected: []importedTask{
+				{row: 1, title: "task1", err: &HTTPError{Type: typeParameterRequired, Comment: "status: true or false"}},
+				{row: 2, title: "task2"},
+				{row: 3, err: &HTTPError{Type: typeParameterRequired, Comment: "title"}},
+			},
+		},
+		{
+			name:   "csv without title",
+			format: taskFormatCSV,
+			data:   "name\ntask1\n",
+			err:    errImportInvalid,
+		},
+		{
+			name:   "csv broken",
+			format: taskFormatCSV,
+			data:   "title\n\"task1\n",
+			err:    errImportInvalid,
+		},
+		{
+			name:   "json",
+			format: taskFormatJSON,
+			data: `[{"title": "task1", "status": "true", "deleted": "2023-05-03"}, {"Title": "task2", "deleted": ""},
+				{"title": 1}, {"title": "` + strings.Repeat("a", maxLengthTaskTitle+1) + `"},
+				{"title": "task5", "due": "2023-05-02T09:30:00+03:00", "recurrence": "FREQ=DAILY"},
+				{"title": "task6", "due": "tomorrow", "recurrence": null}, {"title": "task7", "recurrence": "FREQ=SECONDLY"}]`,
+			expected: []importedTask{
+				{row: 1, title: "task1", status: true, deleted: true},
+				{row: 2, title: "task2"},
+				{row: 3, err: &HTTPError{Type: typeParameterRequired, Comment: "title"}},
+				{
+					row:   4,
+					title: strings.Repeat("a", maxLengthTaskTitle+1),
+					err:   &HTTPError{Type: typeParameterTooLong, Comment: "title: max 200"},
+				},
+				{
+					row:        5,
+					title:      "task5",
+					due:        time.Date(2023, 5, 2, 6, 30, 0, 0, time.UTC),
+					recurrence: "FREQ=DAILY",
+				},
+				{row: 6, title: "task6", err: &HTTPError{Type: typeParameterRequired, Comment: "due: RFC 3339"}},
+				{
+					row:        7,
+					title:      "task7",
+					recurrence: "FREQ=SECONDLY",
+					err: &HTTPError{
+						Type:    typeParameterRequired,
+						Comment: "recurrence",
+						Error:   "invalid recurrence: FREQ: DAILY, WEEKLY, MONTHLY or YEARLY",
+					},
+				},
+			},
+		},
+		{
+			name:   "json object",
+			format: taskFormatJSON,
+			data:   `{"title": "task1"}`,
+			err:    errImportInvalid,
+		},
+		{
+			name:   "json broken",
+			format: taskFormatJSON,
+			data:   `[{"title": "task1"}`,
+			err:    errImportInvalid,
+		},
+		{
+			name:   "md",
+			format: taskFormatMarkdown,
+			data:   "title | status\n:--- | ---\ntask1 | true\n\n| task2 |\n",
+			expected: []importedTask{
+				{row: 1, title: "task1", status: true},
+				{row: 2, title: "task2"},
+			},
+		},
+		{
+			name:   "md without separator",
+			format: taskFormatMarkdown,
+			data:   "| title |\n| task1 |\n",
+			err:    errImportInvalid,
+		},
+		{
+			name:   "todotxt",
+			format: taskFormatTodoTxt,
+			data:   "(A) 2023-05-01 call mom +family @phone\n\nx 2023-05-02 buy milk id:3\nx\n",
+			expected: []importedTask{
+				{row: 1, title: "call mom +family @phone"},
+				{row: 2, title: "buy milk", status: true},
+				{row: 3, status: true, err: &HTTPError{Type: typeParameterRequired, Comment: "title"}},
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, err := decodeAllTasks(t, tt.format, tt.data, tt.columns)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, tasks)
+		})
+	}
+}
+
+func TestDecodeTasksStop(t *testing.T) {
+	errStop := errors.New("stop")
+
+	err := decodeTasks(taskFormatTodoTxt, strings.NewReader("task1\ntask2\n"), nil, func(importedTask) error {
+		return errStop
+	})
+	assert.ErrorIs(t, err, errStop)
+}
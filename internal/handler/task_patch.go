@@ -0,0 +1,268 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"taskmanager/internal/model"
+)
+
+const (
+	mimeJSON       = "application/json"
+	mimeMergePatch = "application/merge-patch+json"
+	mimeJSONPatch  = "application/json-patch+json"
+)
+
+const (
+	patchFieldTitle      = "title"
+	patchFieldCompleted  = "completed"
+	patchFieldDue        = "due"
+	patchFieldRecurrence = "recurrence"
+)
+
+const (
+	patchOpAdd     = "add"
+	patchOpRemove  = "remove"
+	patchOpReplace = "replace"
+	patchOpMove    = "move"
+	patchOpCopy    = "copy"
+	patchOpTest    = "test"
+)
+
+var (
+	errPatchInvalid    = errors.New("invalid patch")
+	errPatchTestFailed = errors.New("patch test failed")
+)
+
+// taskPatch nil - the field is not changed.
+type taskPatch struct {
+	Title     *string
+	Completed *bool
+	// Due zero - removed.
+	Due *time.Time
+	// Recurrence empty - removed.
+	Recurrence *string
+}
+
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// parseMergePatch RFC 7396. Only due and recurrence can be removed(null), the other task fields are required.
+func parseMergePatch(body []byte) (taskPatch, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return taskPatch{}, fmt.Errorf("%w: %s", errPatchInvalid, err.Error())
+	}
+
+	var p taskPatch
+
+	for field, value := range fields {
+		if err := p.set("/"+field, value); err != nil {
+			return taskPatch{}, err
+		}
+	}
+
+	return p, nil
+}
+
+// parseJSONPatch RFC 6902.
+func parseJSONPatch(body []byte) ([]jsonPatchOperation, error) {
+	var operations []jsonPatchOperation
+	if err := json.Unmarshal(body, &operations); err != nil {
+		return nil, fmt.Errorf("%w: %s", errPatchInvalid, err.Error())
+	}
+
+	for _, op := range operations {
+		switch op.Op {
+		case patchOpAdd, patchOpReplace, patchOpTest:
+			if len(op.Value) == 0 {
+				return nil, fmt.Errorf("%w: %s %s: value required", errPatchInvalid, op.Op, op.Path)
+			}
+
+		case patchOpRemove:
+			if !patchRemovable(op.Path) {
+				return nil, fmt.Errorf("%w: %s %s: task fields can not be removed", errPatchInvalid, op.Op, op.Path)
+			}
+
+		case patchOpMove, patchOpCopy:
+			return nil, fmt.Errorf("%w: %s %s: fields have different types", errPatchInvalid, op.Op, op.Path)
+
+		default:
+			return nil, fmt.Errorf("%w: unknown operation %q", errPatchInvalid, op.Op)
+		}
+	}
+
+	return operations, nil
+}
+
+func jsonPatchHasTest(operations []jsonPatchOperation) bool {
+	for _, op := range operations {
+		if op.Op == patchOpTest {
+			return true
+		}
+	}
+
+	return false
+}
+
+// applyJSONPatch operations are applied in order, "test" compares with the task patched by previous operations.
+func applyJSONPatch(operations []jsonPatchOperation, task model.Task) (taskPatch, error) {
+	var p taskPatch
+
+	for _, op := range operations {
+		if op.Op == patchOpTest {
+			if err := p.test(op.Path, op.Value, task); err != nil {
+				return taskPatch{}, err
+			}
+
+			continue
+		}
+
+		value := op.Value
+		if op.Op == patchOpRemove {
+			value = json.RawMessage("null")
+		}
+
+		if err := p.set(op.Path, value); err != nil {
+			return taskPatch{}, err
+		}
+	}
+
+	return p, nil
+}
+
+func patchRemovable(path string) bool {
+	return path == "/"+patchFieldDue || path == "/"+patchFieldRecurrence
+}
+
+func (p *taskPatch) set(path string, value json.RawMessage) error {
+	if bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+		return p.remove(path)
+	}
+
+	switch path {
+	case "/" + patchFieldTitle:
+		var title string
+		if err := json.Unmarshal(value, &title); err != nil {
+			return fmt.Errorf("%w: %s: %s", errPatchInvalid, path, err.Error())
+		}
+
+		if title == "" || utf8.RuneCountInString(title) > maxLengthTaskTitle {
+			return fmt.Errorf("%w: %s: length 1-%d", errPatchInvalid, path, maxLengthTaskTitle)
+		}
+
+		p.Title = &title
+
+	case "/" + patchFieldCompleted:
+		var completed bool
+		if err := json.Unmarshal(value, &completed); err != nil {
+			return fmt.Errorf("%w: %s: %s", errPatchInvalid, path, err.Error())
+		}
+
+		p.Completed = &completed
+
+	case "/" + patchFieldDue:
+		var due time.Time
+		if err := json.Unmarshal(value, &due); err != nil {
+			return fmt.Errorf("%w: %s: RFC 3339 time", errPatchInvalid, path)
+		}
+
+		p.Due = &due
+
+	case "/" + patchFieldRecurrence:
+		var recurrence string
+		if err := json.Unmarshal(value, &recurrence); err != nil {
+			return fmt.Errorf("%w: %s: %s", errPatchInvalid, path, err.Error())
+		}
+
+		if err := checkRecurrence(recurrence); err != nil {
+			return fmt.Errorf("%w: %s: %s", errPatchInvalid, path, err.Error())
+		}
+
+		p.Recurrence = &recurrence
+
+	default:
+		return fmt.Errorf("%w: unknown path %q", errPatchInvalid, path)
+	}
+
+	return nil
+}
+
+func (p *taskPatch) remove(path string) error {
+	switch path {
+	case "/" + patchFieldDue:
+		p.Due = &time.Time{}
+
+	case "/" + patchFieldRecurrence:
+		p.Recurrence = new(string)
+
+	default:
+		return fmt.Errorf("%w: %s: task fields can not be removed", errPatchInvalid, path)
+	}
+
+	return nil
+}
+
+func (p *taskPatch) test(path string, value json.RawMessage, task model.Task) error {
+	var want taskPatch
+	if err := want.set(path, value); err != nil {
+		return err
+	}
+
+	switch {
+	// Current - the task after the previous operations.
+	case want.Title != nil:
+		current := task.Title
+		if p.Title != nil {
+			current = *p.Title
+		}
+
+		if *want.Title != current {
+			return fmt.Errorf("%w: %s: want %q, current %q", errPatchTestFailed, path, *want.Title, current)
+		}
+
+	case want.Completed != nil:
+		current := task.Status
+		if p.Completed != nil {
+			current = *p.Completed
+		}
+
+		if *want.Completed != current {
+			return fmt.Errorf("%w: %s: want %t, current %t", errPatchTestFailed, path, *want.Completed, current)
+		}
+
+	case want.Due != nil:
+		current := time.Time{}
+		if task.Due != nil {
+			current = *task.Due
+		}
+
+		if p.Due != nil {
+			current = *p.Due
+		}
+
+		if !want.Due.Equal(current) {
+			return fmt.Errorf("%w: %s: want %s, current %s", errPatchTestFailed, path, want.Due, current)
+		}
+
+	case want.Recurrence != nil:
+		current := task.Recurrence
+		if p.Recurrence != nil {
+			current = *p.Recurrence
+		}
+
+		if *want.Recurrence != current {
+			return fmt.Errorf("%w: %s: want %q, current %q", errPatchTestFailed, path, *want.Recurrence, current)
+		}
+	}
+
+	return nil
+}
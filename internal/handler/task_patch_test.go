@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taskmanager/internal/model"
+)
+
+func TestParseMergePatch(t *testing.T) {
+	var (
+		title      = "task1"
+		completed  = true
+		due        = time.Date(2023, 5, 2, 9, 30, 0, 0, time.UTC)
+		recurrence = "FREQ=WEEKLY;BYDAY=TU"
+	)
+
+	cases := []struct {
+		name     string
+		body     string
+		expected taskPatch
+		err      error
+	}{
+		{
+			name:     "title",
+			body:     `{"title": "task1"}`,
+			expected: taskPatch{Title: &title},
+		},
+		{
+			name:     "completed",
+			body:     `{"completed": true}`,
+			expected: taskPatch{Completed: &completed},
+		},
+		{
+			name:     "due and recurrence",
+			body:     `{"due": "2023-05-02T09:30:00Z", "recurrence": "FREQ=WEEKLY;BYDAY=TU"}`,
+			expected: taskPatch{Due: &due, Recurrence: &recurrence},
+		},
+		{
+			name:     "remove due and recurrence",
+			body:     `{"due": null, "recurrence": null}`,
+			expected: taskPatch{Due: &time.Time{}, Recurrence: new(string)},
+		},
+		{
+			name: "invalid due",
+			body: `{"due": "2023-05-02"}`,
+			err:  errPatchInvalid,
+		},
+		{
+			name: "invalid recurrence",
+			body: `{"recurrence": "FREQ=HOURLY"}`,
+			err:  errPatchInvalid,
+		},
+		{
+			name:     "empty",
+			body:     `{}`,
+			expected: taskPatch{},
+		},
+		{
+			name: "remove title",
+			body: `{"title": null}`,
+			err:  errPatchInvalid,
+		},
+		{
+			name: "empty title",
+			body: `{"title": ""}`,
+			err:  errPatchInvalid,
+		},
+		{
+			name: "unknown field",
+			body: `{"status": true}`,
+			err:  errPatchInvalid,
+		},
+		{
+			name: "not object",
+			body: `[]`,
+			err:  errPatchInvalid,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := parseMergePatch([]byte(tt.body))
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, patch)
+		})
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	var (
+		title     = "task1_new"
+		completed = true
+	)
+
+	due := time.Date(2023, 5, 2, 9, 30, 0, 0, time.UTC)
+
+	task := model.Task{
+		Title:  "task1",
+		Status: false,
+		Due:    &due,
+	}
+
+	cases := []struct {
+		name     string
+		body     string
+		expected taskPatch
+		err      error
+	}{
+		{
+			name:     "replace title",
+			body:     `[{"op": "replace", "path": "/title", "value": "task1_new"}]`,
+			expected: taskPatch{Title: &title},
+		},
+		{
+			name: "test and replace",
+			body: `[
+				{"op": "test", "path": "/completed", "value": false},
+				{"op": "add", "path": "/completed", "value": true}
+			]`,
+			expected: taskPatch{Completed: &completed},
+		},
+		{
+			name: "test after replace",
+			body: `[
+				{"op": "replace", "path": "/title", "value": "task1_new"},
+				{"op": "test", "path": "/title", "value": "task1_new"}
+			]`,
+			expected: taskPatch{Title: &title},
+		},
+		{
+			name: "test failed",
+			body: `[{"op": "test", "path": "/title", "value": "task2"}]`,
+			err:  errPatchTestFailed,
+		},
+		{
+			name: "remove",
+			body: `[{"op": "remove", "path": "/title"}]`,
+			err:  errPatchInvalid,
+		},
+		{
+			name: "test and remove due",
+			body: `[
+				{"op": "test", "path": "/due", "value": "2023-05-02T12:30:00+03:00"},
+				{"op": "remove", "path": "/due"},
+				{"op": "test", "path": "/due", "value": null}
+			]`,
+			expected: taskPatch{Due: &time.Time{}},
+		},
+		{
+			name: "move",
+			body: `[{"op": "move", "from": "/title", "path": "/completed"}]`,
+			err:  errPatchInvalid,
+		},
+		{
+			name: "wrong type",
+			body: `[{"op": "replace", "path": "/completed", "value": "yes"}]`,
+			err:  errPatchInvalid,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			operations, err := parseJSONPatch([]byte(tt.body))
+			if err == nil {
+				var patch taskPatch
+
+				patch, err = applyJSONPatch(operations, task)
+				if err == nil {
+					assert.Equal(t, tt.expected, patch)
+				}
+			}
+
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"taskmanager/internal/model"
+)
+
+type getCalendarURI struct {
+	File string `uri:"file" binding:"required" example:"9f86d081884c7d659a2feaa0c55ad015.ics"`
+}
+
+type GetCalendarQuery struct {
+	Component string `form:"component" binding:"omitempty,oneof=vtodo vevent" example:"vevent"`
+}
+
+// V1GetCalendar the iCalendar(RFC 5545) feed of the tasks with a due date, without the trash. The URL is the secret,
+// there is no basic auth for the calendar apps; an unknown token - 404.
+// vtodo(default) - the tasks with the status, vevent - the events at the due time for the apps without tasks.
+// The recurring tasks have RRULE starting from the due time.
+//
+// @Summary get calendar
+// @Tags calendar
+// @Produce text/calendar
+// @Param file path string true "<token>.ics"
+// @Param component query string false "vtodo(default), vevent"
+// @Success 200 {string} string "text/calendar"
+// @Failure 400 {object} HTTPError "error type, comment"
+// @Failure 404 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/calendar/{file} [get]
+func V1GetCalendar(ctx context.Context, storage CalendarStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var u getCalendarURI
+		if err := c.ShouldBindUri(&u); err != nil || !strings.HasSuffix(u.File, calendarExtension) {
+			abortWithStatus(c, http.StatusNotFound)
+
+			return
+		}
+
+		var q GetCalendarQuery
+		if err := c.ShouldBindQuery(&q); err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParameterRequired,
+				Comment: "component: vtodo, vevent",
+				Error:   err.Error(),
+				fields:  fieldErrors(err),
+			})
+
+			return
+		}
+
+		if q.Component == "" {
+			q.Component = calendarComponentVTodo
+		}
+
+		token := strings.TrimSuffix(u.File, calendarExtension)
+
+		tasks, err := storage.GetCalendarTasks(ctx, calendarTokenHash(token))
+		if err != nil {
+			if errors.Is(err, model.ErrCalendarTokenNotFound) {
+				abortWithStatus(c, http.StatusNotFound)
+
+				return
+			}
+
+			writeError(c, http.StatusInternalServerError, HTTPError{
+				Type:    typeInternalError,
+				Comment: "get calendar",
+				Error:   err.Error(),
+			})
+
+			return
+		}
+
+		var buf bytes.Buffer
+
+		if err := writeCalendar(&buf, tasks, q.Component, time.Now()); err != nil {
+			writeError(c, http.StatusInternalServerError, HTTPError{
+				Type:    typeInternalError,
+				Comment: "get calendar",
+				Error:   err.Error(),
+			})
+
+			return
+		}
+
+		c.Header("Cache-Control", "private, no-cache")
+		c.Data(http.StatusOK, mimeCalendar+"; charset=utf-8", buf.Bytes())
+	}
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateCalendarTokenResult struct {
+	// Token the secret of the feed, it is returned only here.
+	Token string `json:"token" example:"9f86d081884c7d659a2feaa0c55ad015"`
+	// Path of the feed on this server.
+	Path string `json:"path" example:"/api/v1/calendar/9f86d081884c7d659a2feaa0c55ad015.ics"`
+}
+
+// V1CreateCalendarToken a new secret URL of the iCalendar feed of the tasks with a due date,
+// the feed of the previous token stops working.
+//
+// @Summary create calendar token
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Success 201 {object} CreateCalendarTokenResult "token, path of the feed"
+// @Failure 401 {object} nil
+// @Failure 403 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/calendar/token [post]
+func V1CreateCalendarToken(ctx context.Context, storage CalendarStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := authorizedUserID(ctx, c, storage)
+		if !ok {
+			return
+		}
+
+		token, tokenHash, err := newCalendarToken()
+		if err != nil {
+			writeError(c, http.StatusInternalServerError, HTTPError{
+				Type:    typeInternalError,
+				Comment: "create calendar token",
+				Error:   err.Error(),
+			})
+
+			return
+		}
+
+		if err := storage.SetCalendarToken(ctx, userID, tokenHash); err != nil {
+			writeError(c, http.StatusInternalServerError, HTTPError{
+				Type:    typeInternalError,
+				Comment: "create calendar token",
+				Error:   err.Error(),
+			})
+
+			return
+		}
+
+		// The same version of the API as the request.
+		calendarPath := strings.TrimSuffix(c.Request.URL.Path, "/token")
+
+		c.JSON(http.StatusCreated, CreateCalendarTokenResult{
+			Token: token,
+			Path:  calendarPath + "/" + token + calendarExtension,
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"taskmanager/internal/model"
+)
+
+// V1DeleteCalendarToken the feed stops working until a new token is created.
+//
+// @Summary delete calendar token
+// @Tags calendar
+// @Accept json
+// @Produce json
+// @Success 204 {object} nil
+// @Failure 400 {object} HTTPError "error type, comment"
+// @Failure 401 {object} nil
+// @Failure 403 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/calendar/token [delete]
+func V1DeleteCalendarToken(ctx context.Context, storage CalendarStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := authorizedUserID(ctx, c, storage)
+		if !ok {
+			return
+		}
+
+		if err := storage.DeleteCalendarToken(ctx, userID); err != nil {
+			if errors.Is(err, model.ErrCalendarTokenNotFound) {
+				writeError(c, http.StatusBadRequest, HTTPError{
+					Type:    typeCalendarTokenNotFound,
+					Comment: "create the token first",
+					Error:   err.Error(),
+				})
+
+				return
+			}
+
+			writeError(c, http.StatusInternalServerError, HTTPError{
+				Type:    typeInternalError,
+				Comment: "delete calendar token",
+				Error:   err.Error(),
+			})
+
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
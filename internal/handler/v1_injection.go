@@ -27,13 +27,13 @@ type createTaskInjectionResult struct {
 // @Tags task
 // @Accept json
 // @Produce json
-// @Param data body createTaskBody true "title - max 200"
-// @Success 201 {object} createTaskResult "taskId"
+// @Param data body CreateTaskBody true "title - max 200"
+// @Success 201 {object} CreateTaskResult "taskId"
 // @Failure 400 {object} HTTPError "error type, comment"
 // @Failure 401 {object} nil
 // @Failure 500 {object} HTTPError "error type, comment"
 // @Router /v1/task [post]
-func V1CreateTaskWithInjection(ctx context.Context, postgres PostgresDB) gin.HandlerFunc {
+func V1CreateTaskWithInjection(ctx context.Context, storage TaskStorage) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		username, password, ok := c.Request.BasicAuth()
 		if !ok {
@@ -44,17 +44,18 @@ func V1CreateTaskWithInjection(ctx context.Context, postgres PostgresDB) gin.Han
 
 		var b createTaskInjectionBody
 		if err := c.ShouldBindJSON(&b); err != nil {
-			c.JSON(http.StatusBadRequest, HTTPError{
+			writeError(c, http.StatusBadRequest, HTTPError{
 				Type:    typeParameterRequired,
 				Comment: "title",
 				Error:   err.Error(),
+				fields:  fieldErrors(err),
 			})
 
 			return
 		}
 
 		if utf8.RuneCountInString(b.Title) > maxLengthTaskTitle {
-			c.JSON(http.StatusBadRequest, HTTPError{
+			writeError(c, http.StatusBadRequest, HTTPError{
 				Type:    typeParameterTooLong,
 				Comment: fmt.Sprintf("max %d", maxLengthTaskTitle),
 			})
@@ -62,16 +63,16 @@ func V1CreateTaskWithInjection(ctx context.Context, postgres PostgresDB) gin.Han
 			return
 		}
 
-		taskID, err := postgres.CreateTaskWithInjection(ctx, username, security.SaltPassword(password), b.Title)
+		taskID, err := storage.CreateTaskWithInjection(ctx, username, security.SaltPassword(password), b.Title)
 		if err != nil {
 			if errors.Is(err, model.ErrUserNotFound) {
-				c.AbortWithStatus(http.StatusForbidden)
+				abortWithStatus(c, http.StatusForbidden)
 
 				return
 			}
 
 			if errors.Is(err, model.ErrTaskAlreadyExists) {
-				c.JSON(http.StatusBadRequest, HTTPError{
+				writeError(c, http.StatusBadRequest, HTTPError{
 					Type:    typeTaskAlreadyExists,
 					Comment: "duplicate task",
 					Error:   err.Error(),
@@ -80,7 +81,7 @@ func V1CreateTaskWithInjection(ctx context.Context, postgres PostgresDB) gin.Han
 				return
 			}
 
-			c.JSON(http.StatusInternalServerError, HTTPError{
+			writeError(c, http.StatusInternalServerError, HTTPError{
 				Type:    typeInternalError,
 				Comment: "create task",
 				Error:   err.Error(),
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GetMeResult struct {
+	UserID   int    `json:"userId" example:"24"`
+	Username string `json:"username" example:"newuser"`
+}
+
+// V1GetMe the user of the credentials, 403 for unknown ones: the routes of the tasks return empty lists for them.
+//
+// @Summary get the user of the credentials
+// @Tags user
+// @Accept json
+// @Produce json
+// @Success 200 {object} GetMeResult
+// @Failure 401 {object} nil
+// @Failure 403 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/me [get]
+func V1GetMe(ctx context.Context, storage CredentialsStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := authorizedUserID(ctx, c, storage)
+		if !ok {
+			return
+		}
+
+		username, _, _ := c.Request.BasicAuth()
+
+		c.JSON(http.StatusOK, GetMeResult{
+			UserID:   userID,
+			Username: username,
+		})
+	}
+}
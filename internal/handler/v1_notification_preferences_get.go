@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// V1GetNotificationPreferences all types of the notifications, enabled until the user disables them.
+//
+// @Summary get notification preferences
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Success 200 {object} []model.NotificationPreference
+// @Failure 401 {object} nil
+// @Failure 403 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/notifications/preferences [get]
+func V1GetNotificationPreferences(ctx context.Context, storage NotificationStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := authorizedUserID(ctx, c, storage)
+		if !ok {
+			return
+		}
+
+		preferences, err := storage.GetNotificationPreferences(ctx, userID)
+		if err != nil {
+			notificationWriteError(c, 0, "get notification preferences", err)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, preferences)
+	}
+}
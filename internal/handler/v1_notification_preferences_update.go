@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"taskmanager/internal/model"
+)
+
+type NotificationPreferenceBody struct {
+	Type    string `json:"type" binding:"required,oneof=task.reminder webhook.disabled project.shared task.assigned task.commented" example:"task.reminder"`
+	Enabled *bool  `json:"enabled" binding:"required" example:"false"`
+}
+
+type UpdateNotificationPreferencesBody struct {
+	Preferences []NotificationPreferenceBody `json:"preferences" binding:"required,min=1,dive"`
+}
+
+// V1UpdateNotificationPreferences the notifications of the disabled types are not created, the created ones are
+// kept. The types missing in the body are not changed.
+//
+// @Summary update notification preferences
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param data body UpdateNotificationPreferencesBody true "type - task.reminder, webhook.disabled, project.shared, task.assigned, task.commented"
+// @Success 200 {object} []model.NotificationPreference "all types"
+// @Failure 400 {object} HTTPError "error type, comment"
+// @Failure 401 {object} nil
+// @Failure 403 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/notifications/preferences [put]
+func V1UpdateNotificationPreferences(ctx context.Context, storage NotificationStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := authorizedUserID(ctx, c, storage)
+		if !ok {
+			return
+		}
+
+		var b UpdateNotificationPreferencesBody
+		if err := c.ShouldBindJSON(&b); err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParametersRequired,
+				Comment: "preferences: type, enabled",
+				Error:   err.Error(),
+				fields:  fieldErrors(err),
+			})
+
+			return
+		}
+
+		preferences := make([]model.NotificationPreference, 0, len(b.Preferences))
+
+		for _, p := range b.Preferences {
+			preferences = append(preferences, model.NotificationPreference{
+				Type:    p.Type,
+				Enabled: *p.Enabled,
+			})
+		}
+
+		if err := storage.SetNotificationPreferences(ctx, userID, preferences); err != nil {
+			notificationWriteError(c, 0, "set notification preferences", err)
+
+			return
+		}
+
+		preferences, err := storage.GetNotificationPreferences(ctx, userID)
+		if err != nil {
+			notificationWriteError(c, 0, "get notification preferences", err)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, preferences)
+	}
+}
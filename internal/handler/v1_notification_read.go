@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// V1ReadNotification marks the notification read, the read one keeps the time of the first reading.
+//
+// @Summary mark notification read
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param notificationId path int true "notificationId" minimum(1)
+// @Success 204 {object} nil
+// @Failure 400 {object} HTTPError "error type, comment"
+// @Failure 401 {object} nil
+// @Failure 403 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/notifications/{notificationId}/read [post]
+func V1ReadNotification(ctx context.Context, storage NotificationStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := authorizedUserID(ctx, c, storage)
+		if !ok {
+			return
+		}
+
+		var u notificationURI
+		if err := c.ShouldBindUri(&u); err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParameterRequired,
+				Comment: "notificationId",
+				Error:   err.Error(),
+				fields:  fieldErrors(err),
+			})
+
+			return
+		}
+
+		if err := storage.ReadNotification(ctx, userID, u.NotificationID); err != nil {
+			notificationWriteError(c, u.NotificationID, "read notification", err)
+
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
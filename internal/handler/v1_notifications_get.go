@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"taskmanager/internal/model"
+)
+
+type NotificationsQuery struct {
+	Unread bool `form:"unread" example:"true"`
+	// Before the notificationId of the last notification of the previous page.
+	Before int `form:"before" binding:"omitempty,min=1" example:"42"`
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=100" example:"50"`
+}
+
+type NotificationsResult struct {
+	Notifications []model.Notification `json:"notifications"`
+	// Unread the unread notifications of the user, not only of the page.
+	Unread       int            `json:"unread" example:"3"`
+	UnreadByType map[string]int `json:"unreadByType"`
+}
+
+// V1GetNotifications the inbox of the user, the newest first. The next page - before=notificationId of the last
+// notification of the page.
+//
+// @Summary get notifications
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param unread query bool false "only the unread ones"
+// @Param before query int false "notificationId, the notifications before it" minimum(1)
+// @Param limit query int false "50 by default" minimum(1) maximum(100)
+// @Success 200 {object} NotificationsResult
+// @Failure 400 {object} HTTPError "error type, comment"
+// @Failure 401 {object} nil
+// @Failure 403 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/notifications [get]
+func V1GetNotifications(ctx context.Context, storage NotificationStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := authorizedUserID(ctx, c, storage)
+		if !ok {
+			return
+		}
+
+		var q NotificationsQuery
+		if err := c.ShouldBindQuery(&q); err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParameterRequired,
+				Comment: "unread, before, limit: 1-100",
+				Error:   err.Error(),
+				fields:  fieldErrors(err),
+			})
+
+			return
+		}
+
+		if q.Limit == 0 {
+			q.Limit = defaultNotificationsLimit
+		}
+
+		notifications, err := storage.GetNotifications(ctx, userID, model.NotificationQuery{
+			Unread:   q.Unread,
+			BeforeID: q.Before,
+			Limit:    q.Limit,
+		})
+		if err != nil {
+			notificationWriteError(c, 0, "get notifications", err)
+
+			return
+		}
+
+		unreadByType, err := storage.GetUnreadNotifications(ctx, userID)
+		if err != nil {
+			notificationWriteError(c, 0, "get unread notifications", err)
+
+			return
+		}
+
+		res := NotificationsResult{
+			Notifications: notifications,
+			UnreadByType:  unreadByType,
+		}
+
+		if res.Notifications == nil {
+			res.Notifications = []model.Notification{}
+		}
+
+		for _, quantity := range unreadByType {
+			res.Unread += quantity
+		}
+
+		c.JSON(http.StatusOK, res)
+	}
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReadNotificationsResult struct {
+	// Read the notifications marked read, the ones read before are not counted.
+	Read int64 `json:"read" example:"3"`
+}
+
+// V1ReadNotifications marks all notifications of the user read.
+//
+// @Summary mark all notifications read
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Success 200 {object} ReadNotificationsResult
+// @Failure 401 {object} nil
+// @Failure 403 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/notifications/read [post]
+func V1ReadNotifications(ctx context.Context, storage NotificationStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := authorizedUserID(ctx, c, storage)
+		if !ok {
+			return
+		}
+
+		quantity, err := storage.ReadNotifications(ctx, userID)
+		if err != nil {
+			notificationWriteError(c, 0, "read notifications", err)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, ReadNotificationsResult{
+			Read: quantity,
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CreateProjectBody struct {
+	Name string `json:"name" binding:"required,max=200" example:"board"`
+}
+
+type CreateProjectResult struct {
+	ProjectID int `json:"projectId"`
+}
+
+// V1CreateProject the user owns the project and is its first member.
+//
+// @Summary create project
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param data body CreateProjectBody true "name"
+// @Success 201 {object} CreateProjectResult "projectId"
+// @Failure 400 {object} HTTPError "error type, comment"
+// @Failure 401 {object} nil
+// @Failure 403 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/projects [post]
+func V1CreateProject(ctx context.Context, storage ProjectStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := authorizedUserID(ctx, c, storage)
+		if !ok {
+			return
+		}
+
+		var b CreateProjectBody
+		if err := c.ShouldBindJSON(&b); err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParameterRequired,
+				Comment: "name",
+				Error:   err.Error(),
+				fields:  fieldErrors(err),
+			})
+
+			return
+		}
+
+		projectID, err := storage.CreateProject(ctx, userID, b.Name)
+		if err != nil {
+			projectWriteError(c, 0, "create project", err)
+
+			return
+		}
+
+		c.JSON(http.StatusCreated, CreateProjectResult{
+			ProjectID: projectID,
+		})
+	}
+}
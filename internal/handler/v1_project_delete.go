@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// V1DeleteProject by the owner, the tasks stay with their owners outside of projects.
+//
+// @Summary delete project
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param projectId path int true "projectId" minimum(1)
+// @Success 204 {object} nil
+// @Failure 400 {object} HTTPError "error type, comment"
+// @Failure 401 {object} nil
+// @Failure 403 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/projects/{projectId} [delete]
+func V1DeleteProject(ctx context.Context, storage ProjectStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := authorizedUserID(ctx, c, storage)
+		if !ok {
+			return
+		}
+
+		var u projectURI
+		if err := c.ShouldBindUri(&u); err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParameterRequired,
+				Comment: "projectId",
+				Error:   err.Error(),
+				fields:  fieldErrors(err),
+			})
+
+			return
+		}
+
+		if err := storage.DeleteProject(ctx, userID, u.ProjectID); err != nil {
+			projectWriteError(c, u.ProjectID, "delete project", err)
+
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
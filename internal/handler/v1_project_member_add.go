@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AddProjectMemberBody struct {
+	Username string `json:"username" binding:"required" example:"colleague"`
+}
+
+type AddProjectMemberResult struct {
+	UserID int `json:"userId"`
+}
+
+// V1AddProjectMember by the owner, the member sees the tasks of the project and gets their events.
+// 200 - the user is already a member.
+//
+// @Summary add project member
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param projectId path int true "projectId" minimum(1)
+// @Param data body AddProjectMemberBody true "username"
+// @Success 200 {object} AddProjectMemberResult "userId"
+// @Success 201 {object} AddProjectMemberResult "userId"
+// @Failure 400 {object} HTTPError "error type, comment"
+// @Failure 401 {object} nil
+// @Failure 403 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/projects/{projectId}/members [post]
+func V1AddProjectMember(ctx context.Context, storage ProjectStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := authorizedUserID(ctx, c, storage)
+		if !ok {
+			return
+		}
+
+		var u projectURI
+		if err := c.ShouldBindUri(&u); err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParameterRequired,
+				Comment: "projectId",
+				Error:   err.Error(),
+				fields:  fieldErrors(err),
+			})
+
+			return
+		}
+
+		var b AddProjectMemberBody
+		if err := c.ShouldBindJSON(&b); err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeUsernameRequired,
+				Comment: "username",
+				Error:   err.Error(),
+				fields:  fieldErrors(err),
+			})
+
+			return
+		}
+
+		memberID, added, err := storage.AddProjectMember(ctx, userID, u.ProjectID, b.Username)
+		if err != nil {
+			projectWriteError(c, u.ProjectID, b.Username, err)
+
+			return
+		}
+
+		status := http.StatusOK
+		if added {
+			status = http.StatusCreated
+		}
+
+		c.JSON(status, AddProjectMemberResult{
+			UserID: memberID,
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// V1RemoveProjectMember by the owner or by the member leaving, the owner is not removed.
+// The tasks of the member leave the project.
+//
+// @Summary remove project member
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param projectId path int true "projectId" minimum(1)
+// @Param userId path int true "userId" minimum(1)
+// @Success 204 {object} nil
+// @Failure 400 {object} HTTPError "error type, comment"
+// @Failure 401 {object} nil
+// @Failure 403 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/projects/{projectId}/members/{userId} [delete]
+func V1RemoveProjectMember(ctx context.Context, storage ProjectStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := authorizedUserID(ctx, c, storage)
+		if !ok {
+			return
+		}
+
+		var u projectMemberURI
+		if err := c.ShouldBindUri(&u); err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParameterRequired,
+				Comment: "projectId, userId",
+				Error:   err.Error(),
+				fields:  fieldErrors(err),
+			})
+
+			return
+		}
+
+		if err := storage.RemoveProjectMember(ctx, userID, u.ProjectID, u.UserID); err != nil {
+			projectWriteError(c, u.ProjectID, strconv.Itoa(u.UserID), err)
+
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
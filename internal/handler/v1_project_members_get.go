@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// V1GetProjectMembers the owner first, visible to the members.
+//
+// @Summary get project members
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param projectId path int true "projectId" minimum(1)
+// @Success 200 {object} []model.ProjectMember
+// @Failure 400 {object} HTTPError "error type, comment"
+// @Failure 401 {object} nil
+// @Failure 403 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/projects/{projectId}/members [get]
+func V1GetProjectMembers(ctx context.Context, storage ProjectStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := authorizedUserID(ctx, c, storage)
+		if !ok {
+			return
+		}
+
+		var u projectURI
+		if err := c.ShouldBindUri(&u); err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParameterRequired,
+				Comment: "projectId",
+				Error:   err.Error(),
+				fields:  fieldErrors(err),
+			})
+
+			return
+		}
+
+		members, err := storage.GetProjectMembers(ctx, userID, u.ProjectID)
+		if err != nil {
+			projectWriteError(c, u.ProjectID, "get project members", err)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, members)
+	}
+}
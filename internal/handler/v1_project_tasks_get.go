@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"taskmanager/internal/model"
+)
+
+// V1GetProjectTasks the tasks of all the members in the project, without the trash.
+//
+// @Summary get project tasks
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param projectId path int true "projectId" minimum(1)
+// @Success 200 {object} []model.Task
+// @Failure 400 {object} HTTPError "error type, comment"
+// @Failure 401 {object} nil
+// @Failure 403 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/projects/{projectId}/tasks [get]
+func V1GetProjectTasks(ctx context.Context, storage ProjectStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := authorizedUserID(ctx, c, storage)
+		if !ok {
+			return
+		}
+
+		var u projectURI
+		if err := c.ShouldBindUri(&u); err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParameterRequired,
+				Comment: "projectId",
+				Error:   err.Error(),
+				fields:  fieldErrors(err),
+			})
+
+			return
+		}
+
+		tasks, err := storage.GetProjectTasks(ctx, userID, u.ProjectID)
+		if err != nil {
+			projectWriteError(c, u.ProjectID, "get project tasks", err)
+
+			return
+		}
+
+		if tasks == nil {
+			tasks = []model.Task{}
+		}
+
+		c.JSON(http.StatusOK, tasks)
+	}
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"taskmanager/internal/model"
+)
+
+// V1GetProjects the projects the user is a member of.
+//
+// @Summary get projects
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Success 200 {object} []model.Project
+// @Failure 401 {object} nil
+// @Failure 403 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/projects [get]
+func V1GetProjects(ctx context.Context, storage ProjectStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := authorizedUserID(ctx, c, storage)
+		if !ok {
+			return
+		}
+
+		projects, err := storage.GetProjects(ctx, userID)
+		if err != nil {
+			projectWriteError(c, 0, "get projects", err)
+
+			return
+		}
+
+		if projects == nil {
+			projects = []model.Project{}
+		}
+
+		c.JSON(http.StatusOK, projects)
+	}
+}
@@ -15,11 +15,11 @@ import (
 
 const maxLengthTaskTitle = 200
 
-type createTaskBody struct {
+type CreateTaskBody struct {
 	Title string `json:"title" binding:"required" example:"some title"`
 }
 
-type createTaskResult struct {
+type CreateTaskResult struct {
 	TaskID int `json:"taskId"`
 }
 
@@ -29,13 +29,14 @@ type createTaskResult struct {
 // @Tags task
 // @Accept json
 // @Produce json
-// @Param data body createTaskBody true "title - max 200"
-// @Success 201 {object} createTaskResult "taskId"
+// @Param data body CreateTaskBody true "title - max 200"
+// @Param Idempotency-Key header string false "retries with the same key get the stored response"
+// @Success 201 {object} CreateTaskResult "taskId"
 // @Failure 400 {object} HTTPError "error type, comment"
 // @Failure 401 {object} nil
 // @Failure 500 {object} HTTPError "error type, comment"
 // @Router /v1/task [post]
-func V1CreateTask(ctx context.Context, postgres PostgresDB) gin.HandlerFunc {
+func V1CreateTask(ctx context.Context, storage TaskStorage) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		username, password, ok := c.Request.BasicAuth()
 		if !ok {
@@ -44,19 +45,20 @@ func V1CreateTask(ctx context.Context, postgres PostgresDB) gin.HandlerFunc {
 			return
 		}
 
-		var b createTaskBody
+		var b CreateTaskBody
 		if err := c.ShouldBindJSON(&b); err != nil {
-			c.JSON(http.StatusBadRequest, HTTPError{
+			writeError(c, http.StatusBadRequest, HTTPError{
 				Type:    typeParameterRequired,
 				Comment: "title",
 				Error:   err.Error(),
+				fields:  fieldErrors(err),
 			})
 
 			return
 		}
 
 		if utf8.RuneCountInString(b.Title) > maxLengthTaskTitle {
-			c.JSON(http.StatusBadRequest, HTTPError{
+			writeError(c, http.StatusBadRequest, HTTPError{
 				Type:    typeParameterTooLong,
 				Comment: fmt.Sprintf("max %d", maxLengthTaskTitle),
 			})
@@ -64,16 +66,16 @@ func V1CreateTask(ctx context.Context, postgres PostgresDB) gin.HandlerFunc {
 			return
 		}
 
-		taskID, err := postgres.CreateTask(ctx, username, security.SaltPassword(password), b.Title)
+		taskID, err := storage.CreateTask(ctx, username, security.SaltPassword(password), b.Title)
 		if err != nil {
 			if errors.Is(err, model.ErrUserNotFound) {
-				c.AbortWithStatus(http.StatusForbidden)
+				abortWithStatus(c, http.StatusForbidden)
 
 				return
 			}
 
 			if errors.Is(err, model.ErrTaskAlreadyExists) {
-				c.JSON(http.StatusBadRequest, HTTPError{
+				writeError(c, http.StatusBadRequest, HTTPError{
 					Type:    typeTaskAlreadyExists,
 					Comment: "duplicate task",
 					Error:   err.Error(),
@@ -82,7 +84,7 @@ func V1CreateTask(ctx context.Context, postgres PostgresDB) gin.HandlerFunc {
 				return
 			}
 
-			c.JSON(http.StatusInternalServerError, HTTPError{
+			writeError(c, http.StatusInternalServerError, HTTPError{
 				Type:    typeInternalError,
 				Comment: "create task",
 				Error:   err.Error(),
@@ -91,8 +93,32 @@ func V1CreateTask(ctx context.Context, postgres PostgresDB) gin.HandlerFunc {
 			return
 		}
 
-		c.JSON(http.StatusCreated, createTaskResult{
+		c.JSON(http.StatusCreated, CreateTaskResult{
 			TaskID: taskID,
 		})
 	}
 }
+
+var errInvalidTask = errors.New("invalid task")
+
+// ValidateTaskTitle the title is checked like by the handlers, for the other APIs.
+func ValidateTaskTitle(title string) error {
+	if title == "" {
+		return fmt.Errorf("%w: %s: title", errInvalidTask, typeParameterRequired)
+	}
+
+	if utf8.RuneCountInString(title) > maxLengthTaskTitle {
+		return fmt.Errorf("%w: %s: title: max %d", errInvalidTask, typeParameterTooLong, maxLengthTaskTitle)
+	}
+
+	return nil
+}
+
+// ValidateTaskRecurrence the recurrence is checked like by the handlers, for the other APIs. Empty - no recurrence.
+func ValidateTaskRecurrence(rule string) error {
+	if err := checkRecurrence(rule); err != nil {
+		return fmt.Errorf("%w: %s: recurrence: %s", errInvalidTask, typeParameterRequired, err.Error())
+	}
+
+	return nil
+}
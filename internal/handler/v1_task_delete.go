@@ -16,19 +16,26 @@ type deleteTaskURI struct {
 	TaskID int `uri:"taskId" binding:"required" example:"24"`
 }
 
+type deleteTaskQuery struct {
+	Permanent bool `form:"permanent" example:"true"`
+}
+
 // V1DeleteTask
 //
-// @Summary delete task
+// @Summary delete task(move to the trash)
 // @Tags task
 // @Accept json
 // @Produce json
 // @Param taskId path int true "taskId" minimum(1)
+// @Param permanent query bool false "delete without moving to the trash"
+// @Param If-Match header string false "ETag of the task"
 // @Success 204 {object} nil
 // @Failure 400 {object} HTTPError "error type, comment"
 // @Failure 401 {object} nil
+// @Failure 412 {object} HTTPError "error type, comment"
 // @Failure 500 {object} HTTPError "error type, comment"
 // @Router /v1/task/{taskId} [delete]
-func V1DeleteTask(ctx context.Context, postgres PostgresDB) gin.HandlerFunc {
+func V1DeleteTask(ctx context.Context, storage TaskStorage) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		username, password, ok := c.Request.BasicAuth()
 		if !ok {
@@ -39,18 +46,51 @@ func V1DeleteTask(ctx context.Context, postgres PostgresDB) gin.HandlerFunc {
 
 		var u deleteTaskURI
 		if err := c.ShouldBindUri(&u); err != nil {
-			c.JSON(http.StatusBadRequest, HTTPError{
+			writeError(c, http.StatusBadRequest, HTTPError{
 				Type:    typeParameterRequired,
 				Comment: "taskId",
 				Error:   err.Error(),
+				fields:  fieldErrors(err),
+			})
+
+			return
+		}
+
+		var q deleteTaskQuery
+		if err := c.ShouldBindQuery(&q); err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParameterRequired,
+				Comment: "permanent",
+				Error:   err.Error(),
+				fields:  fieldErrors(err),
+			})
+
+			return
+		}
+
+		match, ok := parseIfMatch(c.GetHeader(headerIfMatch))
+		if !ok {
+			writeError(c, http.StatusPreconditionFailed, HTTPError{
+				Type:    typeTaskVersionMismatch,
+				Comment: headerIfMatch,
 			})
 
 			return
 		}
 
-		if err := postgres.DeleteTask(ctx, username, security.SaltPassword(password), u.TaskID); err != nil {
+		deleteTask := storage.DeleteTask
+		if q.Permanent {
+			deleteTask = storage.DeleteTaskPermanently
+		}
+
+		version, err := match.version(ifMatchTaskVersion(ctx, storage, username, password, u.TaskID))
+		if err == nil {
+			err = deleteTask(ctx, username, security.SaltPassword(password), u.TaskID, version)
+		}
+
+		if err != nil {
 			if errors.Is(err, model.ErrTaskNotFound) {
-				c.JSON(http.StatusBadRequest, HTTPError{
+				writeError(c, http.StatusBadRequest, HTTPError{
 					Type:    typeTaskNotFound,
 					Comment: strconv.Itoa(u.TaskID),
 					Error:   err.Error(),
@@ -59,7 +99,17 @@ func V1DeleteTask(ctx context.Context, postgres PostgresDB) gin.HandlerFunc {
 				return
 			}
 
-			c.JSON(http.StatusInternalServerError, HTTPError{
+			if errors.Is(err, model.ErrVersionMismatch) {
+				writeError(c, http.StatusPreconditionFailed, HTTPError{
+					Type:    typeTaskVersionMismatch,
+					Comment: strconv.Itoa(u.TaskID),
+					Error:   err.Error(),
+				})
+
+				return
+			}
+
+			writeError(c, http.StatusInternalServerError, HTTPError{
 				Type:    typeInternalError,
 				Comment: "delete task",
 				Error:   err.Error(),
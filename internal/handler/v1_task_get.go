@@ -12,6 +12,12 @@ import (
 	"taskmanager/internal/security"
 )
 
+// TaskResponse the task with the ETag of its version for If-Match.
+type TaskResponse struct {
+	model.Task
+	ETag string `json:"etag,omitempty" example:"\"3\""`
+}
+
 type getTaskURI struct {
 	TaskID int `uri:"taskId" binding:"required" example:"24"`
 }
@@ -23,12 +29,14 @@ type getTaskURI struct {
 // @Accept json
 // @Produce json
 // @Param taskId path int true "taskId" minimum(1)
-// @Success 200 {object} model.Task
+// @Param If-None-Match header string false "ETag of the cached task"
+// @Success 200 {object} TaskResponse
+// @Success 304 {object} nil
 // @Failure 400 {object} HTTPError "error type, comment"
 // @Failure 401 {object} nil
 // @Failure 500 {object} HTTPError "error type, comment"
 // @Router /v1/task/{taskId} [get]
-func V1GetTask(ctx context.Context, postgres PostgresDB) gin.HandlerFunc {
+func V1GetTask(ctx context.Context, storage TaskStorage) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		username, password, ok := c.Request.BasicAuth()
 		if !ok {
@@ -39,19 +47,20 @@ func V1GetTask(ctx context.Context, postgres PostgresDB) gin.HandlerFunc {
 
 		var u getTaskURI
 		if err := c.ShouldBindUri(&u); err != nil {
-			c.JSON(http.StatusBadRequest, HTTPError{
+			writeError(c, http.StatusBadRequest, HTTPError{
 				Type:    typeParameterRequired,
 				Comment: "taskId",
 				Error:   err.Error(),
+				fields:  fieldErrors(err),
 			})
 
 			return
 		}
 
-		task, err := postgres.GetTask(ctx, username, security.SaltPassword(password), u.TaskID)
+		task, err := storage.GetTask(ctx, username, security.SaltPassword(password), u.TaskID)
 		if err != nil {
 			if errors.Is(err, model.ErrTaskNotFound) {
-				c.JSON(http.StatusBadRequest, HTTPError{
+				writeError(c, http.StatusBadRequest, HTTPError{
 					Type:    typeTaskNotFound,
 					Comment: strconv.Itoa(u.TaskID),
 					Error:   err.Error(),
@@ -60,7 +69,7 @@ func V1GetTask(ctx context.Context, postgres PostgresDB) gin.HandlerFunc {
 				return
 			}
 
-			c.JSON(http.StatusInternalServerError, HTTPError{
+			writeError(c, http.StatusInternalServerError, HTTPError{
 				Type:    typeInternalError,
 				Comment: "get task",
 				Error:   err.Error(),
@@ -69,6 +78,19 @@ func V1GetTask(ctx context.Context, postgres PostgresDB) gin.HandlerFunc {
 			return
 		}
 
-		c.JSON(http.StatusOK, task)
+		etag := taskETag(task.Version)
+
+		c.Header(headerETag, etag)
+
+		if matchIfNoneMatch(c.GetHeader(headerIfNoneMatch), etag) {
+			c.Status(http.StatusNotModified)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, TaskResponse{
+			Task: task,
+			ETag: etag,
+		})
 	}
 }
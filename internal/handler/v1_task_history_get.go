@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/security"
+)
+
+type getTaskHistoryURI struct {
+	TaskID int `uri:"taskId" binding:"required" example:"24"`
+}
+
+// V1GetTaskHistory
+//
+// @Summary get task change history, also of the task in the trash or deleted permanently
+// @Tags task
+// @Accept json
+// @Produce json
+// @Param taskId path int true "taskId" minimum(1)
+// @Success 200 {object} []model.TaskChange
+// @Failure 400 {object} HTTPError "error type, comment"
+// @Failure 401 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/task/{taskId}/history [get]
+func V1GetTaskHistory(ctx context.Context, storage TaskStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username, password, ok := c.Request.BasicAuth()
+		if !ok {
+			abortWithStatusUnauthorized(c)
+
+			return
+		}
+
+		var u getTaskHistoryURI
+		if err := c.ShouldBindUri(&u); err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParameterRequired,
+				Comment: "taskId",
+				Error:   err.Error(),
+				fields:  fieldErrors(err),
+			})
+
+			return
+		}
+
+		history, err := storage.GetTaskHistory(ctx, username, security.SaltPassword(password), u.TaskID)
+		if err != nil {
+			if errors.Is(err, model.ErrTaskNotFound) {
+				writeError(c, http.StatusBadRequest, HTTPError{
+					Type:    typeTaskNotFound,
+					Comment: strconv.Itoa(u.TaskID),
+					Error:   err.Error(),
+				})
+
+				return
+			}
+
+			writeError(c, http.StatusInternalServerError, HTTPError{
+				Type:    typeInternalError,
+				Comment: "get task history",
+				Error:   err.Error(),
+			})
+
+			return
+		}
+
+		c.JSON(http.StatusOK, history)
+	}
+}
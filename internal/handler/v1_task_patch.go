@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/security"
+)
+
+type patchTaskURI struct {
+	TaskID int `uri:"taskId" binding:"required" example:"24"`
+}
+
+// V1PatchTask changes only the fields present in the patch.
+// JSON Merge Patch(RFC 7396) for application/json and application/merge-patch+json,
+// JSON Patch(RFC 6902) for application/json-patch+json.
+// due - RFC 3339 time; recurrence - RRULE of RFC 5545 without the prefix, the task repeats from due.
+// Only due and recurrence can be removed: null or "remove".
+//
+// @Summary patch task
+// @Tags task
+// @Accept json
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Param taskId path int true "taskId" minimum(1)
+// @Param data body object true "merge patch - the fields; json patch - ops on /title, /completed, /due, /recurrence"
+// @Param If-Match header string false "ETag of the task"
+// @Success 204 {object} nil
+// @Failure 400 {object} HTTPError "error type, comment"
+// @Failure 401 {object} nil
+// @Failure 409 {object} HTTPError "error type, comment"
+// @Failure 412 {object} HTTPError "error type, comment"
+// @Failure 415 {object} HTTPError "error type, comment"
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/task/{taskId} [patch]
+func V1PatchTask(ctx context.Context, storage TaskStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username, password, ok := c.Request.BasicAuth()
+		if !ok {
+			abortWithStatusUnauthorized(c)
+
+			return
+		}
+
+		var u patchTaskURI
+		if err := c.ShouldBindUri(&u); err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParameterRequired,
+				Comment: "taskId",
+				Error:   err.Error(),
+				fields:  fieldErrors(err),
+			})
+
+			return
+		}
+
+		match, ok := parseIfMatch(c.GetHeader(headerIfMatch))
+		if !ok {
+			writeError(c, http.StatusPreconditionFailed, HTTPError{
+				Type:    typeTaskVersionMismatch,
+				Comment: headerIfMatch,
+			})
+
+			return
+		}
+
+		version, err := match.version(ifMatchTaskVersion(ctx, storage, username, password, u.TaskID))
+		if err != nil {
+			patchTaskAbortWithError(c, u.TaskID, err)
+
+			return
+		}
+
+		body, err := c.GetRawData()
+		if err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typePatchInvalid,
+				Comment: "body",
+				Error:   err.Error(),
+			})
+
+			return
+		}
+
+		var patch taskPatch
+
+		switch c.ContentType() {
+		case mimeJSON, mimeMergePatch:
+			patch, err = parseMergePatch(body)
+
+		case mimeJSONPatch:
+			patch, version, err = patchTaskApplyJSONPatch(ctx, storage, username, password, u.TaskID, version, body)
+
+		default:
+			writeError(c, http.StatusUnsupportedMediaType, HTTPError{
+				Type:    typeUnsupportedMediaType,
+				Comment: mimeJSON + ", " + mimeMergePatch + ", " + mimeJSONPatch,
+			})
+
+			return
+		}
+
+		if err != nil {
+			patchTaskAbortWithError(c, u.TaskID, err)
+
+			return
+		}
+
+		if patch.Title == nil && patch.Completed == nil && patch.Due == nil && patch.Recurrence == nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParametersRequired,
+				Comment: "title, completed, due or recurrence",
+			})
+
+			return
+		}
+
+		if err := storage.UpdateTask(
+			ctx, username, security.SaltPassword(password), u.TaskID, version, model.TaskUpdate{
+				Title:      patch.Title,
+				Status:     patch.Completed,
+				Due:        patch.Due,
+				Recurrence: patch.Recurrence,
+			},
+		); err != nil {
+			patchTaskAbortWithError(c, u.TaskID, err)
+
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// patchTaskApplyJSONPatch "test" operations need the current task, then the patch is applied only to its version.
+func patchTaskApplyJSONPatch(
+	ctx context.Context, storage TaskStorage, username, password string, taskID, version int, body []byte,
+) (taskPatch, int, error) {
+	operations, err := parseJSONPatch(body)
+	if err != nil {
+		return taskPatch{}, 0, err
+	}
+
+	var task model.Task
+
+	if jsonPatchHasTest(operations) {
+		task, err = storage.GetTask(ctx, username, security.SaltPassword(password), taskID)
+		if err != nil {
+			return taskPatch{}, 0, fmt.Errorf("get task: %w", err)
+		}
+
+		if version != 0 && version != task.Version {
+			return taskPatch{}, 0, model.ErrVersionMismatch
+		}
+
+		version = task.Version
+	}
+
+	patch, err := applyJSONPatch(operations, task)
+	if err != nil {
+		return taskPatch{}, 0, err
+	}
+
+	return patch, version, nil
+}
+
+func patchTaskAbortWithError(c *gin.Context, taskID int, err error) {
+	switch {
+	case errors.Is(err, errPatchInvalid):
+		writeError(c, http.StatusBadRequest, HTTPError{
+			Type:    typePatchInvalid,
+			Comment: err.Error(),
+			Error:   err.Error(),
+		})
+
+	case errors.Is(err, errPatchTestFailed):
+		writeError(c, http.StatusConflict, HTTPError{
+			Type:    typePatchTestFailed,
+			Comment: err.Error(),
+			Error:   err.Error(),
+		})
+
+	case errors.Is(err, model.ErrTaskNotFound):
+		writeError(c, http.StatusBadRequest, HTTPError{
+			Type:    typeTaskNotFound,
+			Comment: strconv.Itoa(taskID),
+			Error:   err.Error(),
+		})
+
+	case errors.Is(err, model.ErrVersionMismatch):
+		writeError(c, http.StatusPreconditionFailed, HTTPError{
+			Type:    typeTaskVersionMismatch,
+			Comment: strconv.Itoa(taskID),
+			Error:   err.Error(),
+		})
+
+	default:
+		writeError(c, http.StatusInternalServerError, HTTPError{
+			Type:    typeInternalError,
+			Comment: "patch task",
+			Error:   err.Error(),
+		})
+	}
+}
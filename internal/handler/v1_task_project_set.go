@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"taskmanager/internal/security"
+)
+
+type setTaskProjectURI struct {
+	TaskID int `uri:"taskId" binding:"required" example:"24"`
+}
+
+type SetTaskProjectBody struct {
+	// ProjectID 0 - the task leaves its project.
+	ProjectID *int `json:"projectId" binding:"required,min=0" example:"5"`
+}
+
+// V1SetTaskProject moves the task to the project the user is a member of, its members get the events of the task.
+//
+// @Summary set task project
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param taskId path int true "taskId" minimum(1)
+// @Param data body SetTaskProjectBody true "projectId, 0 - out of the project"
+// @Success 204 {object} nil
+// @Failure 400 {object} HTTPError "error type, comment"
+// @Failure 401 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/task/{taskId}/project [put]
+func V1SetTaskProject(ctx context.Context, storage ProjectStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username, password, ok := c.Request.BasicAuth()
+		if !ok {
+			abortWithStatusUnauthorized(c)
+
+			return
+		}
+
+		var u setTaskProjectURI
+		if err := c.ShouldBindUri(&u); err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParameterRequired,
+				Comment: "taskId",
+				Error:   err.Error(),
+				fields:  fieldErrors(err),
+			})
+
+			return
+		}
+
+		var b SetTaskProjectBody
+		if err := c.ShouldBindJSON(&b); err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParameterRequired,
+				Comment: "projectId",
+				Error:   err.Error(),
+				fields:  fieldErrors(err),
+			})
+
+			return
+		}
+
+		if err := storage.SetTaskProject(
+			ctx, username, security.SaltPassword(password), u.TaskID, *b.ProjectID,
+		); err != nil {
+			projectWriteError(c, *b.ProjectID, strconv.Itoa(u.TaskID), err)
+
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"taskmanager/internal/model"
+)
+
+type CreateReminderBody struct {
+	// RemindAt the time of the reminder, or BeforeMinutes.
+	RemindAt *time.Time `json:"remindAt,omitempty" example:"2023-05-02T09:00:00Z"`
+	// BeforeMinutes before the due date of the task, it follows the changes of the due date.
+	BeforeMinutes *int   `json:"beforeMinutes,omitempty" binding:"omitempty,min=0,max=525600" example:"30"`
+	Channel       string `json:"channel" binding:"required,oneof=webhook email inapp" example:"inapp"`
+	// Email the recipient of the email channel.
+	Email string `json:"email,omitempty" binding:"omitempty,email,max=254" example:""`
+}
+
+type CreateReminderResult struct {
+	ReminderID int `json:"reminderId"`
+}
+
+// V1CreateReminder the reminder is sent once at remindAt or beforeMinutes before the due date of the task,
+// while the task is not completed and not in the trash. The webhook channel sends the task.reminder event
+// to the webhooks subscribed to it, the inapp channel - to the event stream.
+//
+// @Summary create task reminder
+// @Tags reminders
+// @Accept json
+// @Produce json
+// @Param taskId path int true "taskId" minimum(1)
+// @Param data body CreateReminderBody true "remindAt or beforeMinutes; channel - webhook, email, inapp"
+// @Success 201 {object} CreateReminderResult "reminderId"
+// @Failure 400 {object} HTTPError "error type, comment"
+// @Failure 401 {object} nil
+// @Failure 403 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/task/{taskId}/reminders [post]
+func V1CreateReminder(ctx context.Context, storage ReminderStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := authorizedUserID(ctx, c, storage)
+		if !ok {
+			return
+		}
+
+		var u remindersURI
+		if err := c.ShouldBindUri(&u); err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParameterRequired,
+				Comment: "taskId",
+				Error:   err.Error(),
+				fields:  fieldErrors(err),
+			})
+
+			return
+		}
+
+		var b CreateReminderBody
+		if err := c.ShouldBindJSON(&b); err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParametersRequired,
+				Comment: "remindAt or beforeMinutes, channel required",
+				Error:   err.Error(),
+				fields:  fieldErrors(err),
+			})
+
+			return
+		}
+
+		if res := checkReminder(b); res != nil {
+			writeError(c, http.StatusBadRequest, *res)
+
+			return
+		}
+
+		reminder := model.Reminder{
+			BeforeMinutes: b.BeforeMinutes,
+			Channel:       b.Channel,
+			Email:         b.Email,
+		}
+
+		if b.RemindAt != nil {
+			remindAt := b.RemindAt.UTC()
+			reminder.RemindAt = &remindAt
+		}
+
+		reminderID, err := storage.CreateReminder(ctx, userID, u.TaskID, reminder)
+		if err != nil {
+			reminderWriteError(c, u.TaskID, 0, "create reminder", err)
+
+			return
+		}
+
+		c.JSON(http.StatusCreated, CreateReminderResult{
+			ReminderID: reminderID,
+		})
+	}
+}
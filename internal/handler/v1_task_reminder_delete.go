@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// V1DeleteReminder the pending reminder is not sent.
+//
+// @Summary delete task reminder
+// @Tags reminders
+// @Accept json
+// @Produce json
+// @Param taskId path int true "taskId" minimum(1)
+// @Param reminderId path int true "reminderId" minimum(1)
+// @Success 204 {object} nil
+// @Failure 400 {object} HTTPError "error type, comment"
+// @Failure 401 {object} nil
+// @Failure 403 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/task/{taskId}/reminders/{reminderId} [delete]
+func V1DeleteReminder(ctx context.Context, storage ReminderStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := authorizedUserID(ctx, c, storage)
+		if !ok {
+			return
+		}
+
+		var u reminderURI
+		if err := c.ShouldBindUri(&u); err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParameterRequired,
+				Comment: "taskId, reminderId",
+				Error:   err.Error(),
+				fields:  fieldErrors(err),
+			})
+
+			return
+		}
+
+		if err := storage.DeleteReminder(ctx, userID, u.TaskID, u.ReminderID); err != nil {
+			reminderWriteError(c, u.TaskID, u.ReminderID, "delete reminder", err)
+
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
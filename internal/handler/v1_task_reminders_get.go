@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// V1GetReminders the pending, sent and failed reminders of the task. fireAt - when the pending one is sent,
+// empty while the task has no due date.
+//
+// @Summary get task reminders
+// @Tags reminders
+// @Accept json
+// @Produce json
+// @Param taskId path int true "taskId" minimum(1)
+// @Success 200 {object} []model.Reminder
+// @Failure 400 {object} HTTPError "error type, comment"
+// @Failure 401 {object} nil
+// @Failure 403 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/task/{taskId}/reminders [get]
+func V1GetReminders(ctx context.Context, storage ReminderStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := authorizedUserID(ctx, c, storage)
+		if !ok {
+			return
+		}
+
+		var u remindersURI
+		if err := c.ShouldBindUri(&u); err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParameterRequired,
+				Comment: "taskId",
+				Error:   err.Error(),
+				fields:  fieldErrors(err),
+			})
+
+			return
+		}
+
+		reminders, err := storage.GetReminders(ctx, userID, u.TaskID)
+		if err != nil {
+			reminderWriteError(c, u.TaskID, 0, "get reminders", err)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, reminders)
+	}
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/security"
+)
+
+type restoreTaskURI struct {
+	TaskID int `uri:"taskId" binding:"required" example:"24"`
+}
+
+// V1RestoreTask
+//
+// @Summary restore deleted task
+// @Tags task
+// @Accept json
+// @Produce json
+// @Param taskId path int true "taskId" minimum(1)
+// @Param Idempotency-Key header string false "retries with the same key get the stored response"
+// @Success 204 {object} nil
+// @Failure 400 {object} HTTPError "error type, comment"
+// @Failure 401 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/task/{taskId}/restore [post]
+func V1RestoreTask(ctx context.Context, storage TaskStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username, password, ok := c.Request.BasicAuth()
+		if !ok {
+			abortWithStatusUnauthorized(c)
+
+			return
+		}
+
+		var u restoreTaskURI
+		if err := c.ShouldBindUri(&u); err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParameterRequired,
+				Comment: "taskId",
+				Error:   err.Error(),
+				fields:  fieldErrors(err),
+			})
+
+			return
+		}
+
+		if err := storage.RestoreTask(ctx, username, security.SaltPassword(password), u.TaskID); err != nil {
+			if errors.Is(err, model.ErrTaskNotFound) {
+				writeError(c, http.StatusBadRequest, HTTPError{
+					Type:    typeTaskNotFound,
+					Comment: strconv.Itoa(u.TaskID),
+					Error:   err.Error(),
+				})
+
+				return
+			}
+
+			writeError(c, http.StatusInternalServerError, HTTPError{
+				Type:    typeInternalError,
+				Comment: "restore task",
+				Error:   err.Error(),
+			})
+
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/security"
+)
+
+type revertTaskURI struct {
+	TaskID   int `uri:"taskId" binding:"required" example:"24"`
+	Revision int `uri:"revision" binding:"min=0" example:"3"`
+}
+
+// V1RevertTask
+//
+// @Summary revert task to the revision(0 - as created)
+// @Tags task
+// @Accept json
+// @Produce json
+// @Param taskId path int true "taskId" minimum(1)
+// @Param revision path int true "revision" minimum(0)
+// @Param Idempotency-Key header string false "retries with the same key get the stored response"
+// @Success 204 {object} nil
+// @Failure 400 {object} HTTPError "error type, comment"
+// @Failure 401 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/task/{taskId}/history/{revision}/revert [post]
+func V1RevertTask(ctx context.Context, storage TaskStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username, password, ok := c.Request.BasicAuth()
+		if !ok {
+			abortWithStatusUnauthorized(c)
+
+			return
+		}
+
+		var u revertTaskURI
+		if err := c.ShouldBindUri(&u); err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParametersRequired,
+				Comment: "taskId, revision",
+				Error:   err.Error(),
+				fields:  fieldErrors(err),
+			})
+
+			return
+		}
+
+		if err := storage.RevertTask(ctx, username, security.SaltPassword(password), u.TaskID, u.Revision); err != nil {
+			if errors.Is(err, model.ErrTaskNotFound) {
+				writeError(c, http.StatusBadRequest, HTTPError{
+					Type:    typeTaskNotFound,
+					Comment: strconv.Itoa(u.TaskID),
+					Error:   err.Error(),
+				})
+
+				return
+			}
+
+			if errors.Is(err, model.ErrRevisionNotFound) {
+				writeError(c, http.StatusBadRequest, HTTPError{
+					Type:    typeRevisionNotFound,
+					Comment: strconv.Itoa(u.Revision),
+					Error:   err.Error(),
+				})
+
+				return
+			}
+
+			writeError(c, http.StatusInternalServerError, HTTPError{
+				Type:    typeInternalError,
+				Comment: "revert task",
+				Error:   err.Error(),
+			})
+
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/security"
+)
+
+type getTaskRevisionURI struct {
+	TaskID   int `uri:"taskId" binding:"required" example:"24"`
+	Revision int `uri:"revision" binding:"min=0" example:"3"`
+}
+
+// V1GetTaskRevision
+//
+// @Summary get task as it was after the revision(0 - as created)
+// @Tags task
+// @Accept json
+// @Produce json
+// @Param taskId path int true "taskId" minimum(1)
+// @Param revision path int true "revision" minimum(0)
+// @Success 200 {object} model.Task
+// @Failure 400 {object} HTTPError "error type, comment"
+// @Failure 401 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/task/{taskId}/history/{revision} [get]
+func V1GetTaskRevision(ctx context.Context, storage TaskStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username, password, ok := c.Request.BasicAuth()
+		if !ok {
+			abortWithStatusUnauthorized(c)
+
+			return
+		}
+
+		var u getTaskRevisionURI
+		if err := c.ShouldBindUri(&u); err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParametersRequired,
+				Comment: "taskId, revision",
+				Error:   err.Error(),
+				fields:  fieldErrors(err),
+			})
+
+			return
+		}
+
+		task, err := storage.GetTaskRevision(ctx, username, security.SaltPassword(password), u.TaskID, u.Revision)
+		if err != nil {
+			if errors.Is(err, model.ErrTaskNotFound) {
+				writeError(c, http.StatusBadRequest, HTTPError{
+					Type:    typeTaskNotFound,
+					Comment: strconv.Itoa(u.TaskID),
+					Error:   err.Error(),
+				})
+
+				return
+			}
+
+			if errors.Is(err, model.ErrRevisionNotFound) {
+				writeError(c, http.StatusBadRequest, HTTPError{
+					Type:    typeRevisionNotFound,
+					Comment: strconv.Itoa(u.Revision),
+					Error:   err.Error(),
+				})
+
+				return
+			}
+
+			writeError(c, http.StatusInternalServerError, HTTPError{
+				Type:    typeInternalError,
+				Comment: "get task revision",
+				Error:   err.Error(),
+			})
+
+			return
+		}
+
+		c.JSON(http.StatusOK, task)
+	}
+}
@@ -6,10 +6,10 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
-	"github.com/lib/pq"
 
 	"taskmanager/internal/model"
 	"taskmanager/internal/security"
@@ -19,25 +19,30 @@ type updateTaskURI struct {
 	TaskID int `uri:"taskId" binding:"required" example:"24"`
 }
 
-type updateTaskBody struct {
-	Title     string `json:"title" example:"some new title"`
-	Completed bool   `json:"completed" example:"true"`
+// UpdateTaskBody the task is replaced: due and recurrence are removed if they are absent.
+type UpdateTaskBody struct {
+	Title      string     `json:"title" binding:"required" example:"some new title"`
+	Completed  *bool      `json:"completed" binding:"required" example:"true"`
+	Due        *time.Time `json:"due" example:"2023-05-02T09:30:00Z"`
+	Recurrence string     `json:"recurrence" example:"FREQ=WEEKLY;BYDAY=TU"`
 }
 
-// V1UpdateTask
+// V1UpdateTask replaces the task, use V1PatchTask to change only some of the fields.
 //
-// @Summary update task
+// @Summary replace task
 // @Tags task
 // @Accept json
 // @Produce json
 // @Param taskId path int true "taskId" minimum(1)
-// @Param data body updateTaskBody true "any of the fields"
+// @Param data body UpdateTaskBody true "all fields; title - max 200; due, recurrence - absent or null removes them"
+// @Param If-Match header string false "ETag of the task"
 // @Success 204 {object} nil
 // @Failure 400 {object} HTTPError "error type, comment"
 // @Failure 401 {object} nil
+// @Failure 412 {object} HTTPError "error type, comment"
 // @Failure 500 {object} HTTPError "error type, comment"
 // @Router /v1/task/{taskId} [put]
-func V1UpdateTask(ctx context.Context, postgres PostgresDB) gin.HandlerFunc {
+func V1UpdateTask(ctx context.Context, storage TaskStorage) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		username, password, ok := c.Request.BasicAuth()
 		if !ok {
@@ -48,27 +53,30 @@ func V1UpdateTask(ctx context.Context, postgres PostgresDB) gin.HandlerFunc {
 
 		var u updateTaskURI
 		if err := c.ShouldBindUri(&u); err != nil {
-			c.JSON(http.StatusBadRequest, HTTPError{
+			writeError(c, http.StatusBadRequest, HTTPError{
 				Type:    typeParameterRequired,
 				Comment: "taskId",
 				Error:   err.Error(),
+				fields:  fieldErrors(err),
 			})
 
 			return
 		}
 
-		var b updateTaskBody
+		var b UpdateTaskBody
 		if err := c.ShouldBindJSON(&b); err != nil {
-			c.JSON(http.StatusBadRequest, HTTPError{
-				Type:  typeParametersRequired,
-				Error: err.Error(),
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParametersRequired,
+				Comment: "title and completed required",
+				Error:   err.Error(),
+				fields:  fieldErrors(err),
 			})
 
 			return
 		}
 
 		if utf8.RuneCountInString(b.Title) > maxLengthTaskTitle {
-			c.JSON(http.StatusBadRequest, HTTPError{
+			writeError(c, http.StatusBadRequest, HTTPError{
 				Type:    typeParameterTooLong,
 				Comment: fmt.Sprintf("max %d", maxLengthTaskTitle),
 			})
@@ -76,11 +84,46 @@ func V1UpdateTask(ctx context.Context, postgres PostgresDB) gin.HandlerFunc {
 			return
 		}
 
-		if err := postgres.UpdateTask(
-			ctx, username, security.SaltPassword(password), u.TaskID, updateTaskCreateSetValues(b),
-		); err != nil {
+		if err := checkRecurrence(b.Recurrence); err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParameterRequired,
+				Comment: "recurrence",
+				Error:   err.Error(),
+			})
+
+			return
+		}
+
+		var due time.Time
+		if b.Due != nil {
+			due = *b.Due
+		}
+
+		match, ok := parseIfMatch(c.GetHeader(headerIfMatch))
+		if !ok {
+			writeError(c, http.StatusPreconditionFailed, HTTPError{
+				Type:    typeTaskVersionMismatch,
+				Comment: headerIfMatch,
+			})
+
+			return
+		}
+
+		version, err := match.version(ifMatchTaskVersion(ctx, storage, username, password, u.TaskID))
+		if err == nil {
+			err = storage.UpdateTask(
+				ctx, username, security.SaltPassword(password), u.TaskID, version, model.TaskUpdate{
+					Title:      &b.Title,
+					Status:     b.Completed,
+					Due:        &due,
+					Recurrence: &b.Recurrence,
+				},
+			)
+		}
+
+		if err != nil {
 			if errors.Is(err, model.ErrTaskNotFound) {
-				c.JSON(http.StatusBadRequest, HTTPError{
+				writeError(c, http.StatusBadRequest, HTTPError{
 					Type:    typeTaskNotFound,
 					Comment: strconv.Itoa(u.TaskID),
 					Error:   err.Error(),
@@ -89,7 +132,17 @@ func V1UpdateTask(ctx context.Context, postgres PostgresDB) gin.HandlerFunc {
 				return
 			}
 
-			c.JSON(http.StatusInternalServerError, HTTPError{
+			if errors.Is(err, model.ErrVersionMismatch) {
+				writeError(c, http.StatusPreconditionFailed, HTTPError{
+					Type:    typeTaskVersionMismatch,
+					Comment: strconv.Itoa(u.TaskID),
+					Error:   err.Error(),
+				})
+
+				return
+			}
+
+			writeError(c, http.StatusInternalServerError, HTTPError{
 				Type:    typeInternalError,
 				Comment: "update task",
 				Error:   err.Error(),
@@ -101,22 +154,3 @@ func V1UpdateTask(ctx context.Context, postgres PostgresDB) gin.HandlerFunc {
 		c.Status(http.StatusNoContent)
 	}
 }
-
-func updateTaskCreateSetValues(b updateTaskBody) []string {
-	setValues := []string{
-		"status=" + strconv.FormatBool(b.Completed),
-	}
-
-	if b.Title != "" {
-		setValues = append(setValues, "title="+pq.QuoteLiteral(b.Title))
-	}
-
-	if b.Completed {
-		setValues = append(setValues, "completed=now()")
-	} else {
-		setValues = append(setValues, "updated=now()")
-		setValues = append(setValues, "completed=null")
-	}
-
-	return setValues
-}
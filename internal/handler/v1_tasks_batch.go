@@ -0,0 +1,348 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/security"
+)
+
+const maxBatchOperations = 100
+
+type BatchTasksBody struct {
+	// Atomic all or nothing, otherwise each operation is applied separately.
+	Atomic     bool                 `json:"atomic" example:"true"`
+	Operations []BatchTaskOperation `json:"operations" binding:"required"`
+}
+
+type BatchTaskOperation struct {
+	Op        string  `json:"op" example:"update"`
+	TaskID    int     `json:"taskId,omitempty" example:"24"`
+	Title     *string `json:"title,omitempty" example:"some title"`
+	Completed *bool   `json:"completed,omitempty" example:"true"`
+	IfMatch   string  `json:"ifMatch,omitempty" example:"\"3\""`
+	Permanent bool    `json:"permanent,omitempty" example:"false"`
+}
+
+type BatchTasksResult struct {
+	Results []BatchTaskOperationResult `json:"results"`
+}
+
+type BatchTaskOperationResult struct {
+	Status int        `json:"status" example:"201"`
+	TaskID int        `json:"taskId,omitempty" example:"24"`
+	Error  *HTTPError `json:"error,omitempty"`
+}
+
+// V1BatchTasks
+//
+// @Summary create, update and delete tasks in one transaction
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param data body BatchTasksBody true "op - create(title), update(taskId, title, completed, ifMatch), delete(taskId, permanent, ifMatch); max 100"
+// @Param Idempotency-Key header string false "retries with the same key get the stored response"
+// @Success 200 {object} BatchTasksResult "status of each operation"
+// @Failure 400 {object} BatchTasksResult "atomic: status of each operation"
+// @Failure 401 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/tasks/batch [post]
+func V1BatchTasks(ctx context.Context, storage TaskStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username, password, ok := c.Request.BasicAuth()
+		if !ok {
+			abortWithStatusUnauthorized(c)
+
+			return
+		}
+
+		var b BatchTasksBody
+		if err := c.ShouldBindJSON(&b); err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParameterRequired,
+				Comment: "operations",
+				Error:   err.Error(),
+				fields:  fieldErrors(err),
+			})
+
+			return
+		}
+
+		if len(b.Operations) == 0 || len(b.Operations) > maxBatchOperations {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParameterRequired,
+				Comment: fmt.Sprintf("operations: min 1, max %d", maxBatchOperations),
+			})
+
+			return
+		}
+
+		operations, results, valid := batchTasksCreateOperations(b.Operations, func(taskID int) func() (int, error) {
+			return ifMatchTaskVersion(ctx, storage, username, password, taskID)
+		})
+
+		if !valid && b.Atomic {
+			for i := range results {
+				if results[i].Error == nil {
+					results[i] = batchTaskOperationResultFromErr(model.ErrOperationRolledBack, 0)
+				}
+			}
+
+			batchTasksWriteResults(c, http.StatusBadRequest, results)
+
+			return
+		}
+
+		// Only valid operations are sent, their indexes are kept to put the results in place.
+		var (
+			validOperations []model.TaskOperation
+			indexes         []int
+		)
+
+		for i, op := range operations {
+			if results[i].Error == nil {
+				validOperations = append(validOperations, op)
+				indexes = append(indexes, i)
+			}
+		}
+
+		opResults, err := storage.BatchTasks(ctx, username, security.SaltPassword(password), validOperations, b.Atomic)
+		if err != nil {
+			writeError(c, http.StatusInternalServerError, HTTPError{
+				Type:    typeInternalError,
+				Comment: "batch tasks",
+				Error:   err.Error(),
+			})
+
+			return
+		}
+
+		status := http.StatusOK
+
+		for i, res := range opResults {
+			results[indexes[i]] = batchTaskOperationResultFromErr(res.Err, res.TaskID)
+
+			if b.Atomic && res.Err != nil && !errors.Is(res.Err, model.ErrOperationRolledBack) {
+				status = results[indexes[i]].Status
+			}
+		}
+
+		if status == http.StatusOK {
+			for i, op := range operations {
+				if op.Op == model.TaskOperationCreate && results[i].Error == nil {
+					results[i].Status = http.StatusCreated
+				}
+			}
+		}
+
+		batchTasksWriteResults(c, status, results)
+	}
+}
+
+// batchTasksWriteResults a failed atomic batch is a Problem in /api/v2, of the first operation that failed.
+func batchTasksWriteResults(c *gin.Context, status int, results []BatchTaskOperationResult) {
+	if status < http.StatusBadRequest || !c.GetBool(contextKeyProblemDetails) {
+		c.JSON(status, BatchTasksResult{
+			Results: results,
+		})
+
+		return
+	}
+
+	httpError := HTTPError{
+		Type: typeOperationRolledBack,
+	}
+
+	for i, res := range results {
+		if res.Error != nil && res.Error.Type != typeOperationRolledBack {
+			httpError = HTTPError{
+				Type:    res.Error.Type,
+				Comment: fmt.Sprintf("operations[%d]: %s", i, res.Error.Comment),
+			}
+
+			break
+		}
+	}
+
+	problem := newProblem(c, status, httpError)
+	problem.Results = results
+
+	writeProblem(c, problem)
+}
+
+// batchTasksCreateOperations invalid operations get the error result, valid is false if there are any.
+// taskVersion the current version of the task for the ifMatch lists.
+func batchTasksCreateOperations(
+	bodyOperations []BatchTaskOperation, taskVersion func(taskID int) func() (int, error),
+) ([]model.TaskOperation, []BatchTaskOperationResult, bool) {
+	operations := make([]model.TaskOperation, len(bodyOperations))
+	results := make([]BatchTaskOperationResult, len(bodyOperations))
+	valid := true
+
+	for i, bodyOp := range bodyOperations {
+		op, res := batchTasksCreateOperation(bodyOp, taskVersion(bodyOp.TaskID))
+		if res != nil {
+			results[i] = *res
+			valid = false
+
+			continue
+		}
+
+		operations[i] = op
+	}
+
+	return operations, results, valid
+}
+
+func batchTasksCreateOperation(
+	b BatchTaskOperation, taskVersion func() (int, error),
+) (model.TaskOperation, *BatchTaskOperationResult) {
+	invalid := func(httpError HTTPError) (model.TaskOperation, *BatchTaskOperationResult) {
+		return model.TaskOperation{}, &BatchTaskOperationResult{
+			Status: http.StatusBadRequest,
+			TaskID: b.TaskID,
+			Error:  &httpError,
+		}
+	}
+
+	if b.Title != nil && *b.Title == "" {
+		return invalid(HTTPError{
+			Type:    typeParameterRequired,
+			Comment: "title",
+		})
+	}
+
+	if b.Title != nil && utf8.RuneCountInString(*b.Title) > maxLengthTaskTitle {
+		return invalid(HTTPError{
+			Type:    typeParameterTooLong,
+			Comment: fmt.Sprintf("title: max %d", maxLengthTaskTitle),
+		})
+	}
+
+	match, ok := parseIfMatch(b.IfMatch)
+	if !ok {
+		return invalid(HTTPError{
+			Type:    typeTaskVersionMismatch,
+			Comment: "ifMatch",
+		})
+	}
+
+	op := model.TaskOperation{
+		Op:        b.Op,
+		TaskID:    b.TaskID,
+		Permanent: b.Permanent,
+	}
+
+	switch b.Op {
+	case model.TaskOperationCreate:
+		if b.Title == nil {
+			return invalid(HTTPError{
+				Type:    typeParameterRequired,
+				Comment: "title",
+			})
+		}
+
+		op.Title = *b.Title
+
+		return op, nil
+
+	case model.TaskOperationUpdate:
+		if b.Title == nil && b.Completed == nil {
+			return invalid(HTTPError{
+				Type:    typeParametersRequired,
+				Comment: "title or completed",
+			})
+		}
+
+		op.Update = model.TaskUpdate{
+			Title:  b.Title,
+			Status: b.Completed,
+		}
+
+	case model.TaskOperationDelete:
+
+	default:
+		return invalid(HTTPError{
+			Type:    typeParameterRequired,
+			Comment: "op: create, update or delete",
+		})
+	}
+
+	if b.TaskID < 1 {
+		return invalid(HTTPError{
+			Type:    typeParameterRequired,
+			Comment: "taskId",
+		})
+	}
+
+	version, err := match.version(taskVersion)
+	if err != nil {
+		res := batchTaskOperationResultFromErr(err, b.TaskID)
+
+		return model.TaskOperation{}, &res
+	}
+
+	op.Version = version
+
+	return op, nil
+}
+
+func batchTaskOperationResultFromErr(err error, taskID int) BatchTaskOperationResult {
+	result := func(status int, httpError HTTPError) BatchTaskOperationResult {
+		return BatchTaskOperationResult{
+			Status: status,
+			TaskID: taskID,
+			Error:  &httpError,
+		}
+	}
+
+	switch {
+	case err == nil:
+		return BatchTaskOperationResult{
+			Status: http.StatusOK,
+			TaskID: taskID,
+		}
+
+	case errors.Is(err, model.ErrOperationRolledBack):
+		return result(http.StatusFailedDependency, HTTPError{
+			Type:    typeOperationRolledBack,
+			Comment: "another operation failed",
+		})
+
+	case errors.Is(err, model.ErrUserNotFound):
+		return result(http.StatusForbidden, HTTPError{
+			Type: typeUserNotFound,
+		})
+
+	case errors.Is(err, model.ErrTaskAlreadyExists):
+		return result(http.StatusBadRequest, HTTPError{
+			Type:    typeTaskAlreadyExists,
+			Comment: "duplicate task",
+		})
+
+	case errors.Is(err, model.ErrTaskNotFound):
+		return result(http.StatusBadRequest, HTTPError{
+			Type:    typeTaskNotFound,
+			Comment: strconv.Itoa(taskID),
+		})
+
+	case errors.Is(err, model.ErrVersionMismatch):
+		return result(http.StatusPreconditionFailed, HTTPError{
+			Type:    typeTaskVersionMismatch,
+			Comment: strconv.Itoa(taskID),
+		})
+	}
+
+	return result(http.StatusInternalServerError, HTTPError{
+		Type:    typeInternalError,
+		Comment: "operation",
+		Error:   err.Error(),
+	})
+}
@@ -9,21 +9,27 @@ import (
 	"taskmanager/internal/security"
 )
 
-type deleteTasksResult struct {
+type deleteTasksQuery struct {
+	Permanent bool `form:"permanent" example:"true"`
+}
+
+type DeleteTasksResult struct {
 	Quantity int64 `json:"quantity"`
 }
 
 // V1DeleteTasks
 //
-// @Summary delete tasks
+// @Summary delete tasks(move to the trash)
 // @Tags tasks
 // @Accept json
 // @Produce json
-// @Success 200 {object} deleteTasksResult
+// @Param permanent query bool false "delete without moving to the trash, the trash is also emptied"
+// @Success 200 {object} DeleteTasksResult
+// @Failure 400 {object} HTTPError "error type, comment"
 // @Failure 401 {object} nil
 // @Failure 500 {object} HTTPError "error type, comment"
 // @Router /v1/tasks [delete]
-func V1DeleteTasks(ctx context.Context, postgres PostgresDB) gin.HandlerFunc {
+func V1DeleteTasks(ctx context.Context, storage TaskStorage) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		username, password, ok := c.Request.BasicAuth()
 		if !ok {
@@ -32,9 +38,26 @@ func V1DeleteTasks(ctx context.Context, postgres PostgresDB) gin.HandlerFunc {
 			return
 		}
 
-		quantity, err := postgres.DeleteTasks(ctx, username, security.SaltPassword(password))
+		var q deleteTasksQuery
+		if err := c.ShouldBindQuery(&q); err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParameterRequired,
+				Comment: "permanent",
+				Error:   err.Error(),
+				fields:  fieldErrors(err),
+			})
+
+			return
+		}
+
+		deleteTasks := storage.DeleteTasks
+		if q.Permanent {
+			deleteTasks = storage.DeleteTasksPermanently
+		}
+
+		taskIDs, err := deleteTasks(ctx, username, security.SaltPassword(password))
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, HTTPError{
+			writeError(c, http.StatusInternalServerError, HTTPError{
 				Type:    typeInternalError,
 				Comment: "delete task",
 				Error:   err.Error(),
@@ -43,8 +66,8 @@ func V1DeleteTasks(ctx context.Context, postgres PostgresDB) gin.HandlerFunc {
 			return
 		}
 
-		c.JSON(http.StatusOK, deleteTasksResult{
-			Quantity: quantity,
+		c.JSON(http.StatusOK, DeleteTasksResult{
+			Quantity: int64(len(taskIDs)),
 		})
 	}
 }
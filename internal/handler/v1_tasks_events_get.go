@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	mimeEventStream   = "text/event-stream"
+	headerLastEventID = "Last-Event-ID"
+)
+
+// maxEventsPerRead the events read from the log at once.
+const maxEventsPerRead = 100
+
+// eventsRetryMilliseconds the delay of the reconnection of EventSource.
+const eventsRetryMilliseconds = 3000
+
+type TaskEventsQuery struct {
+	// LastEventID for the clients that cannot set Last-Event-ID, the header has priority.
+	LastEventID string `form:"lastEventId" example:"42"`
+}
+
+// V1GetTaskEvents Server-Sent Events of the changes of the tasks of the user:
+//
+//	id: 42
+//	event: task.created
+//	data: {"id":"3b1f...","event":"task.created","created":"...","userId":1,"taskId":24,"task":{...}}
+//
+// Without Last-Event-ID only the new events are sent, with it - the events after it from the event log,
+// then the new ones. The comment ": heartbeat" is sent when there are no events.
+//
+// @Summary stream task events
+// @Tags tasks
+// @Produce text/event-stream
+// @Param Last-Event-ID header int false "the id of the last received event"
+// @Param lastEventId query int false "Last-Event-ID for the clients that cannot set it"
+// @Success 200 {object} model.Event "the stream of the events"
+// @Failure 400 {object} HTTPError "error type, comment"
+// @Failure 401 {object} nil
+// @Failure 403 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/tasks/events [get]
+func V1GetTaskEvents(
+	ctx context.Context, storage EventStorage, subscriber EventSubscriber, heartbeat time.Duration,
+) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := authorizedUserID(ctx, c, storage)
+		if !ok {
+			return
+		}
+
+		lastEventID, resume, ok := bindLastEventID(c)
+		if !ok {
+			return
+		}
+
+		// Before reading the log, so that the events saved meanwhile wake the stream.
+		var notify <-chan struct{}
+
+		if subscriber != nil {
+			ch, unsubscribe := subscriber.Subscribe(userID)
+			defer unsubscribe()
+
+			notify = ch
+		}
+
+		lastSeq := lastEventID
+
+		if !resume {
+			var err error
+
+			if lastSeq, err = storage.GetLastEventSeq(ctx, userID); err != nil {
+				writeError(c, http.StatusInternalServerError, HTTPError{
+					Type:    typeInternalError,
+					Comment: "get last event",
+					Error:   err.Error(),
+				})
+
+				return
+			}
+		}
+
+		// The stream is longer than the write timeout of the server.
+		_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+		c.Header("Content-Type", mimeEventStream)
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		// Without buffering by nginx.
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(http.StatusOK)
+
+		if _, err := fmt.Fprintf(c.Writer, "retry: %d\n\n", eventsRetryMilliseconds); err != nil {
+			return
+		}
+
+		c.Writer.Flush()
+
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+
+		for {
+			var err error
+
+			// The client reconnects with Last-Event-ID after an error.
+			if lastSeq, err = writeEvents(ctx, c.Writer, storage, userID, lastSeq); err != nil {
+				return
+			}
+
+			c.Writer.Flush()
+
+			select {
+			case <-ctx.Done():
+				return
+
+			case <-c.Request.Context().Done():
+				return
+
+			case <-notify:
+
+			// The log is also read on the heartbeats in case a notification is lost.
+			case <-ticker.C:
+				if _, err := io.WriteString(c.Writer, ": heartbeat\n\n"); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// bindLastEventID resume false - there is no Last-Event-ID, ok false - the error is written.
+func bindLastEventID(c *gin.Context) (int64, bool, bool) {
+	var q TaskEventsQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		writeError(c, http.StatusBadRequest, HTTPError{
+			Type:    typeParameterRequired,
+			Comment: "lastEventId",
+			Error:   err.Error(),
+			fields:  fieldErrors(err),
+		})
+
+		return 0, false, false
+	}
+
+	lastEventID := c.GetHeader(headerLastEventID)
+	if lastEventID == "" {
+		lastEventID = q.LastEventID
+	}
+
+	if lastEventID == "" {
+		return 0, false, true
+	}
+
+	seq, err := strconv.ParseInt(lastEventID, 10, 64)
+	if err != nil || seq < 0 {
+		writeError(c, http.StatusBadRequest, HTTPError{
+			Type:    typeParameterRequired,
+			Comment: "Last-Event-ID: integer >= 0",
+			fields:  []FieldError{{Field: "lastEventId", Message: "gte=0"}},
+		})
+
+		return 0, false, false
+	}
+
+	return seq, true, true
+}
+
+// writeEvents of the user after the position in the log, returns the position of the last written event.
+func writeEvents(ctx context.Context, w io.Writer, storage EventStorage, userID int, afterSeq int64) (int64, error) {
+	for {
+		events, err := storage.GetEvents(ctx, userID, afterSeq, maxEventsPerRead)
+		if err != nil {
+			return afterSeq, fmt.Errorf("get events: %w", err)
+		}
+
+		for _, event := range events {
+			data, err := json.Marshal(event)
+			if err != nil {
+				return afterSeq, fmt.Errorf("marshal event: %w", err)
+			}
+
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Seq, event.Type, data); err != nil {
+				return afterSeq, fmt.Errorf("write event: %w", err)
+			}
+
+			afterSeq = event.Seq
+		}
+
+		if len(events) < maxEventsPerRead {
+			return afterSeq, nil
+		}
+	}
+}
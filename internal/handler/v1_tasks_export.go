@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/security"
+)
+
+type TaskExportStorage interface {
+	TaskStorage
+	CredentialsStorage
+}
+
+type TaskExportQuery struct {
+	Format string `form:"format" binding:"omitempty,oneof=json csv md todotxt" example:"csv"`
+}
+
+// V1ExportTasks all tasks of the user including the trash, sorted by id. The tasks are written as they are read
+// from the storage, if the storage fails after the first task the response is truncated and the error is logged.
+//
+// The columns of csv and md, the keys of json: id, status, title, created, updated, completed, deleted.
+// todo.txt: "x 2023-05-02 2023-05-01 buy milk id:24 deleted:2023-05-03".
+//
+// @Summary export tasks
+// @Tags tasks
+// @Produce json
+// @Produce text/csv
+// @Produce text/markdown
+// @Produce text/plain
+// @Param format query string false "json(default), csv, md, todotxt"
+// @Success 200 {object} []model.ExportedTask
+// @Failure 400 {object} HTTPError "error type, comment"
+// @Failure 401 {object} nil
+// @Failure 403 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/tasks/export [get]
+func V1ExportTasks(ctx context.Context, storage TaskExportStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := authorizedUserID(ctx, c, storage); !ok {
+			return
+		}
+
+		var q TaskExportQuery
+		if err := c.ShouldBindQuery(&q); err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParameterRequired,
+				Comment: "format: json, csv, md, todotxt",
+				Error:   err.Error(),
+				fields:  fieldErrors(err),
+			})
+
+			return
+		}
+
+		if q.Format == "" {
+			q.Format = taskFormatJSON
+		}
+
+		format := taskFormats[q.Format]
+		encoder := format.newEncoder(c.Writer)
+
+		// The response starts with the first task, so the error of the query is still written as the error.
+		started := false
+
+		start := func() error {
+			started = true
+
+			c.Header("Content-Type", format.contentType)
+			c.Header("Content-Disposition", `attachment; filename="tasks.`+exportExtension(q.Format)+`"`)
+			c.Status(http.StatusOK)
+
+			return encoder.begin()
+		}
+
+		username, password, _ := c.Request.BasicAuth()
+
+		err := storage.ExportTasks(ctx, username, security.SaltPassword(password), func(task model.ExportedTask) error {
+			if !started {
+				if err := start(); err != nil {
+					return err
+				}
+			}
+
+			return encoder.encode(task)
+		})
+		if err == nil && !started {
+			err = start()
+		}
+
+		if err == nil {
+			err = encoder.end()
+		}
+
+		if err != nil {
+			if !started {
+				writeError(c, http.StatusInternalServerError, HTTPError{
+					Type:    typeInternalError,
+					Comment: "export tasks",
+					Error:   err.Error(),
+				})
+
+				return
+			}
+
+			_ = c.Error(err)
+		}
+	}
+}
+
+func exportExtension(format string) string {
+	if format == taskFormatTodoTxt {
+		return "txt"
+	}
+
+	return format
+}
@@ -15,11 +15,13 @@ import (
 // @Tags tasks
 // @Accept json
 // @Produce json
-// @Success 200 {object} []model.Task
+// @Param If-None-Match header string false "ETag of the cached list"
+// @Success 200 {object} []TaskResponse
+// @Success 304 {object} nil
 // @Failure 401 {object} nil
 // @Failure 500 {object} HTTPError "error type, comment"
 // @Router /v1/tasks [get]
-func V1GetTasks(ctx context.Context, postgres PostgresDB) gin.HandlerFunc {
+func V1GetTasks(ctx context.Context, storage TaskStorage) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		username, password, ok := c.Request.BasicAuth()
 		if !ok {
@@ -28,9 +30,9 @@ func V1GetTasks(ctx context.Context, postgres PostgresDB) gin.HandlerFunc {
 			return
 		}
 
-		tasks, err := postgres.GetTasks(ctx, username, security.SaltPassword(password))
+		tasks, err := storage.GetTasks(ctx, username, security.SaltPassword(password))
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, HTTPError{
+			writeError(c, http.StatusInternalServerError, HTTPError{
 				Type:    typeInternalError,
 				Comment: "get tasks",
 				Error:   err.Error(),
@@ -39,6 +41,25 @@ func V1GetTasks(ctx context.Context, postgres PostgresDB) gin.HandlerFunc {
 			return
 		}
 
-		c.JSON(http.StatusOK, tasks)
+		etag := tasksETag(tasks)
+
+		c.Header(headerETag, etag)
+
+		if matchIfNoneMatch(c.GetHeader(headerIfNoneMatch), etag) {
+			c.Status(http.StatusNotModified)
+
+			return
+		}
+
+		res := make([]TaskResponse, len(tasks))
+
+		for i, task := range tasks {
+			res[i] = TaskResponse{
+				Task: task,
+				ETag: taskETag(task.Version),
+			}
+		}
+
+		c.JSON(http.StatusOK, res)
 	}
 }
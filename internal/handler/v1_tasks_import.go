@@ -0,0 +1,322 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/security"
+)
+
+const (
+	maxImportTasks = 10000
+	// maxImportBytes the body of the import.
+	maxImportBytes = 10 << 20
+)
+
+// The statuses of the rows of the import.
+const (
+	ImportStatusCreated = "created"
+	// ImportStatusValid dry run: the task would be created.
+	ImportStatusValid     = "valid"
+	ImportStatusDuplicate = "duplicate"
+	ImportStatusInvalid   = "invalid"
+	// ImportStatusFailed the storage failed to create the task.
+	ImportStatusFailed = "failed"
+)
+
+type TaskImportStorage interface {
+	TaskStorage
+	CredentialsStorage
+}
+
+type TaskImportQuery struct {
+	Format string `form:"format" binding:"omitempty,oneof=json csv md todotxt" example:"csv"`
+	DryRun bool   `form:"dryRun" example:"true"`
+}
+
+type ImportTasksResult struct {
+	DryRun     bool `json:"dryRun" example:"false"`
+	Created    int  `json:"created" example:"2"`
+	Duplicates int  `json:"duplicates" example:"1"`
+	Failed     int  `json:"failed" example:"1"`
+	// Rows all rows of the file in order.
+	Rows []ImportTaskRow `json:"rows"`
+}
+
+type ImportTaskRow struct {
+	Row    int        `json:"row" example:"3"`
+	Title  string     `json:"title,omitempty" example:"some title"`
+	Status string     `json:"status" example:"created"`
+	TaskID int        `json:"taskId,omitempty" example:"24"`
+	Error  *HTTPError `json:"error,omitempty"`
+}
+
+// V1ImportTasks the tasks of the export or of another tool. The columns title, status, deleted are mapped with
+// columns[title]=Name; a task with deleted goes to the trash, the times of the file are not kept.
+// The duplicates - the tasks with the title of a task of the user, including the trash, or of a previous row - are
+// not created. The invalid rows do not stop the import, the other rows are created.
+//
+// @Summary import tasks
+// @Tags tasks
+// @Accept json
+// @Accept text/csv
+// @Accept text/markdown
+// @Accept text/plain
+// @Produce json
+// @Param format query string false "json(default), csv, md, todotxt"
+// @Param dryRun query bool false "only validate, nothing is created"
+// @Param columns[title] query string false "the column of the title, the same for status and deleted"
+// @Param data body string true "the file, max 10000 tasks, 10 MiB; title - max 200"
+// @Success 200 {object} ImportTasksResult "the status of each row"
+// @Failure 400 {object} HTTPError "error type, comment"
+// @Failure 401 {object} nil
+// @Failure 403 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/tasks/import [post]
+func V1ImportTasks(ctx context.Context, storage TaskImportStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := authorizedUserID(ctx, c, storage); !ok {
+			return
+		}
+
+		var q TaskImportQuery
+		if err := c.ShouldBindQuery(&q); err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParameterRequired,
+				Comment: "format: json, csv, md, todotxt; dryRun",
+				Error:   err.Error(),
+				fields:  fieldErrors(err),
+			})
+
+			return
+		}
+
+		if q.Format == "" {
+			q.Format = taskFormatJSON
+		}
+
+		columns := c.QueryMap("columns")
+		for column := range columns {
+			if column != importColumnTitle && column != importColumnStatus && column != importColumnDeleted {
+				writeError(c, http.StatusBadRequest, HTTPError{
+					Type:    typeParameterRequired,
+					Comment: "columns: title, status, deleted",
+				})
+
+				return
+			}
+		}
+
+		username, password, _ := c.Request.BasicAuth()
+		password = security.SaltPassword(password)
+
+		// The titles of the tasks of the user, the task id of the duplicates.
+		existing := make(map[string]int)
+
+		if err := storage.ExportTasks(ctx, username, password, func(task model.ExportedTask) error {
+			existing[task.Title] = task.ID
+
+			return nil
+		}); err != nil {
+			writeError(c, http.StatusInternalServerError, HTTPError{
+				Type:    typeInternalError,
+				Comment: "get tasks",
+				Error:   err.Error(),
+			})
+
+			return
+		}
+
+		tasks, rows, err := importTasksDecode(c, q.Format, columns, existing)
+		if err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeImportInvalid,
+				Comment: err.Error(),
+				Error:   err.Error(),
+			})
+
+			return
+		}
+
+		res := ImportTasksResult{
+			DryRun: q.DryRun,
+			Rows:   rows,
+		}
+
+		if !q.DryRun {
+			if err := importTasksCreate(ctx, storage, username, password, tasks, res.Rows); err != nil {
+				writeError(c, http.StatusInternalServerError, HTTPError{
+					Type:    typeInternalError,
+					Comment: "import tasks",
+					Error:   err.Error(),
+				})
+
+				return
+			}
+		}
+
+		for _, row := range res.Rows {
+			switch row.Status {
+			case ImportStatusCreated, ImportStatusValid:
+				res.Created++
+
+			case ImportStatusDuplicate:
+				res.Duplicates++
+
+			default:
+				res.Failed++
+			}
+		}
+
+		c.JSON(http.StatusOK, res)
+	}
+}
+
+// importTasksDecode the valid tasks to create and the rows of all tasks, the valid ones are ImportStatusValid.
+func importTasksDecode(
+	c *gin.Context, format string, columns map[string]string, existing map[string]int,
+) ([]importedTask, []ImportTaskRow, error) {
+	var (
+		tasks []importedTask
+		rows  []ImportTaskRow
+		// firstRows the rows of the titles of the file.
+		firstRows = make(map[string]int)
+	)
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+
+	err := decodeTasks(format, body, columns, func(task importedTask) error {
+		if task.row > maxImportTasks {
+			return fmt.Errorf("%w: max %d tasks", errImportInvalid, maxImportTasks)
+		}
+
+		row := ImportTaskRow{
+			Row:    task.row,
+			Title:  task.title,
+			Status: ImportStatusValid,
+			Error:  task.err,
+		}
+
+		switch {
+		case task.err != nil:
+			row.Status = ImportStatusInvalid
+
+		case existing[task.title] != 0:
+			row.Status = ImportStatusDuplicate
+			row.TaskID = existing[task.title]
+			row.Error = &HTTPError{Type: typeTaskAlreadyExists, Comment: "task " + strconv.Itoa(row.TaskID)}
+
+		case firstRows[task.title] != 0:
+			row.Status = ImportStatusDuplicate
+			row.Error = &HTTPError{Type: typeTaskAlreadyExists, Comment: "row " + strconv.Itoa(firstRows[task.title])}
+
+		default:
+			firstRows[task.title] = task.row
+			tasks = append(tasks, task)
+		}
+
+		rows = append(rows, row)
+
+		return nil
+	})
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return nil, nil, fmt.Errorf("%w: max %d bytes", errImportInvalid, maxImportBytes)
+		}
+
+		return nil, nil, err
+	}
+
+	return tasks, rows, nil
+}
+
+// importTasksCreate the tasks are created by batches of maxBatchOperations, each task separately, then the status,
+// the due date, the recurrence and the trash of the created ones. rows get the results.
+func importTasksCreate(
+	ctx context.Context, storage TaskStorage, username, password string, tasks []importedTask, rows []ImportTaskRow,
+) error {
+	for start := 0; start < len(tasks); start += maxBatchOperations {
+		end := start + maxBatchOperations
+		if end > len(tasks) {
+			end = len(tasks)
+		}
+
+		batch := tasks[start:end]
+
+		operations := make([]model.TaskOperation, len(batch))
+		for i, task := range batch {
+			operations[i] = model.TaskOperation{
+				Op:    model.TaskOperationCreate,
+				Title: task.title,
+			}
+		}
+
+		results, err := storage.BatchTasks(ctx, username, password, operations, false)
+		if err != nil {
+			return fmt.Errorf("create tasks: %w", err)
+		}
+
+		// The operations of the created tasks and their rows.
+		var (
+			changes    []model.TaskOperation
+			changeRows []*ImportTaskRow
+		)
+
+		for i, res := range results {
+			row := &rows[batch[i].row-1]
+
+			if res.Err != nil {
+				row.Status = ImportStatusFailed
+				row.Error = batchTaskOperationResultFromErr(res.Err, 0).Error
+
+				continue
+			}
+
+			row.Status = ImportStatusCreated
+			row.TaskID = res.TaskID
+
+			if update, ok := batch[i].update(); ok {
+				changes = append(changes, model.TaskOperation{
+					Op:     model.TaskOperationUpdate,
+					TaskID: res.TaskID,
+					Update: update,
+				})
+				changeRows = append(changeRows, row)
+			}
+
+			if batch[i].deleted {
+				changes = append(changes, model.TaskOperation{
+					Op:     model.TaskOperationDelete,
+					TaskID: res.TaskID,
+				})
+				changeRows = append(changeRows, row)
+			}
+		}
+
+		if len(changes) == 0 {
+			continue
+		}
+
+		results, err = storage.BatchTasks(ctx, username, password, changes, false)
+		if err != nil {
+			return fmt.Errorf("update tasks: %w", err)
+		}
+
+		// The task is created, the row keeps its id.
+		for i, res := range results {
+			if res.Err != nil {
+				changeRows[i].Status = ImportStatusFailed
+				changeRows[i].Error = batchTaskOperationResultFromErr(res.Err, changeRows[i].TaskID).Error
+			}
+		}
+	}
+
+	return nil
+}
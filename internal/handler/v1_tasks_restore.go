@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"taskmanager/internal/security"
+)
+
+type RestoreTasksResult struct {
+	Quantity int64 `json:"quantity"`
+}
+
+// V1RestoreTasks
+//
+// @Summary restore all deleted tasks
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Param Idempotency-Key header string false "retries with the same key get the stored response"
+// @Success 200 {object} RestoreTasksResult
+// @Failure 401 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/tasks/trash/restore [post]
+func V1RestoreTasks(ctx context.Context, storage TaskStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username, password, ok := c.Request.BasicAuth()
+		if !ok {
+			abortWithStatusUnauthorized(c)
+
+			return
+		}
+
+		taskIDs, err := storage.RestoreTasks(ctx, username, security.SaltPassword(password))
+		if err != nil {
+			writeError(c, http.StatusInternalServerError, HTTPError{
+				Type:    typeInternalError,
+				Comment: "restore tasks",
+				Error:   err.Error(),
+			})
+
+			return
+		}
+
+		c.JSON(http.StatusOK, RestoreTasksResult{
+			Quantity: int64(len(taskIDs)),
+		})
+	}
+}
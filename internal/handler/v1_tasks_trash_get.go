@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"taskmanager/internal/security"
+)
+
+// V1GetTrash
+//
+// @Summary get deleted tasks
+// @Tags tasks
+// @Accept json
+// @Produce json
+// @Success 200 {object} []model.DeletedTask
+// @Failure 401 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/tasks/trash [get]
+func V1GetTrash(ctx context.Context, storage TaskStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username, password, ok := c.Request.BasicAuth()
+		if !ok {
+			abortWithStatusUnauthorized(c)
+
+			return
+		}
+
+		tasks, err := storage.GetTrash(ctx, username, security.SaltPassword(password))
+		if err != nil {
+			writeError(c, http.StatusInternalServerError, HTTPError{
+				Type:    typeInternalError,
+				Comment: "get trash",
+				Error:   err.Error(),
+			})
+
+			return
+		}
+
+		c.JSON(http.StatusOK, tasks)
+	}
+}
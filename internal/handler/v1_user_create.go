@@ -22,12 +22,14 @@ const (
 	maxLengthPassword = 20
 )
 
-type createUserBody struct {
+var errInvalidUser = errors.New("invalid user")
+
+type CreateUserBody struct {
 	Username string `json:"username" binding:"required" example:"newuser"`
 	Password string `json:"password" binding:"required" example:"newuser"`
 }
 
-type createUserResult struct {
+type CreateUserResult struct {
 	UserID int `json:"userId"`
 }
 
@@ -37,20 +39,22 @@ type createUserResult struct {
 // @Tags management
 // @Accept json
 // @Produce json
-// @Param data body createUserBody true "manage auth - admin:admin; username(3-20); password(5-20)"
-// @Success 201 {object} createUserResult "userId"
+// @Param data body CreateUserBody true "manage auth - admin:admin; username(3-20); password(5-20)"
+// @Param Idempotency-Key header string false "retries with the same key get the stored response"
+// @Success 201 {object} CreateUserResult "userId"
 // @Failure 400 {object} HTTPError "error type, comment"
 // @Failure 401 {object} nil
 // @Failure 500 {object} HTTPError "error type, comment"
 // @Router /v1/manage/user [post]
-func V1CreateUser(ctx context.Context, postgres PostgresDB) gin.HandlerFunc {
+func V1CreateUser(ctx context.Context, storage UserStorage) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var b createUserBody
+		var b CreateUserBody
 		if err := c.ShouldBindJSON(&b); err != nil {
-			c.JSON(http.StatusBadRequest, HTTPError{
+			writeError(c, http.StatusBadRequest, HTTPError{
 				Type:    typeParametersRequired,
 				Comment: "username and password required",
 				Error:   err.Error(),
+				fields:  fieldErrors(err),
 			})
 
 			return
@@ -58,15 +62,15 @@ func V1CreateUser(ctx context.Context, postgres PostgresDB) gin.HandlerFunc {
 
 		res := checkUsernamePassword(b.Username, b.Password)
 		if res != nil {
-			c.JSON(http.StatusBadRequest, res)
+			writeError(c, http.StatusBadRequest, *res)
 
 			return
 		}
 
-		userID, err := postgres.CreateNewUser(ctx, b.Username, security.SaltPassword(b.Password))
+		userID, err := storage.CreateNewUser(ctx, b.Username, security.SaltPassword(b.Password))
 		if err != nil {
 			if errors.Is(err, model.ErrUserAlreadyExists) {
-				c.JSON(http.StatusBadRequest, HTTPError{
+				writeError(c, http.StatusBadRequest, HTTPError{
 					Type:    typeUsernameAlreadyExists,
 					Comment: b.Username,
 					Error:   err.Error(),
@@ -75,7 +79,7 @@ func V1CreateUser(ctx context.Context, postgres PostgresDB) gin.HandlerFunc {
 				return
 			}
 
-			c.JSON(http.StatusInternalServerError, HTTPError{
+			writeError(c, http.StatusInternalServerError, HTTPError{
 				Type:    typeInternalError,
 				Comment: "create new user",
 				Error:   err.Error(),
@@ -84,12 +88,30 @@ func V1CreateUser(ctx context.Context, postgres PostgresDB) gin.HandlerFunc {
 			return
 		}
 
-		c.JSON(http.StatusCreated, createUserResult{
+		c.JSON(http.StatusCreated, CreateUserResult{
 			UserID: userID,
 		})
 	}
 }
 
+// ValidateUsernamePassword the rules of the manage API, for the command line.
+func ValidateUsernamePassword(username, password string) error {
+	if res := checkUsernamePassword(username, password); res != nil {
+		return fmt.Errorf("%w: %s: %s", errInvalidUser, res.Type, res.Comment)
+	}
+
+	return nil
+}
+
+// ValidatePassword the rules of the manage API, for the command line.
+func ValidatePassword(password string) error {
+	if res := checkPassword(password); res != nil {
+		return fmt.Errorf("%w: %s: %s", errInvalidUser, res.Type, res.Comment)
+	}
+
+	return nil
+}
+
 func checkUsernamePassword(username, password string) *HTTPError {
 	res := checkUsername(username)
 
@@ -119,13 +141,16 @@ func checkUsername(username string) *HTTPError {
 		return &HTTPError{
 			Type:    typeUsernameRequired,
 			Comment: "only letters and numbers required",
+			fields:  []FieldError{{Field: "username", Message: "alphanum"}},
 		}
 	}
 
 	if count < minLengthUsername || count > maxLengthUsername {
 		return &HTTPError{
-			Comment: typeUsernameRequired,
-			Type:    fmt.Sprintf("min %d, max %d", minLengthUsername, maxLengthUsername),
+			Type:        typeUsernameRequired,
+			Comment:     fmt.Sprintf("min %d, max %d", minLengthUsername, maxLengthUsername),
+			fields:      []FieldError{{Field: "username", Message: "length"}},
+			swappedInV1: true,
 		}
 	}
 
@@ -147,6 +172,7 @@ func checkPassword(password string) *HTTPError {
 		return &HTTPError{
 			Type:    typePasswordRequired,
 			Comment: fmt.Sprintf("only letters, numbers or %s required", allowedSpecialChars),
+			fields:  []FieldError{{Field: "password", Message: "charset"}},
 		}
 	}
 
@@ -154,6 +180,7 @@ func checkPassword(password string) *HTTPError {
 		return &HTTPError{
 			Type:    typePasswordRequired,
 			Comment: fmt.Sprintf("min %d, max %d", minLengthPassword, maxLengthPassword),
+			fields:  []FieldError{{Field: "password", Message: "length"}},
 		}
 	}
 
@@ -26,22 +26,23 @@ type deleteUserURI struct {
 // @Failure 401 {object} nil
 // @Failure 500 {object} HTTPError "error type, comment"
 // @Router /v1/manage/user/{userId} [delete]
-func V1DeleteUser(ctx context.Context, postgres PostgresDB) gin.HandlerFunc {
+func V1DeleteUser(ctx context.Context, storage UserStorage) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var u deleteUserURI
 		if err := c.ShouldBindUri(&u); err != nil {
-			c.JSON(http.StatusBadRequest, HTTPError{
+			writeError(c, http.StatusBadRequest, HTTPError{
 				Type:    typeParameterRequired,
 				Comment: "userId",
 				Error:   err.Error(),
+				fields:  fieldErrors(err),
 			})
 
 			return
 		}
 
-		if err := postgres.DeleteUser(ctx, u.UserID); err != nil {
+		if err := storage.DeleteUser(ctx, u.UserID); err != nil {
 			if errors.Is(err, model.ErrUserNotFound) {
-				c.JSON(http.StatusBadRequest, HTTPError{
+				writeError(c, http.StatusBadRequest, HTTPError{
 					Type:    typeUserNotFound,
 					Comment: "userId",
 					Error:   err.Error(),
@@ -50,7 +51,7 @@ func V1DeleteUser(ctx context.Context, postgres PostgresDB) gin.HandlerFunc {
 				return
 			}
 
-			c.JSON(http.StatusInternalServerError, HTTPError{
+			writeError(c, http.StatusInternalServerError, HTTPError{
 				Type:    typeInternalError,
 				Comment: "delete user",
 				Error:   err.Error(),
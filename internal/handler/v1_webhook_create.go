@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/security"
+)
+
+const maxLengthWebhookURL = 2000
+
+type CreateWebhookBody struct {
+	URL    string   `json:"url" binding:"required,url" example:"https://example.com/hooks/tasks"`
+	Events []string `json:"events" binding:"required,min=1,dive,oneof=task.created task.updated task.completed task.deleted task.restored task.reminder user.deleted" example:"task.created,task.completed"`
+	// Secret empty - generated.
+	Secret string `json:"secret,omitempty" binding:"omitempty,min=16,max=128" example:""`
+}
+
+// WebhookConf of V1CreateWebhook.
+type WebhookConf struct {
+	// AllowPrivateNetworks the webhooks may target the loopback, private and link-local addresses: the server and
+	// its network. Only for the tests and the networks without other services.
+	AllowPrivateNetworks bool
+}
+
+type CreateWebhookResult struct {
+	WebhookID int `json:"webhookId"`
+	// Secret the key of the signature of the deliveries, it is returned only here.
+	Secret string `json:"secret"`
+}
+
+// V1CreateWebhook the deliveries are POST requests with the event in the body, signed with the secret:
+// X-Webhook-Signature: t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<body>">.
+// The webhooks of the manager get the events of all users, user.deleted only them.
+//
+// @Summary create webhook
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param data body CreateWebhookBody true "url - http or https, a public host; events - task.created, task.updated, task.completed, task.deleted, task.restored, task.reminder, user.deleted(manage); secret - generated if empty"
+// @Success 201 {object} CreateWebhookResult "webhookId, secret"
+// @Failure 400 {object} HTTPError "error type, comment"
+// @Failure 401 {object} nil
+// @Failure 403 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/webhooks [post]
+// @Router /v1/manage/webhooks [post]
+func V1CreateWebhook(ctx context.Context, storage WebhookStorage, conf WebhookConf) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := webhookOwner(ctx, c, storage)
+		if !ok {
+			return
+		}
+
+		var b CreateWebhookBody
+		if err := c.ShouldBindJSON(&b); err != nil {
+			writeError(c, http.StatusBadRequest, HTTPError{
+				Type:    typeParametersRequired,
+				Comment: "url and events required",
+				Error:   err.Error(),
+				fields:  fieldErrors(err),
+			})
+
+			return
+		}
+
+		if res := checkWebhook(userID, b); res != nil {
+			writeError(c, http.StatusBadRequest, *res)
+
+			return
+		}
+
+		if res := conf.checkWebhookHost(ctx, b.URL); res != nil {
+			writeError(c, http.StatusBadRequest, *res)
+
+			return
+		}
+
+		secret := b.Secret
+		if secret == "" {
+			var err error
+
+			if secret, err = newWebhookSecret(); err != nil {
+				writeError(c, http.StatusInternalServerError, HTTPError{
+					Type:    typeInternalError,
+					Comment: "create webhook",
+					Error:   err.Error(),
+				})
+
+				return
+			}
+		}
+
+		webhookID, err := storage.CreateWebhook(ctx, userID, model.Webhook{
+			URL:    b.URL,
+			Events: b.Events,
+			Secret: secret,
+		})
+		if err != nil {
+			writeError(c, http.StatusInternalServerError, HTTPError{
+				Type:    typeInternalError,
+				Comment: "create webhook",
+				Error:   err.Error(),
+			})
+
+			return
+		}
+
+		c.JSON(http.StatusCreated, CreateWebhookResult{
+			WebhookID: webhookID,
+			Secret:    secret,
+		})
+	}
+}
+
+func checkWebhook(userID int, b CreateWebhookBody) *HTTPError {
+	u, err := url.Parse(b.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &HTTPError{
+			Type:    typeParameterRequired,
+			Comment: "url: http or https",
+			fields:  []FieldError{{Field: "url", Message: "url"}},
+		}
+	}
+
+	if len(b.URL) > maxLengthWebhookURL {
+		return &HTTPError{
+			Type:    typeParameterTooLong,
+			Comment: "url: max 2000",
+			fields:  []FieldError{{Field: "url", Message: "max=2000"}},
+		}
+	}
+
+	for _, event := range b.Events {
+		// The user is deleted with the webhooks.
+		if event == model.EventUserDeleted && userID != 0 {
+			return &HTTPError{
+				Type:    typeParameterRequired,
+				Comment: "events: user.deleted is for the manage webhooks",
+				fields:  []FieldError{{Field: "events", Message: "oneof"}},
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkWebhookHost the host must resolve to the public addresses only, the worker checks them again on each
+// connection.
+func (conf WebhookConf) checkWebhookHost(ctx context.Context, webhookURL string) *HTTPError {
+	if conf.AllowPrivateNetworks {
+		return nil
+	}
+
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return &HTTPError{
+			Type:    typeParameterRequired,
+			Comment: "url: http or https",
+			Error:   err.Error(),
+		}
+	}
+
+	if err := security.CheckPublicHost(ctx, u.Hostname()); err != nil {
+		comment := "url: the host is not resolved"
+		if errors.Is(err, security.ErrPrivateAddress) {
+			comment = "url: loopback, private and link-local addresses are not allowed"
+		}
+
+		return &HTTPError{
+			Type:    typeParameterRequired,
+			Comment: comment,
+			Error:   err.Error(),
+			fields:  []FieldError{{Field: "url", Message: "public"}},
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// V1DeleteWebhook with its deliveries.
+//
+// @Summary delete webhook
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhookId path int true "webhookId" minimum(1)
+// @Success 204 {object} nil
+// @Failure 400 {object} HTTPError "error type, comment"
+// @Failure 401 {object} nil
+// @Failure 403 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/webhooks/{webhookId} [delete]
+// @Router /v1/manage/webhooks/{webhookId} [delete]
+func V1DeleteWebhook(ctx context.Context, storage WebhookStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := webhookOwner(ctx, c, storage)
+		if !ok {
+			return
+		}
+
+		u, ok := bindWebhookURI(c)
+		if !ok {
+			return
+		}
+
+		if err := storage.DeleteWebhook(ctx, userID, u.WebhookID); err != nil {
+			webhookWriteError(c, u.WebhookID, "delete webhook", err)
+
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
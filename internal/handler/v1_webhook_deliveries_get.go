@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"taskmanager/internal/model"
+)
+
+const maxWebhookDeliveries = 100
+
+// V1GetWebhookDeliveries the delivery log, the last 100 deliveries.
+//
+// @Summary get webhook deliveries
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhookId path int true "webhookId" minimum(1)
+// @Success 200 {object} []model.WebhookDelivery "the newest first"
+// @Failure 400 {object} HTTPError "error type, comment"
+// @Failure 401 {object} nil
+// @Failure 403 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/webhooks/{webhookId}/deliveries [get]
+// @Router /v1/manage/webhooks/{webhookId}/deliveries [get]
+func V1GetWebhookDeliveries(ctx context.Context, storage WebhookStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := webhookOwner(ctx, c, storage)
+		if !ok {
+			return
+		}
+
+		u, ok := bindWebhookURI(c)
+		if !ok {
+			return
+		}
+
+		deliveries, err := storage.GetWebhookDeliveries(ctx, userID, u.WebhookID, maxWebhookDeliveries)
+		if err != nil {
+			webhookWriteError(c, u.WebhookID, "get webhook deliveries", err)
+
+			return
+		}
+
+		if deliveries == nil {
+			deliveries = []model.WebhookDelivery{}
+		}
+
+		c.JSON(http.StatusOK, deliveries)
+	}
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// V1EnableWebhook the webhook disabled after too many failed deliveries gets the events again.
+//
+// @Summary enable webhook
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhookId path int true "webhookId" minimum(1)
+// @Success 204 {object} nil
+// @Failure 400 {object} HTTPError "error type, comment"
+// @Failure 401 {object} nil
+// @Failure 403 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/webhooks/{webhookId}/enable [post]
+// @Router /v1/manage/webhooks/{webhookId}/enable [post]
+func V1EnableWebhook(ctx context.Context, storage WebhookStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := webhookOwner(ctx, c, storage)
+		if !ok {
+			return
+		}
+
+		u, ok := bindWebhookURI(c)
+		if !ok {
+			return
+		}
+
+		if err := storage.EnableWebhook(ctx, userID, u.WebhookID); err != nil {
+			webhookWriteError(c, u.WebhookID, "enable webhook", err)
+
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"taskmanager/internal/model"
+)
+
+// V1GetWebhooks without the secrets.
+//
+// @Summary get webhooks
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Success 200 {object} []model.Webhook
+// @Failure 401 {object} nil
+// @Failure 403 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/webhooks [get]
+// @Router /v1/manage/webhooks [get]
+func V1GetWebhooks(ctx context.Context, storage WebhookStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := webhookOwner(ctx, c, storage)
+		if !ok {
+			return
+		}
+
+		webhooks, err := storage.GetWebhooks(ctx, userID)
+		if err != nil {
+			writeError(c, http.StatusInternalServerError, HTTPError{
+				Type:    typeInternalError,
+				Comment: "get webhooks",
+				Error:   err.Error(),
+			})
+
+			return
+		}
+
+		if webhooks == nil {
+			webhooks = []model.Webhook{}
+		}
+
+		c.JSON(http.StatusOK, webhooks)
+	}
+}
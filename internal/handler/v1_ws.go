@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+
+	"taskmanager/internal/model"
+)
+
+// The messages of the client.
+const (
+	WebSocketSubscribe     = "subscribe"
+	WebSocketUnsubscribe   = "unsubscribe"
+	WebSocketTaskCreate    = "task.create"
+	WebSocketTaskUpdate    = "task.update"
+	WebSocketTaskDelete    = "task.delete"
+	WebSocketPresenceView  = "presence.view"
+	WebSocketPresenceLeave = "presence.leave"
+)
+
+// The messages of the server.
+const (
+	WebSocketConnected = "connected"
+	WebSocketAck       = "ack"
+	WebSocketError     = "error"
+	WebSocketEvent     = "event"
+	WebSocketPresence  = "presence"
+	WebSocketHeartbeat = "heartbeat"
+)
+
+// The topics of the subscriptions: all tasks of the user, one task, "task:24", or the tasks of the members
+// in a project of the user, "project:5".
+const (
+	WebSocketTopicTasks         = "tasks"
+	WebSocketTopicTaskPrefix    = "task:"
+	WebSocketTopicProjectPrefix = "project:"
+)
+
+type WebSocketStorage interface {
+	TaskStorage
+	EventStorage
+	IsProjectMember(ctx context.Context, userID, projectID int) (bool, error)
+}
+
+type WebSocketConf struct {
+	// SendQueueSize the acks and the presence waiting to be sent, the client is disconnected when it is full.
+	SendQueueSize int
+	// MaxMessageBytes the client is disconnected after a longer message.
+	MaxMessageBytes int
+	// WriteTimeout the client is disconnected if a message is not written in time.
+	WriteTimeout time.Duration
+	Heartbeat    time.Duration
+	// AllowOrigins of the browsers, "*" - any. The clients without Origin are allowed.
+	AllowOrigins []string
+}
+
+// WebSocketRequest the message of the client, id is returned in the ack or the error.
+type WebSocketRequest struct {
+	ID   string `json:"id,omitempty" example:"1"`
+	Type string `json:"type" example:"task.update"`
+	// Topic of subscribe and unsubscribe.
+	Topic string `json:"topic,omitempty" example:"tasks"`
+	// BatchTaskOperation the fields of the mutations and presence, op is set by the type.
+	BatchTaskOperation
+}
+
+// WebSocketMessage the message of the server.
+type WebSocketMessage struct {
+	Type string `json:"type" example:"ack"`
+	// ID of the request of the ack and the error.
+	ID     string `json:"id,omitempty" example:"1"`
+	Status int    `json:"status,omitempty" example:"200"`
+	TaskID int    `json:"taskId,omitempty" example:"24"`
+	Topic  string `json:"topic,omitempty" example:"tasks"`
+	// ConnectionID of connected, the viewers are identified by it.
+	ConnectionID string `json:"connectionId,omitempty" example:"9f86d081884c7d65"`
+	// LastEventID the position in the event log of connected and event, lastEventId of the reconnection.
+	LastEventID int64        `json:"lastEventId,omitempty" example:"42"`
+	Event       *model.Event `json:"event,omitempty"`
+	// Viewers of the task of presence and of the acks of presence and of the subscriptions to the task,
+	// without them nobody views the task.
+	Viewers []WebSocketViewer `json:"viewers,omitempty"`
+	Error   *HTTPError        `json:"error,omitempty"`
+}
+
+type WebSocketViewer struct {
+	ConnectionID string    `json:"connectionId" example:"9f86d081884c7d65"`
+	Username     string    `json:"username" example:"user"`
+	Since        time.Time `json:"since" example:"2023-04-20T12:00:00Z"`
+}
+
+// V1WebSocket the real-time API of the user over WebSocket, JSON text messages:
+//
+//	-> {"id":"1","type":"subscribe","topic":"tasks"}
+//	<- {"type":"ack","id":"1","status":200,"topic":"tasks"}
+//	-> {"id":"2","type":"task.update","taskId":24,"completed":true,"ifMatch":"\"3\""}
+//	<- {"type":"ack","id":"2","status":200,"taskId":24}
+//	<- {"type":"event","lastEventId":43,"event":{"event":"task.completed","taskId":24,...}}
+//
+// The events of the subscribed topics come from the event log, after lastEventId or from the connection on.
+// The log of the member of a project has the events of its tasks, the project is subscribed by its members.
+// The viewers of the presence are the connections of the owner of the task to this instance,
+// the presence is not shared between the instances. The event of a mutation can come before its ack.
+//
+// Slow clients: the events are read from the log as fast as the client receives them,
+// the acks and the presence are queued, the client is disconnected when the queue is full
+// or a message is not written in time, it reconnects with lastEventId.
+//
+// @Summary WebSocket of the tasks: subscriptions, mutations, presence
+// @Tags tasks
+// @Param Last-Event-ID header int false "the id of the last received event"
+// @Param lastEventId query int false "Last-Event-ID for the clients that cannot set it"
+// @Param data body WebSocketRequest false "the messages of the client; type - subscribe(topic: tasks, task:<taskId>, project:<projectId>), unsubscribe(topic), task.create(title), task.update(taskId, title, completed, ifMatch), task.delete(taskId, permanent, ifMatch), presence.view(taskId), presence.leave(taskId)"
+// @Success 101 {object} WebSocketMessage "the messages of the server; type - connected, ack, error, event, presence, heartbeat"
+// @Failure 400 {object} HTTPError "error type, comment"
+// @Failure 401 {object} nil
+// @Failure 403 {object} nil
+// @Failure 500 {object} HTTPError "error type, comment"
+// @Router /v1/ws [get]
+func V1WebSocket(
+	ctx context.Context, storage WebSocketStorage, subscriber EventSubscriber, conf WebSocketConf,
+) gin.HandlerFunc {
+	presence := newWebSocketPresence()
+
+	return func(c *gin.Context) {
+		userID, ok := authorizedUserID(ctx, c, storage)
+		if !ok {
+			return
+		}
+
+		lastEventID, resume, ok := bindLastEventID(c)
+		if !ok {
+			return
+		}
+
+		// Before reading the log, so that the events saved meanwhile wake the connection.
+		var notify <-chan struct{}
+
+		if subscriber != nil {
+			ch, unsubscribe := subscriber.Subscribe(userID)
+			defer unsubscribe()
+
+			notify = ch
+		}
+
+		lastSeq := lastEventID
+
+		if !resume {
+			var err error
+
+			if lastSeq, err = storage.GetLastEventSeq(ctx, userID); err != nil {
+				writeError(c, http.StatusInternalServerError, HTTPError{
+					Type:    typeInternalError,
+					Comment: "get last event",
+					Error:   err.Error(),
+				})
+
+				return
+			}
+		}
+
+		connectionID, err := newWebSocketConnectionID()
+		if err != nil {
+			writeError(c, http.StatusInternalServerError, HTTPError{
+				Type:    typeInternalError,
+				Comment: "connection id",
+				Error:   err.Error(),
+			})
+
+			return
+		}
+
+		username, password, _ := c.Request.BasicAuth()
+
+		websocket.Server{
+			Handshake: conf.checkOrigin,
+			Handler: func(conn *websocket.Conn) {
+				s := newWebSocketSession(conn, conf, connectionID, userID, username, password)
+
+				s.run(ctx, storage, presence, notify, lastSeq)
+			},
+		}.ServeHTTP(c.Writer, c.Request)
+	}
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"taskmanager/internal/model"
+)
+
+const webhookSecretBytes = 32
+
+type webhookURI struct {
+	WebhookID int `uri:"webhookId" binding:"required" example:"3"`
+}
+
+// webhookOwner the user of the credentials, 0 - the manager. The manage routes are authorized by the server,
+// the webhooks of the manager get the events of all users.
+func webhookOwner(ctx context.Context, c *gin.Context, storage WebhookStorage) (int, bool) {
+	if _, manage := c.Get(gin.AuthUserKey); manage {
+		return 0, true
+	}
+
+	return authorizedUserID(ctx, c, storage)
+}
+
+// bindWebhookURI false - the error is written.
+func bindWebhookURI(c *gin.Context) (webhookURI, bool) {
+	var u webhookURI
+	if err := c.ShouldBindUri(&u); err != nil {
+		writeError(c, http.StatusBadRequest, HTTPError{
+			Type:    typeParameterRequired,
+			Comment: "webhookId",
+			Error:   err.Error(),
+			fields:  fieldErrors(err),
+		})
+
+		return webhookURI{}, false
+	}
+
+	return u, true
+}
+
+func webhookWriteError(c *gin.Context, webhookID int, comment string, err error) {
+	if errors.Is(err, model.ErrWebhookNotFound) {
+		writeError(c, http.StatusBadRequest, HTTPError{
+			Type:    typeWebhookNotFound,
+			Comment: strconv.Itoa(webhookID),
+			Error:   err.Error(),
+		})
+
+		return
+	}
+
+	writeError(c, http.StatusInternalServerError, HTTPError{
+		Type:    typeInternalError,
+		Comment: comment,
+		Error:   err.Error(),
+	})
+}
+
+func newWebhookSecret() (string, error) {
+	b := make([]byte, webhookSecretBytes)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("webhook secret: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckWebhookHost(t *testing.T) {
+	cases := []struct {
+		name string
+		url  string
+	}{
+		{name: "loopback", url: "http://127.0.0.1:8080/hook"},
+		{name: "loopback ipv6", url: "http://[::1]/hook"},
+		{name: "localhost", url: "http://localhost/hook"},
+		{name: "private", url: "https://10.0.0.1/hook"},
+		{name: "metadata", url: "http://169.254.169.254/latest/meta-data"},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			httpErr := WebhookConf{}.checkWebhookHost(context.Background(), tt.url)
+			require.NotNil(t, httpErr)
+			assert.Equal(t, typeParameterRequired, httpErr.Type)
+			assert.Equal(t, "url: loopback, private and link-local addresses are not allowed", httpErr.Comment)
+
+			assert.Nil(t, WebhookConf{AllowPrivateNetworks: true}.checkWebhookHost(context.Background(), tt.url))
+		})
+	}
+
+	assert.Nil(t, WebhookConf{}.checkWebhookHost(context.Background(), "https://93.184.216.34/hook"))
+}
@@ -0,0 +1,650 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"taskmanager/internal/model"
+	"taskmanager/internal/security"
+)
+
+const webSocketConnectionIDBytes = 8
+
+// webSocketSession the connection of the user. The messages are read by run and written by write,
+// the other goroutines queue them with send.
+type webSocketSession struct {
+	conn     *websocket.Conn
+	conf     WebSocketConf
+	id       string
+	userID   int
+	username string
+	// password salted.
+	password string
+
+	queue chan WebSocketMessage
+	// newTopics wakes write to read the log for the new topics.
+	newTopics chan struct{}
+	done      chan struct{}
+	stopOnce  sync.Once
+
+	mu     sync.Mutex
+	topics map[string]struct{}
+}
+
+func newWebSocketSession(
+	conn *websocket.Conn, conf WebSocketConf, id string, userID int, username, password string,
+) *webSocketSession {
+	conn.MaxPayloadBytes = conf.MaxMessageBytes
+
+	return &webSocketSession{
+		conn:      conn,
+		conf:      conf,
+		id:        id,
+		userID:    userID,
+		username:  username,
+		password:  security.SaltPassword(password),
+		queue:     make(chan WebSocketMessage, conf.SendQueueSize),
+		newTopics: make(chan struct{}, 1),
+		done:      make(chan struct{}),
+		topics:    make(map[string]struct{}),
+	}
+}
+
+// run reads the messages of the client until the connection is closed.
+func (s *webSocketSession) run(
+	ctx context.Context, storage WebSocketStorage, presence *webSocketPresence, notify <-chan struct{}, lastSeq int64,
+) {
+	// The connection is longer than the timeouts of the server.
+	_ = s.conn.SetReadDeadline(time.Time{})
+
+	presence.join(s)
+	defer presence.leave(s)
+
+	written := make(chan struct{})
+
+	go func() {
+		defer close(written)
+
+		s.write(ctx, storage, notify, lastSeq)
+	}()
+
+	defer func() {
+		s.stop()
+		<-written
+	}()
+
+	if !s.send(WebSocketMessage{
+		Type:         WebSocketConnected,
+		ConnectionID: s.id,
+		LastEventID:  lastSeq,
+	}) {
+		return
+	}
+
+	for {
+		var data []byte
+
+		// io.EOF, closed by write or a too large message, which is left unread.
+		if err := websocket.Message.Receive(s.conn, &data); err != nil {
+			return
+		}
+
+		var req WebSocketRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			if !s.send(webSocketErrorMessage(req.ID, http.StatusBadRequest, HTTPError{
+				Type:    typeParameterRequired,
+				Comment: "message: JSON",
+			})) {
+				return
+			}
+
+			continue
+		}
+
+		if !s.send(s.handle(ctx, storage, presence, req)) {
+			return
+		}
+	}
+}
+
+// write the queued messages and the events of the subscribed topics until the session is stopped.
+func (s *webSocketSession) write(ctx context.Context, storage EventStorage, notify <-chan struct{}, lastSeq int64) {
+	defer func() {
+		s.stop()
+
+		_ = s.conn.Close()
+	}()
+
+	ticker := time.NewTicker(s.conf.Heartbeat)
+	defer ticker.Stop()
+
+	for {
+		var err error
+
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-s.done:
+			return
+
+		case msg := <-s.queue:
+			err = s.writeMessage(msg)
+
+		case <-notify:
+			lastSeq, err = s.writeEvents(ctx, storage, lastSeq)
+
+		case <-s.newTopics:
+			lastSeq, err = s.writeEvents(ctx, storage, lastSeq)
+
+		// The log is also read on the heartbeats in case a notification is lost.
+		case <-ticker.C:
+			if err = s.writeMessage(WebSocketMessage{Type: WebSocketHeartbeat}); err == nil {
+				lastSeq, err = s.writeEvents(ctx, storage, lastSeq)
+			}
+		}
+
+		// The client reconnects with lastEventId.
+		if err != nil {
+			return
+		}
+	}
+}
+
+// writeEvents of the subscribed topics after the position in the log, returns the position of the last read event.
+// The events are read as fast as the client receives them, the log is the buffer of the slow clients.
+// Without the subscriptions the log is not read, the first topics get the events after the position.
+func (s *webSocketSession) writeEvents(ctx context.Context, storage EventStorage, afterSeq int64) (int64, error) {
+	s.mu.Lock()
+	topics := len(s.topics)
+	s.mu.Unlock()
+
+	if topics == 0 {
+		return afterSeq, nil
+	}
+
+	for {
+		events, err := storage.GetEvents(ctx, s.userID, afterSeq, maxEventsPerRead)
+		if err != nil {
+			return afterSeq, fmt.Errorf("get events: %w", err)
+		}
+
+		for i := range events {
+			if topic, ok := s.eventTopic(events[i]); ok {
+				if err := s.writeMessage(WebSocketMessage{
+					Type:        WebSocketEvent,
+					Topic:       topic,
+					LastEventID: events[i].Seq,
+					Event:       &events[i],
+				}); err != nil {
+					return afterSeq, err
+				}
+			}
+
+			afterSeq = events[i].Seq
+		}
+
+		if len(events) < maxEventsPerRead {
+			return afterSeq, nil
+		}
+	}
+}
+
+func (s *webSocketSession) writeMessage(msg WebSocketMessage) error {
+	if err := s.conn.SetWriteDeadline(time.Now().Add(s.conf.WriteTimeout)); err != nil {
+		return fmt.Errorf("set write deadline: %w", err)
+	}
+
+	if err := websocket.JSON.Send(s.conn, msg); err != nil {
+		return fmt.Errorf("send: %w", err)
+	}
+
+	return nil
+}
+
+// send queues the message, false - the queue is full, the session is stopped.
+func (s *webSocketSession) send(msg WebSocketMessage) bool {
+	select {
+	case s.queue <- msg:
+		return true
+
+	case <-s.done:
+		return false
+
+	default:
+		s.stop()
+
+		return false
+	}
+}
+
+// stop closes the connection after the message being written.
+func (s *webSocketSession) stop() {
+	s.stopOnce.Do(func() {
+		close(s.done)
+	})
+}
+
+func (s *webSocketSession) handle(
+	ctx context.Context, storage WebSocketStorage, presence *webSocketPresence, req WebSocketRequest,
+) WebSocketMessage {
+	switch req.Type {
+	case WebSocketSubscribe, WebSocketUnsubscribe:
+		topic, ok := parseWebSocketTopic(req.Topic)
+		if !ok {
+			return webSocketErrorMessage(req.ID, http.StatusBadRequest, HTTPError{
+				Type:    typeParameterRequired,
+				Comment: "topic: tasks, task:<taskId> or project:<projectId>",
+			})
+		}
+
+		if req.Type == WebSocketSubscribe && topic.projectID > 0 {
+			if res := s.checkProjectMember(ctx, storage, req.ID, topic.projectID); res != nil {
+				return *res
+			}
+		}
+
+		ack := WebSocketMessage{
+			Type:   WebSocketAck,
+			ID:     req.ID,
+			Status: http.StatusOK,
+			Topic:  req.Topic,
+		}
+
+		s.mu.Lock()
+		if req.Type == WebSocketSubscribe {
+			s.topics[req.Topic] = struct{}{}
+		} else {
+			delete(s.topics, req.Topic)
+		}
+		s.mu.Unlock()
+
+		if req.Type == WebSocketSubscribe {
+			select {
+			case s.newTopics <- struct{}{}:
+			default:
+			}
+
+			if topic.taskID > 0 {
+				ack.TaskID = topic.taskID
+				ack.Viewers = presence.viewers(s.userID, topic.taskID)
+			}
+		}
+
+		return ack
+
+	case WebSocketTaskCreate, WebSocketTaskUpdate, WebSocketTaskDelete:
+		return s.mutate(ctx, storage, req)
+
+	case WebSocketPresenceView, WebSocketPresenceLeave:
+		if req.TaskID < 1 {
+			return webSocketErrorMessage(req.ID, http.StatusBadRequest, HTTPError{
+				Type:    typeParameterRequired,
+				Comment: "taskId",
+			})
+		}
+
+		var viewers []WebSocketViewer
+
+		if req.Type == WebSocketPresenceView {
+			// Only the tasks of the user are viewed.
+			if _, err := storage.GetTask(ctx, s.username, s.password, req.TaskID); err != nil {
+				res := batchTaskOperationResultFromErr(err, req.TaskID)
+
+				return webSocketErrorMessage(req.ID, res.Status, *res.Error)
+			}
+
+			viewers = presence.view(s, req.TaskID)
+		} else {
+			viewers = presence.unview(s, req.TaskID)
+		}
+
+		return WebSocketMessage{
+			Type:    WebSocketAck,
+			ID:      req.ID,
+			Status:  http.StatusOK,
+			TaskID:  req.TaskID,
+			Viewers: viewers,
+		}
+	}
+
+	return webSocketErrorMessage(req.ID, http.StatusBadRequest, HTTPError{
+		Type: typeParameterRequired,
+		Comment: "type: subscribe, unsubscribe, task.create, task.update, task.delete, " +
+			"presence.view or presence.leave",
+	})
+}
+
+// mutate the task as the operation of a batch.
+func (s *webSocketSession) mutate(ctx context.Context, storage TaskStorage, req WebSocketRequest) WebSocketMessage {
+	req.Op = strings.TrimPrefix(req.Type, "task.")
+
+	op, res := batchTasksCreateOperation(req.BatchTaskOperation, func() (int, error) {
+		task, err := storage.GetTask(ctx, s.username, s.password, req.TaskID)
+		if err != nil {
+			return 0, fmt.Errorf("get task: %w", err)
+		}
+
+		return task.Version, nil
+	})
+	if res != nil {
+		return webSocketErrorMessage(req.ID, res.Status, *res.Error)
+	}
+
+	opResults, err := storage.BatchTasks(ctx, s.username, s.password, []model.TaskOperation{op}, true)
+	if err != nil {
+		return webSocketErrorMessage(req.ID, http.StatusInternalServerError, HTTPError{
+			Type:    typeInternalError,
+			Comment: "batch tasks",
+			Error:   err.Error(),
+		})
+	}
+
+	result := batchTaskOperationResultFromErr(opResults[0].Err, opResults[0].TaskID)
+	if result.Error != nil {
+		return webSocketErrorMessage(req.ID, result.Status, *result.Error)
+	}
+
+	if op.Op == model.TaskOperationCreate {
+		result.Status = http.StatusCreated
+	}
+
+	return WebSocketMessage{
+		Type:   WebSocketAck,
+		ID:     req.ID,
+		Status: result.Status,
+		TaskID: result.TaskID,
+	}
+}
+
+func (s *webSocketSession) subscribed(taskID int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, tasks := s.topics[WebSocketTopicTasks]
+	_, task := s.topics[WebSocketTopicTaskPrefix+strconv.Itoa(taskID)]
+
+	return tasks || task
+}
+
+// checkProjectMember only the members subscribe to the project, nil - the user is a member.
+func (s *webSocketSession) checkProjectMember(
+	ctx context.Context, storage WebSocketStorage, id string, projectID int,
+) *WebSocketMessage {
+	member, err := storage.IsProjectMember(ctx, s.userID, projectID)
+	if err != nil {
+		res := webSocketErrorMessage(id, http.StatusInternalServerError, HTTPError{
+			Type:    typeInternalError,
+			Comment: "is project member",
+			Error:   err.Error(),
+		})
+
+		return &res
+	}
+
+	if !member {
+		res := webSocketErrorMessage(id, http.StatusBadRequest, HTTPError{
+			Type:    typeProjectNotFound,
+			Comment: strconv.Itoa(projectID),
+		})
+
+		return &res
+	}
+
+	return nil
+}
+
+// eventTopic the subscribed topic of the event, false - not subscribed.
+// The tasks topic is of the own tasks, the tasks of the other members come to their projects.
+func (s *webSocketSession) eventTopic(event model.Event) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.topics[WebSocketTopicTasks]; ok && event.UserID == s.userID {
+		return WebSocketTopicTasks, true
+	}
+
+	if event.TaskID > 0 {
+		topic := WebSocketTopicTaskPrefix + strconv.Itoa(event.TaskID)
+
+		if _, ok := s.topics[topic]; ok {
+			return topic, true
+		}
+	}
+
+	if event.ProjectID > 0 {
+		topic := WebSocketTopicProjectPrefix + strconv.Itoa(event.ProjectID)
+
+		if _, ok := s.topics[topic]; ok {
+			return topic, true
+		}
+	}
+
+	return "", false
+}
+
+// webSocketTopic the parsed topic, both ids 0 - all tasks.
+type webSocketTopic struct {
+	taskID    int
+	projectID int
+}
+
+func parseWebSocketTopic(topic string) (webSocketTopic, bool) {
+	if topic == WebSocketTopicTasks {
+		return webSocketTopic{}, true
+	}
+
+	if taskID, ok := parseWebSocketTopicID(topic, WebSocketTopicTaskPrefix); ok {
+		return webSocketTopic{taskID: taskID}, true
+	}
+
+	if projectID, ok := parseWebSocketTopicID(topic, WebSocketTopicProjectPrefix); ok {
+		return webSocketTopic{projectID: projectID}, true
+	}
+
+	return webSocketTopic{}, false
+}
+
+// parseWebSocketTopicID the positive id after the prefix in its canonical form.
+func parseWebSocketTopicID(topic, prefix string) (int, bool) {
+	id, found := strings.CutPrefix(topic, prefix)
+	if !found {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(id)
+	if err != nil || n < 1 || strconv.Itoa(n) != id {
+		return 0, false
+	}
+
+	return n, true
+}
+
+func webSocketErrorMessage(id string, status int, httpError HTTPError) WebSocketMessage {
+	return WebSocketMessage{
+		Type:   WebSocketError,
+		ID:     id,
+		Status: status,
+		Error:  &httpError,
+	}
+}
+
+// checkOrigin the browsers are allowed from the origins of CORS and from the same origin,
+// the clients without Origin from anywhere.
+func (conf WebSocketConf) checkOrigin(_ *websocket.Config, r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+
+	if u, err := url.Parse(origin); err == nil && u.Host == r.Host {
+		return nil
+	}
+
+	for _, allowed := range conf.AllowOrigins {
+		if allowed == "*" || allowed == origin {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("origin %q is not allowed", origin)
+}
+
+func newWebSocketConnectionID() (string, error) {
+	b := make([]byte, webSocketConnectionIDBytes)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("connection id: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+type webSocketTask struct {
+	userID int
+	taskID int
+}
+
+// webSocketPresence the viewers of the tasks, the sessions subscribed to a task get its presence on the changes.
+type webSocketPresence struct {
+	mu       sync.Mutex
+	sessions map[int]map[*webSocketSession]struct{}
+	viewing  map[webSocketTask]map[*webSocketSession]time.Time
+}
+
+func newWebSocketPresence() *webSocketPresence {
+	return &webSocketPresence{
+		sessions: make(map[int]map[*webSocketSession]struct{}),
+		viewing:  make(map[webSocketTask]map[*webSocketSession]time.Time),
+	}
+}
+
+func (p *webSocketPresence) join(s *webSocketSession) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.sessions[s.userID] == nil {
+		p.sessions[s.userID] = make(map[*webSocketSession]struct{})
+	}
+
+	p.sessions[s.userID][s] = struct{}{}
+}
+
+// leave the session stops viewing all tasks.
+func (p *webSocketPresence) leave(s *webSocketSession) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.sessions[s.userID], s)
+
+	if len(p.sessions[s.userID]) == 0 {
+		delete(p.sessions, s.userID)
+	}
+
+	for task, viewers := range p.viewing {
+		if _, ok := viewers[s]; ok {
+			p.removeLocked(s, task)
+		}
+	}
+}
+
+func (p *webSocketPresence) view(s *webSocketSession, taskID int) []WebSocketViewer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	task := webSocketTask{userID: s.userID, taskID: taskID}
+
+	if _, ok := p.viewing[task][s]; ok {
+		return p.viewersLocked(task)
+	}
+
+	if p.viewing[task] == nil {
+		p.viewing[task] = make(map[*webSocketSession]time.Time)
+	}
+
+	p.viewing[task][s] = time.Now().UTC()
+
+	p.broadcastLocked(task)
+
+	return p.viewersLocked(task)
+}
+
+func (p *webSocketPresence) unview(s *webSocketSession, taskID int) []WebSocketViewer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	task := webSocketTask{userID: s.userID, taskID: taskID}
+
+	if _, ok := p.viewing[task][s]; ok {
+		p.removeLocked(s, task)
+	}
+
+	return p.viewersLocked(task)
+}
+
+func (p *webSocketPresence) viewers(userID, taskID int) []WebSocketViewer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.viewersLocked(webSocketTask{userID: userID, taskID: taskID})
+}
+
+func (p *webSocketPresence) removeLocked(s *webSocketSession, task webSocketTask) {
+	delete(p.viewing[task], s)
+
+	if len(p.viewing[task]) == 0 {
+		delete(p.viewing, task)
+	}
+
+	p.broadcastLocked(task)
+}
+
+// broadcastLocked the presence of the task to the subscribed sessions of the user, the slow ones are stopped.
+func (p *webSocketPresence) broadcastLocked(task webSocketTask) {
+	viewers := p.viewersLocked(task)
+
+	for s := range p.sessions[task.userID] {
+		if s.subscribed(task.taskID) {
+			s.send(WebSocketMessage{
+				Type:    WebSocketPresence,
+				TaskID:  task.taskID,
+				Viewers: viewers,
+			})
+		}
+	}
+}
+
+// viewersLocked the first viewer first.
+func (p *webSocketPresence) viewersLocked(task webSocketTask) []WebSocketViewer {
+	viewers := make([]WebSocketViewer, 0, len(p.viewing[task]))
+
+	for s, since := range p.viewing[task] {
+		viewers = append(viewers, WebSocketViewer{
+			ConnectionID: s.id,
+			Username:     s.username,
+			Since:        since,
+		})
+	}
+
+	sort.Slice(viewers, func(i, j int) bool {
+		if viewers[i].Since.Equal(viewers[j].Since) {
+			return viewers[i].ConnectionID < viewers[j].ConnectionID
+		}
+
+		return viewers[i].Since.Before(viewers[j].Since)
+	})
+
+	return viewers
+}
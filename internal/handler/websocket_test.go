@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"taskmanager/internal/model"
+)
+
+func TestParseWebSocketTopic(t *testing.T) {
+	cases := []struct {
+		name       string
+		topic      string
+		expected   webSocketTopic
+		expectedOk bool
+	}{
+		{name: "tasks", topic: "tasks", expected: webSocketTopic{}, expectedOk: true},
+		{name: "task", topic: "task:24", expected: webSocketTopic{taskID: 24}, expectedOk: true},
+		{name: "project", topic: "project:5", expected: webSocketTopic{projectID: 5}, expectedOk: true},
+		{name: "empty", topic: "", expectedOk: false},
+		{name: "no id", topic: "task:", expectedOk: false},
+		{name: "zero", topic: "task:0", expectedOk: false},
+		{name: "leading zero", topic: "task:024", expectedOk: false},
+		{name: "project without id", topic: "project:", expectedOk: false},
+		{name: "negative project", topic: "project:-1", expectedOk: false},
+		{name: "unknown", topic: "user:1", expectedOk: false},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			topic, ok := parseWebSocketTopic(tt.topic)
+			assert.Equal(t, tt.expectedOk, ok)
+			assert.Equal(t, tt.expected, topic)
+		})
+	}
+}
+
+func TestWebSocketEventTopic(t *testing.T) {
+	s := &webSocketSession{
+		userID: 1,
+		topics: map[string]struct{}{"tasks": {}, "project:5": {}},
+	}
+
+	topic, ok := s.eventTopic(model.Event{UserID: 1, TaskID: 3})
+	assert.True(t, ok)
+	assert.Equal(t, "tasks", topic)
+
+	// The task of another member comes to the project, not to the own tasks.
+	topic, ok = s.eventTopic(model.Event{UserID: 2, TaskID: 4, ProjectID: 5})
+	assert.True(t, ok)
+	assert.Equal(t, "project:5", topic)
+
+	_, ok = s.eventTopic(model.Event{UserID: 2, TaskID: 4, ProjectID: 6})
+	assert.False(t, ok)
+}
+
+func TestWebSocketSessionSend(t *testing.T) {
+	s := &webSocketSession{
+		queue: make(chan WebSocketMessage, 1),
+		done:  make(chan struct{}),
+	}
+
+	assert.True(t, s.send(WebSocketMessage{Type: WebSocketAck}))
+
+	// The queue is full - the slow client is disconnected.
+	assert.False(t, s.send(WebSocketMessage{Type: WebSocketAck}))
+
+	select {
+	case <-s.done:
+	default:
+		t.Fatal("the session is not stopped")
+	}
+
+	assert.False(t, s.send(WebSocketMessage{Type: WebSocketAck}))
+}
@@ -0,0 +1,332 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DialectPostgres = "postgres"
+	DialectSQLite   = "sqlite"
+)
+
+// postgresAdvisoryLockKey any constant, the same for all instances.
+const postgresAdvisoryLockKey = 4712950386
+
+var (
+	ErrDirtyVersion = errors.New("applied version has no migration file")
+
+	errInvalidFileName = errors.New("invalid migration file name")
+	errDuplicate       = errors.New("duplicate migration")
+	errNoUp            = errors.New("no up migration")
+	errNoDown          = errors.New("no down migration")
+)
+
+// fileNameRegexp <version>_<name>.up.sql, <version>_<name>.down.sql.
+var fileNameRegexp = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Version int
+	Name    string
+	// Applied zero - pending.
+	Applied time.Time
+}
+
+type Migrator struct {
+	Pool       *sql.DB
+	Dialect    string
+	Migrations []Migration
+	Logger     *logrus.Logger
+}
+
+// Load reads the migrations from the root of fsys, sorted by version.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read dir: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+
+	for _, entry := range entries {
+		match := fileNameRegexp.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("%w: %s", errInvalidFileName, entry.Name())
+		}
+
+		version, err := strconv.Atoi(match[1])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %s", errInvalidFileName, entry.Name(), err.Error())
+		}
+
+		query, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("read file: %w", err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("%w: version %d: %s, %s", errDuplicate, version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(query)
+		} else {
+			m.Down = string(query)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("%w: version %d", errNoUp, m.Version)
+		}
+
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// Up applies all pending migrations, each in its own transaction. Returns the number of applied ones.
+func (m Migrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+
+	err := m.locked(ctx, func(conn *sql.Conn, versions map[int]time.Time) error {
+		for _, migration := range m.Migrations {
+			if _, ok := versions[migration.Version]; ok {
+				continue
+			}
+
+			if err := m.apply(ctx, conn, migration.Up, `
+				INSERT INTO
+					schema_version(version, name, applied)
+				VALUES
+				    ($1, $2, $3)
+			`,
+				migration.Version, migration.Name, time.Now().UTC(),
+			); err != nil {
+				return fmt.Errorf("up %d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			m.Logger.Infof("migration up: %d_%s", migration.Version, migration.Name)
+
+			applied++
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down rolls back the last applied migrations. Returns the number of rolled back ones.
+func (m Migrator) Down(ctx context.Context, steps int) (int, error) {
+	rolledBack := 0
+
+	err := m.locked(ctx, func(conn *sql.Conn, versions map[int]time.Time) error {
+		for i := len(m.Migrations) - 1; i >= 0 && rolledBack < steps; i-- {
+			migration := m.Migrations[i]
+
+			if _, ok := versions[migration.Version]; !ok {
+				continue
+			}
+
+			if migration.Down == "" {
+				return fmt.Errorf("%w: version %d", errNoDown, migration.Version)
+			}
+
+			if err := m.apply(ctx, conn, migration.Down, `
+				DELETE FROM
+				    schema_version
+				WHERE
+				    version = $1
+			`,
+				migration.Version,
+			); err != nil {
+				return fmt.Errorf("down %d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			m.Logger.Infof("migration down: %d_%s", migration.Version, migration.Name)
+
+			rolledBack++
+		}
+
+		return nil
+	})
+
+	return rolledBack, err
+}
+
+// Status all migrations with the time they were applied.
+func (m Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+
+	err := m.locked(ctx, func(conn *sql.Conn, versions map[int]time.Time) error {
+		for _, migration := range m.Migrations {
+			statuses = append(statuses, Status{
+				Version: migration.Version,
+				Name:    migration.Name,
+				Applied: versions[migration.Version],
+			})
+		}
+
+		return nil
+	})
+
+	return statuses, err
+}
+
+// locked runs fn holding the lock, so that instances starting together do not apply the same migrations.
+// The applied versions must have migration files: the database is not older than the binary.
+func (m Migrator) locked(ctx context.Context, fn func(conn *sql.Conn, versions map[int]time.Time) error) error {
+	conn, err := m.Pool.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("conn: %w", err)
+	}
+
+	defer func() {
+		if err := conn.Close(); err != nil {
+			m.Logger.Errorf("migrate: conn close: %v", err)
+		}
+	}()
+
+	// The session lock is held by the connection, SQLite pool has one connection.
+	if m.Dialect == DialectPostgres {
+		if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, postgresAdvisoryLockKey); err != nil {
+			return fmt.Errorf("advisory lock: %w", err)
+		}
+
+		defer func() {
+			if _, err := conn.ExecContext(context.Background(),
+				`SELECT pg_advisory_unlock($1)`, postgresAdvisoryLockKey,
+			); err != nil {
+				m.Logger.Errorf("migrate: advisory unlock: %v", err)
+			}
+		}()
+	}
+
+	if _, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_version
+		(
+		    version integer   not null
+		        constraint schema_version__pk
+		            primary key,
+		    name    text      not null,
+		    applied timestamp not null
+		)
+	`); err != nil {
+		return fmt.Errorf("create schema_version: %w", err)
+	}
+
+	versions, err := m.appliedVersions(ctx, conn)
+	if err != nil {
+		return fmt.Errorf("applied versions: %w", err)
+	}
+
+	known := make(map[int]bool, len(m.Migrations))
+	for _, migration := range m.Migrations {
+		known[migration.Version] = true
+	}
+
+	for version := range versions {
+		if !known[version] {
+			return fmt.Errorf("%w: %d", ErrDirtyVersion, version)
+		}
+	}
+
+	return fn(conn, versions)
+}
+
+func (m Migrator) appliedVersions(ctx context.Context, conn *sql.Conn) (map[int]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, `
+		SELECT
+		    version, applied
+		FROM
+		    schema_version
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			m.Logger.Errorf("migrate: applied versions: %v", err)
+		}
+	}()
+
+	versions := make(map[int]time.Time)
+
+	for rows.Next() {
+		var (
+			version int
+			applied time.Time
+		)
+
+		if err := rows.Scan(&version, &applied); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+
+		versions[version] = applied
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("scan rows: %w", rows.Err())
+	}
+
+	return versions, nil
+}
+
+// apply runs the migration query and updates schema_version in one transaction.
+func (m Migrator) apply(ctx context.Context, conn *sql.Conn, query, versionQuery string, versionArgs ...any) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			m.Logger.Errorf("migrate: rollback: %v", err)
+		}
+	}()
+
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, versionQuery, versionArgs...); err != nil {
+		return fmt.Errorf("schema version: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	return nil
+}
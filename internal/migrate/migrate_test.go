@@ -0,0 +1,145 @@
+package migrate
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taskmanager/internal/db"
+)
+
+func TestLoad(t *testing.T) {
+	cases := []struct {
+		name     string
+		fsys     fstest.MapFS
+		expected []Migration
+		err      error
+	}{
+		{
+			name: "sorted",
+			fsys: fstest.MapFS{
+				"0002_second.up.sql":   {Data: []byte("up2")},
+				"0001_first.up.sql":    {Data: []byte("up1")},
+				"0001_first.down.sql":  {Data: []byte("down1")},
+				"0010_tenth.up.sql":    {Data: []byte("up10")},
+				"0010_tenth.down.sql":  {Data: []byte("down10")},
+				"0002_second.down.sql": {Data: []byte("down2")},
+			},
+			expected: []Migration{
+				{Version: 1, Name: "first", Up: "up1", Down: "down1"},
+				{Version: 2, Name: "second", Up: "up2", Down: "down2"},
+				{Version: 10, Name: "tenth", Up: "up10", Down: "down10"},
+			},
+		},
+		{
+			name: "invalid_file_name",
+			fsys: fstest.MapFS{
+				"0001_first.sql": {Data: []byte("up1")},
+			},
+			err: errInvalidFileName,
+		},
+		{
+			name: "duplicate_version",
+			fsys: fstest.MapFS{
+				"0001_first.up.sql":  {Data: []byte("up1")},
+				"0001_second.up.sql": {Data: []byte("up2")},
+			},
+			err: errDuplicate,
+		},
+		{
+			name: "no_up",
+			fsys: fstest.MapFS{
+				"0001_first.down.sql": {Data: []byte("down1")},
+			},
+			err: errNoUp,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := Load(tt.fsys)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, migrations)
+		})
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+
+	pool, err := db.CreateSQLitePool(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+
+	defer pool.Close()
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	migrations, err := Load(fstest.MapFS{
+		"0001_first.up.sql":    {Data: []byte("create table first (id integer)")},
+		"0001_first.down.sql":  {Data: []byte("drop table first")},
+		"0002_second.up.sql":   {Data: []byte("create table second (id integer); create table third (id integer)")},
+		"0002_second.down.sql": {Data: []byte("drop table third; drop table second")},
+	})
+	require.NoError(t, err)
+
+	m := Migrator{
+		Pool:       pool,
+		Dialect:    DialectSQLite,
+		Migrations: migrations,
+		Logger:     logger,
+	}
+
+	applied, err := m.Up(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, applied)
+
+	applied, err = m.Up(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, applied)
+
+	_, err = pool.Exec(`INSERT INTO third(id) VALUES (1)`)
+	require.NoError(t, err)
+
+	rolledBack, err := m.Down(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, rolledBack)
+
+	_, err = pool.Exec(`INSERT INTO third(id) VALUES (1)`)
+	require.Error(t, err)
+
+	statuses, err := m.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	assert.False(t, statuses[0].Applied.IsZero())
+	assert.True(t, statuses[1].Applied.IsZero())
+
+	// The database is newer than the migrations.
+	_, err = Migrator{Pool: pool, Dialect: DialectSQLite, Logger: logger}.Up(ctx)
+	assert.ErrorIs(t, err, ErrDirtyVersion)
+
+	// A failed migration is rolled back with its version.
+	m.Migrations = append(m.Migrations, Migration{Version: 3, Name: "broken", Up: "create table fourth (id integer); wrong"})
+
+	_, err = m.Up(ctx)
+	require.Error(t, err)
+
+	statuses, err = m.Status(ctx)
+	require.NoError(t, err)
+	assert.True(t, statuses[2].Applied.IsZero())
+
+	_, err = pool.Exec(`INSERT INTO fourth(id) VALUES (1)`)
+	require.Error(t, err)
+}
@@ -0,0 +1,152 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrCalendarTokenNotFound = errors.New("calendar token not found")
+
+// SetCalendarToken replaces the calendar token of the user, the feed of the old token stops working.
+// tokenHash - the hash of the token, the token itself is not stored.
+func (p Postgres) SetCalendarToken(ctx context.Context, userID int, tokenHash string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	if _, err := p.conn(ctx).ExecContext(ctx, `
+		INSERT INTO
+			calendar_token(user_id, token, created)
+		VALUES
+		    ($1, $2, now())
+		ON CONFLICT (user_id) DO UPDATE SET
+		    token = excluded.token,
+		    created = excluded.created
+	`,
+		userID,
+		tokenHash,
+	); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteCalendarToken disables the feed of the user.
+func (p Postgres) DeleteCalendarToken(ctx context.Context, userID int) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	res, err := p.conn(ctx).ExecContext(ctx, `
+		DELETE FROM
+		    calendar_token
+		WHERE
+		    user_id = $1
+	`,
+		userID,
+	)
+	if err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	if rowsAffected != 1 {
+		return fmt.Errorf("userID %d: %w", userID, ErrCalendarTokenNotFound)
+	}
+
+	return nil
+}
+
+// GetCalendarTasks the tasks with a due date of the user of the token, without the trash, sorted by due and id.
+func (p Postgres) GetCalendarTasks(ctx context.Context, tokenHash string) ([]Task, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	var userID int
+
+	if err := p.conn(ctx).QueryRowContext(ctx, `
+		SELECT
+		    user_id
+		FROM
+		    calendar_token
+		WHERE
+		    token = $1
+	`,
+		tokenHash,
+	).Scan(
+		&userID,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCalendarTokenNotFound
+		}
+
+		return nil, fmt.Errorf("query row: %w", err)
+	}
+
+	rows, err := p.conn(ctx).QueryContext(ctx, `
+		SELECT
+		    task_id, status, title, created, updated, completed, due, recurrence, version
+		FROM
+		    task
+		WHERE
+		    user_id = $1 AND
+		    due IS NOT NULL AND
+		    deleted_at IS NULL
+		ORDER BY
+		    due, task_id
+	`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			p.Logger.Errorf("get calendar tasks: %v", err)
+		}
+	}()
+
+	var tasks []Task
+
+	for rows.Next() {
+		var (
+			task           Task
+			taskCompleted  sql.NullTime
+			taskDue        sql.NullTime
+			taskRecurrence sql.NullString
+		)
+
+		if err := rows.Scan(
+			&task.ID,
+			&task.Status,
+			&task.Title,
+			&task.Created,
+			&task.Updated,
+			&taskCompleted,
+			&taskDue,
+			&taskRecurrence,
+			&task.Version,
+		); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+
+		task.Completed = taskCompleted.Time
+		task.Due = nullTimeRef(taskDue)
+		task.Recurrence = taskRecurrence.String
+
+		tasks = append(tasks, task)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("scan rows: %w", rows.Err())
+	}
+
+	return tasks, nil
+}
@@ -0,0 +1,216 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// The events of the changes, published to the event log and to the webhooks.
+const (
+	EventTaskCreated   = "task.created"
+	EventTaskUpdated   = "task.updated"
+	EventTaskCompleted = "task.completed"
+	EventTaskDeleted   = "task.deleted"
+	EventTaskRestored  = "task.restored"
+	EventTaskReminder  = "task.reminder"
+	EventUserDeleted   = "user.deleted"
+)
+
+// EventsChannel the channel of NOTIFY after an event is saved, the payload is the user ID.
+const EventsChannel = "task_events"
+
+// eventsLockClass the first key of the advisory lock of the event log of the user, the second one is the user ID.
+const eventsLockClass = 7301
+
+// Event the change of the data, the payload of the webhook deliveries and of the event stream.
+type Event struct {
+	ID      string    `json:"id"`
+	Type    string    `json:"event"`
+	Created time.Time `json:"created"`
+	// UserID the owner of the task or the deleted user.
+	UserID int `json:"userId"`
+	TaskID int `json:"taskId,omitempty"`
+	// ProjectID the project of the task, the event is also in the logs of its members.
+	ProjectID int `json:"projectId,omitempty"`
+	// Task the task after the change, nil for task.deleted and user.deleted.
+	Task *Task `json:"task,omitempty"`
+	// Reminder the sent reminder of task.reminder.
+	Reminder *Reminder `json:"reminder,omitempty"`
+	// Seq the position in the event log, increasing, set by SaveEvent and GetEvents.
+	Seq int64 `json:"-"`
+}
+
+// SaveEvent appends the event to the event log of the user: the owner of the task or a member of its project.
+// NOTIFY EventsChannel is sent after the commit. Returns the position in the log.
+//
+// The events of the user are saved one by one: event_seq is taken on the insert and visible on the commit, a reader
+// past the seq of the later transaction committed first would skip the event of the earlier one.
+func (p Postgres) SaveEvent(ctx context.Context, userID int, event Event) (int64, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return 0, fmt.Errorf("marshal event: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	var seq int64
+
+	if err := p.WithTx(ctx, func(tx Postgres) error {
+		// Released on the commit, after the event is visible.
+		if _, err := tx.conn(ctx).ExecContext(ctx, `SELECT pg_advisory_xact_lock($1, $2)`,
+			eventsLockClass,
+			userID,
+		); err != nil {
+			return fmt.Errorf("exec: advisory lock: %w", err)
+		}
+
+		//nolint:execinquery
+		if err := tx.conn(ctx).QueryRowContext(ctx, `
+			INSERT INTO
+				event(user_id, event, task_id, payload, created)
+			VALUES
+			    ($1, $2, NULLIF($3, 0), $4, now())
+			RETURNING
+			    event_seq
+		`,
+			userID,
+			event.Type,
+			event.TaskID,
+			string(payload),
+		).Scan(
+			&seq,
+		); err != nil {
+			return fmt.Errorf("query row: %w", err)
+		}
+
+		if _, err := tx.conn(ctx).ExecContext(ctx, `SELECT pg_notify($1, $2)`,
+			EventsChannel,
+			strconv.Itoa(userID),
+		); err != nil {
+			return fmt.Errorf("exec: notify: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	return seq, nil
+}
+
+// GetEvents of the user after the position in the log, oldest first.
+func (p Postgres) GetEvents(ctx context.Context, userID int, afterSeq int64, limit int) ([]Event, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	rows, err := p.conn(ctx).QueryContext(ctx, `
+		SELECT
+			event_seq, payload
+		FROM
+		    event
+		WHERE
+		    user_id = $1 AND
+		    event_seq > $2
+		ORDER BY
+		    event_seq
+		LIMIT $3
+	`,
+		userID,
+		afterSeq,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			p.Logger.Errorf("get events: %v", err)
+		}
+	}()
+
+	var events []Event
+
+	for rows.Next() {
+		var (
+			seq     int64
+			payload string
+		)
+
+		if err := rows.Scan(
+			&seq,
+			&payload,
+		); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+
+		var event Event
+		if err := json.Unmarshal([]byte(payload), &event); err != nil {
+			return nil, fmt.Errorf("unmarshal event %d: %w", seq, err)
+		}
+
+		event.Seq = seq
+
+		events = append(events, event)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("scan rows: %w", rows.Err())
+	}
+
+	return events, nil
+}
+
+// GetLastEventSeq the position of the last event of the user in the log, 0 - no events.
+func (p Postgres) GetLastEventSeq(ctx context.Context, userID int) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	var seq int64
+
+	if err := p.conn(ctx).QueryRowContext(ctx, `
+		SELECT
+			COALESCE(MAX(event_seq), 0)
+		FROM
+		    event
+		WHERE
+		    user_id = $1
+	`,
+		userID,
+	).Scan(
+		&seq,
+	); err != nil {
+		return 0, fmt.Errorf("query row: %w", err)
+	}
+
+	return seq, nil
+}
+
+// PurgeEvents deletes the events created before from the log.
+func (p Postgres) PurgeEvents(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	res, err := p.conn(ctx).ExecContext(ctx, `
+		DELETE FROM
+		    event
+		WHERE
+		    created < $1
+	`,
+		before,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("exec: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
@@ -0,0 +1,163 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// IdempotentResponse the stored response to the request with the Idempotency-Key.
+type IdempotentResponse struct {
+	Fingerprint string
+	// Status 0 - the request is still being processed.
+	Status      int
+	ContentType string
+	Body        []byte
+}
+
+// ReserveIdempotencyKey reserves the key for the request, an expired key is reserved again.
+// If the key is already reserved, returns false and the stored response.
+func (p Postgres) ReserveIdempotencyKey(
+	ctx context.Context, scope, key, fingerprint string, expires time.Time,
+) (IdempotentResponse, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	var reserved bool
+
+	if err := p.conn(ctx).QueryRowContext(ctx, `
+		INSERT INTO
+			idempotency_key(scope, idempotency_key, fingerprint, created, expires)
+		VALUES
+		    ($1, $2, $3, now(), $4)
+		ON CONFLICT (scope, idempotency_key) DO UPDATE SET
+		    fingerprint = excluded.fingerprint,
+		    status = null,
+		    content_type = null,
+		    body = null,
+		    created = excluded.created,
+		    expires = excluded.expires
+		WHERE
+		    idempotency_key.expires < now()
+		RETURNING
+		    true
+	`,
+		scope,
+		key,
+		fingerprint,
+		expires,
+	).Scan(
+		&reserved,
+	); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return IdempotentResponse{}, false, fmt.Errorf("query row: %w", err)
+	}
+
+	if reserved {
+		return IdempotentResponse{}, true, nil
+	}
+
+	var (
+		response    IdempotentResponse
+		status      sql.NullInt64
+		contentType sql.NullString
+	)
+
+	if err := p.conn(ctx).QueryRowContext(ctx, `
+		SELECT
+		    fingerprint, status, content_type, body
+		FROM
+		    idempotency_key
+		WHERE
+		    scope = $1 AND
+		    idempotency_key = $2
+	`,
+		scope,
+		key,
+	).Scan(
+		&response.Fingerprint,
+		&status,
+		&contentType,
+		&response.Body,
+	); err != nil {
+		return IdempotentResponse{}, false, fmt.Errorf("query row: %w", err)
+	}
+
+	response.Status = int(status.Int64)
+	response.ContentType = contentType.String
+
+	return response, false, nil
+}
+
+func (p Postgres) SaveIdempotentResponse(ctx context.Context, scope, key string, response IdempotentResponse) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	if _, err := p.conn(ctx).ExecContext(ctx, `
+		UPDATE
+		    idempotency_key
+		SET
+		    status = $3,
+		    content_type = $4,
+		    body = $5
+		WHERE
+		    scope = $1 AND
+		    idempotency_key = $2
+	`,
+		scope,
+		key,
+		response.Status,
+		response.ContentType,
+		response.Body,
+	); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteIdempotencyKey releases the key so that the request can be retried.
+func (p Postgres) DeleteIdempotencyKey(ctx context.Context, scope, key string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	if _, err := p.conn(ctx).ExecContext(ctx, `
+		DELETE FROM
+		    idempotency_key
+		WHERE
+		    scope = $1 AND
+		    idempotency_key = $2
+	`,
+		scope,
+		key,
+	); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+func (p Postgres) PurgeIdempotencyKeys(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	res, err := p.conn(ctx).ExecContext(ctx, `
+		DELETE FROM
+		    idempotency_key
+		WHERE
+		    expires < $1
+	`,
+		before,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("exec: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
@@ -0,0 +1,2220 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Memory stores the data in memory, it is lost on restart. Safe for concurrent use.
+// Behaves the same as Postgres, for tests and the demo mode without a database.
+type Memory struct {
+	store *memoryStore
+}
+
+type memoryStore struct {
+	mu sync.Mutex
+	memoryData
+}
+
+type memoryData struct {
+	users           map[int]memoryUser
+	tasks           map[int]memoryTask
+	history         map[int]memoryHistory
+	idempotencyKeys map[memoryIdempotencyKeyID]memoryIdempotencyKey
+	webhooks        map[int]memoryWebhook
+	deliveries      map[int]WebhookDelivery
+	// calendarTokens the hashes of the calendar tokens by user id.
+	calendarTokens map[int]string
+	reminders      map[int]memoryReminder
+	notifications  map[int]Notification
+	projects       map[int]memoryProject
+	comments       map[int]TaskComment
+	// notificationPreferences enabled by the user and the type.
+	notificationPreferences map[memoryNotificationPreferenceID]bool
+	// events the event log, ordered by Seq.
+	events []memoryEvent
+
+	lastUserID     int
+	lastTaskID     int
+	lastWebhookID  int
+	lastDeliveryID int
+	lastEventSeq   int64
+	lastReminderID int
+
+	lastNotificationID int
+	lastProjectID      int
+	lastCommentID      int
+}
+
+type memoryEvent struct {
+	// userID the user of the log.
+	userID  int
+	event   Event
+	created time.Time
+}
+
+type memoryUser struct {
+	username string
+	password string
+}
+
+type memoryTask struct {
+	userID int
+	task   Task
+	// tags sorted by name, replaced as a whole.
+	tags []string
+	// deleted zero - not in the trash.
+	deleted time.Time
+}
+
+// memoryHistory of the task, it is kept after the task is deleted permanently.
+type memoryHistory struct {
+	userID  int
+	changes []TaskChange
+}
+
+type memoryWebhook struct {
+	// userID 0 - the webhook of the manager.
+	userID  int
+	webhook Webhook
+}
+
+type memoryReminder struct {
+	userID   int
+	reminder Reminder
+	// nextAttempt zero - the reminder is not claimed.
+	nextAttempt time.Time
+}
+
+type memoryProject struct {
+	project Project
+	// members the time each member joined by the user id, the owner included.
+	members map[int]time.Time
+}
+
+type memoryNotificationPreferenceID struct {
+	userID           int
+	notificationType string
+}
+
+type memoryIdempotencyKeyID struct {
+	scope string
+	key   string
+}
+
+type memoryIdempotencyKey struct {
+	response IdempotentResponse
+	expires  time.Time
+}
+
+func NewMemory() Memory {
+	return Memory{
+		store: &memoryStore{
+			memoryData: memoryData{
+				users:           make(map[int]memoryUser),
+				tasks:           make(map[int]memoryTask),
+				history:         make(map[int]memoryHistory),
+				idempotencyKeys: make(map[memoryIdempotencyKeyID]memoryIdempotencyKey),
+				webhooks:        make(map[int]memoryWebhook),
+				deliveries:      make(map[int]WebhookDelivery),
+				calendarTokens:  make(map[int]string),
+				reminders:       make(map[int]memoryReminder),
+				notifications:   make(map[int]Notification),
+				projects:        make(map[int]memoryProject),
+				comments:        make(map[int]TaskComment),
+
+				notificationPreferences: make(map[memoryNotificationPreferenceID]bool),
+			},
+		},
+	}
+}
+
+// Atomic runs fn, each change of the memory is applied at once and is not rolled back if fn fails later.
+// The writes of the events of the changes do not fail.
+func (m Memory) Atomic(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// memoryNow the precision of the timestamp column.
+func memoryNow() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+func (m Memory) CreateNewUser(_ context.Context, username string, password string) (int, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	for _, user := range m.store.users {
+		if user.username == username {
+			return 0, fmt.Errorf("%s: %w", username, ErrUserAlreadyExists)
+		}
+	}
+
+	m.store.lastUserID++
+
+	m.store.users[m.store.lastUserID] = memoryUser{
+		username: username,
+		password: password,
+	}
+
+	return m.store.lastUserID, nil
+}
+
+func (m Memory) DeleteUser(_ context.Context, userID int) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	if _, ok := m.store.users[userID]; !ok {
+		return fmt.Errorf("userID %d: %w", userID, ErrUserNotFound)
+	}
+
+	delete(m.store.users, userID)
+
+	for taskID, t := range m.store.tasks {
+		if t.userID == userID {
+			m.store.deleteTask(taskID)
+		}
+	}
+
+	for taskID, h := range m.store.history {
+		if h.userID == userID {
+			delete(m.store.history, taskID)
+		}
+	}
+
+	for webhookID, w := range m.store.webhooks {
+		if w.userID == userID {
+			m.store.deleteWebhook(webhookID)
+		}
+	}
+
+	m.store.filterEvents(func(e memoryEvent) bool {
+		return e.userID != userID
+	})
+
+	delete(m.store.calendarTokens, userID)
+
+	for id, n := range m.store.notifications {
+		if n.UserID == userID {
+			delete(m.store.notifications, id)
+		}
+	}
+
+	for id := range m.store.notificationPreferences {
+		if id.userID == userID {
+			delete(m.store.notificationPreferences, id)
+		}
+	}
+
+	for id, c := range m.store.comments {
+		if c.UserID == userID {
+			delete(m.store.comments, id)
+		}
+	}
+
+	for projectID, p := range m.store.projects {
+		if p.project.OwnerID == userID {
+			m.store.deleteProject(projectID)
+		} else {
+			delete(p.members, userID)
+		}
+	}
+
+	return nil
+}
+
+func (m Memory) GetUsers(_ context.Context) ([]User, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	users := make([]User, 0, len(m.store.users))
+
+	for userID, user := range m.store.users {
+		users = append(users, User{
+			ID:       userID,
+			Username: user.username,
+		})
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].ID < users[j].ID
+	})
+
+	return users, nil
+}
+
+func (m Memory) GetUserID(_ context.Context, username string) (int, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	for userID, user := range m.store.users {
+		if user.username == username {
+			return userID, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%s: %w", username, ErrUserNotFound)
+}
+
+func (m Memory) GetUserIDByCredentials(_ context.Context, username, password string) (int, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	userID, ok := m.store.userID(username, password)
+	if !ok {
+		return 0, fmt.Errorf("%s: %w", username, ErrUserNotFound)
+	}
+
+	return userID, nil
+}
+
+func (m Memory) UpdateUserPassword(_ context.Context, username, password string) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	for userID, user := range m.store.users {
+		if user.username == username {
+			user.password = password
+			m.store.users[userID] = user
+
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%s: %w", username, ErrUserNotFound)
+}
+
+func (m Memory) CreateTask(_ context.Context, username, password, title string) (int, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	return m.store.createTask(username, password, title, memoryNow())
+}
+
+// CreateTaskWithInjection there is nothing to inject into, the same as CreateTask.
+func (m Memory) CreateTaskWithInjection(ctx context.Context, username, password, title string) (int, error) {
+	return m.CreateTask(ctx, username, password, title)
+}
+
+func (m Memory) GetTask(_ context.Context, username, password string, taskID int) (Task, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	t, err := m.store.task(username, password, taskID, 0, false)
+	if err != nil {
+		return Task{}, err
+	}
+
+	t.task.ID = 0
+
+	return t.task, nil
+}
+
+func (m Memory) GetTasks(_ context.Context, username, password string) ([]Task, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	var tasks []Task
+
+	for _, t := range m.store.userTasks(username, password) {
+		if t.deleted.IsZero() {
+			tasks = append(tasks, t.task)
+		}
+	}
+
+	return tasks, nil
+}
+
+func (m Memory) GetTasksPage(_ context.Context, username, password string, query TaskQuery) ([]Task, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	var tasks []Task
+
+	for _, t := range m.store.userTasks(username, password) {
+		if len(tasks) == query.Limit {
+			break
+		}
+
+		if t.deleted.IsZero() && t.task.ID > query.AfterID &&
+			(query.Completed == nil || t.task.Status == *query.Completed) {
+			tasks = append(tasks, t.task)
+		}
+	}
+
+	return tasks, nil
+}
+
+func (m Memory) CountTasks(_ context.Context, username, password string, completed *bool) (int, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	quantity := 0
+
+	for _, t := range m.store.userTasks(username, password) {
+		if t.deleted.IsZero() && (completed == nil || t.task.Status == *completed) {
+			quantity++
+		}
+	}
+
+	return quantity, nil
+}
+
+// ExportTasks fn is called outside of the lock.
+func (m Memory) ExportTasks(_ context.Context, username, password string, fn func(ExportedTask) error) error {
+	m.store.mu.Lock()
+
+	var tasks []ExportedTask
+
+	for _, t := range m.store.userTasks(username, password) {
+		tasks = append(tasks, ExportedTask{
+			Task:    t.task,
+			Deleted: t.deleted,
+		})
+	}
+
+	m.store.mu.Unlock()
+
+	for _, task := range tasks {
+		if err := fn(task); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m Memory) UpdateTask(
+	_ context.Context, username, password string, taskID, version int, update TaskUpdate,
+) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	return m.store.updateTask(username, password, taskID, version, update, memoryNow())
+}
+
+func (m Memory) DeleteTask(_ context.Context, username, password string, taskID, version int) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	return m.store.moveTaskToTrash(username, password, taskID, version, memoryNow())
+}
+
+func (m Memory) DeleteTasks(_ context.Context, username, password string) ([]int, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	now := memoryNow()
+
+	var taskIDs []int
+
+	for _, t := range m.store.userTasks(username, password) {
+		if t.deleted.IsZero() {
+			t.deleted = now
+			t.task.Version++
+
+			m.store.tasks[t.task.ID] = t
+			m.store.addTaskHistory(t.task.ID, t.userID, username, []TaskChange{taskMovedToTrash}, now)
+
+			taskIDs = append(taskIDs, t.task.ID)
+		}
+	}
+
+	return taskIDs, nil
+}
+
+func (m Memory) GetTrash(_ context.Context, username, password string) ([]DeletedTask, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	var tasks []DeletedTask
+
+	for _, t := range m.store.userTasks(username, password) {
+		if !t.deleted.IsZero() {
+			t.task.Version = 0
+
+			tasks = append(tasks, DeletedTask{
+				Task:    t.task,
+				Deleted: t.deleted,
+			})
+		}
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].Deleted.After(tasks[j].Deleted)
+	})
+
+	return tasks, nil
+}
+
+func (m Memory) RestoreTask(_ context.Context, username, password string, taskID int) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	t, err := m.store.task(username, password, taskID, 0, true)
+	if err != nil || t.deleted.IsZero() {
+		return fmt.Errorf("taskId %d: %w", taskID, ErrTaskNotFound)
+	}
+
+	t.deleted = time.Time{}
+	t.task.Version++
+
+	m.store.tasks[taskID] = t
+	m.store.addTaskHistory(taskID, t.userID, username, []TaskChange{taskRestored}, memoryNow())
+
+	return nil
+}
+
+func (m Memory) RestoreTasks(_ context.Context, username, password string) ([]int, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	now := memoryNow()
+
+	var taskIDs []int
+
+	for _, t := range m.store.userTasks(username, password) {
+		if !t.deleted.IsZero() {
+			t.deleted = time.Time{}
+			t.task.Version++
+
+			m.store.tasks[t.task.ID] = t
+			m.store.addTaskHistory(t.task.ID, t.userID, username, []TaskChange{taskRestored}, now)
+
+			taskIDs = append(taskIDs, t.task.ID)
+		}
+	}
+
+	return taskIDs, nil
+}
+
+func (m Memory) DeleteTaskPermanently(_ context.Context, username, password string, taskID, version int) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	return m.store.deleteTaskPermanently(username, password, taskID, version, memoryNow())
+}
+
+func (m Memory) DeleteTasksPermanently(_ context.Context, username, password string) ([]int, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	now := memoryNow()
+
+	var taskIDs []int
+
+	for _, t := range m.store.userTasks(username, password) {
+		m.store.deleteTask(t.task.ID)
+		m.store.addTaskHistory(t.task.ID, t.userID, username, []TaskChange{taskDeletedPermanently}, now)
+
+		taskIDs = append(taskIDs, t.task.ID)
+	}
+
+	return taskIDs, nil
+}
+
+func (m Memory) PurgeTrash(_ context.Context, before time.Time) (int64, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	now := memoryNow()
+
+	var purged int64
+
+	for taskID, t := range m.store.tasks {
+		if !t.deleted.IsZero() && t.deleted.Before(before) {
+			m.store.deleteTask(taskID)
+			m.store.addTaskHistory(taskID, t.userID, taskHistoryActorSystem, []TaskChange{taskDeletedPermanently}, now)
+
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+func (m Memory) GetTaskHistory(_ context.Context, username, password string, taskID int) ([]TaskChange, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	if changes := m.store.taskHistory(username, password, taskID); len(changes) > 0 {
+		return changes, nil
+	}
+
+	if _, err := m.store.task(username, password, taskID, 0, false); err != nil {
+		return nil, fmt.Errorf("get task: %w", err)
+	}
+
+	return nil, nil
+}
+
+func (m Memory) GetTasksHistory(
+	_ context.Context, username, password string, taskIDs []int,
+) (map[int][]TaskChange, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	histories := make(map[int][]TaskChange)
+
+	for _, taskID := range taskIDs {
+		if changes := m.store.taskHistory(username, password, taskID); len(changes) > 0 {
+			histories[taskID] = changes
+		}
+	}
+
+	return histories, nil
+}
+
+func (m Memory) GetTaskRevision(_ context.Context, username, password string, taskID, revision int) (Task, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	t, err := m.store.task(username, password, taskID, 0, false)
+	if err != nil {
+		return Task{}, fmt.Errorf("get task: %w", err)
+	}
+
+	task, err := taskAtRevision(t.task, m.store.history[taskID].changes, revision)
+	if err != nil {
+		return Task{}, fmt.Errorf("taskId %d: %w", taskID, err)
+	}
+
+	return task, nil
+}
+
+func (m Memory) RevertTask(_ context.Context, username, password string, taskID, revision int) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	t, err := m.store.task(username, password, taskID, 0, false)
+	if err != nil {
+		return fmt.Errorf("select task: %w", err)
+	}
+
+	task, err := taskAtRevision(t.task, m.store.history[taskID].changes, revision)
+	if err != nil {
+		return fmt.Errorf("taskId %d: %w", taskID, err)
+	}
+
+	return m.store.updateTask(username, password, taskID, 0, revertTaskUpdate(task), memoryNow())
+}
+
+// BatchTasks the same as Postgres.BatchTasks, the atomic batch is rolled back to a copy of the data.
+func (m Memory) BatchTasks(
+	_ context.Context, username, password string, operations []TaskOperation, atomic bool,
+) ([]TaskOperationResult, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	var backup memoryData
+	if atomic {
+		backup = m.store.clone()
+	}
+
+	now := memoryNow()
+	results := make([]TaskOperationResult, len(operations))
+
+	for i, op := range operations {
+		taskID, err := m.store.runTaskOperation(username, password, op, now)
+		if err != nil {
+			if atomic {
+				m.store.memoryData = backup
+
+				return rolledBackResults(len(operations), i, err), nil
+			}
+
+			results[i] = TaskOperationResult{Err: err}
+
+			continue
+		}
+
+		results[i] = TaskOperationResult{TaskID: taskID}
+	}
+
+	return results, nil
+}
+
+func (m Memory) ReserveIdempotencyKey(
+	_ context.Context, scope, key, fingerprint string, expires time.Time,
+) (IdempotentResponse, bool, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	id := memoryIdempotencyKeyID{scope: scope, key: key}
+
+	if stored, ok := m.store.idempotencyKeys[id]; ok && !stored.expires.Before(time.Now()) {
+		return stored.response, false, nil
+	}
+
+	m.store.idempotencyKeys[id] = memoryIdempotencyKey{
+		response: IdempotentResponse{Fingerprint: fingerprint},
+		expires:  expires,
+	}
+
+	return IdempotentResponse{}, true, nil
+}
+
+func (m Memory) SaveIdempotentResponse(_ context.Context, scope, key string, response IdempotentResponse) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	id := memoryIdempotencyKeyID{scope: scope, key: key}
+
+	stored, ok := m.store.idempotencyKeys[id]
+	if !ok {
+		return nil
+	}
+
+	response.Fingerprint = stored.response.Fingerprint
+	stored.response = response
+
+	m.store.idempotencyKeys[id] = stored
+
+	return nil
+}
+
+func (m Memory) DeleteIdempotencyKey(_ context.Context, scope, key string) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	delete(m.store.idempotencyKeys, memoryIdempotencyKeyID{scope: scope, key: key})
+
+	return nil
+}
+
+func (m Memory) PurgeIdempotencyKeys(_ context.Context, before time.Time) (int64, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	var purged int64
+
+	for id, stored := range m.store.idempotencyKeys {
+		if stored.expires.Before(before) {
+			delete(m.store.idempotencyKeys, id)
+
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+func (m Memory) CreateWebhook(_ context.Context, userID int, webhook Webhook) (int, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	m.store.lastWebhookID++
+
+	webhook.ID = m.store.lastWebhookID
+	webhook.Events = append([]string(nil), webhook.Events...)
+	webhook.Active = true
+	webhook.Failures = 0
+	webhook.Created = memoryNow()
+
+	m.store.webhooks[webhook.ID] = memoryWebhook{
+		userID:  userID,
+		webhook: webhook,
+	}
+
+	return webhook.ID, nil
+}
+
+func (m Memory) GetWebhooks(_ context.Context, userID int) ([]Webhook, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	var webhooks []Webhook
+
+	for _, w := range m.store.webhooks {
+		if w.userID == userID {
+			webhook := w.webhook
+			webhook.Secret = ""
+
+			webhooks = append(webhooks, webhook)
+		}
+	}
+
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].ID < webhooks[j].ID
+	})
+
+	return webhooks, nil
+}
+
+func (m Memory) DeleteWebhook(_ context.Context, userID, webhookID int) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	if _, err := m.store.webhook(userID, webhookID); err != nil {
+		return err
+	}
+
+	m.store.deleteWebhook(webhookID)
+
+	return nil
+}
+
+func (m Memory) EnableWebhook(_ context.Context, userID, webhookID int) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	w, err := m.store.webhook(userID, webhookID)
+	if err != nil {
+		return err
+	}
+
+	w.webhook.Active = true
+	w.webhook.Failures = 0
+
+	m.store.webhooks[webhookID] = w
+
+	return nil
+}
+
+func (m Memory) GetWebhookDeliveries(_ context.Context, userID, webhookID, limit int) ([]WebhookDelivery, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	if _, err := m.store.webhook(userID, webhookID); err != nil {
+		return nil, err
+	}
+
+	var deliveries []WebhookDelivery
+
+	for _, delivery := range m.store.deliveries {
+		if delivery.WebhookID == webhookID {
+			delivery.URL, delivery.Secret, delivery.Payload = "", "", nil
+
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].ID > deliveries[j].ID
+	})
+
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	return deliveries, nil
+}
+
+func (m Memory) EnqueueWebhookEvent(_ context.Context, event Event) (int64, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return 0, fmt.Errorf("marshal event: %w", err)
+	}
+
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	now := memoryNow()
+
+	var enqueued int64
+
+	for webhookID, w := range m.store.webhooks {
+		if !w.webhook.Active || (w.userID != event.UserID && w.userID != 0) ||
+			!containsString(w.webhook.Events, event.Type) {
+			continue
+		}
+
+		m.store.lastDeliveryID++
+
+		m.store.deliveries[m.store.lastDeliveryID] = WebhookDelivery{
+			ID:          m.store.lastDeliveryID,
+			WebhookID:   webhookID,
+			EventID:     event.ID,
+			Event:       event.Type,
+			Status:      WebhookDeliveryPending,
+			NextAttempt: now,
+			Created:     now,
+			Payload:     payload,
+		}
+
+		enqueued++
+	}
+
+	return enqueued, nil
+}
+
+func (m Memory) ClaimWebhookDeliveries(_ context.Context, limit int, lease time.Duration) ([]WebhookDelivery, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	now := memoryNow()
+
+	var deliveries []WebhookDelivery
+
+	for _, delivery := range m.store.deliveries {
+		if delivery.Status == WebhookDeliveryPending && !delivery.NextAttempt.After(now) &&
+			m.store.webhooks[delivery.WebhookID].webhook.Active {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		if deliveries[i].NextAttempt.Equal(deliveries[j].NextAttempt) {
+			return deliveries[i].ID < deliveries[j].ID
+		}
+
+		return deliveries[i].NextAttempt.Before(deliveries[j].NextAttempt)
+	})
+
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	for i, delivery := range deliveries {
+		delivery.NextAttempt = now.Add(lease)
+		m.store.deliveries[delivery.ID] = delivery
+
+		deliveries[i].NextAttempt = delivery.NextAttempt
+		w := m.store.webhooks[delivery.WebhookID].webhook
+		deliveries[i].URL = w.URL
+		deliveries[i].Secret = w.Secret
+	}
+
+	return deliveries, nil
+}
+
+func (m Memory) SaveWebhookAttempt(_ context.Context, attempt WebhookAttempt) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	delivery, ok := m.store.deliveries[attempt.DeliveryID]
+	if !ok || !delivery.NextAttempt.Equal(attempt.ClaimedUntil) {
+		return nil
+	}
+
+	delivery.Status = attempt.status()
+	delivery.Attempts++
+	delivery.ResponseStatus = attempt.ResponseStatus
+	delivery.Error = attempt.Error
+	delivery.NextAttempt = memoryNow().Add(attempt.RetryAfter)
+
+	m.store.deliveries[delivery.ID] = delivery
+
+	w, ok := m.store.webhooks[delivery.WebhookID]
+	if !ok {
+		return nil
+	}
+
+	if attempt.Succeeded {
+		w.webhook.Failures = 0
+		m.store.webhooks[delivery.WebhookID] = w
+
+		return nil
+	}
+
+	w.webhook.Failures++
+	w.webhook.Active = w.webhook.Active && (attempt.DisableAfter == 0 || w.webhook.Failures < attempt.DisableAfter)
+	m.store.webhooks[delivery.WebhookID] = w
+
+	if w.webhook.Active {
+		return nil
+	}
+
+	for id, d := range m.store.deliveries {
+		if d.WebhookID == delivery.WebhookID && d.Status == WebhookDeliveryPending {
+			d.Status = WebhookDeliveryFailed
+			d.Error = webhookDisabledError
+			m.store.deliveries[id] = d
+		}
+	}
+
+	if w.webhook.Failures == attempt.DisableAfter && w.userID != 0 {
+		m.store.createNotification(webhookDisabledNotification(w.userID, w.webhook.ID, w.webhook.URL, w.webhook.Failures))
+	}
+
+	return nil
+}
+
+func (m Memory) PurgeWebhookDeliveries(_ context.Context, before time.Time) (int64, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	var purged int64
+
+	for id, delivery := range m.store.deliveries {
+		if delivery.Status != WebhookDeliveryPending && delivery.Created.Before(before) {
+			delete(m.store.deliveries, id)
+
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// SaveEvent the subscribers are notified by the caller.
+func (m Memory) SaveEvent(_ context.Context, userID int, event Event) (int64, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	if _, ok := m.store.users[userID]; !ok {
+		return 0, fmt.Errorf("userID %d: %w", userID, ErrUserNotFound)
+	}
+
+	m.store.lastEventSeq++
+	event.Seq = m.store.lastEventSeq
+
+	m.store.events = append(m.store.events, memoryEvent{
+		userID:  userID,
+		event:   event,
+		created: memoryNow(),
+	})
+
+	return event.Seq, nil
+}
+
+func (m Memory) GetEvents(_ context.Context, userID int, afterSeq int64, limit int) ([]Event, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	var events []Event
+
+	for _, e := range m.store.events {
+		if len(events) == limit {
+			break
+		}
+
+		if e.userID == userID && e.event.Seq > afterSeq {
+			events = append(events, e.event)
+		}
+	}
+
+	return events, nil
+}
+
+func (m Memory) GetLastEventSeq(_ context.Context, userID int) (int64, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	for i := len(m.store.events) - 1; i >= 0; i-- {
+		if m.store.events[i].userID == userID {
+			return m.store.events[i].event.Seq, nil
+		}
+	}
+
+	return 0, nil
+}
+
+func (m Memory) PurgeEvents(_ context.Context, before time.Time) (int64, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	return m.store.filterEvents(func(e memoryEvent) bool {
+		return !e.created.Before(before)
+	}), nil
+}
+
+func (m Memory) SetCalendarToken(_ context.Context, userID int, tokenHash string) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	m.store.calendarTokens[userID] = tokenHash
+
+	return nil
+}
+
+func (m Memory) DeleteCalendarToken(_ context.Context, userID int) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	if _, ok := m.store.calendarTokens[userID]; !ok {
+		return fmt.Errorf("userID %d: %w", userID, ErrCalendarTokenNotFound)
+	}
+
+	delete(m.store.calendarTokens, userID)
+
+	return nil
+}
+
+func (m Memory) GetCalendarTasks(_ context.Context, tokenHash string) ([]Task, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	userID := 0
+
+	for id, hash := range m.store.calendarTokens {
+		if hash == tokenHash {
+			userID = id
+		}
+	}
+
+	if userID == 0 {
+		return nil, ErrCalendarTokenNotFound
+	}
+
+	var tasks []Task
+
+	for _, t := range m.store.tasks {
+		if t.userID == userID && t.task.Due != nil && t.deleted.IsZero() {
+			tasks = append(tasks, t.task)
+		}
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].Due.Equal(*tasks[j].Due) {
+			return tasks[i].Due.Before(*tasks[j].Due)
+		}
+
+		return tasks[i].ID < tasks[j].ID
+	})
+
+	return tasks, nil
+}
+
+func (m Memory) CreateReminder(_ context.Context, userID, taskID int, reminder Reminder) (int, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	if _, err := m.store.userTask(userID, taskID); err != nil {
+		return 0, err
+	}
+
+	m.store.lastReminderID++
+
+	reminder.ID = m.store.lastReminderID
+	reminder.TaskID = taskID
+	reminder.Status = ReminderPending
+	reminder.Attempts = 0
+	reminder.Error = ""
+	reminder.Sent = nil
+	reminder.Created = memoryNow()
+
+	if reminder.RemindAt != nil {
+		remindAt := reminder.RemindAt.UTC().Truncate(time.Microsecond)
+		reminder.RemindAt = &remindAt
+	}
+
+	if reminder.BeforeMinutes != nil {
+		beforeMinutes := *reminder.BeforeMinutes
+		reminder.BeforeMinutes = &beforeMinutes
+	}
+
+	m.store.reminders[reminder.ID] = memoryReminder{
+		userID:   userID,
+		reminder: reminder,
+	}
+
+	return reminder.ID, nil
+}
+
+func (m Memory) GetReminders(_ context.Context, userID, taskID int) ([]Reminder, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	t, err := m.store.userTask(userID, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	var reminders []Reminder
+
+	for _, r := range m.store.reminders {
+		if r.userID == userID && r.reminder.TaskID == taskID {
+			reminder := r.reminder
+			reminder.FireAt = reminderFireAt(reminder.RemindAt, reminder.BeforeMinutes, t.task.Due)
+
+			reminders = append(reminders, reminder)
+		}
+	}
+
+	sort.Slice(reminders, func(i, j int) bool {
+		return reminders[i].ID < reminders[j].ID
+	})
+
+	return reminders, nil
+}
+
+func (m Memory) DeleteReminder(_ context.Context, userID, taskID, reminderID int) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	r, ok := m.store.reminders[reminderID]
+	if !ok || r.userID != userID || r.reminder.TaskID != taskID {
+		return fmt.Errorf("reminderId %d: %w", reminderID, ErrReminderNotFound)
+	}
+
+	delete(m.store.reminders, reminderID)
+
+	return nil
+}
+
+func (m Memory) ClaimReminders(_ context.Context, now time.Time, limit int, lease time.Duration) ([]Reminder, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	var reminders []Reminder
+
+	for _, r := range m.store.reminders {
+		t, ok := m.store.tasks[r.reminder.TaskID]
+		if !ok || t.task.Status || !t.deleted.IsZero() || r.reminder.Status != ReminderPending ||
+			r.nextAttempt.After(now) {
+			continue
+		}
+
+		reminder := r.reminder
+		reminder.FireAt = reminderFireAt(reminder.RemindAt, reminder.BeforeMinutes, t.task.Due)
+
+		if reminder.FireAt == nil || reminder.FireAt.After(now) {
+			continue
+		}
+
+		task := t.task
+		reminder.UserID = r.userID
+		reminder.Task = &task
+		reminder.ClaimedUntil = now.Add(lease)
+
+		reminders = append(reminders, reminder)
+	}
+
+	sort.Slice(reminders, func(i, j int) bool {
+		if !reminders[i].FireAt.Equal(*reminders[j].FireAt) {
+			return reminders[i].FireAt.Before(*reminders[j].FireAt)
+		}
+
+		return reminders[i].ID < reminders[j].ID
+	})
+
+	if len(reminders) > limit {
+		reminders = reminders[:limit]
+	}
+
+	for _, reminder := range reminders {
+		r := m.store.reminders[reminder.ID]
+		r.nextAttempt = reminder.ClaimedUntil
+		m.store.reminders[reminder.ID] = r
+	}
+
+	return reminders, nil
+}
+
+func (m Memory) SaveReminderAttempt(_ context.Context, attempt ReminderAttempt) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	r, ok := m.store.reminders[attempt.ReminderID]
+	if !ok || !r.nextAttempt.Equal(attempt.ClaimedUntil) {
+		return nil
+	}
+
+	r.reminder.Status = attempt.status()
+	r.reminder.Attempts++
+	r.reminder.Error = attempt.Error
+	r.reminder.Sent = nil
+	r.nextAttempt = attempt.Time.Add(attempt.RetryAfter)
+
+	if attempt.Succeeded {
+		sent := attempt.Time.UTC()
+		r.reminder.Sent = &sent
+	}
+
+	m.store.reminders[attempt.ReminderID] = r
+
+	return nil
+}
+
+func (m Memory) CreateNotification(_ context.Context, notification Notification) (int, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	return m.store.createNotification(notification), nil
+}
+
+func (m Memory) GetNotifications(_ context.Context, userID int, query NotificationQuery) ([]Notification, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	var notifications []Notification
+
+	for _, n := range m.store.notifications {
+		if n.UserID != userID || (query.Unread && n.Read != nil) || (query.BeforeID != 0 && n.ID >= query.BeforeID) {
+			continue
+		}
+
+		notifications = append(notifications, n)
+	}
+
+	sort.Slice(notifications, func(i, j int) bool {
+		return notifications[i].ID > notifications[j].ID
+	})
+
+	if len(notifications) > query.Limit {
+		notifications = notifications[:query.Limit]
+	}
+
+	return notifications, nil
+}
+
+func (m Memory) GetUnreadNotifications(_ context.Context, userID int) (map[string]int, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	unread := make(map[string]int)
+
+	for _, n := range m.store.notifications {
+		if n.UserID == userID && n.Read == nil {
+			unread[n.Type]++
+		}
+	}
+
+	return unread, nil
+}
+
+func (m Memory) ReadNotification(_ context.Context, userID, notificationID int) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	n, ok := m.store.notifications[notificationID]
+	if !ok || n.UserID != userID {
+		return fmt.Errorf("notificationId %d: %w", notificationID, ErrNotificationNotFound)
+	}
+
+	if n.Read == nil {
+		read := memoryNow()
+		n.Read = &read
+		m.store.notifications[notificationID] = n
+	}
+
+	return nil
+}
+
+func (m Memory) ReadNotifications(_ context.Context, userID int) (int64, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	read := memoryNow()
+
+	var quantity int64
+
+	for id, n := range m.store.notifications {
+		if n.UserID == userID && n.Read == nil {
+			n.Read = &read
+			m.store.notifications[id] = n
+
+			quantity++
+		}
+	}
+
+	return quantity, nil
+}
+
+func (m Memory) GetNotificationPreferences(_ context.Context, userID int) ([]NotificationPreference, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	enabled := make(map[string]bool)
+
+	for id, e := range m.store.notificationPreferences {
+		if id.userID == userID {
+			enabled[id.notificationType] = e
+		}
+	}
+
+	return notificationPreferences(enabled), nil
+}
+
+func (m Memory) SetNotificationPreferences(_ context.Context, userID int, preferences []NotificationPreference) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	for _, preference := range preferences {
+		m.store.notificationPreferences[memoryNotificationPreferenceID{
+			userID:           userID,
+			notificationType: preference.Type,
+		}] = preference.Enabled
+	}
+
+	return nil
+}
+
+func (m Memory) PurgeNotifications(_ context.Context, before time.Time, maxPerUser int) (int64, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	var (
+		purged int64
+		kept   = make(map[int][]int)
+	)
+
+	for id, n := range m.store.notifications {
+		if n.Created.Before(before) {
+			delete(m.store.notifications, id)
+
+			purged++
+
+			continue
+		}
+
+		kept[n.UserID] = append(kept[n.UserID], id)
+	}
+
+	if maxPerUser == 0 {
+		return purged, nil
+	}
+
+	for _, ids := range kept {
+		if len(ids) <= maxPerUser {
+			continue
+		}
+
+		sort.Sort(sort.Reverse(sort.IntSlice(ids)))
+
+		for _, id := range ids[maxPerUser:] {
+			delete(m.store.notifications, id)
+
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+func (m Memory) CreateProject(_ context.Context, userID int, name string) (int, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	if _, ok := m.store.users[userID]; !ok {
+		return 0, fmt.Errorf("userID %d: %w", userID, ErrUserNotFound)
+	}
+
+	now := memoryNow()
+
+	m.store.lastProjectID++
+
+	m.store.projects[m.store.lastProjectID] = memoryProject{
+		project: Project{
+			ID:      m.store.lastProjectID,
+			Name:    name,
+			OwnerID: userID,
+			Created: now,
+		},
+		members: map[int]time.Time{userID: now},
+	}
+
+	return m.store.lastProjectID, nil
+}
+
+func (m Memory) GetProjects(_ context.Context, userID int) ([]Project, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	var projects []Project
+
+	for _, p := range m.store.projects {
+		if _, ok := p.members[userID]; ok {
+			projects = append(projects, p.project)
+		}
+	}
+
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].ID < projects[j].ID
+	})
+
+	return projects, nil
+}
+
+func (m Memory) DeleteProject(_ context.Context, userID, projectID int) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	p, ok := m.store.projects[projectID]
+	if !ok || p.project.OwnerID != userID {
+		return fmt.Errorf("projectId %d: %w", projectID, ErrProjectNotFound)
+	}
+
+	m.store.deleteProject(projectID)
+
+	return nil
+}
+
+func (m Memory) GetProjectMembers(_ context.Context, userID, projectID int) ([]ProjectMember, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	p, err := m.store.memberProject(userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	members := make([]ProjectMember, 0, len(p.members))
+
+	for memberID, joined := range p.members {
+		members = append(members, ProjectMember{
+			UserID:   memberID,
+			Username: m.store.users[memberID].username,
+			Joined:   joined,
+		})
+	}
+
+	sort.Slice(members, func(i, j int) bool {
+		if !members[i].Joined.Equal(members[j].Joined) {
+			return members[i].Joined.Before(members[j].Joined)
+		}
+
+		return members[i].UserID < members[j].UserID
+	})
+
+	return members, nil
+}
+
+func (m Memory) AddProjectMember(_ context.Context, userID, projectID int, username string) (int, bool, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	p, ok := m.store.projects[projectID]
+	if !ok || p.project.OwnerID != userID {
+		return 0, false, fmt.Errorf("projectId %d: %w", projectID, ErrProjectNotFound)
+	}
+
+	for memberID, user := range m.store.users {
+		if user.username != username {
+			continue
+		}
+
+		if _, ok := p.members[memberID]; ok {
+			return memberID, false, nil
+		}
+
+		p.members[memberID] = memoryNow()
+
+		return memberID, true, nil
+	}
+
+	return 0, false, fmt.Errorf("%s: %w", username, ErrUserNotFound)
+}
+
+func (m Memory) RemoveProjectMember(_ context.Context, userID, projectID, memberID int) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	p, err := m.store.memberProject(userID, projectID)
+	if err != nil {
+		return err
+	}
+
+	if _, ok := p.members[memberID]; !ok || memberID == p.project.OwnerID ||
+		(userID != p.project.OwnerID && userID != memberID) {
+		return fmt.Errorf("userId %d: %w", memberID, ErrUserNotFound)
+	}
+
+	m.store.removeProjectMember(projectID, memberID)
+
+	return nil
+}
+
+func (m Memory) IsProjectMember(_ context.Context, userID, projectID int) (bool, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	_, err := m.store.memberProject(userID, projectID)
+
+	return err == nil, nil
+}
+
+func (m Memory) GetProjectMemberIDs(_ context.Context, projectID int) ([]int, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	var userIDs []int
+
+	for memberID := range m.store.projects[projectID].members {
+		userIDs = append(userIDs, memberID)
+	}
+
+	sort.Ints(userIDs)
+
+	return userIDs, nil
+}
+
+func (m Memory) GetTaskUserIDs(_ context.Context, taskID int) ([]int, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	t, ok := m.store.tasks[taskID]
+	if !ok {
+		return nil, nil
+	}
+
+	userIDs := []int{t.userID}
+
+	for memberID := range m.store.projects[t.task.ProjectID].members {
+		if memberID != t.userID {
+			userIDs = append(userIDs, memberID)
+		}
+	}
+
+	sort.Ints(userIDs)
+
+	return userIDs, nil
+}
+
+func (m Memory) GetTaskProjects(_ context.Context, userID int) (map[int]int, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	projects := make(map[int]int)
+
+	for taskID, t := range m.store.tasks {
+		if t.userID == userID && t.task.ProjectID != 0 {
+			projects[taskID] = t.task.ProjectID
+		}
+	}
+
+	return projects, nil
+}
+
+func (m Memory) SetTaskProject(_ context.Context, username, password string, taskID, projectID int) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	if projectID != 0 {
+		userID, _ := m.store.userID(username, password)
+
+		if _, err := m.store.memberProject(userID, projectID); err != nil {
+			return err
+		}
+	}
+
+	t, err := m.store.task(username, password, taskID, 0, false)
+	if err != nil {
+		return err
+	}
+
+	t.task.ProjectID = projectID
+	t.task.Updated = memoryNow()
+	t.task.Version++
+
+	m.store.tasks[taskID] = t
+
+	return nil
+}
+
+func (m Memory) GetProjectTasks(_ context.Context, userID, projectID int) ([]Task, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	if _, err := m.store.memberProject(userID, projectID); err != nil {
+		return nil, err
+	}
+
+	var tasks []Task
+
+	for _, t := range m.store.tasks {
+		if t.task.ProjectID == projectID && t.deleted.IsZero() {
+			tasks = append(tasks, t.task)
+		}
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].ID < tasks[j].ID
+	})
+
+	return tasks, nil
+}
+
+func (m Memory) SetTaskTags(_ context.Context, userID, taskID int, tags []string) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	t, err := m.store.userTask(userID, taskID)
+	if err != nil {
+		return err
+	}
+
+	t.tags = nil
+
+	for _, tag := range tags {
+		if !containsString(t.tags, tag) {
+			t.tags = append(t.tags, tag)
+		}
+	}
+
+	sort.Strings(t.tags)
+
+	m.store.tasks[taskID] = t
+
+	return nil
+}
+
+func (m Memory) GetTasksTags(_ context.Context, userID int, taskIDs []int) (map[int][]string, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	tags := make(map[int][]string)
+
+	for _, taskID := range taskIDs {
+		if t, ok := m.store.visibleTask(userID, taskID); ok && len(t.tags) > 0 {
+			tags[taskID] = append([]string(nil), t.tags...)
+		}
+	}
+
+	return tags, nil
+}
+
+func (m Memory) CreateTaskComment(_ context.Context, userID, taskID int, text string) (int, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	if _, ok := m.store.visibleTask(userID, taskID); !ok {
+		return 0, fmt.Errorf("taskId %d: %w", taskID, ErrTaskNotFound)
+	}
+
+	m.store.lastCommentID++
+
+	m.store.comments[m.store.lastCommentID] = TaskComment{
+		ID:      m.store.lastCommentID,
+		TaskID:  taskID,
+		UserID:  userID,
+		Text:    text,
+		Created: memoryNow(),
+	}
+
+	return m.store.lastCommentID, nil
+}
+
+func (m Memory) GetTasksComments(_ context.Context, userID int, taskIDs []int) (map[int][]TaskComment, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	comments := make(map[int][]TaskComment)
+
+	for _, c := range m.store.comments {
+		if !containsInt(taskIDs, c.TaskID) {
+			continue
+		}
+
+		if _, ok := m.store.visibleTask(userID, c.TaskID); !ok {
+			continue
+		}
+
+		c.Username = m.store.users[c.UserID].username
+
+		comments[c.TaskID] = append(comments[c.TaskID], c)
+	}
+
+	for _, taskComments := range comments {
+		sort.Slice(taskComments, func(i, j int) bool {
+			if !taskComments[i].Created.Equal(taskComments[j].Created) {
+				return taskComments[i].Created.Before(taskComments[j].Created)
+			}
+
+			return taskComments[i].ID < taskComments[j].ID
+		})
+	}
+
+	return comments, nil
+}
+
+func (m Memory) DeleteTaskComment(_ context.Context, userID, commentID int) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	c, ok := m.store.comments[commentID]
+	if !ok || c.UserID != userID {
+		return fmt.Errorf("commentId %d: %w", commentID, ErrCommentNotFound)
+	}
+
+	delete(m.store.comments, commentID)
+
+	return nil
+}
+
+func (m Memory) SetTaskParent(_ context.Context, username, password string, taskID, parentID int) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	t, err := m.store.task(username, password, taskID, 0, false)
+	if err != nil {
+		return err
+	}
+
+	if parentID != 0 {
+		parent, err := m.store.task(username, password, parentID, 0, false)
+		if err != nil {
+			return err
+		}
+
+		if parentID == taskID || parent.task.ParentID != 0 {
+			return fmt.Errorf("taskId %d, parentId %d: %w", taskID, parentID, ErrTaskParentInvalid)
+		}
+
+		for _, s := range m.store.tasks {
+			if s.task.ParentID == taskID {
+				return fmt.Errorf("taskId %d, parentId %d: %w", taskID, parentID, ErrTaskParentInvalid)
+			}
+		}
+	}
+
+	t.task.ParentID = parentID
+	t.task.Updated = memoryNow()
+	t.task.Version++
+
+	m.store.tasks[taskID] = t
+
+	return nil
+}
+
+func (m Memory) GetSubtasks(_ context.Context, userID int, parentIDs []int) (map[int][]Task, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	subtasks := make(map[int][]Task)
+
+	for taskID, t := range m.store.tasks {
+		if t.task.ParentID == 0 || !containsInt(parentIDs, t.task.ParentID) {
+			continue
+		}
+
+		if _, ok := m.store.visibleTask(userID, taskID); ok {
+			subtasks[t.task.ParentID] = append(subtasks[t.task.ParentID], t.task)
+		}
+	}
+
+	for _, tasks := range subtasks {
+		sort.Slice(tasks, func(i, j int) bool {
+			return tasks[i].ID < tasks[j].ID
+		})
+	}
+
+	return subtasks, nil
+}
+
+// createNotification returns 0 if the user disabled the type.
+func (d *memoryData) createNotification(notification Notification) int {
+	enabled, ok := d.notificationPreferences[memoryNotificationPreferenceID{
+		userID:           notification.UserID,
+		notificationType: notification.Type,
+	}]
+	if ok && !enabled {
+		return 0
+	}
+
+	d.lastNotificationID++
+
+	notification.ID = d.lastNotificationID
+	notification.Read = nil
+	notification.Created = memoryNow()
+
+	d.notifications[notification.ID] = notification
+
+	return notification.ID
+}
+
+// filterEvents keeps the events of the log for which keep is true, returns the number of the deleted ones.
+func (d *memoryData) filterEvents(keep func(e memoryEvent) bool) int64 {
+	events := d.events[:0]
+
+	for _, e := range d.events {
+		if keep(e) {
+			events = append(events, e)
+		}
+	}
+
+	deleted := int64(len(d.events) - len(events))
+	d.events = events
+
+	return deleted
+}
+
+func (d *memoryData) userID(username, password string) (int, bool) {
+	for userID, user := range d.users {
+		if user.username == username && user.password == password {
+			return userID, true
+		}
+	}
+
+	return 0, false
+}
+
+// userTasks including the trash, sorted by id.
+func (d *memoryData) userTasks(username, password string) []memoryTask {
+	userID, ok := d.userID(username, password)
+	if !ok {
+		return nil
+	}
+
+	var tasks []memoryTask
+
+	for _, t := range d.tasks {
+		if t.userID == userID {
+			tasks = append(tasks, t)
+		}
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].task.ID < tasks[j].task.ID
+	})
+
+	return tasks
+}
+
+// task the same errors as taskNotAffectedError. Version 0 - any version.
+func (d *memoryData) task(username, password string, taskID, version int, withTrash bool) (memoryTask, error) {
+	userID, ok := d.userID(username, password)
+	if !ok {
+		return memoryTask{}, fmt.Errorf("taskId %d: %w", taskID, ErrTaskNotFound)
+	}
+
+	t, ok := d.tasks[taskID]
+	if !ok || t.userID != userID || (!withTrash && !t.deleted.IsZero()) {
+		return memoryTask{}, fmt.Errorf("taskId %d: %w", taskID, ErrTaskNotFound)
+	}
+
+	if version != 0 && version != t.task.Version {
+		return memoryTask{}, fmt.Errorf("taskId %d: version %d: %w", taskID, version, ErrVersionMismatch)
+	}
+
+	return t, nil
+}
+
+func (d *memoryData) createTask(username, password, title string, now time.Time) (int, error) {
+	userID, ok := d.userID(username, password)
+	if !ok {
+		return 0, fmt.Errorf("%s: %w", username, ErrUserNotFound)
+	}
+
+	d.lastTaskID++
+
+	d.tasks[d.lastTaskID] = memoryTask{
+		userID: userID,
+		task: Task{
+			ID:      d.lastTaskID,
+			Title:   title,
+			Created: now,
+			Updated: now,
+			Version: 1,
+		},
+	}
+
+	return d.lastTaskID, nil
+}
+
+func (d *memoryData) updateTask(
+	username, password string, taskID, version int, update TaskUpdate, now time.Time,
+) error {
+	t, err := d.task(username, password, taskID, version, false)
+	if err != nil {
+		return err
+	}
+
+	oldTask := t.task
+	t.task = update.apply(t.task, now)
+
+	d.tasks[taskID] = t
+
+	if changes := diffTask(oldTask, t.task); len(changes) > 0 {
+		d.addTaskHistory(taskID, t.userID, username, changes, now)
+	}
+
+	return nil
+}
+
+// addTaskHistory the changes as a new revision.
+func (d *memoryData) addTaskHistory(taskID, userID int, actor string, changes []TaskChange, now time.Time) {
+	h := d.history[taskID]
+	h.userID = userID
+
+	revision := 1
+	if len(h.changes) > 0 {
+		revision = h.changes[len(h.changes)-1].Revision + 1
+	}
+
+	for _, change := range changes {
+		change.Revision = revision
+		change.Actor = actor
+		change.Changed = now
+
+		h.changes = append(h.changes, change)
+	}
+
+	d.history[taskID] = h
+}
+
+// taskHistory a copy of the history of the task of the user, also of the deleted one.
+func (d *memoryData) taskHistory(username, password string, taskID int) []TaskChange {
+	userID, ok := d.userID(username, password)
+	if !ok {
+		return nil
+	}
+
+	h, ok := d.history[taskID]
+	if !ok || h.userID != userID {
+		return nil
+	}
+
+	return append([]TaskChange(nil), h.changes...)
+}
+
+func (d *memoryData) moveTaskToTrash(username, password string, taskID, version int, now time.Time) error {
+	t, err := d.task(username, password, taskID, version, false)
+	if err != nil {
+		return err
+	}
+
+	t.deleted = now
+	t.task.Version++
+
+	d.tasks[taskID] = t
+	d.addTaskHistory(taskID, t.userID, username, []TaskChange{taskMovedToTrash}, now)
+
+	return nil
+}
+
+func (d *memoryData) deleteTaskPermanently(username, password string, taskID, version int, now time.Time) error {
+	t, err := d.task(username, password, taskID, version, true)
+	if err != nil {
+		return err
+	}
+
+	d.deleteTask(taskID)
+	d.addTaskHistory(taskID, t.userID, username, []TaskChange{taskDeletedPermanently}, now)
+
+	return nil
+}
+
+// deleteTask with its reminders and comments, its notifications are kept without the task and its history is kept.
+// Its subtasks are not subtasks anymore.
+func (d *memoryData) deleteTask(taskID int) {
+	delete(d.tasks, taskID)
+
+	for id, t := range d.tasks {
+		if t.task.ParentID == taskID {
+			t.task.ParentID = 0
+			d.tasks[id] = t
+		}
+	}
+
+	for id, c := range d.comments {
+		if c.TaskID == taskID {
+			delete(d.comments, id)
+		}
+	}
+
+	for id, r := range d.reminders {
+		if r.reminder.TaskID == taskID {
+			delete(d.reminders, id)
+		}
+	}
+
+	for id, n := range d.notifications {
+		if n.TaskID == taskID {
+			n.TaskID = 0
+			d.notifications[id] = n
+		}
+	}
+}
+
+// userTask the task of the user outside the trash.
+func (d *memoryData) userTask(userID, taskID int) (memoryTask, error) {
+	t, ok := d.tasks[taskID]
+	if !ok || t.userID != userID || !t.deleted.IsZero() {
+		return memoryTask{}, fmt.Errorf("taskId %d: %w", taskID, ErrTaskNotFound)
+	}
+
+	return t, nil
+}
+
+// visibleTask the task of the user or of the project of the user, outside the trash.
+func (d *memoryData) visibleTask(userID, taskID int) (memoryTask, bool) {
+	t, ok := d.tasks[taskID]
+	if !ok || !t.deleted.IsZero() {
+		return memoryTask{}, false
+	}
+
+	if t.userID == userID {
+		return t, true
+	}
+
+	_, member := d.projects[t.task.ProjectID].members[userID]
+
+	return t, member
+}
+
+// memberProject the project the user is a member of.
+func (d *memoryData) memberProject(userID, projectID int) (memoryProject, error) {
+	p, ok := d.projects[projectID]
+	if !ok {
+		return memoryProject{}, fmt.Errorf("projectId %d: %w", projectID, ErrProjectNotFound)
+	}
+
+	if _, ok := p.members[userID]; !ok {
+		return memoryProject{}, fmt.Errorf("projectId %d: %w", projectID, ErrProjectNotFound)
+	}
+
+	return p, nil
+}
+
+// deleteProject its tasks leave it.
+func (d *memoryData) deleteProject(projectID int) {
+	delete(d.projects, projectID)
+
+	for taskID, t := range d.tasks {
+		if t.task.ProjectID == projectID {
+			t.task.ProjectID = 0
+			d.tasks[taskID] = t
+		}
+	}
+}
+
+// removeProjectMember the tasks of the member leave the project.
+func (d *memoryData) removeProjectMember(projectID, memberID int) {
+	delete(d.projects[projectID].members, memberID)
+
+	for taskID, t := range d.tasks {
+		if t.userID == memberID && t.task.ProjectID == projectID {
+			t.task.ProjectID = 0
+			d.tasks[taskID] = t
+		}
+	}
+}
+
+func (d *memoryData) webhook(userID, webhookID int) (memoryWebhook, error) {
+	w, ok := d.webhooks[webhookID]
+	if !ok || w.userID != userID {
+		return memoryWebhook{}, fmt.Errorf("webhookId %d: %w", webhookID, ErrWebhookNotFound)
+	}
+
+	return w, nil
+}
+
+// deleteWebhook with its deliveries.
+func (d *memoryData) deleteWebhook(webhookID int) {
+	delete(d.webhooks, webhookID)
+
+	for id, delivery := range d.deliveries {
+		if delivery.WebhookID == webhookID {
+			delete(d.deliveries, id)
+		}
+	}
+}
+
+func (d *memoryData) runTaskOperation(username, password string, op TaskOperation, now time.Time) (int, error) {
+	switch op.Op {
+	case TaskOperationCreate:
+		return d.createTask(username, password, op.Title, now)
+
+	case TaskOperationUpdate:
+		return op.TaskID, d.updateTask(username, password, op.TaskID, op.Version, op.Update, now)
+
+	case TaskOperationDelete:
+		if op.Permanent {
+			return op.TaskID, d.deleteTaskPermanently(username, password, op.TaskID, op.Version, now)
+		}
+
+		return op.TaskID, d.moveTaskToTrash(username, password, op.TaskID, op.Version, now)
+	}
+
+	return 0, fmt.Errorf("%w: %s", errUnknownOperation, op.Op)
+}
+
+func (d *memoryData) clone() memoryData {
+	c := memoryData{
+		users:           make(map[int]memoryUser, len(d.users)),
+		tasks:           make(map[int]memoryTask, len(d.tasks)),
+		history:         make(map[int]memoryHistory, len(d.history)),
+		idempotencyKeys: make(map[memoryIdempotencyKeyID]memoryIdempotencyKey, len(d.idempotencyKeys)),
+		webhooks:        make(map[int]memoryWebhook, len(d.webhooks)),
+		deliveries:      make(map[int]WebhookDelivery, len(d.deliveries)),
+		calendarTokens:  make(map[int]string, len(d.calendarTokens)),
+		reminders:       make(map[int]memoryReminder, len(d.reminders)),
+		notifications:   make(map[int]Notification, len(d.notifications)),
+		projects:        make(map[int]memoryProject, len(d.projects)),
+		comments:        make(map[int]TaskComment, len(d.comments)),
+		lastUserID:      d.lastUserID,
+		lastTaskID:      d.lastTaskID,
+		lastWebhookID:   d.lastWebhookID,
+		lastDeliveryID:  d.lastDeliveryID,
+		events:          append([]memoryEvent(nil), d.events...),
+		lastEventSeq:    d.lastEventSeq,
+		lastReminderID:  d.lastReminderID,
+
+		notificationPreferences: make(map[memoryNotificationPreferenceID]bool, len(d.notificationPreferences)),
+		lastNotificationID:      d.lastNotificationID,
+		lastProjectID:           d.lastProjectID,
+		lastCommentID:           d.lastCommentID,
+	}
+
+	for k, v := range d.users {
+		c.users[k] = v
+	}
+
+	for k, v := range d.tasks {
+		c.tasks[k] = v
+	}
+
+	for k, v := range d.history {
+		c.history[k] = memoryHistory{userID: v.userID, changes: append([]TaskChange(nil), v.changes...)}
+	}
+
+	for k, v := range d.idempotencyKeys {
+		c.idempotencyKeys[k] = v
+	}
+
+	for k, v := range d.webhooks {
+		c.webhooks[k] = v
+	}
+
+	for k, v := range d.deliveries {
+		c.deliveries[k] = v
+	}
+
+	for k, v := range d.calendarTokens {
+		c.calendarTokens[k] = v
+	}
+
+	for k, v := range d.reminders {
+		c.reminders[k] = v
+	}
+
+	for k, v := range d.notifications {
+		c.notifications[k] = v
+	}
+
+	for k, v := range d.notificationPreferences {
+		c.notificationPreferences[k] = v
+	}
+
+	for k, v := range d.comments {
+		c.comments[k] = v
+	}
+
+	for k, v := range d.projects {
+		members := make(map[int]time.Time, len(v.members))
+
+		for memberID, joined := range v.members {
+			members[memberID] = joined
+		}
+
+		c.projects[k] = memoryProject{project: v.project, members: members}
+	}
+
+	return c
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
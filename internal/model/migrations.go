@@ -0,0 +1,66 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+
+	"github.com/sirupsen/logrus"
+
+	"taskmanager/internal/migrate"
+)
+
+//go:embed migrations/postgres/*.sql migrations/sqlite/*.sql
+var migrations embed.FS
+
+func (p Postgres) Migrator() (migrate.Migrator, error) {
+	return newMigrator(p.Pool, migrate.DialectPostgres, p.Logger)
+}
+
+func (s SQLite) Migrator() (migrate.Migrator, error) {
+	return newMigrator(s.Pool, migrate.DialectSQLite, s.Logger)
+}
+
+// Migrate applies the pending migrations.
+func (p Postgres) Migrate(ctx context.Context) error {
+	return runMigrations(ctx, p.Migrator)
+}
+
+// Migrate applies the pending migrations.
+func (s SQLite) Migrate(ctx context.Context) error {
+	return runMigrations(ctx, s.Migrator)
+}
+
+func runMigrations(ctx context.Context, migrator func() (migrate.Migrator, error)) error {
+	m, err := migrator()
+	if err != nil {
+		return fmt.Errorf("migrator: %w", err)
+	}
+
+	if _, err := m.Up(ctx); err != nil {
+		return fmt.Errorf("up: %w", err)
+	}
+
+	return nil
+}
+
+func newMigrator(pool *sql.DB, dialect string, logger *logrus.Logger) (migrate.Migrator, error) {
+	dir, err := fs.Sub(migrations, "migrations/"+dialect)
+	if err != nil {
+		return migrate.Migrator{}, fmt.Errorf("sub: %w", err)
+	}
+
+	loaded, err := migrate.Load(dir)
+	if err != nil {
+		return migrate.Migrator{}, fmt.Errorf("load: %w", err)
+	}
+
+	return migrate.Migrator{
+		Pool:       pool,
+		Dialect:    dialect,
+		Migrations: loaded,
+		Logger:     logger,
+	}, nil
+}
@@ -0,0 +1,3 @@
+drop table task;
+
+drop table auth;
@@ -1,4 +1,4 @@
-create table auth
+create table if not exists auth
 (
     user_id  serial not null
         constraint auth__pk
@@ -7,13 +7,13 @@ create table auth
     password text                                             not null
 );
 
-create unique index auth__username__uindex
+create unique index if not exists auth__username__uindex
     on auth (username);
 
-create index auth__password__index
+create index if not exists auth__password__index
     on auth (password);
 
-create table task
+create table if not exists task
 (
     task_id   serial not null
         constraint user__pk
@@ -29,9 +29,8 @@ create table task
     completed timestamp
 );
 
-create index task__user_id__index
+create index if not exists task__user_id__index
     on task (user_id);
 
-create index task__status__index
+create index if not exists task__status__index
     on task (status);
-
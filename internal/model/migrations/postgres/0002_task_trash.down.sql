@@ -0,0 +1,4 @@
+drop index task__deleted_at__index;
+
+alter table task
+    drop column deleted_at;
@@ -0,0 +1,5 @@
+alter table task
+    add column if not exists deleted_at timestamp;
+
+create index if not exists task__deleted_at__index
+    on task (deleted_at);
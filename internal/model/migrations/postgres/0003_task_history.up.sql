@@ -0,0 +1,19 @@
+create table if not exists task_history
+(
+    history_id serial not null
+        constraint task_history__pk
+            primary key,
+    task_id    integer                                         not null
+        constraint task_history__task_id__fk
+            references task
+            on update cascade on delete cascade,
+    revision   integer                                         not null,
+    field      text                                            not null,
+    old_value  text,
+    new_value  text,
+    actor      text                                            not null,
+    changed    timestamp                                       not null
+);
+
+create unique index if not exists task_history__task_id__revision__field__uindex
+    on task_history (task_id, revision, field);
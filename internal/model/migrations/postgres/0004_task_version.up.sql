@@ -0,0 +1,2 @@
+alter table task
+    add column if not exists version integer default 1 not null;
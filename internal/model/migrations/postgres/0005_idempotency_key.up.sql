@@ -0,0 +1,16 @@
+create table if not exists idempotency_key
+(
+    scope           text      not null,
+    idempotency_key text      not null,
+    fingerprint     text      not null,
+    status          integer,
+    content_type    text,
+    body            bytea,
+    created         timestamp not null,
+    expires         timestamp not null,
+    constraint idempotency_key__pk
+        primary key (scope, idempotency_key)
+);
+
+create index if not exists idempotency_key__expires__index
+    on idempotency_key (expires);
@@ -0,0 +1,45 @@
+create table if not exists webhook
+(
+    webhook_id serial not null
+        constraint webhook__pk
+            primary key,
+    user_id    integer
+        constraint webhook__user_id__fk
+            references auth
+            on update cascade on delete cascade,
+    url        text                                            not null,
+    secret     text                                            not null,
+    events     text                                            not null,
+    active     boolean                                         not null,
+    failures   integer                                         not null,
+    created    timestamp                                       not null
+);
+
+create index if not exists webhook__user_id__index
+    on webhook (user_id);
+
+create table if not exists webhook_delivery
+(
+    delivery_id     serial not null
+        constraint webhook_delivery__pk
+            primary key,
+    webhook_id      integer                                    not null
+        constraint webhook_delivery__webhook_id__fk
+            references webhook
+            on update cascade on delete cascade,
+    event_id        text                                       not null,
+    event           text                                       not null,
+    payload         text                                       not null,
+    status          text                                       not null,
+    attempts        integer                                    not null,
+    response_status integer,
+    error           text,
+    next_attempt    timestamp                                  not null,
+    created         timestamp                                  not null
+);
+
+create index if not exists webhook_delivery__webhook_id__index
+    on webhook_delivery (webhook_id);
+
+create index if not exists webhook_delivery__status__next_attempt__index
+    on webhook_delivery (status, next_attempt);
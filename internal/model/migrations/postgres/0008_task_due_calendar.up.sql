@@ -0,0 +1,23 @@
+alter table task
+    add column if not exists due timestamp;
+
+alter table task
+    add column if not exists recurrence text;
+
+create index if not exists task__due__index
+    on task (due);
+
+create table if not exists calendar_token
+(
+    user_id integer                                              not null
+        constraint calendar_token__pk
+            primary key
+        constraint calendar_token__user_id__fk
+            references auth
+            on update cascade on delete cascade,
+    token   text                                                 not null,
+    created timestamp                                            not null
+);
+
+create unique index if not exists calendar_token__token__uindex
+    on calendar_token (token);
@@ -0,0 +1,30 @@
+create table if not exists reminder
+(
+    reminder_id    serial not null
+        constraint reminder__pk
+            primary key,
+    user_id        integer                                    not null
+        constraint reminder__user_id__fk
+            references auth
+            on update cascade on delete cascade,
+    task_id        integer                                    not null
+        constraint reminder__task_id__fk
+            references task
+            on update cascade on delete cascade,
+    remind_at      timestamp,
+    before_minutes integer,
+    channel        text                                       not null,
+    email          text,
+    status         text                                       not null,
+    attempts       integer                                    not null,
+    error          text,
+    next_attempt   timestamp,
+    sent           timestamp,
+    created        timestamp                                  not null
+);
+
+create index if not exists reminder__task_id__index
+    on reminder (task_id);
+
+create index if not exists reminder__status__index
+    on reminder (status);
@@ -0,0 +1,18 @@
+delete from task_history h
+where not exists (select 1 from task t where t.task_id = h.task_id);
+
+delete from task_history
+where field in ('trash', 'deleted');
+
+drop index task_history__user_id__index;
+
+alter table task_history
+    drop constraint task_history__user_id__fk;
+
+alter table task_history
+    add constraint task_history__task_id__fk
+        foreign key (task_id) references task
+            on update cascade on delete cascade;
+
+alter table task_history
+    drop column user_id;
@@ -0,0 +1,21 @@
+alter table task_history
+    add column user_id integer;
+
+update task_history h
+set user_id = t.user_id
+from task t
+where t.task_id = h.task_id;
+
+alter table task_history
+    alter column user_id set not null;
+
+alter table task_history
+    drop constraint task_history__task_id__fk;
+
+alter table task_history
+    add constraint task_history__user_id__fk
+        foreign key (user_id) references auth
+            on update cascade on delete cascade;
+
+create index if not exists task_history__user_id__index
+    on task_history (user_id);
@@ -0,0 +1,8 @@
+drop index task__project_id__index;
+
+alter table task
+    drop column project_id;
+
+drop table project_member;
+
+drop table project;
@@ -0,0 +1,7 @@
+drop table idempotency_key;
+
+drop table task_history;
+
+drop table task;
+
+drop table auth;
@@ -0,0 +1,78 @@
+create table if not exists auth
+(
+    user_id  integer not null
+        constraint auth__pk
+            primary key autoincrement,
+    username text    not null,
+    password text    not null
+);
+
+create unique index if not exists auth__username__uindex
+    on auth (username);
+
+create index if not exists auth__password__index
+    on auth (password);
+
+create table if not exists task
+(
+    task_id    integer not null
+        constraint task__pk
+            primary key autoincrement,
+    user_id    integer not null
+        constraint task__user_id__fk
+            references auth
+            on update cascade on delete cascade,
+    status     boolean not null,
+    title      text    not null,
+    created    timestamp not null,
+    updated    timestamp not null,
+    completed  timestamp,
+    deleted_at timestamp,
+    version    integer default 1 not null
+);
+
+create index if not exists task__user_id__index
+    on task (user_id);
+
+create index if not exists task__status__index
+    on task (status);
+
+create index if not exists task__deleted_at__index
+    on task (deleted_at);
+
+create table if not exists task_history
+(
+    history_id integer not null
+        constraint task_history__pk
+            primary key autoincrement,
+    task_id    integer not null
+        constraint task_history__task_id__fk
+            references task
+            on update cascade on delete cascade,
+    revision   integer   not null,
+    field      text      not null,
+    old_value  text,
+    new_value  text,
+    actor      text      not null,
+    changed    timestamp not null
+);
+
+create unique index if not exists task_history__task_id__revision__field__uindex
+    on task_history (task_id, revision, field);
+
+create table if not exists idempotency_key
+(
+    scope           text      not null,
+    idempotency_key text      not null,
+    fingerprint     text      not null,
+    status          integer,
+    content_type    text,
+    body            blob,
+    created         timestamp not null,
+    expires         timestamp not null,
+    constraint idempotency_key__pk
+        primary key (scope, idempotency_key)
+);
+
+create index if not exists idempotency_key__expires__index
+    on idempotency_key (expires);
@@ -0,0 +1,20 @@
+create table if not exists event
+(
+    event_seq integer not null
+        constraint event__pk
+            primary key autoincrement,
+    user_id   integer   not null
+        constraint event__user_id__fk
+            references auth
+            on update cascade on delete cascade,
+    event     text      not null,
+    task_id   integer,
+    payload   text      not null,
+    created   timestamp not null
+);
+
+create index if not exists event__user_id__event_seq__index
+    on event (user_id, event_seq);
+
+create index if not exists event__created__index
+    on event (created);
@@ -0,0 +1,9 @@
+drop table calendar_token;
+
+drop index task__due__index;
+
+alter table task
+    drop column recurrence;
+
+alter table task
+    drop column due;
@@ -0,0 +1,3 @@
+drop table notification_preference;
+
+drop table notification;
@@ -0,0 +1,36 @@
+create table if not exists notification
+(
+    notification_id integer not null
+        constraint notification__pk
+            primary key autoincrement,
+    user_id         integer   not null
+        constraint notification__user_id__fk
+            references auth
+            on update cascade on delete cascade,
+    type            text      not null,
+    title           text      not null,
+    task_id         integer
+        constraint notification__task_id__fk
+            references task
+            on update cascade on delete set null,
+    read            timestamp,
+    created         timestamp not null
+);
+
+create index if not exists notification__user_id__index
+    on notification (user_id, notification_id);
+
+create index if not exists notification__created__index
+    on notification (created);
+
+create table if not exists notification_preference
+(
+    user_id integer not null
+        constraint notification_preference__user_id__fk
+            references auth
+            on update cascade on delete cascade,
+    type    text    not null,
+    enabled boolean not null,
+    constraint notification_preference__pk
+        primary key (user_id, type)
+);
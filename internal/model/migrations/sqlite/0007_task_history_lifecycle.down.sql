@@ -0,0 +1,30 @@
+create table if not exists task_history_task
+(
+    history_id integer not null
+        constraint task_history__pk
+            primary key autoincrement,
+    task_id    integer not null
+        constraint task_history__task_id__fk
+            references task
+            on update cascade on delete cascade,
+    revision   integer   not null,
+    field      text      not null,
+    old_value  text,
+    new_value  text,
+    actor      text      not null,
+    changed    timestamp not null
+);
+
+insert into task_history_task(history_id, task_id, revision, field, old_value, new_value, actor, changed)
+select h.history_id, h.task_id, h.revision, h.field, h.old_value, h.new_value, h.actor, h.changed
+from task_history h
+join task t on t.task_id = h.task_id
+where h.field not in ('trash', 'deleted');
+
+drop table task_history;
+
+alter table task_history_task
+    rename to task_history;
+
+create unique index if not exists task_history__task_id__revision__field__uindex
+    on task_history (task_id, revision, field);
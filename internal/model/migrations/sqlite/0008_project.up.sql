@@ -0,0 +1,42 @@
+create table if not exists project
+(
+    project_id integer not null
+        constraint project__pk
+            primary key autoincrement,
+    user_id    integer   not null
+        constraint project__user_id__fk
+            references auth
+            on update cascade on delete cascade,
+    name       text      not null,
+    created    timestamp not null
+);
+
+create index if not exists project__user_id__index
+    on project (user_id);
+
+create table if not exists project_member
+(
+    project_id integer   not null
+        constraint project_member__project_id__fk
+            references project
+            on update cascade on delete cascade,
+    user_id    integer   not null
+        constraint project_member__user_id__fk
+            references auth
+            on update cascade on delete cascade,
+    created    timestamp not null,
+    constraint project_member__pk
+        primary key (project_id, user_id)
+);
+
+create index if not exists project_member__user_id__index
+    on project_member (user_id);
+
+alter table task
+    add column project_id integer
+        constraint task__project_id__fk
+            references project
+            on update cascade on delete set null;
+
+create index if not exists task__project_id__index
+    on task (project_id);
@@ -0,0 +1,8 @@
+drop index task__parent_id__index;
+
+alter table task
+    drop column parent_id;
+
+drop table task_comment;
+
+drop table task_tag;
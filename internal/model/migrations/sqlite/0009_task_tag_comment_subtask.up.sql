@@ -0,0 +1,42 @@
+create table if not exists task_tag
+(
+    task_id integer not null
+        constraint task_tag__task_id__fk
+            references task
+            on update cascade on delete cascade,
+    name    text    not null,
+    constraint task_tag__pk
+        primary key (task_id, name)
+);
+
+create table if not exists task_comment
+(
+    comment_id integer not null
+        constraint task_comment__pk
+            primary key autoincrement,
+    task_id    integer   not null
+        constraint task_comment__task_id__fk
+            references task
+            on update cascade on delete cascade,
+    user_id    integer   not null
+        constraint task_comment__user_id__fk
+            references auth
+            on update cascade on delete cascade,
+    text       text      not null,
+    created    timestamp not null
+);
+
+create index if not exists task_comment__task_id__index
+    on task_comment (task_id);
+
+create index if not exists task_comment__user_id__index
+    on task_comment (user_id);
+
+alter table task
+    add column parent_id integer
+        constraint task__parent_id__fk
+            references task
+            on update cascade on delete set null;
+
+create index if not exists task__parent_id__index
+    on task (parent_id);
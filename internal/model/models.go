@@ -13,10 +13,15 @@ var (
 
 	ErrTaskAlreadyExists = errors.New("task already exists")
 	ErrTaskNotFound      = errors.New("task not found")
+	ErrVersionMismatch   = errors.New("task version mismatch")
 )
 
 type Postgres struct {
 	Pool         *sql.DB
 	Logger       *logrus.Logger
 	QueryTimeout int
+	// TxOptions for WithTx and the methods running several queries.
+	TxOptions TxOptions
+
+	tx *sql.Tx
 }
@@ -0,0 +1,440 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// The types of the notifications, the user disables them with the preferences.
+const (
+	NotificationTaskReminder    = EventTaskReminder
+	NotificationWebhookDisabled = "webhook.disabled"
+	// NotificationProjectShared the user is added to the project.
+	NotificationProjectShared = "project.shared"
+	// NotificationTaskAssigned the task is moved to the project of the user by another member.
+	NotificationTaskAssigned = "task.assigned"
+	// NotificationTaskCommented another user commented on the task the user sees.
+	NotificationTaskCommented = "task.commented"
+)
+
+// NotificationTypes in the order of GetNotificationPreferences.
+var NotificationTypes = []string{
+	NotificationTaskReminder,
+	NotificationWebhookDisabled,
+	NotificationProjectShared,
+	NotificationTaskAssigned,
+	NotificationTaskCommented,
+}
+
+type Notification struct {
+	ID    int    `json:"notificationId"`
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	// TaskID 0 - not of a task or the task is deleted.
+	TaskID int `json:"taskId,omitempty"`
+	// Read nil - unread.
+	Read    *time.Time `json:"read"`
+	Created time.Time  `json:"created"`
+
+	UserID int `json:"-"`
+}
+
+type NotificationPreference struct {
+	Type    string `json:"type"`
+	Enabled bool   `json:"enabled"`
+}
+
+// NotificationQuery the page of the notifications, the newest first.
+type NotificationQuery struct {
+	// Unread only the unread ones.
+	Unread bool
+	// BeforeID 0 - from the newest.
+	BeforeID int
+	Limit    int
+}
+
+// CreateNotification in the inbox of the user. Returns 0 if the user disabled the type.
+func (p Postgres) CreateNotification(ctx context.Context, notification Notification) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	var notificationID int
+
+	//nolint:execinquery
+	if err := p.conn(ctx).QueryRowContext(ctx, `
+		INSERT INTO
+			notification(user_id, type, title, task_id, created)
+		SELECT
+		    $1, $2, $3, NULLIF($4, 0), now()
+		WHERE NOT EXISTS (
+		    SELECT
+		        1
+		    FROM
+		        notification_preference
+		    WHERE
+		        user_id = $1 AND
+		        type = $2 AND
+		        NOT enabled
+		)
+		RETURNING
+		    notification_id
+	`,
+		notification.UserID,
+		notification.Type,
+		notification.Title,
+		notification.TaskID,
+	).Scan(
+		&notificationID,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+
+		return 0, fmt.Errorf("query row: %w", err)
+	}
+
+	return notificationID, nil
+}
+
+// GetNotifications of the user by the query.
+func (p Postgres) GetNotifications(ctx context.Context, userID int, query NotificationQuery) ([]Notification, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	rows, err := p.conn(ctx).QueryContext(ctx, `
+		SELECT
+			notification_id, type, title, task_id, read, created
+		FROM
+		    notification
+		WHERE
+		    user_id = $1 AND
+		    (NOT $2 OR read IS NULL) AND
+		    ($3 = 0 OR notification_id < $3)
+		ORDER BY
+		    notification_id DESC
+		LIMIT $4
+	`,
+		userID,
+		query.Unread,
+		query.BeforeID,
+		query.Limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			p.Logger.Errorf("get notifications: %v", err)
+		}
+	}()
+
+	var notifications []Notification
+
+	for rows.Next() {
+		var (
+			notification Notification
+			taskID       sql.NullInt64
+			read         sql.NullTime
+		)
+
+		if err := rows.Scan(
+			&notification.ID,
+			&notification.Type,
+			&notification.Title,
+			&taskID,
+			&read,
+			&notification.Created,
+		); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+
+		notification.TaskID = int(taskID.Int64)
+		notification.UserID = userID
+
+		if read.Valid {
+			notification.Read = &read.Time
+		}
+
+		notifications = append(notifications, notification)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("scan rows: %w", rows.Err())
+	}
+
+	return notifications, nil
+}
+
+// GetUnreadNotifications the quantity of the unread notifications of the user by type, without the types
+// having none.
+func (p Postgres) GetUnreadNotifications(ctx context.Context, userID int) (map[string]int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	rows, err := p.conn(ctx).QueryContext(ctx, `
+		SELECT
+			type, COUNT(*)
+		FROM
+		    notification
+		WHERE
+		    user_id = $1 AND
+		    read IS NULL
+		GROUP BY
+		    type
+	`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			p.Logger.Errorf("get unread notifications: %v", err)
+		}
+	}()
+
+	unread := make(map[string]int)
+
+	for rows.Next() {
+		var (
+			notificationType string
+			quantity         int
+		)
+
+		if err := rows.Scan(
+			&notificationType,
+			&quantity,
+		); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+
+		unread[notificationType] = quantity
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("scan rows: %w", rows.Err())
+	}
+
+	return unread, nil
+}
+
+// ReadNotification marks the notification of the user read, the time of the first reading is kept.
+func (p Postgres) ReadNotification(ctx context.Context, userID, notificationID int) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	res, err := p.conn(ctx).ExecContext(ctx, `
+		UPDATE
+		    notification
+		SET
+		    read = COALESCE(read, now())
+		WHERE
+		    notification_id = $2 AND
+		    user_id = $1
+	`,
+		userID,
+		notificationID,
+	)
+
+	return notificationReadResult(res, err, notificationID)
+}
+
+// ReadNotifications marks all unread notifications of the user read. Returns the quantity of them.
+func (p Postgres) ReadNotifications(ctx context.Context, userID int) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	res, err := p.conn(ctx).ExecContext(ctx, `
+		UPDATE
+		    notification
+		SET
+		    read = now()
+		WHERE
+		    user_id = $1 AND
+		    read IS NULL
+	`,
+		userID,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("exec: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// GetNotificationPreferences of all NotificationTypes, the types are enabled until the user disables them.
+func (p Postgres) GetNotificationPreferences(ctx context.Context, userID int) ([]NotificationPreference, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	rows, err := p.conn(ctx).QueryContext(ctx, `
+		SELECT
+			type, enabled
+		FROM
+		    notification_preference
+		WHERE
+		    user_id = $1
+	`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			p.Logger.Errorf("get notification preferences: %v", err)
+		}
+	}()
+
+	enabled := make(map[string]bool)
+
+	for rows.Next() {
+		var preference NotificationPreference
+
+		if err := rows.Scan(
+			&preference.Type,
+			&preference.Enabled,
+		); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+
+		enabled[preference.Type] = preference.Enabled
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("scan rows: %w", rows.Err())
+	}
+
+	return notificationPreferences(enabled), nil
+}
+
+// SetNotificationPreferences the types missing in the preferences are not changed.
+func (p Postgres) SetNotificationPreferences(
+	ctx context.Context, userID int, preferences []NotificationPreference,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	return p.WithTx(ctx, func(tx Postgres) error {
+		return setNotificationPreferences(ctx, tx.conn(ctx), userID, preferences)
+	})
+}
+
+// PurgeNotifications deletes the notifications created before and the ones of each user beyond the newest
+// maxPerUser, 0 - no limit.
+func (p Postgres) PurgeNotifications(ctx context.Context, before time.Time, maxPerUser int) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	res, err := p.conn(ctx).ExecContext(ctx, `
+		DELETE FROM
+		    notification
+		WHERE
+		    created < $1 OR
+		    notification_id IN (
+		        SELECT
+		            notification_id
+		        FROM (
+		            SELECT
+		                notification_id,
+		                row_number() OVER (PARTITION BY user_id ORDER BY notification_id DESC) AS position
+		            FROM
+		                notification
+		        ) ranked
+		        WHERE
+		            $2 > 0 AND
+		            position > $2
+		    )
+	`,
+		before,
+		maxPerUser,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("exec: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+func setNotificationPreferences(
+	ctx context.Context, q querier, userID int, preferences []NotificationPreference,
+) error {
+	for _, preference := range preferences {
+		if _, err := q.ExecContext(ctx, `
+			INSERT INTO
+				notification_preference(user_id, type, enabled)
+			VALUES
+			    ($1, $2, $3)
+			ON CONFLICT (user_id, type) DO UPDATE SET
+			    enabled = excluded.enabled
+		`,
+			userID,
+			preference.Type,
+			preference.Enabled,
+		); err != nil {
+			return fmt.Errorf("exec: %s: %w", preference.Type, err)
+		}
+	}
+
+	return nil
+}
+
+func notificationReadResult(res sql.Result, err error, notificationID int) error {
+	if err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("notificationId %d: %w", notificationID, ErrNotificationNotFound)
+	}
+
+	return nil
+}
+
+// notificationPreferences of NotificationTypes, enabled by type, the missing types are enabled.
+func notificationPreferences(enabled map[string]bool) []NotificationPreference {
+	preferences := make([]NotificationPreference, 0, len(NotificationTypes))
+
+	for _, notificationType := range NotificationTypes {
+		e, ok := enabled[notificationType]
+
+		preferences = append(preferences, NotificationPreference{
+			Type:    notificationType,
+			Enabled: e || !ok,
+		})
+	}
+
+	return preferences
+}
+
+// webhookDisabledNotification the notification of the owner of the webhook disabled by the failed attempts.
+func webhookDisabledNotification(userID, webhookID int, url string, failures int) Notification {
+	return Notification{
+		Type:   NotificationWebhookDisabled,
+		Title:  fmt.Sprintf("Webhook %d disabled after %d failed attempts in a row: %s", webhookID, failures, url),
+		UserID: userID,
+	}
+}
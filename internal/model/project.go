@@ -0,0 +1,689 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrProjectNotFound = errors.New("project not found")
+
+// Project the members see the tasks of each other in the project, the owner manages the members.
+type Project struct {
+	ID      int       `json:"projectId"`
+	Name    string    `json:"name"`
+	OwnerID int       `json:"ownerId"`
+	Created time.Time `json:"created"`
+}
+
+type ProjectMember struct {
+	UserID   int       `json:"userId"`
+	Username string    `json:"username"`
+	Joined   time.Time `json:"joined"`
+}
+
+// CreateProject owned by the user, the owner is its first member.
+func (p Postgres) CreateProject(ctx context.Context, userID int, name string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	var projectID int
+
+	if err := p.WithTx(ctx, func(tx Postgres) error {
+		//nolint:execinquery
+		if err := tx.conn(ctx).QueryRowContext(ctx, `
+			INSERT INTO
+				project(user_id, name, created)
+			VALUES
+			    ($1, $2, now())
+			RETURNING
+			    project_id
+		`,
+			userID,
+			name,
+		).Scan(
+			&projectID,
+		); err != nil {
+			return fmt.Errorf("query row: %w", err)
+		}
+
+		if _, err := tx.conn(ctx).ExecContext(ctx, `
+			INSERT INTO
+				project_member(project_id, user_id, created)
+			VALUES
+			    ($1, $2, now())
+		`,
+			projectID,
+			userID,
+		); err != nil {
+			return fmt.Errorf("exec: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	return projectID, nil
+}
+
+// GetProjects the user is a member of, sorted by id.
+func (p Postgres) GetProjects(ctx context.Context, userID int) ([]Project, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	rows, err := p.conn(ctx).QueryContext(ctx, `
+		SELECT
+			p.project_id, p.name, p.user_id, p.created
+		FROM
+		    project p
+		JOIN
+		    project_member m USING (project_id)
+		WHERE
+		    m.user_id = $1
+		ORDER BY
+		    p.project_id
+	`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			p.Logger.Errorf("get projects: %v", err)
+		}
+	}()
+
+	var projects []Project
+
+	for rows.Next() {
+		var project Project
+
+		if err := rows.Scan(
+			&project.ID,
+			&project.Name,
+			&project.OwnerID,
+			&project.Created,
+		); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+
+		projects = append(projects, project)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("scan rows: %w", rows.Err())
+	}
+
+	return projects, nil
+}
+
+// DeleteProject of the owner, its tasks stay with their owners outside of projects.
+func (p Postgres) DeleteProject(ctx context.Context, userID, projectID int) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	res, err := p.conn(ctx).ExecContext(ctx, `
+		DELETE FROM
+		    project
+		WHERE
+		    project_id = $2 AND
+		    user_id = $1
+	`,
+		userID,
+		projectID,
+	)
+	if err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	if rowsAffected != 1 {
+		return fmt.Errorf("projectId %d: %w", projectID, ErrProjectNotFound)
+	}
+
+	return nil
+}
+
+// GetProjectMembers of the project of the user, the owner first.
+func (p Postgres) GetProjectMembers(ctx context.Context, userID, projectID int) ([]ProjectMember, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	rows, err := p.conn(ctx).QueryContext(ctx, `
+		SELECT
+			m.user_id, a.username, m.created
+		FROM
+		    project_member m
+		JOIN
+		    auth a USING (user_id)
+		WHERE
+		    m.project_id = $2 AND
+		    EXISTS (
+		        SELECT
+		            1
+		        FROM
+		            project_member
+		        WHERE
+		            project_id = $2 AND
+		            user_id = $1
+		    )
+		ORDER BY
+		    m.created, m.user_id
+	`,
+		userID,
+		projectID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			p.Logger.Errorf("get project members: %v", err)
+		}
+	}()
+
+	var members []ProjectMember
+
+	for rows.Next() {
+		var member ProjectMember
+
+		if err := rows.Scan(
+			&member.UserID,
+			&member.Username,
+			&member.Joined,
+		); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+
+		members = append(members, member)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("scan rows: %w", rows.Err())
+	}
+
+	// The project has at least the owner.
+	if len(members) == 0 {
+		return nil, fmt.Errorf("projectId %d: %w", projectID, ErrProjectNotFound)
+	}
+
+	return members, nil
+}
+
+// AddProjectMember by the owner of the project. Returns the id of the member, false - the user is already a member.
+func (p Postgres) AddProjectMember(ctx context.Context, userID, projectID int, username string) (int, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	return addProjectMember(ctx, p.conn(ctx), userID, projectID, username, `
+		INSERT INTO
+			project_member(project_id, user_id, created)
+		SELECT
+		    p.project_id, a.user_id, now()
+		FROM
+		    project p, auth a
+		WHERE
+		    p.project_id = $2 AND
+		    p.user_id = $1 AND
+		    a.username = $3
+		ON CONFLICT DO NOTHING
+		RETURNING
+		    user_id
+	`)
+}
+
+// addProjectMember the query inserts the member by $1 - the owner, $2 - the project, $3 - the username,
+// the other arguments follow.
+func addProjectMember(
+	ctx context.Context, q querier, userID, projectID int, username, query string, args ...any,
+) (int, bool, error) {
+	var memberID int
+
+	//nolint:execinquery
+	err := q.QueryRowContext(ctx, query, append([]any{userID, projectID, username}, args...)...).Scan(
+		&memberID,
+	)
+	if err == nil {
+		return memberID, true, nil
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, false, fmt.Errorf("query row: %w", err)
+	}
+
+	var (
+		owner  bool
+		member sql.NullInt64
+	)
+
+	if err := q.QueryRowContext(ctx, `
+		SELECT
+		    EXISTS (
+		        SELECT
+		            1
+		        FROM
+		            project
+		        WHERE
+		            project_id = $2 AND
+		            user_id = $1
+		    ),
+		    (SELECT user_id FROM auth WHERE username = $3)
+	`,
+		userID,
+		projectID,
+		username,
+	).Scan(
+		&owner,
+		&member,
+	); err != nil {
+		return 0, false, fmt.Errorf("query row: %w", err)
+	}
+
+	switch {
+	case !owner:
+		return 0, false, fmt.Errorf("projectId %d: %w", projectID, ErrProjectNotFound)
+	case !member.Valid:
+		return 0, false, fmt.Errorf("%s: %w", username, ErrUserNotFound)
+	}
+
+	return int(member.Int64), false, nil
+}
+
+// RemoveProjectMember by the owner of the project or by the member itself, the owner is not removed.
+// The tasks of the member leave the project.
+func (p Postgres) RemoveProjectMember(ctx context.Context, userID, projectID, memberID int) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	return p.WithTx(ctx, func(tx Postgres) error {
+		return removeProjectMember(ctx, tx.conn(ctx), userID, projectID, memberID)
+	})
+}
+
+func removeProjectMember(ctx context.Context, q querier, userID, projectID, memberID int) error {
+	res, err := q.ExecContext(ctx, `
+		DELETE FROM
+		    project_member
+		WHERE
+		    project_id = $2 AND
+		    user_id = $3 AND
+		    EXISTS (
+		        SELECT
+		            1
+		        FROM
+		            project
+		        WHERE
+		            project_id = $2 AND
+		            user_id <> $3 AND
+		            (user_id = $1 OR $3 = $1)
+		    )
+	`,
+		userID,
+		projectID,
+		memberID,
+	)
+	if err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	if rowsAffected != 1 {
+		return projectMemberNotRemovedError(ctx, q, userID, projectID, memberID)
+	}
+
+	if _, err := q.ExecContext(ctx, `
+		UPDATE
+		    task
+		SET
+		    project_id = NULL
+		WHERE
+		    project_id = $1 AND
+		    user_id = $2
+	`,
+		projectID,
+		memberID,
+	); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+// projectMemberNotRemovedError the project of the user is not found or the member is not found in it.
+func projectMemberNotRemovedError(ctx context.Context, q querier, userID, projectID, memberID int) error {
+	member, err := isProjectMember(ctx, q, userID, projectID)
+	if err != nil {
+		return err
+	}
+
+	if !member {
+		return fmt.Errorf("projectId %d: %w", projectID, ErrProjectNotFound)
+	}
+
+	return fmt.Errorf("userId %d: %w", memberID, ErrUserNotFound)
+}
+
+// IsProjectMember the user is a member of the project, false - the project is not found.
+func (p Postgres) IsProjectMember(ctx context.Context, userID, projectID int) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	return isProjectMember(ctx, p.conn(ctx), userID, projectID)
+}
+
+func isProjectMember(ctx context.Context, q querier, userID, projectID int) (bool, error) {
+	var member bool
+
+	if err := q.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT
+			    1
+			FROM
+			    project_member
+			WHERE
+			    project_id = $2 AND
+			    user_id = $1
+		)
+	`,
+		userID,
+		projectID,
+	).Scan(
+		&member,
+	); err != nil {
+		return false, fmt.Errorf("query row: %w", err)
+	}
+
+	return member, nil
+}
+
+// GetProjectMemberIDs the users receiving the events of the tasks of the project.
+func (p Postgres) GetProjectMemberIDs(ctx context.Context, projectID int) ([]int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	rows, err := p.conn(ctx).QueryContext(ctx, `
+		SELECT
+			user_id
+		FROM
+		    project_member
+		WHERE
+		    project_id = $1
+		ORDER BY
+		    user_id
+	`,
+		projectID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			p.Logger.Errorf("get project member ids: %v", err)
+		}
+	}()
+
+	var userIDs []int
+
+	for rows.Next() {
+		var userID int
+
+		if err := rows.Scan(
+			&userID,
+		); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+
+		userIDs = append(userIDs, userID)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("scan rows: %w", rows.Err())
+	}
+
+	return userIDs, nil
+}
+
+// GetTaskUserIDs the users who see the task: its owner and the members of its project.
+func (p Postgres) GetTaskUserIDs(ctx context.Context, taskID int) ([]int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	rows, err := p.conn(ctx).QueryContext(ctx, `
+		SELECT
+			user_id
+		FROM
+		    task
+		WHERE
+		    task_id = $1
+		UNION
+		SELECT
+			m.user_id
+		FROM
+		    project_member m
+		JOIN
+		    task t USING (project_id)
+		WHERE
+		    t.task_id = $1
+		ORDER BY
+		    user_id
+	`,
+		taskID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			p.Logger.Errorf("get task user ids: %v", err)
+		}
+	}()
+
+	var userIDs []int
+
+	for rows.Next() {
+		var userID int
+
+		if err := rows.Scan(
+			&userID,
+		); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+
+		userIDs = append(userIDs, userID)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("scan rows: %w", rows.Err())
+	}
+
+	return userIDs, nil
+}
+
+// GetTaskProjects the projects of the tasks of the user by the task id, including the trash.
+func (p Postgres) GetTaskProjects(ctx context.Context, userID int) (map[int]int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	rows, err := p.conn(ctx).QueryContext(ctx, `
+		SELECT
+			task_id, project_id
+		FROM
+		    task
+		WHERE
+		    user_id = $1 AND
+		    project_id IS NOT NULL
+	`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			p.Logger.Errorf("get task projects: %v", err)
+		}
+	}()
+
+	projects := make(map[int]int)
+
+	for rows.Next() {
+		var taskID, projectID int
+
+		if err := rows.Scan(
+			&taskID,
+			&projectID,
+		); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+
+		projects[taskID] = projectID
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("scan rows: %w", rows.Err())
+	}
+
+	return projects, nil
+}
+
+// SetTaskProject moves the task of the user to the project the user is a member of, 0 - out of the project.
+func (p Postgres) SetTaskProject(ctx context.Context, username, password string, taskID, projectID int) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	return setTaskProject(ctx, p.conn(ctx), username, password, taskID, projectID, `
+		UPDATE
+		    task
+		SET
+		    project_id = NULLIF($4, 0),
+		    updated = now(),
+		    version = version + 1
+		WHERE
+		    user_id = (SELECT user_id FROM auth WHERE username = $1 AND password = $2) AND
+		    task_id = $3 AND
+		    deleted_at IS NULL AND
+		    (
+		        $4 = 0 OR
+		        EXISTS (
+		            SELECT
+		                1
+		            FROM
+		                project_member m
+		            WHERE
+		                m.project_id = $4 AND
+		                m.user_id = task.user_id
+		        )
+		    )
+	`)
+}
+
+// setTaskProject the query updates the task by $1, $2 - the credentials, $3 - the task, $4 - the project,
+// the other arguments follow.
+func setTaskProject(
+	ctx context.Context, q querier, username, password string, taskID, projectID int, query string, args ...any,
+) error {
+	res, err := q.ExecContext(ctx, query, append([]any{username, password, taskID, projectID}, args...)...)
+	if err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	if rowsAffected == 1 {
+		return nil
+	}
+
+	if projectID != 0 {
+		var member bool
+
+		if err := q.QueryRowContext(ctx, `
+			SELECT EXISTS (
+				SELECT
+				    1
+				FROM
+				    project_member m
+				JOIN
+				    auth a USING (user_id)
+				WHERE
+				    m.project_id = $3 AND
+				    a.username = $1 AND
+				    a.password = $2
+			)
+		`,
+			username,
+			password,
+			projectID,
+		).Scan(
+			&member,
+		); err != nil {
+			return fmt.Errorf("query row: %w", err)
+		}
+
+		if !member {
+			return fmt.Errorf("projectId %d: %w", projectID, ErrProjectNotFound)
+		}
+	}
+
+	return fmt.Errorf("taskId %d: %w", taskID, ErrTaskNotFound)
+}
+
+// GetProjectTasks the tasks of all the members in the project of the user, without the trash, sorted by id.
+func (p Postgres) GetProjectTasks(ctx context.Context, userID, projectID int) ([]Task, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	member, err := isProjectMember(ctx, p.conn(ctx), userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !member {
+		return nil, fmt.Errorf("projectId %d: %w", projectID, ErrProjectNotFound)
+	}
+
+	rows, err := p.conn(ctx).QueryContext(ctx, `
+		SELECT
+		    t.task_id, t.status, t.title, t.created, t.updated, t.completed, t.due, t.recurrence, t.project_id,
+		    t.parent_id, t.version
+		FROM
+		    task t
+		WHERE
+		    t.project_id = $1 AND
+		    t.deleted_at IS NULL
+		ORDER BY
+		    t.task_id
+	`,
+		projectID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			p.Logger.Errorf("get project tasks: %v", err)
+		}
+	}()
+
+	return scanTasks(rows)
+}
@@ -0,0 +1,433 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrReminderNotFound = errors.New("reminder not found")
+
+const (
+	ReminderPending = "pending"
+	ReminderSent    = "sent"
+	ReminderFailed  = "failed"
+)
+
+// The channels of the reminders, each has its notifier.
+const (
+	ReminderChannelWebhook = "webhook"
+	ReminderChannelEmail   = "email"
+	ReminderChannelInApp   = "inapp"
+)
+
+type Reminder struct {
+	ID     int `json:"reminderId"`
+	TaskID int `json:"taskId"`
+	// RemindAt the time of the reminder, nil - BeforeMinutes before the due date of the task.
+	RemindAt      *time.Time `json:"remindAt,omitempty"`
+	BeforeMinutes *int       `json:"beforeMinutes,omitempty"`
+	Channel       string     `json:"channel"`
+	// Email the recipient of the email channel.
+	Email  string `json:"email,omitempty"`
+	Status string `json:"status"`
+	// FireAt nil - the task has no due date, the reminder waits for it.
+	FireAt   *time.Time `json:"fireAt,omitempty"`
+	Attempts int        `json:"attempts"`
+	// Error of the last failed attempt.
+	Error   string     `json:"error,omitempty"`
+	Sent    *time.Time `json:"sent,omitempty"`
+	Created time.Time  `json:"created"`
+
+	// UserID, Task and ClaimedUntil are set only by ClaimReminders.
+	UserID int   `json:"-"`
+	Task   *Task `json:"-"`
+	// ClaimedUntil the end of the lease, it identifies the claim in SaveReminderAttempt.
+	ClaimedUntil time.Time `json:"-"`
+}
+
+// ReminderAttempt the result of sending the reminder.
+type ReminderAttempt struct {
+	ReminderID int
+	Succeeded  bool
+	Error      string
+	// Time of the attempt by the clock of the scheduler.
+	Time time.Time
+	// RetryAfter 0 - the failed reminder is not retried.
+	RetryAfter time.Duration
+	// ClaimedUntil the ClaimedUntil of the claimed reminder, the attempt is not saved if the lease expired and
+	// the reminder was claimed again.
+	ClaimedUntil time.Time
+}
+
+func (a ReminderAttempt) status() string {
+	switch {
+	case a.Succeeded:
+		return ReminderSent
+	case a.RetryAfter > 0:
+		return ReminderPending
+	}
+
+	return ReminderFailed
+}
+
+// reminderFireAt the time to send the reminder, nil - the offset reminder of the task without the due date.
+func reminderFireAt(remindAt *time.Time, beforeMinutes *int, due *time.Time) *time.Time {
+	switch {
+	case remindAt != nil:
+		return remindAt
+
+	case beforeMinutes != nil && due != nil:
+		fireAt := due.Add(-time.Minute * time.Duration(*beforeMinutes))
+
+		return &fireAt
+	}
+
+	return nil
+}
+
+// CreateReminder of the task of the user, the task in the trash is not found.
+func (p Postgres) CreateReminder(ctx context.Context, userID, taskID int, reminder Reminder) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	var reminderID int
+
+	//nolint:execinquery
+	if err := p.conn(ctx).QueryRowContext(ctx, `
+		INSERT INTO
+			reminder(user_id, task_id, remind_at, before_minutes, channel, email, status, attempts, created)
+		SELECT
+		    user_id, task_id, $3, $4, $5, NULLIF($6, ''), $7, 0, now()
+		FROM
+		    task
+		WHERE
+		    task_id = $2 AND
+		    user_id = $1 AND
+		    deleted_at IS NULL
+		RETURNING
+		    reminder_id
+	`,
+		userID,
+		taskID,
+		reminder.RemindAt,
+		reminder.BeforeMinutes,
+		reminder.Channel,
+		reminder.Email,
+		ReminderPending,
+	).Scan(
+		&reminderID,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("taskId %d: %w", taskID, ErrTaskNotFound)
+		}
+
+		return 0, fmt.Errorf("query row: %w", err)
+	}
+
+	return reminderID, nil
+}
+
+// GetReminders of the task of the user, ordered by ID.
+func (p Postgres) GetReminders(ctx context.Context, userID, taskID int) ([]Reminder, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	var exists bool
+
+	if err := p.conn(ctx).QueryRowContext(ctx, `
+		SELECT EXISTS (
+		    SELECT
+		        1
+		    FROM
+		        task
+		    WHERE
+		        task_id = $2 AND
+		        user_id = $1 AND
+		        deleted_at IS NULL
+		)
+	`,
+		userID,
+		taskID,
+	).Scan(
+		&exists,
+	); err != nil {
+		return nil, fmt.Errorf("query row: %w", err)
+	}
+
+	if !exists {
+		return nil, fmt.Errorf("taskId %d: %w", taskID, ErrTaskNotFound)
+	}
+
+	rows, err := p.conn(ctx).QueryContext(ctx, `
+		SELECT
+		    r.reminder_id, r.task_id, r.remind_at, r.before_minutes, r.channel, r.email, r.status, r.attempts,
+		    r.error, r.sent, r.created, t.due
+		FROM
+		    reminder r
+		JOIN
+		    task t USING (task_id)
+		WHERE
+		    r.task_id = $2 AND
+		    r.user_id = $1
+		ORDER BY
+		    r.reminder_id
+	`,
+		userID,
+		taskID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			p.Logger.Errorf("get reminders: %v", err)
+		}
+	}()
+
+	var reminders []Reminder
+
+	for rows.Next() {
+		reminder, _, err := scanReminder(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		reminders = append(reminders, reminder)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("scan rows: %w", rows.Err())
+	}
+
+	return reminders, nil
+}
+
+func (p Postgres) DeleteReminder(ctx context.Context, userID, taskID, reminderID int) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	res, err := p.conn(ctx).ExecContext(ctx, `
+		DELETE FROM
+		    reminder
+		WHERE
+		    reminder_id = $3 AND
+		    task_id = $2 AND
+		    user_id = $1
+	`,
+		userID,
+		taskID,
+		reminderID,
+	)
+	if err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	if rowsAffected != 1 {
+		return fmt.Errorf("reminderId %d: %w", reminderID, ErrReminderNotFound)
+	}
+
+	return nil
+}
+
+// ClaimReminders the pending reminders due at now of the not completed tasks outside the trash, the earliest first.
+// They are not claimed again for the lease, so that other instances do not send them at the same time.
+// now - the clock of the scheduler, the end of the lease is rounded to the precision of the timestamp.
+func (p Postgres) ClaimReminders(
+	ctx context.Context, now time.Time, limit int, lease time.Duration,
+) ([]Reminder, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	claimedUntil := now.UTC().Add(lease).Truncate(time.Microsecond)
+
+	rows, err := p.conn(ctx).QueryContext(ctx, `
+		UPDATE
+		    reminder r
+		SET
+		    next_attempt = $3
+		FROM
+		    task t
+		WHERE
+		    t.task_id = r.task_id AND
+		    r.reminder_id IN (
+				SELECT
+				    rr.reminder_id
+				FROM
+				    reminder rr
+				JOIN
+				    task tt USING (task_id)
+				WHERE
+				    rr.status = $4 AND
+				    (rr.next_attempt IS NULL OR rr.next_attempt <= $1::timestamp) AND
+				    COALESCE(rr.remind_at, tt.due - make_interval(mins => rr.before_minutes)) <= $1::timestamp AND
+				    NOT tt.status AND
+				    tt.deleted_at IS NULL
+				ORDER BY
+				    COALESCE(rr.remind_at, tt.due - make_interval(mins => rr.before_minutes)), rr.reminder_id
+				LIMIT $2
+				FOR UPDATE OF rr SKIP LOCKED
+			)
+		RETURNING
+		    r.reminder_id, r.task_id, r.remind_at, r.before_minutes, r.channel, r.email, r.status, r.attempts,
+		    r.error, r.sent, r.created, t.due, r.user_id,
+		    t.status, t.title, t.created, t.updated, t.completed, t.recurrence, t.version
+	`,
+		now.UTC(),
+		limit,
+		claimedUntil,
+		ReminderPending,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			p.Logger.Errorf("claim reminders: %v", err)
+		}
+	}()
+
+	return scanClaimedReminders(rows, claimedUntil)
+}
+
+// SaveReminderAttempt the failed reminder is retried after attempt.RetryAfter. The attempt of the expired lease is
+// not saved if the reminder was claimed again, the attempt of the new claim is saved instead.
+func (p Postgres) SaveReminderAttempt(ctx context.Context, attempt ReminderAttempt) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	return saveReminderAttempt(ctx, p.conn(ctx), attempt)
+}
+
+func saveReminderAttempt(ctx context.Context, q querier, attempt ReminderAttempt) error {
+	attemptTime := attempt.Time.UTC()
+
+	sent := sql.NullTime{Time: attemptTime, Valid: attempt.Succeeded}
+
+	if _, err := q.ExecContext(ctx, `
+		UPDATE
+		    reminder
+		SET
+		    status = $2,
+		    attempts = attempts + 1,
+		    error = NULLIF($3, ''),
+		    sent = $4,
+		    next_attempt = $5
+		WHERE
+		    reminder_id = $1 AND
+		    next_attempt = $6
+	`,
+		attempt.ReminderID,
+		attempt.status(),
+		attempt.Error,
+		sent,
+		attemptTime.Add(attempt.RetryAfter),
+		attempt.ClaimedUntil.UTC(),
+	); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanReminder the columns of GetReminders, then extra. Returns the due date of the task.
+func scanReminder(row rowScanner, extra ...any) (Reminder, *time.Time, error) {
+	var (
+		reminder      Reminder
+		remindAt      sql.NullTime
+		beforeMinutes sql.NullInt64
+		email         sql.NullString
+		reminderError sql.NullString
+		sent          sql.NullTime
+		due           sql.NullTime
+	)
+
+	if err := row.Scan(append([]any{
+		&reminder.ID,
+		&reminder.TaskID,
+		&remindAt,
+		&beforeMinutes,
+		&reminder.Channel,
+		&email,
+		&reminder.Status,
+		&reminder.Attempts,
+		&reminderError,
+		&sent,
+		&reminder.Created,
+		&due,
+	}, extra...)...); err != nil {
+		return Reminder{}, nil, fmt.Errorf("scan row: %w", err)
+	}
+
+	reminder.RemindAt = nullTimeRef(remindAt)
+	reminder.Email = email.String
+	reminder.Error = reminderError.String
+	reminder.Sent = nullTimeRef(sent)
+
+	if beforeMinutes.Valid {
+		minutes := int(beforeMinutes.Int64)
+		reminder.BeforeMinutes = &minutes
+	}
+
+	reminder.FireAt = reminderFireAt(reminder.RemindAt, reminder.BeforeMinutes, nullTimeRef(due))
+
+	return reminder, nullTimeRef(due), nil
+}
+
+// scanClaimedReminders with the user, the task and the end of the lease.
+func scanClaimedReminders(rows *sql.Rows, claimedUntil time.Time) ([]Reminder, error) {
+	var reminders []Reminder
+
+	for rows.Next() {
+		var (
+			userID         int
+			task           Task
+			taskCompleted  sql.NullTime
+			taskRecurrence sql.NullString
+		)
+
+		reminder, due, err := scanReminder(rows,
+			&userID,
+			&task.Status,
+			&task.Title,
+			&task.Created,
+			&task.Updated,
+			&taskCompleted,
+			&taskRecurrence,
+			&task.Version,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		task.ID = reminder.TaskID
+		task.Completed = taskCompleted.Time
+		task.Recurrence = taskRecurrence.String
+		task.Due = due
+
+		reminder.UserID = userID
+		reminder.Task = &task
+		reminder.ClaimedUntil = claimedUntil
+
+		reminders = append(reminders, reminder)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("scan rows: %w", rows.Err())
+	}
+
+	return reminders, nil
+}
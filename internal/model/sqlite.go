@@ -0,0 +1,1439 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SQLite the queries in standard SQL are shared with Postgres,
+// the ones using Postgres functions and row locks have SQLite versions.
+// Times are stored in UTC, so that the text values are compared in order.
+type SQLite struct {
+	Pool         *sql.DB
+	Logger       *logrus.Logger
+	QueryTimeout int
+}
+
+// portable runs the queries shared with Postgres.
+func (s SQLite) portable() Postgres {
+	return Postgres{
+		Pool:         s.Pool,
+		Logger:       s.Logger,
+		QueryTimeout: s.QueryTimeout,
+	}
+}
+
+func (s SQLite) withTx(ctx context.Context, fn func(q querier) error) error {
+	return s.Atomic(ctx, func(ctx context.Context) error {
+		return fn(contextTx(ctx))
+	})
+}
+
+// Atomic runs fn in a transaction, the methods called with the context of fn join it.
+// Inside another transaction fn joins it.
+func (s SQLite) Atomic(ctx context.Context, fn func(ctx context.Context) error) error {
+	if contextTx(ctx) != nil {
+		return fn(ctx)
+	}
+
+	tx, err := s.Pool.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+
+	defer s.portable().rollback(tx)
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	return nil
+}
+
+// conn the transaction if the methods are called in Atomic, otherwise the pool.
+func (s SQLite) conn(ctx context.Context) querier {
+	if tx := contextTx(ctx); tx != nil {
+		return tx
+	}
+
+	return s.Pool
+}
+
+// sqliteNow the precision of the Postgres timestamp.
+func sqliteNow() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
+
+func (s SQLite) CreateNewUser(ctx context.Context, username string, password string) (int, error) {
+	return s.portable().CreateNewUser(ctx, username, password)
+}
+
+func (s SQLite) DeleteUser(ctx context.Context, userID int) error {
+	return s.portable().DeleteUser(ctx, userID)
+}
+
+func (s SQLite) GetUsers(ctx context.Context) ([]User, error) {
+	return s.portable().GetUsers(ctx)
+}
+
+func (s SQLite) GetUserID(ctx context.Context, username string) (int, error) {
+	return s.portable().GetUserID(ctx, username)
+}
+
+func (s SQLite) UpdateUserPassword(ctx context.Context, username, password string) error {
+	return s.portable().UpdateUserPassword(ctx, username, password)
+}
+
+func (s SQLite) GetUserIDByCredentials(ctx context.Context, username, password string) (int, error) {
+	return s.portable().GetUserIDByCredentials(ctx, username, password)
+}
+
+func (s SQLite) CreateTask(ctx context.Context, username, password, title string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	return sqliteCreateTask(ctx, s.conn(ctx), username, password, title)
+}
+
+// CreateTaskWithInjection the injection example is for Postgres, the same as CreateTask.
+func (s SQLite) CreateTaskWithInjection(ctx context.Context, username, password, title string) (int, error) {
+	return s.CreateTask(ctx, username, password, title)
+}
+
+func (s SQLite) GetTask(ctx context.Context, username, password string, taskID int) (Task, error) {
+	return s.portable().GetTask(ctx, username, password, taskID)
+}
+
+func (s SQLite) GetTasks(ctx context.Context, username, password string) ([]Task, error) {
+	return s.portable().GetTasks(ctx, username, password)
+}
+
+func (s SQLite) GetTasksPage(ctx context.Context, username, password string, query TaskQuery) ([]Task, error) {
+	return s.portable().GetTasksPage(ctx, username, password, query)
+}
+
+func (s SQLite) CountTasks(ctx context.Context, username, password string, completed *bool) (int, error) {
+	return s.portable().CountTasks(ctx, username, password, completed)
+}
+
+func (s SQLite) ExportTasks(ctx context.Context, username, password string, fn func(ExportedTask) error) error {
+	return s.portable().ExportTasks(ctx, username, password, fn)
+}
+
+func (s SQLite) UpdateTask(
+	ctx context.Context, username, password string, taskID, version int, update TaskUpdate,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	return s.withTx(ctx, func(q querier) error {
+		return sqliteUpdateTaskWithHistory(ctx, q, username, password, taskID, version, update)
+	})
+}
+
+func (s SQLite) DeleteTask(ctx context.Context, username, password string, taskID, version int) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	return s.withTx(ctx, func(q querier) error {
+		return sqliteDeleteTask(ctx, q, username, password, taskID, version)
+	})
+}
+
+func (s SQLite) DeleteTasks(ctx context.Context, username, password string) ([]int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	var taskIDs []int
+
+	if err := s.withTx(ctx, func(q querier) error {
+		var err error
+
+		taskIDs, err = changeTasks(ctx, q, sqliteWriteTaskHistory, username, taskMovedToTrash, `
+			UPDATE
+			    task
+			SET
+			    deleted_at = $3,
+			    version = version + 1
+			WHERE
+			    user_id = (SELECT user_id FROM auth WHERE username = $1 AND password = $2) AND
+			    deleted_at IS NULL
+			RETURNING
+			    task_id, user_id
+		`,
+			username,
+			password,
+			sqliteNow(),
+		)
+
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return taskIDs, nil
+}
+
+func (s SQLite) DeleteTaskPermanently(ctx context.Context, username, password string, taskID, version int) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	return s.withTx(ctx, func(q querier) error {
+		return deleteTaskPermanently(ctx, q, sqliteWriteTaskHistory, username, password, taskID, version)
+	})
+}
+
+func (s SQLite) DeleteTasksPermanently(ctx context.Context, username, password string) ([]int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	var taskIDs []int
+
+	if err := s.withTx(ctx, func(q querier) error {
+		var err error
+
+		taskIDs, err = deleteTasksPermanently(ctx, q, sqliteWriteTaskHistory, username, password)
+
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return taskIDs, nil
+}
+
+func (s SQLite) GetTrash(ctx context.Context, username, password string) ([]DeletedTask, error) {
+	return s.portable().GetTrash(ctx, username, password)
+}
+
+func (s SQLite) RestoreTask(ctx context.Context, username, password string, taskID int) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	return s.withTx(ctx, func(q querier) error {
+		return restoreTask(ctx, q, sqliteWriteTaskHistory, username, password, taskID)
+	})
+}
+
+func (s SQLite) RestoreTasks(ctx context.Context, username, password string) ([]int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	var taskIDs []int
+
+	if err := s.withTx(ctx, func(q querier) error {
+		var err error
+
+		taskIDs, err = restoreTasks(ctx, q, sqliteWriteTaskHistory, username, password)
+
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return taskIDs, nil
+}
+
+func (s SQLite) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	var purged int64
+
+	if err := s.withTx(ctx, func(q querier) error {
+		var err error
+
+		purged, err = purgeTrash(ctx, q, sqliteWriteTaskHistory, before.UTC())
+
+		return err
+	}); err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
+
+func (s SQLite) GetTaskHistory(ctx context.Context, username, password string, taskID int) ([]TaskChange, error) {
+	return s.portable().GetTaskHistory(ctx, username, password, taskID)
+}
+
+func (s SQLite) GetTasksHistory(
+	ctx context.Context, username, password string, taskIDs []int,
+) (map[int][]TaskChange, error) {
+	return s.portable().GetTasksHistory(ctx, username, password, taskIDs)
+}
+
+func (s SQLite) GetTaskRevision(ctx context.Context, username, password string, taskID, revision int) (Task, error) {
+	return s.portable().GetTaskRevision(ctx, username, password, taskID, revision)
+}
+
+func (s SQLite) RevertTask(ctx context.Context, username, password string, taskID, revision int) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	return s.withTx(ctx, func(q querier) error {
+		task, err := sqliteSelectTask(ctx, q, username, password, taskID)
+		if err != nil {
+			return fmt.Errorf("select task: %w", err)
+		}
+
+		history, err := s.portable().selectTaskHistory(ctx, q, taskID)
+		if err != nil {
+			return fmt.Errorf("select task history: %w", err)
+		}
+
+		task, err = taskAtRevision(task, history, revision)
+		if err != nil {
+			return fmt.Errorf("taskId %d: %w", taskID, err)
+		}
+
+		return sqliteUpdateTaskWithHistory(ctx, q, username, password, taskID, 0, revertTaskUpdate(task))
+	})
+}
+
+// BatchTasks the same as Postgres.BatchTasks.
+func (s SQLite) BatchTasks(
+	ctx context.Context, username, password string, operations []TaskOperation, atomic bool,
+) ([]TaskOperationResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	var results []TaskOperationResult
+
+	if err := s.withTx(ctx, func(q querier) error {
+		var err error
+
+		results, err = batchTasks(ctx, q, operations, atomic, func(op TaskOperation) (int, error) {
+			return sqliteRunTaskOperation(ctx, q, username, password, op)
+		})
+
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// ReserveIdempotencyKey the same as Postgres.ReserveIdempotencyKey.
+func (s SQLite) ReserveIdempotencyKey(
+	ctx context.Context, scope, key, fingerprint string, expires time.Time,
+) (IdempotentResponse, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	var reserved bool
+
+	if err := s.conn(ctx).QueryRowContext(ctx, `
+		INSERT INTO
+			idempotency_key(scope, idempotency_key, fingerprint, created, expires)
+		VALUES
+		    ($1, $2, $3, $4, $5)
+		ON CONFLICT (scope, idempotency_key) DO UPDATE SET
+		    fingerprint = excluded.fingerprint,
+		    status = null,
+		    content_type = null,
+		    body = null,
+		    created = excluded.created,
+		    expires = excluded.expires
+		WHERE
+		    idempotency_key.expires < excluded.created
+		RETURNING
+		    true
+	`,
+		scope,
+		key,
+		fingerprint,
+		sqliteNow(),
+		expires.UTC(),
+	).Scan(
+		&reserved,
+	); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return IdempotentResponse{}, false, fmt.Errorf("query row: %w", err)
+	}
+
+	if reserved {
+		return IdempotentResponse{}, true, nil
+	}
+
+	var (
+		response    IdempotentResponse
+		status      sql.NullInt64
+		contentType sql.NullString
+	)
+
+	if err := s.conn(ctx).QueryRowContext(ctx, `
+		SELECT
+		    fingerprint, status, content_type, body
+		FROM
+		    idempotency_key
+		WHERE
+		    scope = $1 AND
+		    idempotency_key = $2
+	`,
+		scope,
+		key,
+	).Scan(
+		&response.Fingerprint,
+		&status,
+		&contentType,
+		&response.Body,
+	); err != nil {
+		return IdempotentResponse{}, false, fmt.Errorf("query row: %w", err)
+	}
+
+	response.Status = int(status.Int64)
+	response.ContentType = contentType.String
+
+	return response, false, nil
+}
+
+func (s SQLite) SaveIdempotentResponse(ctx context.Context, scope, key string, response IdempotentResponse) error {
+	return s.portable().SaveIdempotentResponse(ctx, scope, key, response)
+}
+
+func (s SQLite) DeleteIdempotencyKey(ctx context.Context, scope, key string) error {
+	return s.portable().DeleteIdempotencyKey(ctx, scope, key)
+}
+
+func (s SQLite) PurgeIdempotencyKeys(ctx context.Context, before time.Time) (int64, error) {
+	return s.portable().PurgeIdempotencyKeys(ctx, before.UTC())
+}
+
+func (s SQLite) CreateWebhook(ctx context.Context, userID int, webhook Webhook) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	var webhookID int
+
+	//nolint:execinquery
+	if err := s.conn(ctx).QueryRowContext(ctx, `
+		INSERT INTO
+			webhook(user_id, url, secret, events, active, failures, created)
+		VALUES
+		    (NULLIF($1, 0), $2, $3, $4, true, 0, $5)
+		RETURNING
+		    webhook_id
+	`,
+		userID,
+		webhook.URL,
+		webhook.Secret,
+		strings.Join(webhook.Events, ","),
+		sqliteNow(),
+	).Scan(
+		&webhookID,
+	); err != nil {
+		return 0, fmt.Errorf("query row: %w", err)
+	}
+
+	return webhookID, nil
+}
+
+func (s SQLite) GetWebhooks(ctx context.Context, userID int) ([]Webhook, error) {
+	return s.portable().GetWebhooks(ctx, userID)
+}
+
+func (s SQLite) DeleteWebhook(ctx context.Context, userID, webhookID int) error {
+	return s.portable().DeleteWebhook(ctx, userID, webhookID)
+}
+
+func (s SQLite) EnableWebhook(ctx context.Context, userID, webhookID int) error {
+	return s.portable().EnableWebhook(ctx, userID, webhookID)
+}
+
+func (s SQLite) GetWebhookDeliveries(ctx context.Context, userID, webhookID, limit int) ([]WebhookDelivery, error) {
+	return s.portable().GetWebhookDeliveries(ctx, userID, webhookID, limit)
+}
+
+// EnqueueWebhookEvent the same as Postgres.EnqueueWebhookEvent.
+func (s SQLite) EnqueueWebhookEvent(ctx context.Context, event Event) (int64, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return 0, fmt.Errorf("marshal event: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	res, err := s.conn(ctx).ExecContext(ctx, `
+		INSERT INTO
+			webhook_delivery(webhook_id, event_id, event, payload, status, attempts, next_attempt, created)
+		SELECT
+		    webhook_id, $2, $3, $4, $5, 0, $6, $6
+		FROM
+		    webhook
+		WHERE
+		    active AND
+		    (user_id = $1 OR user_id IS NULL) AND
+		    ',' || events || ',' LIKE '%,' || $3 || ',%'
+	`,
+		event.UserID,
+		event.ID,
+		event.Type,
+		string(payload),
+		WebhookDeliveryPending,
+		sqliteNow(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("exec: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// ClaimWebhookDeliveries without the row locks, the writes are serialized by SQLite.
+func (s SQLite) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	var deliveries []WebhookDelivery
+
+	if err := s.withTx(ctx, func(q querier) error {
+		now := sqliteNow()
+
+		rows, err := q.QueryContext(ctx, `
+			SELECT
+			    d.delivery_id, d.webhook_id, d.event_id, d.event, d.status, d.attempts, d.payload, d.next_attempt,
+			    d.created, w.url, w.secret
+			FROM
+			    webhook_delivery d
+			JOIN
+			    webhook w USING (webhook_id)
+			WHERE
+			    d.status = $1 AND
+			    d.next_attempt <= $2 AND
+			    w.active
+			ORDER BY
+			    d.next_attempt
+			LIMIT $3
+		`,
+			WebhookDeliveryPending,
+			now,
+			limit,
+		)
+		if err != nil {
+			return fmt.Errorf("query: %w", err)
+		}
+
+		defer func() {
+			if err := rows.Close(); err != nil {
+				s.Logger.Errorf("claim webhook deliveries: %v", err)
+			}
+		}()
+
+		deliveries, err = scanClaimedWebhookDeliveries(rows)
+		if err != nil {
+			return err
+		}
+
+		for i := range deliveries {
+			deliveries[i].NextAttempt = now.Add(lease)
+
+			if _, err := q.ExecContext(ctx, `
+				UPDATE
+				    webhook_delivery
+				SET
+				    next_attempt = $2
+				WHERE
+				    delivery_id = $1
+			`,
+				deliveries[i].ID,
+				deliveries[i].NextAttempt,
+			); err != nil {
+				return fmt.Errorf("exec: %w", err)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// SaveWebhookAttempt the same as Postgres.SaveWebhookAttempt.
+func (s SQLite) SaveWebhookAttempt(ctx context.Context, attempt WebhookAttempt) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	return s.withTx(ctx, func(q querier) error {
+		var webhookID int
+
+		if err := q.QueryRowContext(ctx, `
+			UPDATE
+			    webhook_delivery
+			SET
+			    status = $2,
+			    attempts = attempts + 1,
+			    response_status = NULLIF($3, 0),
+			    error = NULLIF($4, ''),
+			    next_attempt = $5
+			WHERE
+			    delivery_id = $1 AND
+			    next_attempt = $6
+			RETURNING
+			    webhook_id
+		`,
+			attempt.DeliveryID,
+			attempt.status(),
+			attempt.ResponseStatus,
+			attempt.Error,
+			sqliteNow().Add(attempt.RetryAfter),
+			attempt.ClaimedUntil.UTC(),
+		).Scan(
+			&webhookID,
+		); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+
+			return fmt.Errorf("query row: %w", err)
+		}
+
+		return saveWebhookFailures(ctx, q, webhookID, attempt, func(notification Notification) error {
+			if _, err := s.createNotification(ctx, q, notification); err != nil {
+				return fmt.Errorf("create notification: %w", err)
+			}
+
+			return nil
+		})
+	})
+}
+
+func (s SQLite) PurgeWebhookDeliveries(ctx context.Context, before time.Time) (int64, error) {
+	return s.portable().PurgeWebhookDeliveries(ctx, before.UTC())
+}
+
+// SaveEvent the subscribers are notified by the caller, SQLite has no NOTIFY.
+func (s SQLite) SaveEvent(ctx context.Context, userID int, event Event) (int64, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return 0, fmt.Errorf("marshal event: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	var seq int64
+
+	//nolint:execinquery
+	if err := s.conn(ctx).QueryRowContext(ctx, `
+		INSERT INTO
+			event(user_id, event, task_id, payload, created)
+		VALUES
+		    ($1, $2, NULLIF($3, 0), $4, $5)
+		RETURNING
+		    event_seq
+	`,
+		userID,
+		event.Type,
+		event.TaskID,
+		string(payload),
+		sqliteNow(),
+	).Scan(
+		&seq,
+	); err != nil {
+		return 0, fmt.Errorf("query row: %w", err)
+	}
+
+	return seq, nil
+}
+
+func (s SQLite) GetEvents(ctx context.Context, userID int, afterSeq int64, limit int) ([]Event, error) {
+	return s.portable().GetEvents(ctx, userID, afterSeq, limit)
+}
+
+func (s SQLite) GetLastEventSeq(ctx context.Context, userID int) (int64, error) {
+	return s.portable().GetLastEventSeq(ctx, userID)
+}
+
+func (s SQLite) PurgeEvents(ctx context.Context, before time.Time) (int64, error) {
+	return s.portable().PurgeEvents(ctx, before.UTC())
+}
+
+func (s SQLite) SetCalendarToken(ctx context.Context, userID int, tokenHash string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	if _, err := s.conn(ctx).ExecContext(ctx, `
+		INSERT INTO
+			calendar_token(user_id, token, created)
+		VALUES
+		    ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET
+		    token = excluded.token,
+		    created = excluded.created
+	`,
+		userID,
+		tokenHash,
+		sqliteNow(),
+	); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return nil
+}
+
+func (s SQLite) DeleteCalendarToken(ctx context.Context, userID int) error {
+	return s.portable().DeleteCalendarToken(ctx, userID)
+}
+
+func (s SQLite) GetCalendarTasks(ctx context.Context, tokenHash string) ([]Task, error) {
+	return s.portable().GetCalendarTasks(ctx, tokenHash)
+}
+
+func (s SQLite) CreateReminder(ctx context.Context, userID, taskID int, reminder Reminder) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	var reminderID int
+
+	//nolint:execinquery
+	if err := s.conn(ctx).QueryRowContext(ctx, `
+		INSERT INTO
+			reminder(user_id, task_id, remind_at, before_minutes, channel, email, status, attempts, created)
+		SELECT
+		    user_id, task_id, $3, $4, $5, NULLIF($6, ''), $7, 0, $8
+		FROM
+		    task
+		WHERE
+		    task_id = $2 AND
+		    user_id = $1 AND
+		    deleted_at IS NULL
+		RETURNING
+		    reminder_id
+	`,
+		userID,
+		taskID,
+		sqliteNullTimeRef(reminder.RemindAt),
+		reminder.BeforeMinutes,
+		reminder.Channel,
+		reminder.Email,
+		ReminderPending,
+		sqliteNow(),
+	).Scan(
+		&reminderID,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("taskId %d: %w", taskID, ErrTaskNotFound)
+		}
+
+		return 0, fmt.Errorf("query row: %w", err)
+	}
+
+	return reminderID, nil
+}
+
+func (s SQLite) GetReminders(ctx context.Context, userID, taskID int) ([]Reminder, error) {
+	return s.portable().GetReminders(ctx, userID, taskID)
+}
+
+func (s SQLite) DeleteReminder(ctx context.Context, userID, taskID, reminderID int) error {
+	return s.portable().DeleteReminder(ctx, userID, taskID, reminderID)
+}
+
+// ClaimReminders without the row locks, the writes are serialized by SQLite.
+// The due time of the offset reminders is compared in the text format of the timestamps.
+func (s SQLite) ClaimReminders(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]Reminder, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	now = now.UTC()
+	claimedUntil := now.Add(lease)
+
+	var reminders []Reminder
+
+	if err := s.withTx(ctx, func(q querier) error {
+		rows, err := q.QueryContext(ctx, `
+			SELECT
+			    r.reminder_id, r.task_id, r.remind_at, r.before_minutes, r.channel, r.email, r.status, r.attempts,
+			    r.error, r.sent, r.created, t.due, r.user_id,
+			    t.status, t.title, t.created, t.updated, t.completed, t.recurrence, t.version
+			FROM
+			    reminder r
+			JOIN
+			    task t USING (task_id)
+			WHERE
+			    r.status = $1 AND
+			    (r.next_attempt IS NULL OR r.next_attempt <= $2) AND
+			    COALESCE(r.remind_at, datetime(t.due, '-' || r.before_minutes || ' minutes')) <= $2 AND
+			    NOT t.status AND
+			    t.deleted_at IS NULL
+			ORDER BY
+			    COALESCE(r.remind_at, datetime(t.due, '-' || r.before_minutes || ' minutes')), r.reminder_id
+			LIMIT $3
+		`,
+			ReminderPending,
+			now,
+			limit,
+		)
+		if err != nil {
+			return fmt.Errorf("query: %w", err)
+		}
+
+		defer func() {
+			if err := rows.Close(); err != nil {
+				s.Logger.Errorf("claim reminders: %v", err)
+			}
+		}()
+
+		reminders, err = scanClaimedReminders(rows, claimedUntil)
+		if err != nil {
+			return err
+		}
+
+		for _, reminder := range reminders {
+			if _, err := q.ExecContext(ctx, `
+				UPDATE
+				    reminder
+				SET
+				    next_attempt = $2
+				WHERE
+				    reminder_id = $1
+			`,
+				reminder.ID,
+				claimedUntil,
+			); err != nil {
+				return fmt.Errorf("exec: %w", err)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return reminders, nil
+}
+
+func (s SQLite) SaveReminderAttempt(ctx context.Context, attempt ReminderAttempt) error {
+	return s.portable().SaveReminderAttempt(ctx, attempt)
+}
+
+// CreateProject the same as Postgres.CreateProject.
+func (s SQLite) CreateProject(ctx context.Context, userID int, name string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	var projectID int
+
+	if err := s.withTx(ctx, func(q querier) error {
+		now := sqliteNow()
+
+		//nolint:execinquery
+		if err := q.QueryRowContext(ctx, `
+			INSERT INTO
+				project(user_id, name, created)
+			VALUES
+			    ($1, $2, $3)
+			RETURNING
+			    project_id
+		`,
+			userID,
+			name,
+			now,
+		).Scan(
+			&projectID,
+		); err != nil {
+			return fmt.Errorf("query row: %w", err)
+		}
+
+		if _, err := q.ExecContext(ctx, `
+			INSERT INTO
+				project_member(project_id, user_id, created)
+			VALUES
+			    ($1, $2, $3)
+		`,
+			projectID,
+			userID,
+			now,
+		); err != nil {
+			return fmt.Errorf("exec: %w", err)
+		}
+
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	return projectID, nil
+}
+
+func (s SQLite) GetProjects(ctx context.Context, userID int) ([]Project, error) {
+	return s.portable().GetProjects(ctx, userID)
+}
+
+func (s SQLite) DeleteProject(ctx context.Context, userID, projectID int) error {
+	return s.portable().DeleteProject(ctx, userID, projectID)
+}
+
+func (s SQLite) GetProjectMembers(ctx context.Context, userID, projectID int) ([]ProjectMember, error) {
+	return s.portable().GetProjectMembers(ctx, userID, projectID)
+}
+
+// AddProjectMember the same as Postgres.AddProjectMember.
+func (s SQLite) AddProjectMember(ctx context.Context, userID, projectID int, username string) (int, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	return addProjectMember(ctx, s.conn(ctx), userID, projectID, username, `
+		INSERT INTO
+			project_member(project_id, user_id, created)
+		SELECT
+		    p.project_id, a.user_id, $4
+		FROM
+		    project p, auth a
+		WHERE
+		    p.project_id = $2 AND
+		    p.user_id = $1 AND
+		    a.username = $3
+		ON CONFLICT DO NOTHING
+		RETURNING
+		    user_id
+	`,
+		sqliteNow(),
+	)
+}
+
+func (s SQLite) RemoveProjectMember(ctx context.Context, userID, projectID, memberID int) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	return s.withTx(ctx, func(q querier) error {
+		return removeProjectMember(ctx, q, userID, projectID, memberID)
+	})
+}
+
+func (s SQLite) IsProjectMember(ctx context.Context, userID, projectID int) (bool, error) {
+	return s.portable().IsProjectMember(ctx, userID, projectID)
+}
+
+func (s SQLite) GetProjectMemberIDs(ctx context.Context, projectID int) ([]int, error) {
+	return s.portable().GetProjectMemberIDs(ctx, projectID)
+}
+
+func (s SQLite) GetTaskUserIDs(ctx context.Context, taskID int) ([]int, error) {
+	return s.portable().GetTaskUserIDs(ctx, taskID)
+}
+
+func (s SQLite) GetTaskProjects(ctx context.Context, userID int) (map[int]int, error) {
+	return s.portable().GetTaskProjects(ctx, userID)
+}
+
+// SetTaskProject the same as Postgres.SetTaskProject.
+func (s SQLite) SetTaskProject(ctx context.Context, username, password string, taskID, projectID int) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	return setTaskProject(ctx, s.conn(ctx), username, password, taskID, projectID, `
+		UPDATE
+		    task
+		SET
+		    project_id = NULLIF($4, 0),
+		    updated = $5,
+		    version = version + 1
+		WHERE
+		    user_id = (SELECT user_id FROM auth WHERE username = $1 AND password = $2) AND
+		    task_id = $3 AND
+		    deleted_at IS NULL AND
+		    (
+		        $4 = 0 OR
+		        EXISTS (
+		            SELECT
+		                1
+		            FROM
+		                project_member m
+		            WHERE
+		                m.project_id = $4 AND
+		                m.user_id = task.user_id
+		        )
+		    )
+	`,
+		sqliteNow(),
+	)
+}
+
+func (s SQLite) GetProjectTasks(ctx context.Context, userID, projectID int) ([]Task, error) {
+	return s.portable().GetProjectTasks(ctx, userID, projectID)
+}
+
+func (s SQLite) SetTaskTags(ctx context.Context, userID, taskID int, tags []string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	return s.withTx(ctx, func(q querier) error {
+		return setTaskTags(ctx, q, userID, taskID, tags)
+	})
+}
+
+func (s SQLite) GetTasksTags(ctx context.Context, userID int, taskIDs []int) (map[int][]string, error) {
+	return s.portable().GetTasksTags(ctx, userID, taskIDs)
+}
+
+// CreateTaskComment the same as Postgres.CreateTaskComment.
+func (s SQLite) CreateTaskComment(ctx context.Context, userID, taskID int, text string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	return createTaskComment(ctx, s.conn(ctx), userID, taskID, text, `
+		INSERT INTO
+			task_comment(task_id, user_id, text, created)
+		SELECT
+		    t.task_id, $1, $3, $4
+		FROM
+		    task t
+		WHERE
+		    t.task_id = $2 AND
+		    `+visibleTaskCondition+`
+		RETURNING
+		    comment_id
+	`,
+		sqliteNow(),
+	)
+}
+
+func (s SQLite) GetTasksComments(ctx context.Context, userID int, taskIDs []int) (map[int][]TaskComment, error) {
+	return s.portable().GetTasksComments(ctx, userID, taskIDs)
+}
+
+func (s SQLite) DeleteTaskComment(ctx context.Context, userID, commentID int) error {
+	return s.portable().DeleteTaskComment(ctx, userID, commentID)
+}
+
+// SetTaskParent the same as Postgres.SetTaskParent.
+func (s SQLite) SetTaskParent(ctx context.Context, username, password string, taskID, parentID int) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	return setTaskParent(ctx, s.conn(ctx), username, password, taskID, parentID, `
+		UPDATE
+		    task
+		SET
+		    parent_id = NULLIF($4, 0),
+		    updated = $5,
+		    version = version + 1
+		WHERE
+		    user_id = (SELECT user_id FROM auth WHERE username = $1 AND password = $2) AND
+		    task_id = $3 AND
+		    deleted_at IS NULL AND
+		    (
+		        $4 = 0 OR
+		        (
+		            $4 <> $3 AND
+		            EXISTS (
+		                SELECT
+		                    1
+		                FROM
+		                    task p
+		                WHERE
+		                    p.task_id = $4 AND
+		                    p.user_id = task.user_id AND
+		                    p.parent_id IS NULL AND
+		                    p.deleted_at IS NULL
+		            ) AND
+		            NOT EXISTS (
+		                SELECT
+		                    1
+		                FROM
+		                    task s
+		                WHERE
+		                    s.parent_id = $3
+		            )
+		        )
+		    )
+	`,
+		sqliteNow(),
+	)
+}
+
+func (s SQLite) GetSubtasks(ctx context.Context, userID int, parentIDs []int) (map[int][]Task, error) {
+	return s.portable().GetSubtasks(ctx, userID, parentIDs)
+}
+
+func sqliteCreateTask(ctx context.Context, q querier, username, password, title string) (int, error) {
+	var taskID int
+
+	if err := q.QueryRowContext(ctx, `
+		INSERT INTO
+			task(user_id, status, title, created, updated)
+		VALUES
+		    ((SELECT user_id FROM auth WHERE username = $1 AND password = $2), $3, $4, $5, $5)
+		RETURNING
+		    task_id
+	`,
+		username,
+		password,
+		false,
+		title,
+		sqliteNow(),
+	).Scan(
+		&taskID,
+	); err != nil {
+		return 0, createTaskError(username, err)
+	}
+
+	return taskID, nil
+}
+
+func sqliteDeleteTask(ctx context.Context, q querier, username, password string, taskID, version int) error {
+	taskIDs, err := changeTasks(ctx, q, sqliteWriteTaskHistory, username, taskMovedToTrash, `
+		UPDATE
+		    task
+		SET
+		    deleted_at = $5,
+		    version = version + 1
+		WHERE
+		    user_id = (SELECT user_id FROM auth WHERE username = $1 AND password = $2) AND
+		    task_id = $3 AND
+		    deleted_at IS NULL AND
+		    ($4 = 0 OR version = $4)
+		RETURNING
+		    task_id, user_id
+	`,
+		username,
+		password,
+		taskID,
+		version,
+		sqliteNow(),
+	)
+	if err != nil {
+		return err
+	}
+
+	if len(taskIDs) != 1 {
+		return taskNotAffectedError(ctx, q, username, password, taskID, version, false)
+	}
+
+	return nil
+}
+
+func sqliteNullTimeRef(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+
+	return sql.NullTime{Time: t.UTC(), Valid: true}
+}
+
+// sqliteSelectTask without the row lock, the writes are serialized by SQLite.
+func sqliteSelectTask(ctx context.Context, q querier, username, password string, taskID int) (Task, error) {
+	var (
+		task           Task
+		taskCompleted  sql.NullTime
+		taskDue        sql.NullTime
+		taskRecurrence sql.NullString
+	)
+
+	if err := q.QueryRowContext(ctx, `
+		SELECT
+		    t.status, t.title, t.created, t.updated, t.completed, t.due, t.recurrence, t.version
+		FROM
+		    task t
+		JOIN
+		    auth a USING (user_id)
+		WHERE
+		    a.username = $1 AND
+		    a.password = $2 AND
+		    t.task_id = $3 AND
+		    t.deleted_at IS NULL
+	`,
+		username,
+		password,
+		taskID,
+	).Scan(
+		&task.Status,
+		&task.Title,
+		&task.Created,
+		&task.Updated,
+		&taskCompleted,
+		&taskDue,
+		&taskRecurrence,
+		&task.Version,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Task{}, fmt.Errorf("taskId: %d: %w", taskID, ErrTaskNotFound)
+		}
+
+		return Task{}, fmt.Errorf("query row: %w", err)
+	}
+
+	task.Completed = taskCompleted.Time
+	task.Due = nullTimeRef(taskDue)
+	task.Recurrence = taskRecurrence.String
+
+	return task, nil
+}
+
+// sqliteUpdateTaskWithHistory the same as updateTaskWithHistory, the update is applied in Go.
+func sqliteUpdateTaskWithHistory(
+	ctx context.Context, q querier, username, password string, taskID, version int, update TaskUpdate,
+) error {
+	oldTask, err := sqliteSelectTask(ctx, q, username, password, taskID)
+	if err != nil {
+		return err
+	}
+
+	if version != 0 && version != oldTask.Version {
+		return fmt.Errorf("taskId %d: version %d: current %d: %w", taskID, version, oldTask.Version, ErrVersionMismatch)
+	}
+
+	now := sqliteNow()
+	newTask := update.apply(oldTask, now)
+
+	var userID int
+
+	if err := q.QueryRowContext(ctx, `
+		UPDATE
+			task
+		SET
+		    status = $2,
+		    title = $3,
+		    completed = $4,
+		    due = $5,
+		    recurrence = $6,
+		    updated = $7,
+		    version = $8
+		WHERE
+		    task_id = $1
+		RETURNING
+		    user_id
+	`,
+		taskID,
+		newTask.Status,
+		newTask.Title,
+		sql.NullTime{Time: newTask.Completed.UTC(), Valid: !newTask.Completed.IsZero()},
+		sqliteNullTimeRef(newTask.Due),
+		sql.NullString{String: newTask.Recurrence, Valid: newTask.Recurrence != ""},
+		newTask.Updated.UTC(),
+		newTask.Version,
+	).Scan(
+		&userID,
+	); err != nil {
+		return fmt.Errorf("query row: %w", err)
+	}
+
+	changes := diffTask(oldTask, newTask)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	if err := sqliteInsertTaskHistory(ctx, q, taskID, userID, username, changes, now); err != nil {
+		return fmt.Errorf("insert task history: %w", err)
+	}
+
+	return nil
+}
+
+// sqliteWriteTaskHistory the taskHistoryWriter of SQLite.
+func sqliteWriteTaskHistory(
+	ctx context.Context, q querier, taskID, userID int, actor string, changes []TaskChange,
+) error {
+	return sqliteInsertTaskHistory(ctx, q, taskID, userID, actor, changes, sqliteNow())
+}
+
+func sqliteInsertTaskHistory(
+	ctx context.Context, q querier, taskID, userID int, actor string, changes []TaskChange, changed time.Time,
+) error {
+	var revision int
+
+	if err := q.QueryRowContext(ctx, `
+		SELECT
+		    COALESCE(MAX(revision), 0) + 1
+		FROM
+		    task_history
+		WHERE
+		    task_id = $1
+	`,
+		taskID,
+	).Scan(
+		&revision,
+	); err != nil {
+		return fmt.Errorf("query row: %w", err)
+	}
+
+	for _, change := range changes {
+		if _, err := q.ExecContext(ctx, `
+			INSERT INTO
+				task_history(task_id, user_id, revision, field, old_value, new_value, actor, changed)
+			VALUES
+			    ($1, $2, $3, $4, $5, $6, $7, $8)
+		`,
+			taskID,
+			userID,
+			revision,
+			change.Field,
+			sql.NullString{String: change.Old, Valid: change.Old != ""},
+			sql.NullString{String: change.New, Valid: change.New != ""},
+			actor,
+			changed,
+		); err != nil {
+			return fmt.Errorf("exec: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func sqliteRunTaskOperation(
+	ctx context.Context, q querier, username, password string, op TaskOperation,
+) (int, error) {
+	switch op.Op {
+	case TaskOperationCreate:
+		return sqliteCreateTask(ctx, q, username, password, op.Title)
+
+	case TaskOperationUpdate:
+		return op.TaskID, sqliteUpdateTaskWithHistory(ctx, q, username, password, op.TaskID, op.Version, op.Update)
+
+	case TaskOperationDelete:
+		if op.Permanent {
+			return op.TaskID, deleteTaskPermanently(
+				ctx, q, sqliteWriteTaskHistory, username, password, op.TaskID, op.Version,
+			)
+		}
+
+		return op.TaskID, sqliteDeleteTask(ctx, q, username, password, op.TaskID, op.Version)
+	}
+
+	return 0, fmt.Errorf("%w: %s", errUnknownOperation, op.Op)
+}
+
+func (s SQLite) CreateNotification(ctx context.Context, notification Notification) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	return s.createNotification(ctx, s.conn(ctx), notification)
+}
+
+func (s SQLite) GetNotifications(ctx context.Context, userID int, query NotificationQuery) ([]Notification, error) {
+	return s.portable().GetNotifications(ctx, userID, query)
+}
+
+func (s SQLite) GetUnreadNotifications(ctx context.Context, userID int) (map[string]int, error) {
+	return s.portable().GetUnreadNotifications(ctx, userID)
+}
+
+func (s SQLite) ReadNotification(ctx context.Context, userID, notificationID int) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	res, err := s.conn(ctx).ExecContext(ctx, `
+		UPDATE
+		    notification
+		SET
+		    read = COALESCE(read, $3)
+		WHERE
+		    notification_id = $2 AND
+		    user_id = $1
+	`,
+		userID,
+		notificationID,
+		sqliteNow(),
+	)
+
+	return notificationReadResult(res, err, notificationID)
+}
+
+func (s SQLite) ReadNotifications(ctx context.Context, userID int) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	res, err := s.conn(ctx).ExecContext(ctx, `
+		UPDATE
+		    notification
+		SET
+		    read = $2
+		WHERE
+		    user_id = $1 AND
+		    read IS NULL
+	`,
+		userID,
+		sqliteNow(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("exec: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+func (s SQLite) GetNotificationPreferences(ctx context.Context, userID int) ([]NotificationPreference, error) {
+	return s.portable().GetNotificationPreferences(ctx, userID)
+}
+
+func (s SQLite) SetNotificationPreferences(
+	ctx context.Context, userID int, preferences []NotificationPreference,
+) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(s.QueryTimeout))
+	defer cancel()
+
+	return s.withTx(ctx, func(q querier) error {
+		return setNotificationPreferences(ctx, q, userID, preferences)
+	})
+}
+
+func (s SQLite) PurgeNotifications(ctx context.Context, before time.Time, maxPerUser int) (int64, error) {
+	return s.portable().PurgeNotifications(ctx, before.UTC(), maxPerUser)
+}
+
+// createNotification the same as Postgres.CreateNotification.
+func (s SQLite) createNotification(ctx context.Context, q querier, notification Notification) (int, error) {
+	var notificationID int
+
+	//nolint:execinquery
+	if err := q.QueryRowContext(ctx, `
+		INSERT INTO
+			notification(user_id, type, title, task_id, created)
+		SELECT
+		    $1, $2, $3, NULLIF($4, 0), $5
+		WHERE NOT EXISTS (
+		    SELECT
+		        1
+		    FROM
+		        notification_preference
+		    WHERE
+		        user_id = $1 AND
+		        type = $2 AND
+		        NOT enabled
+		)
+		RETURNING
+		    notification_id
+	`,
+		notification.UserID,
+		notification.Type,
+		notification.Title,
+		notification.TaskID,
+		sqliteNow(),
+	).Scan(
+		&notificationID,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+
+		return 0, fmt.Errorf("query row: %w", err)
+	}
+
+	return notificationID, nil
+}
@@ -0,0 +1,127 @@
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryPurge(t *testing.T) {
+	testStoragePurge(t, NewMemory())
+}
+
+type purgeStorage interface {
+	conformanceStorage
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
+	PurgeIdempotencyKeys(ctx context.Context, before time.Time) (int64, error)
+	PurgeWebhookDeliveries(ctx context.Context, before time.Time) (int64, error)
+	PurgeEvents(ctx context.Context, before time.Time) (int64, error)
+	PurgeNotifications(ctx context.Context, before time.Time, maxPerUser int) (int64, error)
+}
+
+// testStoragePurge purges all users, the storage must not be shared.
+func testStoragePurge(t *testing.T, m purgeStorage) {
+	ctx := context.Background()
+
+	_, err := m.CreateNewUser(ctx, "user", "password")
+	require.NoError(t, err)
+
+	taskID1, err := m.CreateTask(ctx, "user", "password", "task1")
+	require.NoError(t, err)
+
+	_, err = m.CreateTask(ctx, "user", "password", "task2")
+	require.NoError(t, err)
+
+	require.NoError(t, m.DeleteTask(ctx, "user", "password", taskID1, 0))
+
+	purged, err := m.PurgeTrash(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), purged)
+
+	purged, err = m.PurgeTrash(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	tasks, err := m.GetTasks(ctx, "user", "password")
+	require.NoError(t, err)
+	assert.Len(t, tasks, 1)
+
+	history, err := m.GetTaskHistory(ctx, "user", "password", taskID1)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assertTaskLifecycle(t, history[1], 2, taskDeletedPermanently, taskHistoryActorSystem)
+
+	_, _, err = m.ReserveIdempotencyKey(ctx, "scope", "key", "fingerprint", time.Now().Add(time.Minute))
+	require.NoError(t, err)
+
+	purged, err = m.PurgeIdempotencyKeys(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	_, err = m.CreateWebhook(ctx, 0, Webhook{URL: "http://127.0.0.1/hook", Events: []string{EventTaskCreated}})
+	require.NoError(t, err)
+
+	for _, eventID := range []string{"event1", "event2"} {
+		_, err = m.EnqueueWebhookEvent(ctx, Event{ID: eventID, Type: EventTaskCreated})
+		require.NoError(t, err)
+	}
+
+	deliveries, err := m.ClaimWebhookDeliveries(ctx, 1, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+
+	require.NoError(t, m.SaveWebhookAttempt(ctx, WebhookAttempt{
+		DeliveryID:   deliveries[0].ID,
+		ClaimedUntil: deliveries[0].NextAttempt,
+		Succeeded:    true,
+	}))
+
+	// The pending delivery is kept.
+	purged, err = m.PurgeWebhookDeliveries(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	userID, err := m.GetUserID(ctx, "user")
+	require.NoError(t, err)
+
+	_, err = m.SaveEvent(ctx, userID, Event{ID: "event1", Type: EventTaskCreated, UserID: userID})
+	require.NoError(t, err)
+
+	purged, err = m.PurgeEvents(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), purged)
+
+	purged, err = m.PurgeEvents(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	var notificationIDs []int
+
+	for i := 0; i < 3; i++ {
+		notificationID, err := m.CreateNotification(ctx, Notification{
+			UserID: userID,
+			Type:   NotificationTaskReminder,
+			Title:  "Reminder: task2",
+		})
+		require.NoError(t, err)
+
+		notificationIDs = append(notificationIDs, notificationID)
+	}
+
+	// Only the newest two are kept.
+	purged, err = m.PurgeNotifications(ctx, time.Now().Add(-time.Hour), 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+
+	notifications, err := m.GetNotifications(ctx, userID, NotificationQuery{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, notifications, 2)
+	assert.Equal(t, notificationIDs[2], notifications[0].ID)
+	assert.Equal(t, notificationIDs[1], notifications[1].ID)
+
+	purged, err = m.PurgeNotifications(ctx, time.Now().Add(time.Hour), 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+}
@@ -0,0 +1,1664 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taskmanager/internal/config"
+	"taskmanager/internal/db"
+)
+
+// conformanceStorage all storages must behave the same.
+type conformanceStorage interface {
+	CreateNewUser(ctx context.Context, username string, password string) (int, error)
+	DeleteUser(ctx context.Context, userID int) error
+	GetUsers(ctx context.Context) ([]User, error)
+	GetUserID(ctx context.Context, username string) (int, error)
+	GetUserIDByCredentials(ctx context.Context, username, password string) (int, error)
+	UpdateUserPassword(ctx context.Context, username, password string) error
+
+	CreateTask(ctx context.Context, username, password, title string) (int, error)
+	GetTask(ctx context.Context, username, password string, taskID int) (Task, error)
+	GetTasks(ctx context.Context, username, password string) ([]Task, error)
+	GetTasksPage(ctx context.Context, username, password string, query TaskQuery) ([]Task, error)
+	CountTasks(ctx context.Context, username, password string, completed *bool) (int, error)
+	UpdateTask(ctx context.Context, username, password string, taskID, version int, update TaskUpdate) error
+	DeleteTask(ctx context.Context, username, password string, taskID, version int) error
+	DeleteTasks(ctx context.Context, username, password string) ([]int, error)
+	DeleteTaskPermanently(ctx context.Context, username, password string, taskID, version int) error
+	DeleteTasksPermanently(ctx context.Context, username, password string) ([]int, error)
+
+	GetTrash(ctx context.Context, username, password string) ([]DeletedTask, error)
+	ExportTasks(ctx context.Context, username, password string, fn func(ExportedTask) error) error
+	RestoreTask(ctx context.Context, username, password string, taskID int) error
+	RestoreTasks(ctx context.Context, username, password string) ([]int, error)
+
+	GetTaskHistory(ctx context.Context, username, password string, taskID int) ([]TaskChange, error)
+	GetTasksHistory(ctx context.Context, username, password string, taskIDs []int) (map[int][]TaskChange, error)
+	GetTaskRevision(ctx context.Context, username, password string, taskID, revision int) (Task, error)
+	RevertTask(ctx context.Context, username, password string, taskID, revision int) error
+
+	BatchTasks(
+		ctx context.Context, username, password string, operations []TaskOperation, atomic bool,
+	) ([]TaskOperationResult, error)
+
+	ReserveIdempotencyKey(
+		ctx context.Context, scope, key, fingerprint string, expires time.Time,
+	) (IdempotentResponse, bool, error)
+	SaveIdempotentResponse(ctx context.Context, scope, key string, response IdempotentResponse) error
+	DeleteIdempotencyKey(ctx context.Context, scope, key string) error
+
+	CreateWebhook(ctx context.Context, userID int, webhook Webhook) (int, error)
+	GetWebhooks(ctx context.Context, userID int) ([]Webhook, error)
+	DeleteWebhook(ctx context.Context, userID, webhookID int) error
+	EnableWebhook(ctx context.Context, userID, webhookID int) error
+	GetWebhookDeliveries(ctx context.Context, userID, webhookID, limit int) ([]WebhookDelivery, error)
+	EnqueueWebhookEvent(ctx context.Context, event Event) (int64, error)
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]WebhookDelivery, error)
+	SaveWebhookAttempt(ctx context.Context, attempt WebhookAttempt) error
+
+	SaveEvent(ctx context.Context, userID int, event Event) (int64, error)
+	GetEvents(ctx context.Context, userID int, afterSeq int64, limit int) ([]Event, error)
+	GetLastEventSeq(ctx context.Context, userID int) (int64, error)
+
+	SetCalendarToken(ctx context.Context, userID int, tokenHash string) error
+	DeleteCalendarToken(ctx context.Context, userID int) error
+	GetCalendarTasks(ctx context.Context, tokenHash string) ([]Task, error)
+
+	CreateReminder(ctx context.Context, userID, taskID int, reminder Reminder) (int, error)
+	GetReminders(ctx context.Context, userID, taskID int) ([]Reminder, error)
+	DeleteReminder(ctx context.Context, userID, taskID, reminderID int) error
+	ClaimReminders(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]Reminder, error)
+	SaveReminderAttempt(ctx context.Context, attempt ReminderAttempt) error
+
+	CreateNotification(ctx context.Context, notification Notification) (int, error)
+	GetNotifications(ctx context.Context, userID int, query NotificationQuery) ([]Notification, error)
+	GetUnreadNotifications(ctx context.Context, userID int) (map[string]int, error)
+	ReadNotification(ctx context.Context, userID, notificationID int) error
+	ReadNotifications(ctx context.Context, userID int) (int64, error)
+	GetNotificationPreferences(ctx context.Context, userID int) ([]NotificationPreference, error)
+	SetNotificationPreferences(ctx context.Context, userID int, preferences []NotificationPreference) error
+
+	CreateProject(ctx context.Context, userID int, name string) (int, error)
+	GetProjects(ctx context.Context, userID int) ([]Project, error)
+	DeleteProject(ctx context.Context, userID, projectID int) error
+	GetProjectMembers(ctx context.Context, userID, projectID int) ([]ProjectMember, error)
+	AddProjectMember(ctx context.Context, userID, projectID int, username string) (int, bool, error)
+	RemoveProjectMember(ctx context.Context, userID, projectID, memberID int) error
+	IsProjectMember(ctx context.Context, userID, projectID int) (bool, error)
+	GetProjectMemberIDs(ctx context.Context, projectID int) ([]int, error)
+	GetTaskProjects(ctx context.Context, userID int) (map[int]int, error)
+	GetTaskUserIDs(ctx context.Context, taskID int) ([]int, error)
+	SetTaskProject(ctx context.Context, username, password string, taskID, projectID int) error
+	GetProjectTasks(ctx context.Context, userID, projectID int) ([]Task, error)
+	SetTaskTags(ctx context.Context, userID, taskID int, tags []string) error
+	GetTasksTags(ctx context.Context, userID int, taskIDs []int) (map[int][]string, error)
+	CreateTaskComment(ctx context.Context, userID, taskID int, text string) (int, error)
+	GetTasksComments(ctx context.Context, userID int, taskIDs []int) (map[int][]TaskComment, error)
+	DeleteTaskComment(ctx context.Context, userID, commentID int) error
+	SetTaskParent(ctx context.Context, username, password string, taskID, parentID int) error
+	GetSubtasks(ctx context.Context, userID int, parentIDs []int) (map[int][]Task, error)
+}
+
+var (
+	_ conformanceStorage = Postgres{}
+	_ conformanceStorage = Memory{}
+	_ conformanceStorage = SQLite{}
+)
+
+func TestMemoryConformance(t *testing.T) {
+	testStorageConformance(t, NewMemory())
+}
+
+func TestSQLiteConformance(t *testing.T) {
+	testStorageConformance(t, testSQLite(t))
+}
+
+func TestSQLitePurge(t *testing.T) {
+	testStoragePurge(t, testSQLite(t))
+}
+
+func testSQLite(t *testing.T) SQLite {
+	pool, err := db.CreateSQLitePool(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, pool.Close())
+	})
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	s := SQLite{
+		Pool:         pool,
+		Logger:       logger,
+		QueryTimeout: 5,
+	}
+
+	require.NoError(t, s.Migrate(context.Background()))
+
+	// Applied migrations are skipped.
+	require.NoError(t, s.Migrate(context.Background()))
+
+	return s
+}
+
+// TestPostgresConformance working database is required, DB_ADDRESS overrides the address of the config.
+func TestPostgresConformance(t *testing.T) {
+	conf, err := config.GetFromFile("../../configs/conf.toml")
+	require.NoError(t, err)
+
+	pool, err := sql.Open("postgres", db.Conf{ConnAddress: conf.Postgres.ConnAddress}.Address())
+	require.NoError(t, err)
+
+	if err := pool.Ping(); err != nil {
+		t.Skipf("SKIP - failed to connect to the database to run this test: %v", err)
+	}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	p := Postgres{
+		Pool:         pool,
+		Logger:       logger,
+		QueryTimeout: conf.Postgres.QueryTimeout,
+	}
+
+	require.NoError(t, p.Migrate(context.Background()))
+
+	testStorageConformance(t, p)
+}
+
+func testStorageConformance(t *testing.T, s conformanceStorage) {
+	ctx := context.Background()
+
+	username := fmt.Sprintf("conformance_%d", time.Now().UnixNano())
+	password := "password"
+
+	userID, err := s.CreateNewUser(ctx, username, password)
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, s.DeleteUser(ctx, userID))
+		assert.ErrorIs(t, s.DeleteUser(ctx, userID), ErrUserNotFound)
+	}()
+
+	t.Run("users", func(t *testing.T) {
+		_, err := s.CreateNewUser(ctx, username, "another")
+		assert.ErrorIs(t, err, ErrUserAlreadyExists)
+
+		_, err = s.CreateTask(ctx, username, "wrong", "task")
+		assert.ErrorIs(t, err, ErrUserNotFound)
+
+		users, err := s.GetUsers(ctx)
+		require.NoError(t, err)
+		assert.Contains(t, users, User{ID: userID, Username: username})
+
+		id, err := s.GetUserID(ctx, username)
+		require.NoError(t, err)
+		assert.Equal(t, userID, id)
+
+		id, err = s.GetUserIDByCredentials(ctx, username, password)
+		require.NoError(t, err)
+		assert.Equal(t, userID, id)
+
+		_, err = s.GetUserIDByCredentials(ctx, username, "wrong")
+		assert.ErrorIs(t, err, ErrUserNotFound)
+
+		_, err = s.GetUserID(ctx, username+"_unknown")
+		assert.ErrorIs(t, err, ErrUserNotFound)
+
+		require.NoError(t, s.UpdateUserPassword(ctx, username, "changed"))
+
+		_, err = s.CreateTask(ctx, username, password, "task")
+		assert.ErrorIs(t, err, ErrUserNotFound)
+
+		require.NoError(t, s.UpdateUserPassword(ctx, username, password))
+
+		assert.ErrorIs(t, s.UpdateUserPassword(ctx, username+"_unknown", password), ErrUserNotFound)
+	})
+
+	t.Run("tasks", func(t *testing.T) {
+		testStorageConformanceTasks(t, s, username, password)
+	})
+
+	t.Run("trash", func(t *testing.T) {
+		testStorageConformanceTrash(t, s, username, password)
+	})
+
+	t.Run("history", func(t *testing.T) {
+		testStorageConformanceHistory(t, s, username, password)
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		testStorageConformanceBatch(t, s, username, password)
+	})
+
+	t.Run("idempotency", func(t *testing.T) {
+		testStorageConformanceIdempotency(t, s, username)
+	})
+
+	t.Run("webhooks", func(t *testing.T) {
+		testStorageConformanceWebhooks(t, s, userID, username)
+	})
+
+	t.Run("events", func(t *testing.T) {
+		testStorageConformanceEvents(t, s, userID)
+	})
+
+	t.Run("calendar", func(t *testing.T) {
+		testStorageConformanceCalendar(t, s, userID, username, password)
+	})
+
+	t.Run("reminders", func(t *testing.T) {
+		testStorageConformanceReminders(t, s, userID, username, password)
+	})
+
+	t.Run("notifications", func(t *testing.T) {
+		testStorageConformanceNotifications(t, s, userID, username+"_notifications", password)
+	})
+
+	t.Run("export", func(t *testing.T) {
+		testStorageConformanceExport(t, s, username+"_export", password)
+	})
+
+	t.Run("projects", func(t *testing.T) {
+		testStorageConformanceProjects(t, s, userID, username, password)
+	})
+
+	t.Run("relations", func(t *testing.T) {
+		testStorageConformanceRelations(t, s, userID, username, password)
+	})
+}
+
+func testStorageConformanceTasks(t *testing.T, s conformanceStorage, username, password string) {
+	ctx := context.Background()
+
+	taskID, err := s.CreateTask(ctx, username, password, "task1")
+	require.NoError(t, err)
+
+	task, err := s.GetTask(ctx, username, password, taskID)
+	require.NoError(t, err)
+	assert.Equal(t, "task1", task.Title)
+	assert.False(t, task.Status)
+	assert.True(t, task.Completed.IsZero())
+	assert.Equal(t, 1, task.Version)
+
+	_, err = s.GetTask(ctx, username, "wrong", taskID)
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+
+	title := "task1_new"
+	require.NoError(t, s.UpdateTask(ctx, username, password, taskID, 1, TaskUpdate{Title: &title}))
+
+	err = s.UpdateTask(ctx, username, password, taskID, 1, TaskUpdate{Title: &title})
+	assert.ErrorIs(t, err, ErrVersionMismatch)
+
+	completed := true
+	require.NoError(t, s.UpdateTask(ctx, username, password, taskID, 0, TaskUpdate{Status: &completed}))
+
+	task, err = s.GetTask(ctx, username, password, taskID)
+	require.NoError(t, err)
+	assert.Equal(t, title, task.Title)
+	assert.True(t, task.Status)
+	assert.False(t, task.Completed.IsZero())
+	assert.Equal(t, 3, task.Version)
+
+	err = s.UpdateTask(ctx, username, password, taskID+1000000, 0, TaskUpdate{Title: &title})
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+
+	tasks, err := s.GetTasks(ctx, username, password)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, taskID, tasks[0].ID)
+
+	testStorageConformanceTasksPage(t, s, username, password, taskID)
+
+	err = s.DeleteTaskPermanently(ctx, username, password, taskID, 1)
+	assert.ErrorIs(t, err, ErrVersionMismatch)
+
+	require.NoError(t, s.DeleteTaskPermanently(ctx, username, password, taskID, 3))
+
+	_, err = s.GetTask(ctx, username, password, taskID)
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+}
+
+// testStorageConformanceTasksPage taskID - the only task of the user, completed.
+func testStorageConformanceTasksPage(t *testing.T, s conformanceStorage, username, password string, taskID int) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	otherTaskID, err := s.CreateTask(ctx, username, password, "task2")
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, s.DeleteTaskPermanently(ctx, username, password, otherTaskID, 0))
+	}()
+
+	completed, notCompleted := true, false
+
+	pageIDs := func(query TaskQuery) []int {
+		tasks, err := s.GetTasksPage(ctx, username, password, query)
+		require.NoError(t, err)
+
+		ids := make([]int, 0, len(tasks))
+		for _, task := range tasks {
+			ids = append(ids, task.ID)
+		}
+
+		return ids
+	}
+
+	assert.Equal(t, []int{taskID}, pageIDs(TaskQuery{Limit: 1}))
+	assert.Equal(t, []int{taskID, otherTaskID}, pageIDs(TaskQuery{Limit: 10}))
+	assert.Equal(t, []int{otherTaskID}, pageIDs(TaskQuery{AfterID: taskID, Limit: 10}))
+	assert.Equal(t, []int{taskID}, pageIDs(TaskQuery{Completed: &completed, Limit: 10}))
+	assert.Equal(t, []int{otherTaskID}, pageIDs(TaskQuery{Completed: &notCompleted, Limit: 10}))
+
+	tasks, err := s.GetTasksPage(ctx, username, "wrong", TaskQuery{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+
+	for _, tt := range []struct {
+		completed *bool
+		expected  int
+	}{
+		{completed: nil, expected: 2},
+		{completed: &completed, expected: 1},
+		{completed: &notCompleted, expected: 1},
+	} {
+		quantity, err := s.CountTasks(ctx, username, password, tt.completed)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, quantity)
+	}
+
+	quantity, err := s.CountTasks(ctx, username, "wrong", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, quantity)
+}
+
+func testStorageConformanceTrash(t *testing.T, s conformanceStorage, username, password string) {
+	ctx := context.Background()
+
+	taskID1, err := s.CreateTask(ctx, username, password, "task1")
+	require.NoError(t, err)
+
+	taskID2, err := s.CreateTask(ctx, username, password, "task2")
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, s.DeleteTask(ctx, username, password, taskID1, 2), ErrVersionMismatch)
+	require.NoError(t, s.DeleteTask(ctx, username, password, taskID1, 1))
+	assert.ErrorIs(t, s.DeleteTask(ctx, username, password, taskID1, 0), ErrTaskNotFound)
+
+	trash, err := s.GetTrash(ctx, username, password)
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	assert.Equal(t, taskID1, trash[0].ID)
+	assert.False(t, trash[0].Deleted.IsZero())
+
+	assert.ErrorIs(t, s.RestoreTask(ctx, username, password, taskID2), ErrTaskNotFound)
+	require.NoError(t, s.RestoreTask(ctx, username, password, taskID1))
+
+	task, err := s.GetTask(ctx, username, password, taskID1)
+	require.NoError(t, err)
+	assert.Equal(t, 3, task.Version)
+
+	deleted, err := s.DeleteTasks(ctx, username, password)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{taskID1, taskID2}, deleted)
+
+	tasks, err := s.GetTasks(ctx, username, password)
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+
+	restored, err := s.RestoreTasks(ctx, username, password)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{taskID1, taskID2}, restored)
+
+	require.NoError(t, s.DeleteTask(ctx, username, password, taskID2, 0))
+
+	var exported []ExportedTask
+
+	require.NoError(t, s.ExportTasks(ctx, username, password, func(task ExportedTask) error {
+		exported = append(exported, task)
+
+		return nil
+	}))
+	require.Len(t, exported, 2)
+	assert.Equal(t, taskID1, exported[0].ID)
+	assert.Equal(t, "task1", exported[0].Title)
+	assert.True(t, exported[0].Deleted.IsZero())
+	assert.Equal(t, taskID2, exported[1].ID)
+	assert.False(t, exported[1].Deleted.IsZero())
+
+	errStop := errors.New("stop")
+
+	calls := 0
+	err = s.ExportTasks(ctx, username, password, func(ExportedTask) error {
+		calls++
+
+		return errStop
+	})
+	assert.ErrorIs(t, err, errStop)
+	assert.Equal(t, 1, calls)
+
+	require.NoError(t, s.ExportTasks(ctx, username, "wrong", func(ExportedTask) error {
+		return errStop
+	}))
+
+	deleted, err = s.DeleteTasksPermanently(ctx, username, password)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{taskID1, taskID2}, deleted)
+
+	trash, err = s.GetTrash(ctx, username, password)
+	require.NoError(t, err)
+	assert.Empty(t, trash)
+
+	// Each move is recorded: the trash, the restore, the trash of all tasks, the restore of all tasks and
+	// the permanent deletion of all tasks.
+	history, err := s.GetTaskHistory(ctx, username, password, taskID1)
+	require.NoError(t, err)
+	require.Len(t, history, 5)
+
+	for i, expected := range []TaskChange{
+		taskMovedToTrash, taskRestored, taskMovedToTrash, taskRestored, taskDeletedPermanently,
+	} {
+		assertTaskLifecycle(t, history[i], i+1, expected, username)
+	}
+}
+
+func testStorageConformanceHistory(t *testing.T, s conformanceStorage, username, password string) {
+	ctx := context.Background()
+
+	taskID, err := s.CreateTask(ctx, username, password, "task1")
+	require.NoError(t, err)
+
+	history, err := s.GetTaskHistory(ctx, username, password, taskID)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+
+	title := "task1_new"
+	require.NoError(t, s.UpdateTask(ctx, username, password, taskID, 0, TaskUpdate{Title: &title}))
+
+	// Nothing changed - no revision.
+	require.NoError(t, s.UpdateTask(ctx, username, password, taskID, 0, TaskUpdate{Title: &title}))
+
+	completed := true
+	require.NoError(t, s.UpdateTask(ctx, username, password, taskID, 0, TaskUpdate{Status: &completed}))
+
+	history, err = s.GetTaskHistory(ctx, username, password, taskID)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.False(t, history[0].Changed.IsZero())
+
+	history[0].Changed = time.Time{}
+	assert.Equal(t, TaskChange{Revision: 1, Field: taskFieldTitle, Old: "task1", New: title, Actor: username}, history[0])
+	assert.Equal(t, 2, history[1].Revision)
+	assert.Equal(t, 2, history[2].Revision)
+
+	task, err := s.GetTaskRevision(ctx, username, password, taskID, 0)
+	require.NoError(t, err)
+	assert.Equal(t, taskID, task.ID)
+	assert.Equal(t, "task1", task.Title)
+	assert.False(t, task.Status)
+
+	_, err = s.GetTaskRevision(ctx, username, password, taskID, 3)
+	assert.ErrorIs(t, err, ErrRevisionNotFound)
+
+	require.NoError(t, s.RevertTask(ctx, username, password, taskID, 0))
+
+	task, err = s.GetTask(ctx, username, password, taskID)
+	require.NoError(t, err)
+	assert.Equal(t, "task1", task.Title)
+	assert.False(t, task.Status)
+	assert.True(t, task.Completed.IsZero())
+
+	history, err = s.GetTaskHistory(ctx, username, password, taskID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, history[len(history)-1].Revision)
+
+	otherTaskID, err := s.CreateTask(ctx, username, password, "task2")
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, s.DeleteTaskPermanently(ctx, username, password, otherTaskID, 0))
+	}()
+
+	// The tasks in the trash have the history too, the move to the trash is a revision.
+	require.NoError(t, s.DeleteTask(ctx, username, password, taskID, 0))
+
+	histories, err := s.GetTasksHistory(ctx, username, password, []int{taskID, otherTaskID, otherTaskID + 1000})
+	require.NoError(t, err)
+	require.Len(t, histories, 1)
+	require.Len(t, histories[taskID], len(history)+1)
+	assert.Equal(t, history, histories[taskID][:len(history)])
+	assertTaskLifecycle(t, histories[taskID][len(history)], 4, taskMovedToTrash, username)
+
+	require.NoError(t, s.RestoreTask(ctx, username, password, taskID))
+
+	// The revert does not move the task to the trash, revision 4 has the same fields.
+	require.NoError(t, s.RevertTask(ctx, username, password, taskID, 4))
+
+	history, err = s.GetTaskHistory(ctx, username, password, taskID)
+	require.NoError(t, err)
+	require.Len(t, history, 8)
+	assertTaskLifecycle(t, history[7], 5, taskRestored, username)
+
+	// The history is kept after the permanent deletion.
+	require.NoError(t, s.DeleteTaskPermanently(ctx, username, password, taskID, 0))
+
+	history, err = s.GetTaskHistory(ctx, username, password, taskID)
+	require.NoError(t, err)
+	require.Len(t, history, 9)
+	assertTaskLifecycle(t, history[8], 6, taskDeletedPermanently, username)
+
+	_, err = s.GetTaskHistory(ctx, username, "wrong", taskID)
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+
+	histories, err = s.GetTasksHistory(ctx, username, "wrong", []int{taskID})
+	require.NoError(t, err)
+	assert.Empty(t, histories)
+
+	// The task without changes has no history.
+	history, err = s.GetTaskHistory(ctx, username, password, otherTaskID)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+func assertTaskLifecycle(t *testing.T, change TaskChange, revision int, expected TaskChange, actor string) {
+	t.Helper()
+
+	assert.False(t, change.Changed.IsZero())
+
+	expected.Revision = revision
+	expected.Actor = actor
+	expected.Changed = change.Changed
+	assert.Equal(t, expected, change)
+}
+
+func testStorageConformanceBatch(t *testing.T, s conformanceStorage, username, password string) {
+	ctx := context.Background()
+
+	taskID, err := s.CreateTask(ctx, username, password, "task1")
+	require.NoError(t, err)
+
+	defer func() {
+		_, err := s.DeleteTasksPermanently(ctx, username, password)
+		require.NoError(t, err)
+	}()
+
+	title := "task1_new"
+
+	results, err := s.BatchTasks(ctx, username, password, []TaskOperation{
+		{Op: TaskOperationCreate, Title: "task2"},
+		{Op: TaskOperationUpdate, TaskID: taskID, Version: 1, Update: TaskUpdate{Title: &title}},
+		{Op: TaskOperationDelete, TaskID: taskID, Version: 1},
+	}, true)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.ErrorIs(t, results[0].Err, ErrOperationRolledBack)
+	assert.ErrorIs(t, results[1].Err, ErrOperationRolledBack)
+	assert.ErrorIs(t, results[2].Err, ErrVersionMismatch)
+
+	tasks, err := s.GetTasks(ctx, username, password)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "task1", tasks[0].Title)
+	assert.Equal(t, 1, tasks[0].Version)
+
+	results, err = s.BatchTasks(ctx, username, password, []TaskOperation{
+		{Op: TaskOperationCreate, Title: "task2"},
+		{Op: TaskOperationDelete, TaskID: taskID, Version: 2},
+		{Op: TaskOperationUpdate, TaskID: taskID, Version: 1, Update: TaskUpdate{Title: &title}},
+	}, false)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	require.NoError(t, results[0].Err)
+	assert.NotZero(t, results[0].TaskID)
+	assert.ErrorIs(t, results[1].Err, ErrVersionMismatch)
+	require.NoError(t, results[2].Err)
+
+	tasks, err = s.GetTasks(ctx, username, password)
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+	assert.Equal(t, title, tasks[0].Title)
+	assert.Equal(t, "task2", tasks[1].Title)
+}
+
+func testStorageConformanceIdempotency(t *testing.T, s conformanceStorage, scope string) {
+	ctx := context.Background()
+	expires := time.Now().Add(time.Hour)
+
+	defer func() {
+		require.NoError(t, s.DeleteIdempotencyKey(ctx, scope, "key1"))
+	}()
+
+	_, reserved, err := s.ReserveIdempotencyKey(ctx, scope, "key1", "fingerprint1", expires)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+
+	stored, reserved, err := s.ReserveIdempotencyKey(ctx, scope, "key1", "fingerprint2", expires)
+	require.NoError(t, err)
+	assert.False(t, reserved)
+	assert.Equal(t, IdempotentResponse{Fingerprint: "fingerprint1"}, stored)
+
+	response := IdempotentResponse{Status: 201, ContentType: "application/json", Body: []byte(`{"taskId":1}`)}
+	require.NoError(t, s.SaveIdempotentResponse(ctx, scope, "key1", response))
+
+	stored, reserved, err = s.ReserveIdempotencyKey(ctx, scope, "key1", "fingerprint1", expires)
+	require.NoError(t, err)
+	assert.False(t, reserved)
+
+	response.Fingerprint = "fingerprint1"
+	assert.Equal(t, response, stored)
+
+	require.NoError(t, s.DeleteIdempotencyKey(ctx, scope, "key1"))
+
+	_, reserved, err = s.ReserveIdempotencyKey(ctx, scope, "key1", "fingerprint2", expires)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+}
+
+// testStorageConformanceWebhooks the events are unique to the user, other runs may share the database.
+func testStorageConformanceWebhooks(t *testing.T, s conformanceStorage, userID int, username string) {
+	ctx := context.Background()
+
+	webhookID, err := s.CreateWebhook(ctx, userID, Webhook{
+		URL:    "http://127.0.0.1/user",
+		Secret: "secret",
+		Events: []string{EventTaskCreated, EventTaskDeleted},
+	})
+	require.NoError(t, err)
+
+	manageWebhookID, err := s.CreateWebhook(ctx, 0, Webhook{
+		URL:    "http://127.0.0.1/manage",
+		Secret: "manage",
+		Events: []string{EventTaskCreated},
+	})
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, s.DeleteWebhook(ctx, 0, manageWebhookID))
+	}()
+
+	webhooks, err := s.GetWebhooks(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, webhooks, 1)
+	assert.Equal(t, webhookID, webhooks[0].ID)
+	assert.Equal(t, []string{EventTaskCreated, EventTaskDeleted}, webhooks[0].Events)
+	assert.True(t, webhooks[0].Active)
+	assert.Empty(t, webhooks[0].Secret)
+
+	assert.ErrorIs(t, s.DeleteWebhook(ctx, 0, webhookID), ErrWebhookNotFound)
+
+	event := Event{ID: username + "_created", Type: EventTaskCreated, UserID: userID, TaskID: 1}
+
+	enqueued, err := s.EnqueueWebhookEvent(ctx, event)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), enqueued)
+
+	enqueued, err = s.EnqueueWebhookEvent(ctx, Event{ID: username + "_updated", Type: EventTaskUpdated, UserID: userID})
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), enqueued)
+
+	claimed := func() map[int]WebhookDelivery {
+		deliveries, err := s.ClaimWebhookDeliveries(ctx, 100, time.Minute)
+		require.NoError(t, err)
+
+		byWebhook := make(map[int]WebhookDelivery)
+
+		for _, delivery := range deliveries {
+			if delivery.EventID == event.ID {
+				byWebhook[delivery.WebhookID] = delivery
+			}
+		}
+
+		return byWebhook
+	}
+
+	deliveries := claimed()
+	require.Len(t, deliveries, 2)
+
+	delivery := deliveries[webhookID]
+	assert.Equal(t, "http://127.0.0.1/user", delivery.URL)
+	assert.Equal(t, "secret", delivery.Secret)
+	assert.Equal(t, EventTaskCreated, delivery.Event)
+
+	var payload Event
+
+	require.NoError(t, json.Unmarshal(delivery.Payload, &payload))
+	assert.Equal(t, event.ID, payload.ID)
+	assert.Equal(t, event.TaskID, payload.TaskID)
+
+	// Leased.
+	assert.Empty(t, claimed())
+
+	require.NoError(t, s.SaveWebhookAttempt(ctx, WebhookAttempt{
+		DeliveryID:     deliveries[manageWebhookID].ID,
+		ClaimedUntil:   deliveries[manageWebhookID].NextAttempt,
+		Succeeded:      true,
+		ResponseStatus: http.StatusOK,
+	}))
+
+	require.NoError(t, s.SaveWebhookAttempt(ctx, WebhookAttempt{
+		DeliveryID:     delivery.ID,
+		ClaimedUntil:   delivery.NextAttempt,
+		ResponseStatus: http.StatusInternalServerError,
+		Error:          "status 500",
+		RetryAfter:     time.Minute,
+		DisableAfter:   1,
+	}))
+
+	webhooks, err = s.GetWebhooks(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, webhooks, 1)
+	assert.False(t, webhooks[0].Active)
+	assert.Equal(t, 1, webhooks[0].Failures)
+
+	log, err := s.GetWebhookDeliveries(ctx, userID, webhookID, 10)
+	require.NoError(t, err)
+	require.Len(t, log, 1)
+	assert.Equal(t, WebhookDeliveryFailed, log[0].Status)
+	assert.Equal(t, 1, log[0].Attempts)
+	assert.Equal(t, http.StatusInternalServerError, log[0].ResponseStatus)
+	assert.Equal(t, webhookDisabledError, log[0].Error)
+
+	// The owner is notified, the manager is not.
+	notifications, err := s.GetNotifications(ctx, userID, NotificationQuery{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, notifications, 1)
+	assert.Equal(t, NotificationWebhookDisabled, notifications[0].Type)
+	assert.Contains(t, notifications[0].Title, "http://127.0.0.1/user")
+
+	log, err = s.GetWebhookDeliveries(ctx, 0, manageWebhookID, 10)
+	require.NoError(t, err)
+	require.NotEmpty(t, log)
+	assert.Equal(t, WebhookDeliverySucceeded, log[0].Status)
+
+	_, err = s.GetWebhookDeliveries(ctx, userID, manageWebhookID, 10)
+	assert.ErrorIs(t, err, ErrWebhookNotFound)
+
+	// Only the manager gets the events while the webhook is disabled.
+	enqueued, err = s.EnqueueWebhookEvent(ctx, Event{ID: username + "_disabled", Type: EventTaskCreated, UserID: userID})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), enqueued)
+
+	require.NoError(t, s.EnableWebhook(ctx, userID, webhookID))
+
+	webhooks, err = s.GetWebhooks(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, webhooks, 1)
+	assert.True(t, webhooks[0].Active)
+	assert.Equal(t, 0, webhooks[0].Failures)
+
+	testStorageConformanceWebhookLease(t, s, userID, webhookID, username)
+
+	require.NoError(t, s.DeleteWebhook(ctx, userID, webhookID))
+	assert.ErrorIs(t, s.DeleteWebhook(ctx, userID, webhookID), ErrWebhookNotFound)
+}
+
+// testStorageConformanceWebhookLease the attempt of the worker whose lease expired is not saved over the attempt
+// of the worker that claimed the delivery again.
+func testStorageConformanceWebhookLease(t *testing.T, s conformanceStorage, userID, webhookID int, username string) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	_, err := s.EnqueueWebhookEvent(ctx, Event{ID: username + "_lease", Type: EventTaskCreated, UserID: userID})
+	require.NoError(t, err)
+
+	claim := func(lease time.Duration) WebhookDelivery {
+		deliveries, err := s.ClaimWebhookDeliveries(ctx, 100, lease)
+		require.NoError(t, err)
+
+		for _, delivery := range deliveries {
+			if delivery.WebhookID == webhookID && delivery.EventID == username+"_lease" {
+				return delivery
+			}
+		}
+
+		require.Fail(t, "the delivery is not claimed")
+
+		return WebhookDelivery{}
+	}
+
+	expired := claim(time.Millisecond)
+
+	time.Sleep(10 * time.Millisecond)
+
+	delivery := claim(time.Minute)
+
+	require.NoError(t, s.SaveWebhookAttempt(ctx, WebhookAttempt{
+		DeliveryID:   expired.ID,
+		ClaimedUntil: expired.NextAttempt,
+		Succeeded:    true,
+	}))
+
+	log, err := s.GetWebhookDeliveries(ctx, userID, webhookID, 1)
+	require.NoError(t, err)
+	require.Len(t, log, 1)
+	assert.Equal(t, WebhookDeliveryPending, log[0].Status)
+	assert.Equal(t, 0, log[0].Attempts)
+
+	require.NoError(t, s.SaveWebhookAttempt(ctx, WebhookAttempt{
+		DeliveryID:   delivery.ID,
+		ClaimedUntil: delivery.NextAttempt,
+		Succeeded:    true,
+	}))
+
+	log, err = s.GetWebhookDeliveries(ctx, userID, webhookID, 1)
+	require.NoError(t, err)
+	require.Len(t, log, 1)
+	assert.Equal(t, WebhookDeliverySucceeded, log[0].Status)
+	assert.Equal(t, 1, log[0].Attempts)
+}
+
+func testStorageConformanceEvents(t *testing.T, s conformanceStorage, userID int) {
+	ctx := context.Background()
+
+	last, err := s.GetLastEventSeq(ctx, userID)
+	require.NoError(t, err)
+
+	task := Task{ID: 3, Title: "event"}
+
+	var seqs []int64
+
+	for _, event := range []Event{
+		{ID: "created", Type: EventTaskCreated, UserID: userID, TaskID: task.ID, Task: &task},
+		{ID: "deleted", Type: EventTaskDeleted, UserID: userID, TaskID: task.ID},
+		{ID: "completed", Type: EventTaskCompleted, UserID: userID, TaskID: task.ID, Task: &task},
+	} {
+		seq, err := s.SaveEvent(ctx, userID, event)
+		require.NoError(t, err)
+		assert.Greater(t, seq, last)
+
+		seqs = append(seqs, seq)
+		last = seq
+	}
+
+	_, err = s.SaveEvent(ctx, userID+1000, Event{ID: "unknown", Type: EventTaskCreated, UserID: userID})
+	assert.Error(t, err)
+
+	last, err = s.GetLastEventSeq(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, seqs[2], last)
+
+	events, err := s.GetEvents(ctx, userID, seqs[0]-1, 2)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, seqs[0], events[0].Seq)
+	assert.Equal(t, "created", events[0].ID)
+	require.NotNil(t, events[0].Task)
+	assert.Equal(t, task.Title, events[0].Task.Title)
+	assert.Equal(t, "deleted", events[1].ID)
+	assert.Nil(t, events[1].Task)
+
+	events, err = s.GetEvents(ctx, userID, seqs[1], 100)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, EventTaskCompleted, events[0].Type)
+
+	events, err = s.GetEvents(ctx, userID+1000, 0, 100)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	testStorageConformanceEventsConcurrent(t, s, userID)
+}
+
+// testStorageConformanceEventsConcurrent the reader following the log while the events are saved concurrently
+// gets all of them.
+func testStorageConformanceEventsConcurrent(t *testing.T, s conformanceStorage, userID int) {
+	t.Helper()
+
+	const (
+		writers          = 4
+		eventsPerWriter  = 10
+		concurrentPrefix = "concurrent_"
+	)
+
+	ctx := context.Background()
+
+	after, err := s.GetLastEventSeq(ctx, userID)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+
+		go func(w int) {
+			defer wg.Done()
+
+			for i := 0; i < eventsPerWriter; i++ {
+				event := Event{ID: fmt.Sprintf("%s%d_%d", concurrentPrefix, w, i), Type: EventTaskUpdated, UserID: userID}
+
+				_, err := s.SaveEvent(ctx, userID, event)
+				assert.NoError(t, err)
+			}
+		}(w)
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	seen := make(map[string]bool)
+
+	read := func() {
+		events, err := s.GetEvents(ctx, userID, after, 100)
+		require.NoError(t, err)
+
+		for _, event := range events {
+			seen[event.ID] = true
+			after = event.Seq
+		}
+	}
+
+	for stop := false; !stop; {
+		select {
+		case <-done:
+			stop = true
+		default:
+		}
+
+		// The last read is after all events are saved.
+		read()
+	}
+
+	assert.Len(t, seen, writers*eventsPerWriter)
+}
+
+func testStorageConformanceCalendar(t *testing.T, s conformanceStorage, userID int, username, password string) {
+	ctx := context.Background()
+
+	tokenHash := fmt.Sprintf("hash_%d", time.Now().UnixNano())
+
+	_, err := s.GetCalendarTasks(ctx, tokenHash)
+	assert.ErrorIs(t, err, ErrCalendarTokenNotFound)
+
+	require.NoError(t, s.SetCalendarToken(ctx, userID, "old_"+tokenHash))
+	require.NoError(t, s.SetCalendarToken(ctx, userID, tokenHash))
+
+	_, err = s.GetCalendarTasks(ctx, "old_"+tokenHash)
+	assert.ErrorIs(t, err, ErrCalendarTokenNotFound)
+
+	withoutDueID, err := s.CreateTask(ctx, username, password, "without due")
+	require.NoError(t, err)
+
+	taskID, err := s.CreateTask(ctx, username, password, "weekly")
+	require.NoError(t, err)
+
+	earlierID, err := s.CreateTask(ctx, username, password, "earlier")
+	require.NoError(t, err)
+
+	deletedID, err := s.CreateTask(ctx, username, password, "deleted")
+	require.NoError(t, err)
+
+	defer func() {
+		for _, id := range []int{withoutDueID, taskID, earlierID, deletedID} {
+			require.NoError(t, s.DeleteTaskPermanently(ctx, username, password, id, 0))
+		}
+	}()
+
+	due := time.Date(2023, 5, 2, 9, 30, 0, 0, time.UTC)
+	earlier := due.Add(-time.Hour)
+	recurrence := "FREQ=WEEKLY;BYDAY=TU"
+
+	require.NoError(t, s.UpdateTask(ctx, username, password, taskID, 0, TaskUpdate{Due: &due, Recurrence: &recurrence}))
+	require.NoError(t, s.UpdateTask(ctx, username, password, earlierID, 0, TaskUpdate{Due: &earlier}))
+	require.NoError(t, s.UpdateTask(ctx, username, password, deletedID, 0, TaskUpdate{Due: &due}))
+	require.NoError(t, s.DeleteTask(ctx, username, password, deletedID, 0))
+
+	task, err := s.GetTask(ctx, username, password, taskID)
+	require.NoError(t, err)
+	require.NotNil(t, task.Due)
+	assert.True(t, due.Equal(*task.Due))
+	assert.Equal(t, recurrence, task.Recurrence)
+
+	history, err := s.GetTaskHistory(ctx, username, password, taskID)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, taskFieldDue, history[0].Field)
+	assert.Equal(t, "2023-05-02T09:30:00Z", history[0].New)
+	assert.Equal(t, taskFieldRecurrence, history[1].Field)
+
+	tasks, err := s.GetCalendarTasks(ctx, tokenHash)
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+	assert.Equal(t, earlierID, tasks[0].ID)
+	assert.Equal(t, taskID, tasks[1].ID)
+	assert.Equal(t, recurrence, tasks[1].Recurrence)
+
+	require.NoError(t, s.RevertTask(ctx, username, password, taskID, 0))
+
+	task, err = s.GetTask(ctx, username, password, taskID)
+	require.NoError(t, err)
+	assert.Nil(t, task.Due)
+	assert.Empty(t, task.Recurrence)
+
+	require.NoError(t, s.DeleteCalendarToken(ctx, userID))
+	assert.ErrorIs(t, s.DeleteCalendarToken(ctx, userID), ErrCalendarTokenNotFound)
+
+	_, err = s.GetCalendarTasks(ctx, tokenHash)
+	assert.ErrorIs(t, err, ErrCalendarTokenNotFound)
+}
+
+func testStorageConformanceReminders(t *testing.T, s conformanceStorage, userID int, username, password string) {
+	ctx := context.Background()
+
+	taskID, err := s.CreateTask(ctx, username, password, "call the bank")
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, s.DeleteTaskPermanently(ctx, username, password, taskID, 0))
+
+		_, err := s.GetReminders(ctx, userID, taskID)
+		assert.ErrorIs(t, err, ErrTaskNotFound)
+	}()
+
+	_, err = s.CreateReminder(ctx, userID+1000000, taskID, Reminder{Channel: ReminderChannelInApp})
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+
+	due := time.Date(2023, 5, 2, 9, 30, 0, 0, time.UTC)
+	remindAt := due.Add(-2 * time.Hour)
+	beforeMinutes := 30
+
+	atID, err := s.CreateReminder(ctx, userID, taskID, Reminder{
+		RemindAt: &remindAt,
+		Channel:  ReminderChannelEmail,
+		Email:    "user@example.com",
+	})
+	require.NoError(t, err)
+
+	beforeID, err := s.CreateReminder(ctx, userID, taskID, Reminder{
+		BeforeMinutes: &beforeMinutes,
+		Channel:       ReminderChannelInApp,
+	})
+	require.NoError(t, err)
+
+	reminders, err := s.GetReminders(ctx, userID, taskID)
+	require.NoError(t, err)
+	require.Len(t, reminders, 2)
+	assert.Equal(t, atID, reminders[0].ID)
+	assert.Equal(t, "user@example.com", reminders[0].Email)
+	assert.Equal(t, ReminderPending, reminders[0].Status)
+	require.NotNil(t, reminders[0].FireAt)
+	assert.True(t, remindAt.Equal(*reminders[0].FireAt))
+	require.NotNil(t, reminders[1].BeforeMinutes)
+	assert.Equal(t, 30, *reminders[1].BeforeMinutes)
+	assert.Nil(t, reminders[1].FireAt, "waits for the due date")
+
+	// own returns the claimed reminders of the task, the other users may have theirs in the shared database.
+	claim := func(now time.Time) []Reminder {
+		claimed, err := s.ClaimReminders(ctx, now, 100, time.Hour)
+		require.NoError(t, err)
+
+		var own []Reminder
+
+		for _, reminder := range claimed {
+			if reminder.TaskID == taskID {
+				own = append(own, reminder)
+			}
+		}
+
+		return own
+	}
+
+	claimed := claim(due.Add(-time.Hour))
+	require.Len(t, claimed, 1)
+	assert.Equal(t, atID, claimed[0].ID)
+	assert.Equal(t, userID, claimed[0].UserID)
+	require.NotNil(t, claimed[0].Task)
+	assert.Equal(t, "call the bank", claimed[0].Task.Title)
+	assert.True(t, due.Equal(claimed[0].ClaimedUntil))
+
+	atClaimedUntil := claimed[0].ClaimedUntil
+
+	assert.Empty(t, claim(due.Add(-time.Hour)), "leased")
+
+	require.NoError(t, s.UpdateTask(ctx, username, password, taskID, 0, TaskUpdate{Due: &due}))
+
+	reminders, err = s.GetReminders(ctx, userID, taskID)
+	require.NoError(t, err)
+	require.NotNil(t, reminders[1].FireAt)
+	assert.True(t, due.Add(-30*time.Minute).Equal(*reminders[1].FireAt))
+
+	assert.Empty(t, claim(due.Add(-31*time.Minute)), "the lease has not expired, the offset is not reached")
+
+	require.NoError(t, s.SaveReminderAttempt(ctx, ReminderAttempt{
+		ReminderID:   atID,
+		Error:        "connection refused",
+		Time:         due.Add(-31 * time.Minute),
+		RetryAfter:   5 * time.Minute,
+		ClaimedUntil: atClaimedUntil,
+	}))
+
+	claimed = claim(due.Add(-29 * time.Minute))
+	require.Len(t, claimed, 1)
+	assert.Equal(t, beforeID, claimed[0].ID)
+	require.NotNil(t, claimed[0].Task.Due)
+	assert.True(t, due.Equal(*claimed[0].Task.Due))
+
+	sent := due.Add(-29 * time.Minute)
+
+	require.NoError(t, s.SaveReminderAttempt(ctx, ReminderAttempt{
+		ReminderID:   beforeID,
+		Succeeded:    true,
+		Time:         sent,
+		ClaimedUntil: claimed[0].ClaimedUntil,
+	}))
+
+	claimed = claim(due.Add(-25 * time.Minute))
+	require.Len(t, claimed, 1)
+	assert.Equal(t, atID, claimed[0].ID)
+	assert.Equal(t, 1, claimed[0].Attempts)
+
+	// The attempt of the expired claim is not saved over the new one.
+	require.NoError(t, s.SaveReminderAttempt(ctx, ReminderAttempt{
+		ReminderID:   atID,
+		Succeeded:    true,
+		Time:         due.Add(-25 * time.Minute),
+		ClaimedUntil: atClaimedUntil,
+	}))
+
+	require.NoError(t, s.SaveReminderAttempt(ctx, ReminderAttempt{
+		ReminderID:   atID,
+		Error:        "connection refused",
+		Time:         due.Add(-25 * time.Minute),
+		ClaimedUntil: claimed[0].ClaimedUntil,
+	}))
+
+	reminders, err = s.GetReminders(ctx, userID, taskID)
+	require.NoError(t, err)
+	assert.Equal(t, ReminderFailed, reminders[0].Status)
+	assert.Equal(t, 2, reminders[0].Attempts)
+	assert.Equal(t, "connection refused", reminders[0].Error)
+	assert.Equal(t, ReminderSent, reminders[1].Status)
+	require.NotNil(t, reminders[1].Sent)
+	assert.True(t, sent.Equal(*reminders[1].Sent))
+
+	assert.Empty(t, claim(due.Add(time.Hour)), "sent and failed")
+
+	laterID, err := s.CreateReminder(ctx, userID, taskID, Reminder{
+		BeforeMinutes: &beforeMinutes,
+		Channel:       ReminderChannelWebhook,
+	})
+	require.NoError(t, err)
+
+	completed := true
+
+	require.NoError(t, s.UpdateTask(ctx, username, password, taskID, 0, TaskUpdate{Status: &completed}))
+	assert.Empty(t, claim(due.Add(time.Hour)), "the task is completed")
+
+	assert.ErrorIs(t, s.DeleteReminder(ctx, userID, taskID+1000000, laterID), ErrReminderNotFound)
+	require.NoError(t, s.DeleteReminder(ctx, userID, taskID, laterID))
+	assert.ErrorIs(t, s.DeleteReminder(ctx, userID, taskID, laterID), ErrReminderNotFound)
+}
+
+// testStorageConformanceNotifications creates the user with the username, otherUserID does not see the notifications.
+func testStorageConformanceNotifications(
+	t *testing.T, s conformanceStorage, otherUserID int, username, password string,
+) {
+	ctx := context.Background()
+
+	userID, err := s.CreateNewUser(ctx, username, password)
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, s.DeleteUser(ctx, userID))
+	}()
+
+	taskID, err := s.CreateTask(ctx, username, password, "call the bank")
+	require.NoError(t, err)
+
+	create := func(notification Notification) int {
+		t.Helper()
+
+		notification.UserID = userID
+
+		notificationID, err := s.CreateNotification(ctx, notification)
+		require.NoError(t, err)
+
+		return notificationID
+	}
+
+	reminderID1 := create(Notification{Type: NotificationTaskReminder, Title: "Reminder: call the bank", TaskID: taskID})
+	webhookID := create(Notification{Type: NotificationWebhookDisabled, Title: "Webhook disabled"})
+	reminderID2 := create(Notification{Type: NotificationTaskReminder, Title: "Reminder: call the bank", TaskID: taskID})
+
+	notifications, err := s.GetNotifications(ctx, userID, NotificationQuery{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, notifications, 3)
+	assert.Equal(t, []int{reminderID2, webhookID, reminderID1},
+		[]int{notifications[0].ID, notifications[1].ID, notifications[2].ID}, "the newest first")
+	assert.Equal(t, NotificationTaskReminder, notifications[2].Type)
+	assert.Equal(t, "Reminder: call the bank", notifications[2].Title)
+	assert.Equal(t, taskID, notifications[2].TaskID)
+	assert.Equal(t, 0, notifications[1].TaskID)
+	assert.Nil(t, notifications[2].Read)
+	assert.WithinDuration(t, time.Now(), notifications[2].Created, time.Minute)
+
+	// The pages.
+	notifications, err = s.GetNotifications(ctx, userID, NotificationQuery{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, notifications, 2)
+
+	notifications, err = s.GetNotifications(ctx, userID, NotificationQuery{BeforeID: notifications[1].ID, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, notifications, 1)
+	assert.Equal(t, reminderID1, notifications[0].ID)
+
+	unread, err := s.GetUnreadNotifications(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{NotificationTaskReminder: 2, NotificationWebhookDisabled: 1}, unread)
+
+	// Read.
+	assert.ErrorIs(t, s.ReadNotification(ctx, otherUserID, reminderID1), ErrNotificationNotFound)
+	require.NoError(t, s.ReadNotification(ctx, userID, reminderID1))
+
+	notifications, err = s.GetNotifications(ctx, userID, NotificationQuery{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, notifications, 3)
+	require.NotNil(t, notifications[2].Read)
+
+	read := *notifications[2].Read
+
+	require.NoError(t, s.ReadNotification(ctx, userID, reminderID1))
+
+	notifications, err = s.GetNotifications(ctx, userID, NotificationQuery{Unread: true, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, notifications, 2)
+	assert.Equal(t, reminderID2, notifications[0].ID)
+	assert.Equal(t, webhookID, notifications[1].ID)
+
+	notifications, err = s.GetNotifications(ctx, userID, NotificationQuery{Limit: 10})
+	require.NoError(t, err)
+	require.NotNil(t, notifications[2].Read)
+	assert.True(t, read.Equal(*notifications[2].Read), "the first reading is kept")
+
+	quantity, err := s.ReadNotifications(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), quantity)
+
+	quantity, err = s.ReadNotifications(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), quantity)
+
+	unread, err = s.GetUnreadNotifications(ctx, userID)
+	require.NoError(t, err)
+	assert.Empty(t, unread)
+
+	// The preferences.
+	preferences, err := s.GetNotificationPreferences(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, []NotificationPreference{
+		{Type: NotificationTaskReminder, Enabled: true},
+		{Type: NotificationWebhookDisabled, Enabled: true},
+		{Type: NotificationProjectShared, Enabled: true},
+		{Type: NotificationTaskAssigned, Enabled: true},
+		{Type: NotificationTaskCommented, Enabled: true},
+	}, preferences)
+
+	require.NoError(t, s.SetNotificationPreferences(ctx, userID, []NotificationPreference{
+		{Type: NotificationTaskReminder, Enabled: false},
+	}))
+
+	preferences, err = s.GetNotificationPreferences(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, []NotificationPreference{
+		{Type: NotificationTaskReminder, Enabled: false},
+		{Type: NotificationWebhookDisabled, Enabled: true},
+		{Type: NotificationProjectShared, Enabled: true},
+		{Type: NotificationTaskAssigned, Enabled: true},
+		{Type: NotificationTaskCommented, Enabled: true},
+	}, preferences)
+
+	assert.Equal(t, 0, create(Notification{Type: NotificationTaskReminder, Title: "Reminder: call the bank"}))
+	assert.NotEqual(t, 0, create(Notification{Type: NotificationWebhookDisabled, Title: "Webhook disabled"}))
+
+	require.NoError(t, s.SetNotificationPreferences(ctx, userID, []NotificationPreference{
+		{Type: NotificationTaskReminder, Enabled: true},
+	}))
+
+	assert.NotEqual(t, 0, create(Notification{Type: NotificationTaskReminder, Title: "Reminder: call the bank"}))
+
+	preferences, err = s.GetNotificationPreferences(ctx, otherUserID)
+	require.NoError(t, err)
+	assert.True(t, preferences[0].Enabled)
+
+	// The notifications are kept without the deleted task.
+	require.NoError(t, s.DeleteTaskPermanently(ctx, username, password, taskID, 0))
+
+	notifications, err = s.GetNotifications(ctx, userID, NotificationQuery{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, notifications, 5)
+	assert.Equal(t, reminderID1, notifications[4].ID)
+	assert.Equal(t, 0, notifications[4].TaskID)
+}
+
+func testStorageConformanceExport(t *testing.T, s conformanceStorage, username, password string) {
+	ctx := context.Background()
+
+	userID, err := s.CreateNewUser(ctx, username, password)
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, s.DeleteUser(ctx, userID))
+	}()
+
+	created := make([]int, 0, exportTasksPageSize+1)
+
+	for i := 0; i <= exportTasksPageSize; i++ {
+		taskID, err := s.CreateTask(ctx, username, password, fmt.Sprintf("task%d", i))
+		require.NoError(t, err)
+
+		created = append(created, taskID)
+	}
+
+	var exported []int
+
+	// The storage is available while fn writes: the single connection of SQLite is not held.
+	require.NoError(t, s.ExportTasks(ctx, username, password, func(task ExportedTask) error {
+		stored, err := s.GetTask(ctx, username, password, task.ID)
+		if err != nil {
+			return err
+		}
+
+		assert.Equal(t, stored.Title, task.Title)
+
+		exported = append(exported, task.ID)
+
+		return nil
+	}))
+	assert.Equal(t, created, exported)
+}
+
+func testStorageConformanceProjects(t *testing.T, s conformanceStorage, userID int, username, password string) {
+	ctx := context.Background()
+
+	memberName := username + "_member"
+
+	memberID, err := s.CreateNewUser(ctx, memberName, password)
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, s.DeleteUser(ctx, memberID))
+	}()
+
+	projectID, err := s.CreateProject(ctx, userID, "board")
+	require.NoError(t, err)
+
+	projects, err := s.GetProjects(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, projects, 1)
+	assert.Equal(t, projectID, projects[0].ID)
+	assert.Equal(t, "board", projects[0].Name)
+	assert.Equal(t, userID, projects[0].OwnerID)
+
+	projects, err = s.GetProjects(ctx, memberID)
+	require.NoError(t, err)
+	assert.Empty(t, projects)
+
+	member, err := s.IsProjectMember(ctx, memberID, projectID)
+	require.NoError(t, err)
+	assert.False(t, member)
+
+	_, err = s.GetProjectMembers(ctx, memberID, projectID)
+	assert.ErrorIs(t, err, ErrProjectNotFound)
+
+	// Only the owner adds the members.
+	_, _, err = s.AddProjectMember(ctx, memberID, projectID, memberName)
+	assert.ErrorIs(t, err, ErrProjectNotFound)
+
+	_, _, err = s.AddProjectMember(ctx, userID, projectID, memberName+"_unknown")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	id, added, err := s.AddProjectMember(ctx, userID, projectID, memberName)
+	require.NoError(t, err)
+	assert.True(t, added)
+	assert.Equal(t, memberID, id)
+
+	id, added, err = s.AddProjectMember(ctx, userID, projectID, memberName)
+	require.NoError(t, err)
+	assert.False(t, added)
+	assert.Equal(t, memberID, id)
+
+	member, err = s.IsProjectMember(ctx, memberID, projectID)
+	require.NoError(t, err)
+	assert.True(t, member)
+
+	members, err := s.GetProjectMembers(ctx, memberID, projectID)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	assert.Equal(t, userID, members[0].UserID)
+	assert.Equal(t, username, members[0].Username)
+	assert.Equal(t, memberName, members[1].Username)
+
+	memberIDs, err := s.GetProjectMemberIDs(ctx, projectID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{userID, memberID}, memberIDs)
+
+	taskID, err := s.CreateTask(ctx, username, password, "project task")
+	require.NoError(t, err)
+
+	memberTaskID, err := s.CreateTask(ctx, memberName, password, "member task")
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, s.SetTaskProject(ctx, username, password, memberTaskID, projectID), ErrTaskNotFound)
+	assert.ErrorIs(t, s.SetTaskProject(ctx, username, password, taskID, projectID+1000), ErrProjectNotFound)
+
+	before, err := s.GetTask(ctx, username, password, taskID)
+	require.NoError(t, err)
+
+	require.NoError(t, s.SetTaskProject(ctx, username, password, taskID, projectID))
+	require.NoError(t, s.SetTaskProject(ctx, memberName, password, memberTaskID, projectID))
+
+	task, err := s.GetTask(ctx, username, password, taskID)
+	require.NoError(t, err)
+	assert.Equal(t, projectID, task.ProjectID)
+	assert.Greater(t, task.Version, before.Version)
+
+	userIDs, err := s.GetTaskUserIDs(ctx, memberTaskID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []int{userID, memberID}, userIDs)
+
+	tasks, err := s.GetProjectTasks(ctx, memberID, projectID)
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+	assert.Equal(t, taskID, tasks[0].ID)
+	assert.Equal(t, memberTaskID, tasks[1].ID)
+	assert.Equal(t, projectID, tasks[1].ProjectID)
+
+	// The trash is included.
+	require.NoError(t, s.DeleteTask(ctx, username, password, taskID, 0))
+
+	taskProjects, err := s.GetTaskProjects(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, map[int]int{taskID: projectID}, taskProjects)
+
+	tasks, err = s.GetProjectTasks(ctx, memberID, projectID)
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+
+	_, err = s.GetProjectTasks(ctx, memberID, projectID+1000)
+	assert.ErrorIs(t, err, ErrProjectNotFound)
+
+	// The owner is not removed, the member leaves with its tasks.
+	assert.ErrorIs(t, s.RemoveProjectMember(ctx, memberID, projectID, userID), ErrUserNotFound)
+	assert.ErrorIs(t, s.RemoveProjectMember(ctx, userID, projectID+1000, memberID), ErrProjectNotFound)
+	require.NoError(t, s.RemoveProjectMember(ctx, memberID, projectID, memberID))
+	assert.ErrorIs(t, s.RemoveProjectMember(ctx, userID, projectID, memberID), ErrUserNotFound)
+
+	memberTask, err := s.GetTask(ctx, memberName, password, memberTaskID)
+	require.NoError(t, err)
+	assert.Zero(t, memberTask.ProjectID)
+
+	userIDs, err = s.GetTaskUserIDs(ctx, memberTaskID)
+	require.NoError(t, err)
+	assert.Equal(t, []int{memberID}, userIDs)
+
+	_, err = s.GetProjectTasks(ctx, memberID, projectID)
+	assert.ErrorIs(t, err, ErrProjectNotFound)
+
+	assert.ErrorIs(t, s.DeleteProject(ctx, memberID, projectID), ErrProjectNotFound)
+	require.NoError(t, s.DeleteProject(ctx, userID, projectID))
+
+	taskProjects, err = s.GetTaskProjects(ctx, userID)
+	require.NoError(t, err)
+	assert.Empty(t, taskProjects)
+
+	projects, err = s.GetProjects(ctx, userID)
+	require.NoError(t, err)
+	assert.Empty(t, projects)
+}
+
+// testStorageConformanceRelations the tags, the comments and the subtasks.
+func testStorageConformanceRelations(t *testing.T, s conformanceStorage, userID int, username, password string) {
+	ctx := context.Background()
+
+	memberName := username + "_relations"
+
+	memberID, err := s.CreateNewUser(ctx, memberName, password)
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, s.DeleteUser(ctx, memberID))
+	}()
+
+	parentID, err := s.CreateTask(ctx, username, password, "relations parent")
+	require.NoError(t, err)
+
+	subtaskID, err := s.CreateTask(ctx, username, password, "relations subtask")
+	require.NoError(t, err)
+
+	defer func() {
+		for _, taskID := range []int{parentID, subtaskID} {
+			require.NoError(t, s.DeleteTaskPermanently(ctx, username, password, taskID, 0))
+		}
+	}()
+
+	// The tags are replaced, the duplicates are ignored.
+	require.NoError(t, s.SetTaskTags(ctx, userID, parentID, []string{"work", "home", "work"}))
+	require.NoError(t, s.SetTaskTags(ctx, userID, subtaskID, []string{"home"}))
+	require.NoError(t, s.SetTaskTags(ctx, userID, subtaskID, []string{"urgent"}))
+	assert.ErrorIs(t, s.SetTaskTags(ctx, memberID, parentID, []string{"other"}), ErrTaskNotFound)
+
+	tags, err := s.GetTasksTags(ctx, userID, []int{parentID, subtaskID, parentID + 1000})
+	require.NoError(t, err)
+	assert.Equal(t, map[int][]string{parentID: {"home", "work"}, subtaskID: {"urgent"}}, tags)
+
+	tags, err = s.GetTasksTags(ctx, memberID, []int{parentID})
+	require.NoError(t, err)
+	assert.Empty(t, tags)
+
+	// The members of the project of the task see it and comment on it.
+	_, err = s.CreateTaskComment(ctx, memberID, parentID, "hidden")
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+
+	projectID, err := s.CreateProject(ctx, userID, "relations")
+	require.NoError(t, err)
+
+	defer func() {
+		require.NoError(t, s.DeleteProject(ctx, userID, projectID))
+	}()
+
+	_, _, err = s.AddProjectMember(ctx, userID, projectID, memberName)
+	require.NoError(t, err)
+	require.NoError(t, s.SetTaskProject(ctx, username, password, parentID, projectID))
+
+	commentID, err := s.CreateTaskComment(ctx, userID, parentID, "first")
+	require.NoError(t, err)
+
+	memberCommentID, err := s.CreateTaskComment(ctx, memberID, parentID, "second")
+	require.NoError(t, err)
+
+	comments, err := s.GetTasksComments(ctx, memberID, []int{parentID, subtaskID})
+	require.NoError(t, err)
+	require.Len(t, comments, 1)
+	require.Len(t, comments[parentID], 2)
+	assert.Equal(t, commentID, comments[parentID][0].ID)
+	assert.Equal(t, username, comments[parentID][0].Username)
+	assert.Equal(t, "first", comments[parentID][0].Text)
+	assert.Equal(t, memberCommentID, comments[parentID][1].ID)
+	assert.Equal(t, memberID, comments[parentID][1].UserID)
+
+	tags, err = s.GetTasksTags(ctx, memberID, []int{parentID})
+	require.NoError(t, err)
+	assert.Equal(t, map[int][]string{parentID: {"home", "work"}}, tags)
+
+	// Only the author deletes the comment.
+	assert.ErrorIs(t, s.DeleteTaskComment(ctx, userID, memberCommentID), ErrCommentNotFound)
+	require.NoError(t, s.DeleteTaskComment(ctx, memberID, memberCommentID))
+	assert.ErrorIs(t, s.DeleteTaskComment(ctx, memberID, memberCommentID), ErrCommentNotFound)
+
+	// The subtasks are one level deep.
+	before, err := s.GetTask(ctx, username, password, subtaskID)
+	require.NoError(t, err)
+
+	require.NoError(t, s.SetTaskParent(ctx, username, password, subtaskID, parentID))
+
+	task, err := s.GetTask(ctx, username, password, subtaskID)
+	require.NoError(t, err)
+	assert.Equal(t, parentID, task.ParentID)
+	assert.Greater(t, task.Version, before.Version)
+
+	assert.ErrorIs(t, s.SetTaskParent(ctx, username, password, parentID, subtaskID), ErrTaskParentInvalid)
+	assert.ErrorIs(t, s.SetTaskParent(ctx, username, password, parentID, parentID), ErrTaskParentInvalid)
+	assert.ErrorIs(t, s.SetTaskParent(ctx, username, password, subtaskID, parentID+1000), ErrTaskNotFound)
+	assert.ErrorIs(t, s.SetTaskParent(ctx, memberName, password, subtaskID, 0), ErrTaskNotFound)
+
+	subtasks, err := s.GetSubtasks(ctx, userID, []int{parentID, subtaskID})
+	require.NoError(t, err)
+	require.Len(t, subtasks, 1)
+	require.Len(t, subtasks[parentID], 1)
+	assert.Equal(t, subtaskID, subtasks[parentID][0].ID)
+	assert.Equal(t, "relations subtask", subtasks[parentID][0].Title)
+
+	// The subtask is not in the project.
+	subtasks, err = s.GetSubtasks(ctx, memberID, []int{parentID})
+	require.NoError(t, err)
+	assert.Empty(t, subtasks)
+
+	require.NoError(t, s.SetTaskParent(ctx, username, password, subtaskID, 0))
+
+	subtasks, err = s.GetSubtasks(ctx, userID, []int{parentID})
+	require.NoError(t, err)
+	assert.Empty(t, subtasks)
+
+	// The subtasks of the deleted task are not subtasks anymore, its comments are deleted.
+	require.NoError(t, s.SetTaskParent(ctx, username, password, subtaskID, parentID))
+
+	otherID, err := s.CreateTask(ctx, username, password, "relations other")
+	require.NoError(t, err)
+
+	require.NoError(t, s.SetTaskParent(ctx, username, password, subtaskID, otherID))
+	_, err = s.CreateTaskComment(ctx, userID, otherID, "gone")
+	require.NoError(t, err)
+
+	require.NoError(t, s.DeleteTaskPermanently(ctx, username, password, otherID, 0))
+
+	task, err = s.GetTask(ctx, username, password, subtaskID)
+	require.NoError(t, err)
+	assert.Zero(t, task.ParentID)
+
+	comments, err = s.GetTasksComments(ctx, userID, []int{otherID})
+	require.NoError(t, err)
+	assert.Empty(t, comments)
+}
@@ -5,11 +5,8 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"strings"
 	"time"
 
-	"github.com/lib/pq"
-
 	"taskmanager/internal/db"
 )
 
@@ -20,15 +17,46 @@ type Task struct {
 	Created   time.Time `json:"created"`
 	Updated   time.Time `json:"updated"`
 	Completed time.Time `json:"completed"`
+	// Due nil - the task has no due date.
+	Due *time.Time `json:"due,omitempty"`
+	// Recurrence the RRULE of RFC 5545 without the prefix, for example FREQ=WEEKLY;BYDAY=MO, the task repeats from Due.
+	Recurrence string `json:"recurrence,omitempty"`
+	// ProjectID 0 - the task is not in a project.
+	ProjectID int `json:"projectId,omitempty"`
+	// ParentID 0 - the task is not a subtask.
+	ParentID int `json:"parentId,omitempty"`
+	Version  int `json:"-"`
+}
+
+// TaskQuery the page of the tasks sorted by id.
+type TaskQuery struct {
+	// AfterID 0 - from the first task.
+	AfterID int
+	// Completed nil - all tasks.
+	Completed *bool
+	Limit     int
+}
+
+// nullTimeRef nil for null.
+func nullTimeRef(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+
+	return &t.Time
 }
 
 func (p Postgres) CreateTask(ctx context.Context, username, password, title string) (int, error) {
 	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
 	defer cancel()
 
+	return createTask(ctx, p.conn(ctx), username, password, title)
+}
+
+func createTask(ctx context.Context, q querier, username, password, title string) (int, error) {
 	var taskID int
 
-	if err := p.Pool.QueryRowContext(ctx, `
+	if err := q.QueryRowContext(ctx, `
 		INSERT INTO
 			task(user_id, status, title, created, updated)
 		VALUES
@@ -43,18 +71,23 @@ func (p Postgres) CreateTask(ctx context.Context, username, password, title stri
 	).Scan(
 		&taskID,
 	); err != nil {
-		if db.IsUniqueConstraintError(err) {
-			return 0, fmt.Errorf("%s: %w: %s", username, ErrTaskAlreadyExists, err.Error())
-		}
+		return 0, createTaskError(username, err)
+	}
 
-		if db.IsNullValueError(err) {
-			return 0, fmt.Errorf("%s: %w: %s", username, ErrUserNotFound, err.Error())
-		}
+	return taskID, nil
+}
 
-		return 0, fmt.Errorf("query row: %w", err)
+// createTaskError user_id is null if the user is not found.
+func createTaskError(username string, err error) error {
+	if db.IsUniqueConstraintError(err) {
+		return fmt.Errorf("%s: %w: %s", username, ErrTaskAlreadyExists, err.Error())
 	}
 
-	return taskID, nil
+	if db.IsNullValueError(err) {
+		return fmt.Errorf("%s: %w: %s", username, ErrUserNotFound, err.Error())
+	}
+
+	return fmt.Errorf("query row: %w", err)
 }
 
 func (p Postgres) GetTask(ctx context.Context, username, password string, taskID int) (Task, error) {
@@ -62,13 +95,18 @@ func (p Postgres) GetTask(ctx context.Context, username, password string, taskID
 	defer cancel()
 
 	var (
-		task          Task
-		taskCompleted sql.NullTime
+		task           Task
+		taskCompleted  sql.NullTime
+		taskDue        sql.NullTime
+		taskRecurrence sql.NullString
+		taskProjectID  sql.NullInt64
+		taskParentID   sql.NullInt64
 	)
 
-	if err := p.Pool.QueryRowContext(ctx, `
+	if err := p.conn(ctx).QueryRowContext(ctx, `
 		SELECT
-		    t.status, t.title, t.created, t.updated, t.completed
+		    t.status, t.title, t.created, t.updated, t.completed, t.due, t.recurrence, t.project_id, t.parent_id,
+		    t.version
 		FROM
 		    task t 
 		JOIN
@@ -76,7 +114,8 @@ func (p Postgres) GetTask(ctx context.Context, username, password string, taskID
 		WHERE
 		    a.username = $1 AND
 		    a.password = $2 AND
-		    t.task_id = $3
+		    t.task_id = $3 AND
+		    t.deleted_at IS NULL
 	`,
 		username,
 		password,
@@ -87,6 +126,11 @@ func (p Postgres) GetTask(ctx context.Context, username, password string, taskID
 		&task.Created,
 		&task.Updated,
 		&taskCompleted,
+		&taskDue,
+		&taskRecurrence,
+		&taskProjectID,
+		&taskParentID,
+		&task.Version,
 	); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return Task{}, fmt.Errorf("taskId: %d: %w", taskID, ErrTaskNotFound)
@@ -96,6 +140,10 @@ func (p Postgres) GetTask(ctx context.Context, username, password string, taskID
 	}
 
 	task.Completed = taskCompleted.Time
+	task.Due = nullTimeRef(taskDue)
+	task.Recurrence = taskRecurrence.String
+	task.ProjectID = int(taskProjectID.Int64)
+	task.ParentID = int(taskParentID.Int64)
 
 	return task, nil
 }
@@ -104,16 +152,20 @@ func (p Postgres) GetTasks(ctx context.Context, username, password string) ([]Ta
 	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
 	defer cancel()
 
-	rows, err := p.Pool.QueryContext(ctx, `
+	rows, err := p.conn(ctx).QueryContext(ctx, `
 		SELECT
-		    t.task_id, t.status, t.title, t.created, t.updated, t.completed
+		    t.task_id, t.status, t.title, t.created, t.updated, t.completed, t.due, t.recurrence, t.project_id,
+		    t.parent_id, t.version
 		FROM
 		    task t 
 		JOIN
 		    auth a USING (user_id)
 		WHERE
 		    a.username = $1 AND
-		    a.password = $2
+		    a.password = $2 AND
+		    t.deleted_at IS NULL
+		ORDER BY
+		    t.task_id
 	`,
 		username,
 		password,
@@ -128,12 +180,96 @@ func (p Postgres) GetTasks(ctx context.Context, username, password string) ([]Ta
 		}
 	}()
 
+	return scanTasks(rows)
+}
+
+// GetTasksPage the tasks of the user sorted by id, without the trash.
+func (p Postgres) GetTasksPage(ctx context.Context, username, password string, query TaskQuery) ([]Task, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	rows, err := p.conn(ctx).QueryContext(ctx, `
+		SELECT
+		    t.task_id, t.status, t.title, t.created, t.updated, t.completed, t.due, t.recurrence, t.project_id,
+		    t.parent_id, t.version
+		FROM
+		    task t
+		JOIN
+		    auth a USING (user_id)
+		WHERE
+		    a.username = $1 AND
+		    a.password = $2 AND
+		    t.deleted_at IS NULL AND
+		    t.task_id > $3 AND
+		    (NOT $4 OR t.status = $5)
+		ORDER BY
+		    t.task_id
+		LIMIT $6
+	`,
+		username,
+		password,
+		query.AfterID,
+		query.Completed != nil,
+		query.Completed != nil && *query.Completed,
+		query.Limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			p.Logger.Errorf("get tasks page: %v", err)
+		}
+	}()
+
+	return scanTasks(rows)
+}
+
+// CountTasks of the user without the trash, completed nil - all of them.
+func (p Postgres) CountTasks(ctx context.Context, username, password string, completed *bool) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	var quantity int
+
+	if err := p.conn(ctx).QueryRowContext(ctx, `
+		SELECT
+		    COUNT(*)
+		FROM
+		    task t
+		JOIN
+		    auth a USING (user_id)
+		WHERE
+		    a.username = $1 AND
+		    a.password = $2 AND
+		    t.deleted_at IS NULL AND
+		    (NOT $3 OR t.status = $4)
+	`,
+		username,
+		password,
+		completed != nil,
+		completed != nil && *completed,
+	).Scan(
+		&quantity,
+	); err != nil {
+		return 0, fmt.Errorf("query row: %w", err)
+	}
+
+	return quantity, nil
+}
+
+func scanTasks(rows *sql.Rows) ([]Task, error) {
 	var tasks []Task
 
 	for rows.Next() {
 		var (
-			task          Task
-			taskCompleted sql.NullTime
+			task           Task
+			taskCompleted  sql.NullTime
+			taskDue        sql.NullTime
+			taskRecurrence sql.NullString
+			taskProjectID  sql.NullInt64
+			taskParentID   sql.NullInt64
 		)
 
 		if err := rows.Scan(
@@ -143,11 +279,20 @@ func (p Postgres) GetTasks(ctx context.Context, username, password string) ([]Ta
 			&task.Created,
 			&task.Updated,
 			&taskCompleted,
+			&taskDue,
+			&taskRecurrence,
+			&taskProjectID,
+			&taskParentID,
+			&task.Version,
 		); err != nil {
 			return nil, fmt.Errorf("scan row: %w", err)
 		}
 
 		task.Completed = taskCompleted.Time
+		task.Due = nullTimeRef(taskDue)
+		task.Recurrence = taskRecurrence.String
+		task.ProjectID = int(taskProjectID.Int64)
+		task.ParentID = int(taskParentID.Int64)
 
 		tasks = append(tasks, task)
 	}
@@ -159,92 +304,133 @@ func (p Postgres) GetTasks(ctx context.Context, username, password string) ([]Ta
 	return tasks, nil
 }
 
-func (p Postgres) UpdateTask(ctx context.Context, username, password string, taskID int, setValues []string) error {
+// UpdateTask updates the task, the changed fields are recorded in the task history.
+// Version 0 - update regardless of the current version.
+func (p Postgres) UpdateTask(
+	ctx context.Context, username, password string, taskID, version int, update TaskUpdate,
+) error {
 	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
 	defer cancel()
 
-	res, err := p.Pool.ExecContext(ctx, fmt.Sprintf(`
-		UPDATE
-			task
-		SET
-		   %s
-		WHERE
-		    user_id = (SELECT user_id FROM auth WHERE username = %s AND password = %s) AND
-		    task_id = %d
-	`,
-		strings.Join(setValues, ","),
-		pq.QuoteLiteral(username),
-		pq.QuoteLiteral(password),
-		taskID,
-	))
-	if err != nil {
-		return fmt.Errorf("exec: %w", err)
-	}
-
-	rowsAffected, err := res.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("rows affected: %w", err)
-	}
-
-	if rowsAffected != 1 {
-		return fmt.Errorf("taskId %d: rows affected %d: %w", taskID, rowsAffected, ErrTaskNotFound)
-	}
-
-	return nil
+	return p.WithTx(ctx, func(tx Postgres) error {
+		return updateTaskWithHistory(ctx, tx.conn(ctx), username, password, taskID, version, update)
+	})
 }
 
-func (p Postgres) DeleteTask(ctx context.Context, username, password string, taskID int) error {
+// DeleteTask moves the task to the trash, recorded in the task history.
+// Version 0 - delete regardless of the current version.
+func (p Postgres) DeleteTask(ctx context.Context, username, password string, taskID, version int) error {
 	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
 	defer cancel()
 
-	res, err := p.Pool.ExecContext(ctx, `
-		DELETE FROM
+	return p.WithTx(ctx, func(tx Postgres) error {
+		return deleteTask(ctx, tx.conn(ctx), username, password, taskID, version)
+	})
+}
+
+func deleteTask(ctx context.Context, q querier, username, password string, taskID, version int) error {
+	taskIDs, err := changeTasks(ctx, q, insertTaskHistory, username, taskMovedToTrash, `
+		UPDATE
 		    task
+		SET
+		    deleted_at = now(),
+		    version = version + 1
 		WHERE
 		    user_id = (SELECT user_id FROM auth WHERE username = $1 AND password = $2) AND
-		    task_id = $3
+		    task_id = $3 AND
+		    deleted_at IS NULL AND
+		    ($4 = 0 OR version = $4)
+		RETURNING
+		    task_id, user_id
 	`,
 		username,
 		password,
 		taskID,
+		version,
 	)
 	if err != nil {
-		return fmt.Errorf("exec: %w", err)
+		return err
 	}
 
-	rowsAffected, err := res.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("rows affected: %w", err)
-	}
-
-	if rowsAffected != 1 {
-		return fmt.Errorf("taskId %d: rows affected %d: %w", taskID, rowsAffected, ErrTaskNotFound)
+	if len(taskIDs) != 1 {
+		return taskNotAffectedError(ctx, q, username, password, taskID, version, false)
 	}
 
 	return nil
 }
 
-func (p Postgres) DeleteTasks(ctx context.Context, username, password string) (int64, error) {
+// DeleteTasks moves all user tasks to the trash, recorded in the task history. Returns the ids of the moved tasks.
+func (p Postgres) DeleteTasks(ctx context.Context, username, password string) ([]int, error) {
 	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
 	defer cancel()
 
-	res, err := p.Pool.ExecContext(ctx, `
-		DELETE FROM
-		    task
-		WHERE
-		    user_id = (SELECT user_id FROM auth WHERE username = $1 AND password = $2)
+	var taskIDs []int
+
+	if err := p.WithTx(ctx, func(tx Postgres) error {
+		var err error
+
+		taskIDs, err = changeTasks(ctx, tx.conn(ctx), insertTaskHistory, username, taskMovedToTrash, `
+			UPDATE
+			    task
+			SET
+			    deleted_at = now(),
+			    version = version + 1
+			WHERE
+			    user_id = (SELECT user_id FROM auth WHERE username = $1 AND password = $2) AND
+			    deleted_at IS NULL
+			RETURNING
+			    task_id, user_id
+		`,
+			username,
+			password,
+		)
+
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return taskIDs, nil
+}
+
+// taskNotAffectedError explains why the task was not changed: it was not found or its version has changed.
+func taskNotAffectedError(
+	ctx context.Context, q querier, username, password string, taskID, version int, withTrash bool,
+) error {
+	if version == 0 {
+		return fmt.Errorf("taskId %d: %w", taskID, ErrTaskNotFound)
+	}
+
+	var exists bool
+
+	if err := q.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT
+			    1
+			FROM
+			    task t
+			JOIN
+			    auth a USING (user_id)
+			WHERE
+			    a.username = $1 AND
+			    a.password = $2 AND
+			    t.task_id = $3 AND
+			    ($4 OR t.deleted_at IS NULL)
+		)
 	`,
 		username,
 		password,
-	)
-	if err != nil {
-		return 0, fmt.Errorf("exec: %w", err)
+		taskID,
+		withTrash,
+	).Scan(
+		&exists,
+	); err != nil {
+		return fmt.Errorf("query row: %w", err)
 	}
 
-	rowsAffected, err := res.RowsAffected()
-	if err != nil {
-		return 0, fmt.Errorf("rows affected: %w", err)
+	if exists {
+		return fmt.Errorf("taskId %d: version %d: %w", taskID, version, ErrVersionMismatch)
 	}
 
-	return rowsAffected, nil
+	return fmt.Errorf("taskId %d: %w", taskID, ErrTaskNotFound)
 }
@@ -0,0 +1,153 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"taskmanager/internal/db"
+)
+
+var (
+	ErrOperationRolledBack = errors.New("operation rolled back")
+
+	errUnknownOperation = errors.New("unknown operation")
+)
+
+const (
+	TaskOperationCreate = "create"
+	TaskOperationUpdate = "update"
+	TaskOperationDelete = "delete"
+)
+
+type TaskOperation struct {
+	Op     string
+	TaskID int
+	// Title create.
+	Title string
+	// Update update.
+	Update TaskUpdate
+	// Version update, delete: 0 - regardless of the current version.
+	Version int
+	// Permanent delete: without moving to the trash.
+	Permanent bool
+}
+
+type TaskOperationResult struct {
+	// TaskID created task.
+	TaskID int
+	Err    error
+}
+
+// BatchTasks runs the operations in one transaction.
+// Atomic - all or nothing: after the first failed operation the others are not run,
+// and all except the failed one get ErrOperationRolledBack.
+// Not atomic - each operation is applied or rolled back separately.
+// The error is returned only if the transaction itself failed, after a serialization failure the batch is retried.
+func (p Postgres) BatchTasks(
+	ctx context.Context, username, password string, operations []TaskOperation, atomic bool,
+) ([]TaskOperationResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	var results []TaskOperationResult
+
+	if err := p.WithTx(ctx, func(tx Postgres) error {
+		var err error
+
+		results, err = batchTasks(ctx, tx.conn(ctx), operations, atomic, func(op TaskOperation) (int, error) {
+			return runTaskOperation(ctx, tx.conn(ctx), username, password, op)
+		})
+
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// batchTasks runs each operation in a savepoint of the transaction q.
+func batchTasks(
+	ctx context.Context, q querier, operations []TaskOperation, atomic bool, run func(op TaskOperation) (int, error),
+) ([]TaskOperationResult, error) {
+	// The batch savepoint keeps the outer transaction, if any, when the atomic batch is rolled back.
+	if _, err := q.ExecContext(ctx, `SAVEPOINT batch`); err != nil {
+		return nil, fmt.Errorf("savepoint: %w", err)
+	}
+
+	results := make([]TaskOperationResult, len(operations))
+
+	for i, op := range operations {
+		if _, err := q.ExecContext(ctx, `SAVEPOINT operation`); err != nil {
+			return nil, fmt.Errorf("savepoint: %w", err)
+		}
+
+		taskID, err := run(op)
+		if err != nil {
+			if db.IsSerializationError(err) {
+				return nil, err
+			}
+
+			if atomic {
+				if _, err := q.ExecContext(ctx, `ROLLBACK TO SAVEPOINT batch`); err != nil {
+					return nil, fmt.Errorf("rollback to savepoint: %w", err)
+				}
+
+				return rolledBackResults(len(operations), i, err), nil
+			}
+
+			if _, err := q.ExecContext(ctx, `ROLLBACK TO SAVEPOINT operation`); err != nil {
+				return nil, fmt.Errorf("rollback to savepoint: %w", err)
+			}
+
+			results[i] = TaskOperationResult{Err: err}
+
+			continue
+		}
+
+		if _, err := q.ExecContext(ctx, `RELEASE SAVEPOINT operation`); err != nil {
+			return nil, fmt.Errorf("release savepoint: %w", err)
+		}
+
+		results[i] = TaskOperationResult{TaskID: taskID}
+	}
+
+	if _, err := q.ExecContext(ctx, `RELEASE SAVEPOINT batch`); err != nil {
+		return nil, fmt.Errorf("release savepoint: %w", err)
+	}
+
+	return results, nil
+}
+
+func runTaskOperation(ctx context.Context, q querier, username, password string, op TaskOperation) (int, error) {
+	switch op.Op {
+	case TaskOperationCreate:
+		return createTask(ctx, q, username, password, op.Title)
+
+	case TaskOperationUpdate:
+		return op.TaskID, updateTaskWithHistory(ctx, q, username, password, op.TaskID, op.Version, op.Update)
+
+	case TaskOperationDelete:
+		if op.Permanent {
+			return op.TaskID, deleteTaskPermanently(ctx, q, insertTaskHistory, username, password, op.TaskID, op.Version)
+		}
+
+		return op.TaskID, deleteTask(ctx, q, username, password, op.TaskID, op.Version)
+	}
+
+	return 0, fmt.Errorf("%w: %s", errUnknownOperation, op.Op)
+}
+
+func rolledBackResults(quantity, failed int, err error) []TaskOperationResult {
+	results := make([]TaskOperationResult, quantity)
+
+	for i := range results {
+		results[i] = TaskOperationResult{Err: ErrOperationRolledBack}
+	}
+
+	results[failed] = TaskOperationResult{Err: err}
+
+	return results
+}
@@ -0,0 +1,17 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRolledBackResults(t *testing.T) {
+	results := rolledBackResults(3, 1, ErrTaskNotFound)
+
+	assert.Equal(t, []TaskOperationResult{
+		{Err: ErrOperationRolledBack},
+		{Err: ErrTaskNotFound},
+		{Err: ErrOperationRolledBack},
+	}, results)
+}
@@ -0,0 +1,157 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrCommentNotFound = errors.New("comment not found")
+
+// TaskComment of the owner of the task or of a member of its project.
+type TaskComment struct {
+	ID       int       `json:"commentId"`
+	TaskID   int       `json:"taskId"`
+	UserID   int       `json:"userId"`
+	Username string    `json:"username"`
+	Text     string    `json:"text"`
+	Created  time.Time `json:"created"`
+}
+
+// CreateTaskComment on the task the user sees.
+func (p Postgres) CreateTaskComment(ctx context.Context, userID, taskID int, text string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	return createTaskComment(ctx, p.conn(ctx), userID, taskID, text, `
+		INSERT INTO
+			task_comment(task_id, user_id, text, created)
+		SELECT
+		    t.task_id, $1, $3, now()
+		FROM
+		    task t
+		WHERE
+		    t.task_id = $2 AND
+		    `+visibleTaskCondition+`
+		RETURNING
+		    comment_id
+	`)
+}
+
+// createTaskComment the query inserts the comment by $1 - the user, $2 - the task, $3 - the text,
+// the other arguments follow.
+func createTaskComment(
+	ctx context.Context, q querier, userID, taskID int, text, query string, args ...any,
+) (int, error) {
+	var commentID int
+
+	//nolint:execinquery
+	if err := q.QueryRowContext(ctx, query, append([]any{userID, taskID, text}, args...)...).Scan(
+		&commentID,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("taskId %d: %w", taskID, ErrTaskNotFound)
+		}
+
+		return 0, fmt.Errorf("query row: %w", err)
+	}
+
+	return commentID, nil
+}
+
+// GetTasksComments the comments of the tasks the user sees by the task id, the oldest first.
+func (p Postgres) GetTasksComments(ctx context.Context, userID int, taskIDs []int) (map[int][]TaskComment, error) {
+	comments := make(map[int][]TaskComment)
+
+	if len(taskIDs) == 0 {
+		return comments, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	args, placeholders := taskIDsArgs(userID, taskIDs)
+
+	rows, err := p.conn(ctx).QueryContext(ctx, `
+		SELECT
+		    c.comment_id, c.task_id, c.user_id, a.username, c.text, c.created
+		FROM
+		    task_comment c
+		JOIN
+		    task t USING (task_id)
+		JOIN
+		    auth a ON a.user_id = c.user_id
+		WHERE
+		    c.task_id IN (`+placeholders+`) AND
+		    `+visibleTaskCondition+`
+		ORDER BY
+		    c.task_id, c.created, c.comment_id
+	`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			p.Logger.Errorf("get tasks comments: %v", err)
+		}
+	}()
+
+	for rows.Next() {
+		var comment TaskComment
+
+		if err := rows.Scan(
+			&comment.ID,
+			&comment.TaskID,
+			&comment.UserID,
+			&comment.Username,
+			&comment.Text,
+			&comment.Created,
+		); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+
+		comments[comment.TaskID] = append(comments[comment.TaskID], comment)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("scan rows: %w", rows.Err())
+	}
+
+	return comments, nil
+}
+
+// DeleteTaskComment of the user, only its author deletes it.
+func (p Postgres) DeleteTaskComment(ctx context.Context, userID, commentID int) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	res, err := p.conn(ctx).ExecContext(ctx, `
+		DELETE FROM
+		    task_comment
+		WHERE
+		    comment_id = $2 AND
+		    user_id = $1
+	`,
+		userID,
+		commentID,
+	)
+	if err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	if rowsAffected != 1 {
+		return fmt.Errorf("commentId %d: %w", commentID, ErrCommentNotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ExportedTask the task and the trash. Deleted - zero outside the trash.
+type ExportedTask struct {
+	Task
+	Deleted time.Time `json:"deleted"`
+}
+
+// exportTasksPageSize the tasks read by one query of the export.
+const exportTasksPageSize = 100
+
+// ExportTasks calls fn for all tasks of the user including the trash, sorted by id. The tasks are read by pages of
+// task_id, fn is called between the queries: it holds neither the connection nor QueryTimeout, so a slow client
+// blocks neither the pool nor the single connection of SQLite. The error of fn stops the export and is returned.
+func (p Postgres) ExportTasks(ctx context.Context, username, password string, fn func(ExportedTask) error) error {
+	afterID := 0
+
+	for {
+		tasks, err := p.exportTasksPage(ctx, username, password, afterID)
+		if err != nil {
+			return err
+		}
+
+		for _, task := range tasks {
+			if err := fn(task); err != nil {
+				return err
+			}
+		}
+
+		if len(tasks) < exportTasksPageSize {
+			return nil
+		}
+
+		afterID = tasks[len(tasks)-1].ID
+	}
+}
+
+func (p Postgres) exportTasksPage(ctx context.Context, username, password string, afterID int) ([]ExportedTask, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	rows, err := p.conn(ctx).QueryContext(ctx, `
+		SELECT
+		    t.task_id, t.status, t.title, t.created, t.updated, t.completed, t.due, t.recurrence, t.deleted_at
+		FROM
+		    task t
+		JOIN
+		    auth a USING (user_id)
+		WHERE
+		    a.username = $1 AND
+		    a.password = $2 AND
+		    t.task_id > $3
+		ORDER BY
+		    t.task_id
+		LIMIT $4
+	`,
+		username,
+		password,
+		afterID,
+		exportTasksPageSize,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			p.Logger.Errorf("export tasks: %v", err)
+		}
+	}()
+
+	tasks := make([]ExportedTask, 0, exportTasksPageSize)
+
+	for rows.Next() {
+		var (
+			task           ExportedTask
+			taskCompleted  sql.NullTime
+			taskDue        sql.NullTime
+			taskRecurrence sql.NullString
+			taskDeleted    sql.NullTime
+		)
+
+		if err := rows.Scan(
+			&task.ID,
+			&task.Status,
+			&task.Title,
+			&task.Created,
+			&task.Updated,
+			&taskCompleted,
+			&taskDue,
+			&taskRecurrence,
+			&taskDeleted,
+		); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+
+		task.Completed = taskCompleted.Time
+		task.Due = nullTimeRef(taskDue)
+		task.Recurrence = taskRecurrence.String
+		task.Deleted = taskDeleted.Time
+
+		tasks = append(tasks, task)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("scan rows: %w", rows.Err())
+	}
+
+	return tasks, nil
+}
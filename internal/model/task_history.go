@@ -0,0 +1,551 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrRevisionNotFound = errors.New("revision not found")
+
+	errUnknownTaskField = errors.New("unknown task field")
+)
+
+const (
+	taskFieldTitle      = "title"
+	taskFieldStatus     = "status"
+	taskFieldCompleted  = "completed"
+	taskFieldDue        = "due"
+	taskFieldRecurrence = "recurrence"
+	// taskFieldTrash "true" - the task is moved to the trash, "false" - it is restored.
+	taskFieldTrash = "trash"
+	// taskFieldDeleted "true" - the task is deleted permanently.
+	taskFieldDeleted = "deleted"
+)
+
+// taskHistoryActorSystem the actor of the purge of the trash.
+const taskHistoryActorSystem = "system"
+
+// The changes of the trash and the permanent deletion, they are not the fields of the task.
+var (
+	taskMovedToTrash       = TaskChange{Field: taskFieldTrash, Old: "false", New: "true"}
+	taskRestored           = TaskChange{Field: taskFieldTrash, Old: "true", New: "false"}
+	taskDeletedPermanently = TaskChange{Field: taskFieldDeleted, Old: "false", New: "true"}
+)
+
+// taskHistoryWriter inserts the changes of the task as a new revision, the storages differ in the time of the change.
+type taskHistoryWriter func(
+	ctx context.Context, q querier, taskID, userID int, actor string, changes []TaskChange,
+) error
+
+// TaskChange one changed field of the task. Empty value - null.
+type TaskChange struct {
+	Revision int       `json:"revision"`
+	Field    string    `json:"field"`
+	Old      string    `json:"old"`
+	New      string    `json:"new"`
+	Actor    string    `json:"actor"`
+	Changed  time.Time `json:"changed"`
+}
+
+// GetTaskHistory the history is kept after the task is moved to the trash or deleted permanently.
+func (p Postgres) GetTaskHistory(ctx context.Context, username, password string, taskID int) ([]TaskChange, error) {
+	histories, err := p.GetTasksHistory(ctx, username, password, []int{taskID})
+	if err != nil {
+		return nil, fmt.Errorf("get tasks history: %w", err)
+	}
+
+	if history, ok := histories[taskID]; ok {
+		return history, nil
+	}
+
+	// The task has not been changed since it was created.
+	if _, err := p.GetTask(ctx, username, password, taskID); err != nil {
+		return nil, fmt.Errorf("get task: %w", err)
+	}
+
+	return nil, nil
+}
+
+// GetTasksHistory returns the histories of the tasks of the user in one query, also of the tasks in the trash and
+// the deleted ones. The tasks without history are absent.
+func (p Postgres) GetTasksHistory(
+	ctx context.Context, username, password string, taskIDs []int,
+) (map[int][]TaskChange, error) {
+	histories := make(map[int][]TaskChange)
+
+	if len(taskIDs) == 0 {
+		return histories, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	args := []any{username, password}
+	placeholders := make([]string, 0, len(taskIDs))
+
+	for _, taskID := range taskIDs {
+		args = append(args, taskID)
+		placeholders = append(placeholders, "$"+strconv.Itoa(len(args)))
+	}
+
+	rows, err := p.conn(ctx).QueryContext(ctx, `
+		SELECT
+		    h.task_id, h.revision, h.field, h.old_value, h.new_value, h.actor, h.changed
+		FROM
+		    task_history h
+		JOIN
+		    auth a USING (user_id)
+		WHERE
+		    a.username = $1 AND
+		    a.password = $2 AND
+		    h.task_id IN (`+strings.Join(placeholders, ", ")+`)
+		ORDER BY
+		    h.task_id, h.revision, h.history_id
+	`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			p.Logger.Errorf("get tasks history: %v", err)
+		}
+	}()
+
+	for rows.Next() {
+		var (
+			taskID   int
+			change   TaskChange
+			oldValue sql.NullString
+			newValue sql.NullString
+		)
+
+		if err := rows.Scan(
+			&taskID,
+			&change.Revision,
+			&change.Field,
+			&oldValue,
+			&newValue,
+			&change.Actor,
+			&change.Changed,
+		); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+
+		change.Old = oldValue.String
+		change.New = newValue.String
+
+		histories[taskID] = append(histories[taskID], change)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("scan rows: %w", rows.Err())
+	}
+
+	return histories, nil
+}
+
+// GetTaskRevision returns the task as it was after the specified revision, revision 0 - as it was created.
+func (p Postgres) GetTaskRevision(ctx context.Context, username, password string, taskID, revision int) (Task, error) {
+	task, err := p.GetTask(ctx, username, password, taskID)
+	if err != nil {
+		return Task{}, fmt.Errorf("get task: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	history, err := p.selectTaskHistory(ctx, p.conn(ctx), taskID)
+	if err != nil {
+		return Task{}, fmt.Errorf("select task history: %w", err)
+	}
+
+	task, err = taskAtRevision(task, history, revision)
+	if err != nil {
+		return Task{}, fmt.Errorf("taskId %d: %w", taskID, err)
+	}
+
+	task.ID = taskID
+
+	return task, nil
+}
+
+// RevertTask returns the task fields to the specified revision, the revert is recorded as a new revision.
+func (p Postgres) RevertTask(ctx context.Context, username, password string, taskID, revision int) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	return p.WithTx(ctx, func(tx Postgres) error {
+		task, err := selectTaskForUpdate(ctx, tx.conn(ctx), username, password, taskID)
+		if err != nil {
+			return fmt.Errorf("select task: %w", err)
+		}
+
+		history, err := tx.selectTaskHistory(ctx, tx.conn(ctx), taskID)
+		if err != nil {
+			return fmt.Errorf("select task history: %w", err)
+		}
+
+		task, err = taskAtRevision(task, history, revision)
+		if err != nil {
+			return fmt.Errorf("taskId %d: %w", taskID, err)
+		}
+
+		return updateTaskWithHistory(ctx, tx.conn(ctx), username, password, taskID, 0, revertTaskUpdate(task))
+	})
+}
+
+func (p Postgres) selectTaskHistory(ctx context.Context, q querier, taskID int) ([]TaskChange, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT
+		    revision, field, old_value, new_value, actor, changed
+		FROM
+		    task_history
+		WHERE
+		    task_id = $1
+		ORDER BY
+		    revision, history_id
+	`,
+		taskID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			p.Logger.Errorf("select task history: %v", err)
+		}
+	}()
+
+	var history []TaskChange
+
+	for rows.Next() {
+		var (
+			change   TaskChange
+			oldValue sql.NullString
+			newValue sql.NullString
+		)
+
+		if err := rows.Scan(
+			&change.Revision,
+			&change.Field,
+			&oldValue,
+			&newValue,
+			&change.Actor,
+			&change.Changed,
+		); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+
+		change.Old = oldValue.String
+		change.New = newValue.String
+
+		history = append(history, change)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("scan rows: %w", rows.Err())
+	}
+
+	return history, nil
+}
+
+func selectTaskForUpdate(ctx context.Context, q querier, username, password string, taskID int) (Task, error) {
+	var (
+		task           Task
+		taskCompleted  sql.NullTime
+		taskDue        sql.NullTime
+		taskRecurrence sql.NullString
+	)
+
+	if err := q.QueryRowContext(ctx, `
+		SELECT
+		    t.status, t.title, t.created, t.updated, t.completed, t.due, t.recurrence, t.version
+		FROM
+		    task t
+		JOIN
+		    auth a USING (user_id)
+		WHERE
+		    a.username = $1 AND
+		    a.password = $2 AND
+		    t.task_id = $3 AND
+		    t.deleted_at IS NULL
+		FOR UPDATE OF t
+	`,
+		username,
+		password,
+		taskID,
+	).Scan(
+		&task.Status,
+		&task.Title,
+		&task.Created,
+		&task.Updated,
+		&taskCompleted,
+		&taskDue,
+		&taskRecurrence,
+		&task.Version,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Task{}, fmt.Errorf("taskId: %d: %w", taskID, ErrTaskNotFound)
+		}
+
+		return Task{}, fmt.Errorf("query row: %w", err)
+	}
+
+	task.Completed = taskCompleted.Time
+	task.Due = nullTimeRef(taskDue)
+	task.Recurrence = taskRecurrence.String
+
+	return task, nil
+}
+
+// updateTaskWithHistory updates the task and records the changed fields as a new revision.
+// Version 0 - update regardless of the current version.
+func updateTaskWithHistory(
+	ctx context.Context, q querier, username, password string, taskID, version int, update TaskUpdate,
+) error {
+	oldTask, err := selectTaskForUpdate(ctx, q, username, password, taskID)
+	if err != nil {
+		return err
+	}
+
+	if version != 0 && version != oldTask.Version {
+		return fmt.Errorf("taskId %d: version %d: current %d: %w", taskID, version, oldTask.Version, ErrVersionMismatch)
+	}
+
+	var (
+		newTask        Task
+		taskCompleted  sql.NullTime
+		taskDue        sql.NullTime
+		taskRecurrence sql.NullString
+		userID         int
+	)
+
+	query, args := update.query(taskID)
+
+	if err := q.QueryRowContext(ctx, query, args...).Scan(
+		&newTask.Status,
+		&newTask.Title,
+		&taskCompleted,
+		&taskDue,
+		&taskRecurrence,
+		&userID,
+	); err != nil {
+		return fmt.Errorf("query row: %w", err)
+	}
+
+	newTask.Completed = taskCompleted.Time
+	newTask.Due = nullTimeRef(taskDue)
+	newTask.Recurrence = taskRecurrence.String
+
+	changes := diffTask(oldTask, newTask)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	if err := insertTaskHistory(ctx, q, taskID, userID, username, changes); err != nil {
+		return fmt.Errorf("insert task history: %w", err)
+	}
+
+	return nil
+}
+
+func insertTaskHistory(ctx context.Context, q querier, taskID, userID int, actor string, changes []TaskChange) error {
+	var revision int
+
+	if err := q.QueryRowContext(ctx, `
+		SELECT
+		    COALESCE(MAX(revision), 0) + 1
+		FROM
+		    task_history
+		WHERE
+		    task_id = $1
+	`,
+		taskID,
+	).Scan(
+		&revision,
+	); err != nil {
+		return fmt.Errorf("query row: %w", err)
+	}
+
+	for _, change := range changes {
+		if _, err := q.ExecContext(ctx, `
+			INSERT INTO
+				task_history(task_id, user_id, revision, field, old_value, new_value, actor, changed)
+			VALUES
+			    ($1, $2, $3, $4, $5, $6, $7, now())
+		`,
+			taskID,
+			userID,
+			revision,
+			change.Field,
+			sql.NullString{String: change.Old, Valid: change.Old != ""},
+			sql.NullString{String: change.New, Valid: change.New != ""},
+			actor,
+		); err != nil {
+			return fmt.Errorf("exec: %w", err)
+		}
+	}
+
+	return nil
+}
+
+var taskFields = []string{taskFieldTitle, taskFieldStatus, taskFieldCompleted, taskFieldDue, taskFieldRecurrence}
+
+func diffTask(oldTask, newTask Task) []TaskChange {
+	var changes []TaskChange
+
+	for _, field := range taskFields {
+		oldValue, newValue := taskFieldValue(oldTask, field), taskFieldValue(newTask, field)
+		if oldValue != newValue {
+			changes = append(changes, TaskChange{
+				Field: field,
+				Old:   oldValue,
+				New:   newValue,
+			})
+		}
+	}
+
+	return changes
+}
+
+// taskAtRevision rolls back the changes made after the revision. The history is sorted by revision.
+func taskAtRevision(task Task, history []TaskChange, revision int) (Task, error) {
+	lastRevision := 0
+	if len(history) > 0 {
+		lastRevision = history[len(history)-1].Revision
+	}
+
+	if revision < 0 || revision > lastRevision {
+		return Task{}, fmt.Errorf("revision %d: last revision %d: %w", revision, lastRevision, ErrRevisionNotFound)
+	}
+
+	if revision == 0 {
+		task.Updated = task.Created
+	}
+
+	for i := len(history) - 1; i >= 0; i-- {
+		change := history[i]
+
+		if change.Revision == revision {
+			task.Updated = change.Changed
+		}
+
+		if change.Revision <= revision {
+			continue
+		}
+
+		if err := setTaskFieldValue(&task, change.Field, change.Old); err != nil {
+			return Task{}, fmt.Errorf("revision %d: %w", change.Revision, err)
+		}
+	}
+
+	return task, nil
+}
+
+func revertTaskUpdate(task Task) TaskUpdate {
+	var due time.Time
+	if task.Due != nil {
+		due = *task.Due
+	}
+
+	return TaskUpdate{
+		Title:      &task.Title,
+		Status:     &task.Status,
+		Completed:  &task.Completed,
+		Due:        &due,
+		Recurrence: &task.Recurrence,
+	}
+}
+
+func taskFieldValue(task Task, field string) string {
+	switch field {
+	case taskFieldTitle:
+		return task.Title
+
+	case taskFieldStatus:
+		return strconv.FormatBool(task.Status)
+
+	case taskFieldCompleted:
+		if task.Completed.IsZero() {
+			return ""
+		}
+
+		return task.Completed.UTC().Format(time.RFC3339Nano)
+
+	case taskFieldDue:
+		if task.Due == nil {
+			return ""
+		}
+
+		return task.Due.UTC().Format(time.RFC3339Nano)
+
+	case taskFieldRecurrence:
+		return task.Recurrence
+	}
+
+	return ""
+}
+
+func setTaskFieldValue(task *Task, field, value string) error {
+	switch field {
+	case taskFieldTitle:
+		task.Title = value
+
+	case taskFieldStatus:
+		status, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("parse status: %w", err)
+		}
+
+		task.Status = status
+
+	case taskFieldCompleted:
+		if value == "" {
+			task.Completed = time.Time{}
+
+			return nil
+		}
+
+		completed, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return fmt.Errorf("parse completed: %w", err)
+		}
+
+		task.Completed = completed
+
+	case taskFieldDue:
+		if value == "" {
+			task.Due = nil
+
+			return nil
+		}
+
+		due, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return fmt.Errorf("parse due: %w", err)
+		}
+
+		task.Due = &due
+
+	case taskFieldRecurrence:
+		task.Recurrence = value
+
+	// The revert does not move the task in or out of the trash.
+	case taskFieldTrash, taskFieldDeleted:
+
+	default:
+		return fmt.Errorf("%w: %s", errUnknownTaskField, field)
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffTask(t *testing.T) {
+	completed := time.Date(2023, 4, 20, 12, 30, 0, 0, time.UTC)
+
+	cases := []struct {
+		name     string
+		oldTask  Task
+		newTask  Task
+		expected []TaskChange
+	}{
+		{
+			name:     "no changes",
+			oldTask:  Task{Title: "task1"},
+			newTask:  Task{Title: "task1"},
+			expected: nil,
+		},
+		{
+			name:    "new title",
+			oldTask: Task{Title: "task1"},
+			newTask: Task{Title: "task2"},
+			expected: []TaskChange{
+				{Field: "title", Old: "task1", New: "task2"},
+			},
+		},
+		{
+			name:    "completed",
+			oldTask: Task{Title: "task1"},
+			newTask: Task{Title: "task1", Status: true, Completed: completed},
+			expected: []TaskChange{
+				{Field: "status", Old: "false", New: "true"},
+				{Field: "completed", Old: "", New: "2023-04-20T12:30:00Z"},
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, diffTask(tt.oldTask, tt.newTask))
+		})
+	}
+}
+
+func TestTaskAtRevision(t *testing.T) {
+	created := time.Date(2023, 4, 20, 10, 0, 0, 0, time.UTC)
+	changed1 := time.Date(2023, 4, 20, 11, 0, 0, 0, time.UTC)
+	changed2 := time.Date(2023, 4, 20, 12, 0, 0, 0, time.UTC)
+
+	current := Task{
+		Status:    true,
+		Title:     "task1_new",
+		Created:   created,
+		Updated:   changed2,
+		Completed: changed2,
+	}
+
+	history := []TaskChange{
+		{Revision: 1, Field: "title", Old: "task1", New: "task1_new", Changed: changed1},
+		{Revision: 2, Field: "status", Old: "false", New: "true", Changed: changed2},
+		{Revision: 2, Field: "completed", Old: "", New: "2023-04-20T12:00:00Z", Changed: changed2},
+	}
+
+	cases := []struct {
+		name     string
+		revision int
+		expected Task
+		err      error
+	}{
+		{
+			name:     "created",
+			revision: 0,
+			expected: Task{Title: "task1", Created: created, Updated: created},
+		},
+		{
+			name:     "revision 1",
+			revision: 1,
+			expected: Task{Title: "task1_new", Created: created, Updated: changed1},
+		},
+		{
+			name:     "last revision",
+			revision: 2,
+			expected: current,
+		},
+		{
+			name:     "unknown revision",
+			revision: 3,
+			err:      ErrRevisionNotFound,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			task, err := taskAtRevision(current, history, tt.revision)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, task)
+		})
+	}
+}
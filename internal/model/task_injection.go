@@ -14,7 +14,7 @@ func (p Postgres) CreateTaskWithInjection(ctx context.Context, username, passwor
 
 	var taskID int
 
-	if err := p.Pool.QueryRowContext(ctx, fmt.Sprintf(`
+	if err := p.conn(ctx).QueryRowContext(ctx, fmt.Sprintf(`
 			INSERT INTO
 				task(user_id, status, title, created, updated)
 			VALUES
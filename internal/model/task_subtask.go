@@ -0,0 +1,162 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTaskParentInvalid the subtasks are one level deep: the parent is not a subtask and the subtask has no subtasks.
+var ErrTaskParentInvalid = errors.New("invalid task parent")
+
+// SetTaskParent makes the task of the user the subtask of another task of the user, 0 - the task is not a subtask.
+func (p Postgres) SetTaskParent(ctx context.Context, username, password string, taskID, parentID int) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	return setTaskParent(ctx, p.conn(ctx), username, password, taskID, parentID, `
+		UPDATE
+		    task
+		SET
+		    parent_id = NULLIF($4, 0),
+		    updated = now(),
+		    version = version + 1
+		WHERE
+		    user_id = (SELECT user_id FROM auth WHERE username = $1 AND password = $2) AND
+		    task_id = $3 AND
+		    deleted_at IS NULL AND
+		    (
+		        $4 = 0 OR
+		        (
+		            $4 <> $3 AND
+		            EXISTS (
+		                SELECT
+		                    1
+		                FROM
+		                    task p
+		                WHERE
+		                    p.task_id = $4 AND
+		                    p.user_id = task.user_id AND
+		                    p.parent_id IS NULL AND
+		                    p.deleted_at IS NULL
+		            ) AND
+		            NOT EXISTS (
+		                SELECT
+		                    1
+		                FROM
+		                    task s
+		                WHERE
+		                    s.parent_id = $3
+		            )
+		        )
+		    )
+	`)
+}
+
+// setTaskParent the query updates the task by $1, $2 - the credentials, $3 - the task, $4 - the parent,
+// the other arguments follow.
+func setTaskParent(
+	ctx context.Context, q querier, username, password string, taskID, parentID int, query string, args ...any,
+) error {
+	res, err := q.ExecContext(ctx, query, append([]any{username, password, taskID, parentID}, args...)...)
+	if err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	if rowsAffected == 1 {
+		return nil
+	}
+
+	for _, id := range []int{taskID, parentID} {
+		if id == 0 {
+			continue
+		}
+
+		var found bool
+
+		if err := q.QueryRowContext(ctx, `
+			SELECT EXISTS (
+				SELECT
+				    1
+				FROM
+				    task t
+				JOIN
+				    auth a USING (user_id)
+				WHERE
+				    a.username = $1 AND
+				    a.password = $2 AND
+				    t.task_id = $3 AND
+				    t.deleted_at IS NULL
+			)
+		`,
+			username,
+			password,
+			id,
+		).Scan(
+			&found,
+		); err != nil {
+			return fmt.Errorf("query row: %w", err)
+		}
+
+		if !found {
+			return fmt.Errorf("taskId %d: %w", id, ErrTaskNotFound)
+		}
+	}
+
+	return fmt.Errorf("taskId %d, parentId %d: %w", taskID, parentID, ErrTaskParentInvalid)
+}
+
+// GetSubtasks of the tasks the user sees by the id of the parent, without the trash, sorted by id.
+func (p Postgres) GetSubtasks(ctx context.Context, userID int, parentIDs []int) (map[int][]Task, error) {
+	subtasks := make(map[int][]Task)
+
+	if len(parentIDs) == 0 {
+		return subtasks, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	args, placeholders := taskIDsArgs(userID, parentIDs)
+
+	rows, err := p.conn(ctx).QueryContext(ctx, `
+		SELECT
+		    t.task_id, t.status, t.title, t.created, t.updated, t.completed, t.due, t.recurrence, t.project_id,
+		    t.parent_id, t.version
+		FROM
+		    task t
+		WHERE
+		    t.parent_id IN (`+placeholders+`) AND
+		    `+visibleTaskCondition+`
+		ORDER BY
+		    t.task_id
+	`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			p.Logger.Errorf("get subtasks: %v", err)
+		}
+	}()
+
+	tasks, err := scanTasks(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, task := range tasks {
+		subtasks[task.ParentID] = append(subtasks[task.ParentID], task)
+	}
+
+	return subtasks, nil
+}
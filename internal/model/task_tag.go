@@ -0,0 +1,163 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SetTaskTags replaces the tags of the task of the user, the duplicates are ignored.
+func (p Postgres) SetTaskTags(ctx context.Context, userID, taskID int, tags []string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	return p.WithTx(ctx, func(tx Postgres) error {
+		return setTaskTags(ctx, tx.conn(ctx), userID, taskID, tags)
+	})
+}
+
+func setTaskTags(ctx context.Context, q querier, userID, taskID int, tags []string) error {
+	if err := q.QueryRowContext(ctx, `
+		SELECT
+		    task_id
+		FROM
+		    task
+		WHERE
+		    task_id = $2 AND
+		    user_id = $1 AND
+		    deleted_at IS NULL
+	`,
+		userID,
+		taskID,
+	).Scan(
+		&taskID,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("taskId %d: %w", taskID, ErrTaskNotFound)
+		}
+
+		return fmt.Errorf("query row: %w", err)
+	}
+
+	if _, err := q.ExecContext(ctx, `
+		DELETE FROM
+		    task_tag
+		WHERE
+		    task_id = $1
+	`,
+		taskID,
+	); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	for _, tag := range tags {
+		if _, err := q.ExecContext(ctx, `
+			INSERT INTO
+				task_tag(task_id, name)
+			VALUES
+			    ($1, $2)
+			ON CONFLICT DO NOTHING
+		`,
+			taskID,
+			tag,
+		); err != nil {
+			return fmt.Errorf("exec: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetTasksTags the tags of the tasks the user sees by the task id, sorted by name.
+// The tasks of the others and the ones in the trash are skipped.
+func (p Postgres) GetTasksTags(ctx context.Context, userID int, taskIDs []int) (map[int][]string, error) {
+	tags := make(map[int][]string)
+
+	if len(taskIDs) == 0 {
+		return tags, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	args, placeholders := taskIDsArgs(userID, taskIDs)
+
+	rows, err := p.conn(ctx).QueryContext(ctx, `
+		SELECT
+		    g.task_id, g.name
+		FROM
+		    task_tag g
+		JOIN
+		    task t USING (task_id)
+		WHERE
+		    g.task_id IN (`+placeholders+`) AND
+		    `+visibleTaskCondition+`
+		ORDER BY
+		    g.task_id, g.name
+	`,
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			p.Logger.Errorf("get tasks tags: %v", err)
+		}
+	}()
+
+	for rows.Next() {
+		var (
+			taskID int
+			tag    string
+		)
+
+		if err := rows.Scan(
+			&taskID,
+			&tag,
+		); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+
+		tags[taskID] = append(tags[taskID], tag)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("scan rows: %w", rows.Err())
+	}
+
+	return tags, nil
+}
+
+// visibleTaskCondition the task t is seen by the user $1: its owner or a member of its project, not in the trash.
+const visibleTaskCondition = `t.deleted_at IS NULL AND
+		    (
+		        t.user_id = $1 OR
+		        EXISTS (
+		            SELECT
+		                1
+		            FROM
+		                project_member m
+		            WHERE
+		                m.project_id = t.project_id AND
+		                m.user_id = $1
+		        )
+		    )`
+
+// taskIDsArgs $1 - the user, the placeholders of the tasks follow.
+func taskIDsArgs(userID int, taskIDs []int) ([]any, string) {
+	args := []any{userID}
+	placeholders := make([]string, 0, len(taskIDs))
+
+	for _, taskID := range taskIDs {
+		args = append(args, taskID)
+		placeholders = append(placeholders, "$"+strconv.Itoa(len(args)))
+	}
+
+	return args, strings.Join(placeholders, ", ")
+}
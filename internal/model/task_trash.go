@@ -0,0 +1,338 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type DeletedTask struct {
+	Task
+	Deleted time.Time `json:"deleted"`
+}
+
+func (p Postgres) GetTrash(ctx context.Context, username, password string) ([]DeletedTask, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	rows, err := p.conn(ctx).QueryContext(ctx, `
+		SELECT
+		    t.task_id, t.status, t.title, t.created, t.updated, t.completed, t.due, t.recurrence, t.deleted_at
+		FROM
+		    task t
+		JOIN
+		    auth a USING (user_id)
+		WHERE
+		    a.username = $1 AND
+		    a.password = $2 AND
+		    t.deleted_at IS NOT NULL
+		ORDER BY
+		    t.deleted_at DESC
+	`,
+		username,
+		password,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			p.Logger.Errorf("get trash: %v", err)
+		}
+	}()
+
+	var tasks []DeletedTask
+
+	for rows.Next() {
+		var (
+			task           DeletedTask
+			taskCompleted  sql.NullTime
+			taskDue        sql.NullTime
+			taskRecurrence sql.NullString
+		)
+
+		if err := rows.Scan(
+			&task.ID,
+			&task.Status,
+			&task.Title,
+			&task.Created,
+			&task.Updated,
+			&taskCompleted,
+			&taskDue,
+			&taskRecurrence,
+			&task.Deleted,
+		); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+
+		task.Completed = taskCompleted.Time
+		task.Due = nullTimeRef(taskDue)
+		task.Recurrence = taskRecurrence.String
+
+		tasks = append(tasks, task)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("scan rows: %w", rows.Err())
+	}
+
+	return tasks, nil
+}
+
+// RestoreTask moves the task out of the trash, recorded in the task history.
+func (p Postgres) RestoreTask(ctx context.Context, username, password string, taskID int) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	return p.WithTx(ctx, func(tx Postgres) error {
+		return restoreTask(ctx, tx.conn(ctx), insertTaskHistory, username, password, taskID)
+	})
+}
+
+func restoreTask(
+	ctx context.Context, q querier, write taskHistoryWriter, username, password string, taskID int,
+) error {
+	taskIDs, err := changeTasks(ctx, q, write, username, taskRestored, `
+		UPDATE
+		    task
+		SET
+		    deleted_at = null,
+		    version = version + 1
+		WHERE
+		    user_id = (SELECT user_id FROM auth WHERE username = $1 AND password = $2) AND
+		    task_id = $3 AND
+		    deleted_at IS NOT NULL
+		RETURNING
+		    task_id, user_id
+	`,
+		username,
+		password,
+		taskID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if len(taskIDs) != 1 {
+		return fmt.Errorf("taskId %d: rows affected %d: %w", taskID, len(taskIDs), ErrTaskNotFound)
+	}
+
+	return nil
+}
+
+// RestoreTasks moves all user tasks out of the trash, recorded in the task history.
+// Returns the ids of the restored tasks.
+func (p Postgres) RestoreTasks(ctx context.Context, username, password string) ([]int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	var taskIDs []int
+
+	if err := p.WithTx(ctx, func(tx Postgres) error {
+		var err error
+
+		taskIDs, err = restoreTasks(ctx, tx.conn(ctx), insertTaskHistory, username, password)
+
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return taskIDs, nil
+}
+
+func restoreTasks(ctx context.Context, q querier, write taskHistoryWriter, username, password string) ([]int, error) {
+	return changeTasks(ctx, q, write, username, taskRestored, `
+		UPDATE
+		    task
+		SET
+		    deleted_at = null,
+		    version = version + 1
+		WHERE
+		    user_id = (SELECT user_id FROM auth WHERE username = $1 AND password = $2) AND
+		    deleted_at IS NOT NULL
+		RETURNING
+		    task_id, user_id
+	`,
+		username,
+		password,
+	)
+}
+
+// DeleteTaskPermanently deletes the task, whether it is in the trash or not. Its history is kept with the deletion.
+// Version 0 - delete regardless of the current version.
+func (p Postgres) DeleteTaskPermanently(ctx context.Context, username, password string, taskID, version int) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	return p.WithTx(ctx, func(tx Postgres) error {
+		return deleteTaskPermanently(ctx, tx.conn(ctx), insertTaskHistory, username, password, taskID, version)
+	})
+}
+
+func deleteTaskPermanently(
+	ctx context.Context, q querier, write taskHistoryWriter, username, password string, taskID, version int,
+) error {
+	taskIDs, err := changeTasks(ctx, q, write, username, taskDeletedPermanently, `
+		DELETE FROM
+		    task
+		WHERE
+		    user_id = (SELECT user_id FROM auth WHERE username = $1 AND password = $2) AND
+		    task_id = $3 AND
+		    ($4 = 0 OR version = $4)
+		RETURNING
+		    task_id, user_id
+	`,
+		username,
+		password,
+		taskID,
+		version,
+	)
+	if err != nil {
+		return err
+	}
+
+	if len(taskIDs) != 1 {
+		return taskNotAffectedError(ctx, q, username, password, taskID, version, true)
+	}
+
+	return nil
+}
+
+// DeleteTasksPermanently deletes all user tasks including the trash, their history is kept with the deletion.
+// Returns the ids of the deleted tasks.
+func (p Postgres) DeleteTasksPermanently(ctx context.Context, username, password string) ([]int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	var taskIDs []int
+
+	if err := p.WithTx(ctx, func(tx Postgres) error {
+		var err error
+
+		taskIDs, err = deleteTasksPermanently(ctx, tx.conn(ctx), insertTaskHistory, username, password)
+
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return taskIDs, nil
+}
+
+func deleteTasksPermanently(
+	ctx context.Context, q querier, write taskHistoryWriter, username, password string,
+) ([]int, error) {
+	return changeTasks(ctx, q, write, username, taskDeletedPermanently, `
+		DELETE FROM
+		    task
+		WHERE
+		    user_id = (SELECT user_id FROM auth WHERE username = $1 AND password = $2)
+		RETURNING
+		    task_id, user_id
+	`,
+		username,
+		password,
+	)
+}
+
+// PurgeTrash deletes the tasks of all users that were moved to the trash before the specified time.
+// The deletion is recorded in the task history by the system.
+func (p Postgres) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	var purged int64
+
+	if err := p.WithTx(ctx, func(tx Postgres) error {
+		var err error
+
+		purged, err = purgeTrash(ctx, tx.conn(ctx), insertTaskHistory, before)
+
+		return err
+	}); err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
+
+func purgeTrash(ctx context.Context, q querier, write taskHistoryWriter, before time.Time) (int64, error) {
+	taskIDs, err := changeTasks(ctx, q, write, taskHistoryActorSystem, taskDeletedPermanently, `
+		DELETE FROM
+		    task
+		WHERE
+		    deleted_at < $1
+		RETURNING
+		    task_id, user_id
+	`,
+		before,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(len(taskIDs)), nil
+}
+
+// changeTasks runs the query changing the tasks and returning their task_id and user_id, the change is recorded in
+// the history of each task. Returns the ids of the changed tasks.
+func changeTasks(
+	ctx context.Context, q querier, write taskHistoryWriter, actor string, change TaskChange, query string, args ...any,
+) ([]int, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	owners, err := scanTaskOwners(rows)
+	if closeErr := rows.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("close rows: %w", closeErr)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	taskIDs := make([]int, 0, len(owners))
+
+	for _, owner := range owners {
+		if err := write(ctx, q, owner.taskID, owner.userID, actor, []TaskChange{change}); err != nil {
+			return nil, fmt.Errorf("taskId %d: insert task history: %w", owner.taskID, err)
+		}
+
+		taskIDs = append(taskIDs, owner.taskID)
+	}
+
+	return taskIDs, nil
+}
+
+type taskOwner struct {
+	taskID int
+	userID int
+}
+
+func scanTaskOwners(rows *sql.Rows) ([]taskOwner, error) {
+	var owners []taskOwner
+
+	for rows.Next() {
+		var owner taskOwner
+
+		if err := rows.Scan(
+			&owner.taskID,
+			&owner.userID,
+		); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+
+		owners = append(owners, owner)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("scan rows: %w", rows.Err())
+	}
+
+	return owners, nil
+}
@@ -0,0 +1,136 @@
+package model
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TaskUpdate nil - the field is not changed.
+type TaskUpdate struct {
+	Title  *string
+	Status *bool
+	// Completed sets the exact completion time, zero - null.
+	// By default, it is set from Status: now() when the task is completed, null when it is not.
+	Completed *time.Time
+	// Due zero - null.
+	Due *time.Time
+	// Recurrence empty - null.
+	Recurrence *string
+}
+
+// query UPDATE of one task by task_id($1) returning the changed fields and the owner, the task version is incremented.
+func (u TaskUpdate) query(taskID int) (string, []any) {
+	setClause, args := u.setClause([]any{taskID})
+
+	return fmt.Sprintf(`
+		UPDATE
+			task
+		SET
+		    %s
+		WHERE
+		    task_id = $1
+		RETURNING
+		    status, title, completed, due, recurrence, user_id
+	`,
+		setClause,
+	), args
+}
+
+// setClause column names are constants, all values are passed as arguments following args.
+func (u TaskUpdate) setClause(args []any) (string, []any) {
+	var columns []string
+
+	set := func(column string, value any) {
+		args = append(args, value)
+		columns = append(columns, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+
+	if u.Status != nil {
+		set("status", *u.Status)
+	}
+
+	if u.Title != nil {
+		set("title", *u.Title)
+	}
+
+	if u.Due != nil {
+		set("due", sql.NullTime{Time: u.Due.UTC(), Valid: !u.Due.IsZero()})
+	}
+
+	if u.Recurrence != nil {
+		set("recurrence", sql.NullString{String: *u.Recurrence, Valid: *u.Recurrence != ""})
+	}
+
+	completing := u.Status != nil && *u.Status
+
+	switch {
+	case u.Completed != nil:
+		set("completed", sql.NullTime{Time: *u.Completed, Valid: !u.Completed.IsZero()})
+
+	case u.Status == nil:
+
+	case completing:
+		columns = append(columns, "completed = now()")
+
+	default:
+		columns = append(columns, "completed = null")
+	}
+
+	// The completion time is the time of the last change for the completed task.
+	if !completing || u.Completed != nil {
+		columns = append(columns, "updated = now()")
+	}
+
+	columns = append(columns, "version = version + 1")
+
+	return strings.Join(columns, ", "), args
+}
+
+// apply the same changes as the query, for the storages without SQL.
+func (u TaskUpdate) apply(task Task, now time.Time) Task {
+	if u.Status != nil {
+		task.Status = *u.Status
+	}
+
+	if u.Title != nil {
+		task.Title = *u.Title
+	}
+
+	if u.Due != nil {
+		task.Due = nil
+
+		if !u.Due.IsZero() {
+			due := *u.Due
+			task.Due = &due
+		}
+	}
+
+	if u.Recurrence != nil {
+		task.Recurrence = *u.Recurrence
+	}
+
+	completing := u.Status != nil && *u.Status
+
+	switch {
+	case u.Completed != nil:
+		task.Completed = *u.Completed
+
+	case u.Status == nil:
+
+	case completing:
+		task.Completed = now
+
+	default:
+		task.Completed = time.Time{}
+	}
+
+	if !completing || u.Completed != nil {
+		task.Updated = now
+	}
+
+	task.Version++
+
+	return task
+}
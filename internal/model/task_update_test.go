@@ -0,0 +1,116 @@
+package model
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskUpdateSetClause(t *testing.T) {
+	var (
+		title1 = "task1"
+		title2 = "task2"
+		title3 = "task3'); DROP TABLE task; --"
+
+		completed    = true
+		notCompleted = false
+
+		completedTime = time.Date(2023, 4, 20, 12, 30, 0, 0, time.UTC)
+		zeroTime      = time.Time{}
+
+		// The timestamp column of Postgres drops the offset.
+		dueWithOffset = time.Date(2023, 5, 2, 12, 30, 0, 0, time.FixedZone("MSK", 3*60*60))
+	)
+
+	cases := []struct {
+		name           string
+		update         TaskUpdate
+		expectedClause string
+		expectedArgs   []any
+	}{
+		{
+			name: "test1",
+			update: TaskUpdate{
+				Title:  &title1,
+				Status: &notCompleted,
+			},
+			expectedClause: "status = $2, title = $3, completed = null, updated = now(), version = version + 1",
+			expectedArgs:   []any{24, false, "task1"},
+		},
+		{
+			name: "test2",
+			update: TaskUpdate{
+				Title:  &title2,
+				Status: &completed,
+			},
+			expectedClause: "status = $2, title = $3, completed = now(), version = version + 1",
+			expectedArgs:   []any{24, true, "task2"},
+		},
+		{
+			name: "test3",
+			update: TaskUpdate{
+				Title: &title3,
+			},
+			expectedClause: "title = $2, updated = now(), version = version + 1",
+			expectedArgs:   []any{24, "task3'); DROP TABLE task; --"},
+		},
+		{
+			name: "test4",
+			update: TaskUpdate{
+				Status: &notCompleted,
+			},
+			expectedClause: "status = $2, completed = null, updated = now(), version = version + 1",
+			expectedArgs:   []any{24, false},
+		},
+		{
+			name: "test5",
+			update: TaskUpdate{
+				Status: &completed,
+			},
+			expectedClause: "status = $2, completed = now(), version = version + 1",
+			expectedArgs:   []any{24, true},
+		},
+		{
+			name: "exact completion time",
+			update: TaskUpdate{
+				Status:    &completed,
+				Completed: &completedTime,
+			},
+			expectedClause: "status = $2, completed = $3, updated = now(), version = version + 1",
+			expectedArgs:   []any{24, true, sql.NullTime{Time: completedTime, Valid: true}},
+		},
+		{
+			name: "zero completion time",
+			update: TaskUpdate{
+				Status:    &notCompleted,
+				Completed: &zeroTime,
+			},
+			expectedClause: "status = $2, completed = $3, updated = now(), version = version + 1",
+			expectedArgs:   []any{24, false, sql.NullTime{}},
+		},
+		{
+			name: "due in UTC",
+			update: TaskUpdate{
+				Due: &dueWithOffset,
+			},
+			expectedClause: "due = $2, updated = now(), version = version + 1",
+			expectedArgs:   []any{24, sql.NullTime{Time: time.Date(2023, 5, 2, 9, 30, 0, 0, time.UTC), Valid: true}},
+		},
+		{
+			name:           "nothing",
+			update:         TaskUpdate{},
+			expectedClause: "updated = now(), version = version + 1",
+			expectedArgs:   []any{24},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			clause, args := tt.update.setClause([]any{24})
+			assert.Equal(t, tt.expectedClause, clause)
+			assert.Equal(t, tt.expectedArgs, args)
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"taskmanager/internal/db"
+)
+
+const delayBetweenTxAttempts = time.Millisecond * 50
+
+var errUnknownIsolationLevel = errors.New("unknown isolation level")
+
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// txKey the context key of the transaction of Atomic.
+type txKey struct{}
+
+type TxOptions struct {
+	// Isolation 0 - the database default.
+	Isolation sql.IsolationLevel
+	// MaxRetries the transaction is run again after a serialization failure or a deadlock.
+	MaxRetries int
+}
+
+// ParseIsolationLevel "read committed", "repeatable read", "serializable", empty - the database default.
+func ParseIsolationLevel(level string) (sql.IsolationLevel, error) {
+	switch strings.ToLower(level) {
+	case "":
+		return sql.LevelDefault, nil
+	case "read committed":
+		return sql.LevelReadCommitted, nil
+	case "repeatable read":
+		return sql.LevelRepeatableRead, nil
+	case "serializable":
+		return sql.LevelSerializable, nil
+	}
+
+	return sql.LevelDefault, fmt.Errorf("%w: %s", errUnknownIsolationLevel, level)
+}
+
+// WithTx runs fn in a transaction with p.TxOptions, the methods of tx are bound to it.
+// The transaction is committed if fn returns nil, otherwise rolled back.
+// After a serialization failure fn is run again in a new transaction, so it must not have other side effects.
+// Inside another transaction fn joins it.
+func (p Postgres) WithTx(ctx context.Context, fn func(tx Postgres) error) error {
+	return p.WithTxOptions(ctx, p.TxOptions, fn)
+}
+
+func (p Postgres) WithTxOptions(ctx context.Context, opts TxOptions, fn func(tx Postgres) error) error {
+	if p.tx == nil {
+		p.tx = contextTx(ctx)
+	}
+
+	if p.tx != nil {
+		return fn(p)
+	}
+
+	var err error
+
+	for attempt := 0; attempt <= opts.MaxRetries; attempt++ {
+		if attempt > 0 {
+			p.Logger.Warnf("tx attempt %d: %v", attempt+1, err)
+
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("%w: %s", ctx.Err(), err.Error())
+			case <-time.After(delayBetweenTxAttempts * time.Duration(attempt)):
+			}
+		}
+
+		if err = p.runTx(ctx, opts, fn); err == nil || !db.IsSerializationError(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+func (p Postgres) runTx(ctx context.Context, opts TxOptions, fn func(tx Postgres) error) error {
+	tx, err := p.Pool.BeginTx(ctx, &sql.TxOptions{Isolation: opts.Isolation})
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+
+	defer p.rollback(tx)
+
+	p.tx = tx
+
+	if err := fn(p); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	return nil
+}
+
+// Atomic runs fn in a transaction as WithTx, the methods called with the context of fn join it.
+// The decorators of the storage make their writes a part of the change with it.
+func (p Postgres) Atomic(ctx context.Context, fn func(ctx context.Context) error) error {
+	return p.WithTx(ctx, func(tx Postgres) error {
+		return fn(context.WithValue(ctx, txKey{}, tx.tx))
+	})
+}
+
+// conn the transaction if the methods are called in WithTx or in Atomic, otherwise the pool.
+func (p Postgres) conn(ctx context.Context) querier {
+	if p.tx != nil {
+		return p.tx
+	}
+
+	if tx := contextTx(ctx); tx != nil {
+		return tx
+	}
+
+	return p.Pool
+}
+
+func contextTx(ctx context.Context) *sql.Tx {
+	tx, _ := ctx.Value(txKey{}).(*sql.Tx)
+
+	return tx
+}
+
+func (p Postgres) rollback(tx *sql.Tx) {
+	if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+		p.Logger.Errorf("rollback: %v", err)
+	}
+}
@@ -0,0 +1,245 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIsolationLevel(t *testing.T) {
+	cases := []struct {
+		level    string
+		expected sql.IsolationLevel
+		wantErr  bool
+	}{
+		{"", sql.LevelDefault, false},
+		{"read committed", sql.LevelReadCommitted, false},
+		{"Repeatable Read", sql.LevelRepeatableRead, false},
+		{"serializable", sql.LevelSerializable, false},
+		{"read uncommitted", sql.LevelDefault, true},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.level, func(t *testing.T) {
+			level, err := ParseIsolationLevel(tt.level)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, errUnknownIsolationLevel)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, level)
+		})
+	}
+}
+
+func TestWithTxJoinsOuterTx(t *testing.T) {
+	outer := Postgres{tx: &sql.Tx{}}
+
+	err := outer.WithTx(context.Background(), func(tx Postgres) error {
+		assert.Same(t, outer.tx, tx.tx)
+
+		return ErrTaskNotFound
+	})
+
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+}
+
+func TestAtomicJoinedByMethods(t *testing.T) {
+	p := txTestPostgres(t)
+
+	err := p.Atomic(context.Background(), func(ctx context.Context) error {
+		tx := contextTx(ctx)
+		require.NotNil(t, tx)
+		assert.Same(t, tx, p.conn(ctx))
+
+		return p.WithTx(ctx, func(inner Postgres) error {
+			assert.Same(t, tx, inner.tx)
+
+			return ErrTaskNotFound
+		})
+	})
+
+	assert.ErrorIs(t, err, ErrTaskNotFound)
+}
+
+func TestSQLiteAtomic(t *testing.T) {
+	s := testSQLite(t)
+	ctx := context.Background()
+
+	_, err := s.CreateNewUser(ctx, "atomic", "password")
+	require.NoError(t, err)
+
+	// The pool has one connection, the methods would wait for it outside of the transaction.
+	err = s.Atomic(ctx, func(ctx context.Context) error {
+		if _, err := s.CreateTask(ctx, "atomic", "password", "rolled back"); err != nil {
+			return err
+		}
+
+		if _, err := s.DeleteTasks(ctx, "atomic", "password"); err != nil {
+			return err
+		}
+
+		return ErrOperationRolledBack
+	})
+	assert.ErrorIs(t, err, ErrOperationRolledBack)
+
+	trash, err := s.GetTrash(ctx, "atomic", "password")
+	require.NoError(t, err)
+	assert.Empty(t, trash)
+
+	require.NoError(t, s.Atomic(ctx, func(ctx context.Context) error {
+		_, err := s.CreateTask(ctx, "atomic", "password", "committed")
+
+		return err
+	}))
+
+	tasks, err := s.GetTasks(ctx, "atomic", "password")
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "committed", tasks[0].Title)
+}
+
+// txTestConnector opens the connections, which only begin the transactions, to run WithTx without a database.
+type txTestConnector struct{}
+
+func (c txTestConnector) Connect(context.Context) (driver.Conn, error) {
+	return txTestConn{}, nil
+}
+
+func (c txTestConnector) Driver() driver.Driver {
+	return nil
+}
+
+type txTestConn struct{}
+
+func (c txTestConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c txTestConn) Close() error {
+	return nil
+}
+
+func (c txTestConn) Begin() (driver.Tx, error) {
+	return c, nil
+}
+
+func (c txTestConn) Commit() error {
+	return nil
+}
+
+func (c txTestConn) Rollback() error {
+	return nil
+}
+
+func TestWithTxOptionsRetries(t *testing.T) {
+	errSerialization := &pq.Error{Code: "40001"}
+
+	cases := []struct {
+		name          string
+		maxRetries    int
+		errs          []error
+		expectedCalls int
+		expectedErr   error
+	}{
+		{
+			name:          "committed",
+			maxRetries:    3,
+			expectedCalls: 1,
+		},
+		{
+			name:          "retried",
+			maxRetries:    3,
+			errs:          []error{errSerialization, errSerialization},
+			expectedCalls: 3,
+		},
+		{
+			name:          "deadlock retried",
+			maxRetries:    1,
+			errs:          []error{&pq.Error{Code: "40P01"}},
+			expectedCalls: 2,
+		},
+		{
+			name:          "retries exhausted",
+			maxRetries:    2,
+			errs:          []error{errSerialization, errSerialization, errSerialization, errSerialization},
+			expectedCalls: 3,
+			expectedErr:   errSerialization,
+		},
+		{
+			name:          "not retryable",
+			maxRetries:    3,
+			errs:          []error{ErrTaskNotFound},
+			expectedCalls: 1,
+			expectedErr:   ErrTaskNotFound,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			p := txTestPostgres(t)
+
+			var calls int
+
+			err := p.WithTxOptions(context.Background(), TxOptions{MaxRetries: tt.maxRetries}, func(tx Postgres) error {
+				calls++
+
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+
+				return nil
+			})
+
+			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.Equal(t, tt.expectedCalls, calls)
+		})
+	}
+}
+
+func TestWithTxOptionsCanceled(t *testing.T) {
+	p := txTestPostgres(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls int
+
+	err := p.WithTxOptions(ctx, TxOptions{MaxRetries: 3}, func(tx Postgres) error {
+		calls++
+
+		cancel()
+
+		return &pq.Error{Code: "40001"}
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, calls)
+}
+
+func txTestPostgres(t *testing.T) Postgres {
+	t.Helper()
+
+	pool := sql.OpenDB(txTestConnector{})
+	t.Cleanup(func() {
+		require.NoError(t, pool.Close())
+	})
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	return Postgres{
+		Pool:   pool,
+		Logger: logger,
+	}
+}
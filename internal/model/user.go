@@ -2,6 +2,8 @@ package model
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -22,7 +24,7 @@ func (p Postgres) CreateNewUser(ctx context.Context, username string, password s
 	var userID int
 
 	//nolint:execinquery
-	if err := p.Pool.QueryRowContext(ctx, `
+	if err := p.conn(ctx).QueryRowContext(ctx, `
 		INSERT INTO
 			auth(username, password)
 		VALUES
@@ -49,7 +51,7 @@ func (p Postgres) DeleteUser(ctx context.Context, userID int) error {
 	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
 	defer cancel()
 
-	res, err := p.Pool.ExecContext(ctx, `
+	res, err := p.conn(ctx).ExecContext(ctx, `
 		DELETE FROM
 		    auth
 		WHERE
@@ -72,3 +74,138 @@ func (p Postgres) DeleteUser(ctx context.Context, userID int) error {
 
 	return nil
 }
+
+// GetUsers without passwords, ordered by ID.
+func (p Postgres) GetUsers(ctx context.Context) ([]User, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	rows, err := p.conn(ctx).QueryContext(ctx, `
+		SELECT
+			user_id, username
+		FROM
+		    auth
+		ORDER BY
+		    user_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			p.Logger.Errorf("get users: %v", err)
+		}
+	}()
+
+	var users []User
+
+	for rows.Next() {
+		var user User
+
+		if err := rows.Scan(
+			&user.ID,
+			&user.Username,
+		); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+
+		users = append(users, user)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("scan rows: %w", rows.Err())
+	}
+
+	return users, nil
+}
+
+func (p Postgres) GetUserID(ctx context.Context, username string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	var userID int
+
+	if err := p.conn(ctx).QueryRowContext(ctx, `
+		SELECT
+			user_id
+		FROM
+		    auth
+		WHERE
+		    username = $1
+	`,
+		username,
+	).Scan(
+		&userID,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", username, ErrUserNotFound)
+		}
+
+		return 0, fmt.Errorf("query row: %w", err)
+	}
+
+	return userID, nil
+}
+
+func (p Postgres) UpdateUserPassword(ctx context.Context, username, password string) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	res, err := p.conn(ctx).ExecContext(ctx, `
+		UPDATE
+		    auth
+		SET
+		    password = $2
+		WHERE
+		    username = $1
+	`,
+		username,
+		password,
+	)
+	if err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	if rowsAffected != 1 {
+		return fmt.Errorf("%s: rows affected %d: %w", username, rowsAffected, ErrUserNotFound)
+	}
+
+	return nil
+}
+
+// GetUserIDByCredentials ErrUserNotFound if the username or the password is wrong.
+func (p Postgres) GetUserIDByCredentials(ctx context.Context, username, password string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	var userID int
+
+	if err := p.conn(ctx).QueryRowContext(ctx, `
+		SELECT
+			user_id
+		FROM
+		    auth
+		WHERE
+		    username = $1 AND
+		    password = $2
+	`,
+		username,
+		password,
+	).Scan(
+		&userID,
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", username, ErrUserNotFound)
+		}
+
+		return 0, fmt.Errorf("query row: %w", err)
+	}
+
+	return userID, nil
+}
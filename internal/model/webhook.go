@@ -0,0 +1,601 @@
+package model
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// webhookDisabledError the error of the pending deliveries of the disabled webhook.
+const webhookDisabledError = "webhook disabled"
+
+type Webhook struct {
+	ID     int      `json:"webhookId"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// Secret the key of the signature of the payload, it is not returned after the webhook is created.
+	Secret string `json:"-"`
+	// Active false - disabled after too many failed attempts in a row.
+	Active bool `json:"active"`
+	// Failures the failed attempts in a row.
+	Failures int       `json:"failures"`
+	Created  time.Time `json:"created"`
+}
+
+type WebhookDelivery struct {
+	ID        int    `json:"deliveryId"`
+	WebhookID int    `json:"webhookId"`
+	EventID   string `json:"eventId"`
+	Event     string `json:"event"`
+	Status    string `json:"status"`
+	Attempts  int    `json:"attempts"`
+	// ResponseStatus of the last attempt, 0 - no response.
+	ResponseStatus int       `json:"responseStatus,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	NextAttempt    time.Time `json:"nextAttempt"`
+	Created        time.Time `json:"created"`
+
+	// URL, Secret and Payload are set only by ClaimWebhookDeliveries.
+	URL     string `json:"-"`
+	Secret  string `json:"-"`
+	Payload []byte `json:"-"`
+}
+
+// WebhookAttempt the result of sending the delivery.
+type WebhookAttempt struct {
+	DeliveryID int
+	// ClaimedUntil the NextAttempt of the claimed delivery, the attempt is not saved if the lease expired and
+	// the delivery was claimed again.
+	ClaimedUntil   time.Time
+	Succeeded      bool
+	ResponseStatus int
+	Error          string
+	// RetryAfter 0 - the failed delivery is not retried.
+	RetryAfter time.Duration
+	// DisableAfter the webhook is disabled after the failed attempts in a row, 0 - never.
+	DisableAfter int
+}
+
+func (a WebhookAttempt) status() string {
+	switch {
+	case a.Succeeded:
+		return WebhookDeliverySucceeded
+	case a.RetryAfter > 0:
+		return WebhookDeliveryPending
+	}
+
+	return WebhookDeliveryFailed
+}
+
+// CreateWebhook userID 0 - the webhook of the manager, it gets the events of all users.
+func (p Postgres) CreateWebhook(ctx context.Context, userID int, webhook Webhook) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	var webhookID int
+
+	//nolint:execinquery
+	if err := p.conn(ctx).QueryRowContext(ctx, `
+		INSERT INTO
+			webhook(user_id, url, secret, events, active, failures, created)
+		VALUES
+		    (NULLIF($1, 0), $2, $3, $4, true, 0, now())
+		RETURNING
+		    webhook_id
+	`,
+		userID,
+		webhook.URL,
+		webhook.Secret,
+		strings.Join(webhook.Events, ","),
+	).Scan(
+		&webhookID,
+	); err != nil {
+		return 0, fmt.Errorf("query row: %w", err)
+	}
+
+	return webhookID, nil
+}
+
+// GetWebhooks of the user, userID 0 - of the manager. Ordered by ID.
+func (p Postgres) GetWebhooks(ctx context.Context, userID int) ([]Webhook, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	rows, err := p.conn(ctx).QueryContext(ctx, `
+		SELECT
+			webhook_id, url, events, active, failures, created
+		FROM
+		    webhook
+		WHERE
+		    COALESCE(user_id, 0) = $1
+		ORDER BY
+		    webhook_id
+	`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			p.Logger.Errorf("get webhooks: %v", err)
+		}
+	}()
+
+	var webhooks []Webhook
+
+	for rows.Next() {
+		var (
+			webhook Webhook
+			events  string
+		)
+
+		if err := rows.Scan(
+			&webhook.ID,
+			&webhook.URL,
+			&events,
+			&webhook.Active,
+			&webhook.Failures,
+			&webhook.Created,
+		); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+
+		webhook.Events = strings.Split(events, ",")
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("scan rows: %w", rows.Err())
+	}
+
+	return webhooks, nil
+}
+
+// DeleteWebhook with its deliveries.
+func (p Postgres) DeleteWebhook(ctx context.Context, userID, webhookID int) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	res, err := p.conn(ctx).ExecContext(ctx, `
+		DELETE FROM
+		    webhook
+		WHERE
+		    COALESCE(user_id, 0) = $1 AND
+		    webhook_id = $2
+	`,
+		userID,
+		webhookID,
+	)
+	if err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return webhookAffectedError(res, webhookID)
+}
+
+// EnableWebhook the disabled webhook gets the events again, the failures are reset.
+// The deliveries failed while it was disabled are not retried.
+func (p Postgres) EnableWebhook(ctx context.Context, userID, webhookID int) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	res, err := p.conn(ctx).ExecContext(ctx, `
+		UPDATE
+		    webhook
+		SET
+		    active = true,
+		    failures = 0
+		WHERE
+		    COALESCE(user_id, 0) = $1 AND
+		    webhook_id = $2
+	`,
+		userID,
+		webhookID,
+	)
+	if err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	return webhookAffectedError(res, webhookID)
+}
+
+// GetWebhookDeliveries the last deliveries of the webhook, the newest first.
+func (p Postgres) GetWebhookDeliveries(ctx context.Context, userID, webhookID, limit int) ([]WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	var exists bool
+
+	if err := p.conn(ctx).QueryRowContext(ctx, `
+		SELECT
+			EXISTS(SELECT 1 FROM webhook WHERE COALESCE(user_id, 0) = $1 AND webhook_id = $2)
+	`,
+		userID,
+		webhookID,
+	).Scan(
+		&exists,
+	); err != nil {
+		return nil, fmt.Errorf("query row: %w", err)
+	}
+
+	if !exists {
+		return nil, fmt.Errorf("webhookId %d: %w", webhookID, ErrWebhookNotFound)
+	}
+
+	rows, err := p.conn(ctx).QueryContext(ctx, `
+		SELECT
+			delivery_id, webhook_id, event_id, event, status, attempts, response_status, error, next_attempt, created
+		FROM
+		    webhook_delivery
+		WHERE
+		    webhook_id = $1
+		ORDER BY
+		    delivery_id DESC
+		LIMIT $2
+	`,
+		webhookID,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			p.Logger.Errorf("get webhook deliveries: %v", err)
+		}
+	}()
+
+	var deliveries []WebhookDelivery
+
+	for rows.Next() {
+		var (
+			delivery       WebhookDelivery
+			responseStatus sql.NullInt64
+			deliveryErr    sql.NullString
+		)
+
+		if err := rows.Scan(
+			&delivery.ID,
+			&delivery.WebhookID,
+			&delivery.EventID,
+			&delivery.Event,
+			&delivery.Status,
+			&delivery.Attempts,
+			&responseStatus,
+			&deliveryErr,
+			&delivery.NextAttempt,
+			&delivery.Created,
+		); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+
+		delivery.ResponseStatus = int(responseStatus.Int64)
+		delivery.Error = deliveryErr.String
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("scan rows: %w", rows.Err())
+	}
+
+	return deliveries, nil
+}
+
+// EnqueueWebhookEvent creates the deliveries of the event to the active webhooks of the user and of the manager
+// subscribed to it. Returns the number of deliveries.
+func (p Postgres) EnqueueWebhookEvent(ctx context.Context, event Event) (int64, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return 0, fmt.Errorf("marshal event: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	res, err := p.conn(ctx).ExecContext(ctx, `
+		INSERT INTO
+			webhook_delivery(webhook_id, event_id, event, payload, status, attempts, next_attempt, created)
+		SELECT
+		    webhook_id, $2, $3, $4, $5, 0, now(), now()
+		FROM
+		    webhook
+		WHERE
+		    active AND
+		    (user_id = $1 OR user_id IS NULL) AND
+		    ',' || events || ',' LIKE '%,' || $3 || ',%'
+	`,
+		event.UserID,
+		event.ID,
+		event.Type,
+		string(payload),
+		WebhookDeliveryPending,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("exec: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// ClaimWebhookDeliveries the pending deliveries due to be sent, oldest first. They are not claimed again
+// for the lease, so that other instances do not send them at the same time.
+func (p Postgres) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	rows, err := p.conn(ctx).QueryContext(ctx, `
+		UPDATE
+		    webhook_delivery d
+		SET
+		    next_attempt = now() + make_interval(secs => $3)
+		FROM
+		    webhook w
+		WHERE
+		    w.webhook_id = d.webhook_id AND
+		    d.delivery_id IN (
+				SELECT
+				    dd.delivery_id
+				FROM
+				    webhook_delivery dd
+				JOIN
+				    webhook ww USING (webhook_id)
+				WHERE
+				    dd.status = $1 AND
+				    dd.next_attempt <= now() AND
+				    ww.active
+				ORDER BY
+				    dd.next_attempt
+				LIMIT $2
+				FOR UPDATE OF dd SKIP LOCKED
+			)
+		RETURNING
+		    d.delivery_id, d.webhook_id, d.event_id, d.event, d.status, d.attempts, d.payload, d.next_attempt,
+		    d.created, w.url, w.secret
+	`,
+		WebhookDeliveryPending,
+		limit,
+		lease.Seconds(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	defer func() {
+		if err := rows.Close(); err != nil {
+			p.Logger.Errorf("claim webhook deliveries: %v", err)
+		}
+	}()
+
+	return scanClaimedWebhookDeliveries(rows)
+}
+
+// SaveWebhookAttempt the webhook is disabled after attempt.DisableAfter failed attempts in a row,
+// then its pending deliveries fail. The attempt of the delivery claimed again after its lease is not saved.
+func (p Postgres) SaveWebhookAttempt(ctx context.Context, attempt WebhookAttempt) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	return p.WithTx(ctx, func(tx Postgres) error {
+		var webhookID int
+
+		if err := tx.conn(ctx).QueryRowContext(ctx, `
+			UPDATE
+			    webhook_delivery
+			SET
+			    status = $2,
+			    attempts = attempts + 1,
+			    response_status = NULLIF($3, 0),
+			    error = NULLIF($4, ''),
+			    next_attempt = now() + make_interval(secs => $5)
+			WHERE
+			    delivery_id = $1 AND
+			    next_attempt = $6
+			RETURNING
+			    webhook_id
+		`,
+			attempt.DeliveryID,
+			attempt.status(),
+			attempt.ResponseStatus,
+			attempt.Error,
+			attempt.RetryAfter.Seconds(),
+			attempt.ClaimedUntil,
+		).Scan(
+			&webhookID,
+		); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			}
+
+			return fmt.Errorf("query row: %w", err)
+		}
+
+		return saveWebhookFailures(ctx, tx.conn(ctx), webhookID, attempt, func(notification Notification) error {
+			if _, err := tx.CreateNotification(ctx, notification); err != nil {
+				return fmt.Errorf("create notification: %w", err)
+			}
+
+			return nil
+		})
+	})
+}
+
+// PurgeWebhookDeliveries deletes the sent and failed deliveries created before.
+func (p Postgres) PurgeWebhookDeliveries(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(p.QueryTimeout))
+	defer cancel()
+
+	res, err := p.conn(ctx).ExecContext(ctx, `
+		DELETE FROM
+		    webhook_delivery
+		WHERE
+		    status <> $1 AND
+		    created < $2
+	`,
+		WebhookDeliveryPending,
+		before,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("exec: %w", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// saveWebhookFailures a success resets the failures, the webhook with too many is disabled.
+// notify creates the notification of the owner if the attempt disabled the webhook.
+func saveWebhookFailures(
+	ctx context.Context, q querier, webhookID int, attempt WebhookAttempt, notify func(Notification) error,
+) error {
+	if attempt.Succeeded {
+		if _, err := q.ExecContext(ctx, `
+			UPDATE
+			    webhook
+			SET
+			    failures = 0
+			WHERE
+			    webhook_id = $1
+		`,
+			webhookID,
+		); err != nil {
+			return fmt.Errorf("exec: %w", err)
+		}
+
+		return nil
+	}
+
+	var (
+		active   bool
+		failures int
+		userID   int
+		url      string
+	)
+
+	if err := q.QueryRowContext(ctx, `
+		UPDATE
+		    webhook
+		SET
+		    failures = failures + 1,
+		    active = active AND ($2 = 0 OR failures + 1 < $2)
+		WHERE
+		    webhook_id = $1
+		RETURNING
+		    active, failures, COALESCE(user_id, 0), url
+	`,
+		webhookID,
+		attempt.DisableAfter,
+	).Scan(
+		&active,
+		&failures,
+		&userID,
+		&url,
+	); err != nil {
+		return fmt.Errorf("query row: %w", err)
+	}
+
+	if active {
+		return nil
+	}
+
+	if _, err := q.ExecContext(ctx, `
+		UPDATE
+		    webhook_delivery
+		SET
+		    status = $2,
+		    error = $3
+		WHERE
+		    webhook_id = $1 AND
+		    status = $4
+	`,
+		webhookID,
+		WebhookDeliveryFailed,
+		webhookDisabledError,
+		WebhookDeliveryPending,
+	); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	// The attempts of the deliveries claimed before are saved after, the webhook is disabled by the first one.
+	// The webhooks of the manager have no owner to notify.
+	if failures != attempt.DisableAfter || userID == 0 {
+		return nil
+	}
+
+	return notify(webhookDisabledNotification(userID, webhookID, url, failures))
+}
+
+func scanClaimedWebhookDeliveries(rows *sql.Rows) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+
+	for rows.Next() {
+		var (
+			delivery WebhookDelivery
+			payload  string
+		)
+
+		if err := rows.Scan(
+			&delivery.ID,
+			&delivery.WebhookID,
+			&delivery.EventID,
+			&delivery.Event,
+			&delivery.Status,
+			&delivery.Attempts,
+			&payload,
+			&delivery.NextAttempt,
+			&delivery.Created,
+			&delivery.URL,
+			&delivery.Secret,
+		); err != nil {
+			return nil, fmt.Errorf("scan row: %w", err)
+		}
+
+		delivery.Payload = []byte(payload)
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("scan rows: %w", rows.Err())
+	}
+
+	return deliveries, nil
+}
+
+func webhookAffectedError(res sql.Result, webhookID int) error {
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+
+	if rowsAffected != 1 {
+		return fmt.Errorf("webhookId %d: rows affected %d: %w", webhookID, rowsAffected, ErrWebhookNotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+const (
+	publicDialTimeout   = 30 * time.Second
+	publicDialKeepAlive = 30 * time.Second
+	publicMaxRedirects  = 10
+)
+
+// ErrPrivateAddress the requests to the URLs of the users must not reach the server or its network.
+var ErrPrivateAddress = errors.New("private address")
+
+var errTooManyRedirects = errors.New("too many redirects")
+
+// sharedAddressSpace RFC 6598, the carrier-grade NAT and some metadata services of the clouds.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// PublicIP false for the loopback, private(RFC 1918, RFC 4193), link-local, shared, multicast and unspecified
+// addresses, the IPv4-mapped IPv6 ones included.
+func PublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() && !ip.IsUnspecified() && !sharedAddressSpace.Contains(ip)
+}
+
+// CheckPublicHost every address of the host must be public, the IP is not resolved.
+func CheckPublicHost(ctx context.Context, host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if !PublicIP(ip) {
+			return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+		}
+
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("lookup %s: %w", host, err)
+	}
+
+	for _, addr := range addrs {
+		if !PublicIP(addr.IP) {
+			return fmt.Errorf("%w: %s: %s", ErrPrivateAddress, host, addr.IP)
+		}
+	}
+
+	return nil
+}
+
+// PublicDialControl the Control of net.Dialer, the address is checked after the resolution, just before the
+// connection: a name resolving to a public address on the check and to a private one later is refused.
+func PublicDialControl(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("split host port: %w", err)
+	}
+
+	if ip := net.ParseIP(host); ip == nil || !PublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, host)
+	}
+
+	return nil
+}
+
+// NewPublicHTTPClient the client of the requests to the URLs of the users, only the public addresses are
+// connected. The redirects are followed only to the public hosts, the proxies of the environment are not used:
+// the proxy would connect to the private address itself.
+func NewPublicHTTPClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   publicDialTimeout,
+		KeepAlive: publicDialKeepAlive,
+		Control:   PublicDialControl,
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone() //nolint:forcetypeassert
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= publicMaxRedirects {
+				return errTooManyRedirects
+			}
+
+			return CheckPublicHost(req.Context(), req.URL.Hostname())
+		},
+	}
+}
@@ -0,0 +1,74 @@
+package security
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicIP(t *testing.T) {
+	cases := []struct {
+		ip       string
+		expected bool
+	}{
+		{ip: "93.184.216.34", expected: true},
+		{ip: "2606:2800:220:1:248:1893:25c8:1946", expected: true},
+		{ip: "127.0.0.1", expected: false},
+		{ip: "::1", expected: false},
+		{ip: "10.0.0.1", expected: false},
+		{ip: "172.16.0.1", expected: false},
+		{ip: "192.168.1.1", expected: false},
+		{ip: "fd00::1", expected: false},
+		{ip: "169.254.169.254", expected: false},
+		{ip: "fe80::1", expected: false},
+		{ip: "100.100.100.200", expected: false},
+		{ip: "0.0.0.0", expected: false},
+		{ip: "224.0.0.1", expected: false},
+		{ip: "::ffff:127.0.0.1", expected: false},
+		{ip: "::ffff:10.0.0.1", expected: false},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.ip, func(t *testing.T) {
+			assert.Equal(t, tt.expected, PublicIP(net.ParseIP(tt.ip)))
+		})
+	}
+}
+
+func TestCheckPublicHost(t *testing.T) {
+	assert.NoError(t, CheckPublicHost(context.Background(), "93.184.216.34"))
+	assert.ErrorIs(t, CheckPublicHost(context.Background(), "169.254.169.254"), ErrPrivateAddress)
+	assert.ErrorIs(t, CheckPublicHost(context.Background(), "localhost"), ErrPrivateAddress)
+}
+
+func TestNewPublicHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(server.Close)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	res, err := NewPublicHTTPClient().Do(req)
+	if res != nil {
+		res.Body.Close()
+	}
+
+	assert.ErrorIs(t, err, ErrPrivateAddress)
+}
+
+func TestNewPublicHTTPClientRedirect(t *testing.T) {
+	client := NewPublicHTTPClient()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://127.0.0.1/internal", nil)
+	require.NoError(t, err)
+
+	via, err := http.NewRequestWithContext(context.Background(), http.MethodGet, "http://93.184.216.34/", nil)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, client.CheckRedirect(req, []*http.Request{via}), ErrPrivateAddress)
+}
@@ -0,0 +1,88 @@
+package grpcsrv
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"taskmanager/internal/model"
+)
+
+// The types of the errors of /api/v1 in the messages of the statuses.
+const (
+	typeParameterRequired     = "PARAMETER_REQUIRED"
+	typeParametersRequired    = "PARAMETERS_REQUIRED"
+	typeRevisionNotFound      = "REVISION_NOT_FOUND"
+	typeTaskAlreadyExists     = "TASK_ALREADY_EXISTS"
+	typeTaskNotFound          = "TASK_NOT_FOUND"
+	typeUsernameAlreadyExists = "USERNAME_ALREADY_EXISTS"
+	typeUserNotFound          = "USER_NOT_FOUND"
+	typeTaskVersionMismatch   = "TASK_VERSION_MISMATCH"
+	typeOperationRolledBack   = "OPERATION_ROLLED_BACK"
+	typeInternalError         = "INTERNAL"
+)
+
+// internalError the client gets the status without the cause, the cause is logged.
+type internalError struct {
+	comment string
+	err     error
+}
+
+func (e internalError) Error() string {
+	return fmt.Sprintf("%s: %s: %v", typeInternalError, e.comment, e.err)
+}
+
+func (e internalError) Unwrap() error {
+	return e.err
+}
+
+func (e internalError) GRPCStatus() *status.Status {
+	return status.Newf(codes.Internal, "%s: %s", typeInternalError, e.comment)
+}
+
+// newStatus the message is the type of the error of /api/v1 and the comment.
+func newStatus(code codes.Code, errorType, comment string) *status.Status {
+	return status.Newf(code, "%s: %s", errorType, comment)
+}
+
+func invalidArgument(errorType, comment string) error {
+	return newStatus(codes.InvalidArgument, errorType, comment).Err()
+}
+
+// statusFromErr the error of the storage, like the handlers write it. comment of the internal error.
+func statusFromErr(err error, taskID int, comment string) error {
+	s, ok := storageErrStatus(err, taskID)
+	if !ok {
+		return internalError{comment: comment, err: err}
+	}
+
+	return s.Err()
+}
+
+// storageErrStatus false - the error is internal.
+func storageErrStatus(err error, taskID int) (*status.Status, bool) {
+	switch {
+	case errors.Is(err, model.ErrOperationRolledBack):
+		return newStatus(codes.Aborted, typeOperationRolledBack, "another operation failed"), true
+
+	case errors.Is(err, model.ErrUserNotFound):
+		return newStatus(codes.PermissionDenied, typeUserNotFound, "credentials"), true
+
+	case errors.Is(err, model.ErrTaskAlreadyExists):
+		return newStatus(codes.AlreadyExists, typeTaskAlreadyExists, "duplicate task"), true
+
+	case errors.Is(err, model.ErrTaskNotFound):
+		return newStatus(codes.NotFound, typeTaskNotFound, strconv.Itoa(taskID)), true
+
+	case errors.Is(err, model.ErrRevisionNotFound):
+		return newStatus(codes.NotFound, typeRevisionNotFound, strconv.Itoa(taskID)), true
+
+	case errors.Is(err, model.ErrVersionMismatch):
+		return newStatus(codes.FailedPrecondition, typeTaskVersionMismatch, strconv.Itoa(taskID)), true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,99 @@
+package grpcsrv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+
+	taskmanagerv1 "taskmanager/api/taskmanager/v1"
+	"taskmanager/internal/app"
+	"taskmanager/internal/handler"
+)
+
+var errForcedStop = errors.New("forced stop")
+
+type Conf struct {
+	Port            string
+	ManageUsername  string
+	ManagePassword  string
+	MaxShutdownTime int
+}
+
+// RunGRPCServer the gRPC API on its own port, stopped gracefully when the context is canceled.
+func (conf Conf) RunGRPCServer(
+	ctx context.Context, storage handler.Storage, metrics app.Metrics, logger *logrus.Logger,
+) error {
+	listener, err := net.Listen("tcp", ":"+conf.Port)
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+
+	server := conf.NewServer(storage, metrics, logger)
+
+	chErr := make(chan error, 1)
+
+	go func() {
+		logger.Infof("start grpc server: port %s", conf.Port)
+
+		if err := server.Serve(listener); err != nil {
+			chErr <- fmt.Errorf("serve: %w", err)
+		}
+	}()
+
+	if err := conf.waitingStopSignal(ctx, server, chErr); err != nil {
+		return fmt.Errorf("waiting server to stop: %w", err)
+	}
+
+	logger.Info("stop grpc server: ok")
+
+	return nil
+}
+
+// NewServer the services with the interceptors, without the listener.
+func (conf Conf) NewServer(storage handler.Storage, metrics app.Metrics, logger *logrus.Logger) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			recoverer(logger),
+			requestMetrics(metrics),
+			requestLogger(logger),
+			conf.authorization(),
+		),
+	)
+
+	taskmanagerv1.RegisterUserServiceServer(server, userService{storage: storage})
+	taskmanagerv1.RegisterTaskServiceServer(server, taskService{storage: storage})
+
+	return server
+}
+
+// waitingStopSignal the requests in progress are finished for MaxShutdownTime, then they are canceled.
+func (conf Conf) waitingStopSignal(ctx context.Context, server *grpc.Server, chErr chan error) error {
+	select {
+	case err := <-chErr:
+		return err
+
+	case <-ctx.Done():
+		stopped := make(chan struct{})
+
+		go func() {
+			server.GracefulStop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+
+		case <-time.After(time.Second * time.Duration(conf.MaxShutdownTime)):
+			server.Stop()
+
+			return errForcedStop
+		}
+	}
+
+	return nil
+}
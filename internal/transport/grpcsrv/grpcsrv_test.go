@@ -0,0 +1,261 @@
+package grpcsrv
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	taskmanagerv1 "taskmanager/api/taskmanager/v1"
+	"taskmanager/internal/app"
+	"taskmanager/internal/model"
+)
+
+const (
+	testManageUsername = "admin"
+	testManagePassword = "admin"
+)
+
+func newTestConn(t *testing.T) *grpc.ClientConn {
+	t.Helper()
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	// Not registered, the metrics of the tests are not global.
+	metrics := app.Metrics{
+		GRPCRequestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test"}, []string{"code", "method"}),
+	}
+
+	server := Conf{
+		ManageUsername: testManageUsername,
+		ManagePassword: testManagePassword,
+	}.NewServer(model.NewMemory(), metrics, logger)
+
+	listener := bufconn.Listen(1 << 20)
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return conn
+}
+
+func withBasicAuth(ctx context.Context, username, password string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, metadataAuthorization,
+		"Basic "+base64.StdEncoding.EncodeToString([]byte(username+":"+password)))
+}
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+
+	conn := newTestConn(t)
+
+	users := taskmanagerv1.NewUserServiceClient(conn)
+	tasks := taskmanagerv1.NewTaskServiceClient(conn)
+
+	manager := withBasicAuth(ctx, testManageUsername, testManagePassword)
+
+	_, err := users.CreateUser(ctx, &taskmanagerv1.CreateUserRequest{Username: "grpcuser", Password: "grpcpassword"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = users.CreateUser(withBasicAuth(ctx, testManageUsername, "wrong"),
+		&taskmanagerv1.CreateUserRequest{Username: "grpcuser", Password: "grpcpassword"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = users.CreateUser(manager, &taskmanagerv1.CreateUserRequest{Username: "grpcuser", Password: "1"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	created, err := users.CreateUser(manager,
+		&taskmanagerv1.CreateUserRequest{Username: "grpcuser", Password: "grpcpassword"})
+	require.NoError(t, err)
+	assert.Positive(t, created.GetUserId())
+
+	_, err = users.CreateUser(manager, &taskmanagerv1.CreateUserRequest{Username: "grpcuser", Password: "grpcpassword"})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	user := withBasicAuth(ctx, "grpcuser", "grpcpassword")
+
+	_, err = tasks.CreateTask(withBasicAuth(ctx, "grpcuser", "wrong"), &taskmanagerv1.CreateTaskRequest{Title: "first"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = tasks.CreateTask(user, &taskmanagerv1.CreateTaskRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	createdTask, err := tasks.CreateTask(user, &taskmanagerv1.CreateTaskRequest{Title: "first"})
+	require.NoError(t, err)
+
+	taskID := createdTask.GetTaskId()
+
+	got, err := tasks.GetTask(user, &taskmanagerv1.GetTaskRequest{TaskId: taskID})
+	require.NoError(t, err)
+	assert.Equal(t, "first", got.GetTask().GetTitle())
+	assert.False(t, got.GetTask().GetCompleted())
+	assert.Nil(t, got.GetTask().GetCompletedAt())
+
+	_, err = tasks.UpdateTask(user, &taskmanagerv1.UpdateTaskRequest{TaskId: taskID})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = tasks.UpdateTask(user, &taskmanagerv1.UpdateTaskRequest{
+		TaskId:    taskID,
+		Completed: proto.Bool(true),
+		Version:   got.GetTask().GetVersion() + 1,
+	})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = tasks.UpdateTask(user, &taskmanagerv1.UpdateTaskRequest{
+		TaskId:    taskID,
+		Completed: proto.Bool(true),
+		Version:   got.GetTask().GetVersion(),
+	})
+	require.NoError(t, err)
+
+	list, err := tasks.GetTasks(user, &taskmanagerv1.GetTasksRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.GetTasks(), 1)
+	assert.True(t, list.GetTasks()[0].GetCompleted())
+	assert.NotNil(t, list.GetTasks()[0].GetCompletedAt())
+
+	due := time.Date(2023, 5, 4, 7, 0, 0, 0, time.UTC)
+
+	_, err = tasks.UpdateTask(user, &taskmanagerv1.UpdateTaskRequest{
+		TaskId:     taskID,
+		Recurrence: proto.String("FREQ=SECONDLY"),
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = tasks.UpdateTask(user, &taskmanagerv1.UpdateTaskRequest{
+		TaskId:     taskID,
+		Due:        timestamppb.New(due),
+		Recurrence: proto.String("FREQ=WEEKLY"),
+	})
+	require.NoError(t, err)
+
+	got, err = tasks.GetTask(user, &taskmanagerv1.GetTaskRequest{TaskId: taskID})
+	require.NoError(t, err)
+	assert.Equal(t, due, got.GetTask().GetDue().AsTime())
+	assert.Equal(t, "FREQ=WEEKLY", got.GetTask().GetRecurrence())
+
+	_, err = tasks.UpdateTask(user, &taskmanagerv1.UpdateTaskRequest{
+		TaskId:     taskID,
+		RemoveDue:  true,
+		Recurrence: proto.String(""),
+	})
+	require.NoError(t, err)
+
+	got, err = tasks.GetTask(user, &taskmanagerv1.GetTaskRequest{TaskId: taskID})
+	require.NoError(t, err)
+	assert.Nil(t, got.GetTask().GetDue())
+	assert.Empty(t, got.GetTask().GetRecurrence())
+
+	history, err := tasks.GetTaskHistory(user, &taskmanagerv1.GetTaskHistoryRequest{TaskId: taskID})
+	require.NoError(t, err)
+	assert.NotEmpty(t, history.GetChanges())
+
+	_, err = tasks.DeleteTask(user, &taskmanagerv1.DeleteTaskRequest{TaskId: taskID})
+	require.NoError(t, err)
+
+	_, err = tasks.GetTask(user, &taskmanagerv1.GetTaskRequest{TaskId: taskID})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	trash, err := tasks.GetTrash(user, &taskmanagerv1.GetTrashRequest{})
+	require.NoError(t, err)
+	require.Len(t, trash.GetTasks(), 1)
+	assert.Equal(t, taskID, trash.GetTasks()[0].GetTask().GetId())
+
+	_, err = tasks.RestoreTask(user, &taskmanagerv1.RestoreTaskRequest{TaskId: taskID})
+	require.NoError(t, err)
+
+	_, err = tasks.GetTask(user, &taskmanagerv1.GetTaskRequest{TaskId: taskID})
+	require.NoError(t, err)
+
+	_, err = users.DeleteUser(manager, &taskmanagerv1.DeleteUserRequest{UserId: created.GetUserId() + 100})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = users.DeleteUser(manager, &taskmanagerv1.DeleteUserRequest{UserId: created.GetUserId()})
+	require.NoError(t, err)
+
+	_, err = tasks.CreateTask(user, &taskmanagerv1.CreateTaskRequest{Title: "second"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestServerBatchTasks(t *testing.T) {
+	ctx := context.Background()
+
+	conn := newTestConn(t)
+
+	_, err := taskmanagerv1.NewUserServiceClient(conn).CreateUser(
+		withBasicAuth(ctx, testManageUsername, testManagePassword),
+		&taskmanagerv1.CreateUserRequest{Username: "grpcuser", Password: "grpcpassword"})
+	require.NoError(t, err)
+
+	tasks := taskmanagerv1.NewTaskServiceClient(conn)
+	user := withBasicAuth(ctx, "grpcuser", "grpcpassword")
+
+	_, err = tasks.BatchTasks(user, &taskmanagerv1.BatchTasksRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// The invalid operation - the atomic batch is not run.
+	res, err := tasks.BatchTasks(user, &taskmanagerv1.BatchTasksRequest{
+		Atomic: true,
+		Operations: []*taskmanagerv1.BatchTaskOperation{
+			{Op: model.TaskOperationCreate, Title: proto.String("first")},
+			{Op: model.TaskOperationUpdate, TaskId: 1},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, res.GetResults(), 2)
+	assert.Equal(t, int32(codes.Aborted), res.GetResults()[0].GetCode())
+	assert.Equal(t, int32(codes.InvalidArgument), res.GetResults()[1].GetCode())
+
+	list, err := tasks.GetTasks(user, &taskmanagerv1.GetTasksRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, list.GetTasks())
+
+	res, err = tasks.BatchTasks(user, &taskmanagerv1.BatchTasksRequest{
+		Operations: []*taskmanagerv1.BatchTaskOperation{
+			{Op: model.TaskOperationCreate, Title: proto.String("first")},
+			{Op: model.TaskOperationDelete, TaskId: 1000},
+			{Op: model.TaskOperationCreate, Title: proto.String("second")},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, res.GetResults(), 3)
+	assert.Equal(t, int32(codes.OK), res.GetResults()[0].GetCode())
+	assert.Positive(t, res.GetResults()[0].GetTaskId())
+	assert.Equal(t, int32(codes.NotFound), res.GetResults()[1].GetCode())
+	assert.Equal(t, int32(codes.OK), res.GetResults()[2].GetCode())
+
+	list, err = tasks.GetTasks(user, &taskmanagerv1.GetTasksRequest{})
+	require.NoError(t, err)
+	assert.Len(t, list.GetTasks(), 2)
+}
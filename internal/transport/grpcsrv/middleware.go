@@ -0,0 +1,129 @@
+package grpcsrv
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	taskmanagerv1 "taskmanager/api/taskmanager/v1"
+	"taskmanager/internal/app"
+	"taskmanager/internal/security"
+)
+
+const metadataAuthorization = "authorization"
+
+type credentialsKey struct{}
+
+// credentials of the user of the request, the password is salted.
+type credentials struct {
+	username string
+	password string
+}
+
+// authorization the basic auth of the REST API: of the manager for UserService,
+// the credentials of the user are checked by the storage for TaskService.
+func (conf Conf) authorization() grpc.UnaryServerInterceptor {
+	userService := "/" + taskmanagerv1.UserService_ServiceDesc.ServiceName + "/"
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+		username, password, ok := basicAuth(ctx)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "basic auth required")
+		}
+
+		if strings.HasPrefix(info.FullMethod, userService) {
+			if subtle.ConstantTimeCompare([]byte(username), []byte(conf.ManageUsername)) != 1 ||
+				subtle.ConstantTimeCompare([]byte(password), []byte(conf.ManagePassword)) != 1 {
+				return nil, status.Error(codes.Unauthenticated, "manager credentials required")
+			}
+
+			return next(ctx, req)
+		}
+
+		return next(context.WithValue(ctx, credentialsKey{}, credentials{
+			username: username,
+			password: security.SaltPassword(password),
+		}), req)
+	}
+}
+
+// basicAuth of the metadata, parsed like the header of the REST API.
+func basicAuth(ctx context.Context) (string, string, bool) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	values := md.Get(metadataAuthorization)
+	if len(values) == 0 {
+		return "", "", false
+	}
+
+	r := http.Request{Header: http.Header{"Authorization": values}}
+
+	return r.BasicAuth()
+}
+
+func requestCredentials(ctx context.Context) credentials {
+	c, _ := ctx.Value(credentialsKey{}).(credentials)
+
+	return c
+}
+
+func requestMetrics(metrics app.Metrics) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+		res, err := next(ctx, req)
+
+		metrics.GRPCRequestsTotal.WithLabelValues(status.Code(err).String(), info.FullMethod).Inc()
+
+		return res, err
+	}
+}
+
+// requestLogger the failed requests, with the causes of the internal errors.
+func requestLogger(logger *logrus.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+
+		res, err := next(ctx, req)
+		if err != nil {
+			var addr string
+
+			if p, ok := peer.FromContext(ctx); ok {
+				addr = p.Addr.String()
+			}
+
+			logger.Errorf(
+				"[GRPC] %s | %21s | %12s | %s | %v",
+				status.Code(err),
+				addr,
+				time.Since(start),
+				info.FullMethod,
+				err,
+			)
+		}
+
+		return res, err
+	}
+}
+
+// recoverer the panic of a request is the internal error of it, like gin.Recovery.
+func recoverer(logger *logrus.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (res any, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Errorf("[GRPC] panic: %s: %v", info.FullMethod, r)
+
+				err = status.Errorf(codes.Internal, "%s: panic", typeInternalError)
+			}
+		}()
+
+		return next(ctx, req)
+	}
+}
@@ -0,0 +1,462 @@
+package grpcsrv
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	taskmanagerv1 "taskmanager/api/taskmanager/v1"
+	"taskmanager/internal/handler"
+	"taskmanager/internal/model"
+)
+
+// maxBatchOperations like /api/v1/tasks/batch.
+const maxBatchOperations = 100
+
+type taskService struct {
+	taskmanagerv1.UnimplementedTaskServiceServer
+
+	storage handler.TaskStorage
+}
+
+func (s taskService) CreateTask(
+	ctx context.Context, req *taskmanagerv1.CreateTaskRequest,
+) (*taskmanagerv1.CreateTaskResponse, error) {
+	if err := handler.ValidateTaskTitle(req.GetTitle()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	c := requestCredentials(ctx)
+
+	taskID, err := s.storage.CreateTask(ctx, c.username, c.password, req.GetTitle())
+	if err != nil {
+		return nil, statusFromErr(err, 0, "create task")
+	}
+
+	return &taskmanagerv1.CreateTaskResponse{
+		TaskId: int64(taskID),
+	}, nil
+}
+
+func (s taskService) GetTask(
+	ctx context.Context, req *taskmanagerv1.GetTaskRequest,
+) (*taskmanagerv1.GetTaskResponse, error) {
+	taskID, err := requestTaskID(req.GetTaskId())
+	if err != nil {
+		return nil, err
+	}
+
+	c := requestCredentials(ctx)
+
+	task, err := s.storage.GetTask(ctx, c.username, c.password, taskID)
+	if err != nil {
+		return nil, statusFromErr(err, taskID, "get task")
+	}
+
+	return &taskmanagerv1.GetTaskResponse{
+		Task: taskToProto(task),
+	}, nil
+}
+
+func (s taskService) UpdateTask(
+	ctx context.Context, req *taskmanagerv1.UpdateTaskRequest,
+) (*taskmanagerv1.UpdateTaskResponse, error) {
+	taskID, err := requestTaskID(req.GetTaskId())
+	if err != nil {
+		return nil, err
+	}
+
+	update := model.TaskUpdate{
+		Title:      req.Title,
+		Status:     req.Completed,
+		Recurrence: req.Recurrence,
+	}
+
+	switch {
+	case req.GetRemoveDue():
+		update.Due = &time.Time{}
+
+	case req.Due != nil:
+		due := req.GetDue().AsTime()
+		update.Due = &due
+	}
+
+	if update.Title == nil && update.Status == nil && update.Due == nil && update.Recurrence == nil {
+		return nil, invalidArgument(typeParametersRequired, "title, completed, due or recurrence")
+	}
+
+	if req.Title != nil {
+		if err := handler.ValidateTaskTitle(req.GetTitle()); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	if err := handler.ValidateTaskRecurrence(req.GetRecurrence()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	c := requestCredentials(ctx)
+
+	if err := s.storage.UpdateTask(ctx, c.username, c.password, taskID, int(req.GetVersion()), update); err != nil {
+		return nil, statusFromErr(err, taskID, "update task")
+	}
+
+	return &taskmanagerv1.UpdateTaskResponse{}, nil
+}
+
+func (s taskService) DeleteTask(
+	ctx context.Context, req *taskmanagerv1.DeleteTaskRequest,
+) (*taskmanagerv1.DeleteTaskResponse, error) {
+	taskID, err := requestTaskID(req.GetTaskId())
+	if err != nil {
+		return nil, err
+	}
+
+	c := requestCredentials(ctx)
+
+	deleteTask := s.storage.DeleteTask
+	if req.GetPermanent() {
+		deleteTask = s.storage.DeleteTaskPermanently
+	}
+
+	if err := deleteTask(ctx, c.username, c.password, taskID, int(req.GetVersion())); err != nil {
+		return nil, statusFromErr(err, taskID, "delete task")
+	}
+
+	return &taskmanagerv1.DeleteTaskResponse{}, nil
+}
+
+func (s taskService) RestoreTask(
+	ctx context.Context, req *taskmanagerv1.RestoreTaskRequest,
+) (*taskmanagerv1.RestoreTaskResponse, error) {
+	taskID, err := requestTaskID(req.GetTaskId())
+	if err != nil {
+		return nil, err
+	}
+
+	c := requestCredentials(ctx)
+
+	if err := s.storage.RestoreTask(ctx, c.username, c.password, taskID); err != nil {
+		return nil, statusFromErr(err, taskID, "restore task")
+	}
+
+	return &taskmanagerv1.RestoreTaskResponse{}, nil
+}
+
+func (s taskService) GetTaskHistory(
+	ctx context.Context, req *taskmanagerv1.GetTaskHistoryRequest,
+) (*taskmanagerv1.GetTaskHistoryResponse, error) {
+	taskID, err := requestTaskID(req.GetTaskId())
+	if err != nil {
+		return nil, err
+	}
+
+	c := requestCredentials(ctx)
+
+	changes, err := s.storage.GetTaskHistory(ctx, c.username, c.password, taskID)
+	if err != nil {
+		return nil, statusFromErr(err, taskID, "get task history")
+	}
+
+	res := &taskmanagerv1.GetTaskHistoryResponse{
+		Changes: make([]*taskmanagerv1.TaskChange, 0, len(changes)),
+	}
+
+	for _, change := range changes {
+		res.Changes = append(res.Changes, &taskmanagerv1.TaskChange{
+			Revision: int64(change.Revision),
+			Field:    change.Field,
+			Old:      change.Old,
+			New:      change.New,
+			Actor:    change.Actor,
+			Changed:  timestamppb.New(change.Changed),
+		})
+	}
+
+	return res, nil
+}
+
+func (s taskService) GetTaskRevision(
+	ctx context.Context, req *taskmanagerv1.GetTaskRevisionRequest,
+) (*taskmanagerv1.GetTaskRevisionResponse, error) {
+	taskID, revision, err := requestTaskRevision(req.GetTaskId(), req.GetRevision())
+	if err != nil {
+		return nil, err
+	}
+
+	c := requestCredentials(ctx)
+
+	task, err := s.storage.GetTaskRevision(ctx, c.username, c.password, taskID, revision)
+	if err != nil {
+		return nil, statusFromErr(err, taskID, "get task revision")
+	}
+
+	return &taskmanagerv1.GetTaskRevisionResponse{
+		Task: taskToProto(task),
+	}, nil
+}
+
+func (s taskService) RevertTask(
+	ctx context.Context, req *taskmanagerv1.RevertTaskRequest,
+) (*taskmanagerv1.RevertTaskResponse, error) {
+	taskID, revision, err := requestTaskRevision(req.GetTaskId(), req.GetRevision())
+	if err != nil {
+		return nil, err
+	}
+
+	c := requestCredentials(ctx)
+
+	if err := s.storage.RevertTask(ctx, c.username, c.password, taskID, revision); err != nil {
+		return nil, statusFromErr(err, taskID, "revert task")
+	}
+
+	return &taskmanagerv1.RevertTaskResponse{}, nil
+}
+
+func (s taskService) GetTasks(
+	ctx context.Context, _ *taskmanagerv1.GetTasksRequest,
+) (*taskmanagerv1.GetTasksResponse, error) {
+	c := requestCredentials(ctx)
+
+	tasks, err := s.storage.GetTasks(ctx, c.username, c.password)
+	if err != nil {
+		return nil, statusFromErr(err, 0, "get tasks")
+	}
+
+	res := &taskmanagerv1.GetTasksResponse{
+		Tasks: make([]*taskmanagerv1.Task, 0, len(tasks)),
+	}
+
+	for _, task := range tasks {
+		res.Tasks = append(res.Tasks, taskToProto(task))
+	}
+
+	return res, nil
+}
+
+func (s taskService) DeleteTasks(
+	ctx context.Context, req *taskmanagerv1.DeleteTasksRequest,
+) (*taskmanagerv1.DeleteTasksResponse, error) {
+	c := requestCredentials(ctx)
+
+	deleteTasks := s.storage.DeleteTasks
+	if req.GetPermanent() {
+		deleteTasks = s.storage.DeleteTasksPermanently
+	}
+
+	deleted, err := deleteTasks(ctx, c.username, c.password)
+	if err != nil {
+		return nil, statusFromErr(err, 0, "delete tasks")
+	}
+
+	return &taskmanagerv1.DeleteTasksResponse{
+		Deleted: int64(len(deleted)),
+	}, nil
+}
+
+func (s taskService) GetTrash(
+	ctx context.Context, _ *taskmanagerv1.GetTrashRequest,
+) (*taskmanagerv1.GetTrashResponse, error) {
+	c := requestCredentials(ctx)
+
+	tasks, err := s.storage.GetTrash(ctx, c.username, c.password)
+	if err != nil {
+		return nil, statusFromErr(err, 0, "get trash")
+	}
+
+	res := &taskmanagerv1.GetTrashResponse{
+		Tasks: make([]*taskmanagerv1.DeletedTask, 0, len(tasks)),
+	}
+
+	for _, task := range tasks {
+		res.Tasks = append(res.Tasks, &taskmanagerv1.DeletedTask{
+			Task:    taskToProto(task.Task),
+			Deleted: timestamppb.New(task.Deleted),
+		})
+	}
+
+	return res, nil
+}
+
+func (s taskService) RestoreTasks(
+	ctx context.Context, _ *taskmanagerv1.RestoreTasksRequest,
+) (*taskmanagerv1.RestoreTasksResponse, error) {
+	c := requestCredentials(ctx)
+
+	restored, err := s.storage.RestoreTasks(ctx, c.username, c.password)
+	if err != nil {
+		return nil, statusFromErr(err, 0, "restore tasks")
+	}
+
+	return &taskmanagerv1.RestoreTasksResponse{
+		Restored: int64(len(restored)),
+	}, nil
+}
+
+// BatchTasks the results are of each operation, the failed atomic batch is not an error of the request.
+func (s taskService) BatchTasks(
+	ctx context.Context, req *taskmanagerv1.BatchTasksRequest,
+) (*taskmanagerv1.BatchTasksResponse, error) {
+	if len(req.GetOperations()) == 0 || len(req.GetOperations()) > maxBatchOperations {
+		return nil, invalidArgument(typeParameterRequired,
+			fmt.Sprintf("operations: min 1, max %d", maxBatchOperations))
+	}
+
+	results := make([]*taskmanagerv1.BatchTaskOperationResult, len(req.GetOperations()))
+
+	var (
+		operations []model.TaskOperation
+		// indexes of the valid operations, to put their results in place.
+		indexes []int
+	)
+
+	for i, op := range req.GetOperations() {
+		operation, err := batchTaskOperation(op)
+		if err != nil {
+			results[i] = batchTaskOperationResult(status.Convert(err), op.GetTaskId())
+
+			continue
+		}
+
+		operations = append(operations, operation)
+		indexes = append(indexes, i)
+	}
+
+	// Like /api/v1/tasks/batch, the atomic batch with invalid operations is not run.
+	if req.GetAtomic() && len(operations) < len(req.GetOperations()) {
+		rolledBack, _ := storageErrStatus(model.ErrOperationRolledBack, 0)
+
+		for _, i := range indexes {
+			results[i] = batchTaskOperationResult(rolledBack, req.GetOperations()[i].GetTaskId())
+		}
+
+		return &taskmanagerv1.BatchTasksResponse{Results: results}, nil
+	}
+
+	c := requestCredentials(ctx)
+
+	opResults, err := s.storage.BatchTasks(ctx, c.username, c.password, operations, req.GetAtomic())
+	if err != nil {
+		return nil, internalError{comment: "batch tasks", err: err}
+	}
+
+	for i, res := range opResults {
+		var st *status.Status
+
+		if res.Err != nil {
+			var ok bool
+
+			if st, ok = storageErrStatus(res.Err, res.TaskID); !ok {
+				st = internalError{comment: "operation", err: res.Err}.GRPCStatus()
+			}
+		}
+
+		results[indexes[i]] = batchTaskOperationResult(st, int64(res.TaskID))
+	}
+
+	return &taskmanagerv1.BatchTasksResponse{Results: results}, nil
+}
+
+// batchTaskOperation the operation is checked like by /api/v1/tasks/batch.
+func batchTaskOperation(op *taskmanagerv1.BatchTaskOperation) (model.TaskOperation, error) {
+	if op.Title != nil {
+		if err := handler.ValidateTaskTitle(op.GetTitle()); err != nil {
+			return model.TaskOperation{}, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	operation := model.TaskOperation{
+		Op:        op.GetOp(),
+		TaskID:    int(op.GetTaskId()),
+		Version:   int(op.GetVersion()),
+		Permanent: op.GetPermanent(),
+	}
+
+	switch op.GetOp() {
+	case model.TaskOperationCreate:
+		if op.Title == nil {
+			return model.TaskOperation{}, invalidArgument(typeParameterRequired, "title")
+		}
+
+		operation.Title = op.GetTitle()
+
+		return operation, nil
+
+	case model.TaskOperationUpdate:
+		if op.Title == nil && op.Completed == nil {
+			return model.TaskOperation{}, invalidArgument(typeParametersRequired, "title or completed")
+		}
+
+		operation.Update = model.TaskUpdate{
+			Title:  op.Title,
+			Status: op.Completed,
+		}
+
+	case model.TaskOperationDelete:
+
+	default:
+		return model.TaskOperation{}, invalidArgument(typeParameterRequired, "op: create, update or delete")
+	}
+
+	if _, err := requestTaskID(op.GetTaskId()); err != nil {
+		return model.TaskOperation{}, err
+	}
+
+	return operation, nil
+}
+
+// batchTaskOperationResult st nil - the operation succeeded.
+func batchTaskOperationResult(st *status.Status, taskID int64) *taskmanagerv1.BatchTaskOperationResult {
+	return &taskmanagerv1.BatchTaskOperationResult{
+		Code:   int32(st.Code()),
+		TaskId: taskID,
+		Error:  st.Message(),
+	}
+}
+
+func requestTaskID(taskID int64) (int, error) {
+	if taskID < 1 {
+		return 0, invalidArgument(typeParameterRequired, "taskId")
+	}
+
+	return int(taskID), nil
+}
+
+func requestTaskRevision(taskID, revision int64) (int, int, error) {
+	id, err := requestTaskID(taskID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if revision < 1 {
+		return 0, 0, invalidArgument(typeParameterRequired, "revision")
+	}
+
+	return id, int(revision), nil
+}
+
+func taskToProto(t model.Task) *taskmanagerv1.Task {
+	task := &taskmanagerv1.Task{
+		Id:         int64(t.ID),
+		Title:      t.Title,
+		Completed:  t.Status,
+		Created:    timestamppb.New(t.Created),
+		Updated:    timestamppb.New(t.Updated),
+		Version:    int64(t.Version),
+		Recurrence: t.Recurrence,
+	}
+
+	if !t.Completed.IsZero() {
+		task.CompletedAt = timestamppb.New(t.Completed)
+	}
+
+	if t.Due != nil {
+		task.Due = timestamppb.New(*t.Due)
+	}
+
+	return task
+}
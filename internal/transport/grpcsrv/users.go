@@ -0,0 +1,60 @@
+package grpcsrv
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	taskmanagerv1 "taskmanager/api/taskmanager/v1"
+	"taskmanager/internal/handler"
+	"taskmanager/internal/model"
+	"taskmanager/internal/security"
+)
+
+type userService struct {
+	taskmanagerv1.UnimplementedUserServiceServer
+
+	storage handler.UserStorage
+}
+
+func (s userService) CreateUser(
+	ctx context.Context, req *taskmanagerv1.CreateUserRequest,
+) (*taskmanagerv1.CreateUserResponse, error) {
+	if err := handler.ValidateUsernamePassword(req.GetUsername(), req.GetPassword()); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	userID, err := s.storage.CreateNewUser(ctx, req.GetUsername(), security.SaltPassword(req.GetPassword()))
+	if err != nil {
+		if errors.Is(err, model.ErrUserAlreadyExists) {
+			return nil, newStatus(codes.AlreadyExists, typeUsernameAlreadyExists, req.GetUsername()).Err()
+		}
+
+		return nil, internalError{comment: "create new user", err: err}
+	}
+
+	return &taskmanagerv1.CreateUserResponse{
+		UserId: int64(userID),
+	}, nil
+}
+
+func (s userService) DeleteUser(
+	ctx context.Context, req *taskmanagerv1.DeleteUserRequest,
+) (*taskmanagerv1.DeleteUserResponse, error) {
+	if req.GetUserId() < 1 {
+		return nil, invalidArgument(typeParameterRequired, "userId")
+	}
+
+	if err := s.storage.DeleteUser(ctx, int(req.GetUserId())); err != nil {
+		if errors.Is(err, model.ErrUserNotFound) {
+			return nil, newStatus(codes.NotFound, typeUserNotFound, strconv.FormatInt(req.GetUserId(), 10)).Err()
+		}
+
+		return nil, internalError{comment: "delete user", err: err}
+	}
+
+	return &taskmanagerv1.DeleteUserResponse{}, nil
+}
@@ -44,6 +44,9 @@ func TestAuthorizationNegative(t *testing.T) {
 		{
 			"update_task", http.MethodPut, "/api/v1/task/3", http.StatusUnauthorized,
 		},
+		{
+			"patch_task", http.MethodPatch, "/api/v1/task/3", http.StatusUnauthorized,
+		},
 		{
 			"delete_task", http.MethodDelete, "/api/v1/task/4", http.StatusUnauthorized,
 		},
@@ -53,6 +56,27 @@ func TestAuthorizationNegative(t *testing.T) {
 		{
 			"delete_tasks", http.MethodDelete, "/api/v1/tasks/", http.StatusUnauthorized,
 		},
+		{
+			"restore_task", http.MethodPost, "/api/v1/task/5/restore", http.StatusUnauthorized,
+		},
+		{
+			"get_task_history", http.MethodGet, "/api/v1/task/6/history", http.StatusUnauthorized,
+		},
+		{
+			"get_task_revision", http.MethodGet, "/api/v1/task/6/history/1", http.StatusUnauthorized,
+		},
+		{
+			"revert_task", http.MethodPost, "/api/v1/task/6/history/1/revert", http.StatusUnauthorized,
+		},
+		{
+			"get_trash", http.MethodGet, "/api/v1/tasks/trash", http.StatusUnauthorized,
+		},
+		{
+			"restore_tasks", http.MethodPost, "/api/v1/tasks/trash/restore", http.StatusUnauthorized,
+		},
+		{
+			"batch_tasks", http.MethodPost, "/api/v1/tasks/batch", http.StatusUnauthorized,
+		},
 	}
 
 	for _, tt := range cases {
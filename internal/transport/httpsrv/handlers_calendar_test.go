@@ -0,0 +1,172 @@
+package httpsrv
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taskmanager/internal/handler"
+	"taskmanager/internal/security"
+)
+
+func TestCalendar(t *testing.T) {
+	ctx := context.Background()
+
+	router, storage, username, password := newTestRouter(t)
+
+	taskID, err := storage.CreateTask(ctx, username, security.SaltPassword(password), "weekly review")
+	require.NoError(t, err)
+
+	_, err = storage.CreateTask(ctx, username, security.SaltPassword(password), "without due")
+	require.NoError(t, err)
+
+	request := func(method, path, body string, auth bool) *httptest.ResponseRecorder {
+		if !auth {
+			return serveTestRequest(router, method, path, body, "", "")
+		}
+
+		return serveTestRequest(router, method, path, body, username, password)
+	}
+
+	createToken := func(t *testing.T) handler.CreateCalendarTokenResult {
+		t.Helper()
+
+		w := request(http.MethodPost, "/api/v1/calendar/token", "", true)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var res handler.CreateCalendarTokenResult
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+
+		return res
+	}
+
+	w := request(http.MethodPatch, "/api/v1/task/"+strconv.Itoa(taskID),
+		`{"due": "2023-05-02T12:30:00+03:00", "recurrence": "FREQ=WEEKLY;BYDAY=TU"}`, true)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+	t.Run("feed", func(t *testing.T) {
+		token := createToken(t)
+		assert.Equal(t, "/api/v1/calendar/"+token.Token+".ics", token.Path)
+
+		w := request(http.MethodGet, token.Path, "", false)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/calendar; charset=utf-8", w.Header().Get("Content-Type"))
+
+		body := w.Body.String()
+		assert.True(t, strings.HasPrefix(body, "BEGIN:VCALENDAR\r\n"))
+		assert.Equal(t, 1, strings.Count(body, "BEGIN:VTODO"))
+		assert.Contains(t, body, "SUMMARY:weekly review\r\n")
+		assert.Contains(t, body, "DUE:20230502T093000Z\r\n")
+		assert.Contains(t, body, "RRULE:FREQ=WEEKLY;BYDAY=TU\r\n")
+		assert.Contains(t, body, "STATUS:NEEDS-ACTION\r\n")
+
+		w = request(http.MethodGet, token.Path+"?component=vevent", "", false)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "BEGIN:VEVENT\r\n")
+
+		w = request(http.MethodGet, token.Path+"?component=vjournal", "", false)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = request(http.MethodGet, strings.TrimSuffix(token.Path, ".ics"), "", false)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("regenerate", func(t *testing.T) {
+		oldToken := createToken(t)
+		token := createToken(t)
+		assert.NotEqual(t, oldToken.Token, token.Token)
+
+		w := request(http.MethodGet, oldToken.Path, "", false)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = request(http.MethodGet, token.Path, "", false)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = request(http.MethodDelete, "/api/v1/calendar/token", "", true)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		w = request(http.MethodGet, token.Path, "", false)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = request(http.MethodDelete, "/api/v1/calendar/token", "", true)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"type": "CALENDAR_TOKEN_NOT_FOUND", "comment": "create the token first"}`, w.Body.String())
+	})
+
+	t.Run("v2 path", func(t *testing.T) {
+		w := request(http.MethodPost, "/api/v2/calendar/token", "", true)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var res handler.CreateCalendarTokenResult
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		assert.Equal(t, "/api/v2/calendar/"+res.Token+".ics", res.Path)
+	})
+
+	t.Run("credentials", func(t *testing.T) {
+		w := request(http.MethodPost, "/api/v1/calendar/token", "", false)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("remove due", func(t *testing.T) {
+		token := createToken(t)
+
+		r := httptest.NewRequest(http.MethodPatch, "/api/v1/task/"+strconv.Itoa(taskID),
+			strings.NewReader(`[{"op": "remove", "path": "/due"}]`))
+		r.SetBasicAuth(username, password)
+		r.Header.Set("Content-Type", "application/json-patch+json")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+		w = request(http.MethodGet, token.Path, "", false)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), "VTODO")
+
+		w = request(http.MethodPatch, "/api/v1/task/"+strconv.Itoa(taskID),
+			`{"recurrence": "FREQ=SECONDLY"}`, true)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("put replaces due and recurrence", func(t *testing.T) {
+		path := "/api/v1/task/" + strconv.Itoa(taskID)
+
+		getTask := func(t *testing.T) map[string]any {
+			t.Helper()
+
+			w := request(http.MethodGet, path, "", true)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			var task map[string]any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &task))
+
+			return task
+		}
+
+		w := request(http.MethodPut, path, `{"title": "weekly review", "completed": false,
+			"due": "2023-05-09T09:30:00Z", "recurrence": "FREQ=WEEKLY;BYDAY=TU"}`, true)
+		require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+		task := getTask(t)
+		assert.Equal(t, "2023-05-09T09:30:00Z", task["due"])
+		assert.Equal(t, "FREQ=WEEKLY;BYDAY=TU", task["recurrence"])
+
+		w = request(http.MethodPut, path, `{"title": "weekly review", "completed": false}`, true)
+		require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+		task = getTask(t)
+		assert.NotContains(t, task, "due")
+		assert.NotContains(t, task, "recurrence")
+
+		w = request(http.MethodPut, path, `{"title": "weekly review", "completed": false, "recurrence": "FREQ=SECONDLY"}`,
+			true)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
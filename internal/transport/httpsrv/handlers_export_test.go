@@ -0,0 +1,90 @@
+package httpsrv
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taskmanager/internal/security"
+)
+
+func TestExportTasks(t *testing.T) {
+	ctx := context.Background()
+
+	router, storage, username, password := newTestRouter(t)
+
+	taskID1, err := storage.CreateTask(ctx, username, security.SaltPassword(password), "task1")
+	require.NoError(t, err)
+
+	taskID2, err := storage.CreateTask(ctx, username, security.SaltPassword(password), "task2")
+	require.NoError(t, err)
+
+	require.NoError(t, storage.DeleteTask(ctx, username, security.SaltPassword(password), taskID2, 0))
+
+	export := func(query, username, password string) *httptest.ResponseRecorder {
+		return serveTestRequest(router, http.MethodGet, "/api/v1/tasks/export"+query, "", username, password)
+	}
+
+	t.Run("json", func(t *testing.T) {
+		w := export("", username, password)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="tasks.json"`, w.Header().Get("Content-Disposition"))
+
+		var tasks []struct {
+			ID      int     `json:"id"`
+			Title   string  `json:"title"`
+			Deleted *string `json:"deleted"`
+		}
+
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tasks))
+		require.Len(t, tasks, 2)
+		assert.Equal(t, taskID1, tasks[0].ID)
+		assert.Nil(t, tasks[0].Deleted)
+		assert.Equal(t, taskID2, tasks[1].ID)
+		assert.NotNil(t, tasks[1].Deleted)
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		w := export("?format=csv", username, password)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		require.Len(t, lines, 3)
+		assert.Equal(t, "id,status,title,created,updated,completed,deleted,due,recurrence", lines[0])
+		assert.True(t, strings.HasPrefix(lines[1], strconv.Itoa(taskID1)+",false,task1,"))
+	})
+
+	t.Run("todotxt", func(t *testing.T) {
+		w := export("?format=todotxt", username, password)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, `attachment; filename="tasks.txt"`, w.Header().Get("Content-Disposition"))
+		assert.Contains(t, w.Body.String(), "task2 id:"+strconv.Itoa(taskID2)+" deleted:")
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		_, err := storage.CreateNewUser(ctx, "exportempty", security.SaltPassword(password))
+		require.NoError(t, err)
+
+		w := export("?format=md", "exportempty", password)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 2, strings.Count(w.Body.String(), "\n"))
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		w := export("?format=xml", username, password)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"type": "PARAMETER_REQUIRED", "comment": "format: json, csv, md, todotxt"}`, w.Body.String())
+
+		assert.Equal(t, http.StatusUnauthorized, export("", "", "").Code)
+		assert.Equal(t, http.StatusForbidden, export("", username, "wrong").Code)
+	})
+}
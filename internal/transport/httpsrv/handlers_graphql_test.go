@@ -0,0 +1,473 @@
+package httpsrv
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taskmanager/internal/app"
+	"taskmanager/internal/model"
+	"taskmanager/internal/security"
+)
+
+// batchCountingStorage counts the queries of the relations of the tasks by the method.
+type batchCountingStorage struct {
+	model.Memory
+	queries map[string]int
+}
+
+func (s batchCountingStorage) GetTasksHistory(
+	ctx context.Context, username, password string, taskIDs []int,
+) (map[int][]model.TaskChange, error) {
+	s.queries["GetTasksHistory"]++
+
+	return s.Memory.GetTasksHistory(ctx, username, password, taskIDs)
+}
+
+func (s batchCountingStorage) GetTasksTags(ctx context.Context, userID int, taskIDs []int) (map[int][]string, error) {
+	s.queries["GetTasksTags"]++
+
+	return s.Memory.GetTasksTags(ctx, userID, taskIDs)
+}
+
+func (s batchCountingStorage) GetTasksComments(
+	ctx context.Context, userID int, taskIDs []int,
+) (map[int][]model.TaskComment, error) {
+	s.queries["GetTasksComments"]++
+
+	return s.Memory.GetTasksComments(ctx, userID, taskIDs)
+}
+
+func (s batchCountingStorage) GetSubtasks(
+	ctx context.Context, userID int, parentIDs []int,
+) (map[int][]model.Task, error) {
+	s.queries["GetSubtasks"]++
+
+	return s.Memory.GetSubtasks(ctx, userID, parentIDs)
+}
+
+type graphQLTestResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string         `json:"message"`
+		Path       []any          `json:"path"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+func TestGraphQL(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctx := context.Background()
+
+	storage := batchCountingStorage{Memory: model.NewMemory(), queries: make(map[string]int)}
+
+	_, err := storage.CreateNewUser(ctx, "graphqluser", security.SaltPassword("graphqlpassword"))
+	require.NoError(t, err)
+
+	_, err = storage.CreateNewUser(ctx, "graphqlmember", security.SaltPassword("graphqlpassword"))
+	require.NoError(t, err)
+
+	serverConf := Conf{
+		ManageUsername:       "manage",
+		ManagePassword:       "manage",
+		GraphQLMaxDepth:      6,
+		GraphQLMaxComplexity: 500,
+	}
+
+	router := gin.New()
+	serverConf.setRouters(ctx, storage, router, app.Metrics{MetricsRoute: "/metrics"})
+
+	graphQLAs := func(t *testing.T, username, query string, variables map[string]any) graphQLTestResponse {
+		t.Helper()
+
+		body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+		require.NoError(t, err)
+
+		r := httptest.NewRequest(http.MethodPost, "/api/graphql", strings.NewReader(string(body)))
+		r.Header.Set("Content-Type", "application/json")
+		r.SetBasicAuth(username, "graphqlpassword")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var res graphQLTestResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+
+		return res
+	}
+
+	graphQL := func(t *testing.T, query string, variables map[string]any) graphQLTestResponse {
+		t.Helper()
+
+		return graphQLAs(t, "graphqluser", query, variables)
+	}
+
+	t.Run("credentials", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, "/api/graphql", strings.NewReader(`{"query": "{ tasks { totalCount } }"}`))
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		r = httptest.NewRequest(http.MethodPost, "/api/graphql", strings.NewReader(`{"query": "{ tasks { totalCount } }"}`))
+		r.SetBasicAuth("graphqluser", "wrong")
+
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	var taskIDs []int
+
+	for _, title := range []string{"first", "second", "third"} {
+		res := graphQL(t, `mutation($title: String!) { createTask(title: $title) { id title completed } }`,
+			map[string]any{"title": title})
+		require.Empty(t, res.Errors)
+
+		var data struct {
+			CreateTask struct {
+				ID        int    `json:"id"`
+				Title     string `json:"title"`
+				Completed bool   `json:"completed"`
+			} `json:"createTask"`
+		}
+
+		require.NoError(t, json.Unmarshal(res.Data, &data))
+		assert.Equal(t, title, data.CreateTask.Title)
+
+		taskIDs = append(taskIDs, data.CreateTask.ID)
+	}
+
+	t.Run("mutations", func(t *testing.T) {
+		res := graphQL(t, `mutation($id: Int!) { updateTask(id: $id, completed: true, version: 1) { completed version } }`,
+			map[string]any{"id": taskIDs[0]})
+		require.Empty(t, res.Errors)
+		assert.JSONEq(t, `{"updateTask": {"completed": true, "version": 2}}`, string(res.Data))
+
+		res = graphQL(t, `mutation($id: Int!) { updateTask(id: $id, title: "new", version: 1) { version } }`,
+			map[string]any{"id": taskIDs[0]})
+		require.Len(t, res.Errors, 1)
+		assert.Equal(t, "TASK_VERSION_MISMATCH", res.Errors[0].Extensions["code"])
+		assert.JSONEq(t, `null`, string(res.Data))
+
+		res = graphQL(t, `mutation { createTask(title: "") { id } }`, nil)
+		require.Len(t, res.Errors, 1)
+		assert.Equal(t, "PARAMETER_REQUIRED", res.Errors[0].Extensions["code"])
+
+		dueMutation := `mutation($id: Int!, $due: String, $recurrence: String) {
+			updateTask(id: $id, due: $due, recurrence: $recurrence) { due recurrence }
+		}`
+
+		res = graphQL(t, dueMutation, map[string]any{
+			"id": taskIDs[0], "due": "2023-05-04T10:00:00+03:00", "recurrence": "FREQ=WEEKLY",
+		})
+		require.Empty(t, res.Errors)
+		assert.JSONEq(t, `{"updateTask": {"due": "2023-05-04T07:00:00Z", "recurrence": "FREQ=WEEKLY"}}`, string(res.Data))
+
+		res = graphQL(t, dueMutation, map[string]any{"id": taskIDs[0], "due": "", "recurrence": ""})
+		require.Empty(t, res.Errors)
+		assert.JSONEq(t, `{"updateTask": {"due": null, "recurrence": null}}`, string(res.Data))
+
+		for _, variables := range []map[string]any{
+			{"id": taskIDs[0], "due": "tomorrow"},
+			{"id": taskIDs[0], "recurrence": "FREQ=SECONDLY"},
+		} {
+			res = graphQL(t, dueMutation, variables)
+			require.Len(t, res.Errors, 1)
+			assert.Equal(t, "PARAMETER_REQUIRED", res.Errors[0].Extensions["code"])
+		}
+
+		res = graphQL(t, `{ task(id: 100000) { id } }`, nil)
+		require.Empty(t, res.Errors)
+		assert.JSONEq(t, `{"task": null}`, string(res.Data))
+	})
+
+	t.Run("pagination and batched history", func(t *testing.T) {
+		storage.queries["GetTasksHistory"] = 0
+
+		query := `query($after: String) {
+			tasks(first: 2, after: $after) {
+				totalCount
+				pageInfo { hasNextPage endCursor }
+				edges { node { id history { revision field old new } } }
+			}
+		}`
+
+		res := graphQL(t, query, nil)
+		require.Empty(t, res.Errors)
+
+		var data struct {
+			Tasks struct {
+				TotalCount int `json:"totalCount"`
+				PageInfo   struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+				Edges []struct {
+					Node struct {
+						ID      int `json:"id"`
+						History []struct {
+							Revision int     `json:"revision"`
+							Field    string  `json:"field"`
+							Old      *string `json:"old"`
+							New      *string `json:"new"`
+						} `json:"history"`
+					} `json:"node"`
+				} `json:"edges"`
+			} `json:"tasks"`
+		}
+
+		require.NoError(t, json.Unmarshal(res.Data, &data))
+		assert.Equal(t, 3, data.Tasks.TotalCount)
+		assert.True(t, data.Tasks.PageInfo.HasNextPage)
+		require.Len(t, data.Tasks.Edges, 2)
+		assert.Equal(t, taskIDs[0], data.Tasks.Edges[0].Node.ID)
+		assert.NotEmpty(t, data.Tasks.Edges[0].Node.History)
+		assert.Empty(t, data.Tasks.Edges[1].Node.History)
+
+		// The histories of the page in one query.
+		assert.Equal(t, 1, storage.queries["GetTasksHistory"])
+
+		res = graphQL(t, query, map[string]any{"after": data.Tasks.PageInfo.EndCursor})
+		require.Empty(t, res.Errors)
+
+		require.NoError(t, json.Unmarshal(res.Data, &data))
+		assert.False(t, data.Tasks.PageInfo.HasNextPage)
+		require.Len(t, data.Tasks.Edges, 1)
+		assert.Equal(t, taskIDs[2], data.Tasks.Edges[0].Node.ID)
+	})
+
+	t.Run("trash", func(t *testing.T) {
+		res := graphQL(t, `mutation($id: Int!) { deleteTask(id: $id) }`, map[string]any{"id": taskIDs[1]})
+		require.Empty(t, res.Errors)
+
+		res = graphQL(t, `{ trash { totalCount edges { node { task { id } deleted } } } }`, nil)
+		require.Empty(t, res.Errors)
+		assert.Contains(t, string(res.Data), `"totalCount":1`)
+
+		res = graphQL(t, `mutation($id: Int!) { restoreTask(id: $id) { id } }`, map[string]any{"id": taskIDs[1]})
+		require.Empty(t, res.Errors)
+	})
+
+	t.Run("limits", func(t *testing.T) {
+		res := graphQL(t, `{ tasks(first: 100) { edges { cursor node { id title history { field old new } } } } }`, nil)
+		require.Len(t, res.Errors, 1)
+		assert.Equal(t, "COMPLEXITY_LIMIT_EXCEEDED", res.Errors[0].Extensions["code"])
+
+		res = graphQL(t, `{ tasks(first: 1000) { totalCount } }`, nil)
+		require.Len(t, res.Errors, 1)
+		assert.Equal(t, "PARAMETER_REQUIRED", res.Errors[0].Extensions["code"])
+
+		res = graphQL(t, `{ tasks { edges { node { unknown } } } }`, nil)
+		require.Len(t, res.Errors, 1)
+		assert.Contains(t, res.Errors[0].Message, "unknown")
+
+		res = graphQL(t, `{ tasks { edges { node { subtasks { project { tasks { id } } } } } } }`, nil)
+		require.Len(t, res.Errors, 1)
+		assert.Equal(t, "DEPTH_LIMIT_EXCEEDED", res.Errors[0].Extensions["code"])
+		assert.Empty(t, res.Data)
+
+		// The introspection of the tools is deeper than MaxDepth.
+		res = graphQL(t, `{
+			__schema { queryType { name } }
+			__type(name: "Task") { fields { name type { kind ofType { kind ofType { kind ofType { name } } } } } }
+		}`, nil)
+		require.Empty(t, res.Errors)
+		assert.Contains(t, string(res.Data), `"name":"subtasks"`)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		query := url.Values{
+			"query":     {`query($first: Int) { tasks(first: $first) { totalCount } }`},
+			"variables": {`{"first": 1}`},
+		}
+
+		r := httptest.NewRequest(http.MethodGet, "/api/graphql?"+query.Encode(), nil)
+		r.SetBasicAuth("graphqluser", "graphqlpassword")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"data": {"tasks": {"totalCount": 3}}}`, w.Body.String())
+
+		query.Set("query", `mutation { createTask(title: "get") { id } }`)
+
+		r = httptest.NewRequest(http.MethodGet, "/api/graphql?"+query.Encode(), nil)
+		r.SetBasicAuth("graphqluser", "graphqlpassword")
+
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "mutations are not allowed with GET")
+	})
+
+	t.Run("relations", func(t *testing.T) {
+		res := graphQL(t, `mutation($id: Int!) { setTaskTags(id: $id, tags: ["work", "home", "work"]) { tags } }`,
+			map[string]any{"id": taskIDs[0]})
+		require.Empty(t, res.Errors)
+		assert.JSONEq(t, `{"setTaskTags": {"tags": ["home", "work"]}}`, string(res.Data))
+
+		res = graphQL(t, `mutation($id: Int!) { addComment(taskId: $id, text: "first comment") { id text author } }`,
+			map[string]any{"id": taskIDs[0]})
+		require.Empty(t, res.Errors)
+
+		var comment struct {
+			AddComment struct {
+				ID     int    `json:"id"`
+				Text   string `json:"text"`
+				Author string `json:"author"`
+			} `json:"addComment"`
+		}
+
+		require.NoError(t, json.Unmarshal(res.Data, &comment))
+		assert.Equal(t, "first comment", comment.AddComment.Text)
+		assert.Equal(t, "graphqluser", comment.AddComment.Author)
+
+		res = graphQL(t, `mutation($id: Int!, $parentId: Int) { setTaskParent(id: $id, parentId: $parentId) { parentId } }`,
+			map[string]any{"id": taskIDs[2], "parentId": taskIDs[0]})
+		require.Empty(t, res.Errors)
+
+		// The subtasks are one level deep.
+		res = graphQL(t, `mutation($id: Int!, $parentId: Int) { setTaskParent(id: $id, parentId: $parentId) { parentId } }`,
+			map[string]any{"id": taskIDs[1], "parentId": taskIDs[2]})
+		require.Len(t, res.Errors, 1)
+		assert.Equal(t, "TASK_PARENT_INVALID", res.Errors[0].Extensions["code"])
+
+		for method := range storage.queries {
+			delete(storage.queries, method)
+		}
+
+		res = graphQL(t, `{
+			tasks {
+				edges { node { id parentId tags comments { text } subtasks { id parentId tags comments { text } } } }
+			}
+		}`, nil)
+		require.Empty(t, res.Errors)
+
+		var data struct {
+			Tasks struct {
+				Edges []struct {
+					Node struct {
+						ID       int      `json:"id"`
+						ParentID *int     `json:"parentId"`
+						Tags     []string `json:"tags"`
+						Comments []struct {
+							Text string `json:"text"`
+						} `json:"comments"`
+						Subtasks []struct {
+							ID       int      `json:"id"`
+							ParentID int      `json:"parentId"`
+							Tags     []string `json:"tags"`
+						} `json:"subtasks"`
+					} `json:"node"`
+				} `json:"edges"`
+			} `json:"tasks"`
+		}
+
+		require.NoError(t, json.Unmarshal(res.Data, &data))
+		require.Len(t, data.Tasks.Edges, 3)
+
+		first := data.Tasks.Edges[0].Node
+		assert.Nil(t, first.ParentID)
+		assert.Equal(t, []string{"home", "work"}, first.Tags)
+		require.Len(t, first.Comments, 1)
+		require.Len(t, first.Subtasks, 1)
+		assert.Equal(t, taskIDs[2], first.Subtasks[0].ID)
+		assert.Equal(t, taskIDs[0], first.Subtasks[0].ParentID)
+		assert.Empty(t, first.Subtasks[0].Tags)
+		assert.Empty(t, data.Tasks.Edges[1].Node.Tags)
+
+		// The relations of all the tasks and of their subtasks in one query each.
+		assert.Equal(t, map[string]int{"GetTasksTags": 1, "GetTasksComments": 1, "GetSubtasks": 1}, storage.queries)
+
+		res = graphQL(t, `mutation($id: Int!) { deleteComment(id: $id) }`, map[string]any{"id": comment.AddComment.ID})
+		require.Empty(t, res.Errors)
+
+		res = graphQL(t, `mutation($id: Int!) { deleteComment(id: $id) }`, map[string]any{"id": comment.AddComment.ID})
+		require.Len(t, res.Errors, 1)
+		assert.Equal(t, "COMMENT_NOT_FOUND", res.Errors[0].Extensions["code"])
+
+		res = graphQL(t, `mutation($id: Int!) { setTaskParent(id: $id) { parentId } }`, map[string]any{"id": taskIDs[2]})
+		require.Empty(t, res.Errors)
+		assert.JSONEq(t, `{"setTaskParent": {"parentId": null}}`, string(res.Data))
+	})
+
+	t.Run("projects", func(t *testing.T) {
+		res := graphQL(t, `mutation { createProject(name: "board") { id name members { username } } }`, nil)
+		require.Empty(t, res.Errors)
+
+		var project struct {
+			CreateProject struct {
+				ID      int    `json:"id"`
+				Name    string `json:"name"`
+				Members []struct {
+					Username string `json:"username"`
+				} `json:"members"`
+			} `json:"createProject"`
+		}
+
+		require.NoError(t, json.Unmarshal(res.Data, &project))
+		assert.Equal(t, "board", project.CreateProject.Name)
+		require.Len(t, project.CreateProject.Members, 1)
+		assert.Equal(t, "graphqluser", project.CreateProject.Members[0].Username)
+
+		projectID := project.CreateProject.ID
+
+		res = graphQL(t, `mutation($id: Int!) { addProjectMember(projectId: $id, username: "graphqlmember") { username } }`,
+			map[string]any{"id": projectID})
+		require.Empty(t, res.Errors)
+		assert.JSONEq(t, `{"addProjectMember": {"username": "graphqlmember"}}`, string(res.Data))
+
+		res = graphQL(t, `mutation($id: Int!, $projectId: Int) {
+			setTaskProject(id: $id, projectId: $projectId) { project { name } }
+		}`, map[string]any{"id": taskIDs[1], "projectId": projectID})
+		require.Empty(t, res.Errors)
+		assert.JSONEq(t, `{"setTaskProject": {"project": {"name": "board"}}}`, string(res.Data))
+
+		// The member sees the task of the project and comments on it, only the owner changes it.
+		res = graphQLAs(t, "graphqlmember", `{ projects { name tasks { id title project { id } } } }`, nil)
+		require.Empty(t, res.Errors)
+		assert.JSONEq(t, `{"projects": [{"name": "board", "tasks": [
+			{"id": `+strconv.Itoa(taskIDs[1])+`, "title": "second", "project": {"id": `+strconv.Itoa(projectID)+`}}
+		]}]}`, string(res.Data))
+
+		res = graphQLAs(t, "graphqlmember", `mutation($id: Int!) { addComment(taskId: $id, text: "seen") { author } }`,
+			map[string]any{"id": taskIDs[1]})
+		require.Empty(t, res.Errors)
+
+		res = graphQLAs(t, "graphqlmember", `mutation($id: Int!) { setTaskTags(id: $id, tags: ["mine"]) { id } }`,
+			map[string]any{"id": taskIDs[1]})
+		require.Len(t, res.Errors, 1)
+		assert.Equal(t, "TASK_NOT_FOUND", res.Errors[0].Extensions["code"])
+
+		res = graphQL(t, `mutation($id: Int!) { setTaskProject(id: $id, projectId: 100000) { id } }`,
+			map[string]any{"id": taskIDs[1]})
+		require.Len(t, res.Errors, 1)
+		assert.Equal(t, "PROJECT_NOT_FOUND", res.Errors[0].Extensions["code"])
+
+		res = graphQL(t, `{ project(id: 100000) { id } }`, nil)
+		require.Empty(t, res.Errors)
+		assert.JSONEq(t, `{"project": null}`, string(res.Data))
+
+		res = graphQL(t, `mutation($id: Int!) { deleteProject(id: $id) }`, map[string]any{"id": projectID})
+		require.Empty(t, res.Errors)
+
+		res = graphQLAs(t, "graphqlmember", `{ projects { id } }`, nil)
+		require.Empty(t, res.Errors)
+		assert.JSONEq(t, `{"projects": []}`, string(res.Data))
+	})
+}
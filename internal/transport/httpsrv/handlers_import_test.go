@@ -0,0 +1,110 @@
+package httpsrv
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taskmanager/internal/handler"
+	"taskmanager/internal/security"
+)
+
+func TestImportTasks(t *testing.T) {
+	ctx := context.Background()
+
+	router, storage, username, password := newTestRouter(t)
+
+	existingID, err := storage.CreateTask(ctx, username, security.SaltPassword(password), "existing")
+	require.NoError(t, err)
+
+	importTasks := func(t *testing.T, query, body string) (*httptest.ResponseRecorder, handler.ImportTasksResult) {
+		t.Helper()
+
+		w := serveTestRequest(router, http.MethodPost, "/api/v1/tasks/import"+query, body, username, password)
+
+		var res handler.ImportTasksResult
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		}
+
+		return w, res
+	}
+
+	csv := "Name,Done,Trash\ntask1,true,\nexisting,,\ntask2,,2023-05-03\ntask1,,\n,,\n"
+	columns := "&columns[title]=Name&columns[status]=Done&columns[deleted]=Trash"
+
+	t.Run("dry run", func(t *testing.T) {
+		w, res := importTasks(t, "?format=csv&dryRun=true"+columns, csv)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		assert.True(t, res.DryRun)
+		assert.Equal(t, 2, res.Created)
+		assert.Equal(t, 2, res.Duplicates)
+		assert.Equal(t, 1, res.Failed)
+
+		require.Len(t, res.Rows, 5)
+		assert.Equal(t, handler.ImportStatusValid, res.Rows[0].Status)
+		assert.Equal(t, handler.ImportStatusDuplicate, res.Rows[1].Status)
+		assert.Equal(t, existingID, res.Rows[1].TaskID)
+		assert.Equal(t, handler.ImportStatusDuplicate, res.Rows[3].Status)
+		assert.Equal(t, "row 1", res.Rows[3].Error.Comment)
+		assert.Equal(t, handler.ImportStatusInvalid, res.Rows[4].Status)
+		assert.Equal(t, "PARAMETER_REQUIRED", res.Rows[4].Error.Type)
+
+		tasks, err := storage.GetTasks(ctx, username, security.SaltPassword(password))
+		require.NoError(t, err)
+		assert.Len(t, tasks, 1)
+	})
+
+	t.Run("import", func(t *testing.T) {
+		w, res := importTasks(t, "?format=csv"+columns, csv)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		assert.False(t, res.DryRun)
+		assert.Equal(t, 2, res.Created)
+		assert.Equal(t, handler.ImportStatusCreated, res.Rows[0].Status)
+		assert.Equal(t, handler.ImportStatusCreated, res.Rows[2].Status)
+
+		task, err := storage.GetTask(ctx, username, security.SaltPassword(password), res.Rows[0].TaskID)
+		require.NoError(t, err)
+		assert.Equal(t, "task1", task.Title)
+		assert.True(t, task.Status)
+
+		trash, err := storage.GetTrash(ctx, username, security.SaltPassword(password))
+		require.NoError(t, err)
+		require.Len(t, trash, 1)
+		assert.Equal(t, res.Rows[2].TaskID, trash[0].ID)
+	})
+
+	t.Run("export and import", func(t *testing.T) {
+		w := serveTestRequest(router, http.MethodGet, "/api/v1/tasks/export?format=todotxt", "", username, password)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w, res := importTasks(t, "?format=todotxt", w.Body.String())
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, 0, res.Created)
+		assert.Equal(t, 3, res.Duplicates)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		w, _ := importTasks(t, "", `{"title": "task"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"type": "IMPORT_INVALID", "comment": "invalid import: json: array required"}`, w.Body.String())
+
+		w, _ = importTasks(t, "?format=xml", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w, _ = importTasks(t, "?columns[created]=Date", "[]")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w, _ = importTasks(t, "?format=todotxt", strings.Repeat("task\n", 10001))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "max 10000 tasks")
+	})
+}
@@ -0,0 +1,136 @@
+package httpsrv
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taskmanager/internal/handler"
+	"taskmanager/internal/model"
+)
+
+func TestNotifications(t *testing.T) {
+	ctx := context.Background()
+
+	router, storage, username, password := newTestRouter(t)
+
+	userID, err := storage.GetUserID(ctx, username)
+	require.NoError(t, err)
+
+	var notificationIDs []int
+
+	for _, notificationType := range []string{
+		model.NotificationTaskReminder, model.NotificationWebhookDisabled, model.NotificationTaskReminder,
+	} {
+		notificationID, err := storage.CreateNotification(ctx, model.Notification{
+			UserID: userID,
+			Type:   notificationType,
+			Title:  notificationType,
+		})
+		require.NoError(t, err)
+
+		notificationIDs = append(notificationIDs, notificationID)
+	}
+
+	request := func(method, path, body string, auth bool) *httptest.ResponseRecorder {
+		if !auth {
+			return serveTestRequest(router, method, path, body, "", "")
+		}
+
+		return serveTestRequest(router, method, path, body, username, password)
+	}
+
+	getNotifications := func(t *testing.T, query string) handler.NotificationsResult {
+		t.Helper()
+
+		w := request(http.MethodGet, "/api/v1/notifications/"+query, "", true)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var res handler.NotificationsResult
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+
+		return res
+	}
+
+	t.Run("get", func(t *testing.T) {
+		res := getNotifications(t, "")
+		require.Len(t, res.Notifications, 3)
+		assert.Equal(t, notificationIDs[2], res.Notifications[0].ID)
+		assert.Equal(t, 3, res.Unread)
+		assert.Equal(t, map[string]int{model.NotificationTaskReminder: 2, model.NotificationWebhookDisabled: 1},
+			res.UnreadByType)
+
+		res = getNotifications(t, "?limit=2&before="+strconv.Itoa(notificationIDs[2]))
+		require.Len(t, res.Notifications, 2)
+		assert.Equal(t, notificationIDs[1], res.Notifications[0].ID)
+		assert.Equal(t, 3, res.Unread, "of all notifications")
+
+		w := request(http.MethodGet, "/api/v1/notifications/?limit=101", "", true)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("read", func(t *testing.T) {
+		w := request(http.MethodPost, "/api/v1/notifications/"+strconv.Itoa(notificationIDs[0])+"/read", "", true)
+		assert.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+		res := getNotifications(t, "?unread=true")
+		require.Len(t, res.Notifications, 2)
+		assert.Equal(t, 2, res.Unread)
+
+		w = request(http.MethodPost, "/api/v1/notifications/1000/read", "", true)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"type": "NOTIFICATION_NOT_FOUND", "comment": "1000"}`, w.Body.String())
+
+		w = request(http.MethodPost, "/api/v1/notifications/read", "", true)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.JSONEq(t, `{"read": 2}`, w.Body.String())
+
+		res = getNotifications(t, "")
+		assert.Len(t, res.Notifications, 3)
+		assert.Equal(t, 0, res.Unread)
+		assert.Empty(t, res.UnreadByType)
+	})
+
+	t.Run("preferences", func(t *testing.T) {
+		w := request(http.MethodGet, "/api/v1/notifications/preferences", "", true)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.JSONEq(t, `[{"type": "task.reminder", "enabled": true}, {"type": "webhook.disabled", "enabled": true},
+			{"type": "project.shared", "enabled": true}, {"type": "task.assigned", "enabled": true},
+			{"type": "task.commented", "enabled": true}]`, w.Body.String())
+
+		w = request(http.MethodPut, "/api/v1/notifications/preferences",
+			`{"preferences": [{"type": "webhook.disabled", "enabled": false}]}`, true)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.JSONEq(t, `[{"type": "task.reminder", "enabled": true}, {"type": "webhook.disabled", "enabled": false},
+			{"type": "project.shared", "enabled": true}, {"type": "task.assigned", "enabled": true},
+			{"type": "task.commented", "enabled": true}]`, w.Body.String())
+
+		notificationID, err := storage.CreateNotification(ctx, model.Notification{
+			UserID: userID,
+			Type:   model.NotificationWebhookDisabled,
+			Title:  model.NotificationWebhookDisabled,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 0, notificationID, "disabled")
+
+		for _, body := range []string{
+			`{"preferences": []}`,
+			`{"preferences": [{"type": "task.comment", "enabled": false}]}`,
+			`{"preferences": [{"type": "task.reminder"}]}`,
+		} {
+			w = request(http.MethodPut, "/api/v1/notifications/preferences", body, true)
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+	})
+
+	t.Run("credentials", func(t *testing.T) {
+		w := request(http.MethodGet, "/api/v1/notifications/", "", false)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
@@ -0,0 +1,127 @@
+package httpsrv
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taskmanager/internal/handler"
+	"taskmanager/internal/model"
+	"taskmanager/internal/security"
+)
+
+func TestReminders(t *testing.T) {
+	ctx := context.Background()
+
+	router, storage, username, password := newTestRouter(t)
+
+	taskID, err := storage.CreateTask(ctx, username, security.SaltPassword(password), "call the bank")
+	require.NoError(t, err)
+
+	path := "/api/v1/task/" + strconv.Itoa(taskID) + "/reminders"
+
+	request := func(method, path, body string, auth bool) *httptest.ResponseRecorder {
+		if !auth {
+			return serveTestRequest(router, method, path, body, "", "")
+		}
+
+		return serveTestRequest(router, method, path, body, username, password)
+	}
+
+	getReminders := func(t *testing.T) []model.Reminder {
+		t.Helper()
+
+		w := request(http.MethodGet, path, "", true)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var reminders []model.Reminder
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &reminders))
+
+		return reminders
+	}
+
+	t.Run("create", func(t *testing.T) {
+		w := request(http.MethodPost, path, `{"remindAt": "2023-05-02T12:00:00+03:00", "channel": "inapp"}`, true)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var res handler.CreateReminderResult
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+
+		w = request(http.MethodPost, path,
+			`{"beforeMinutes": 30, "channel": "email", "email": "user@example.com"}`, true)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		reminders := getReminders(t)
+		require.Len(t, reminders, 2)
+		assert.Equal(t, res.ReminderID, reminders[0].ID)
+		assert.Equal(t, model.ReminderPending, reminders[0].Status)
+		require.NotNil(t, reminders[0].FireAt)
+		assert.Equal(t, "2023-05-02T09:00:00Z", reminders[0].FireAt.Format("2006-01-02T15:04:05Z07:00"))
+		assert.Nil(t, reminders[1].FireAt, "the task has no due date")
+
+		w = request(http.MethodPatch, "/api/v1/task/"+strconv.Itoa(taskID), `{"due": "2023-05-02T12:30:00Z"}`, true)
+		require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+		reminders = getReminders(t)
+		require.NotNil(t, reminders[1].FireAt)
+		assert.Equal(t, "2023-05-02T12:00:00Z", reminders[1].FireAt.Format("2006-01-02T15:04:05Z07:00"))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		cases := []struct {
+			name string
+			body string
+		}{
+			{name: "without time", body: `{"channel": "inapp"}`},
+			{name: "both times", body: `{"remindAt": "2023-05-02T12:00:00Z", "beforeMinutes": 5, "channel": "inapp"}`},
+			{name: "negative offset", body: `{"beforeMinutes": -5, "channel": "inapp"}`},
+			{name: "unknown channel", body: `{"beforeMinutes": 5, "channel": "sms"}`},
+			{name: "email without address", body: `{"beforeMinutes": 5, "channel": "email"}`},
+			{name: "address without email", body: `{"beforeMinutes": 5, "channel": "inapp", "email": "user@example.com"}`},
+			{name: "invalid address", body: `{"beforeMinutes": 5, "channel": "email", "email": "user"}`},
+		}
+
+		for _, tt := range cases {
+			t.Run(tt.name, func(t *testing.T) {
+				w := request(http.MethodPost, path, tt.body, true)
+				assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+			})
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		w := request(http.MethodPost, path, `{"beforeMinutes": 0, "channel": "webhook"}`, true)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var res handler.CreateReminderResult
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+
+		w = request(http.MethodDelete, path+"/"+strconv.Itoa(res.ReminderID), "", true)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+
+		w = request(http.MethodDelete, path+"/"+strconv.Itoa(res.ReminderID), "", true)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"type": "REMINDER_NOT_FOUND", "comment": "`+strconv.Itoa(res.ReminderID)+`"}`,
+			w.Body.String())
+	})
+
+	t.Run("task not found", func(t *testing.T) {
+		w := request(http.MethodGet, "/api/v1/task/1000/reminders", "", true)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.JSONEq(t, `{"type": "TASK_NOT_FOUND", "comment": "1000"}`, w.Body.String())
+
+		w = request(http.MethodPost, "/api/v1/task/1000/reminders", `{"beforeMinutes": 5, "channel": "inapp"}`, true)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("credentials", func(t *testing.T) {
+		w := request(http.MethodGet, path, "", false)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
@@ -5,12 +5,15 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
 	"strconv"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	_ "github.com/lib/pq"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -18,28 +21,84 @@ import (
 	"taskmanager/internal/app"
 	"taskmanager/internal/config"
 	"taskmanager/internal/db"
+	"taskmanager/internal/handler"
 	"taskmanager/internal/model"
 	"taskmanager/internal/security"
 )
 
+const (
+	scenarioTestUsername = "testuser45983x"
+	scenarioTestPassword = "testpassword45983x"
+)
+
 type hData struct {
 	testUsername string
 	testPassword string
 	router       *gin.Engine
 }
 
-// TestSimplePositiveScenario working database with tables is required.
 func TestSimplePositiveScenario(t *testing.T) {
-	router, conf, postgres := testSimplePositiveScenarioPrepareRouter(t)
+	conf, err := config.GetFromFile("../../../configs/conf.toml")
+	require.NoError(t, err)
+
+	t.Run("memory", func(t *testing.T) {
+		testSimplePositiveScenario(t, conf, model.NewMemory())
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		logger := logrus.New()
+		logger.SetOutput(io.Discard)
+
+		sqlitePool, err := db.CreateSQLitePool(filepath.Join(t.TempDir(), "test.db"))
+		require.NoError(t, err)
+
+		defer sqlitePool.Close()
+
+		sqlite := model.SQLite{
+			Pool:         sqlitePool,
+			QueryTimeout: conf.SQLite.QueryTimeout,
+			Logger:       logger,
+		}
+
+		require.NoError(t, sqlite.Migrate(context.Background()))
+
+		testSimplePositiveScenario(t, conf, sqlite)
+	})
+
+	// Working database is required, DB_ADDRESS overrides the address of the config. The pool is not created by
+	// db.Conf.CreatePool: it retries a refused connection for a minute instead of skipping the test.
+	t.Run("postgres", func(t *testing.T) {
+		logger := logrus.New()
+		logger.SetOutput(io.Discard)
+
+		postgresPool, err := sql.Open("postgres", db.Conf{ConnAddress: conf.Postgres.ConnAddress}.Address())
+		require.NoError(t, err)
+
+		if err := postgresPool.Ping(); err != nil {
+			t.Skipf("SKIP - failed to connect to the database to run this test: %v", err)
+		}
+
+		postgres := model.Postgres{
+			Pool:         postgresPool,
+			QueryTimeout: conf.Postgres.QueryTimeout,
+			Logger:       logger,
+		}
 
+		require.NoError(t, postgres.Migrate(context.Background()))
+
+		defer clearTestData(t, postgresPool, scenarioTestUsername, security.SaltPassword(scenarioTestPassword))
+
+		testSimplePositiveScenario(t, conf, postgres)
+	})
+}
+
+func testSimplePositiveScenario(t *testing.T, conf *config.Conf, storage handler.Storage) {
 	h := hData{
-		testUsername: "testuser45983x",
-		testPassword: "testpassword45983x",
-		router:       router,
+		testUsername: scenarioTestUsername,
+		testPassword: scenarioTestPassword,
+		router:       testSimplePositiveScenarioPrepareRouter(conf, storage),
 	}
 
-	defer clearTestData(t, postgres, h.testUsername, security.SaltPassword(h.testPassword))
-
 	// step 1
 	userID := h.createNewUser(t, conf.Server.ManageUsername, conf.Server.ManagePassword)
 
@@ -63,6 +122,12 @@ func TestSimplePositiveScenario(t *testing.T) {
 	// step 7
 	h.checkTaskStatusCompleted(t, taskID1)
 
+	// step 7.1
+	h.checkTaskHistory(t, taskID1, 2)
+
+	// step 7.2
+	h.getTaskRevision(t, taskID1, 0, taskTitle1)
+
 	// step 8
 	taskID2 := h.createTask(t, "45983_2")
 
@@ -83,26 +148,25 @@ func TestSimplePositiveScenario(t *testing.T) {
 	h.checkNoTasks(t)
 
 	// step 14
-	h.deleteUser(t, conf.Server.ManageUsername, conf.Server.ManagePassword, userID)
-}
+	h.checkTrash(t, 3)
 
-func testSimplePositiveScenarioPrepareRouter(t *testing.T) (*gin.Engine, *config.Conf, *sql.DB) {
-	var logger *logrus.Logger
+	// step 15
+	h.restoreTask(t, taskID2)
 
-	conf, err := config.GetFromFile("../../../configs/conf.toml")
-	require.NoError(t, err)
+	// step 16
+	h.checkTrash(t, 2)
 
-	postgresPool, err := db.Conf{ConnAddress: conf.Postgres.ConnAddress}.CreatePool(logger)
-	if err != nil {
-		t.Skipf("SKIP - failed to connect to the database to run this test: %v", err)
-	}
+	// step 17
+	h.deleteTasksPermanently(t, 3)
 
-	postgres := model.Postgres{
-		Pool:         postgresPool,
-		QueryTimeout: conf.Postgres.QueryTimeout,
-		Logger:       logger,
-	}
+	// step 18
+	h.checkTrash(t, 0)
+
+	// step 19
+	h.deleteUser(t, conf.Server.ManageUsername, conf.Server.ManagePassword, userID)
+}
 
+func testSimplePositiveScenarioPrepareRouter(conf *config.Conf, storage handler.Storage) *gin.Engine {
 	serverConf := Conf{
 		ManageUsername: conf.Server.ManageUsername,
 		ManagePassword: conf.Server.ManagePassword,
@@ -116,9 +180,9 @@ func testSimplePositiveScenarioPrepareRouter(t *testing.T) (*gin.Engine, *config
 		MetricsRoute: "/metrics",
 	}
 
-	serverConf.setRouters(context.Background(), postgres, router, metrics)
+	serverConf.setRouters(context.Background(), storage, router, metrics)
 
-	return router, conf, postgresPool
+	return router
 }
 
 func (h hData) createNewUser(t *testing.T, manageUsername, managePassword string) int {
@@ -257,8 +321,7 @@ func (h hData) checkTaskNewTitle(t *testing.T, taskID int, taskNameNew string) {
 
 func (h hData) updateTaskStatusCompleted(t *testing.T, taskID int) {
 	reqBody := struct {
-		Title     string `json:"title"`
-		Completed bool   `json:"completed"`
+		Completed bool `json:"completed"`
 	}{
 		Completed: true,
 	}
@@ -268,9 +331,10 @@ func (h hData) updateTaskStatusCompleted(t *testing.T, taskID int) {
 
 	w := httptest.NewRecorder()
 
-	req, err := http.NewRequest(http.MethodPut, "/api/v1/task/"+strconv.Itoa(taskID), bytes.NewReader(body))
+	req, err := http.NewRequest(http.MethodPatch, "/api/v1/task/"+strconv.Itoa(taskID), bytes.NewReader(body))
 	require.NoError(t, err)
 
+	req.Header.Set("Content-Type", "application/merge-patch+json")
 	req.SetBasicAuth(h.testUsername, h.testPassword)
 
 	h.router.ServeHTTP(w, req)
@@ -303,6 +367,51 @@ func (h hData) checkTaskStatusCompleted(t *testing.T, taskID int) {
 	return
 }
 
+func (h hData) checkTaskHistory(t *testing.T, taskID, expectedRevision int) {
+	w := httptest.NewRecorder()
+
+	req, err := http.NewRequest(http.MethodGet, "/api/v1/task/"+strconv.Itoa(taskID)+"/history", nil)
+	require.NoError(t, err)
+
+	req.SetBasicAuth(h.testUsername, h.testPassword)
+
+	h.router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var result []model.TaskChange
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+
+	require.NotEmpty(t, result)
+
+	assert.Equal(t, expectedRevision, result[len(result)-1].Revision)
+	assert.Equal(t, h.testUsername, result[len(result)-1].Actor)
+
+	return
+}
+
+func (h hData) getTaskRevision(t *testing.T, taskID, revision int, taskName string) {
+	w := httptest.NewRecorder()
+
+	req, err := http.NewRequest(
+		http.MethodGet, "/api/v1/task/"+strconv.Itoa(taskID)+"/history/"+strconv.Itoa(revision), nil,
+	)
+	require.NoError(t, err)
+
+	req.SetBasicAuth(h.testUsername, h.testPassword)
+
+	h.router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var result model.Task
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+
+	assert.Equal(t, taskName, result.Title)
+
+	return
+}
+
 func (h hData) deleteTask(t *testing.T, taskID int) {
 	w := httptest.NewRecorder()
 
@@ -386,6 +495,67 @@ func (h hData) checkNoTasks(t *testing.T) {
 	return
 }
 
+func (h hData) checkTrash(t *testing.T, expectedQuantity int) {
+	w := httptest.NewRecorder()
+
+	req, err := http.NewRequest(http.MethodGet, "/api/v1/tasks/trash", nil)
+	require.NoError(t, err)
+
+	req.SetBasicAuth(h.testUsername, h.testPassword)
+
+	h.router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var result []model.DeletedTask
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+
+	assert.Equal(t, expectedQuantity, len(result))
+
+	for _, task := range result {
+		assert.Equal(t, false, task.Deleted.IsZero())
+	}
+
+	return
+}
+
+func (h hData) restoreTask(t *testing.T, taskID int) {
+	w := httptest.NewRecorder()
+
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/task/"+strconv.Itoa(taskID)+"/restore", nil)
+	require.NoError(t, err)
+
+	req.SetBasicAuth(h.testUsername, h.testPassword)
+
+	h.router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	return
+}
+
+func (h hData) deleteTasksPermanently(t *testing.T, expectedQuantity int64) {
+	w := httptest.NewRecorder()
+
+	req, err := http.NewRequest(http.MethodDelete, "/api/v1/tasks/?permanent=true", nil)
+	require.NoError(t, err)
+
+	req.SetBasicAuth(h.testUsername, h.testPassword)
+
+	h.router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	result := struct {
+		Quantity int64 `json:"quantity"`
+	}{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+
+	assert.Equal(t, expectedQuantity, result.Quantity)
+
+	return
+}
+
 func (h hData) deleteUser(t *testing.T, manageUsername, managePassword string, userId int) {
 	w := httptest.NewRecorder()
 
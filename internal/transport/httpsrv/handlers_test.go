@@ -2,10 +2,12 @@ package httpsrv
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -15,6 +17,7 @@ import (
 	"taskmanager/internal/config"
 	"taskmanager/internal/handler"
 	"taskmanager/internal/model"
+	"taskmanager/internal/security"
 )
 
 type postgresTest struct {
@@ -38,22 +41,268 @@ func (p postgresTest) GetTasks(ctx context.Context, username, password string) (
 	return nil, p.err
 }
 
-func (p postgresTest) UpdateTask(ctx context.Context, username, password string, taskID int, setValues []string) error {
+func (p postgresTest) GetTasksPage(
+	ctx context.Context, username, password string, query model.TaskQuery,
+) ([]model.Task, error) {
+	return nil, p.err
+}
+
+func (p postgresTest) CountTasks(ctx context.Context, username, password string, completed *bool) (int, error) {
+	return 0, p.err
+}
+
+func (p postgresTest) ExportTasks(
+	ctx context.Context, username, password string, fn func(model.ExportedTask) error,
+) error {
+	return p.err
+}
+
+func (p postgresTest) UpdateTask(
+	ctx context.Context, username, password string, taskID, version int, update model.TaskUpdate,
+) error {
+	return p.err
+}
+
+func (p postgresTest) DeleteTask(ctx context.Context, username, password string, taskID, version int) error {
+	return p.err
+}
+
+func (p postgresTest) DeleteTaskPermanently(
+	ctx context.Context, username, password string, taskID, version int,
+) error {
+	return p.err
+}
+
+func (p postgresTest) RestoreTask(ctx context.Context, username, password string, taskID int) error {
 	return p.err
 }
 
-func (p postgresTest) DeleteTask(ctx context.Context, username, password string, taskID int) error {
+func (p postgresTest) GetTaskHistory(ctx context.Context, username, password string, taskID int) ([]model.TaskChange, error) {
+	return nil, p.err
+}
+
+func (p postgresTest) GetTasksHistory(
+	ctx context.Context, username, password string, taskIDs []int,
+) (map[int][]model.TaskChange, error) {
+	return nil, p.err
+}
+
+func (p postgresTest) GetTaskRevision(
+	ctx context.Context, username, password string, taskID, revision int,
+) (model.Task, error) {
+	return model.Task{}, p.err
+}
+
+func (p postgresTest) RevertTask(ctx context.Context, username, password string, taskID, revision int) error {
 	return p.err
 }
 
-func (p postgresTest) DeleteTasks(ctx context.Context, username, password string) (int64, error) {
-	return int64(p.userID), p.err
+func (p postgresTest) DeleteTasks(ctx context.Context, username, password string) ([]int, error) {
+	return make([]int, p.userID), p.err
+}
+
+func (p postgresTest) DeleteTasksPermanently(ctx context.Context, username, password string) ([]int, error) {
+	return make([]int, p.userID), p.err
+}
+
+func (p postgresTest) GetTrash(ctx context.Context, username, password string) ([]model.DeletedTask, error) {
+	return nil, p.err
+}
+
+func (p postgresTest) RestoreTasks(ctx context.Context, username, password string) ([]int, error) {
+	return make([]int, p.userID), p.err
+}
+
+func (p postgresTest) BatchTasks(
+	ctx context.Context, username, password string, operations []model.TaskOperation, atomic bool,
+) ([]model.TaskOperationResult, error) {
+	results := make([]model.TaskOperationResult, len(operations))
+
+	for i := range results {
+		results[i] = model.TaskOperationResult{TaskID: p.userID, Err: p.err}
+	}
+
+	return results, nil
 }
 
 func (p postgresTest) CreateNewUser(ctx context.Context, username string, password string) (int, error) {
 	return p.userID, p.err
 }
 
+func (p postgresTest) ReserveIdempotencyKey(
+	ctx context.Context, scope, key, fingerprint string, expires time.Time,
+) (model.IdempotentResponse, bool, error) {
+	return model.IdempotentResponse{}, true, p.err
+}
+
+func (p postgresTest) SaveIdempotentResponse(
+	ctx context.Context, scope, key string, response model.IdempotentResponse,
+) error {
+	return p.err
+}
+
+func (p postgresTest) DeleteIdempotencyKey(ctx context.Context, scope, key string) error {
+	return p.err
+}
+
+func (p postgresTest) CreateTaskWithInjection(ctx context.Context, username, password, title string) (int, error) {
+	return p.userID, p.err
+}
+
+func (p postgresTest) GetUserIDByCredentials(ctx context.Context, username, password string) (int, error) {
+	return p.userID, p.err
+}
+
+func (p postgresTest) CreateWebhook(ctx context.Context, userID int, webhook model.Webhook) (int, error) {
+	return p.userID, p.err
+}
+
+func (p postgresTest) GetWebhooks(ctx context.Context, userID int) ([]model.Webhook, error) {
+	return nil, p.err
+}
+
+func (p postgresTest) DeleteWebhook(ctx context.Context, userID, webhookID int) error {
+	return p.err
+}
+
+func (p postgresTest) EnableWebhook(ctx context.Context, userID, webhookID int) error {
+	return p.err
+}
+
+func (p postgresTest) GetWebhookDeliveries(
+	ctx context.Context, userID, webhookID, limit int,
+) ([]model.WebhookDelivery, error) {
+	return nil, p.err
+}
+
+func (p postgresTest) GetEvents(ctx context.Context, userID int, afterSeq int64, limit int) ([]model.Event, error) {
+	return nil, p.err
+}
+
+func (p postgresTest) GetLastEventSeq(ctx context.Context, userID int) (int64, error) {
+	return 0, p.err
+}
+
+func (p postgresTest) SetCalendarToken(ctx context.Context, userID int, tokenHash string) error {
+	return p.err
+}
+
+func (p postgresTest) DeleteCalendarToken(ctx context.Context, userID int) error {
+	return p.err
+}
+
+func (p postgresTest) GetCalendarTasks(ctx context.Context, tokenHash string) ([]model.Task, error) {
+	return nil, p.err
+}
+
+func (p postgresTest) CreateReminder(ctx context.Context, userID, taskID int, reminder model.Reminder) (int, error) {
+	return 0, p.err
+}
+
+func (p postgresTest) GetReminders(ctx context.Context, userID, taskID int) ([]model.Reminder, error) {
+	return nil, p.err
+}
+
+func (p postgresTest) DeleteReminder(ctx context.Context, userID, taskID, reminderID int) error {
+	return p.err
+}
+
+func (p postgresTest) GetNotifications(
+	ctx context.Context, userID int, query model.NotificationQuery,
+) ([]model.Notification, error) {
+	return nil, p.err
+}
+
+func (p postgresTest) GetUnreadNotifications(ctx context.Context, userID int) (map[string]int, error) {
+	return nil, p.err
+}
+
+func (p postgresTest) ReadNotification(ctx context.Context, userID, notificationID int) error {
+	return p.err
+}
+
+func (p postgresTest) ReadNotifications(ctx context.Context, userID int) (int64, error) {
+	return 0, p.err
+}
+
+func (p postgresTest) GetNotificationPreferences(
+	ctx context.Context, userID int,
+) ([]model.NotificationPreference, error) {
+	return nil, p.err
+}
+
+func (p postgresTest) SetNotificationPreferences(
+	ctx context.Context, userID int, preferences []model.NotificationPreference,
+) error {
+	return p.err
+}
+
+func (p postgresTest) CreateProject(ctx context.Context, userID int, name string) (int, error) {
+	return 0, p.err
+}
+
+func (p postgresTest) GetProjects(ctx context.Context, userID int) ([]model.Project, error) {
+	return nil, p.err
+}
+
+func (p postgresTest) DeleteProject(ctx context.Context, userID, projectID int) error {
+	return p.err
+}
+
+func (p postgresTest) GetProjectMembers(ctx context.Context, userID, projectID int) ([]model.ProjectMember, error) {
+	return nil, p.err
+}
+
+func (p postgresTest) AddProjectMember(ctx context.Context, userID, projectID int, username string) (int, bool, error) {
+	return 0, false, p.err
+}
+
+func (p postgresTest) RemoveProjectMember(ctx context.Context, userID, projectID, memberID int) error {
+	return p.err
+}
+
+func (p postgresTest) IsProjectMember(ctx context.Context, userID, projectID int) (bool, error) {
+	return false, p.err
+}
+
+func (p postgresTest) GetProjectTasks(ctx context.Context, userID, projectID int) ([]model.Task, error) {
+	return nil, p.err
+}
+
+func (p postgresTest) SetTaskProject(ctx context.Context, username, password string, taskID, projectID int) error {
+	return p.err
+}
+
+func (p postgresTest) SetTaskTags(ctx context.Context, userID, taskID int, tags []string) error {
+	return p.err
+}
+
+func (p postgresTest) GetTasksTags(ctx context.Context, userID int, taskIDs []int) (map[int][]string, error) {
+	return nil, p.err
+}
+
+func (p postgresTest) CreateTaskComment(ctx context.Context, userID, taskID int, text string) (int, error) {
+	return 0, p.err
+}
+
+func (p postgresTest) GetTasksComments(
+	ctx context.Context, userID int, taskIDs []int,
+) (map[int][]model.TaskComment, error) {
+	return nil, p.err
+}
+
+func (p postgresTest) DeleteTaskComment(ctx context.Context, userID, commentID int) error {
+	return p.err
+}
+
+func (p postgresTest) SetTaskParent(ctx context.Context, username, password string, taskID, parentID int) error {
+	return p.err
+}
+
+func (p postgresTest) GetSubtasks(ctx context.Context, userID int, parentIDs []int) (map[int][]model.Task, error) {
+	return nil, p.err
+}
+
 func TestV1CreateUser(t *testing.T) {
 	conf, err := config.GetFromFile("../../../configs/conf.toml")
 	require.NoError(t, err)
@@ -164,6 +413,424 @@ func TestV1CreateUser(t *testing.T) {
 	}
 }
 
+func TestV1TaskPreconditions(t *testing.T) {
+	cases := []struct {
+		name         string
+		postgres     postgresTest
+		method       string
+		route        string
+		headers      map[string]string
+		body         string
+		expectedCode int
+	}{
+		{
+			name:         "get_task_not_modified",
+			method:       http.MethodGet,
+			route:        "/api/v1/task/1",
+			headers:      map[string]string{"If-None-Match": `"0"`},
+			expectedCode: http.StatusNotModified,
+		},
+		{
+			name:         "get_task_modified",
+			method:       http.MethodGet,
+			route:        "/api/v1/task/1",
+			headers:      map[string]string{"If-None-Match": `"7"`},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "update_task_invalid_if_match",
+			method:       http.MethodPut,
+			route:        "/api/v1/task/1",
+			headers:      map[string]string{"If-Match": `W/"1"`},
+			body:         `{"title": "task1", "completed": false}`,
+			expectedCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:         "update_task_if_match_list_mismatch",
+			method:       http.MethodPut,
+			route:        "/api/v1/task/1",
+			headers:      map[string]string{"If-Match": `W/"0", "1", "2"`},
+			body:         `{"title": "task1", "completed": false}`,
+			expectedCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:         "update_task_version_mismatch",
+			postgres:     postgresTest{err: model.ErrVersionMismatch},
+			method:       http.MethodPut,
+			route:        "/api/v1/task/1",
+			headers:      map[string]string{"If-Match": `"1"`},
+			body:         `{"title": "task1", "completed": false}`,
+			expectedCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:         "patch_task_version_mismatch",
+			postgres:     postgresTest{err: model.ErrVersionMismatch},
+			method:       http.MethodPatch,
+			route:        "/api/v1/task/1",
+			headers:      map[string]string{"If-Match": `"1"`, "Content-Type": "application/merge-patch+json"},
+			body:         `{"completed": true}`,
+			expectedCode: http.StatusPreconditionFailed,
+		},
+		{
+			name:         "patch_task_test_failed",
+			method:       http.MethodPatch,
+			route:        "/api/v1/task/1",
+			headers:      map[string]string{"Content-Type": "application/json-patch+json"},
+			body:         `[{"op": "test", "path": "/title", "value": "task1"}]`,
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "patch_task_unsupported_media_type",
+			method:       http.MethodPatch,
+			route:        "/api/v1/task/1",
+			headers:      map[string]string{"Content-Type": "text/plain"},
+			body:         `title=task1`,
+			expectedCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:         "patch_task",
+			method:       http.MethodPatch,
+			route:        "/api/v1/task/1",
+			headers:      map[string]string{"Content-Type": "application/json"},
+			body:         `{"title": "task1"}`,
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "update_task_not_full",
+			method:       http.MethodPut,
+			route:        "/api/v1/task/1",
+			body:         `{"title": "task1"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "delete_task_version_mismatch",
+			postgres:     postgresTest{err: model.ErrVersionMismatch},
+			method:       http.MethodDelete,
+			route:        "/api/v1/task/1",
+			headers:      map[string]string{"If-Match": `"1"`},
+			expectedCode: http.StatusPreconditionFailed,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			router := testHandlersPrepareRouter(tt.postgres, "admin", "admin")
+			w := httptest.NewRecorder()
+
+			req, err := http.NewRequest(tt.method, tt.route, strings.NewReader(tt.body))
+			require.NoError(t, err)
+
+			req.SetBasicAuth("user", "password")
+
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
+
+func TestV1BatchTasks(t *testing.T) {
+	cases := []struct {
+		name             string
+		postgres         postgresTest
+		body             string
+		expectedCode     int
+		expectedStatuses []int
+		expectedTypes    []string
+	}{
+		{
+			name:             "applied",
+			postgres:         postgresTest{userID: 1},
+			body:             `{"operations": [{"op": "create", "title": "task1"}, {"op": "update", "taskId": 1, "completed": true}]}`,
+			expectedCode:     http.StatusOK,
+			expectedStatuses: []int{http.StatusCreated, http.StatusOK},
+		},
+		{
+			name:             "not_atomic_invalid_operation",
+			postgres:         postgresTest{userID: 1},
+			body:             `{"operations": [{"op": "move", "taskId": 1}, {"op": "delete", "taskId": 1}]}`,
+			expectedCode:     http.StatusOK,
+			expectedStatuses: []int{http.StatusBadRequest, http.StatusOK},
+		},
+		{
+			name:             "atomic_invalid_operation",
+			body:             `{"atomic": true, "operations": [{"op": "delete"}, {"op": "delete", "taskId": 1}]}`,
+			expectedCode:     http.StatusBadRequest,
+			expectedStatuses: []int{http.StatusBadRequest, http.StatusFailedDependency},
+		},
+		{
+			name:             "not_atomic_task_not_found",
+			postgres:         postgresTest{err: model.ErrTaskNotFound},
+			body:             `{"operations": [{"op": "delete", "taskId": 1}]}`,
+			expectedCode:     http.StatusOK,
+			expectedStatuses: []int{http.StatusBadRequest},
+		},
+		{
+			name:             "atomic_version_mismatch",
+			postgres:         postgresTest{err: model.ErrVersionMismatch},
+			body:             `{"atomic": true, "operations": [{"op": "delete", "taskId": 1, "ifMatch": "\"2\""}]}`,
+			expectedCode:     http.StatusPreconditionFailed,
+			expectedStatuses: []int{http.StatusPreconditionFailed},
+		},
+		{
+			name:             "empty_title",
+			body:             `{"operations": [{"op": "create", "title": ""}]}`,
+			expectedCode:     http.StatusOK,
+			expectedStatuses: []int{http.StatusBadRequest},
+			expectedTypes:    []string{"PARAMETER_REQUIRED"},
+		},
+		{
+			name:             "long_title",
+			body:             `{"operations": [{"op": "create", "title": "` + strings.Repeat("a", 201) + `"}]}`,
+			expectedCode:     http.StatusOK,
+			expectedStatuses: []int{http.StatusBadRequest},
+			expectedTypes:    []string{"PARAMETER_TOO_LONG"},
+		},
+		{
+			name:         "no_operations",
+			body:         `{"operations": []}`,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			router := testHandlersPrepareRouter(tt.postgres, "admin", "admin")
+			w := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v1/tasks/batch", strings.NewReader(tt.body))
+			require.NoError(t, err)
+
+			req.SetBasicAuth("user", "password")
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+
+			if tt.expectedStatuses == nil {
+				return
+			}
+
+			var res struct {
+				Results []struct {
+					Status int `json:"status"`
+					Error  *struct {
+						Type string `json:"type"`
+					} `json:"error"`
+				} `json:"results"`
+			}
+
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+
+			statuses := make([]int, len(res.Results))
+			for i, r := range res.Results {
+				statuses[i] = r.Status
+			}
+
+			assert.Equal(t, tt.expectedStatuses, statuses)
+
+			if tt.expectedTypes == nil {
+				return
+			}
+
+			types := make([]string, len(res.Results))
+			for i, r := range res.Results {
+				if r.Error != nil {
+					types[i] = r.Error.Type
+				}
+			}
+
+			assert.Equal(t, tt.expectedTypes, types)
+		})
+	}
+}
+
+func TestV2ProblemDetails(t *testing.T) {
+	cases := []struct {
+		name            string
+		postgres        postgresTest
+		username        string
+		method          string
+		route           string
+		body            string
+		expectedCode    int
+		expectedProblem handler.Problem
+	}{
+		{
+			name:         "field_required",
+			username:     "user",
+			method:       http.MethodPost,
+			route:        "/api/v2/task/",
+			body:         `{}`,
+			expectedCode: http.StatusBadRequest,
+			expectedProblem: handler.Problem{
+				Type:     "urn:taskmanager:problem:parameter-required",
+				Title:    "Parameter required",
+				Status:   http.StatusBadRequest,
+				Detail:   "title",
+				Instance: "/api/v2/task/",
+				Errors:   []handler.FieldError{{Field: "title", Message: "required"}},
+			},
+		},
+		{
+			name:         "field_type",
+			username:     "user",
+			method:       http.MethodPost,
+			route:        "/api/v2/task/",
+			body:         `{"title": 1}`,
+			expectedCode: http.StatusBadRequest,
+			expectedProblem: handler.Problem{
+				Type:     "urn:taskmanager:problem:parameter-required",
+				Title:    "Parameter required",
+				Status:   http.StatusBadRequest,
+				Detail:   "title",
+				Instance: "/api/v2/task/",
+				Errors:   []handler.FieldError{{Field: "title", Message: "number instead of string"}},
+			},
+		},
+		{
+			name:         "username_length",
+			username:     "admin",
+			method:       http.MethodPost,
+			route:        "/api/v2/manage/user",
+			body:         `{"username": "q", "password": "qwerty"}`,
+			expectedCode: http.StatusBadRequest,
+			expectedProblem: handler.Problem{
+				Type:     "urn:taskmanager:problem:username-required",
+				Title:    "Invalid username",
+				Status:   http.StatusBadRequest,
+				Detail:   "min 3, max 20",
+				Instance: "/api/v2/manage/user",
+				Errors:   []handler.FieldError{{Field: "username", Message: "length"}},
+			},
+		},
+		{
+			name:         "unauthorized",
+			method:       http.MethodGet,
+			route:        "/api/v2/tasks/",
+			expectedCode: http.StatusUnauthorized,
+			expectedProblem: handler.Problem{
+				Type:     "about:blank",
+				Title:    "Unauthorized",
+				Status:   http.StatusUnauthorized,
+				Instance: "/api/v2/tasks/",
+			},
+		},
+		{
+			name:         "manage_unauthorized",
+			username:     "user",
+			method:       http.MethodDelete,
+			route:        "/api/v2/manage/user/1",
+			expectedCode: http.StatusUnauthorized,
+			expectedProblem: handler.Problem{
+				Type:     "about:blank",
+				Title:    "Unauthorized",
+				Status:   http.StatusUnauthorized,
+				Instance: "/api/v2/manage/user/1",
+			},
+		},
+		{
+			name:         "forbidden",
+			postgres:     postgresTest{err: model.ErrUserNotFound},
+			username:     "user",
+			method:       http.MethodPost,
+			route:        "/api/v2/task/",
+			body:         `{"title": "task"}`,
+			expectedCode: http.StatusForbidden,
+			expectedProblem: handler.Problem{
+				Type:     "about:blank",
+				Title:    "Forbidden",
+				Status:   http.StatusForbidden,
+				Instance: "/api/v2/task/",
+			},
+		},
+		{
+			name:         "task_not_found",
+			postgres:     postgresTest{err: model.ErrTaskNotFound},
+			username:     "user",
+			method:       http.MethodGet,
+			route:        "/api/v2/task/24",
+			expectedCode: http.StatusBadRequest,
+			expectedProblem: handler.Problem{
+				Type:     "urn:taskmanager:problem:task-not-found",
+				Title:    "Task not found",
+				Status:   http.StatusBadRequest,
+				Detail:   "24",
+				Instance: "/api/v2/task/24",
+			},
+		},
+		{
+			name:         "atomic_batch",
+			postgres:     postgresTest{userID: 1, err: model.ErrVersionMismatch},
+			username:     "user",
+			method:       http.MethodPost,
+			route:        "/api/v2/tasks/batch",
+			body:         `{"atomic": true, "operations": [{"op": "delete", "taskId": 1}]}`,
+			expectedCode: http.StatusPreconditionFailed,
+			expectedProblem: handler.Problem{
+				Type:     "urn:taskmanager:problem:task-version-mismatch",
+				Title:    "Task version mismatch",
+				Status:   http.StatusPreconditionFailed,
+				Detail:   "operations[0]: 1",
+				Instance: "/api/v2/tasks/batch",
+				Results: []handler.BatchTaskOperationResult{{
+					Status: http.StatusPreconditionFailed,
+					TaskID: 1,
+					Error: &handler.HTTPError{
+						Type:    "TASK_VERSION_MISMATCH",
+						Comment: "1",
+					},
+				}},
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			router := testHandlersPrepareRouter(tt.postgres, "admin", "admin")
+			w := httptest.NewRecorder()
+
+			req, err := http.NewRequest(tt.method, tt.route, strings.NewReader(tt.body))
+			require.NoError(t, err)
+
+			if tt.username != "" {
+				req.SetBasicAuth(tt.username, tt.username)
+			}
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+
+			var problem handler.Problem
+
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, tt.expectedProblem, problem)
+		})
+	}
+}
+
+func TestV1UsernameLengthCompatibility(t *testing.T) {
+	router := testHandlersPrepareRouter(postgresTest{}, "admin", "admin")
+	w := httptest.NewRecorder()
+
+	body := `{"username": "q", "password": "qwerty"}`
+
+	req, err := http.NewRequest(http.MethodPost, "/api/v1/manage/user", strings.NewReader(body))
+	require.NoError(t, err)
+
+	req.SetBasicAuth("admin", "admin")
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"type": "min 3, max 20", "comment": "USERNAME_REQUIRED"}`, w.Body.String())
+}
+
 func testHandlersPrepareRouter(postgres postgresTest, manageUsername, managePassword string) *gin.Engine {
 	serverConf := Conf{
 		ManageUsername: manageUsername,
@@ -182,3 +849,42 @@ func testHandlersPrepareRouter(postgres postgresTest, manageUsername, managePass
 
 	return router
 }
+
+// newTestRouter the router of the handlers on the memory storage with one user, the password is not salted.
+func newTestRouter(t *testing.T) (*gin.Engine, model.Memory, string, string) {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+
+	storage := model.NewMemory()
+
+	username, password := "testuser", "testpassword"
+
+	_, err := storage.CreateNewUser(context.Background(), username, security.SaltPassword(password))
+	require.NoError(t, err)
+
+	serverConf := Conf{
+		ManageUsername: "manage",
+		ManagePassword: "manage",
+	}
+
+	router := gin.New()
+	serverConf.setRouters(context.Background(), storage, router, app.Metrics{MetricsRoute: "/metrics"})
+
+	return router, storage, username, password
+}
+
+// serveTestRequest the request with the JSON body, the basic auth is not set if username is empty.
+func serveTestRequest(router *gin.Engine, method, path, body, username, password string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if username != "" {
+		r.SetBasicAuth(username, password)
+	}
+
+	r.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	return w
+}
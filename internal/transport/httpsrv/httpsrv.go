@@ -17,18 +17,44 @@ import (
 
 	"taskmanager/internal/app"
 	"taskmanager/internal/handler"
-	"taskmanager/internal/model"
+)
+
+const defaultEventsHeartbeat = 15 * time.Second
+
+const (
+	defaultWebSocketSendQueueSize   = 64
+	defaultWebSocketMaxMessageBytes = 64 << 10
+	defaultWebSocketWriteTimeout    = 10 * time.Second
+)
+
+const (
+	defaultGraphQLMaxDepth      = 10
+	defaultGraphQLMaxComplexity = 1000
 )
 
 type Conf struct {
-	Port               string
-	ManageUsername     string
-	ManagePassword     string
-	Mode               string
-	MaxHeaderBytes     int
-	ReadTimeoutSecond  int
-	WriteTimeoutSecond int
-	MaxShutdownTime    int
+	Port                   string
+	ManageUsername         string
+	ManagePassword         string
+	Mode                   string
+	MaxHeaderBytes         int
+	ReadTimeoutSecond      int
+	WriteTimeoutSecond     int
+	MaxShutdownTime        int
+	IdempotencyKeyTTLHours int
+	// EventsHeartbeatSeconds 0 - defaultEventsHeartbeat.
+	EventsHeartbeatSeconds int
+	// EventSubscriber nil - the event streams read the event log only on the heartbeats.
+	EventSubscriber handler.EventSubscriber
+	// WebSocketSendQueueSize, WebSocketMaxMessageBytes, WebSocketWriteTimeoutSeconds 0 - the defaults.
+	WebSocketSendQueueSize       int
+	WebSocketMaxMessageBytes     int
+	WebSocketWriteTimeoutSeconds int
+	// GraphQLMaxDepth, GraphQLMaxComplexity 0 - the defaults.
+	GraphQLMaxDepth      int
+	GraphQLMaxComplexity int
+	// WebhookAllowPrivateNetworks the webhooks may target the server and its network.
+	WebhookAllowPrivateNetworks bool
 	CORS
 }
 
@@ -39,7 +65,7 @@ type CORS struct {
 }
 
 func (conf Conf) RunHTTPServer(
-	ctx context.Context, postgres model.Postgres, metrics app.Metrics, logger *logrus.Logger,
+	ctx context.Context, storage handler.Storage, metrics app.Metrics, logger *logrus.Logger,
 ) error {
 	gin.DisableConsoleColor()
 	gin.SetMode(conf.Mode)
@@ -49,6 +75,7 @@ func (conf Conf) RunHTTPServer(
 	confCors.AllowHeaders = conf.AllowHeaders
 	confCors.AllowMethods = conf.AllowMethods
 	confCors.AllowOrigins = conf.AllowOrigins
+	confCors.ExposeHeaders = []string{"ETag", "Idempotent-Replayed"}
 
 	router := gin.New()
 
@@ -59,7 +86,7 @@ func (conf Conf) RunHTTPServer(
 		gin.LoggerWithFormatter(createLoggerFormatter()),
 	)
 
-	conf.setRouters(ctx, postgres, router, metrics)
+	conf.setRouters(ctx, storage, router, metrics)
 
 	server := &http.Server{
 		Addr:           ":" + conf.Port,
@@ -88,7 +115,16 @@ func (conf Conf) RunHTTPServer(
 	return nil
 }
 
-func (conf Conf) setRouters(ctx context.Context, postgres handler.PostgresDB, router *gin.Engine, metrics app.Metrics) {
+// NewRouter the routes without the middleware of the server, to run the API in process.
+func (conf Conf) NewRouter(ctx context.Context, storage handler.Storage, metrics app.Metrics) *gin.Engine {
+	router := gin.New()
+
+	conf.setRouters(ctx, storage, router, metrics)
+
+	return router
+}
+
+func (conf Conf) setRouters(ctx context.Context, storage handler.Storage, router *gin.Engine, metrics app.Metrics) {
 	// Swagger(OpenAPI).
 	router.GET("/doc/*any", swag.WrapHandler(swagFiles.Handler))
 
@@ -96,29 +132,174 @@ func (conf Conf) setRouters(ctx context.Context, postgres handler.PostgresDB, ro
 	router.GET(metrics.MetricsRoute, gin.WrapH(promhttp.Handler()))
 
 	api := router.Group("/api")
+
 	v1 := api.Group("/v1")
+	// The handlers of v1, the errors are application/problem+json.
+	v2 := api.Group("/v2", handler.ProblemDetails())
+
+	if conf.IdempotencyKeyTTLHours > 0 {
+		idempotency := handler.Idempotency(ctx, storage, time.Hour*time.Duration(conf.IdempotencyKeyTTLHours),
+			conf.ManageUsername, conf.ManagePassword)
+
+		v1.Use(idempotency)
+		v2.Use(idempotency)
+	}
+
+	conf.setV1Routers(ctx, storage, v1, gin.BasicAuth(gin.Accounts{conf.ManageUsername: conf.ManagePassword}))
+	conf.setV1Routers(ctx, storage, v2, handler.BasicAuth(conf.ManageUsername, conf.ManagePassword))
+
+	// Only in /api/v1, the errors after the upgrade are messages.
+	v1.GET("/ws", handler.V1WebSocket(ctx, storage, conf.EventSubscriber, conf.webSocketConf()))
 
-	manage := v1.Group("/manage", gin.BasicAuth(gin.Accounts{conf.ManageUsername: conf.ManagePassword}))
+	// Not versioned, the schema evolves without versions.
+	graphQL := handler.GraphQL(ctx, storage, conf.graphQLConf())
+
+	api.GET("/graphql", graphQL)
+	api.POST("/graphql", graphQL)
+}
+
+func (conf Conf) setV1Routers(
+	ctx context.Context, storage handler.Storage, v1 *gin.RouterGroup, manageAuth gin.HandlerFunc,
+) {
+	manage := v1.Group("/manage", manageAuth)
 	{
-		manage.POST("/user", handler.V1CreateUser(ctx, postgres))
-		manage.DELETE("/user/:userId", handler.V1DeleteUser(ctx, postgres))
+		manage.POST("/user", handler.V1CreateUser(ctx, storage))
+		manage.DELETE("/user/:userId", handler.V1DeleteUser(ctx, storage))
+
+		setWebhookRouters(ctx, storage, manage.Group("/webhooks"), conf.webhookConf())
 	}
 
+	v1.GET("/me", handler.V1GetMe(ctx, storage))
+
 	task := v1.Group("/task")
 	{
-		task.POST("/", handler.V1CreateTask(ctx, postgres))
-		task.GET("/:taskId", handler.V1GetTask(ctx, postgres))
-		task.PUT("/:taskId", handler.V1UpdateTask(ctx, postgres))
-		task.DELETE("/:taskId", handler.V1DeleteTask(ctx, postgres))
+		task.POST("/", handler.V1CreateTask(ctx, storage))
+		task.GET("/:taskId", handler.V1GetTask(ctx, storage))
+		task.PUT("/:taskId", handler.V1UpdateTask(ctx, storage))
+		task.PATCH("/:taskId", handler.V1PatchTask(ctx, storage))
+		task.DELETE("/:taskId", handler.V1DeleteTask(ctx, storage))
+		task.POST("/:taskId/restore", handler.V1RestoreTask(ctx, storage))
+
+		task.GET("/:taskId/history", handler.V1GetTaskHistory(ctx, storage))
+		task.GET("/:taskId/history/:revision", handler.V1GetTaskRevision(ctx, storage))
+		task.POST("/:taskId/history/:revision/revert", handler.V1RevertTask(ctx, storage))
+
+		task.POST("/:taskId/reminders", handler.V1CreateReminder(ctx, storage))
+		task.GET("/:taskId/reminders", handler.V1GetReminders(ctx, storage))
+		task.DELETE("/:taskId/reminders/:reminderId", handler.V1DeleteReminder(ctx, storage))
+
+		task.PUT("/:taskId/project", handler.V1SetTaskProject(ctx, storage))
 
-		task.POST("/create-task-injection", handler.V1CreateTaskWithInjection(ctx, postgres))
+		task.POST("/create-task-injection", handler.V1CreateTaskWithInjection(ctx, storage))
 	}
 
 	tasks := v1.Group("/tasks")
 	{
-		tasks.GET("/", handler.V1GetTasks(ctx, postgres))
-		tasks.DELETE("/", handler.V1DeleteTasks(ctx, postgres))
+		tasks.GET("/", handler.V1GetTasks(ctx, storage))
+		tasks.DELETE("/", handler.V1DeleteTasks(ctx, storage))
+		tasks.POST("/batch", handler.V1BatchTasks(ctx, storage))
+		tasks.GET("/export", handler.V1ExportTasks(ctx, storage))
+		tasks.POST("/import", handler.V1ImportTasks(ctx, storage))
+
+		tasks.GET("/trash", handler.V1GetTrash(ctx, storage))
+		tasks.POST("/trash/restore", handler.V1RestoreTasks(ctx, storage))
+
+		tasks.GET("/events", handler.V1GetTaskEvents(ctx, storage, conf.EventSubscriber, conf.eventsHeartbeat()))
+	}
+
+	setWebhookRouters(ctx, storage, v1.Group("/webhooks"), conf.webhookConf())
+
+	notifications := v1.Group("/notifications")
+	{
+		notifications.GET("/", handler.V1GetNotifications(ctx, storage))
+		notifications.POST("/read", handler.V1ReadNotifications(ctx, storage))
+		notifications.POST("/:notificationId/read", handler.V1ReadNotification(ctx, storage))
+		notifications.GET("/preferences", handler.V1GetNotificationPreferences(ctx, storage))
+		notifications.PUT("/preferences", handler.V1UpdateNotificationPreferences(ctx, storage))
+	}
+
+	projects := v1.Group("/projects")
+	{
+		projects.POST("/", handler.V1CreateProject(ctx, storage))
+		projects.GET("/", handler.V1GetProjects(ctx, storage))
+		projects.DELETE("/:projectId", handler.V1DeleteProject(ctx, storage))
+		projects.GET("/:projectId/tasks", handler.V1GetProjectTasks(ctx, storage))
+		projects.GET("/:projectId/members", handler.V1GetProjectMembers(ctx, storage))
+		projects.POST("/:projectId/members", handler.V1AddProjectMember(ctx, storage))
+		projects.DELETE("/:projectId/members/:userId", handler.V1RemoveProjectMember(ctx, storage))
+	}
+
+	calendar := v1.Group("/calendar")
+	{
+		calendar.POST("/token", handler.V1CreateCalendarToken(ctx, storage))
+		calendar.DELETE("/token", handler.V1DeleteCalendarToken(ctx, storage))
+		// The token is the secret, no basic auth.
+		calendar.GET("/:file", handler.V1GetCalendar(ctx, storage))
+	}
+}
+
+// setWebhookRouters the webhooks of the user, of the manager in the manage group.
+func setWebhookRouters(
+	ctx context.Context, storage handler.Storage, webhooks *gin.RouterGroup, conf handler.WebhookConf,
+) {
+	webhooks.POST("/", handler.V1CreateWebhook(ctx, storage, conf))
+	webhooks.GET("/", handler.V1GetWebhooks(ctx, storage))
+	webhooks.DELETE("/:webhookId", handler.V1DeleteWebhook(ctx, storage))
+	webhooks.POST("/:webhookId/enable", handler.V1EnableWebhook(ctx, storage))
+	webhooks.GET("/:webhookId/deliveries", handler.V1GetWebhookDeliveries(ctx, storage))
+}
+
+func (conf Conf) eventsHeartbeat() time.Duration {
+	if conf.EventsHeartbeatSeconds <= 0 {
+		return defaultEventsHeartbeat
+	}
+
+	return time.Second * time.Duration(conf.EventsHeartbeatSeconds)
+}
+
+func (conf Conf) webhookConf() handler.WebhookConf {
+	return handler.WebhookConf{AllowPrivateNetworks: conf.WebhookAllowPrivateNetworks}
+}
+
+func (conf Conf) webSocketConf() handler.WebSocketConf {
+	webSocketConf := handler.WebSocketConf{
+		SendQueueSize:   conf.WebSocketSendQueueSize,
+		MaxMessageBytes: conf.WebSocketMaxMessageBytes,
+		WriteTimeout:    time.Second * time.Duration(conf.WebSocketWriteTimeoutSeconds),
+		Heartbeat:       conf.eventsHeartbeat(),
+		AllowOrigins:    conf.AllowOrigins,
 	}
+
+	if webSocketConf.SendQueueSize <= 0 {
+		webSocketConf.SendQueueSize = defaultWebSocketSendQueueSize
+	}
+
+	if webSocketConf.MaxMessageBytes <= 0 {
+		webSocketConf.MaxMessageBytes = defaultWebSocketMaxMessageBytes
+	}
+
+	if webSocketConf.WriteTimeout <= 0 {
+		webSocketConf.WriteTimeout = defaultWebSocketWriteTimeout
+	}
+
+	return webSocketConf
+}
+
+func (conf Conf) graphQLConf() handler.GraphQLConf {
+	graphQLConf := handler.GraphQLConf{
+		MaxDepth:      conf.GraphQLMaxDepth,
+		MaxComplexity: conf.GraphQLMaxComplexity,
+	}
+
+	if graphQLConf.MaxDepth <= 0 {
+		graphQLConf.MaxDepth = defaultGraphQLMaxDepth
+	}
+
+	if graphQLConf.MaxComplexity <= 0 {
+		graphQLConf.MaxComplexity = defaultGraphQLMaxComplexity
+	}
+
+	return graphQLConf
 }
 
 func createLoggerFormatter() func(p gin.LogFormatterParams) string {
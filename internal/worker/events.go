@@ -0,0 +1,38 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type EventsPurger interface {
+	PurgeEvents(ctx context.Context, before time.Time) (int64, error)
+}
+
+type EventsConf struct {
+	RetentionDays        int
+	PurgeIntervalMinutes int
+}
+
+// RunEventsPurge deletes the events older than the retention period from the event log.
+// Blocks until the context is canceled.
+func (conf EventsConf) RunEventsPurge(ctx context.Context, postgres EventsPurger, logger *logrus.Logger) {
+	runPeriodically(ctx, time.Minute*time.Duration(conf.PurgeIntervalMinutes), func(ctx context.Context) {
+		before := time.Now().Add(-time.Hour * hoursInDay * time.Duration(conf.RetentionDays))
+
+		quantity, err := postgres.PurgeEvents(ctx, before)
+		if err != nil {
+			logger.Errorf("purge events: %v", err)
+
+			return
+		}
+
+		if quantity > 0 {
+			logger.Infof("purge events: %d events deleted", quantity)
+		}
+	})
+
+	logger.Info("stop events purge: ok")
+}
@@ -0,0 +1,37 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type IdempotencyKeysPurger interface {
+	PurgeIdempotencyKeys(ctx context.Context, before time.Time) (int64, error)
+}
+
+type IdempotencyConf struct {
+	PurgeIntervalMinutes int
+}
+
+// RunIdempotencyKeysPurge deletes expired idempotency keys and their stored responses.
+// Blocks until the context is canceled.
+func (conf IdempotencyConf) RunIdempotencyKeysPurge(
+	ctx context.Context, postgres IdempotencyKeysPurger, logger *logrus.Logger,
+) {
+	runPeriodically(ctx, time.Minute*time.Duration(conf.PurgeIntervalMinutes), func(ctx context.Context) {
+		quantity, err := postgres.PurgeIdempotencyKeys(ctx, time.Now())
+		if err != nil {
+			logger.Errorf("purge idempotency keys: %v", err)
+
+			return
+		}
+
+		if quantity > 0 {
+			logger.Infof("purge idempotency keys: %d keys deleted", quantity)
+		}
+	})
+
+	logger.Info("stop idempotency keys purge: ok")
+}
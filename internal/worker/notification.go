@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type NotificationsPurger interface {
+	PurgeNotifications(ctx context.Context, before time.Time, maxPerUser int) (int64, error)
+}
+
+type NotificationConf struct {
+	RetentionDays int
+	// MaxPerUser the newest notifications kept of each user, 0 - no limit.
+	MaxPerUser           int
+	PurgeIntervalMinutes int
+}
+
+// RunNotificationsPurge deletes the notifications older than the retention period, read or not, and the ones
+// beyond the limit of the user.
+// Blocks until the context is canceled.
+func (conf NotificationConf) RunNotificationsPurge(
+	ctx context.Context, postgres NotificationsPurger, logger *logrus.Logger,
+) {
+	runPeriodically(ctx, time.Minute*time.Duration(conf.PurgeIntervalMinutes), func(ctx context.Context) {
+		before := time.Now().Add(-time.Hour * hoursInDay * time.Duration(conf.RetentionDays))
+
+		quantity, err := postgres.PurgeNotifications(ctx, before, conf.MaxPerUser)
+		if err != nil {
+			logger.Errorf("purge notifications: %v", err)
+
+			return
+		}
+
+		if quantity > 0 {
+			logger.Infof("purge notifications: %d notifications deleted", quantity)
+		}
+	})
+
+	logger.Info("stop notifications purge: ok")
+}
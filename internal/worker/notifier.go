@@ -0,0 +1,226 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+
+	"taskmanager/internal/model"
+)
+
+var (
+	errReminderWebhookNotFound = errors.New("no active webhook subscribed to " + model.EventTaskReminder)
+	errReminderEmailRequired   = errors.New("email required")
+)
+
+type WebhookEnqueuer interface {
+	EnqueueWebhookEvent(ctx context.Context, event model.Event) (int64, error)
+}
+
+type EventSaver interface {
+	SaveEvent(ctx context.Context, userID int, event model.Event) (int64, error)
+}
+
+type NotificationCreator interface {
+	CreateNotification(ctx context.Context, notification model.Notification) (int, error)
+}
+
+type InAppSaver interface {
+	EventSaver
+	NotificationCreator
+}
+
+// EventNotifier wakes the event streams of the user, event.Hub.
+type EventNotifier interface {
+	Notify(userID int)
+}
+
+// WebhookNotifier enqueues the task.reminder event to the webhooks of the user and of the manager subscribed to it,
+// they are sent by RunWebhookDeliveries with the signatures and the retries of the other events.
+type WebhookNotifier struct {
+	Postgres WebhookEnqueuer
+}
+
+func (n WebhookNotifier) Notify(ctx context.Context, reminder model.Reminder) error {
+	deliveries, err := n.Postgres.EnqueueWebhookEvent(ctx, reminderEvent(reminder))
+	if err != nil {
+		return fmt.Errorf("enqueue webhook event: %w", err)
+	}
+
+	if deliveries == 0 {
+		return errReminderWebhookNotFound
+	}
+
+	return nil
+}
+
+// InAppNotifier saves the task.reminder event to the event log of the user, it is sent to the event streams,
+// and the notification to the inbox of the user unless the user disabled its type.
+type InAppNotifier struct {
+	Postgres InAppSaver
+	// Hub nil - the streams read the log on the heartbeats, the streams of the other instances are woken by NOTIFY.
+	Hub EventNotifier
+}
+
+func (n InAppNotifier) Notify(ctx context.Context, reminder model.Reminder) error {
+	if _, err := n.Postgres.SaveEvent(ctx, reminder.UserID, reminderEvent(reminder)); err != nil {
+		return fmt.Errorf("save event: %w", err)
+	}
+
+	// The last, so a retried attempt does not repeat it, the receivers drop the repeated event.
+	if _, err := n.Postgres.CreateNotification(ctx, reminderNotification(reminder)); err != nil {
+		return fmt.Errorf("create notification: %w", err)
+	}
+
+	if n.Hub != nil {
+		n.Hub.Notify(reminder.UserID)
+	}
+
+	return nil
+}
+
+// SMTPNotifier sends the reminder to its email. STARTTLS is used if the server supports it.
+type SMTPNotifier struct {
+	// Address host:port of the SMTP server.
+	Address string
+	From    string
+	// Username empty - without authentication.
+	Username string
+	Password string
+}
+
+func (n SMTPNotifier) Notify(ctx context.Context, reminder model.Reminder) error {
+	if reminder.Email == "" {
+		return errReminderEmailRequired
+	}
+
+	host, _, err := net.SplitHostPort(n.Address)
+	if err != nil {
+		return fmt.Errorf("smtp address: %w", err)
+	}
+
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(ctx, "tcp", n.Address)
+	if err != nil {
+		return fmt.Errorf("dial: %w", err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			conn.Close()
+
+			return fmt.Errorf("set deadline: %w", err)
+		}
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+
+		return fmt.Errorf("smtp client: %w", err)
+	}
+
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}); err != nil {
+			return fmt.Errorf("starttls: %w", err)
+		}
+	}
+
+	if n.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", n.Username, n.Password, host)); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+
+	if err := client.Mail(n.From); err != nil {
+		return fmt.Errorf("mail: %w", err)
+	}
+
+	if err := client.Rcpt(reminder.Email); err != nil {
+		return fmt.Errorf("rcpt: %w", err)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("data: %w", err)
+	}
+
+	if _, err := w.Write(reminderEmail(n.From, reminder, time.Now())); err != nil {
+		return fmt.Errorf("write data: %w", err)
+	}
+
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("close data: %w", err)
+	}
+
+	if err := client.Quit(); err != nil {
+		return fmt.Errorf("quit: %w", err)
+	}
+
+	return nil
+}
+
+// reminderEvent the ID is the same for the attempts of the reminder, the receivers drop the repeated ones.
+func reminderEvent(reminder model.Reminder) model.Event {
+	return model.Event{
+		ID:       "reminder-" + strconv.Itoa(reminder.ID),
+		Type:     model.EventTaskReminder,
+		Created:  time.Now().UTC(),
+		UserID:   reminder.UserID,
+		TaskID:   reminder.TaskID,
+		Task:     reminder.Task,
+		Reminder: &reminder,
+	}
+}
+
+func reminderNotification(reminder model.Reminder) model.Notification {
+	title := "Reminder"
+	if reminder.Task != nil {
+		title += ": " + reminder.Task.Title
+	}
+
+	return model.Notification{
+		Type:   model.NotificationTaskReminder,
+		Title:  title,
+		TaskID: reminder.TaskID,
+		UserID: reminder.UserID,
+	}
+}
+
+// reminderEmail the message of RFC 5322, the subject is encoded, so the title does not break the headers.
+func reminderEmail(from string, reminder model.Reminder, date time.Time) []byte {
+	title := ""
+	if reminder.Task != nil {
+		title = reminder.Task.Title
+	}
+
+	var b bytes.Buffer
+
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + reminder.Email + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", "Reminder: "+title) + "\r\n")
+	b.WriteString("Date: " + date.Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(title + "\r\n\r\n")
+
+	if reminder.Task != nil && reminder.Task.Due != nil {
+		b.WriteString("Due: " + reminder.Task.Due.UTC().Format(time.RFC3339) + "\r\n")
+	}
+
+	b.WriteString("Task: " + strconv.Itoa(reminder.TaskID) + "\r\n")
+
+	return b.Bytes()
+}
@@ -0,0 +1,179 @@
+package worker
+
+import (
+	"context"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taskmanager/internal/model"
+)
+
+type smtpMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+// smtpStandIn a local SMTP server without TLS and authentication, it receives the messages to the channel.
+// rejectRcpt - the recipients are rejected.
+func smtpStandIn(t *testing.T, rejectRcpt bool) (string, <-chan smtpMessage) {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		l.Close()
+	})
+
+	messages := make(chan smtpMessage, 10)
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			go serveSMTP(conn, messages, rejectRcpt)
+		}
+	}()
+
+	return l.Addr().String(), messages
+}
+
+func serveSMTP(conn net.Conn, messages chan<- smtpMessage, rejectRcpt bool) {
+	tp := textproto.NewConn(conn)
+	defer tp.Close()
+
+	var message smtpMessage
+
+	reply := func(format string, args ...any) bool {
+		return tp.PrintfLine(format, args...) == nil
+	}
+
+	if !reply("220 localhost ESMTP stand-in") {
+		return
+	}
+
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+
+		command, arg, _ := strings.Cut(line, " ")
+
+		var ok bool
+
+		switch strings.ToUpper(command) {
+		case "EHLO", "HELO":
+			ok = reply("250 localhost")
+
+		case "MAIL":
+			message = smtpMessage{from: smtpPath(arg)}
+			ok = reply("250 OK")
+
+		case "RCPT":
+			if rejectRcpt {
+				ok = reply("550 no such user")
+
+				break
+			}
+
+			message.to = append(message.to, smtpPath(arg))
+			ok = reply("250 OK")
+
+		case "DATA":
+			if !reply("354 end with <CRLF>.<CRLF>") {
+				return
+			}
+
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+
+			message.data = string(data)
+			messages <- message
+
+			ok = reply("250 OK")
+
+		case "RSET", "NOOP":
+			ok = reply("250 OK")
+
+		case "QUIT":
+			reply("221 bye")
+
+			return
+
+		default:
+			ok = reply("502 not implemented")
+		}
+
+		if !ok {
+			return
+		}
+	}
+}
+
+// smtpPath the address of FROM:<address> and TO:<address>.
+func smtpPath(arg string) string {
+	_, path, _ := strings.Cut(arg, "<")
+	path, _, _ = strings.Cut(path, ">")
+
+	return path
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	due := time.Date(2023, 5, 2, 9, 30, 0, 0, time.UTC)
+
+	reminder := model.Reminder{
+		ID:      3,
+		TaskID:  24,
+		Channel: model.ReminderChannelEmail,
+		Email:   "user@example.com",
+		UserID:  1,
+		Task:    &model.Task{ID: 24, Title: "call the bank\r\nBcc: other@example.com", Due: &due},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t.Run("sent", func(t *testing.T) {
+		address, messages := smtpStandIn(t, false)
+
+		notifier := SMTPNotifier{Address: address, From: "tasks@example.com"}
+		require.NoError(t, notifier.Notify(ctx, reminder))
+
+		message := <-messages
+		assert.Equal(t, "tasks@example.com", message.from)
+		assert.Equal(t, []string{"user@example.com"}, message.to)
+
+		header, body, ok := strings.Cut(message.data, "\n\n")
+		require.True(t, ok)
+		assert.Contains(t, header, "To: user@example.com\n")
+		assert.Contains(t, header, "Subject: =?utf-8?q?Reminder:_call_the_bank")
+		assert.NotContains(t, header, "\nBcc:", "the title is encoded")
+		assert.Contains(t, body, "Due: 2023-05-02T09:30:00Z\n")
+		assert.Contains(t, body, "Task: 24\n")
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		address, _ := smtpStandIn(t, true)
+
+		notifier := SMTPNotifier{Address: address, From: "tasks@example.com"}
+		assert.ErrorContains(t, notifier.Notify(ctx, reminder), "rcpt: 550")
+	})
+
+	t.Run("without email", func(t *testing.T) {
+		notifier := SMTPNotifier{Address: "127.0.0.1:25", From: "tasks@example.com"}
+		assert.ErrorIs(t, notifier.Notify(ctx, model.Reminder{Task: reminder.Task}), errReminderEmailRequired)
+	})
+}
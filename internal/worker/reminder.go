@@ -0,0 +1,153 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"taskmanager/internal/model"
+)
+
+// remindersLimit the reminders sent at once.
+const remindersLimit = 100
+
+// remindersBatch the reminders claimed at once, they are sent one by one under one lease.
+const remindersBatch = 10
+
+// maxLengthReminderError the error of the last attempt of the reminder.
+const maxLengthReminderError = 500
+
+var errNotifierNotConfigured = errors.New("notifier not configured")
+
+// Clock the time of the scheduler, the tests use a fake one.
+type Clock interface {
+	Now() time.Time
+}
+
+// Notifier sends the reminder to its channel.
+type Notifier interface {
+	Notify(ctx context.Context, reminder model.Reminder) error
+}
+
+type ReminderSender interface {
+	ClaimReminders(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]model.Reminder, error)
+	SaveReminderAttempt(ctx context.Context, attempt model.ReminderAttempt) error
+}
+
+type ReminderConf struct {
+	IntervalSeconds int
+	// TimeoutSeconds of the notification, the batch of the reminders is claimed for the timeout of each of them and
+	// one more.
+	TimeoutSeconds int
+	// MaxAttempts the reminder fails after the attempts.
+	MaxAttempts int
+	// RetryDelaySeconds the delay after the first failed attempt, doubled after each next one.
+	RetryDelaySeconds int
+	// Clock nil - the system clock.
+	Clock Clock
+}
+
+// RunReminders sends the due reminders with the notifiers of their channels, the failed ones are retried with
+// exponential backoff. The reminders are stored, so the ones due during a restart are sent after it. Each reminder
+// is claimed by one instance at a time, it is sent again only if the instance stopped before saving the attempt.
+// Blocks until the context is canceled.
+func (conf ReminderConf) RunReminders(
+	ctx context.Context, postgres ReminderSender, notifiers map[string]Notifier, logger *logrus.Logger,
+) {
+	runPeriodically(ctx, time.Second*time.Duration(conf.IntervalSeconds), func(ctx context.Context) {
+		conf.sendReminders(ctx, postgres, notifiers, logger)
+	})
+
+	logger.Info("stop reminders: ok")
+}
+
+func (conf ReminderConf) now() time.Time {
+	if conf.Clock == nil {
+		return time.Now()
+	}
+
+	return conf.Clock.Now()
+}
+
+func (conf ReminderConf) sendReminders(
+	ctx context.Context, postgres ReminderSender, notifiers map[string]Notifier, logger *logrus.Logger,
+) {
+	for sent := 0; sent < remindersLimit; sent += remindersBatch {
+		if conf.sendRemindersBatch(ctx, postgres, notifiers, logger) < remindersBatch {
+			return
+		}
+	}
+}
+
+// sendRemindersBatch returns the quantity of the claimed reminders.
+func (conf ReminderConf) sendRemindersBatch(
+	ctx context.Context, postgres ReminderSender, notifiers map[string]Notifier, logger *logrus.Logger,
+) int {
+	timeout := time.Second * time.Duration(conf.TimeoutSeconds)
+
+	// Each notification takes up to the timeout, the lease covers the batch sent one by one and the saving.
+	// The reminders are claimed again if the worker stopped before saving the attempts.
+	lease := timeout * (remindersBatch + 1)
+	claimed := conf.now()
+
+	reminders, err := postgres.ClaimReminders(ctx, claimed, remindersBatch, lease)
+	if err != nil {
+		logger.Errorf("claim reminders: %v", err)
+
+		return 0
+	}
+
+	for _, reminder := range reminders {
+		// The rest is claimed again after the lease rather than sent twice.
+		if ctx.Err() != nil || conf.now().Sub(claimed)+timeout >= lease {
+			return 0
+		}
+
+		attempt := conf.sendReminder(ctx, notifiers, reminder)
+
+		if err := postgres.SaveReminderAttempt(ctx, attempt); err != nil {
+			logger.Errorf("save reminder attempt: reminder %d: %v", reminder.ID, err)
+		}
+	}
+
+	return len(reminders)
+}
+
+func (conf ReminderConf) sendReminder(
+	ctx context.Context, notifiers map[string]Notifier, reminder model.Reminder,
+) model.ReminderAttempt {
+	err := conf.notify(ctx, notifiers, reminder)
+
+	attempt := model.ReminderAttempt{
+		ReminderID:   reminder.ID,
+		Succeeded:    err == nil,
+		Time:         conf.now(),
+		ClaimedUntil: reminder.ClaimedUntil,
+	}
+
+	if err != nil {
+		attempt.Error = err.Error()
+		if len(attempt.Error) > maxLengthReminderError {
+			attempt.Error = attempt.Error[:maxLengthReminderError]
+		}
+
+		attempt.RetryAfter = retryAfter(reminder.Attempts+1, conf.MaxAttempts, conf.RetryDelaySeconds)
+	}
+
+	return attempt
+}
+
+func (conf ReminderConf) notify(ctx context.Context, notifiers map[string]Notifier, reminder model.Reminder) error {
+	notifier, ok := notifiers[reminder.Channel]
+	if !ok {
+		return fmt.Errorf("%s: %w", reminder.Channel, errNotifierNotConfigured)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(conf.TimeoutSeconds))
+	defer cancel()
+
+	return notifier.Notify(ctx, reminder)
+}
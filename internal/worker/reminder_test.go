@@ -0,0 +1,446 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taskmanager/internal/model"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+// countingNotifier counts the notifications of the reminders, fails with err.
+type countingNotifier struct {
+	mu    sync.Mutex
+	sent  map[int]int
+	err   error
+	delay time.Duration
+}
+
+func (n *countingNotifier) Notify(_ context.Context, reminder model.Reminder) error {
+	time.Sleep(n.delay)
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.sent == nil {
+		n.sent = make(map[int]int)
+	}
+
+	n.sent[reminder.ID]++
+
+	return n.err
+}
+
+func (n *countingNotifier) count(reminderID int) int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.sent[reminderID]
+}
+
+// stallingNotifier counts the notifications, the first one of the reminder stallID advances the clock by stall
+// and blocks until release is closed, as the notifier slower than the lease.
+type stallingNotifier struct {
+	countingNotifier
+	clock   *fakeClock
+	stallID int
+	stall   time.Duration
+	stalled chan struct{}
+	release chan struct{}
+	stalls  atomic.Bool
+}
+
+func (n *stallingNotifier) Notify(ctx context.Context, reminder model.Reminder) error {
+	if reminder.ID == n.stallID && n.stalls.CompareAndSwap(false, true) {
+		n.clock.Advance(n.stall)
+		close(n.stalled)
+		<-n.release
+	}
+
+	return n.countingNotifier.Notify(ctx, reminder)
+}
+
+// testReminderTask the user and the task due at due.
+func testReminderTask(t *testing.T, storage model.Memory, due time.Time) (int, int) {
+	t.Helper()
+
+	ctx := context.Background()
+
+	userID, err := storage.CreateNewUser(ctx, "reminderuser", "password")
+	require.NoError(t, err)
+
+	taskID, err := storage.CreateTask(ctx, "reminderuser", "password", "call the bank")
+	require.NoError(t, err)
+
+	require.NoError(t, storage.UpdateTask(ctx, "reminderuser", "password", taskID, 0, model.TaskUpdate{Due: &due}))
+
+	return userID, taskID
+}
+
+func TestRunReminders(t *testing.T) {
+	ctx := context.Background()
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	due := time.Date(2023, 5, 2, 9, 30, 0, 0, time.UTC)
+	clock := &fakeClock{now: due.Add(-2 * time.Hour)}
+
+	storage := model.NewMemory()
+	userID, taskID := testReminderTask(t, storage, due)
+
+	_, err := storage.CreateWebhook(ctx, userID, model.Webhook{
+		URL:    "https://example.com/hooks",
+		Events: []string{model.EventTaskReminder},
+		Secret: "secret",
+	})
+	require.NoError(t, err)
+
+	beforeMinutes := 30
+	remindAt := due.Add(-time.Hour)
+
+	inAppID, err := storage.CreateReminder(ctx, userID, taskID, model.Reminder{
+		BeforeMinutes: &beforeMinutes,
+		Channel:       model.ReminderChannelInApp,
+	})
+	require.NoError(t, err)
+
+	webhookID, err := storage.CreateReminder(ctx, userID, taskID, model.Reminder{
+		BeforeMinutes: &beforeMinutes,
+		Channel:       model.ReminderChannelWebhook,
+	})
+	require.NoError(t, err)
+
+	emailID, err := storage.CreateReminder(ctx, userID, taskID, model.Reminder{
+		RemindAt: &remindAt,
+		Channel:  model.ReminderChannelEmail,
+		Email:    "user@example.com",
+	})
+	require.NoError(t, err)
+
+	address, messages := smtpStandIn(t, false)
+
+	notifiers := map[string]Notifier{
+		model.ReminderChannelInApp:   InAppNotifier{Postgres: storage},
+		model.ReminderChannelWebhook: WebhookNotifier{Postgres: storage},
+		model.ReminderChannelEmail:   SMTPNotifier{Address: address, From: "tasks@example.com"},
+	}
+
+	conf := ReminderConf{
+		TimeoutSeconds:    5,
+		MaxAttempts:       3,
+		RetryDelaySeconds: 60,
+		Clock:             clock,
+	}
+
+	status := func(reminderID int) string {
+		reminders, err := storage.GetReminders(ctx, userID, taskID)
+		require.NoError(t, err)
+
+		for _, reminder := range reminders {
+			if reminder.ID == reminderID {
+				return reminder.Status
+			}
+		}
+
+		return ""
+	}
+
+	conf.sendReminders(ctx, storage, notifiers, logger)
+	assert.Equal(t, model.ReminderPending, status(emailID), "not due")
+
+	clock.Advance(time.Hour)
+	conf.sendReminders(ctx, storage, notifiers, logger)
+	assert.Equal(t, model.ReminderSent, status(emailID))
+	assert.Equal(t, model.ReminderPending, status(inAppID))
+
+	select {
+	case message := <-messages:
+		assert.Equal(t, []string{"user@example.com"}, message.to)
+		assert.Contains(t, message.data, "Subject: Reminder: call the bank\n")
+	case <-time.After(5 * time.Second):
+		t.Fatal("the email is not received")
+	}
+
+	clock.Advance(30 * time.Minute)
+	conf.sendReminders(ctx, storage, notifiers, logger)
+	assert.Equal(t, model.ReminderSent, status(inAppID))
+	assert.Equal(t, model.ReminderSent, status(webhookID))
+
+	events, err := storage.GetEvents(ctx, userID, 0, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, model.EventTaskReminder, events[0].Type)
+	assert.Equal(t, taskID, events[0].TaskID)
+	require.NotNil(t, events[0].Reminder)
+	assert.Equal(t, inAppID, events[0].Reminder.ID)
+
+	notifications, err := storage.GetNotifications(ctx, userID, model.NotificationQuery{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, notifications, 1)
+	assert.Equal(t, model.NotificationTaskReminder, notifications[0].Type)
+	assert.Equal(t, "Reminder: call the bank", notifications[0].Title)
+	assert.Equal(t, taskID, notifications[0].TaskID)
+
+	deliveries, err := storage.ClaimWebhookDeliveries(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, model.EventTaskReminder, deliveries[0].Event)
+	assert.Contains(t, string(deliveries[0].Payload), `"reminderId":2`)
+
+	// The sent reminders are not sent again.
+	clock.Advance(time.Hour)
+	conf.sendReminders(ctx, storage, notifiers, logger)
+
+	events, err = storage.GetEvents(ctx, userID, 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+	assert.Empty(t, messages)
+}
+
+func TestRunRemindersInstances(t *testing.T) {
+	ctx := context.Background()
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	due := time.Date(2023, 5, 2, 9, 30, 0, 0, time.UTC)
+	clock := &fakeClock{now: due}
+
+	storage := model.NewMemory()
+	userID, taskID := testReminderTask(t, storage, due)
+
+	var reminderIDs []int
+
+	for i := 0; i < 20; i++ {
+		beforeMinutes := i
+
+		reminderID, err := storage.CreateReminder(ctx, userID, taskID, model.Reminder{
+			BeforeMinutes: &beforeMinutes,
+			Channel:       model.ReminderChannelInApp,
+		})
+		require.NoError(t, err)
+
+		reminderIDs = append(reminderIDs, reminderID)
+	}
+
+	notifier := &countingNotifier{delay: time.Millisecond}
+	notifiers := map[string]Notifier{model.ReminderChannelInApp: notifier}
+
+	conf := ReminderConf{
+		TimeoutSeconds:    5,
+		MaxAttempts:       3,
+		RetryDelaySeconds: 60,
+		Clock:             clock,
+	}
+
+	// The instances share the storage, as the ones of the same database.
+	var wg sync.WaitGroup
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			conf.sendReminders(ctx, storage, notifiers, logger)
+		}()
+	}
+
+	wg.Wait()
+
+	for _, reminderID := range reminderIDs {
+		assert.Equal(t, 1, notifier.count(reminderID), "reminder %d", reminderID)
+	}
+}
+
+func TestRunRemindersRetry(t *testing.T) {
+	ctx := context.Background()
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	due := time.Date(2023, 5, 2, 9, 30, 0, 0, time.UTC)
+	clock := &fakeClock{now: due}
+
+	storage := model.NewMemory()
+	userID, taskID := testReminderTask(t, storage, due)
+
+	remindAt := due
+
+	reminderID, err := storage.CreateReminder(ctx, userID, taskID, model.Reminder{
+		RemindAt: &remindAt,
+		Channel:  model.ReminderChannelWebhook,
+	})
+	require.NoError(t, err)
+
+	notifier := &countingNotifier{err: errors.New("connection refused")}
+	notifiers := map[string]Notifier{model.ReminderChannelWebhook: notifier}
+
+	conf := ReminderConf{
+		TimeoutSeconds:    5,
+		MaxAttempts:       3,
+		RetryDelaySeconds: 60,
+		Clock:             clock,
+	}
+
+	reminder := func() model.Reminder {
+		reminders, err := storage.GetReminders(ctx, userID, taskID)
+		require.NoError(t, err)
+		require.Len(t, reminders, 1)
+
+		return reminders[0]
+	}
+
+	conf.sendReminders(ctx, storage, notifiers, logger)
+	assert.Equal(t, 1, notifier.count(reminderID))
+	assert.Equal(t, model.ReminderPending, reminder().Status)
+	assert.Equal(t, "connection refused", reminder().Error)
+
+	// The backoff: 60 seconds after the first attempt, 120 after the second.
+	clock.Advance(59 * time.Second)
+	conf.sendReminders(ctx, storage, notifiers, logger)
+	assert.Equal(t, 1, notifier.count(reminderID))
+
+	clock.Advance(time.Second)
+	conf.sendReminders(ctx, storage, notifiers, logger)
+	assert.Equal(t, 2, notifier.count(reminderID))
+
+	clock.Advance(120 * time.Second)
+	conf.sendReminders(ctx, storage, notifiers, logger)
+	assert.Equal(t, 3, notifier.count(reminderID))
+	assert.Equal(t, model.ReminderFailed, reminder().Status)
+	assert.Equal(t, 3, reminder().Attempts)
+
+	clock.Advance(time.Hour)
+	conf.sendReminders(ctx, storage, notifiers, logger)
+	assert.Equal(t, 3, notifier.count(reminderID))
+
+	t.Run("not configured", func(t *testing.T) {
+		remindAt := clock.Now()
+
+		reminderID, err := storage.CreateReminder(ctx, userID, taskID, model.Reminder{
+			RemindAt: &remindAt,
+			Channel:  model.ReminderChannelEmail,
+			Email:    "user@example.com",
+		})
+		require.NoError(t, err)
+
+		reminders, err := storage.ClaimReminders(ctx, clock.Now(), 10, time.Minute)
+		require.NoError(t, err)
+		require.Len(t, reminders, 1)
+
+		attempt := conf.sendReminder(ctx, notifiers, reminders[0])
+		assert.Equal(t, reminderID, attempt.ReminderID)
+		assert.False(t, attempt.Succeeded)
+		assert.Equal(t, "email: notifier not configured", attempt.Error)
+		assert.Equal(t, time.Minute, attempt.RetryAfter)
+	})
+}
+
+func TestRunRemindersLease(t *testing.T) {
+	ctx := context.Background()
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	due := time.Date(2023, 5, 2, 9, 30, 0, 0, time.UTC)
+	clock := &fakeClock{now: due}
+
+	storage := model.NewMemory()
+	userID, taskID := testReminderTask(t, storage, due)
+
+	var reminderIDs []int
+
+	for i := 0; i < remindersBatch; i++ {
+		beforeMinutes := i
+
+		reminderID, err := storage.CreateReminder(ctx, userID, taskID, model.Reminder{
+			BeforeMinutes: &beforeMinutes,
+			Channel:       model.ReminderChannelInApp,
+		})
+		require.NoError(t, err)
+
+		reminderIDs = append(reminderIDs, reminderID)
+	}
+
+	conf := ReminderConf{
+		TimeoutSeconds:    5,
+		MaxAttempts:       3,
+		RetryDelaySeconds: 60,
+		Clock:             clock,
+	}
+
+	// The earliest reminder is sent first, its notification outlasts the lease of the batch.
+	stalledID := reminderIDs[len(reminderIDs)-1]
+
+	notifier := &stallingNotifier{
+		clock:   clock,
+		stallID: stalledID,
+		stall:   time.Second * time.Duration(conf.TimeoutSeconds) * (remindersBatch + 2),
+		stalled: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	notifiers := map[string]Notifier{model.ReminderChannelInApp: notifier}
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		conf.sendReminders(ctx, storage, notifiers, logger)
+	}()
+
+	<-notifier.stalled
+
+	// The lease of the first worker expired, the second one claims the batch again and sends it.
+	conf.sendReminders(ctx, storage, notifiers, logger)
+
+	close(notifier.release)
+	<-done
+
+	for _, reminderID := range reminderIDs {
+		if reminderID == stalledID {
+			assert.Equal(t, 2, notifier.count(reminderID), "sent by both workers")
+
+			continue
+		}
+
+		assert.Equal(t, 1, notifier.count(reminderID), "reminder %d: the first worker stops after the lease", reminderID)
+	}
+
+	reminders, err := storage.GetReminders(ctx, userID, taskID)
+	require.NoError(t, err)
+
+	for _, reminder := range reminders {
+		assert.Equal(t, model.ReminderSent, reminder.Status)
+		assert.Equal(t, 1, reminder.Attempts, "reminder %d: the attempt of the expired lease is not saved", reminder.ID)
+	}
+}
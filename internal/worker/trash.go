@@ -0,0 +1,46 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const hoursInDay = 24
+
+type TrashPurger interface {
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
+}
+
+type TrashConf struct {
+	RetentionDays        int
+	PurgeIntervalMinutes int
+}
+
+// RunTrashPurge permanently deletes tasks that have been in the trash longer than the retention period.
+// Blocks until the context is canceled.
+func (conf TrashConf) RunTrashPurge(ctx context.Context, postgres TrashPurger, logger *logrus.Logger) {
+	runPeriodically(ctx, time.Minute*time.Duration(conf.PurgeIntervalMinutes), func(ctx context.Context) {
+		conf.purgeTrash(ctx, postgres, logger)
+	})
+
+	logger.Info("stop trash purge: ok")
+}
+
+func (conf TrashConf) purgeTrash(ctx context.Context, postgres TrashPurger, logger *logrus.Logger) {
+	quantity, err := postgres.PurgeTrash(ctx, conf.purgeBefore(time.Now()))
+	if err != nil {
+		logger.Errorf("purge trash: %v", err)
+
+		return
+	}
+
+	if quantity > 0 {
+		logger.Infof("purge trash: %d tasks deleted", quantity)
+	}
+}
+
+func (conf TrashConf) purgeBefore(now time.Time) time.Time {
+	return now.Add(-time.Hour * hoursInDay * time.Duration(conf.RetentionDays))
+}
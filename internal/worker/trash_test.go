@@ -0,0 +1,82 @@
+package worker
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type trashPurgerTest struct {
+	before chan time.Time
+}
+
+func (p trashPurgerTest) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	p.before <- before
+
+	return 0, nil
+}
+
+func TestTrashPurgeBefore(t *testing.T) {
+	now := time.Date(2023, 4, 20, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name          string
+		retentionDays int
+		expected      time.Time
+	}{
+		{
+			name:          "one day",
+			retentionDays: 1,
+			expected:      time.Date(2023, 4, 19, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "thirty days",
+			retentionDays: 30,
+			expected:      time.Date(2023, 3, 21, 12, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := TrashConf{
+				RetentionDays: tt.retentionDays,
+			}
+
+			assert.Equal(t, tt.expected, conf.purgeBefore(now))
+		})
+	}
+}
+
+func TestRunTrashPurge(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	conf := TrashConf{
+		RetentionDays:        7,
+		PurgeIntervalMinutes: 60,
+	}
+
+	postgres := trashPurgerTest{
+		before: make(chan time.Time, 1),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+
+	go func() {
+		conf.RunTrashPurge(ctx, postgres, logger)
+		close(done)
+	}()
+
+	// The first purge runs immediately, without waiting for the interval.
+	before := <-postgres.before
+	assert.WithinDuration(t, time.Now().Add(-time.Hour*24*7), before, time.Minute)
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,209 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"taskmanager/internal/model"
+)
+
+const (
+	HeaderWebhookEvent     = "X-Webhook-Event"
+	HeaderWebhookDelivery  = "X-Webhook-Delivery"
+	HeaderWebhookSignature = "X-Webhook-Signature"
+)
+
+// webhookDeliveriesLimit the deliveries sent at each interval.
+const webhookDeliveriesLimit = 100
+
+// webhookDeliveriesBatch the deliveries claimed at once, they are sent one by one under one lease.
+const webhookDeliveriesBatch = 10
+
+// maxLengthWebhookError the error of the delivery in the log.
+const maxLengthWebhookError = 500
+
+type WebhookDeliverer interface {
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error)
+	SaveWebhookAttempt(ctx context.Context, attempt model.WebhookAttempt) error
+}
+
+type WebhookDeliveriesPurger interface {
+	PurgeWebhookDeliveries(ctx context.Context, before time.Time) (int64, error)
+}
+
+type WebhookConf struct {
+	IntervalSeconds int
+	TimeoutSeconds  int
+	// MaxAttempts the delivery fails after the attempts.
+	MaxAttempts int
+	// RetryDelaySeconds the delay after the first failed attempt, doubled after each next one.
+	RetryDelaySeconds int
+	// DisableAfterFailures the webhook is disabled after the failed attempts in a row.
+	DisableAfterFailures int
+	RetentionDays        int
+	PurgeIntervalMinutes int
+}
+
+// RunWebhookDeliveries sends the pending deliveries, the failed ones are retried with exponential backoff.
+// Blocks until the context is canceled.
+func (conf WebhookConf) RunWebhookDeliveries(
+	ctx context.Context, postgres WebhookDeliverer, client *http.Client, logger *logrus.Logger,
+) {
+	runPeriodically(ctx, time.Second*time.Duration(conf.IntervalSeconds), func(ctx context.Context) {
+		conf.sendWebhookDeliveries(ctx, postgres, client, logger)
+	})
+
+	logger.Info("stop webhook deliveries: ok")
+}
+
+// RunWebhookDeliveriesPurge deletes the deliveries older than the retention period from the delivery log.
+// Blocks until the context is canceled.
+func (conf WebhookConf) RunWebhookDeliveriesPurge(
+	ctx context.Context, postgres WebhookDeliveriesPurger, logger *logrus.Logger,
+) {
+	runPeriodically(ctx, time.Minute*time.Duration(conf.PurgeIntervalMinutes), func(ctx context.Context) {
+		before := time.Now().Add(-time.Hour * hoursInDay * time.Duration(conf.RetentionDays))
+
+		quantity, err := postgres.PurgeWebhookDeliveries(ctx, before)
+		if err != nil {
+			logger.Errorf("purge webhook deliveries: %v", err)
+
+			return
+		}
+
+		if quantity > 0 {
+			logger.Infof("purge webhook deliveries: %d deliveries deleted", quantity)
+		}
+	})
+
+	logger.Info("stop webhook deliveries purge: ok")
+}
+
+// SignWebhookPayload the value of X-Webhook-Signature: t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<payload>">.
+func SignWebhookPayload(secret string, timestamp time.Time, payload []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(t + "."))
+	mac.Write(payload)
+
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (conf WebhookConf) sendWebhookDeliveries(
+	ctx context.Context, postgres WebhookDeliverer, client *http.Client, logger *logrus.Logger,
+) {
+	for sent := 0; sent < webhookDeliveriesLimit; sent += webhookDeliveriesBatch {
+		if conf.sendWebhookDeliveriesBatch(ctx, postgres, client, logger) < webhookDeliveriesBatch {
+			return
+		}
+	}
+}
+
+// sendWebhookDeliveriesBatch returns the quantity of the claimed deliveries.
+func (conf WebhookConf) sendWebhookDeliveriesBatch(
+	ctx context.Context, postgres WebhookDeliverer, client *http.Client, logger *logrus.Logger,
+) int {
+	timeout := time.Second * time.Duration(conf.TimeoutSeconds)
+
+	// Each delivery takes up to the timeout, the lease covers the batch sent one by one and the saving.
+	// The deliveries are claimed again if the worker stopped before saving the attempts.
+	lease := timeout * (webhookDeliveriesBatch + 1)
+	claimed := time.Now()
+
+	deliveries, err := postgres.ClaimWebhookDeliveries(ctx, webhookDeliveriesBatch, lease)
+	if err != nil {
+		logger.Errorf("claim webhook deliveries: %v", err)
+
+		return 0
+	}
+
+	for _, delivery := range deliveries {
+		// The rest is claimed again after the lease rather than sent twice.
+		if ctx.Err() != nil || time.Since(claimed)+timeout >= lease {
+			return 0
+		}
+
+		attempt := conf.sendWebhookDelivery(ctx, client, delivery)
+
+		if err := postgres.SaveWebhookAttempt(ctx, attempt); err != nil {
+			logger.Errorf("save webhook attempt: delivery %d: %v", delivery.ID, err)
+		}
+	}
+
+	return len(deliveries)
+}
+
+func (conf WebhookConf) sendWebhookDelivery(
+	ctx context.Context, client *http.Client, delivery model.WebhookDelivery,
+) model.WebhookAttempt {
+	attempt := model.WebhookAttempt{
+		DeliveryID:   delivery.ID,
+		ClaimedUntil: delivery.NextAttempt,
+		DisableAfter: conf.DisableAfterFailures,
+	}
+
+	responseStatus, err := conf.postWebhookDelivery(ctx, client, delivery)
+
+	attempt.ResponseStatus = responseStatus
+	attempt.Succeeded = err == nil
+
+	if err != nil {
+		attempt.Error = err.Error()
+		if len(attempt.Error) > maxLengthWebhookError {
+			attempt.Error = attempt.Error[:maxLengthWebhookError]
+		}
+
+		attempt.RetryAfter = conf.retryAfter(delivery.Attempts + 1)
+	}
+
+	return attempt
+}
+
+func (conf WebhookConf) postWebhookDelivery(
+	ctx context.Context, client *http.Client, delivery model.WebhookDelivery,
+) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*time.Duration(conf.TimeoutSeconds))
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("new request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderWebhookEvent, delivery.Event)
+	req.Header.Set(HeaderWebhookDelivery, delivery.EventID)
+	req.Header.Set(HeaderWebhookSignature, SignWebhookPayload(delivery.Secret, time.Now(), delivery.Payload))
+
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("do request: %w", err)
+	}
+
+	defer res.Body.Close()
+
+	// The connection is reused after the body is read.
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, maxLengthWebhookError))
+
+	if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusMultipleChoices {
+		return res.StatusCode, fmt.Errorf("response status: %d", res.StatusCode)
+	}
+
+	return res.StatusCode, nil
+}
+
+// retryAfter the delay after the failed attempt, 0 - the delivery is not retried.
+func (conf WebhookConf) retryAfter(attempts int) time.Duration {
+	return retryAfter(attempts, conf.MaxAttempts, conf.RetryDelaySeconds)
+}
@@ -0,0 +1,206 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"taskmanager/internal/model"
+)
+
+type webhookDelivererTest struct {
+	deliveries []model.WebhookDelivery
+	attempts   []model.WebhookAttempt
+	// claims the limits of the claims.
+	claims []int
+	leases []time.Duration
+}
+
+func (d *webhookDelivererTest) ClaimWebhookDeliveries(
+	ctx context.Context, limit int, lease time.Duration,
+) ([]model.WebhookDelivery, error) {
+	d.claims = append(d.claims, limit)
+	d.leases = append(d.leases, lease)
+
+	deliveries := d.deliveries
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	d.deliveries = d.deliveries[len(deliveries):]
+
+	return deliveries, nil
+}
+
+func (d *webhookDelivererTest) SaveWebhookAttempt(ctx context.Context, attempt model.WebhookAttempt) error {
+	d.attempts = append(d.attempts, attempt)
+
+	return nil
+}
+
+func TestWebhookRetryAfter(t *testing.T) {
+	conf := WebhookConf{
+		MaxAttempts:       4,
+		RetryDelaySeconds: 10,
+	}
+
+	cases := []struct {
+		name     string
+		attempts int
+		expected time.Duration
+	}{
+		{
+			name:     "first",
+			attempts: 1,
+			expected: 10 * time.Second,
+		},
+		{
+			name:     "third",
+			attempts: 3,
+			expected: 40 * time.Second,
+		},
+		{
+			name:     "last",
+			attempts: 4,
+			expected: 0,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, conf.retryAfter(tt.attempts))
+		})
+	}
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	timestamp := time.Unix(1681992000, 0)
+
+	assert.Equal(t,
+		"t=1681992000,v1=ea66f0b0f63dfd8c51991a610a30f10d6e05b070eddf244c09bb751d01016e61",
+		SignWebhookPayload("secret", timestamp, []byte(`{"event":"task.created"}`)),
+	)
+}
+
+func TestSendWebhookDeliveries(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	received := make(chan *http.Request, 2)
+	bodies := make(chan []byte, 2)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		received <- r
+		bodies <- body
+
+		if r.Header.Get(HeaderWebhookEvent) == model.EventTaskDeleted {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(receiver.Close)
+
+	conf := WebhookConf{
+		TimeoutSeconds:       5,
+		MaxAttempts:          3,
+		RetryDelaySeconds:    60,
+		DisableAfterFailures: 10,
+	}
+
+	deliverer := &webhookDelivererTest{
+		deliveries: []model.WebhookDelivery{
+			{
+				ID:      1,
+				EventID: "e1",
+				Event:   model.EventTaskCreated,
+				URL:     receiver.URL,
+				Secret:  "secret",
+				Payload: []byte(`{"event":"task.created"}`),
+			},
+			{
+				ID:       2,
+				EventID:  "e2",
+				Event:    model.EventTaskDeleted,
+				Attempts: 1,
+				URL:      receiver.URL,
+				Secret:   "secret",
+				Payload:  []byte(`{"event":"task.deleted"}`),
+			},
+		},
+	}
+
+	conf.sendWebhookDeliveries(context.Background(), deliverer, receiver.Client(), logger)
+
+	require.Len(t, deliverer.attempts, 2)
+
+	assert.Equal(t, model.WebhookAttempt{
+		DeliveryID:     1,
+		Succeeded:      true,
+		ResponseStatus: http.StatusOK,
+		DisableAfter:   10,
+	}, deliverer.attempts[0])
+
+	assert.False(t, deliverer.attempts[1].Succeeded)
+	assert.Equal(t, http.StatusInternalServerError, deliverer.attempts[1].ResponseStatus)
+	assert.NotEmpty(t, deliverer.attempts[1].Error)
+	assert.Equal(t, 2*time.Minute, deliverer.attempts[1].RetryAfter)
+
+	r, body := <-received, <-bodies
+	assert.Equal(t, "e1", r.Header.Get(HeaderWebhookDelivery))
+	assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+	assert.Equal(t, `{"event":"task.created"}`, string(body))
+
+	signature := r.Header.Get(HeaderWebhookSignature)
+	require.Regexp(t, `^t=\d+,v1=[0-9a-f]{64}$`, signature)
+
+	var unix int64
+	_, err := fmt.Sscanf(signature, "t=%d,", &unix)
+	require.NoError(t, err)
+	assert.Equal(t, SignWebhookPayload("secret", time.Unix(unix, 0), body), signature)
+}
+
+func TestSendWebhookDeliveriesBatches(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(receiver.Close)
+
+	conf := WebhookConf{TimeoutSeconds: 5}
+
+	deliverer := &webhookDelivererTest{}
+
+	for i := 1; i <= 2*webhookDeliveriesBatch+5; i++ {
+		deliverer.deliveries = append(deliverer.deliveries, model.WebhookDelivery{
+			ID:  i,
+			URL: receiver.URL,
+			// The lease of the claim.
+			NextAttempt: time.Unix(int64(i), 0),
+		})
+	}
+
+	conf.sendWebhookDeliveries(context.Background(), deliverer, receiver.Client(), logger)
+
+	// Each batch is claimed under the lease covering the sending of all of its deliveries.
+	assert.Equal(t, []int{webhookDeliveriesBatch, webhookDeliveriesBatch, webhookDeliveriesBatch}, deliverer.claims)
+
+	for _, lease := range deliverer.leases {
+		assert.Equal(t, 5*time.Second*(webhookDeliveriesBatch+1), lease)
+	}
+
+	require.Len(t, deliverer.attempts, 2*webhookDeliveriesBatch+5)
+
+	for i, attempt := range deliverer.attempts {
+		assert.Equal(t, i+1, attempt.DeliveryID)
+		assert.Equal(t, time.Unix(int64(i+1), 0), attempt.ClaimedUntil)
+	}
+}
@@ -0,0 +1,33 @@
+package worker
+
+import (
+	"context"
+	"time"
+)
+
+// runPeriodically runs the job immediately and then every interval until the context is canceled.
+func runPeriodically(ctx context.Context, interval time.Duration, job func(ctx context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		job(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+		}
+	}
+}
+
+// retryAfter the delay after the failed attempts: retryDelaySeconds after the first one, doubled after each next one.
+// 0 - maxAttempts are made, the job is not retried.
+func retryAfter(attempts, maxAttempts, retryDelaySeconds int) time.Duration {
+	if attempts >= maxAttempts {
+		return 0
+	}
+
+	return time.Second * time.Duration(retryDelaySeconds) << (attempts - 1)
+}